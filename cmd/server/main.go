@@ -7,6 +7,14 @@ import (
 	"github.com/ciliverse/cilikube/internal/app"
 )
 
+// @title CiliKube API
+// @version 1.0
+// @description Multi-cluster Kubernetes management API.
+// @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+
 // just do it ! go!go!go!
 func main() {
 	configPath := app.GetConfigPath()
@@ -2,25 +2,33 @@ package configs
 
 import (
 	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/google/uuid"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Server     ServerConfig     `yaml:"server" json:"server"`
-	Kubernetes KubernetesConfig `yaml:"kubernetes" json:"kubernetes"`
-	Installer  InstallerConfig  `yaml:"installer" json:"installer"`
-	Database   DatabaseConfig   `yaml:"database" json:"database"`
-	Storage    StorageConfig    `yaml:"storage" json:"storage"`
-	JWT        JWTConfig        `yaml:"jwt" json:"jwt"`
-	OAuth      OAuthConfig      `yaml:"oauth" json:"oauth"`
-	Security   SecurityConfig   `yaml:"security" json:"security"`
-	Clusters   []ClusterInfo    `yaml:"clusters" json:"clusters"`
+	Server      ServerConfig      `yaml:"server" json:"server"`
+	Kubernetes  KubernetesConfig  `yaml:"kubernetes" json:"kubernetes"`
+	Installer   InstallerConfig   `yaml:"installer" json:"installer"`
+	Database    DatabaseConfig    `yaml:"database" json:"database"`
+	Storage     StorageConfig     `yaml:"storage" json:"storage"`
+	JWT         JWTConfig         `yaml:"jwt" json:"jwt"`
+	OAuth       OAuthConfig       `yaml:"oauth" json:"oauth"`
+	AuthWebhook AuthWebhookConfig `yaml:"auth_webhook" json:"auth_webhook"`
+	Security    SecurityConfig    `yaml:"security" json:"security"`
+	Monitoring  MonitoringConfig  `yaml:"monitoring" json:"monitoring"`
+	Admin       AdminConfig       `yaml:"admin" json:"admin"`
+	Clusters    []ClusterInfo     `yaml:"clusters" json:"clusters"`
 }
 
 type ServerConfig struct {
@@ -30,10 +38,192 @@ type ServerConfig struct {
 	Mode            string `yaml:"mode" json:"mode"`                   // debug, release
 	ActiveClusterID string `yaml:"activeCluster" json:"activeCluster"` // Modified to match field name in config file
 	EncryptionKey   string `yaml:"encryptionKey" json:"encryptionKey"`
+	MaxBodyBytes    int64  `yaml:"max_body_bytes" json:"max_body_bytes"` // Largest accepted request body, 0 uses 10 MiB and negative disables the limit
+	EnablePprof     bool   `yaml:"enable_pprof" json:"enable_pprof"`     // Serve /debug/pprof and runtime stats outside debug mode
+	BasePath        string `yaml:"base_path" json:"base_path"`           // Prefix of every route when served below a path, e.g. "/cilikube" behind an ingress
+	ExternalURL     string `yaml:"external_url" json:"external_url"`     // URL clients reach the server at, base path included, e.g. "https://ops.example.com/cilikube"
+	LogLevel        string `yaml:"log_level" json:"log_level"`           // debug, info, warn or error, see SlogLevel
+	LogFormat       string `yaml:"log_format" json:"log_format"`         // json or text, see SlogFormat
+
+	// TrustedProxies lists the IPs and CIDRs of load balancers and reverse proxies whose X-Forwarded-For
+	// is used to resolve the client IP, and whose X-Forwarded-Prefix, -Host and -Proto build the links
+	// returned to clients. Empty trusts none, so the connection's address is recorded.
+	TrustedProxies []string `yaml:"trusted_proxies" json:"trusted_proxies"`
+
+	Compression CompressionConfig `yaml:"compression" json:"compression"`
+	CORS        CORSConfig        `yaml:"cors" json:"cors"`
+	Swagger     SwaggerConfig     `yaml:"swagger" json:"swagger"`
+	Pagination  PaginationConfig  `yaml:"pagination" json:"pagination"`
+
+	SecurityHeaders SecurityHeadersConfig `yaml:"security_headers" json:"security_headers"`
+	TLS             TLSConfig             `yaml:"tls" json:"tls"`
+}
+
+// TLSConfig serves HTTPS directly with a certificate that is reloaded when its files change
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled" json:"enabled"`
+	CertFile string `yaml:"cert_file" json:"cert_file"` // PEM certificate, followed by any intermediates
+	KeyFile  string `yaml:"key_file" json:"key_file"`   // PEM private key of the certificate
+}
+
+// Validate checks that both files are set when TLS is enabled
+func (t TLSConfig) Validate() error {
+	if t.Enabled && (t.CertFile == "" || t.KeyFile == "") {
+		return fmt.Errorf("server.tls.cert_file and server.tls.key_file are required when server.tls.enabled is set")
+	}
+	return nil
+}
+
+// PaginationConfig bounds the page sizes of the admin list APIs
+type PaginationConfig struct {
+	DefaultSize int `yaml:"default_size" json:"default_size"` // Page size used when page_size is not given
+	MaxSize     int `yaml:"max_size" json:"max_size"`         // Larger page_size values are rejected with 400
+}
+
+// SwaggerConfig controls the /swagger/doc.json spec and /swagger/index.html UI
+type SwaggerConfig struct {
+	Enabled *bool `yaml:"enabled" json:"enabled"` // Unset serves the docs in every mode except release
+}
+
+// SwaggerEnabled reports whether the API docs are served
+func (s ServerConfig) SwaggerEnabled() bool {
+	if s.Swagger.Enabled != nil {
+		return *s.Swagger.Enabled
+	}
+	return s.Mode != "release"
+}
+
+// RoutePrefix returns the base path as a route prefix: "" at the root, otherwise with a leading and no trailing slash
+func (s ServerConfig) RoutePrefix() string {
+	prefix := strings.Trim(strings.TrimSpace(s.BasePath), "/")
+	if prefix == "" {
+		return ""
+	}
+	return "/" + prefix
+}
+
+// ValidateTrustedProxies checks that every trusted proxy is an IP address or a CIDR
+func (s ServerConfig) ValidateTrustedProxies() error {
+	for _, proxy := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err == nil {
+			continue
+		}
+		if net.ParseIP(proxy) == nil {
+			return fmt.Errorf("server.trusted_proxies: %q is neither an IP address nor a CIDR", proxy)
+		}
+	}
+	return nil
+}
+
+// ValidateExternalURL checks that the external URL, when set, is an absolute http or https URL
+func (s ServerConfig) ValidateExternalURL() error {
+	if s.ExternalURL == "" {
+		return nil
+	}
+	u, err := url.Parse(s.ExternalURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("server.external_url: %q is not an absolute http or https URL", s.ExternalURL)
+	}
+	return nil
+}
+
+// Log formats of server.log_format
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// SlogLevel returns the level of server.log_level, or of CILIKUBE_LOG_LEVEL which overrides it: debug, info,
+// warn or error. Unset, it is debug in debug mode and info otherwise.
+func (s ServerConfig) SlogLevel() (slog.Level, error) {
+	name := s.LogLevel
+	if env := os.Getenv("CILIKUBE_LOG_LEVEL"); env != "" {
+		name = env
+	}
+	if name == "" {
+		if s.Mode == "debug" {
+			return slog.LevelDebug, nil
+		}
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("server.log_level: %q is not one of debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+// SlogFormat returns the format of server.log_format, or of CILIKUBE_LOG_FORMAT which overrides it: json,
+// the default, for log aggregation, or text for reading in a terminal
+func (s ServerConfig) SlogFormat() (string, error) {
+	format := s.LogFormat
+	if env := os.Getenv("CILIKUBE_LOG_FORMAT"); env != "" {
+		format = env
+	}
+	switch strings.ToLower(format) {
+	case "", LogFormatJSON:
+		return LogFormatJSON, nil
+	case LogFormatText:
+		return LogFormatText, nil
+	}
+	return LogFormatJSON, fmt.Errorf("server.log_format: %q is neither json nor text", format)
+}
+
+// ValidateLogging checks the log level and format, including their environment overrides
+func (s ServerConfig) ValidateLogging() error {
+	if _, err := s.SlogLevel(); err != nil {
+		return err
+	}
+	_, err := s.SlogFormat()
+	return err
+}
+
+// PprofEnabled reports whether the admin-only /debug profiling routes are served
+func (s ServerConfig) PprofEnabled() bool {
+	return s.Mode == "debug" || s.EnablePprof
+}
+
+// CORSConfig controls which browser origins may call the API.
+// With allow_credentials enabled only the listed origins are reflected, "*" is never sent.
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins" json:"allowed_origins"`     // Exact origins, or "*" for any origin without credentials
+	AllowedMethods   []string `yaml:"allowed_methods" json:"allowed_methods"`     // Methods answered to preflight requests
+	AllowedHeaders   []string `yaml:"allowed_headers" json:"allowed_headers"`     // Request headers answered to preflight requests
+	AllowCredentials bool     `yaml:"allow_credentials" json:"allow_credentials"` // Allow cookies and Authorization headers
+	MaxAge           int      `yaml:"max_age" json:"max_age"`                     // Seconds browsers may cache a preflight response
+}
+
+// SecurityHeadersConfig tunes the security headers added to responses. An unset header uses its default,
+// an empty one is left out.
+type SecurityHeadersConfig struct {
+	Enabled               *bool   `yaml:"enabled" json:"enabled"`                                 // Unset adds the headers
+	ContentTypeOptions    *string `yaml:"content_type_options" json:"content_type_options"`       // X-Content-Type-Options, default nosniff
+	FrameOptions          *string `yaml:"frame_options" json:"frame_options"`                     // X-Frame-Options, default DENY
+	ContentSecurityPolicy *string `yaml:"content_security_policy" json:"content_security_policy"` // Default allows nothing to load and no framing
+	ReferrerPolicy        *string `yaml:"referrer_policy" json:"referrer_policy"`                 // Default no-referrer
+	HSTSMaxAge            int     `yaml:"hsts_max_age" json:"hsts_max_age"`                       // Seconds of Strict-Transport-Security sent over HTTPS, 0 uses one year, negative disables it
+	HSTSIncludeSubdomains bool    `yaml:"hsts_include_subdomains" json:"hsts_include_subdomains"`
+}
+
+// CompressionConfig tunes gzip/deflate compression of API responses
+type CompressionConfig struct {
+	Level   int `yaml:"level" json:"level"`       // 1 (fastest) to 9 (smallest), 0 uses the gzip default
+	MinSize int `yaml:"min_size" json:"min_size"` // Responses smaller than this many bytes are not compressed
 }
 
 type KubernetesConfig struct {
-	Kubeconfig string `yaml:"kubeconfig" json:"kubeconfig"`
+	Kubeconfig     string        `yaml:"kubeconfig" json:"kubeconfig"`
+	QPS            float32       `yaml:"qps" json:"qps"`                         // Client-side rate limit for each cluster's API server
+	Burst          int           `yaml:"burst" json:"burst"`                     // Requests allowed above QPS in short bursts
+	RequestTimeout time.Duration `yaml:"request_timeout" json:"request_timeout"` // Timeout of a single API server request including retries, streams are exempt
+	// MaxCachedClients bounds the cluster clients kept alive, the least recently used are dropped and rebuilt on demand
+	MaxCachedClients int `yaml:"max_cached_clients" json:"max_cached_clients"`
+	// ExecAllowlist names the exec credential plugins (e.g. aws, gke-gcloud-auth-plugin) kubeconfigs may run, as
+	// command names or absolute paths, "*" for any. Unset allows the plugins of the major cloud providers.
+	ExecAllowlist []string `yaml:"exec_allowlist" json:"exec_allowlist"`
+	// ExecEnvAllowlist names the environment variables the exec plugin of a kubeconfig may set, "*" for any.
+	// Unset allows the AWS profile and region variables of EKS kubeconfigs.
+	ExecEnvAllowlist []string `yaml:"exec_env_allowlist" json:"exec_env_allowlist"`
 }
 
 type InstallerConfig struct {
@@ -44,17 +234,123 @@ type InstallerConfig struct {
 
 type DatabaseConfig struct {
 	Enabled  bool   `yaml:"enabled" json:"enabled"`
-	Type     string `yaml:"type" json:"type"` // "mysql", "postgresql", "sqlite"
+	Type     string `yaml:"type" json:"type"` // "mysql", "postgresql", "sqlite", "mongodb"
 	Host     string `yaml:"host" json:"host"`
 	Port     int    `yaml:"port" json:"port"`
 	Username string `yaml:"username" json:"username"` // Ensure this is username
 	Password string `yaml:"password" json:"password"`
 	Database string `yaml:"database" json:"database"` // Ensure this is database
 	Charset  string `yaml:"charset" json:"charset"`
+
+	BusyTimeout int    `yaml:"busy_timeout" json:"busy_timeout"` // SQLite only: milliseconds to wait for a lock before failing, 0 uses 5000
+	URI         string `yaml:"uri" json:"uri"`                   // MongoDB only: connection string, overrides host, port and credentials
+
+	// TLS settings for PostgreSQL and MySQL. SSLMode takes the PostgreSQL values "disable", "allow",
+	// "prefer", "require", "verify-ca" and "verify-full"; empty keeps TLS off as before.
+	SSLMode     string `yaml:"ssl_mode" json:"ssl_mode"`
+	SSLRootCert string `yaml:"ssl_root_cert" json:"ssl_root_cert"` // CA certificate file used to verify the server
+	SSLCert     string `yaml:"ssl_cert" json:"ssl_cert"`           // Client certificate file
+	SSLKey      string `yaml:"ssl_key" json:"ssl_key"`             // Client private key file
+}
+
+// MySQLTLSConfigName is the name the database package registers the MySQL TLS settings under
+// when certificate files are configured
+const MySQLTLSConfigName = "cilikube"
+
+// PostgresDSN returns the PostgreSQL connection string
+func (d *DatabaseConfig) PostgresDSN() string {
+	sslMode := d.SSLMode
+	if sslMode == "" {
+		sslMode = "disable" // Backward compatible default
+	}
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		d.Host,
+		d.Port,
+		d.Username,
+		d.Password,
+		d.Database,
+		sslMode)
+	for _, param := range []struct{ key, value string }{
+		{"sslrootcert", d.SSLRootCert},
+		{"sslcert", d.SSLCert},
+		{"sslkey", d.SSLKey},
+	} {
+		if param.value != "" {
+			dsn += fmt.Sprintf(" %s=%s", param.key, quotePostgresValue(param.value))
+		}
+	}
+	return dsn
+}
+
+// quotePostgresValue quotes a connection string value that contains spaces, quotes or backslashes
+func quotePostgresValue(value string) string {
+	if !strings.ContainsAny(value, " '\\") {
+		return value
+	}
+	return "'" + strings.NewReplacer("\\", "\\\\", "'", "\\'").Replace(value) + "'"
+}
+
+// MySQLDSN returns the MySQL connection string
+func (d *DatabaseConfig) MySQLDSN() string {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=true",
+		d.Username,
+		d.Password,
+		d.Host,
+		d.Port,
+		d.Database,
+		d.Charset)
+	if tls := d.MySQLTLS(); tls != "" {
+		dsn += "&tls=" + tls
+	}
+	return dsn
+}
+
+// MySQLTLS maps SSLMode onto the MySQL driver's tls parameter. Certificate files need TLS settings
+// registered under MySQLTLSConfigName; without them the driver's built-in modes are used.
+func (d *DatabaseConfig) MySQLTLS() string {
+	if d.UsesMySQLTLSConfig() {
+		return MySQLTLSConfigName
+	}
+	switch d.SSLMode {
+	case "":
+		return "" // TLS stays off as before
+	case "disable", "false":
+		return "false"
+	case "allow", "prefer", "preferred":
+		return "preferred"
+	case "require", "skip-verify":
+		return "skip-verify"
+	default:
+		return "true"
+	}
+}
+
+// UsesMySQLTLSConfig reports whether MySQL TLS needs settings built from the certificate files
+func (d *DatabaseConfig) UsesMySQLTLSConfig() bool {
+	if d.SSLMode == "disable" || d.SSLMode == "false" {
+		return false
+	}
+	return d.SSLRootCert != "" || d.SSLCert != ""
+}
+
+// MongoURI returns the MongoDB connection string, built from the host, port and credentials unless URI is set
+func (d *DatabaseConfig) MongoURI() string {
+	if d.URI != "" {
+		return d.URI
+	}
+	host := d.Host
+	if d.Port != 0 {
+		host = fmt.Sprintf("%s:%d", d.Host, d.Port)
+	}
+	uri := url.URL{Scheme: "mongodb", Host: host, Path: "/" + d.Database}
+	if d.Username != "" {
+		uri.User = url.UserPassword(d.Username, d.Password)
+	}
+	return uri.String()
 }
 
 type StorageConfig struct {
-	Type     string          `yaml:"type" json:"type"` // "memory" or "database", optional, automatically determined based on database configuration by default
+	Type     string          `yaml:"type" json:"type"` // "memory", "database" or "mongodb", optional, automatically determined based on database configuration by default
 	Database *DatabaseConfig `yaml:"database" json:"database"`
 }
 
@@ -62,23 +358,105 @@ type OAuthConfig struct {
 	GitHub GitHubOAuthConfig `yaml:"github" json:"github"`
 }
 
+// EnabledProviders returns the OAuth providers users can log in with, those with client credentials set
+func (o OAuthConfig) EnabledProviders() []string {
+	providers := []string{}
+	if o.GitHub.ClientID != "" && o.GitHub.ClientSecret != "" {
+		providers = append(providers, "github")
+	}
+	return providers
+}
+
 type GitHubOAuthConfig struct {
 	ClientID     string `yaml:"client_id" json:"client_id"`
 	ClientSecret string `yaml:"client_secret" json:"client_secret"`
-	RedirectURL  string `yaml:"redirect_url" json:"redirect_url"`
+	RedirectURL  string `yaml:"redirect_url" json:"redirect_url"` // Unset uses the callback at the address and base path clients reach the server with
+}
+
+// AuthWebhookConfig delegates password logins to an external service that returns the user's identity and groups,
+// disabled by default so passwords are checked locally
+type AuthWebhookConfig struct {
+	Enabled bool          `yaml:"enabled" json:"enabled"`
+	URL     string        `yaml:"url" json:"url"`
+	Secret  string        `yaml:"secret" json:"-"`        // Shared secret for the X-Cilikube-Auth-Signature HMAC-SHA256 header
+	Timeout time.Duration `yaml:"timeout" json:"timeout"` // Timeout of a login request, 10s when unset
+
+	// GroupRoles gives the users of each group a role, synced on every login. The first matching entry is the primary role.
+	GroupRoles []GroupRoleMapping `yaml:"group_roles" json:"group_roles"`
+	// DefaultRole is given to users in none of the mapped groups, who are refused when it is empty
+	DefaultRole string `yaml:"default_role" json:"default_role"`
+}
+
+// GroupRoleMapping gives the members of an external group a cilikube role
+type GroupRoleMapping struct {
+	Group string `yaml:"group" json:"group"`
+	Role  string `yaml:"role" json:"role"`
+}
+
+// Validate checks that the url is set when the webhook is enabled and that every mapping names a group and a role
+func (a AuthWebhookConfig) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	if a.URL == "" {
+		return fmt.Errorf("auth_webhook.url is required when auth_webhook.enabled is set")
+	}
+	for i, mapping := range a.GroupRoles {
+		if mapping.Group == "" || mapping.Role == "" {
+			return fmt.Errorf("auth_webhook.group_roles[%d] needs both a group and a role", i)
+		}
+	}
+	return nil
 }
 
 type JWTConfig struct {
 	SecretKey      string        `yaml:"secret_key" json:"secret_key"`
 	ExpireDuration time.Duration `yaml:"expire_duration" json:"expire_duration"`
 	Issuer         string        `yaml:"issuer" json:"issuer"`
+
+	// Algorithm is "HS256" (default), signing with SecretKey, or "RS256", signing with PrivateKeyFile
+	Algorithm      string `yaml:"algorithm" json:"algorithm"`
+	PrivateKeyFile string `yaml:"private_key_file" json:"private_key_file"` // RS256 only: PEM RSA private key used to sign tokens
+	KeyID          string `yaml:"key_id" json:"key_id"`                     // RS256 only: kid of the signing key, derived from the key when empty
+	// PublicKeys verify tokens in addition to the signing key; keep retired keys here after a rotation
+	// so tokens they signed stay valid until they expire
+	PublicKeys []JWTPublicKey `yaml:"public_keys" json:"public_keys"`
+}
+
+// Validate checks that the signing algorithm is one cilikube supports, so a typo stops the server
+// instead of silently signing tokens with HS256
+func (j JWTConfig) Validate() error {
+	switch j.Algorithm {
+	case "HS256", "RS256":
+		return nil
+	}
+	return fmt.Errorf("jwt.algorithm %q is not supported, use HS256 or RS256", j.Algorithm)
+}
+
+// JWTPublicKey is an RS256 verification key
+type JWTPublicKey struct {
+	KeyID string `yaml:"key_id" json:"key_id"` // Derived from the key when empty
+	File  string `yaml:"file" json:"file"`     // PEM public key or certificate
 }
 
 type SecurityConfig struct {
-	Password    PasswordConfig    `yaml:"password" json:"password"`
-	AccountLock AccountLockConfig `yaml:"account_lock" json:"account_lock"`
-	Session     SessionConfig     `yaml:"session" json:"session"`
-	RateLimit   RateLimitConfig   `yaml:"rate_limit" json:"rate_limit"`
+	AllowRegistration *bool         `yaml:"allow_registration" json:"allow_registration"` // Self-service sign-up with a password, open when unset
+	InviteTTL         time.Duration `yaml:"invite_ttl" json:"invite_ttl"`                 // How long user invites stay valid, 72h when unset
+
+	Password      PasswordConfig      `yaml:"password" json:"password"`
+	AccountLock   AccountLockConfig   `yaml:"account_lock" json:"account_lock"`
+	Session       SessionConfig       `yaml:"session" json:"session"`
+	RateLimit     RateLimitConfig     `yaml:"rate_limit" json:"rate_limit"`
+	GeoIP         GeoIPConfig         `yaml:"geoip" json:"geoip"`
+	AuditWebhook  AuditWebhookConfig  `yaml:"audit_webhook" json:"audit_webhook"`
+	ImageScan     ImageScanConfig     `yaml:"image_scan" json:"image_scan"`
+	Cleanup       CleanupConfig       `yaml:"cleanup" json:"cleanup"`
+	InactiveUsers InactiveUsersConfig `yaml:"inactive_users" json:"inactive_users"`
+}
+
+// RegistrationEnabled reports whether visitors may create an account with /auth/register
+func (s SecurityConfig) RegistrationEnabled() bool {
+	return s.AllowRegistration == nil || *s.AllowRegistration
 }
 
 type PasswordConfig struct {
@@ -113,6 +491,155 @@ type RateLimitConfig struct {
 	BurstSize     int           `yaml:"burst_size" json:"burst_size"`         // Allow burst requests
 }
 
+type MonitoringConfig struct {
+	Thresholds   MonitoringThresholds `yaml:"thresholds" json:"thresholds"`
+	Alertmanager AlertManagerConfig   `yaml:"alertmanager" json:"alertmanager"`
+}
+
+// AlertManagerConfig forwards monitoring alerts to a Prometheus Alertmanager, disabled when url is empty
+type AlertManagerConfig struct {
+	URL       string        `yaml:"url" json:"url"`               // Base URL of the Alertmanager, e.g. http://alertmanager:9093
+	Timeout   time.Duration `yaml:"timeout" json:"timeout"`       // Timeout of a single delivery, 10s when unset
+	QueueSize int           `yaml:"queue_size" json:"queue_size"` // Alerts waiting for delivery, beyond which new ones are dropped, 100 when unset
+}
+
+// MonitoringThresholds are the rates above which the monitoring service raises alerts
+type MonitoringThresholds struct {
+	FailedLoginsPerMinute     float64 `yaml:"failed_logins_per_minute" json:"failed_logins_per_minute"`
+	PermissionDenialsPerHour  int     `yaml:"permission_denials_per_hour" json:"permission_denials_per_hour"`
+	SecurityViolationsPerHour int     `yaml:"security_violations_per_hour" json:"security_violations_per_hour"`
+}
+
+// DefaultMonitoringThresholds returns the thresholds used when none are configured
+func DefaultMonitoringThresholds() MonitoringThresholds {
+	return MonitoringThresholds{
+		FailedLoginsPerMinute:     10,
+		PermissionDenialsPerHour:  50,
+		SecurityViolationsPerHour: 5,
+	}
+}
+
+// WithDefaults returns a copy of the thresholds with unset values replaced by their defaults
+func (t MonitoringThresholds) WithDefaults() MonitoringThresholds {
+	defaults := DefaultMonitoringThresholds()
+	if t.FailedLoginsPerMinute == 0 {
+		t.FailedLoginsPerMinute = defaults.FailedLoginsPerMinute
+	}
+	if t.PermissionDenialsPerHour == 0 {
+		t.PermissionDenialsPerHour = defaults.PermissionDenialsPerHour
+	}
+	if t.SecurityViolationsPerHour == 0 {
+		t.SecurityViolationsPerHour = defaults.SecurityViolationsPerHour
+	}
+	return t
+}
+
+// Validate checks that all thresholds are positive
+func (t MonitoringThresholds) Validate() error {
+	if t.FailedLoginsPerMinute <= 0 {
+		return fmt.Errorf("monitoring.thresholds.failed_logins_per_minute must be positive, got %v", t.FailedLoginsPerMinute)
+	}
+	if t.PermissionDenialsPerHour <= 0 {
+		return fmt.Errorf("monitoring.thresholds.permission_denials_per_hour must be positive, got %d", t.PermissionDenialsPerHour)
+	}
+	if t.SecurityViolationsPerHour <= 0 {
+		return fmt.Errorf("monitoring.thresholds.security_violations_per_hour must be positive, got %d", t.SecurityViolationsPerHour)
+	}
+	return nil
+}
+
+// GeoIPConfig points to MaxMind databases used to enrich audit logs, enrichment is disabled when both are empty
+type GeoIPConfig struct {
+	CountryDatabase string `yaml:"country_database" json:"country_database"` // GeoLite2-Country or GeoLite2-City mmdb
+	ASNDatabase     string `yaml:"asn_database" json:"asn_database"`         // GeoLite2-ASN mmdb
+
+	// MaxTravelSpeedKmh is the fastest plausible travel speed between two successful logins of a user,
+	// faster movement is reported as impossible travel. Requires a GeoLite2-City country database.
+	MaxTravelSpeedKmh float64 `yaml:"max_travel_speed_kmh" json:"max_travel_speed_kmh"`
+}
+
+// AuditWebhookConfig forwards audit events to an external endpoint such as a SIEM, disabled by default
+type AuditWebhookConfig struct {
+	Enabled    bool          `yaml:"enabled" json:"enabled"`
+	URL        string        `yaml:"url" json:"url"`
+	Secret     string        `yaml:"secret" json:"-"`                // Shared secret for the X-Cilikube-Signature HMAC-SHA256 header
+	QueueSize  int           `yaml:"queue_size" json:"queue_size"`   // Events buffered before new ones are dropped
+	MaxRetries int           `yaml:"max_retries" json:"max_retries"` // Retries after the first failed delivery, 0 disables retries
+	Timeout    time.Duration `yaml:"timeout" json:"timeout"`         // Timeout of a single delivery attempt
+}
+
+// ImageScanConfig selects the vulnerability scanner behind the pod image scan endpoint, disabled by default
+type ImageScanConfig struct {
+	Scanner       string        `yaml:"scanner" json:"scanner"`               // "trivy", empty disables scanning
+	TrivyPath     string        `yaml:"trivy_path" json:"trivy_path"`         // Trivy binary, looked up in PATH when empty
+	ServerURL     string        `yaml:"server_url" json:"server_url"`         // Trivy server to scan against in client mode, scans locally when empty
+	Timeout       time.Duration `yaml:"timeout" json:"timeout"`               // Timeout of the scan of a pod's images, 5 minutes when unset
+	CacheTTL      time.Duration `yaml:"cache_ttl" json:"cache_ttl"`           // How long results are reused for the same image digest
+	MaxConcurrent int           `yaml:"max_concurrent" json:"max_concurrent"` // Image scans running at once across all requests, 2 when unset
+}
+
+// CleanupConfig controls the background job removing expired sessions and old login attempts from the store
+type CleanupConfig struct {
+	Interval              time.Duration `yaml:"interval" json:"interval"`                               // How often cleanup runs, hourly when unset
+	LoginAttemptRetention time.Duration `yaml:"login_attempt_retention" json:"login_attempt_retention"` // Older login attempts are removed, 30 days when unset
+}
+
+// InactiveUsersConfig controls the background job deactivating accounts that haven't logged in for a while, disabled by default.
+// Admins are never deactivated.
+type InactiveUsersConfig struct {
+	Enabled      bool          `yaml:"enabled" json:"enabled"`
+	InactiveDays int           `yaml:"inactive_days" json:"inactive_days"` // Days without a login, counted from creation for users who never logged in, 90 when unset
+	Interval     time.Duration `yaml:"interval" json:"interval"`           // How often accounts are checked, daily when unset
+	DryRun       bool          `yaml:"dry_run" json:"dry_run"`             // Only log the accounts that would be deactivated
+	ExemptUsers  []string      `yaml:"exempt_users" json:"exempt_users"`   // Usernames never deactivated, such as system and service accounts
+}
+
+// Bootstrap admin settings used when neither the configuration nor the environment sets them
+const (
+	DefaultAdminUsername = "admin"
+	DefaultAdminEmail    = "admin@cilikube.com"
+	DefaultAdminPassword = "12345678" // Insecure, refused in release mode
+)
+
+// AdminConfig is the admin account created on first start, when the store has no users yet.
+// Unset fields are read from CILIKUBE_ADMIN_USERNAME, CILIKUBE_ADMIN_EMAIL and CILIKUBE_ADMIN_PASSWORD.
+type AdminConfig struct {
+	Username string `yaml:"username" json:"username"`
+	Email    string `yaml:"email" json:"email"`
+	Password string `yaml:"password" json:"-"`
+}
+
+// WithDefaults returns the settings with unset fields read from the environment or set to the defaults.
+// It is applied when the admin is created rather than on load, so the password is never saved to the config file.
+func (a AdminConfig) WithDefaults() AdminConfig {
+	fields := []struct {
+		value    *string
+		env      string
+		fallback string
+	}{
+		{&a.Username, "CILIKUBE_ADMIN_USERNAME", DefaultAdminUsername},
+		{&a.Email, "CILIKUBE_ADMIN_EMAIL", DefaultAdminEmail},
+		{&a.Password, "CILIKUBE_ADMIN_PASSWORD", DefaultAdminPassword},
+	}
+	for _, field := range fields {
+		if *field.value == "" {
+			*field.value = os.Getenv(field.env)
+		}
+		if *field.value == "" {
+			*field.value = field.fallback
+		}
+	}
+	return a
+}
+
+// Validate refuses the insecure default password in release mode
+func (a AdminConfig) Validate(mode string) error {
+	if mode == "release" && a.Password == DefaultAdminPassword {
+		return fmt.Errorf("the default admin password can't be used in release mode, set admin.password or CILIKUBE_ADMIN_PASSWORD")
+	}
+	return nil
+}
+
 type ClusterInfo struct {
 	// ID is the unique identifier for the cluster, using UUID format
 	// If empty, the system will automatically generate a UUID
@@ -141,6 +668,10 @@ type ClusterInfo struct {
 
 	// Labels custom labels for grouping and filtering
 	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	// QPS and Burst override kubernetes.qps and kubernetes.burst for this cluster when positive
+	QPS   float32 `yaml:"qps,omitempty" json:"qps,omitempty"`
+	Burst int     `yaml:"burst,omitempty" json:"burst,omitempty"`
 }
 
 var GlobalConfig *Config
@@ -176,6 +707,28 @@ func Load(path string) (*Config, error) {
 	GlobalConfig = cfg
 	setDefaults()
 
+	if err := cfg.Monitoring.Thresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.Server.ValidateTrustedProxies(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.Server.ValidateExternalURL(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.Server.ValidateLogging(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.Server.TLS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.AuthWebhook.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.JWT.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	return cfg, nil
 }
 
@@ -186,20 +739,19 @@ func loadViperConfig(path string) (*Config, error) {
 	// Set configuration file path and name
 	v.SetConfigFile(path)
 
-	// Set environment variable prefix
+	// Environment variables override the keys of the file, e.g. CILIKUBE_SERVER_PORT for server.port
 	v.SetEnvPrefix("CILIKUBE")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
-	// Set field name mapping so viper can correctly map fields
-	v.RegisterAlias("server.activeCluster", "server.activeClusterID")
-
 	// Read configuration file
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("viper failed to read configuration file %s: %w", path, err)
 	}
 
+	// Decode by the yaml tags, the keys the configuration file uses, rather than by field name
 	cfg := &Config{}
-	if err := v.Unmarshal(cfg); err != nil {
+	if err := v.Unmarshal(cfg, func(dc *mapstructure.DecoderConfig) { dc.TagName = "yaml" }); err != nil {
 		return nil, fmt.Errorf("viper failed to parse configuration file: %w", err)
 	}
 
@@ -274,6 +826,12 @@ func setDefaults() {
 	if GlobalConfig.Server.WriteTimeout == 0 {
 		GlobalConfig.Server.WriteTimeout = 30
 	}
+	if GlobalConfig.Server.Pagination.DefaultSize <= 0 {
+		GlobalConfig.Server.Pagination.DefaultSize = 20
+	}
+	if GlobalConfig.Server.Pagination.MaxSize <= 0 {
+		GlobalConfig.Server.Pagination.MaxSize = 100
+	}
 	// ... (other default value settings for database, jwt, installer, kubernetes remain unchanged) ...
 	if GlobalConfig.Database.Enabled { // Fix: only set database default values when enabled
 		// Set default database type if not specified
@@ -327,24 +885,36 @@ func setDefaults() {
 		}
 	}
 
-	if GlobalConfig.JWT.SecretKey == "" {
-		GlobalConfig.JWT.SecretKey = os.Getenv("JWT_SECRET")
-		if GlobalConfig.JWT.SecretKey == "" {
-			GlobalConfig.JWT.SecretKey = "cilikube-secret-key-change-in-production"
+	// A secret from the environment wins over the configuration file, so deployments don't need to edit it
+	for _, env := range []string{"JWT_SECRET", "CILIKUBE_JWT_SECRET"} {
+		if secret := os.Getenv(env); secret != "" {
+			GlobalConfig.JWT.SecretKey = secret
+			break
 		}
 	}
+	if GlobalConfig.JWT.SecretKey == "" {
+		GlobalConfig.JWT.SecretKey = "cilikube-secret-key-change-in-production"
+	}
 	if GlobalConfig.JWT.ExpireDuration == 0 {
 		GlobalConfig.JWT.ExpireDuration = 24 * time.Hour
 	}
 	if GlobalConfig.JWT.Issuer == "" {
 		GlobalConfig.JWT.Issuer = "cilikube"
 	}
+	GlobalConfig.JWT.Algorithm = strings.ToUpper(GlobalConfig.JWT.Algorithm)
+	if GlobalConfig.JWT.Algorithm == "" {
+		GlobalConfig.JWT.Algorithm = "HS256"
+	}
 	if GlobalConfig.Installer.MinikubeDriver == "" {
 		GlobalConfig.Installer.MinikubeDriver = "docker"
 	}
 	if GlobalConfig.Installer.DownloadDir == "" {
 		GlobalConfig.Installer.DownloadDir = "."
 	}
+	// kubernetes.qps, burst and request_timeout default in k8s.DefaultClientOptions
+	if GlobalConfig.Kubernetes.MaxCachedClients == 0 {
+		GlobalConfig.Kubernetes.MaxCachedClients = 20
+	}
 	if GlobalConfig.Kubernetes.Kubeconfig == "" || GlobalConfig.Kubernetes.Kubeconfig == "default" {
 		if kubeconfigEnv := os.Getenv("KUBECONFIG"); kubeconfigEnv != "" {
 			GlobalConfig.Kubernetes.Kubeconfig = kubeconfigEnv
@@ -386,6 +956,9 @@ func setDefaults() {
 	// Set security configuration defaults
 	setSecurityDefaults()
 
+	// Set monitoring configuration defaults
+	GlobalConfig.Monitoring.Thresholds = GlobalConfig.Monitoring.Thresholds.WithDefaults()
+
 	// If new ID was generated or active cluster was updated, save configuration file
 	if configChanged {
 		_ = SaveGlobalConfig() // Ignore errors as this is optional
@@ -399,34 +972,16 @@ func (c *Config) GetDSN() string {
 
 	// Support different database types
 	switch c.Database.Type {
+	case "mongodb":
+		return c.Database.MongoURI()
 	case "sqlite":
 		return c.Database.Database // For SQLite, database field contains the file path
 	case "postgresql", "postgres":
 		// PostgreSQL DSN format
-		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-			c.Database.Host,
-			c.Database.Port,
-			c.Database.Username,
-			c.Database.Password,
-			c.Database.Database)
-	case "mysql", "":
-		// Default to MySQL format for backward compatibility
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=true",
-			c.Database.Username,
-			c.Database.Password,
-			c.Database.Host,
-			c.Database.Port,
-			c.Database.Database,
-			c.Database.Charset)
+		return c.Database.PostgresDSN()
 	default:
-		// Default to MySQL format
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=true",
-			c.Database.Username,
-			c.Database.Password,
-			c.Database.Host,
-			c.Database.Port,
-			c.Database.Database,
-			c.Database.Charset)
+		// Default to MySQL format for backward compatibility
+		return c.Database.MySQLDSN()
 	}
 }
 
@@ -467,14 +1022,9 @@ func setStorageDefaults() {
 	}
 
 	// If no storage database configuration is specified, use global database configuration
-	if GlobalConfig.Storage.Database == nil && GlobalConfig.Storage.Type == "database" {
+	if GlobalConfig.Storage.Database == nil && (GlobalConfig.Storage.Type == "database" || GlobalConfig.Storage.Type == "mongodb") {
 		GlobalConfig.Storage.Database = &GlobalConfig.Database
 	}
-
-	// Set OAuth default configuration
-	if GlobalConfig.OAuth.GitHub.RedirectURL == "" {
-		GlobalConfig.OAuth.GitHub.RedirectURL = "http://localhost:8080/api/v1/auth/oauth/callback"
-	}
 }
 
 // DetermineStorageType automatically determines storage type based on configuration
@@ -498,6 +1048,14 @@ func DetermineStorageType(config *StorageConfig) string {
 		return "memory"
 	}
 
+	// MongoDB has its own store and needs either a connection string or a host
+	if dbConfig.Type == "mongodb" {
+		if dbConfig.URI == "" && dbConfig.Host == "" {
+			return "memory"
+		}
+		return "mongodb"
+	}
+
 	// For SQLite, only need to check the database field (file path)
 	if dbConfig.Type == "sqlite" {
 		if dbConfig.Database == "" {
@@ -520,51 +1078,37 @@ func (c *Config) GetStorageType() string {
 	return DetermineStorageType(&c.Storage)
 }
 
+// GetStorageDatabase returns the database settings used by the store
+func (c *Config) GetStorageDatabase() *DatabaseConfig {
+	if c.Storage.Database != nil {
+		return c.Storage.Database
+	}
+	return &c.Database
+}
+
 // GetStorageDSN returns the DSN for storage database connection
 func (c *Config) GetStorageDSN() string {
-	if c.Storage.Type != "database" {
+	if c.Storage.Type != "database" && c.Storage.Type != "mongodb" {
 		return ""
 	}
 
-	dbConfig := c.Storage.Database
-	if dbConfig == nil {
-		dbConfig = &c.Database
-	}
-
+	dbConfig := c.GetStorageDatabase()
 	if !dbConfig.Enabled {
 		return ""
 	}
 
 	// Support different database types for storage
 	switch dbConfig.Type {
+	case "mongodb":
+		return dbConfig.MongoURI()
 	case "sqlite":
 		return dbConfig.Database // For SQLite, database field contains the file path
 	case "postgresql", "postgres":
 		// PostgreSQL DSN format
-		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-			dbConfig.Host,
-			dbConfig.Port,
-			dbConfig.Username,
-			dbConfig.Password,
-			dbConfig.Database)
-	case "mysql", "":
-		// Default to MySQL format for backward compatibility
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=true",
-			dbConfig.Username,
-			dbConfig.Password,
-			dbConfig.Host,
-			dbConfig.Port,
-			dbConfig.Database,
-			dbConfig.Charset)
+		return dbConfig.PostgresDSN()
 	default:
-		// Default to MySQL format
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=true",
-			dbConfig.Username,
-			dbConfig.Password,
-			dbConfig.Host,
-			dbConfig.Port,
-			dbConfig.Database,
-			dbConfig.Charset)
+		// Default to MySQL format for backward compatibility
+		return dbConfig.MySQLDSN()
 	}
 }
 
@@ -605,6 +1149,11 @@ func setSecurityDefaults() {
 		GlobalConfig.Security.Session.AbsoluteTimeout = 8 * time.Hour
 	}
 
+	// Impossible travel detection defaults, roughly the cruising speed of a commercial airliner
+	if GlobalConfig.Security.GeoIP.MaxTravelSpeedKmh == 0 {
+		GlobalConfig.Security.GeoIP.MaxTravelSpeedKmh = 1000
+	}
+
 	// Rate limiting defaults
 	if GlobalConfig.Security.RateLimit.LoginAttempts == 0 {
 		GlobalConfig.Security.RateLimit.LoginAttempts = 10
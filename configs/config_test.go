@@ -0,0 +1,250 @@
+package configs
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postgresConfig() *Config {
+	return &Config{Database: DatabaseConfig{
+		Enabled:  true,
+		Type:     "postgresql",
+		Host:     "db.example.com",
+		Port:     5432,
+		Username: "cilikube",
+		Password: "secret",
+		Database: "cilikube",
+	}}
+}
+
+func TestGetDSN_PostgresSSL(t *testing.T) {
+	t.Run("defaults to disable", func(t *testing.T) {
+		assert.Equal(t,
+			"host=db.example.com port=5432 user=cilikube password=secret dbname=cilikube sslmode=disable",
+			postgresConfig().GetDSN())
+	})
+
+	t.Run("configured mode and certificates", func(t *testing.T) {
+		cfg := postgresConfig()
+		cfg.Database.SSLMode = "verify-full"
+		cfg.Database.SSLRootCert = "/etc/ssl/ca.pem"
+		cfg.Database.SSLCert = "/etc/ssl/client.pem"
+		cfg.Database.SSLKey = "/etc/ssl/my keys/client.key"
+
+		dsn := cfg.GetDSN()
+		assert.Contains(t, dsn, "sslmode=verify-full")
+		assert.NotContains(t, dsn, "sslmode=disable")
+		assert.Contains(t, dsn, "sslrootcert=/etc/ssl/ca.pem")
+		assert.Contains(t, dsn, "sslcert=/etc/ssl/client.pem")
+		assert.Contains(t, dsn, "sslkey='/etc/ssl/my keys/client.key'")
+	})
+
+	t.Run("storage database", func(t *testing.T) {
+		cfg := &Config{Storage: StorageConfig{Type: "database", Database: &postgresConfig().Database}}
+		cfg.Storage.Database.SSLMode = "require"
+		assert.Contains(t, cfg.GetStorageDSN(), "sslmode=require")
+	})
+}
+
+func TestGetDSN_MySQLTLS(t *testing.T) {
+	mysqlConfig := func(mode, rootCert string) *Config {
+		return &Config{Database: DatabaseConfig{
+			Enabled:     true,
+			Type:        "mysql",
+			Host:        "db.example.com",
+			Port:        3306,
+			Username:    "root",
+			Password:    "secret",
+			Database:    "cilikube",
+			Charset:     "utf8mb4",
+			SSLMode:     mode,
+			SSLRootCert: rootCert,
+		}}
+	}
+
+	assert.Equal(t, "root:secret@tcp(db.example.com:3306)/cilikube?charset=utf8mb4&parseTime=true",
+		mysqlConfig("", "").GetDSN(), "no tls parameter when unset")
+
+	tests := []struct {
+		mode, rootCert, want string
+	}{
+		{"disable", "", "&tls=false"},
+		{"prefer", "", "&tls=preferred"},
+		{"require", "", "&tls=skip-verify"},
+		{"verify-full", "", "&tls=true"},
+		{"verify-ca", "/etc/ssl/ca.pem", "&tls=" + MySQLTLSConfigName},
+		{"", "/etc/ssl/ca.pem", "&tls=" + MySQLTLSConfigName},
+		{"disable", "/etc/ssl/ca.pem", "&tls=false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+tt.rootCert, func(t *testing.T) {
+			cfg := mysqlConfig(tt.mode, tt.rootCert)
+			assert.Contains(t, cfg.GetDSN(), tt.want)
+
+			cfg.Storage = StorageConfig{Type: "database", Database: &cfg.Database}
+			assert.Contains(t, cfg.GetStorageDSN(), tt.want)
+		})
+	}
+}
+
+func TestServerConfig_SwaggerEnabled(t *testing.T) {
+	enabled, disabled := true, false
+
+	assert.True(t, ServerConfig{Mode: "debug"}.SwaggerEnabled())
+	assert.False(t, ServerConfig{Mode: "release"}.SwaggerEnabled(), "docs are off in release mode by default")
+	assert.True(t, ServerConfig{Mode: "release", Swagger: SwaggerConfig{Enabled: &enabled}}.SwaggerEnabled())
+	assert.False(t, ServerConfig{Mode: "debug", Swagger: SwaggerConfig{Enabled: &disabled}}.SwaggerEnabled())
+}
+
+func TestServerConfig_RoutePrefix(t *testing.T) {
+	for basePath, want := range map[string]string{
+		"":            "",
+		"/":           "",
+		"/cilikube":   "/cilikube",
+		"cilikube/":   "/cilikube",
+		" /apps/ck/ ": "/apps/ck",
+	} {
+		assert.Equal(t, want, ServerConfig{BasePath: basePath}.RoutePrefix(), basePath)
+	}
+}
+
+func TestServerConfig_ValidateTrustedProxies(t *testing.T) {
+	assert.NoError(t, ServerConfig{}.ValidateTrustedProxies())
+	assert.NoError(t, ServerConfig{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.10", "fd00::/8", "::1"}}.ValidateTrustedProxies())
+	assert.ErrorContains(t, ServerConfig{TrustedProxies: []string{"10.0.0.0/8", "lb.internal"}}.ValidateTrustedProxies(), "lb.internal")
+	assert.Error(t, ServerConfig{TrustedProxies: []string{"10.0.0.0/33"}}.ValidateTrustedProxies())
+}
+
+func TestServerConfig_ValidateExternalURL(t *testing.T) {
+	assert.NoError(t, ServerConfig{}.ValidateExternalURL())
+	assert.NoError(t, ServerConfig{ExternalURL: "https://ops.example.com/cilikube"}.ValidateExternalURL())
+	assert.Error(t, ServerConfig{ExternalURL: "ops.example.com"}.ValidateExternalURL())
+	assert.Error(t, ServerConfig{ExternalURL: "javascript:alert(1)"}.ValidateExternalURL())
+}
+
+func TestServerConfig_Logging(t *testing.T) {
+	t.Setenv("CILIKUBE_LOG_LEVEL", "")
+	t.Setenv("CILIKUBE_LOG_FORMAT", "")
+
+	level, err := ServerConfig{Mode: "debug"}.SlogLevel()
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+	level, err = ServerConfig{Mode: "release"}.SlogLevel()
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelInfo, level)
+	level, err = ServerConfig{Mode: "debug", LogLevel: "warn"}.SlogLevel()
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level, "the configured level wins over the mode")
+
+	format, err := ServerConfig{}.SlogFormat()
+	require.NoError(t, err)
+	assert.Equal(t, LogFormatJSON, format)
+	format, err = ServerConfig{LogFormat: "TEXT"}.SlogFormat()
+	require.NoError(t, err)
+	assert.Equal(t, LogFormatText, format)
+
+	assert.Error(t, ServerConfig{LogLevel: "verbose"}.ValidateLogging())
+	assert.Error(t, ServerConfig{LogFormat: "logfmt"}.ValidateLogging())
+
+	t.Setenv("CILIKUBE_LOG_LEVEL", "error")
+	t.Setenv("CILIKUBE_LOG_FORMAT", "text")
+	server := ServerConfig{LogLevel: "debug", LogFormat: "json"}
+	level, _ = server.SlogLevel()
+	format, _ = server.SlogFormat()
+	assert.Equal(t, slog.LevelError, level, "the environment overrides the file")
+	assert.Equal(t, LogFormatText, format)
+}
+
+func TestSecurityConfig_RegistrationEnabled(t *testing.T) {
+	open, closed := true, false
+	assert.True(t, SecurityConfig{}.RegistrationEnabled(), "registration is open unless turned off")
+	assert.True(t, SecurityConfig{AllowRegistration: &open}.RegistrationEnabled())
+	assert.False(t, SecurityConfig{AllowRegistration: &closed}.RegistrationEnabled())
+}
+
+func TestOAuthConfig_EnabledProviders(t *testing.T) {
+	assert.Empty(t, OAuthConfig{}.EnabledProviders())
+	assert.Empty(t, OAuthConfig{GitHub: GitHubOAuthConfig{ClientID: "id"}}.EnabledProviders(), "a provider needs its secret too")
+	assert.Equal(t, []string{"github"}, OAuthConfig{GitHub: GitHubOAuthConfig{ClientID: "id", ClientSecret: "secret"}}.EnabledProviders())
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	assert.NoError(t, TLSConfig{}.Validate())
+	assert.NoError(t, TLSConfig{Enabled: true, CertFile: "tls.crt", KeyFile: "tls.key"}.Validate())
+	assert.Error(t, TLSConfig{Enabled: true, CertFile: "tls.crt"}.Validate())
+}
+
+func TestAuthWebhookConfig_Validate(t *testing.T) {
+	assert.NoError(t, AuthWebhookConfig{}.Validate())
+	assert.NoError(t, AuthWebhookConfig{Enabled: true, URL: "https://idp.internal/login", GroupRoles: []GroupRoleMapping{{Group: "ops", Role: "admin"}}}.Validate())
+	assert.Error(t, AuthWebhookConfig{Enabled: true}.Validate())
+	assert.Error(t, AuthWebhookConfig{Enabled: true, URL: "https://idp.internal/login", GroupRoles: []GroupRoleMapping{{Group: "ops"}}}.Validate())
+}
+
+func TestJWTConfig_Validate(t *testing.T) {
+	assert.NoError(t, JWTConfig{Algorithm: "HS256"}.Validate())
+	assert.NoError(t, JWTConfig{Algorithm: "RS256"}.Validate())
+	assert.Error(t, JWTConfig{Algorithm: "ES256"}.Validate(), "unknown algorithms do not fall back to HS256")
+}
+
+func TestLoad_FileKeys(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("CILIKUBE_JWT_SECRET", "")
+	previous := GlobalConfig
+	t.Cleanup(func() { GlobalConfig = previous })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+server:
+    port: "8080"
+    read_timeout: 45
+    activeCluster: prod
+    base_path: /cilikube
+    enable_pprof: true
+    trusted_proxies:
+        - 10.0.0.0/8
+    security_headers:
+        content_security_policy: ""
+jwt:
+    secret_key: from-file
+`), 0o600))
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 45, cfg.Server.ReadTimeout, "multi-word keys are read by their yaml names")
+	assert.Equal(t, "prod", cfg.Server.ActiveClusterID)
+	assert.Equal(t, "/cilikube", cfg.Server.RoutePrefix())
+	assert.True(t, cfg.Server.EnablePprof)
+	assert.Equal(t, []string{"10.0.0.0/8"}, cfg.Server.TrustedProxies)
+	if assert.NotNil(t, cfg.Server.SecurityHeaders.ContentSecurityPolicy, "an empty header is kept apart from an unset one") {
+		assert.Empty(t, *cfg.Server.SecurityHeaders.ContentSecurityPolicy)
+	}
+	assert.Nil(t, cfg.Server.SecurityHeaders.FrameOptions)
+	assert.Equal(t, "from-file", cfg.JWT.SecretKey)
+
+	t.Setenv("JWT_SECRET", "from-env")
+	cfg, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cfg.JWT.SecretKey, "the environment overrides the secret of the file")
+}
+
+func TestAdminConfig(t *testing.T) {
+	t.Setenv("CILIKUBE_ADMIN_USERNAME", "")
+	t.Setenv("CILIKUBE_ADMIN_EMAIL", "ops@example.com")
+	t.Setenv("CILIKUBE_ADMIN_PASSWORD", "from-env")
+
+	admin := AdminConfig{Password: "from-config"}.WithDefaults()
+	assert.Equal(t, AdminConfig{Username: DefaultAdminUsername, Email: "ops@example.com", Password: "from-config"}, admin)
+
+	t.Setenv("CILIKUBE_ADMIN_PASSWORD", "")
+	admin = AdminConfig{}.WithDefaults()
+	assert.Equal(t, DefaultAdminPassword, admin.Password)
+	assert.NoError(t, admin.Validate("debug"))
+	assert.Error(t, admin.Validate("release"), "release mode refuses the default password")
+	assert.NoError(t, AdminConfig{Password: "s3cure-password"}.Validate("release"))
+}
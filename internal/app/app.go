@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
 	"fmt"
@@ -21,15 +22,29 @@ import (
 	"github.com/ciliverse/cilikube/internal/service"
 	"github.com/ciliverse/cilikube/internal/store"
 	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/certwatch"
 	"github.com/ciliverse/cilikube/pkg/database"
 	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
 )
 
+// seedPath is a JSON file of clusters, roles and users loaded into the store at startup, see store.SeedData
+var seedPath = flag.String("seed", "", "JSON file of clusters, roles and users to seed the store with")
+
 type Application struct {
-	Config *configs.Config
-	Logger *slog.Logger
-	Router *gin.Engine
-	Server *http.Server
+	Config  *configs.Config
+	Logger  *slog.Logger
+	Router  *gin.Engine
+	Handler http.Handler // Router served below server.base_path
+	Server  *http.Server
+	Janitor *service.JanitorService
+	Audit   *service.AuditService // Closed on shutdown to flush the audit webhook queue
+
+	Monitoring *service.MonitoringService // Stopped on shutdown to flush the Alertmanager queue
+
+	InactiveUsers *service.InactiveUserService // Deactivates unused accounts when security.inactive_users is enabled
+
+	Certificates *certwatch.Reloader // Set when server.tls is enabled
 }
 
 func New(configPath string) (*Application, error) {
@@ -40,14 +55,10 @@ func New(configPath string) (*Application, error) {
 	}
 
 	// --- 2. Initialize logger ---
-	var logLevel slog.Level
-	if cfg.Server.Mode == "debug" {
-		logLevel = slog.LevelDebug
-	} else {
-		logLevel = slog.LevelInfo
-	}
-
-	appLogger := logger.New(logLevel)
+	// Both were validated by configs.Load
+	logLevel, _ := cfg.Server.SlogLevel()
+	logFormat, _ := cfg.Server.SlogFormat()
+	appLogger := logger.New(os.Stdout, logLevel, logFormat)
 	slog.SetDefault(appLogger)
 
 	// --- 3. Configuration loaded ---
@@ -56,8 +67,9 @@ func New(configPath string) (*Application, error) {
 	// --- 4. Database and Store initialization ---
 	slog.Info("initializing storage system...")
 
-	// Initialize database if enabled
-	if cfg.Database.Enabled {
+	// Initialize the SQL database if enabled; MongoDB is connected by its store instead
+	sqlDatabase := cfg.Database.Enabled && cfg.Database.Type != "mongodb"
+	if sqlDatabase {
 		slog.Info("database enabled, initializing...")
 		if err := database.InitDatabase(); err != nil {
 			return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -82,6 +94,23 @@ func New(configPath string) (*Application, error) {
 
 	slog.Info("storage system initialized successfully", "type", cfg.GetStorageType())
 
+	// Seed before the cluster manager starts, so seeded clusters are registered
+	if *seedPath != "" {
+		data, err := store.LoadSeedFile(*seedPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.Seed(mainStore, data); err != nil {
+			return nil, fmt.Errorf("failed to seed store: %w", err)
+		}
+		slog.Info("store seeded", "path", *seedPath, "clusters", len(data.Clusters), "roles", len(data.Roles), "users", len(data.Users))
+	}
+
+	// Load the JWT signing keys so a bad key file stops startup
+	if err := auth.InitJWTKeys(cfg.JWT); err != nil {
+		return nil, err
+	}
+
 	// --- 5. Initialize ClusterManager ---
 	k8sManager, err := k8s.NewClusterManager(mainStore, cfg)
 	if err != nil {
@@ -98,9 +127,20 @@ func New(configPath string) (*Application, error) {
 	}
 	slog.Info("default roles initialized successfully")
 
+	// Start background metric collection for the monitoring endpoints
+	if err := services.MonitoringService.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start monitoring service: %w", err)
+	}
+
+	// Start removing expired sessions and old login attempts in the background
+	services.JanitorService.Start()
+
+	// Deactivate accounts unused for too long, when enabled
+	services.InactiveUserService.Start()
+
 	// --- 7. Casbin initialization ---
 	var e *casbin.Enforcer
-	if cfg.Database.Enabled {
+	if sqlDatabase {
 		var casbinErr error
 		e, casbinErr = auth.InitCasbin(database.DB)
 		if casbinErr != nil {
@@ -112,8 +152,10 @@ func New(configPath string) (*Application, error) {
 	// Initialize permission service
 	services.PermissionService = service.NewPermissionService(mainStore, e)
 
-	// Set permission service reference in role service for synchronization
+	// Set permission service reference in the role and auth services for synchronization
 	services.RoleService.SetPermissionService(services.PermissionService)
+	services.AuthService.SetPermissionService(services.PermissionService)
+	services.AuthService.SetAuditService(services.AuditService)
 
 	// Initialize default policies
 	if err := services.PermissionService.InitializeDefaultPolicies(); err != nil {
@@ -125,25 +167,60 @@ func New(configPath string) (*Application, error) {
 	router := initialization.SetupRouter(cfg, services, k8sManager, e)
 	slog.Info("Gin router setup completed")
 
+	// --- 9. TLS certificate ---
+	var certificates *certwatch.Reloader
+	if cfg.Server.TLS.Enabled {
+		if certificates, err = certwatch.New(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile); err != nil {
+			return nil, err
+		}
+		slog.Info("TLS certificate loaded", "cert_file", cfg.Server.TLS.CertFile)
+	}
+
 	return &Application{
-		Config: cfg,
-		Logger: appLogger,
-		Router: router,
+		Config:        cfg,
+		Logger:        appLogger,
+		Router:        router,
+		Handler:       utils.TrustForwardedHeaders(cfg.Server.TrustedProxies, utils.StripBasePath(cfg.Server.RoutePrefix(), router)),
+		Janitor:       services.JanitorService,
+		Audit:         services.AuditService,
+		Monitoring:    services.MonitoringService,
+		InactiveUsers: services.InactiveUserService,
+		Certificates:  certificates,
 	}, nil
 }
 
 func (app *Application) Run() {
 	serverAddr := ":" + app.Config.Server.Port
-	initialization.DisplayServerInfo(serverAddr, app.Config.Server.Mode)
+	scheme := "http"
+	if app.Certificates != nil {
+		scheme = "https"
+	}
+	initialization.DisplayServerInfo(scheme, serverAddr, app.Config.Server.Mode)
 	app.Server = &http.Server{
 		Addr:         serverAddr,
-		Handler:      app.Router,
+		Handler:      app.Handler,
 		ReadTimeout:  time.Duration(app.Config.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(app.Config.Server.WriteTimeout) * time.Second,
 	}
+	if app.Certificates != nil {
+		// Renewed certificates are picked up by GetCertificate without a restart
+		if err := app.Certificates.Watch(); err != nil {
+			app.Logger.Warn("TLS certificate changes won't be reloaded", "error", err)
+		}
+		app.Server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: app.Certificates.GetCertificate,
+		}
+	}
 	go func() {
-		app.Logger.Info("server is listening...", "address", app.Server.Addr)
-		if err := app.Server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		app.Logger.Info("server is listening...", "address", app.Server.Addr, "scheme", scheme)
+		var err error
+		if app.Certificates != nil {
+			err = app.Server.ListenAndServeTLS("", "")
+		} else {
+			err = app.Server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			app.Logger.Error("server closed unexpectedly", "error", err)
 			os.Exit(1)
 		}
@@ -154,12 +231,25 @@ func (app *Application) Run() {
 	app.Logger.Info("received shutdown signal, shutting down server...")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if app.Config.Database.Enabled {
+	// Stop the background jobs and requests logging audit events before the audit webhook is flushed,
+	// then close the database they use. Open streams can make the shutdown time out, the queued audit
+	// events are delivered all the same.
+	app.Janitor.Stop()
+	app.InactiveUsers.Stop()
+	shutdownErr := app.Server.Shutdown(ctx)
+	if shutdownErr != nil {
+		app.Logger.Error("failed to shutdown server", "error", shutdownErr)
+	}
+	_ = app.Monitoring.Stop()
+	app.Audit.Close()
+	if app.Config.Database.Enabled && app.Config.Database.Type != "mongodb" {
 		database.CloseDatabase()
 		app.Logger.Info("database connection closed")
 	}
-	if err := app.Server.Shutdown(ctx); err != nil {
-		app.Logger.Error("failed to shutdown server", "error", err)
+	if app.Certificates != nil {
+		app.Certificates.Close()
+	}
+	if shutdownErr != nil {
 		os.Exit(1)
 	}
 	app.Logger.Info("server shutdown gracefully")
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ciliverse/cilikube/configs"
+)
+
+func TestNew_WithoutClusters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := configs.GlobalConfig
+	t.Cleanup(func() { configs.GlobalConfig = previous })
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+server:
+    port: "8080"
+    mode: release
+kubernetes:
+    kubeconfig: /nonexistent/kubeconfig
+database:
+    enabled: false
+jwt:
+    secret_key: test-secret
+admin:
+    password: test-admin-password
+clusters: []
+`), 0o600))
+
+	application, err := New(configPath)
+	require.NoError(t, err, "the server boots without any cluster")
+	assert.Empty(t, application.Config.Clusters)
+
+	w := httptest.NewRecorder()
+	application.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/nodes", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var body struct {
+		ErrorCode string `json:"errorCode"`
+		Message   string `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "NO_ACTIVE_CLUSTER", body.ErrorCode)
+	assert.Equal(t, "no active cluster configured", body.Message)
+
+	// Features that don't need a cluster keep working
+	w = httptest.NewRecorder()
+	application.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestNew_BasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := configs.GlobalConfig
+	t.Cleanup(func() { configs.GlobalConfig = previous })
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+server:
+    port: "8080"
+    mode: release
+    base_path: /cilikube/
+    trusted_proxies: [192.0.2.0/24] # httptest's remote address
+kubernetes:
+    kubeconfig: /nonexistent/kubeconfig
+database:
+    enabled: false
+jwt:
+    secret_key: test-secret
+admin:
+    password: test-admin-password
+oauth:
+    github:
+        client_id: test-client
+clusters: []
+`), 0o600))
+
+	application, err := New(configPath)
+	require.NoError(t, err)
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		application.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/cilikube/api/v1/version", nil).Code, "routes are registered under the base path")
+	assert.Equal(t, http.StatusNotFound, serve("/api/v1/version", nil).Code)
+
+	w := serve("/cilikube/api/v1/auth/oauth/github/auth", http.Header{"X-Forwarded-Proto": {"https"}, "X-Forwarded-Host": {"ops.example.com"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data struct {
+			AuthURL string `json:"auth_url"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	authURL, err := url.Parse(body.Data.AuthURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://ops.example.com/cilikube/api/v1/auth/oauth/callback", authURL.Query().Get("redirect_uri"),
+		"the OAuth callback includes the base path")
+}
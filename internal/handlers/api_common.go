@@ -1,6 +1,14 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,12 +25,100 @@ type SuccessResponse struct {
 	Message string      `json:"message"`
 }
 
-// respondError returns an error response
+// respondError returns an error response with the error code matching the HTTP status
 func respondError(c *gin.Context, code int, message string) {
-	c.JSON(code, ErrorResponse{
-		Code:    code,
-		Message: message,
-	})
+	utils.ApiError(c, code, message)
+}
+
+var (
+	errNoCurrentUser          = errors.New("user information not found")
+	errPermissionsUnavailable = errors.New("permissions can't be checked, only admins are allowed")
+)
+
+// permissionSubject returns the current user whose permissions are checked against permissionService, and
+// whether the user is an admin, who may perform every action. Other users are denied with errPermissionsUnavailable
+// when there is no permission service to check them against.
+func permissionSubject(c *gin.Context, permissionService *service.PermissionService) (uint, bool, error) {
+	userID, _, role, ok := auth.GetCurrentUser(c)
+	if !ok {
+		return 0, false, errNoCurrentUser
+	}
+	if role == "admin" {
+		return userID, true, nil
+	}
+	if permissionService == nil {
+		return 0, false, errPermissionsUnavailable
+	}
+	return userID, false, nil
+}
+
+// requirePermissionSubject is permissionSubject responding with an error when the user may not continue
+func requirePermissionSubject(c *gin.Context, permissionService *service.PermissionService) (uint, bool, bool) {
+	userID, admin, err := permissionSubject(c, permissionService)
+	switch {
+	case errors.Is(err, errNoCurrentUser):
+		utils.ApiError(c, http.StatusUnauthorized, err.Error(), "")
+		return 0, false, false
+	case err != nil:
+		utils.ApiError(c, http.StatusForbidden, "permission denied", err.Error())
+		return 0, false, false
+	}
+	return userID, admin, true
+}
+
+// authorizeNamespacedResource checks that the current user may perform action, such as PATCH or delete, on
+// the resources of a namespace, responding with an error when it may not. Admins may perform every action,
+// other users are denied when there is no permission service to check them against.
+func authorizeNamespacedResource(c *gin.Context, permissionService *service.PermissionService, namespace, resource, action string) bool {
+	object := fmt.Sprintf("/api/v1/namespaces/%s/%s", namespace, resource)
+	return authorizeObject(c, permissionService, object, action, "this requires the "+action+" permission on "+resource)
+}
+
+// authorizeObject checks that the current user may perform action on object, responding with deniedDetail
+// when it may not
+func authorizeObject(c *gin.Context, permissionService *service.PermissionService, object, action, deniedDetail string) bool {
+	userID, admin, ok := requirePermissionSubject(c, permissionService)
+	if !ok {
+		return false
+	}
+	if admin {
+		return true
+	}
+
+	allowed, err := permissionService.CheckPermission(userID, object, action)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to check permission", err.Error())
+		return false
+	}
+	if !allowed {
+		utils.ApiError(c, http.StatusForbidden, "permission denied", deniedDetail)
+		return false
+	}
+	return true
+}
+
+// namespacedResourceFilter builds a filter reporting whether the current user may perform action on the
+// resources of a namespace, or of the cluster for an empty namespace. Admins get a nil filter allowing
+// everything. Like requirePermissionSubject, it responds with an error and returns false when the user may not continue.
+func namespacedResourceFilter(c *gin.Context, permissionService *service.PermissionService, action string) (service.NamespaceFilter, bool) {
+	userID, admin, ok := requirePermissionSubject(c, permissionService)
+	if !ok || admin {
+		return nil, ok
+	}
+
+	decisions := make(map[string]bool)
+	return func(namespace, resource string) bool {
+		object := fmt.Sprintf("/api/v1/namespaces/%s/%s", namespace, resource)
+		if namespace == "" {
+			object = fmt.Sprintf("/api/v1/%s/", resource) // Cluster-scoped resources such as nodes
+		}
+		if allowed, seen := decisions[object]; seen {
+			return allowed
+		}
+		allowed, err := permissionService.CheckPermission(userID, object, action)
+		decisions[object] = err == nil && allowed
+		return decisions[object]
+	}, true
 }
 
 // respondSuccess returns a successful response
@@ -33,3 +129,20 @@ func respondSuccess(c *gin.Context, code int, data interface{}) {
 		Message: "success",
 	})
 }
+
+// kubernetesAPIError maps service validation errors and Kubernetes API errors to an API error
+func kubernetesAPIError(message string, err error) *utils.APIError {
+	if errors.Is(err, k8s.ErrNoActiveCluster) {
+		return k8s.NoActiveClusterError()
+	}
+	if errors.Is(err, service.ErrInvalidResource) || errors.Is(err, service.ErrResourceScopeMismatch) ||
+		errors.Is(err, service.ErrEmptySelector) {
+		return utils.NewAPIError(http.StatusBadRequest, utils.ErrCodeValidationFailed, message, err.Error())
+	}
+	return utils.KubernetesAPIError(message, err)
+}
+
+// respondKubernetesError writes the error response matching a service or Kubernetes API error
+func respondKubernetesError(c *gin.Context, message string, err error) {
+	utils.ApiErrorFrom(c, kubernetesAPIError(message, err))
+}
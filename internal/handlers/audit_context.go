@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"regexp"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request, taken from the client or generated, echoed in the response
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDRegex bounds the request IDs accepted from clients, which end up in the audit log
+var requestIDRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+// RequestAuditContext attaches the audit context of each request to its context: the client IP, user
+// agent and request ID, and the caller and session once authentication has run. Services record it in
+// the audit log entries they write for the request.
+func RequestAuditContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDRegex.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		audit := service.AuditContext{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: requestID,
+		}
+		// Authentication middleware is registered on the routes, so the caller is looked up when read
+		ctx := service.WithAuditContext(c.Request.Context(), audit, func(audit *service.AuditContext) {
+			if userID, username, _, ok := auth.GetCurrentUser(c); ok {
+				audit.UserID = &userID
+				audit.Username = username
+			}
+			if claims, ok := auth.CurrentClaims(c); ok {
+				audit.SessionID = claims.ID
+			}
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
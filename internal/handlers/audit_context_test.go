@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestAuditContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var audit service.AuditContext
+	router := gin.New()
+	router.Use(RequestAuditContext())
+	// Authentication runs on the route, after the middleware
+	router.GET("/audited", func(c *gin.Context) {
+		c.Set("user_id", uint(5))
+		c.Set("username", "alice")
+		c.Set("user_role", "editor")
+	}, func(c *gin.Context) {
+		audit = service.AuditContextFrom(c.Request.Context())
+	})
+
+	serve := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/audited", nil)
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("req-123")
+	assert.Equal(t, "req-123", w.Header().Get(RequestIDHeader), "the client's request ID is kept")
+	assert.Equal(t, "req-123", audit.RequestID)
+	assert.Equal(t, "192.0.2.1", audit.IPAddress)
+	assert.Equal(t, "Mozilla/5.0", audit.UserAgent)
+	require.NotNil(t, audit.UserID, "the caller authenticated on the route is resolved")
+	assert.Equal(t, uint(5), *audit.UserID)
+	assert.Equal(t, "alice", audit.Username)
+
+	for _, requestID := range []string{"", "bad id\nwith newline"} {
+		w := serve(requestID)
+		generated := w.Header().Get(RequestIDHeader)
+		assert.Len(t, generated, 36, "a UUID replaces %q", requestID)
+		assert.Equal(t, generated, audit.RequestID)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -27,7 +28,7 @@ func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
-// @Param page_size query int false "Page size" default(20)
+// @Param page_size query int false "Page size, at most server.pagination.max_size" default(20)
 // @Param user_id query int false "Filter by user ID"
 // @Param action query string false "Filter by action"
 // @Param start_time query string false "Start time (RFC3339 format)"
@@ -38,38 +39,27 @@ func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
 // @Failure 403 {object} map[string]interface{}
 // @Router /api/v1/audit/logs [get]
 func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, ok := utils.ParsePage(c)
+	if !ok {
+		return
+	}
 	userIDStr := c.Query("user_id")
 	action := c.Query("action")
 	startTimeStr := c.Query("start_time")
 	endTimeStr := c.Query("end_time")
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 20
-	}
-
-	offset := (page - 1) * pageSize
+	offset, pageSize := page.Offset(), page.PageSize
 
 	// Parse time filters (for future use in filtering)
 	if startTimeStr != "" {
 		if _, parseErr := time.Parse(time.RFC3339, startTimeStr); parseErr != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code":    400,
-				"message": "Invalid start_time format. Use RFC3339 format.",
-			})
+			utils.ApiError(c, http.StatusBadRequest, "Invalid start_time format. Use RFC3339 format.")
 			return
 		}
 	}
 	if endTimeStr != "" {
 		if _, parseErr := time.Parse(time.RFC3339, endTimeStr); parseErr != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code":    400,
-				"message": "Invalid end_time format. Use RFC3339 format.",
-			})
+			utils.ApiError(c, http.StatusBadRequest, "Invalid end_time format. Use RFC3339 format.")
 			return
 		}
 	}
@@ -82,10 +72,7 @@ func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
 	if userIDStr != "" {
 		userID, parseErr := strconv.ParseUint(userIDStr, 10, 32)
 		if parseErr != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code":    400,
-				"message": "Invalid user_id format",
-			})
+			utils.ApiError(c, http.StatusBadRequest, "Invalid user_id format")
 			return
 		}
 		logs, total, err = h.auditService.GetAuditLogsByUserID(uint(userID), offset, pageSize)
@@ -96,10 +83,7 @@ func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to get audit logs: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to get audit logs: "+err.Error())
 		return
 	}
 
@@ -109,8 +93,8 @@ func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
 		"data": gin.H{
 			"logs":      logs,
 			"total":     total,
-			"page":      page,
-			"page_size": pageSize,
+			"page":      page.Page,
+			"page_size": page.PageSize,
 		},
 	})
 }
@@ -131,64 +115,94 @@ func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
 // @Failure 403 {object} map[string]interface{}
 // @Router /api/v1/audit/report [get]
 func (h *AuditHandler) GetAuditReport(c *gin.Context) {
+	startTime, endTime, userID, ok := parseAuditReportQuery(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.auditService.GetAuditReport(startTime, endTime, userID)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to generate audit report: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Report generated successfully",
+		"data":    report,
+	})
+}
+
+// GetMutationReport summarizes the mutating resource operations of a time period
+// @Summary Get mutation report
+// @Description Summarize mutating resource operations (create, update, patch, delete and similar) for access reviews, grouped by user and action with counts and the affected namespaces
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_time query string true "Start time (RFC3339 format)"
+// @Param end_time query string true "End time (RFC3339 format)"
+// @Param user_id query int false "Filter by user ID"
+// @Success 200 {object} service.MutationReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/audit/report/mutations [get]
+func (h *AuditHandler) GetMutationReport(c *gin.Context) {
+	startTime, endTime, userID, ok := parseAuditReportQuery(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.auditService.GetMutationReport(startTime, endTime, userID)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to generate mutation report: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Report generated successfully",
+		"data":    report,
+	})
+}
+
+// parseAuditReportQuery parses the required start_time and end_time and the optional user_id of a report
+// request, responding with 400 and returning false when they are invalid
+func parseAuditReportQuery(c *gin.Context) (time.Time, time.Time, *uint, bool) {
 	startTimeStr := c.Query("start_time")
 	endTimeStr := c.Query("end_time")
 	userIDStr := c.Query("user_id")
 
 	if startTimeStr == "" || endTimeStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "start_time and end_time are required",
-		})
-		return
+		utils.ApiError(c, http.StatusBadRequest, "start_time and end_time are required")
+		return time.Time{}, time.Time{}, nil, false
 	}
 
 	startTime, err := time.Parse(time.RFC3339, startTimeStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid start_time format. Use RFC3339 format.",
-		})
-		return
+		utils.ApiError(c, http.StatusBadRequest, "Invalid start_time format. Use RFC3339 format.")
+		return time.Time{}, time.Time{}, nil, false
 	}
 
 	endTime, err := time.Parse(time.RFC3339, endTimeStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid end_time format. Use RFC3339 format.",
-		})
-		return
+		utils.ApiError(c, http.StatusBadRequest, "Invalid end_time format. Use RFC3339 format.")
+		return time.Time{}, time.Time{}, nil, false
 	}
 
 	var userID *uint
 	if userIDStr != "" {
 		uid, err := strconv.ParseUint(userIDStr, 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"code":    400,
-				"message": "Invalid user_id format",
-			})
-			return
+			utils.ApiError(c, http.StatusBadRequest, "Invalid user_id format")
+			return time.Time{}, time.Time{}, nil, false
 		}
 		uidUint := uint(uid)
 		userID = &uidUint
 	}
 
-	report, err := h.auditService.GetAuditReport(startTime, endTime, userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to generate audit report: " + err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "Report generated successfully",
-		"data":    report,
-	})
+	return startTime, endTime, userID, true
 }
 
 // GetSecurityMetrics gets security metrics for monitoring
@@ -209,19 +223,13 @@ func (h *AuditHandler) GetSecurityMetrics(c *gin.Context) {
 
 	period, err := time.ParseDuration(periodStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid period format. Use duration format like '24h', '7d', etc.",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "Invalid period format. Use duration format like '24h', '7d', etc.")
 		return
 	}
 
 	metrics, err := h.auditService.GetSecurityMetrics(period)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to get security metrics: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to get security metrics: "+err.Error())
 		return
 	}
 
@@ -246,10 +254,7 @@ func (h *AuditHandler) GetSecurityMetrics(c *gin.Context) {
 func (h *AuditHandler) DetectThreats(c *gin.Context) {
 	threats, err := h.auditService.DetectAnomalousActivity()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to detect threats: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to detect threats: "+err.Error())
 		return
 	}
 
@@ -283,19 +288,13 @@ func (h *AuditHandler) GetUserActivity(c *gin.Context) {
 
 	userID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid user_id format",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "Invalid user_id format")
 		return
 	}
 
 	period, err := time.ParseDuration(periodStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid period format. Use duration format like '24h', '7d', etc.",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "Invalid period format. Use duration format like '24h', '7d', etc.")
 		return
 	}
 
@@ -305,10 +304,7 @@ func (h *AuditHandler) GetUserActivity(c *gin.Context) {
 
 	report, err := h.auditService.GetAuditReport(startTime, endTime, &uid)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to get user activity: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to get user activity: "+err.Error())
 		return
 	}
 
@@ -337,10 +333,7 @@ func (h *AuditHandler) GetSystemActivity(c *gin.Context) {
 
 	period, err := time.ParseDuration(periodStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid period format. Use duration format like '24h', '7d', etc.",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "Invalid period format. Use duration format like '24h', '7d', etc.")
 		return
 	}
 
@@ -350,30 +343,21 @@ func (h *AuditHandler) GetSystemActivity(c *gin.Context) {
 	// Get system-wide report
 	report, err := h.auditService.GetAuditReport(startTime, endTime, nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to get system activity: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to get system activity: "+err.Error())
 		return
 	}
 
 	// Get security metrics
 	metrics, err := h.auditService.GetSecurityMetrics(period)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to get security metrics: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to get security metrics: "+err.Error())
 		return
 	}
 
 	// Detect current threats
 	threats, err := h.auditService.DetectAnomalousActivity()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Failed to detect threats: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to detect threats: "+err.Error())
 		return
 	}
 
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/ciliverse/cilikube/internal/models"
 	"github.com/ciliverse/cilikube/internal/service"
 	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -30,27 +32,22 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 // @Success 200 {object} models.LoginResponse
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "parameter error: " + err.Error(),
-		})
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	// Get client IP and user agent
-	ipAddress := c.ClientIP()
-	userAgent := c.GetHeader("User-Agent")
-
-	response, err := h.authService.Login(&req, ipAddress, userAgent)
+	response, err := h.authService.Login(c.Request.Context(), &req)
+	if errors.Is(err, service.ErrAuthServiceUnavailable) {
+		utils.ApiError(c, http.StatusServiceUnavailable, err.Error())
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": err.Error(),
-		})
+		utils.ApiError(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
@@ -70,23 +67,22 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Param register body models.RegisterRequest true "Registration information"
 // @Success 200 {object} models.UserResponse
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{} "Registration is closed"
 // @Router /api/v1/auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "parameter error: " + err.Error(),
-		})
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	response, err := h.authService.Register(&req)
+	response, err := h.authService.Register(c.Request.Context(), &req)
+	if errors.Is(err, service.ErrRegistrationClosed) {
+		utils.ApiError(c, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		utils.ApiError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -110,19 +106,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "user information does not exist",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "user information does not exist")
 		return
 	}
 
 	response, err := h.authService.GetProfileLegacy(userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		utils.ApiError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -146,19 +136,13 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 func (h *AuthHandler) GetDetailedProfile(c *gin.Context) {
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "user information does not exist",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "user information does not exist")
 		return
 	}
 
 	response, err := h.authService.GetProfile(userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		utils.ApiError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -184,10 +168,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	// Get token from Authorization header
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "Authorization header is required",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "Authorization header is required")
 		return
 	}
 
@@ -196,19 +177,13 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
 		tokenString = authHeader[7:]
 	} else {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "Invalid authorization header format",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "Invalid authorization header format")
 		return
 	}
 
-	response, err := h.authService.RefreshToken(tokenString)
+	response, err := h.authService.RefreshToken(c.Request.Context(), tokenString)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": err.Error(),
-		})
+		utils.ApiError(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
@@ -234,28 +209,19 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "user information does not exist",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "user information does not exist")
 		return
 	}
 
 	var req models.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "parameter error: " + err.Error(),
-		})
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	response, err := h.authService.UpdateProfile(userID, &req)
+	response, err := h.authService.UpdateProfile(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		utils.ApiError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -281,28 +247,19 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "user information does not exist",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "user information does not exist")
 		return
 	}
 
 	var req models.ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "parameter error: " + err.Error(),
-		})
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	err := h.authService.ChangePassword(userID, &req)
+	err := h.authService.ChangePassword(c.Request.Context(), userID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		utils.ApiError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -312,6 +269,36 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	})
 }
 
+// GetPasswordExpiry returns when the current user's password expires
+// @Summary Get password expiry
+// @Description Reports days until the password of the currently logged in user expires, so the UI can warn in time
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/auth/password/expiry [get]
+func (h *AuthHandler) GetPasswordExpiry(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information does not exist")
+		return
+	}
+
+	status, err := h.authService.GetPasswordExpiry(userID)
+	if err != nil {
+		utils.ApiError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "retrieved successfully",
+		"data":    status,
+	})
+}
+
 // Logout user logout
 // @Summary User logout
 // @Description User logs out of the system and invalidates session
@@ -325,7 +312,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if ok {
 		// Invalidate user sessions
-		if err := h.authService.Logout(userID); err != nil {
+		if err := h.authService.Logout(c.Request.Context(), userID); err != nil {
 			// Log error but don't fail logout
 			// Frontend will clear token regardless
 		}
@@ -337,6 +324,46 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	})
 }
 
+// LogoutAll logs the current user out everywhere
+// @Summary Log out everywhere
+// @Description Invalidate all sessions of the current user and revoke every token issued to it, including the one used for this request
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information does not exist")
+		return
+	}
+
+	terminated, err := h.authService.LogoutAll(c.Request.Context(), userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "logged out of all sessions",
+		"data": gin.H{
+			"sessions_terminated": terminated,
+		},
+	})
+}
+
 // GetUserSessions gets current user's active sessions
 // @Summary Get user sessions
 // @Description Get list of active sessions for current user
@@ -350,19 +377,13 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 func (h *AuthHandler) GetUserSessions(c *gin.Context) {
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "user information does not exist",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "user information does not exist")
 		return
 	}
 
 	sessions, err := h.authService.GetUserSessions(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "failed to get user sessions: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "failed to get user sessions: "+err.Error())
 		return
 	}
 
@@ -388,28 +409,19 @@ func (h *AuthHandler) GetUserSessions(c *gin.Context) {
 func (h *AuthHandler) InvalidateSession(c *gin.Context) {
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "user information does not exist",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "user information does not exist")
 		return
 	}
 
 	sessionID := c.Param("sessionId")
 	if sessionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "session ID is required",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "session ID is required")
 		return
 	}
 
 	err := h.authService.InvalidateUserSession(userID, sessionID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		utils.ApiError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -432,19 +444,13 @@ func (h *AuthHandler) InvalidateSession(c *gin.Context) {
 func (h *AuthHandler) GetSecurityEvents(c *gin.Context) {
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "user information does not exist",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "user information does not exist")
 		return
 	}
 
 	events, warnings, err := h.authService.GetUserSecurityInfo(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "failed to get security events: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "failed to get security events: "+err.Error())
 		return
 	}
 
@@ -471,19 +477,13 @@ func (h *AuthHandler) GetSecurityEvents(c *gin.Context) {
 func (h *AuthHandler) ValidatePassword(c *gin.Context) {
 	var req models.ValidatePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "parameter error: " + err.Error(),
-		})
+		utils.ApiBindError(c, err)
 		return
 	}
 
 	response, err := h.authService.ValidatePassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "failed to validate password: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "failed to validate password: "+err.Error())
 		return
 	}
 
@@ -502,28 +502,21 @@ func (h *AuthHandler) ValidatePassword(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number" default(1)
-// @Param page_size query int false "Page size" default(10)
+// @Param page_size query int false "Page size, at most server.pagination.max_size" default(20)
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Router /api/v1/auth/users [get]
 func (h *AuthHandler) GetUserList(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 10
+	page, ok := utils.ParsePage(c)
+	if !ok {
+		return
 	}
 
-	users, total, err := h.authService.GetUserList(page, pageSize)
+	users, total, err := h.authService.GetUserList(page.Page, page.PageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "failed to get user list: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "failed to get user list: "+err.Error())
 		return
 	}
 
@@ -533,8 +526,8 @@ func (h *AuthHandler) GetUserList(c *gin.Context) {
 		"data": gin.H{
 			"users":     users,
 			"total":     total,
-			"page":      page,
-			"page_size": pageSize,
+			"page":      page.Page,
+			"page_size": page.PageSize,
 		},
 	})
 }
@@ -556,10 +549,7 @@ func (h *AuthHandler) GetUserList(c *gin.Context) {
 func (h *AuthHandler) UpdateUserStatus(c *gin.Context) {
 	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "invalid user ID",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "invalid user ID")
 		return
 	}
 
@@ -567,19 +557,13 @@ func (h *AuthHandler) UpdateUserStatus(c *gin.Context) {
 		IsActive bool `json:"is_active"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "parameter error: " + err.Error(),
-		})
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	err = h.authService.UpdateUserStatus(uint(userID), req.IsActive)
+	err = h.authService.UpdateUserStatus(c.Request.Context(), uint(userID), req.IsActive)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "failed to update user status: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "failed to update user status: "+err.Error())
 		return
 	}
 
@@ -589,6 +573,47 @@ func (h *AuthHandler) UpdateUserStatus(c *gin.Context) {
 	})
 }
 
+// UnlockUser unlocks a user account (admin)
+// @Summary Unlock user account
+// @Description Admin clears the lockout caused by failed login attempts before it expires
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/auth/admin/users/{id}/unlock [post]
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	status, err := h.authService.UnlockAccount(c.Request.Context(), uint(userID))
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, service.ErrUserNotFound) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{
+			"code":    code,
+			"message": "failed to unlock user: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "user unlocked successfully",
+		"data":    status,
+	})
+}
+
 // DeleteUser deletes user (admin)
 // @Summary Delete user
 // @Description Admin deletes user account
@@ -605,29 +630,20 @@ func (h *AuthHandler) UpdateUserStatus(c *gin.Context) {
 func (h *AuthHandler) DeleteUser(c *gin.Context) {
 	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "invalid user ID",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "invalid user ID")
 		return
 	}
 
 	// Prevent deleting oneself
 	currentUserID, _, _, ok := auth.GetCurrentUser(c)
 	if ok && currentUserID == uint(userID) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "cannot delete your own account",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "cannot delete your own account")
 		return
 	}
 
-	err = h.authService.DeleteUser(uint(userID))
+	err = h.authService.DeleteUser(c.Request.Context(), uint(userID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "failed to delete user: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "failed to delete user: "+err.Error())
 		return
 	}
 
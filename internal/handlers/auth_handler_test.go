@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validationErrorResponse struct {
+	ErrorCode string            `json:"errorCode"`
+	Errors    map[string]string `json:"errors"`
+}
+
+func postValidation(t *testing.T, router *gin.Engine, method, path, body string) (int, validationErrorResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	var resp validationErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w.Code, resp
+}
+
+func TestAuthHandler_RegisterValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Invalid requests are rejected before the service is used
+	router.POST("/register", NewAuthHandler(nil).Register)
+
+	tests := []struct {
+		name string
+		body string
+		want map[string]string
+	}{
+		{
+			name: "missing fields",
+			body: `{}`,
+			want: map[string]string{"username": "is required", "email": "is required", "password": "is required"},
+		},
+		{
+			name: "short username",
+			body: `{"username":"al","email":"al@example.com","password":"secret1"}`,
+			want: map[string]string{"username": "min 3 chars"},
+		},
+		{
+			name: "long username",
+			body: `{"username":"` + strings.Repeat("a", 51) + `","email":"al@example.com","password":"secret1"}`,
+			want: map[string]string{"username": "max 50 chars"},
+		},
+		{
+			name: "username charset",
+			body: `{"username":"alice smith","email":"alice@example.com","password":"secret1"}`,
+			want: map[string]string{"username": "may only contain letters, digits, '.', '_' and '-', starting with a letter or digit"},
+		},
+		{
+			name: "username starting with a symbol",
+			body: `{"username":".alice","email":"alice@example.com","password":"secret1"}`,
+			want: map[string]string{"username": "may only contain letters, digits, '.', '_' and '-', starting with a letter or digit"},
+		},
+		{
+			name: "invalid email",
+			body: `{"username":"alice","email":"alice.example.com","password":"secret1"}`,
+			want: map[string]string{"email": "must be a valid email"},
+		},
+		{
+			name: "short password",
+			body: `{"username":"alice","email":"alice@example.com","password":"12345"}`,
+			want: map[string]string{"password": "min 6 chars"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := postValidation(t, router, http.MethodPost, "/register", tt.body)
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, "VALIDATION_FAILED", resp.ErrorCode)
+			assert.Equal(t, tt.want, resp.Errors)
+		})
+	}
+
+	t.Run("malformed body", func(t *testing.T) {
+		status, resp := postValidation(t, router, http.MethodPost, "/register", `{"username":`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "BAD_REQUEST", resp.ErrorCode)
+		assert.Empty(t, resp.Errors)
+	})
+}
+
+func TestAuthHandler_UpdateProfileValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		c.Set("username", "alice")
+		c.Set("user_role", "user")
+	})
+	router.PUT("/profile", NewAuthHandler(nil).UpdateProfile)
+	router.PUT("/profile/v2", NewProfileHandler(nil, nil).UpdateProfile)
+
+	tests := []struct {
+		name string
+		body string
+		want map[string]string
+	}{
+		{
+			name: "missing email",
+			body: `{"display_name":"Alice"}`,
+			want: map[string]string{"email": "is required"},
+		},
+		{
+			name: "invalid email",
+			body: `{"email":"alice@","display_name":"Alice"}`,
+			want: map[string]string{"email": "must be a valid email"},
+		},
+		{
+			name: "long display name",
+			body: `{"email":"alice@example.com","display_name":"` + strings.Repeat("A", 101) + `"}`,
+			want: map[string]string{"display_name": "max 100 chars"},
+		},
+	}
+
+	for _, path := range []string{"/profile", "/profile/v2"} {
+		for _, tt := range tests {
+			t.Run(path+" "+tt.name, func(t *testing.T) {
+				status, resp := postValidation(t, router, http.MethodPut, path, tt.body)
+				assert.Equal(t, http.StatusBadRequest, status)
+				assert.Equal(t, "VALIDATION_FAILED", resp.ErrorCode)
+				assert.Equal(t, tt.want, resp.Errors)
+			})
+		}
+	}
+}
+
+func TestAuthHandler_AllowRegistration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(allow bool) *gin.Engine {
+		memoryStore := store.NewMemoryStore()
+		require.NoError(t, memoryStore.Initialize())
+		authService := service.NewAuthService(memoryStore, &configs.Config{
+			Security: configs.SecurityConfig{AllowRegistration: &allow},
+		})
+		router := gin.New()
+		router.POST("/auth/register", NewAuthHandler(authService).Register)
+		router.POST("/users", NewUserManagementHandler(authService, nil).CreateUser)
+		return router
+	}
+	post := func(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	const visitor = `{"username":"visitor","email":"visitor@example.com","password":"k7#Rt9mQ2x"}`
+
+	t.Run("enabled", func(t *testing.T) {
+		w := post(newRouter(true), "/auth/register", visitor)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		router := newRouter(false)
+		w := post(router, "/auth/register", visitor)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		var resp validationErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "FORBIDDEN", resp.ErrorCode)
+
+		w = post(router, "/users", `{"username":"colleague","email":"colleague@example.com","password":"k7#Rt9mQ2x","confirmPassword":"k7#Rt9mQ2x"}`)
+		assert.Equal(t, http.StatusOK, w.Code, "administrators still create accounts: %s", w.Body.String())
+	})
+}
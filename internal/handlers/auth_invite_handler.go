@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInvite invites a user by email (admin)
+// @Summary Invite a user
+// @Description Admin creates an invite for an email address with the roles the account will get, the viewer role when none are given. The returned token is sent to the invitee, who accepts it with /auth/accept-invite before it expires (security.invite_ttl, 72h by default). The token is only returned here.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param invite body models.CreateInviteRequest true "Invitee email and roles"
+// @Success 200 {object} models.InviteResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/admin/invites [post]
+func (h *AuthHandler) CreateInvite(c *gin.Context) {
+	var req models.CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	currentUserID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	invite, err := h.authService.CreateInvite(c.Request.Context(), req.Email, req.RoleIDs, currentUserID)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "Failed to create invite", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, invite, "Invite created successfully")
+}
+
+// ListInvites lists invites with their status (admin)
+// @Summary List invites
+// @Description Admin lists invites newest first, each pending, accepted or expired
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size, at most server.pagination.max_size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/auth/admin/invites [get]
+func (h *AuthHandler) ListInvites(c *gin.Context) {
+	page, ok := utils.ParsePage(c)
+	if !ok {
+		return
+	}
+
+	invites, total, err := h.authService.ListInvites(page.Page, page.PageSize)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to get invite list", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, gin.H{
+		"invites":   invites,
+		"total":     total,
+		"page":      page.Page,
+		"page_size": page.PageSize,
+	}, "Invites retrieved successfully")
+}
+
+// AcceptInvite creates the account of an invite
+// @Summary Accept an invite
+// @Description The invitee chooses a username and password to create the account of an invite, which gets the invited email and roles. An invite is accepted only once.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param invite body models.AcceptInviteRequest true "Invite token, username and password"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} map[string]interface{} "Invalid token or account details"
+// @Failure 409 {object} map[string]interface{} "Invite already accepted"
+// @Failure 410 {object} map[string]interface{} "Invite expired"
+// @Router /api/v1/auth/accept-invite [post]
+func (h *AuthHandler) AcceptInvite(c *gin.Context) {
+	var req models.AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	user, err := h.authService.AcceptInvite(c.Request.Context(), &req)
+	switch {
+	case errors.Is(err, service.ErrInviteAccepted):
+		utils.ApiError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInviteExpired):
+		utils.ApiError(c, http.StatusGone, err.Error())
+	case err != nil:
+		utils.ApiError(c, http.StatusBadRequest, "Failed to accept invite", err.Error())
+	default:
+		utils.ApiSuccess(c, user, "Account created successfully")
+	}
+}
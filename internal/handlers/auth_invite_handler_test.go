@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHandler_InviteFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	memoryStore := store.NewMemoryStore()
+	require.NoError(t, memoryStore.Initialize())
+	closed := false
+	handler := NewAuthHandler(service.NewAuthService(memoryStore, &configs.Config{
+		JWT:      configs.JWTConfig{SecretKey: "secret"},
+		Security: configs.SecurityConfig{AllowRegistration: &closed},
+	}))
+
+	router := gin.New()
+	router.POST("/auth/accept-invite", handler.AcceptInvite)
+	admin := router.Group("/auth/admin", func(c *gin.Context) {
+		c.Set("user_id", uint(1))
+		c.Set("username", "admin")
+		c.Set("user_role", "admin")
+	})
+	admin.POST("/invites", handler.CreateInvite)
+	admin.GET("/invites", handler.ListInvites)
+
+	send := func(method, path, body string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		var resp struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+		return w.Code, resp.Data
+	}
+
+	status, invite := send(http.MethodPost, "/auth/admin/invites", `{"email":"carol@example.com"}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "pending", invite["status"])
+	token, _ := invite["token"].(string)
+	require.NotEmpty(t, token)
+
+	accept := `{"token":"` + token + `","username":"carol","password":"k7#Rt9mQ2x"}`
+	status, user := send(http.MethodPost, "/auth/accept-invite", accept)
+	require.Equal(t, http.StatusOK, status, "invites work while registration is closed")
+	assert.Equal(t, "carol@example.com", user["email"])
+
+	status, _ = send(http.MethodPost, "/auth/accept-invite", accept)
+	assert.Equal(t, http.StatusConflict, status, "an invite is accepted once")
+
+	status, _ = send(http.MethodPost, "/auth/accept-invite", `{"token":"forged.token","username":"mallory","password":"k7#Rt9mQ2x"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, list := send(http.MethodGet, "/auth/admin/invites", "")
+	require.Equal(t, http.StatusOK, status)
+	invites, _ := list["invites"].([]interface{})
+	require.Len(t, invites, 1)
+	listed, _ := invites[0].(map[string]interface{})
+	assert.Equal(t, "accepted", listed["status"])
+	assert.NotContains(t, listed, "token", "the token is only returned on creation")
+}
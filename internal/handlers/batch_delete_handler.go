@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ActionDelete is the permission action required to delete resources in bulk
+const ActionDelete = "delete"
+
+// BatchDeleteHandler handles deleting all resources that match a label selector
+type BatchDeleteHandler struct {
+	service               *service.BatchDeleteService
+	customResourceService *service.CustomResourceService
+	permissionService     *service.PermissionService
+	clusterManager        *k8s.ClusterManager
+}
+
+// NewBatchDeleteHandler creates a new BatchDeleteHandler
+func NewBatchDeleteHandler(svc *service.BatchDeleteService, customResourceService *service.CustomResourceService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *BatchDeleteHandler {
+	return &BatchDeleteHandler{
+		service:               svc,
+		customResourceService: customResourceService,
+		permissionService:     permissionService,
+		clusterManager:        cm,
+	}
+}
+
+// DeleteCollection handles DELETE /api/v1/clusters/:id/namespaces/:namespace/:resource?labelSelector=...&confirm=true
+func (h *BatchDeleteHandler) DeleteCollection(c *gin.Context) {
+	namespace := c.Param("namespace")
+	resource := c.Param("resource")
+	selector := c.Query("labelSelector")
+
+	if selector == "" {
+		utils.ApiError(c, http.StatusBadRequest, "invalid parameters", service.ErrEmptySelector.Error())
+		return
+	}
+	if c.Query("confirm") != "true" {
+		utils.ApiError(c, http.StatusBadRequest, "confirmation required", "pass confirm=true to delete all matching resources")
+		return
+	}
+	if !authorizeNamespacedResource(c, h.permissionService, namespace, resource, ActionDelete) {
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	mapper := h.customResourceService.MapperFor(c.Param("id"), k8sClient.DiscoveryClient)
+	result, err := h.service.DeleteBySelector(k8sClient.DynamicClient, mapper, namespace, resource, selector)
+	gvr := resolveGVR(mapper, resource)
+	if err != nil {
+		auditResourceChange(c, gvr, namespace, "", service.ResourceActionDeleteCollection, err)
+		respondKubernetesError(c, "failed to delete resources", err)
+		return
+	}
+	for _, name := range result.Names {
+		auditResourceChange(c, gvr, namespace, name, service.ResourceActionDelete, nil)
+	}
+	for _, failure := range result.Errors {
+		auditResourceChange(c, gvr, namespace, failure.Name, service.ResourceActionDelete, errors.New(failure.Error))
+	}
+	utils.ApiSuccess(c, result, fmt.Sprintf("deleted %d %s", result.Deleted, result.Resource))
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchDeleteHandler_Guards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.DELETE("/clusters/:id/namespaces/:namespace/:resource", NewBatchDeleteHandler(nil, nil, nil, nil).DeleteCollection)
+
+	for _, query := range []string{"", "?confirm=true", "?labelSelector=app%3Dweb", "?labelSelector=app%3Dweb&confirm=1"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/clusters/c1/namespaces/test/pods"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// Without an authenticated user nothing is deleted even when the request is confirmed
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/clusters/c1/namespaces/test/pods?labelSelector=app%3Dweb&confirm=true", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
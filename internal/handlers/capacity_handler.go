@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// CapacityHandler handles cluster capacity report requests
+type CapacityHandler struct {
+	service        *service.CapacityService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewCapacityHandler creates a new CapacityHandler
+func NewCapacityHandler(svc *service.CapacityService, cm *k8s.ClusterManager) *CapacityHandler {
+	return &CapacityHandler{
+		service:        svc,
+		clusterManager: cm,
+	}
+}
+
+// GetCapacity handles GET /api/v1/clusters/:id/capacity
+func (h *CapacityHandler) GetCapacity(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	listers, err := h.service.ListersFor(c.Param("id"), k8sClient.Clientset)
+	if err != nil {
+		utils.ApiError(c, http.StatusServiceUnavailable, "failed to prepare resource cache", err.Error())
+		return
+	}
+
+	// Usage is optional, so a metrics client that cannot be created only leaves it out
+	var metricsClient versioned.Interface
+	if client, err := versioned.NewForConfig(k8sClient.Config); err == nil {
+		metricsClient = client
+	} else {
+		log.Printf("failed to create metrics client for cluster %s: %v", c.Param("id"), err)
+	}
+
+	capacity, err := h.service.Capacity(listers, metricsClient)
+	if err != nil {
+		respondKubernetesError(c, "failed to build capacity report", err)
+		return
+	}
+	utils.ApiSuccess(c, capacity, "successfully retrieved cluster capacity")
+}
+
+// GetNodeProblems handles GET /api/v1/clusters/:id/nodes/problems
+func (h *CapacityHandler) GetNodeProblems(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	listers, err := h.service.ListersFor(c.Param("id"), k8sClient.Clientset)
+	if err != nil {
+		utils.ApiError(c, http.StatusServiceUnavailable, "failed to prepare resource cache", err.Error())
+		return
+	}
+
+	problems, err := h.service.NodeProblems(listers.Nodes)
+	if err != nil {
+		respondKubernetesError(c, "failed to check node conditions", err)
+		return
+	}
+	utils.ApiSuccess(c, problems, "successfully retrieved node problems")
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/ciliverse/cilikube/internal/models"
 	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
 	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -18,9 +20,20 @@ func NewClusterHandler(svc *service.ClusterService) *ClusterHandler {
 	return &ClusterHandler{service: svc}
 }
 
-// ListClusters gets cluster list
+// ListClusters gets cluster list, optionally filtered by label, provider, environment and region.
+// Repeated label parameters are combined with AND: ?label=env=prod&label=team=core
 func (h *ClusterHandler) ListClusters(c *gin.Context) {
-	clusters := h.service.ListClusters()
+	labels, err := k8s.ParseLabelSelectors(c.QueryArray("label"))
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid label filter", err.Error())
+		return
+	}
+	clusters := h.service.ListClusters(k8s.ClusterFilter{
+		Labels:      labels,
+		Provider:    c.Query("provider"),
+		Environment: c.Query("environment"),
+		Region:      c.Query("region"),
+	})
 	utils.ApiSuccess(c, clusters, "successfully retrieved cluster list")
 }
 
@@ -39,10 +52,14 @@ func (h *ClusterHandler) GetCluster(c *gin.Context) {
 func (h *ClusterHandler) CreateCluster(c *gin.Context) {
 	var req models.CreateClusterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "request parameter error", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 	if err := h.service.CreateCluster(req); err != nil {
+		if errors.Is(err, k8s.ErrExecPluginNotAllowed) || errors.Is(err, k8s.ErrExecPluginNotFound) {
+			utils.ApiError(c, http.StatusBadRequest, "failed to create cluster", err.Error())
+			return
+		}
 		utils.ApiError(c, http.StatusInternalServerError, "failed to create cluster", err.Error())
 		return
 	}
@@ -54,7 +71,7 @@ func (h *ClusterHandler) UpdateCluster(c *gin.Context) {
 	clusterID := c.Param("id")
 	var req models.UpdateClusterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "request parameter error", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 	if err := h.service.UpdateCluster(clusterID, req); err != nil {
@@ -74,6 +91,23 @@ func (h *ClusterHandler) DeleteCluster(c *gin.Context) {
 	utils.ApiSuccess(c, nil, "cluster deleted successfully")
 }
 
+// ReloadCluster rebuilds the client of a cluster after its credentials were rotated
+func (h *ClusterHandler) ReloadCluster(c *gin.Context) {
+	info, err := h.service.ReloadCluster(c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, k8s.ErrClusterNotFound):
+			utils.ApiError(c, http.StatusNotFound, "cluster not found", err.Error())
+		case errors.Is(err, k8s.ErrClusterValidation):
+			utils.ApiError(c, http.StatusBadGateway, "failed to connect with the reloaded kubeconfig, the previous client is kept", err.Error())
+		default:
+			utils.ApiError(c, http.StatusInternalServerError, "failed to reload cluster", err.Error())
+		}
+		return
+	}
+	utils.ApiSuccess(c, info, "cluster reloaded successfully")
+}
+
 // SetActiveCluster sets the current active cluster
 func (h *ClusterHandler) SetActiveCluster(c *gin.Context) {
 	var req struct {
@@ -81,7 +115,7 @@ func (h *ClusterHandler) SetActiveCluster(c *gin.Context) {
 		Name string `json:"name"` // Maintain backward compatibility
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "request parameter error", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -91,7 +125,7 @@ func (h *ClusterHandler) SetActiveCluster(c *gin.Context) {
 		targetID = req.ID
 	} else if req.Name != "" {
 		// Backward compatibility: find cluster ID by name
-		clusters := h.service.ListClusters()
+		clusters := h.service.ListClusters(k8s.ClusterFilter{})
 		for _, cluster := range clusters {
 			if cluster.Name == req.Name {
 				targetID = cluster.ID
@@ -129,7 +163,7 @@ func (h *ClusterHandler) SetActiveCluster(c *gin.Context) {
 func (h *ClusterHandler) GetActiveCluster(c *gin.Context) {
 	activeClusterID := h.service.GetActiveClusterID()
 	if activeClusterID == "" {
-		utils.ApiError(c, http.StatusNotFound, "no active cluster currently", "please add and activate a cluster first")
+		utils.ApiErrorFrom(c, k8s.NoActiveClusterError())
 		return
 	}
 
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ContainerInfoHandler handles container image and env inspection requests
+type ContainerInfoHandler struct {
+	service        *service.ContainerInfoService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewContainerInfoHandler creates a new ContainerInfoHandler
+func NewContainerInfoHandler(svc *service.ContainerInfoService, cm *k8s.ClusterManager) *ContainerInfoHandler {
+	return &ContainerInfoHandler{
+		service:        svc,
+		clusterManager: cm,
+	}
+}
+
+// GetContainers handles GET /api/v1/clusters/:id/namespaces/:namespace/:resource/:name/containers?maskValues=true
+func (h *ContainerInfoHandler) GetContainers(c *gin.Context) {
+	maskValues, _ := strconv.ParseBool(c.Query("maskValues"))
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.GetContainers(c.Request.Context(), k8sClient.Clientset, c.Param("resource"), c.Param("namespace"), c.Param("name"), maskValues)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "resource not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to get containers", err)
+		return
+	}
+	utils.ApiSuccess(c, result, "successfully retrieved containers")
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// respondResource writes a Kubernetes object or list in the format asked for by the Accept header:
+// application/yaml (or application/x-yaml) returns the bare manifest as YAML, anything else the usual
+// JSON response with an ETag derived from version as in utils.ApiSuccessWithETag. Both formats are
+// rendered from the same copy, without managedFields, and keep the resourceVersion updates require.
+func respondResource(c *gin.Context, obj runtime.Object, message, version string) {
+	obj = resourceForResponse(obj)
+	c.Writer.Header().Add("Vary", "Accept")
+
+	switch c.NegotiateFormat(binding.MIMEJSON, binding.MIMEYAML2, binding.MIMEYAML) {
+	case binding.MIMEYAML2, binding.MIMEYAML:
+		// Going through JSON keeps the field names of the Kubernetes API
+		data, err := json.Marshal(obj)
+		if err == nil {
+			data, err = yaml.JSONToYAML(data)
+		}
+		if err != nil {
+			utils.ApiError(c, http.StatusInternalServerError, "failed to render resource as YAML", err.Error())
+			return
+		}
+		if version != "" {
+			// Lets a YAML editor send the version it loaded back as If-Match
+			c.Header("ETag", strconv.Quote(version))
+		}
+		c.Data(http.StatusOK, binding.MIMEYAML2+"; charset=utf-8", data)
+	default:
+		utils.ApiSuccessWithETag(c, obj, message, version)
+	}
+}
+
+// resourceForResponse returns a copy of obj without the managedFields of the object or of the list items,
+// and with its apiVersion and kind set when the client left them out
+func resourceForResponse(obj runtime.Object) runtime.Object {
+	obj = obj.DeepCopyObject()
+	if obj.GetObjectKind().GroupVersionKind().Empty() {
+		if gvks, _, err := scheme.Scheme.ObjectKinds(obj); err == nil && len(gvks) > 0 {
+			obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+		}
+	}
+
+	clearManagedFields := func(item runtime.Object) error {
+		if accessor, err := meta.Accessor(item); err == nil {
+			accessor.SetManagedFields(nil)
+		}
+		return nil
+	}
+	if meta.IsListType(obj) {
+		_ = meta.EachListItem(obj, clearManagedFields)
+	} else {
+		_ = clearManagedFields(obj)
+	}
+	return obj
+}
+
+// bindResource decodes the request body into obj, as YAML when the Content-Type says so and as JSON
+// otherwise, so a manifest fetched as YAML can be edited and sent back as is
+func bindResource(c *gin.Context, obj interface{}) error {
+	switch c.ContentType() {
+	case binding.MIMEYAML, binding.MIMEYAML2:
+		data, err := c.GetRawData()
+		if err != nil {
+			return err
+		}
+		return yaml.Unmarshal(data, obj)
+	default:
+		return c.ShouldBindJSON(obj)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ciliverse/cilikube/pkg/k8s"
 	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // CRDHandler handles CRD operations
@@ -138,11 +139,13 @@ func (h *CRDHandler) CreateCustomResource(c *gin.Context) {
 
 	var req models.CustomResourceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "invalid request body", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
 	resource, err := h.crdService.CreateCustomResource(k8sClient, group, version, plural, namespace, &req)
+	name, _ := req.Metadata["name"].(string)
+	auditResourceChange(c, schema.GroupVersionResource{Group: group, Version: version, Resource: plural}, namespace, name, service.ResourceActionCreate, err)
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "failed to create custom resource", err.Error())
 		return
@@ -171,11 +174,12 @@ func (h *CRDHandler) UpdateCustomResource(c *gin.Context) {
 
 	var req models.CustomResourceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "invalid request body", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
 	resource, err := h.crdService.UpdateCustomResource(k8sClient, group, version, plural, namespace, name, &req)
+	auditResourceChange(c, schema.GroupVersionResource{Group: group, Version: version, Resource: plural}, namespace, name, service.ResourceActionUpdate, err)
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "failed to update custom resource", err.Error())
 		return
@@ -203,6 +207,7 @@ func (h *CRDHandler) DeleteCustomResource(c *gin.Context) {
 	}
 
 	err := h.crdService.DeleteCustomResource(k8sClient, group, version, plural, namespace, name)
+	auditResourceChange(c, schema.GroupVersionResource{Group: group, Version: version, Resource: plural}, namespace, name, service.ResourceActionDelete, err)
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "failed to delete custom resource", err.Error())
 		return
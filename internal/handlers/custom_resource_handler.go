@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CustomResourceHandler handles generic custom resource requests addressed by group, version and plural
+type CustomResourceHandler struct {
+	service        *service.CustomResourceService
+	crdService     service.CRDService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewCustomResourceHandler creates a new CustomResourceHandler
+func NewCustomResourceHandler(svc *service.CustomResourceService, crdService service.CRDService, cm *k8s.ClusterManager) *CustomResourceHandler {
+	return &CustomResourceHandler{
+		service:        svc,
+		crdService:     crdService,
+		clusterManager: cm,
+	}
+}
+
+// ListCRDs handles GET /api/v1/clusters/:id/crds
+func (h *CustomResourceHandler) ListCRDs(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	crds, err := h.crdService.ListCRDs(k8sClient)
+	if err != nil {
+		h.respondError(c, "failed to get CRD list", err)
+		return
+	}
+	utils.ApiSuccess(c, crds, "successfully retrieved CRD list")
+}
+
+// List handles GET on a custom resource collection, answering in YAML or JSON according to the Accept header
+func (h *CustomResourceHandler) List(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 64)
+	opts := metav1.ListOptions{
+		LabelSelector: c.Query("labelSelector"),
+		Limit:         limit,
+		Continue:      c.Query("continue"),
+	}
+
+	list, err := h.service.List(k8sClient.DynamicClient, h.mapper(c, k8sClient), customResourceRef(c), opts)
+	if err != nil {
+		h.respondError(c, "failed to get custom resource list", err)
+		return
+	}
+	respondResource(c, list, "successfully retrieved custom resource list", list.GetResourceVersion())
+}
+
+// Get handles GET on a single custom resource, answering in YAML or JSON according to the Accept header
+func (h *CustomResourceHandler) Get(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	obj, err := h.service.Get(k8sClient.DynamicClient, h.mapper(c, k8sClient), customResourceRef(c))
+	if err != nil {
+		h.respondError(c, "failed to get custom resource", err)
+		return
+	}
+	respondResource(c, obj, "successfully retrieved custom resource", obj.GetResourceVersion())
+}
+
+// Create handles POST on a custom resource collection
+func (h *CustomResourceHandler) Create(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	var obj unstructured.Unstructured
+	if err := c.ShouldBindJSON(&obj.Object); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	created, err := h.service.Create(k8sClient.DynamicClient, h.mapper(c, k8sClient), customResourceRef(c), &obj)
+	h.audit(c, obj.GetName(), service.ResourceActionCreate, err)
+	if err != nil {
+		h.respondError(c, "failed to create custom resource", err)
+		return
+	}
+	utils.ApiSuccess(c, created, "custom resource created successfully")
+}
+
+// Update handles PUT on a single custom resource with a JSON or YAML body, conditional on the resourceVersion
+// the client edited
+func (h *CustomResourceHandler) Update(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	var obj unstructured.Unstructured
+	if err := bindResource(c, &obj.Object); err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if !requireResourceVersion(c, &obj) {
+		return
+	}
+
+	updated, err := h.service.Update(k8sClient.DynamicClient, h.mapper(c, k8sClient), customResourceRef(c), &obj)
+	h.audit(c, c.Param("name"), service.ResourceActionUpdate, err)
+	if err != nil {
+		respondUpdateError(c, "failed to update custom resource", err)
+		return
+	}
+	utils.ApiSuccess(c, updated, "custom resource updated successfully")
+}
+
+// Delete handles DELETE on a single custom resource
+func (h *CustomResourceHandler) Delete(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	err := h.service.Delete(k8sClient.DynamicClient, h.mapper(c, k8sClient), customResourceRef(c))
+	h.audit(c, c.Param("name"), service.ResourceActionDelete, err)
+	if err != nil {
+		h.respondError(c, "failed to delete custom resource", err)
+		return
+	}
+	utils.ApiSuccess(c, nil, "custom resource deleted successfully")
+}
+
+// mapper returns the RESTMapper of the cluster addressed by the request
+func (h *CustomResourceHandler) mapper(c *gin.Context, k8sClient *k8s.Client) meta.RESTMapper {
+	return h.service.MapperFor(c.Param("id"), k8sClient.DiscoveryClient)
+}
+
+// audit records a mutating operation on the custom resource addressed by the request
+func (h *CustomResourceHandler) audit(c *gin.Context, name, action string, err error) {
+	ref := customResourceRef(c)
+	gvr := schema.GroupVersionResource{Group: ref.Group, Version: ref.Version, Resource: ref.Plural}
+	auditResourceChange(c, gvr, ref.Namespace, name, action, err)
+}
+
+// respondError maps Kubernetes API errors to HTTP status codes and error codes
+func (h *CustomResourceHandler) respondError(c *gin.Context, message string, err error) {
+	respondKubernetesError(c, message, err)
+}
+
+// customResourceRef builds the resource reference from the request path
+func customResourceRef(c *gin.Context) service.CustomResourceRef {
+	return service.CustomResourceRef{
+		Group:     c.Param("group"),
+		Version:   c.Param("version"),
+		Plural:    c.Param("plural"),
+		Namespace: c.Param("namespace"),
+		Name:      c.Param("name"),
+	}
+}
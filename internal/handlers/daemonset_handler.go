@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// DaemonSetHandler handles DaemonSet requests beyond generic CRUD
+type DaemonSetHandler struct {
+	clusterManager *k8s.ClusterManager
+}
+
+// NewDaemonSetHandler creates a new DaemonSetHandler
+func NewDaemonSetHandler(cm *k8s.ClusterManager) *DaemonSetHandler {
+	return &DaemonSetHandler{clusterManager: cm}
+}
+
+// ListItems handles GET /namespaces/:namespace/daemonsets/summary
+func (h *DaemonSetHandler) ListItems(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	items, err := service.ListDaemonSetItems(c.Request.Context(), k8sClient.Clientset, c.Param("namespace"))
+	if err != nil {
+		respondKubernetesError(c, "failed to get daemon set list", err)
+		return
+	}
+	utils.ApiSuccess(c, items, "successfully retrieved daemon set list")
+}
+
+// GetStatus handles GET /namespaces/:namespace/daemonsets/:name/status
+func (h *DaemonSetHandler) GetStatus(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	item, err := service.GetDaemonSetItem(c.Request.Context(), k8sClient.Clientset, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "daemon set not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to get daemon set status", err)
+		return
+	}
+	utils.ApiSuccess(c, item, "successfully retrieved daemon set status")
+}
+
+// UpdateStrategy handles PUT /namespaces/:namespace/daemonsets/:name/strategy
+func (h *DaemonSetHandler) UpdateStrategy(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	var req models.UpdateDaemonSetStrategyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	ds, err := service.SetDaemonSetUpdateStrategy(c.Request.Context(), k8sClient.Clientset, namespace, name, &req)
+	auditResourceChange(c, appsv1.SchemeGroupVersion.WithResource("daemonsets"), namespace, name, service.ResourceActionUpdate, err)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "daemon set not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to update daemon set strategy", err)
+		return
+	}
+	utils.ApiSuccess(c, service.DaemonSetItemFrom(ds), "daemon set update strategy updated successfully")
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() interface{} { return runtime.NumGoroutine() }))
+}
+
+// RuntimeStats is a snapshot of the Go runtime of the server process
+type RuntimeStats struct {
+	GoVersion     string    `json:"goVersion"`
+	NumCPU        int       `json:"numCPU"`
+	Goroutines    int       `json:"goroutines"`
+	HeapAlloc     uint64    `json:"heapAlloc"`
+	HeapInuse     uint64    `json:"heapInuse"`
+	HeapObjects   uint64    `json:"heapObjects"`
+	Sys           uint64    `json:"sys"`
+	NumGC         uint32    `json:"numGC"`
+	LastGC        time.Time `json:"lastGC,omitempty"`
+	PauseTotalNs  uint64    `json:"pauseTotalNs"`
+	GCCPUFraction float64   `json:"gcCPUFraction"`
+	StartedAt     time.Time `json:"startedAt"`
+}
+
+// DebugHandler serves profiling and runtime statistics for diagnosing the server process
+type DebugHandler struct {
+	startedAt time.Time
+}
+
+// NewDebugHandler creates a new DebugHandler
+func NewDebugHandler() *DebugHandler {
+	return &DebugHandler{startedAt: time.Now()}
+}
+
+// Pprof handles GET /debug/pprof/*profile, serving the net/http/pprof index and profiles
+func (h *DebugHandler) Pprof(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("profile"), "/") {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		capProfileSeconds(c.Request, 30)
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		capProfileSeconds(c.Request, 1)
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// Index serves both the listing and named profiles such as goroutine or heap
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
+// capProfileSeconds keeps the seconds a CPU profile or trace runs for, defaultSeconds when unset, below the
+// server write timeout: net/http/pprof refuses durations reaching it, which with the default 30s write
+// timeout includes a CPU profile requested without seconds
+func capProfileSeconds(r *http.Request, defaultSeconds int) {
+	srv, ok := r.Context().Value(http.ServerContextKey).(*http.Server)
+	if !ok || srv.WriteTimeout <= 0 {
+		return
+	}
+	limit := int(srv.WriteTimeout.Seconds()) - 1
+	if limit < 1 {
+		return
+	}
+
+	query := r.URL.Query()
+	seconds, err := strconv.Atoi(query.Get("seconds"))
+	if err != nil || seconds <= 0 {
+		seconds = defaultSeconds
+	}
+	if seconds > limit {
+		query.Set("seconds", strconv.Itoa(limit))
+		r.URL.RawQuery = query.Encode()
+	}
+}
+
+// Vars handles GET /debug/vars, serving the expvar variables including memstats and goroutines
+func (h *DebugHandler) Vars(c *gin.Context) {
+	expvar.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// GetRuntimeStats handles GET /debug/runtime
+func (h *DebugHandler) GetRuntimeStats(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := RuntimeStats{
+		GoVersion:     runtime.Version(),
+		NumCPU:        runtime.NumCPU(),
+		Goroutines:    runtime.NumGoroutine(),
+		HeapAlloc:     mem.HeapAlloc,
+		HeapInuse:     mem.HeapInuse,
+		HeapObjects:   mem.HeapObjects,
+		Sys:           mem.Sys,
+		NumGC:         mem.NumGC,
+		PauseTotalNs:  mem.PauseTotalNs,
+		GCCPUFraction: mem.GCCPUFraction,
+		StartedAt:     h.startedAt,
+	}
+	if mem.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(mem.LastGC))
+	}
+	utils.ApiSuccess(c, stats, "successfully retrieved runtime statistics")
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapProfileSeconds(t *testing.T) {
+	seconds := func(writeTimeout time.Duration, target string, defaultSeconds int) string {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, &http.Server{WriteTimeout: writeTimeout}))
+		capProfileSeconds(req, defaultSeconds)
+		return req.URL.Query().Get("seconds")
+	}
+
+	assert.Equal(t, "29", seconds(30*time.Second, "/debug/pprof/profile", 30), "the default profile runs within the write timeout")
+	assert.Equal(t, "29", seconds(30*time.Second, "/debug/pprof/profile?seconds=120", 30))
+	assert.Equal(t, "10", seconds(30*time.Second, "/debug/pprof/profile?seconds=10", 30))
+	assert.Equal(t, "", seconds(30*time.Second, "/debug/pprof/trace", 1))
+	assert.Equal(t, "120", seconds(0, "/debug/pprof/profile?seconds=120", 30), "without a write timeout any duration is allowed")
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// DeploymentRolloutHandler handles Deployment rollout history and rollback requests
+type DeploymentRolloutHandler struct {
+	service        *service.DeploymentRolloutService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewDeploymentRolloutHandler creates a new DeploymentRolloutHandler
+func NewDeploymentRolloutHandler(svc *service.DeploymentRolloutService, cm *k8s.ClusterManager) *DeploymentRolloutHandler {
+	return &DeploymentRolloutHandler{
+		service:        svc,
+		clusterManager: cm,
+	}
+}
+
+// ListRevisions handles GET /namespaces/:namespace/deployments/:name/revisions
+func (h *DeploymentRolloutHandler) ListRevisions(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	revisions, err := h.service.ListRevisions(c.Request.Context(), k8sClient.Clientset, namespace, name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "deployment not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to get deployment revisions", err)
+		return
+	}
+	utils.ApiSuccess(c, revisions, "successfully retrieved deployment revisions")
+}
+
+// Rollback handles POST /namespaces/:namespace/deployments/:name/rollback
+func (h *DeploymentRolloutHandler) Rollback(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	var req models.DeploymentRollbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	deployment, err := h.service.Rollback(c.Request.Context(), k8sClient.Clientset, namespace, name, req.Revision)
+	auditResourceChange(c, appsv1.SchemeGroupVersion.WithResource("deployments"), namespace, name, service.ResourceActionRollback, err)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRevisionNotFound):
+			utils.ApiError(c, http.StatusNotFound, "revision not found", err.Error())
+		case k8serrors.IsNotFound(err):
+			utils.ApiError(c, http.StatusNotFound, "deployment not found", err.Error())
+		default:
+			respondKubernetesError(c, "failed to roll back deployment", err)
+		}
+		return
+	}
+	utils.ApiSuccess(c, deployment, "deployment rolled back successfully")
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DescribeHandler handles kubectl describe style requests
+type DescribeHandler struct {
+	service               *service.DescribeService
+	customResourceService *service.CustomResourceService
+	clusterManager        *k8s.ClusterManager
+}
+
+// NewDescribeHandler creates a new DescribeHandler
+func NewDescribeHandler(svc *service.DescribeService, customResourceService *service.CustomResourceService, cm *k8s.ClusterManager) *DescribeHandler {
+	return &DescribeHandler{
+		service:               svc,
+		customResourceService: customResourceService,
+		clusterManager:        cm,
+	}
+}
+
+// Describe handles GET /api/v1/clusters/:id/namespaces/:namespace/:resource/:name/describe
+func (h *DescribeHandler) Describe(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	mapper := h.customResourceService.MapperFor(c.Param("id"), k8sClient.DiscoveryClient)
+	description, err := h.service.Describe(c.Request.Context(), k8sClient.Clientset, k8sClient.DynamicClient, mapper,
+		c.Param("namespace"), c.Param("resource"), c.Param("name"))
+	if err != nil {
+		respondKubernetesError(c, "failed to describe resource", err)
+		return
+	}
+	utils.ApiSuccess(c, description, "successfully described "+description.Resource+" "+description.Metadata.Name)
+}
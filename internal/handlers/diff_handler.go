@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// maxManifestSize limits the size of a manifest submitted for diffing
+const maxManifestSize = 1 << 20
+
+// DiffHandler handles requests comparing manifests with the live cluster state
+type DiffHandler struct {
+	service               *service.DiffService
+	customResourceService *service.CustomResourceService
+	clusterManager        *k8s.ClusterManager
+}
+
+// NewDiffHandler creates a new DiffHandler
+func NewDiffHandler(svc *service.DiffService, customResourceService *service.CustomResourceService, cm *k8s.ClusterManager) *DiffHandler {
+	return &DiffHandler{
+		service:               svc,
+		customResourceService: customResourceService,
+		clusterManager:        cm,
+	}
+}
+
+// Diff handles POST /api/v1/clusters/:id/diff. The body is a YAML or JSON manifest.
+// Server-managed fields are ignored unless includeServerFields=true is given. Without a namespace
+// parameter the caller's preferred namespace for the cluster is used before the "default" namespace.
+func (h *DiffHandler) Diff(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	// One byte past the limit tells an oversized manifest apart from one of exactly the limit
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxManifestSize+1))
+	if err != nil {
+		status := http.StatusBadRequest
+		if utils.IsBodyTooLarge(err) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		utils.ApiError(c, status, "failed to read request body", err.Error())
+		return
+	}
+	if len(data) > maxManifestSize {
+		utils.ApiError(c, http.StatusRequestEntityTooLarge, "manifest too large", fmt.Sprintf("the limit is %d bytes", maxManifestSize))
+		return
+	}
+	var desired unstructured.Unstructured
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096).Decode(&desired.Object); err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid manifest", err.Error())
+		return
+	}
+	if desired.Object == nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid manifest", "manifest is empty")
+		return
+	}
+
+	includeServerFields, _ := strconv.ParseBool(c.Query("includeServerFields"))
+	mapper := h.customResourceService.MapperFor(c.Param("id"), k8sClient.DiscoveryClient)
+	namespace := preferredNamespace(c, c.Query("namespace"))
+	diff, err := h.service.Diff(k8sClient.DynamicClient, mapper, &desired, namespace, service.DiffOptions{IncludeServerFields: includeServerFields})
+	if err != nil {
+		respondKubernetesError(c, "failed to diff resource", err)
+		return
+	}
+	utils.ApiSuccess(c, diff, "successfully computed resource diff")
+}
+
+// Compare handles POST /api/v1/compare, comparing the object named by the body in two clusters, e.g. to
+// detect drift between staging and prod. Server-managed fields are ignored unless includeServerFields is set.
+func (h *DiffHandler) Compare(c *gin.Context) {
+	var req models.CompareRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	from, ok := h.compareTarget(c, req.FromClusterID)
+	if !ok {
+		return
+	}
+	to, ok := h.compareTarget(c, req.ToClusterID)
+	if !ok {
+		return
+	}
+
+	gvr := schema.GroupVersionResource{Group: req.Group, Version: req.Version, Resource: req.Resource}
+	comparison, err := h.service.Compare(c.Request.Context(), from, to, gvr, req.Namespace, req.Name, service.DiffOptions{IncludeServerFields: req.IncludeServerFields})
+	if err != nil {
+		respondKubernetesError(c, "failed to compare resource", err)
+		return
+	}
+	utils.ApiSuccess(c, comparison, "successfully compared resource")
+}
+
+// compareTarget returns the client and mapper of a compared cluster, answering the request when it is unknown
+// or unreachable
+func (h *DiffHandler) compareTarget(c *gin.Context, clusterID string) (service.CompareTarget, bool) {
+	k8sClient, ok := k8s.GetClientByID(c, h.clusterManager, clusterID)
+	if !ok {
+		return service.CompareTarget{}, false
+	}
+	return service.CompareTarget{
+		ClusterID: clusterID,
+		Client:    k8sClient.DynamicClient,
+		Mapper:    h.customResourceService.MapperFor(clusterID, k8sClient.DiscoveryClient),
+	}, true
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffHandler_RejectsOversizedManifest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clusterManager, _, clusterID := newFakeCluster(t, newFakeAPIServer(t).URL)
+	router := gin.New()
+	router.POST("/clusters/:id/diff", NewDiffHandler(service.NewDiffService(), nil, clusterManager).Diff)
+
+	diff := func(size int) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/clusters/"+clusterID+"/diff", strings.NewReader(strings.Repeat("a", size))))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusRequestEntityTooLarge, diff(maxManifestSize+1), "oversized manifests are not truncated")
+	assert.Equal(t, http.StatusBadRequest, diff(maxManifestSize), "a manifest of exactly the limit is parsed")
+}
@@ -1,21 +1,31 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/ciliverse/cilikube/internal/models"
 	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
 	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
 )
 
+// eventStreamHeartbeat keeps idle event streams open through proxies
+const eventStreamHeartbeat = 30 * time.Second
+
 type EventHandler struct {
-	service *service.EventService
+	service        *service.EventService
+	clusterManager *k8s.ClusterManager
 }
 
-func NewEventHandler(svc *service.EventService) *EventHandler {
-	return &EventHandler{service: svc}
+func NewEventHandler(svc *service.EventService, cm *k8s.ClusterManager) *EventHandler {
+	return &EventHandler{service: svc, clusterManager: cm}
 }
 
 // ListEvents handles GET /api/v1/events
@@ -23,7 +33,7 @@ func (h *EventHandler) ListEvents(c *gin.Context) {
 	var req models.EventListRequest
 
 	// Parse query parameters
-	req.Namespace = c.Query("namespace")
+	req.Namespace = preferredNamespace(c, c.Query("namespace"))
 	req.Type = c.Query("type")
 	req.Since = c.Query("since")
 
@@ -33,9 +43,14 @@ func (h *EventHandler) ListEvents(c *gin.Context) {
 		}
 	}
 
-	response, err := h.service.ListEvents(req)
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.ListEvents(k8sClient.Clientset, req)
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to retrieve cluster events", err.Error())
+		respondKubernetesError(c, "failed to retrieve cluster events", err)
 		return
 	}
 
@@ -51,9 +66,14 @@ func (h *EventHandler) GetRecentEvents(c *gin.Context) {
 		}
 	}
 
-	events, err := h.service.GetRecentEvents(limit)
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	events, err := h.service.GetRecentEvents(k8sClient.Clientset, limit)
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to retrieve recent events", err.Error())
+		respondKubernetesError(c, "failed to retrieve recent events", err)
 		return
 	}
 
@@ -74,9 +94,14 @@ func (h *EventHandler) GetEventsByObject(c *gin.Context) {
 		return
 	}
 
-	events, err := h.service.GetEventsByObject(namespace, kind, name)
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	events, err := h.service.GetEventsByObject(k8sClient.Clientset, namespace, kind, name)
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to retrieve object events", err.Error())
+		respondKubernetesError(c, "failed to retrieve object events", err)
 		return
 	}
 
@@ -90,3 +115,57 @@ func (h *EventHandler) GetEventsByObject(c *gin.Context) {
 		},
 	}, "successfully retrieved object events")
 }
+
+// StreamClusterEvents handles GET /api/v1/clusters/:id/events/stream?type=Warning.
+// Events of all namespaces are pushed as Server-Sent Events as they occur, repeats of an event for
+// the same object and reason within a short window are dropped.
+func (h *EventHandler) StreamClusterEvents(c *gin.Context) {
+	eventType := c.Query("type")
+	if eventType != "" && eventType != corev1.EventTypeNormal && eventType != corev1.EventTypeWarning {
+		utils.ApiError(c, http.StatusBadRequest, "invalid parameters", "type must be Normal or Warning")
+		return
+	}
+
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	events, err := h.service.StreamEvents(c.Request.Context(), k8sClient.Clientset, service.EventStreamOptions{Type: eventType})
+	if err != nil {
+		respondKubernetesError(c, "failed to watch cluster events", err)
+		return
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	// The watch is stopped when the request context is cancelled on disconnect
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "event: event\ndata: %s\n\n", data); err != nil {
+				log.Printf("SSE: Failed to write cluster event to client: %v", err)
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
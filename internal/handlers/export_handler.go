@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler handles namespace export (backup) requests
+type ExportHandler struct {
+	service           *service.ExportService
+	permissionService *service.PermissionService
+	clusterManager    *k8s.ClusterManager
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(svc *service.ExportService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *ExportHandler {
+	return &ExportHandler{
+		service:           svc,
+		permissionService: permissionService,
+		clusterManager:    cm,
+	}
+}
+
+// ExportNamespace handles GET /api/v1/clusters/:id/namespaces/:namespace/export
+func (h *ExportHandler) ExportNamespace(c *gin.Context) {
+	format := c.DefaultQuery("format", service.ExportFormatTarGz)
+	if format != service.ExportFormatTarGz && format != service.ExportFormatYAML {
+		utils.ApiError(c, http.StatusBadRequest, "invalid format", "format must be "+service.ExportFormatTarGz+" or "+service.ExportFormatYAML)
+		return
+	}
+
+	// kinds may be repeated or comma-separated
+	var requested []string
+	for _, value := range c.QueryArray("kinds") {
+		requested = append(requested, strings.Split(value, ",")...)
+	}
+	kinds, err := h.service.ResolveKinds(requested)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid kinds", err.Error())
+		return
+	}
+
+	namespace := c.Param("namespace")
+	kinds, ok := h.readableKinds(c, namespace, kinds, len(requested) > 0)
+	if !ok {
+		return
+	}
+
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	if k8sClient.DynamicClient == nil {
+		utils.ApiError(c, http.StatusInternalServerError, "dynamic client not available", "")
+		return
+	}
+
+	objects, err := h.service.Collect(c.Request.Context(), k8sClient.DynamicClient, namespace, kinds)
+	if err != nil {
+		respondKubernetesError(c, "failed to export namespace", err)
+		return
+	}
+
+	contentType := "application/gzip"
+	if format == service.ExportFormatYAML {
+		contentType = "application/x-yaml"
+	}
+	filename := fmt.Sprintf("%s-%s-%s.%s", c.Param("id"), namespace, time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+
+	// The response has started, so a failure here can only be logged
+	if err := h.service.Write(c.Writer, format, namespace, objects); err != nil {
+		log.Printf("failed to write export of namespace %s in cluster %s: %v", namespace, c.Param("id"), err)
+	}
+}
+
+// readableKinds keeps the kinds the current user may read in the namespace. Explicitly requested
+// kinds that are not readable are rejected, while the default selection silently drops them.
+// Admins may read every kind.
+func (h *ExportHandler) readableKinds(c *gin.Context, namespace string, kinds []service.ExportKind, explicit bool) ([]service.ExportKind, bool) {
+	userID, admin, ok := requirePermissionSubject(c, h.permissionService)
+	if !ok {
+		return nil, false
+	}
+	if admin {
+		return kinds, true
+	}
+
+	readable := make([]service.ExportKind, 0, len(kinds))
+	var denied []string
+	for _, kind := range kinds {
+		object := fmt.Sprintf("/api/v1/namespaces/%s/%s", namespace, kind.Resource)
+		allowed, err := h.permissionService.CheckPermission(userID, object, http.MethodGet)
+		if err != nil {
+			utils.ApiError(c, http.StatusInternalServerError, "failed to check permission", err.Error())
+			return nil, false
+		}
+		if allowed {
+			readable = append(readable, kind)
+		} else {
+			denied = append(denied, kind.Resource)
+		}
+	}
+
+	if explicit && len(denied) > 0 {
+		utils.ApiError(c, http.StatusForbidden, "permission denied", "reading "+strings.Join(denied, ", ")+" in namespace "+namespace+" is not permitted")
+		return nil, false
+	}
+	if len(readable) == 0 {
+		utils.ApiError(c, http.StatusForbidden, "permission denied", "no exportable kinds are readable in namespace "+namespace)
+		return nil, false
+	}
+	return readable, true
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// FavoriteHandler handles the caller's favorite resources
+type FavoriteHandler struct {
+	service           *service.FavoriteService
+	permissionService *service.PermissionService
+}
+
+// NewFavoriteHandler creates a new FavoriteHandler
+func NewFavoriteHandler(svc *service.FavoriteService, permissionService *service.PermissionService) *FavoriteHandler {
+	return &FavoriteHandler{service: svc, permissionService: permissionService}
+}
+
+// ListFavorites handles GET /api/v1/favorites
+func (h *FavoriteHandler) ListFavorites(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information not found", "")
+		return
+	}
+	favorites, err := h.service.ListFavorites(userID)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to list favorites", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, favorites, "successfully retrieved favorites")
+}
+
+// AddFavorite handles POST /api/v1/favorites
+func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information not found", "")
+		return
+	}
+	var req models.AddFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+	favorite, err := h.service.AddFavorite(userID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFavorite) {
+			utils.ApiError(c, http.StatusBadRequest, "invalid favorite", err.Error())
+			return
+		}
+		utils.ApiError(c, http.StatusInternalServerError, "failed to add favorite", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, favorite, "successfully added favorite")
+}
+
+// RemoveFavorite handles DELETE /api/v1/favorites/:id
+func (h *FavoriteHandler) RemoveFavorite(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information not found", "")
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid favorite ID", err.Error())
+		return
+	}
+	if err := h.service.RemoveFavorite(userID, uint(id)); err != nil {
+		if errors.Is(err, store.ErrFavoriteNotFound) {
+			utils.ApiError(c, http.StatusNotFound, "favorite not found", "")
+			return
+		}
+		utils.ApiError(c, http.StatusInternalServerError, "failed to remove favorite", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, nil, "successfully removed favorite")
+}
+
+// ResolveFavorites handles GET /api/v1/favorites/resolved, returning the caller's favorites with the
+// current status of their objects. Favorites the caller may no longer read are reported with an error.
+func (h *FavoriteHandler) ResolveFavorites(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information not found", "")
+		return
+	}
+	allowed, ok := namespacedResourceFilter(c, h.permissionService, http.MethodGet)
+	if !ok {
+		return
+	}
+
+	favorites, err := h.service.ResolveFavorites(c.Request.Context(), userID, allowed)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to resolve favorites", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, favorites, "successfully resolved favorites")
+}
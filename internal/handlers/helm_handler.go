@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// HelmHandler handles Helm release requests
+type HelmHandler struct {
+	service        *service.HelmService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewHelmHandler creates a new HelmHandler
+func NewHelmHandler(svc *service.HelmService, cm *k8s.ClusterManager) *HelmHandler {
+	return &HelmHandler{
+		service:        svc,
+		clusterManager: cm,
+	}
+}
+
+// ListReleases handles GET /api/v1/clusters/:id/helm/releases
+func (h *HelmHandler) ListReleases(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	releases, err := h.service.ListReleases(k8sClient.Clientset, preferredNamespace(c, c.Query("namespace")))
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to get helm release list", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, releases, "successfully retrieved helm release list")
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ImageScanHandler handles pod image vulnerability scan requests
+type ImageScanHandler struct {
+	service        *service.ImageScanService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewImageScanHandler creates a new ImageScanHandler
+func NewImageScanHandler(svc *service.ImageScanService, cm *k8s.ClusterManager) *ImageScanHandler {
+	return &ImageScanHandler{
+		service:        svc,
+		clusterManager: cm,
+	}
+}
+
+// scanResponseMargin is the time left to send a scan result once the scan timeout is reached
+const scanResponseMargin = 10 * time.Second
+
+// Timeout returns the read and write deadline of scan requests: the scan timeout plus the time to answer
+func (h *ImageScanHandler) Timeout() time.Duration {
+	if h.service == nil {
+		return scanResponseMargin
+	}
+	return h.service.Timeout() + scanResponseMargin
+}
+
+// ScanPod handles GET /api/v1/clusters/:id/namespaces/:namespace/pods/:name/scan
+func (h *ImageScanHandler) ScanPod(c *gin.Context) {
+	if h.service == nil || !h.service.Enabled() {
+		utils.ApiError(c, http.StatusNotImplemented, "image scanning is not configured", service.ErrScannerNotConfigured.Error())
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ScanPod(c.Request.Context(), k8sClient.Clientset, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrScannerNotConfigured):
+			utils.ApiError(c, http.StatusNotImplemented, "image scanning is not configured", err.Error())
+		case k8serrors.IsNotFound(err):
+			utils.ApiError(c, http.StatusNotFound, "pod not found", err.Error())
+		default:
+			respondKubernetesError(c, "failed to scan pod images", err)
+		}
+		return
+	}
+	utils.ApiSuccess(c, result, "successfully scanned pod images")
+}
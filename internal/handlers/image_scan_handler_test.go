@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageScanHandler_NotConfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewImageScanHandler(service.NewImageScanService(configs.ImageScanConfig{}), nil)
+	router.GET("/clusters/:id/namespaces/:namespace/pods/:name/scan", handler.ScanPod)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/clusters/c1/namespaces/test/pods/web-1/scan", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
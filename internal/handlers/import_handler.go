@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportHandler handles restoring exported namespace archives
+type ImportHandler struct {
+	service               *service.ImportService
+	customResourceService *service.CustomResourceService
+	permissionService     *service.PermissionService
+	clusterManager        *k8s.ClusterManager
+}
+
+// NewImportHandler creates a new ImportHandler
+func NewImportHandler(svc *service.ImportService, customResourceService *service.CustomResourceService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *ImportHandler {
+	return &ImportHandler{
+		service:               svc,
+		customResourceService: customResourceService,
+		permissionService:     permissionService,
+		clusterManager:        cm,
+	}
+}
+
+// ImportNamespace handles POST /api/v1/clusters/:id/namespaces/:namespace/import?dryRun=true.
+// The body is a tar.gz archive or multi-document YAML produced by the export, sent as the raw body
+// or as the "file" field of a multipart form. Objects are applied into the namespace in the path.
+func (h *ImportHandler) ImportNamespace(c *gin.Context) {
+	allowed, ok := namespacedResourceFilter(c, h.permissionService, http.MethodPost)
+	if !ok {
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+	data, ok := readImportArchive(c)
+	if !ok {
+		return
+	}
+	objects, err := h.service.ParseArchive(data)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid archive", err.Error())
+		return
+	}
+	if len(objects) == 0 {
+		utils.ApiError(c, http.StatusBadRequest, "invalid archive", "the archive contains no objects")
+		return
+	}
+
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	if k8sClient.DynamicClient == nil {
+		utils.ApiError(c, http.StatusInternalServerError, "dynamic client not available", "")
+		return
+	}
+
+	namespace := c.Param("namespace")
+	opts := service.ImportOptions{DryRun: dryRun}
+	if allowed != nil {
+		opts.Allowed = func(resource string) bool {
+			return allowed(namespace, resource)
+		}
+	}
+
+	mapper := h.customResourceService.MapperFor(c.Param("id"), k8sClient.DiscoveryClient)
+	result := h.service.Import(c.Request.Context(), k8sClient.DynamicClient, mapper, namespace, objects, opts)
+	if !result.DryRun {
+		for _, object := range result.Objects {
+			var err error
+			if object.Status == service.ImportFailed {
+				err = errors.New(object.Error)
+			}
+			auditResourceChange(c, kindGVR(mapper, object.APIVersion, object.Kind), namespace, object.Name, service.ResourceActionApply, err)
+		}
+	}
+	utils.ApiSuccess(c, result, fmt.Sprintf("applied %d of %d objects", result.Applied, len(result.Objects)))
+}
+
+// readImportArchive reads the archive from the "file" form field of a multipart request, or from the raw body
+func readImportArchive(c *gin.Context) ([]byte, bool) {
+	body := c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		opened, err := file.Open()
+		if err != nil {
+			utils.ApiError(c, http.StatusBadRequest, "failed to read uploaded file", err.Error())
+			return nil, false
+		}
+		defer opened.Close()
+		body = opened
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		status := http.StatusBadRequest
+		if utils.IsBodyTooLarge(err) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		utils.ApiError(c, status, "failed to read request body", err.Error())
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ActionGenerateKubeconfig is the permission action required to generate ServiceAccount kubeconfigs
+const ActionGenerateKubeconfig = "generate:kubeconfig"
+
+// KubeconfigHandler handles ServiceAccount kubeconfig requests
+type KubeconfigHandler struct {
+	service           *service.KubeconfigService
+	permissionService *service.PermissionService
+	clusterManager    *k8s.ClusterManager
+}
+
+// NewKubeconfigHandler creates a new KubeconfigHandler
+func NewKubeconfigHandler(svc *service.KubeconfigService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *KubeconfigHandler {
+	return &KubeconfigHandler{
+		service:           svc,
+		permissionService: permissionService,
+		clusterManager:    cm,
+	}
+}
+
+// GenerateServiceAccountKubeconfig handles POST /api/v1/clusters/:id/serviceaccounts/:namespace/:name/kubeconfig
+func (h *KubeconfigHandler) GenerateServiceAccountKubeconfig(c *gin.Context) {
+	if !h.authorize(c) {
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	var req models.ServiceAccountKubeconfigRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ApiBindError(c, err)
+			return
+		}
+	}
+
+	clusterID := c.Param("id")
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	data, err := h.service.GenerateServiceAccountKubeconfig(k8sClient.Clientset, k8sClient.Config, clusterID, namespace, name, req.ExpirationSeconds)
+	auditResourceChange(c, corev1.SchemeGroupVersion.WithResource("serviceaccounts"), namespace, name, service.ResourceActionKubeconfig, err)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "service account not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to generate kubeconfig", err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s.kubeconfig", clusterID, namespace, name)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/x-yaml", data)
+}
+
+// authorize checks the generate:kubeconfig permission of the current user on the request path
+func (h *KubeconfigHandler) authorize(c *gin.Context) bool {
+	return authorizeObject(c, h.permissionService, c.Request.URL.Path, ActionGenerateKubeconfig,
+		"generating kubeconfig requires the "+ActionGenerateKubeconfig+" permission")
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ManifestValidationHandler handles linting manifests without applying them
+type ManifestValidationHandler struct {
+	service               *service.ManifestValidationService
+	customResourceService *service.CustomResourceService
+	clusterManager        *k8s.ClusterManager
+}
+
+// NewManifestValidationHandler creates a new ManifestValidationHandler
+func NewManifestValidationHandler(svc *service.ManifestValidationService, customResourceService *service.CustomResourceService, cm *k8s.ClusterManager) *ManifestValidationHandler {
+	return &ManifestValidationHandler{
+		service:               svc,
+		customResourceService: customResourceService,
+		clusterManager:        cm,
+	}
+}
+
+// Validate handles POST /api/v1/validate?clusterId=<id>&namespace=<namespace>. The body is multi-document
+// YAML or JSON, sent as the raw body or as the "file" field of a multipart form. The manifests are checked
+// offline unless a cluster is named with the clusterId query parameter or the X-Cluster-ID header, which
+// also resolves custom resources and dry-runs every object on that cluster. Invalid documents are reported
+// in the result, not as an error response.
+func (h *ManifestValidationHandler) Validate(c *gin.Context) {
+	data, ok := readImportArchive(c)
+	if !ok {
+		return
+	}
+
+	var target *service.ManifestValidationTarget
+	if clusterID := k8s.ExplicitClusterID(c); clusterID != "" {
+		k8sClient, ok := k8s.GetClientByID(c, h.clusterManager, clusterID)
+		if !ok {
+			return
+		}
+		if k8sClient.DynamicClient == nil {
+			utils.ApiError(c, http.StatusInternalServerError, "dynamic client not available", "")
+			return
+		}
+		target = &service.ManifestValidationTarget{
+			Client:    k8sClient.DynamicClient,
+			Mapper:    h.customResourceService.MapperFor(clusterID, k8sClient.DiscoveryClient),
+			Namespace: c.Query("namespace"),
+		}
+	}
+
+	result, err := h.service.Validate(c.Request.Context(), data, target)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid manifest", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, result, fmt.Sprintf("%d of %d documents are valid", result.Documents-result.Invalid, result.Documents))
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MetadataHandler handles changing the labels and annotations of resources
+type MetadataHandler struct {
+	service               *service.MetadataService
+	customResourceService *service.CustomResourceService
+	permissionService     *service.PermissionService
+	clusterManager        *k8s.ClusterManager
+}
+
+// NewMetadataHandler creates a new MetadataHandler
+func NewMetadataHandler(svc *service.MetadataService, customResourceService *service.CustomResourceService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *MetadataHandler {
+	return &MetadataHandler{
+		service:               svc,
+		customResourceService: customResourceService,
+		permissionService:     permissionService,
+		clusterManager:        cm,
+	}
+}
+
+// PatchMetadata handles PATCH /api/v1/clusters/:id/namespaces/:namespace/:resource/:name/metadata
+func (h *MetadataHandler) PatchMetadata(c *gin.Context) {
+	namespace := c.Param("namespace")
+	resource := c.Param("resource")
+	name := c.Param("name")
+
+	var patch service.MetadataPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+	if err := patch.Validate(); err != nil {
+		respondKubernetesError(c, "invalid metadata patch", err)
+		return
+	}
+	if !authorizeNamespacedResource(c, h.permissionService, namespace, resource, http.MethodPatch) {
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	mapper := h.customResourceService.MapperFor(c.Param("id"), k8sClient.DiscoveryClient)
+	result, err := h.service.PatchMetadata(c.Request.Context(), k8sClient.DynamicClient, mapper, namespace, resource, name, patch)
+	auditResourceChange(c, resolveGVR(mapper, resource), namespace, name, service.ResourceActionPatch, err)
+	if err != nil {
+		respondKubernetesError(c, "failed to patch metadata", err)
+		return
+	}
+	utils.ApiSuccess(c, result, fmt.Sprintf("updated metadata of %s %s", result.Resource, result.Name))
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataHandler_Guards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/clusters/:id/namespaces/:namespace/:resource/:name/metadata", NewMetadataHandler(nil, nil, nil, nil).PatchMetadata)
+
+	for body, status := range map[string]int{
+		`not json`:                        http.StatusBadRequest,
+		`{}`:                              http.StatusBadRequest,
+		`{"labels":{"bad key":"x"}}`:      http.StatusBadRequest,
+		`{"removeAnnotations":["a/b/c"]}`: http.StatusBadRequest,
+		// Without an authenticated user nothing is patched even when the patch is valid
+		`{"labels":{"env":"prod"}}`: http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/clusters/c1/namespaces/test/deployments/web/metadata", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, body)
+	}
+}
@@ -1,11 +1,19 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -40,6 +48,73 @@ func (h *MonitoringHandler) GetRealTimeMetrics(c *gin.Context) {
 	})
 }
 
+// monitoringStreamHeartbeat keeps idle SSE connections open through proxies
+const monitoringStreamHeartbeat = 30 * time.Second
+
+// StreamUpdates pushes metrics and alerts to the client as they are produced
+// @Summary Stream monitoring updates
+// @Description Server-Sent Events stream of real-time metrics and new alerts
+// @Tags Monitoring
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {object} service.MonitoringEvent
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /api/v1/monitoring/stream [get]
+func (h *MonitoringHandler) StreamUpdates(c *gin.Context) {
+	// Set SSE headers
+	c.Writer.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Flush()
+
+	events, unsubscribe := h.monitoringService.Subscribe()
+	defer unsubscribe()
+
+	// Send the current snapshot so the dashboard renders before the next tick
+	initial := service.MonitoringEvent{Type: service.MonitoringEventMetrics, Metrics: h.monitoringService.GetRealTimeMetrics()}
+	if err := writeMonitoringEvent(c, initial); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(monitoringStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	clientGone := c.Request.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeMonitoringEvent(c, event); err != nil {
+				log.Printf("SSE: Failed to write monitoring event to client: %v", err)
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeMonitoringEvent writes a single SSE event named after the event type
+func writeMonitoringEvent(c *gin.Context, event service.MonitoringEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
 // GetSystemHealth gets overall system health status
 // @Summary Get system health
 // @Description Get overall system health status and issues
@@ -127,19 +202,13 @@ func (h *MonitoringHandler) GetMetricsHistory(c *gin.Context) {
 
 	period, err := time.ParseDuration(periodStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid period format. Use duration format like '1h', '24h', etc.",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "Invalid period format. Use duration format like '1h', '24h', etc.")
 		return
 	}
 
 	interval, err := time.ParseDuration(intervalStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid interval format. Use duration format like '1m', '5m', etc.",
-		})
+		utils.ApiError(c, http.StatusBadRequest, "Invalid interval format. Use duration format like '1m', '5m', etc.")
 		return
 	}
 
@@ -193,46 +262,212 @@ func (h *MonitoringHandler) GetSecurityOverview(c *gin.Context) {
 	})
 }
 
-// GetAlerts gets current system alerts
+// GetAlerts gets stored system alerts
 // @Summary Get system alerts
-// @Description Get current system alerts and notifications
+// @Description Get stored system alerts, most recently seen first. Follow next_page_token to get the next page.
 // @Tags Monitoring
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param severity query string false "Filter by severity (info, warning, error, critical)"
+// @Param level query string false "Filter by level (info, warning, error, critical)"
+// @Param severity query string false "Former name of level"
+// @Param type query string false "Filter by alert type"
+// @Param resolved query bool false "Filter by resolved state"
+// @Param since query string false "Only alerts seen at or after this RFC3339 time, or within this duration (e.g., '1h', '24h')"
+// @Param until query string false "Only alerts seen before this RFC3339 time, or this duration ago"
 // @Param limit query int false "Limit number of results" default(50)
+// @Param offset query int false "Number of results to skip" default(0)
+// @Param page_token query string false "next_page_token of the previous page"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Router /api/v1/monitoring/alerts [get]
 func (h *MonitoringHandler) GetAlerts(c *gin.Context) {
-	severity := c.Query("severity")
-	limitStr := c.DefaultQuery("limit", "50")
+	filter := store.AlertFilter{
+		Level: c.Query("level"),
+		Type:  c.Query("type"),
+	}
+	if filter.Level == "" {
+		filter.Level = c.Query("severity")
+	}
 
-	limit, err := strconv.Atoi(limitStr)
+	if resolvedStr := c.Query("resolved"); resolvedStr != "" {
+		resolved, err := strconv.ParseBool(resolvedStr)
+		if err != nil {
+			utils.ApiError(c, http.StatusBadRequest, "Invalid resolved value. Use 'true' or 'false'.")
+			return
+		}
+		filter.Resolved = &resolved
+	}
+
+	now := time.Now()
+	for _, bound := range []struct {
+		param string
+		time  *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := c.Query(bound.param)
+		if value == "" {
+			continue
+		}
+		t, ok := parseAlertTime(value, now)
+		if !ok {
+			utils.ApiError(c, http.StatusBadRequest, fmt.Sprintf("Invalid %s format. Use an RFC3339 time or a duration like '1h', '24h', etc.", bound.param))
+			return
+		}
+		*bound.time = t
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Until.After(filter.Since) {
+		utils.ApiError(c, http.StatusBadRequest, "until must be after since")
+		return
+	}
+
+	if token := c.Query("page_token"); token != "" {
+		cursor, err := decodeAlertPageToken(token)
+		if err != nil {
+			utils.ApiError(c, http.StatusBadRequest, "Invalid page_token")
+			return
+		}
+		filter.After = cursor
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	if err != nil || limit <= 0 {
 		limit = 50
 	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
 
-	// In a real implementation, this would fetch alerts from a database or queue
-	alerts := h.generateSampleAlerts(severity, limit)
+	// One more alert than the page tells whether there is a next page
+	alerts, total, err := h.monitoringService.ListAlerts(filter, offset, limit+1)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to retrieve alerts", err.Error())
+		return
+	}
+	nextPageToken := ""
+	if len(alerts) > limit {
+		alerts = alerts[:limit]
+		nextPageToken = encodeAlertPageToken(store.CursorOf(alerts[limit-1]))
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "Alerts retrieved successfully",
 		"data": gin.H{
-			"alerts": alerts,
-			"count":  len(alerts),
+			"alerts":          alerts,
+			"count":           len(alerts),
+			"total":           total,
+			"next_page_token": nextPageToken,
 			"filter": gin.H{
-				"severity": severity,
+				"level":    filter.Level,
+				"severity": filter.Level,
+				"type":     filter.Type,
+				"resolved": filter.Resolved,
+				"since":    c.Query("since"),
+				"until":    c.Query("until"),
 				"limit":    limit,
+				"offset":   offset,
 			},
 		},
 	})
 }
 
+// parseAlertTime reads an RFC3339 time, or a positive duration before now
+func parseAlertTime(value string, now time.Time) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return time.Time{}, false
+	}
+	return now.Add(-d), true
+}
+
+// encodeAlertPageToken returns the opaque token of the alert listing page following cursor
+func encodeAlertPageToken(cursor store.AlertCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAlertPageToken(token string) (*store.AlertCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var cursor store.AlertCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.LastSeen.IsZero() {
+		return nil, errors.New("page token has no position")
+	}
+	return &cursor, nil
+}
+
+// AcknowledgeAlert acknowledges an alert
+// @Summary Acknowledge alert
+// @Description Mark an alert as acknowledged by the current user
+// @Tags Monitoring
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Alert ID"
+// @Success 200 {object} store.Alert
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/monitoring/alerts/{id}/acknowledge [post]
+func (h *MonitoringHandler) AcknowledgeAlert(c *gin.Context) {
+	h.updateAlert(c, h.monitoringService.AcknowledgeAlert, "Alert acknowledged successfully")
+}
+
+// ResolveAlert resolves an alert
+// @Summary Resolve alert
+// @Description Mark an alert as resolved by the current user
+// @Tags Monitoring
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Alert ID"
+// @Success 200 {object} store.Alert
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/monitoring/alerts/{id}/resolve [post]
+func (h *MonitoringHandler) ResolveAlert(c *gin.Context) {
+	h.updateAlert(c, h.monitoringService.ResolveAlert, "Alert resolved successfully")
+}
+
+// updateAlert applies an alert state change on behalf of the current user
+func (h *MonitoringHandler) updateAlert(c *gin.Context, update func(id, userID uint) (*store.Alert, error), message string) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "Invalid alert ID")
+		return
+	}
+
+	alert, err := update(uint(id), userID)
+	if err != nil {
+		if errors.Is(err, store.ErrAlertNotFound) {
+			utils.ApiError(c, http.StatusNotFound, "Alert not found")
+			return
+		}
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to update alert", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": message,
+		"data":    alert,
+	})
+}
+
 // Helper methods
 
 func countIssuesBySeverity(issues []service.HealthIssue, severity string) int {
@@ -368,40 +603,3 @@ func (h *MonitoringHandler) getSecurityRecommendations(metrics *service.RealTime
 
 	return recommendations
 }
-
-func (h *MonitoringHandler) generateSampleAlerts(severity string, limit int) []map[string]interface{} {
-	// In a real implementation, this would fetch alerts from a database
-	alerts := make([]map[string]interface{}, 0)
-
-	sampleAlerts := []map[string]interface{}{
-		{
-			"id":          "alert_001",
-			"level":       "warning",
-			"type":        "high_failed_logins",
-			"title":       "High Failed Login Rate",
-			"description": "15 failed logins per minute detected",
-			"timestamp":   time.Now().Add(-5 * time.Minute),
-			"resolved":    false,
-		},
-		{
-			"id":          "alert_002",
-			"level":       "error",
-			"type":        "security_violation",
-			"title":       "Security Violation",
-			"description": "Unauthorized access attempt detected",
-			"timestamp":   time.Now().Add(-15 * time.Minute),
-			"resolved":    false,
-		},
-	}
-
-	for _, alert := range sampleAlerts {
-		if severity == "" || alert["level"] == severity {
-			alerts = append(alerts, alert)
-			if len(alerts) >= limit {
-				break
-			}
-		}
-	}
-
-	return alerts
-}
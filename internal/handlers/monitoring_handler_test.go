@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type alertsPage struct {
+	Alerts        []store.Alert `json:"alerts"`
+	Count         int           `json:"count"`
+	Total         int64         `json:"total"`
+	NextPageToken string        `json:"next_page_token"`
+}
+
+// newAlertsRouter serves the alerts of a store holding, one minute apart from now back, a resolved
+// critical node alert followed by warning login alerts alternately resolved
+func newAlertsRouter(t *testing.T, now time.Time) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	memoryStore := store.NewMemoryStore()
+	alerts := []*store.Alert{{Level: "critical", Type: "node_not_ready", Resolved: true, LastSeen: now}}
+	for i := 1; i <= 6; i++ {
+		alerts = append(alerts, &store.Alert{Level: "warning", Type: "high_failed_logins", Resolved: i%2 == 0, LastSeen: now.Add(-time.Duration(i) * time.Minute)})
+	}
+	for _, alert := range alerts {
+		require.NoError(t, memoryStore.CreateAlert(alert))
+	}
+
+	handler := NewMonitoringHandler(service.NewMonitoringService(memoryStore, &configs.Config{}, nil))
+	router := gin.New()
+	router.GET("/alerts", handler.GetAlerts)
+	return router
+}
+
+func getAlerts(t *testing.T, router *gin.Engine, query url.Values) (int, alertsPage) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/alerts?"+query.Encode(), nil))
+	var body struct {
+		Data alertsPage `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	}
+	return w.Code, body.Data
+}
+
+func TestMonitoringHandler_GetAlertsFilters(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	router := newAlertsRouter(t, now)
+
+	tests := []struct {
+		name  string
+		query url.Values
+		total int64
+	}{
+		{"no filter", url.Values{}, 7},
+		{"level", url.Values{"level": {"warning"}}, 6},
+		{"severity is the former name of level", url.Values{"severity": {"critical"}}, 1},
+		{"type", url.Values{"type": {"node_not_ready"}}, 1},
+		{"resolved", url.Values{"type": {"high_failed_logins"}, "resolved": {"false"}}, 3},
+		{"since duration", url.Values{"since": {"150s"}}, 3},
+		{"time range", url.Values{"since": {now.Add(-5 * time.Minute).Format(time.RFC3339)}, "until": {now.Add(-2 * time.Minute).Format(time.RFC3339)}}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, page := getAlerts(t, router, tt.query)
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, tt.total, page.Total)
+			assert.Len(t, page.Alerts, int(tt.total))
+			for i := 1; i < len(page.Alerts); i++ {
+				assert.False(t, page.Alerts[i].LastSeen.After(page.Alerts[i-1].LastSeen), "newest first")
+			}
+		})
+	}
+
+	for _, query := range []url.Values{
+		{"resolved": {"maybe"}},
+		{"since": {"yesterday"}},
+		{"since": {"1h"}, "until": {"2h"}},
+		{"page_token": {"not-a-token"}},
+	} {
+		code, _ := getAlerts(t, router, query)
+		assert.Equal(t, http.StatusBadRequest, code, query.Encode())
+	}
+}
+
+func TestMonitoringHandler_GetAlertsPagination(t *testing.T) {
+	router := newAlertsRouter(t, time.Now())
+
+	var seen []uint
+	query := url.Values{"level": {"warning"}, "limit": {"4"}}
+	for pages := 1; ; pages++ {
+		require.LessOrEqual(t, pages, 2, "the listing ends")
+		code, page := getAlerts(t, router, query)
+		require.Equal(t, http.StatusOK, code)
+		assert.EqualValues(t, 6, page.Total, "the total counts every page")
+		for _, alert := range page.Alerts {
+			seen = append(seen, alert.ID)
+		}
+		if page.NextPageToken == "" {
+			assert.Len(t, page.Alerts, 2, "the last page holds the rest")
+			break
+		}
+		assert.Len(t, page.Alerts, 4)
+		query.Set("page_token", page.NextPageToken)
+	}
+	assert.Equal(t, []uint{2, 3, 4, 5, 6, 7}, seen, "every alert is listed once, newest first")
+
+	code, page := getAlerts(t, router, url.Values{"limit": {fmt.Sprint(7)}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, page.Alerts, 7)
+	assert.Empty(t, page.NextPageToken, "no next page when the page holds every alert")
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NamespaceHandler handles namespace requests, limiting namespace lists to what the caller may access
+type NamespaceHandler struct {
+	*ResourceHandler[*corev1.Namespace]
+	permissionService *service.PermissionService
+}
+
+// NewNamespaceHandler creates a new NamespaceHandler
+func NewNamespaceHandler(svc service.ResourceService[*corev1.Namespace], permissionService *service.PermissionService, cm *k8s.ClusterManager) *NamespaceHandler {
+	return &NamespaceHandler{
+		ResourceHandler:   NewResourceHandler(svc, cm, "namespaces"),
+		permissionService: permissionService,
+	}
+}
+
+// List handles namespace list requests. Non-admin callers only see the namespaces they may access.
+func (h *NamespaceHandler) List(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	selector := c.Query("labelSelector")
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 64)
+	list, err := h.listNamespaces(k8sClient, selector, limit, c.Query("continue"))
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to get resource list", err.Error())
+		return
+	}
+
+	filtered, err := h.filter(c, list)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to check namespace permissions", err.Error())
+		return
+	}
+	if filtered {
+		// The list differs per user, so the ETag is derived from the filtered body
+		respondResource(c, list, "successfully retrieved resource list", "")
+		return
+	}
+	respondResource(c, list, "successfully retrieved resource list", list.ResourceVersion)
+}
+
+// MyNamespaces handles GET /api/v1/clusters/:id/my-namespaces, returning the names of the namespaces the caller may access
+func (h *NamespaceHandler) MyNamespaces(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	list, err := h.listNamespaces(k8sClient, "", 0, "")
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to get namespaces", err.Error())
+		return
+	}
+	if _, err := h.filter(c, list); err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to check namespace permissions", err.Error())
+		return
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, namespace := range list.Items {
+		names = append(names, namespace.Name)
+	}
+	utils.ApiSuccess(c, names, "successfully retrieved accessible namespaces")
+}
+
+func (h *NamespaceHandler) listNamespaces(k8sClient *k8s.Client, selector string, limit int64, continueToken string) (*corev1.NamespaceList, error) {
+	obj, err := h.service.List(k8sClient.Clientset, "", selector, limit, continueToken)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := obj.(*corev1.NamespaceList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected namespace list type %T", obj)
+	}
+	return list, nil
+}
+
+// filter removes the namespaces the current user may not access and reports whether the list was filtered.
+// Admins see every namespace, anonymous requests and users whose permissions can't be checked none.
+func (h *NamespaceHandler) filter(c *gin.Context, list *corev1.NamespaceList) (bool, error) {
+	userID, admin, err := permissionSubject(c, h.permissionService)
+	if err != nil {
+		list.Items = []corev1.Namespace{}
+		return true, nil
+	}
+	if admin {
+		return false, nil
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, namespace := range list.Items {
+		names = append(names, namespace.Name)
+	}
+	accessible, err := h.permissionService.AccessibleNamespaces(userID, names)
+	if err != nil {
+		return false, err
+	}
+
+	allowed := make(map[string]bool, len(accessible))
+	for _, name := range accessible {
+		allowed[name] = true
+	}
+	items := list.Items[:0]
+	for _, namespace := range list.Items {
+		if allowed[namespace.Name] {
+			items = append(items, namespace)
+		}
+	}
+	list.Items = items
+	return true, nil
+}
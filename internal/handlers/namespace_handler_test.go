@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testNamespaceList(names ...string) *corev1.NamespaceList {
+	list := &corev1.NamespaceList{}
+	for _, name := range names {
+		list.Items = append(list.Items, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	return list
+}
+
+func namespaceNames(list *corev1.NamespaceList) []string {
+	names := make([]string, 0, len(list.Items))
+	for _, namespace := range list.Items {
+		names = append(names, namespace.Name)
+	}
+	return names
+}
+
+func TestNamespaceHandler_FilterScopedEditor(t *testing.T) {
+	enforcer, err := casbin.NewEnforcer("../../pkg/auth/model.conf")
+	require.NoError(t, err)
+	_, err = enforcer.AddPolicy("team-editor", "/api/v1/namespaces/team-a/*", "*")
+	require.NoError(t, err)
+	_, err = enforcer.AddPolicy("team-editor", "/api/v1/namespaces/team-b/*", "*")
+	require.NoError(t, err)
+	_, err = enforcer.AddGroupingPolicy("user:5", "team-editor")
+	require.NoError(t, err)
+	h := NewNamespaceHandler(nil, service.NewPermissionService(nil, enforcer), nil)
+
+	newContext := func(userID uint, role string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("user_id", userID)
+		c.Set("username", "someone")
+		c.Set("user_role", role)
+		return c
+	}
+
+	list := testNamespaceList("default", "team-a", "kube-system", "team-b")
+	filtered, err := h.filter(newContext(5, "editor"), list)
+	require.NoError(t, err)
+	assert.True(t, filtered)
+	assert.Equal(t, []string{"team-a", "team-b"}, namespaceNames(list))
+
+	list = testNamespaceList("default", "team-a", "kube-system", "team-b")
+	filtered, err = h.filter(newContext(1, "admin"), list)
+	require.NoError(t, err)
+	assert.False(t, filtered, "admins see everything")
+	assert.Len(t, list.Items, 4)
+
+	anonymous, _ := gin.CreateTestContext(httptest.NewRecorder())
+	filtered, err = h.filter(anonymous, list)
+	require.NoError(t, err)
+	assert.True(t, filtered)
+	assert.Empty(t, list.Items, "requests without a user see no namespaces")
+}
+
+func TestNamespaceHandler_FilterWithoutPermissionService(t *testing.T) {
+	h := NewNamespaceHandler(nil, nil, nil)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("user_id", uint(5))
+	c.Set("username", "someone")
+	c.Set("user_role", "editor")
+	list := testNamespaceList("default", "team-a")
+	filtered, err := h.filter(c, list)
+	require.NoError(t, err)
+	assert.True(t, filtered)
+	assert.Empty(t, list.Items, "permissions can't be checked, so non-admins see no namespaces")
+
+	c.Set("user_role", "admin")
+	list = testNamespaceList("default", "team-a")
+	filtered, err = h.filter(c, list)
+	require.NoError(t, err)
+	assert.False(t, filtered)
+	assert.Len(t, list.Items, 2)
+}
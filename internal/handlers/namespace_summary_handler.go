@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// NamespaceSummaryHandler handles one-call namespace overview requests
+type NamespaceSummaryHandler struct {
+	service        *service.NamespaceSummaryService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewNamespaceSummaryHandler creates a new NamespaceSummaryHandler
+func NewNamespaceSummaryHandler(svc *service.NamespaceSummaryService, cm *k8s.ClusterManager) *NamespaceSummaryHandler {
+	return &NamespaceSummaryHandler{
+		service:        svc,
+		clusterManager: cm,
+	}
+}
+
+// GetSummary handles GET /api/v1/clusters/:id/namespaces/:namespace/summary
+func (h *NamespaceSummaryHandler) GetSummary(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	listers, err := h.service.ListersFor(c.Param("id"), k8sClient.Clientset)
+	if err != nil {
+		utils.ApiError(c, http.StatusServiceUnavailable, "failed to prepare resource cache", err.Error())
+		return
+	}
+
+	summary, err := h.service.Summary(listers, h.metricsClient(c, k8sClient), c.Param("namespace"))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "namespace not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to summarize namespace", err)
+		return
+	}
+	utils.ApiSuccess(c, summary, "successfully retrieved namespace summary")
+}
+
+// GetUsage handles GET /api/v1/clusters/:id/namespaces/usage?sortBy=pods|cpu|memory|cpuUsage|memoryUsage
+func (h *NamespaceSummaryHandler) GetUsage(c *gin.Context) {
+	sortBy := c.Query("sortBy")
+	if err := service.ValidateNamespaceUsageSortBy(sortBy); err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid parameters", err.Error())
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	listers, err := h.service.ListersFor(c.Param("id"), k8sClient.Clientset)
+	if err != nil {
+		utils.ApiError(c, http.StatusServiceUnavailable, "failed to prepare resource cache", err.Error())
+		return
+	}
+
+	report, err := h.service.Usage(c.Request.Context(), listers, h.metricsClient(c, k8sClient), sortBy)
+	if err != nil {
+		respondKubernetesError(c, "failed to build namespace usage report", err)
+		return
+	}
+	utils.ApiSuccess(c, report, "successfully retrieved namespace usage")
+}
+
+// metricsClient returns a metrics client for the cluster. Usage is optional, so a metrics client that
+// cannot be created only leaves it out.
+func (h *NamespaceSummaryHandler) metricsClient(c *gin.Context, k8sClient *k8s.Client) versioned.Interface {
+	client, err := versioned.NewForConfig(k8sClient.Config)
+	if err != nil {
+		log.Printf("failed to create metrics client for cluster %s: %v", c.Param("id"), err)
+		return nil
+	}
+	return client
+}
+
+// GetUnhealthyPods handles GET /api/v1/clusters/:id/namespaces/:namespace/pods/unhealthy?restartThreshold=5
+func (h *NamespaceSummaryHandler) GetUnhealthyPods(c *gin.Context) {
+	threshold, err := strconv.Atoi(c.DefaultQuery("restartThreshold", strconv.Itoa(service.DefaultRestartThreshold)))
+	if err != nil || threshold <= 0 {
+		utils.ApiError(c, http.StatusBadRequest, "invalid parameters", "restartThreshold must be a positive integer")
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	listers, err := h.service.ListersFor(c.Param("id"), k8sClient.Clientset)
+	if err != nil {
+		utils.ApiError(c, http.StatusServiceUnavailable, "failed to prepare resource cache", err.Error())
+		return
+	}
+
+	report, err := h.service.UnhealthyPods(listers, c.Param("namespace"), int32(threshold))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "namespace not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to list unhealthy pods", err)
+		return
+	}
+	utils.ApiSuccess(c, report, "successfully retrieved unhealthy pods")
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/ciliverse/cilikube/internal/models"
 	"github.com/ciliverse/cilikube/internal/service"
 	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -30,13 +32,9 @@ func (h *OAuthHandler) GetAuthURL(c *gin.Context) {
 		state = "default_state" // In production, generate a secure random state
 	}
 
-	authURL, err := h.oauthService.GetAuthURL(provider, state)
+	authURL, err := h.oauthService.GetAuthURL(provider, state, utils.ExternalURL(c, service.OAuthCallbackPath))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Failed to generate auth URL",
-			"error":   err.Error(),
-		})
+		utils.ApiError(c, http.StatusBadRequest, "Failed to generate auth URL", err.Error())
 		return
 	}
 
@@ -54,22 +52,14 @@ func (h *OAuthHandler) GetAuthURL(c *gin.Context) {
 func (h *OAuthHandler) HandleCallback(c *gin.Context) {
 	var req models.OAuthLoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid request format",
-			"error":   err.Error(),
-		})
+		utils.ApiBindError(c, err)
 		return
 	}
 
 	// Handle OAuth login
-	loginResp, err := h.oauthService.LoginWithOAuth(req.Provider, req.Code)
+	loginResp, err := h.oauthService.LoginWithOAuth(c.Request.Context(), req.Provider, req.Code)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "OAuth login failed",
-			"error":   err.Error(),
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "OAuth login failed", err.Error())
 		return
 	}
 
@@ -85,30 +75,19 @@ func (h *OAuthHandler) LinkAccount(c *gin.Context) {
 	// Get current user from JWT token
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "Authentication required",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "Authentication required")
 		return
 	}
 
 	var req models.OAuthLinkRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid request format",
-			"error":   err.Error(),
-		})
+		utils.ApiBindError(c, err)
 		return
 	}
 
 	// Link OAuth account
-	if err := h.oauthService.LinkAccount(userID, req.Provider, req.Code); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Failed to link OAuth account",
-			"error":   err.Error(),
-		})
+	if err := h.oauthService.LinkAccount(c.Request.Context(), userID, req.Provider, req.Code); err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "Failed to link OAuth account", err.Error())
 		return
 	}
 
@@ -123,35 +102,88 @@ func (h *OAuthHandler) UnlinkAccount(c *gin.Context) {
 	// Get current user from JWT token
 	userID, _, _, ok := auth.GetCurrentUser(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "Authentication required",
-		})
+		utils.ApiError(c, http.StatusUnauthorized, "Authentication required")
 		return
 	}
 
 	var req models.OAuthUnlinkRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "Invalid request format",
-			"error":   err.Error(),
-		})
+		utils.ApiBindError(c, err)
 		return
 	}
 
 	// Unlink OAuth account
-	if err := h.oauthService.UnlinkAccount(userID, req.Provider); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
+	if err := h.oauthService.UnlinkAccount(c.Request.Context(), userID, req.Provider); err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "Failed to unlink OAuth account", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "OAuth account unlinked successfully",
+	})
+}
+
+// LinkProvider completes an OAuth code exchange for the provider in the path and links it to the current user
+func (h *OAuthHandler) LinkProvider(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	var req models.OAuthCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	if err := h.oauthService.LinkAccount(c.Request.Context(), userID, c.Param("provider"), req.Code); err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "Failed to link OAuth account", err.Error())
+		return
+	}
+
+	h.respondLinkedProviders(c, userID, "OAuth account linked successfully")
+}
+
+// UnlinkProvider unlinks the provider in the path from the current user
+func (h *OAuthHandler) UnlinkProvider(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	if err := h.oauthService.UnlinkAccount(c.Request.Context(), userID, c.Param("provider")); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrOAuthProviderNotLinked):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrLastAuthMethod):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
 			"message": "Failed to unlink OAuth account",
 			"error":   err.Error(),
 		})
 		return
 	}
 
+	h.respondLinkedProviders(c, userID, "OAuth account unlinked successfully")
+}
+
+// respondLinkedProviders responds with the providers currently linked to the user
+func (h *OAuthHandler) respondLinkedProviders(c *gin.Context, userID uint, message string) {
+	providers, err := h.oauthService.ListLinkedProviders(userID)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to list linked OAuth accounts", err.Error())
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "OAuth account unlinked successfully",
+		"data":    providers,
+		"message": message,
 	})
 }
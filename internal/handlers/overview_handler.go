@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// OverviewHandler handles multi-cluster overview requests
+type OverviewHandler struct {
+	service *service.OverviewService
+}
+
+// NewOverviewHandler creates a new OverviewHandler
+func NewOverviewHandler(svc *service.OverviewService) *OverviewHandler {
+	return &OverviewHandler{service: svc}
+}
+
+// GetOverview handles GET /api/v1/overview
+func (h *OverviewHandler) GetOverview(c *gin.Context) {
+	overview := h.service.GetOverview(c.Request.Context())
+	utils.ApiSuccess(c, overview, "successfully retrieved multi-cluster overview")
+}
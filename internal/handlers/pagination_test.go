@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminListEndpoints_RejectOversizedPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(utils.PaginationLimits(configs.PaginationConfig{DefaultSize: 20, MaxSize: 100}))
+	// Oversized pages are rejected before the services are used
+	router.GET("/auth/users", NewAuthHandler(nil).GetUserList)
+	router.GET("/admin/users", NewUserManagementHandler(nil, nil).ListUsers)
+	router.GET("/admin/roles", NewRoleManagementHandler(nil).ListRoles)
+	router.GET("/audit/logs", NewAuditHandler(nil).GetAuditLogs)
+
+	for _, path := range []string{"/auth/users", "/admin/users", "/admin/roles", "/audit/logs"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path+"?page_size=1000000", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		assert.Contains(t, w.Body.String(), "page_size must not exceed 100", path)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PersistentVolumeHandler handles PersistentVolume requests beyond generic CRUD
+type PersistentVolumeHandler struct {
+	clusterManager *k8s.ClusterManager
+}
+
+// NewPersistentVolumeHandler creates a new PersistentVolumeHandler
+func NewPersistentVolumeHandler(cm *k8s.ClusterManager) *PersistentVolumeHandler {
+	return &PersistentVolumeHandler{clusterManager: cm}
+}
+
+// ListItems handles GET /api/v1/persistentvolumes/summary
+func (h *PersistentVolumeHandler) ListItems(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	items, err := service.ListPersistentVolumeItems(k8sClient.Clientset)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to get persistent volume list", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, items, "successfully retrieved persistent volume list")
+}
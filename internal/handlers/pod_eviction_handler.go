@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// PodEvictionHandler handles evicting single pods
+type PodEvictionHandler struct {
+	service           *service.PodEvictionService
+	permissionService *service.PermissionService
+	clusterManager    *k8s.ClusterManager
+}
+
+// NewPodEvictionHandler creates a new PodEvictionHandler
+func NewPodEvictionHandler(svc *service.PodEvictionService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *PodEvictionHandler {
+	return &PodEvictionHandler{
+		service:           svc,
+		permissionService: permissionService,
+		clusterManager:    cm,
+	}
+}
+
+// EvictPod handles POST /api/v1/clusters/:id/namespaces/:namespace/pods/:name/evict
+func (h *PodEvictionHandler) EvictPod(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !authorizeNamespacedResource(c, h.permissionService, namespace, "pods", ActionDelete) {
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	err := h.service.Evict(c.Request.Context(), k8sClient.Clientset, namespace, name)
+	auditResourceChange(c, corev1.SchemeGroupVersion.WithResource("pods"), namespace, name, service.ResourceActionEvict, err)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEvictionBlocked):
+			utils.ApiError(c, http.StatusTooManyRequests, "pod eviction blocked by a PodDisruptionBudget, retry once more replicas are available", err.Error())
+		case k8serrors.IsNotFound(err):
+			utils.ApiError(c, http.StatusNotFound, "pod not found", err.Error())
+		default:
+			respondKubernetesError(c, "failed to evict pod", err)
+		}
+		return
+	}
+	utils.ApiSuccess(c, gin.H{"namespace": namespace, "name": name}, "pod evicted successfully")
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodEvictionHandler_RequiresUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/clusters/:id/namespaces/:namespace/pods/:name/evict", NewPodEvictionHandler(nil, nil, nil).EvictPod)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/clusters/c1/namespaces/test/pods/web-1/evict", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestPodEvictionHandler_DeniesWithoutPermissionService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/clusters/:id/namespaces/:namespace/pods/:name/evict", func(c *gin.Context) {
+		c.Set("user_id", uint(7))
+		c.Set("username", "alice")
+		c.Set("user_role", "editor")
+	}, NewPodEvictionHandler(nil, nil, nil).EvictPod)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/clusters/c1/namespaces/test/pods/web-1/evict", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code, "only admins pass when permissions can't be checked")
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/ciliverse/cilikube/pkg/k8s"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // PodExecHandler handles pod execution requests
@@ -86,7 +87,9 @@ func (h *PodExecHandler) ExecPod(c *gin.Context) {
 		TTY:       true,
 	}
 
-	err = h.service.Exec(k8sClient.Clientset, namespace, podName, options, wsStreamHandler, wsStreamHandler)
+	err = h.service.Exec(k8sClient.Config, k8sClient.Clientset, namespace, podName, options, wsStreamHandler, wsStreamHandler)
+	auditResourceOperation(c, corev1.SchemeGroupVersion.WithResource("pods"), namespace, podName, service.ResourceActionExec,
+		map[string]interface{}{"container": container, "command": command}, err)
 	if err != nil {
 		errmsg := []byte(fmt.Sprintf("\r\n--- Command Execution Failed ---\r\nError: %v\r\n", err))
 		wsStreamHandler.WriteMessage(websocket.TextMessage, errmsg)
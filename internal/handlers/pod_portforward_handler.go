@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// ActionPortForward is the permission action required to forward pod ports
+const ActionPortForward = "portforward:pods"
+
+// Port-forward frame types. Every WebSocket message is a binary frame made of the type byte,
+// the big-endian uint32 ID of the stream chosen by the client when opening it, and the payload.
+const (
+	PortForwardFrameOpen  byte = 0 // client: open a new connection to the pod port
+	PortForwardFrameData  byte = 1 // both: bytes of a connection
+	PortForwardFrameClose byte = 2 // both: the connection was closed
+	PortForwardFrameError byte = 3 // server: the connection failed, payload is the message
+)
+
+const portForwardFrameHeaderSize = 5
+
+// errShortPortForwardFrame is returned for frames without a complete header
+var errShortPortForwardFrame = errors.New("port-forward frame is shorter than its header")
+
+// encodePortForwardFrame builds a frame of the port-forward WebSocket protocol
+func encodePortForwardFrame(frameType byte, streamID uint32, payload []byte) []byte {
+	frame := make([]byte, portForwardFrameHeaderSize+len(payload))
+	frame[0] = frameType
+	binary.BigEndian.PutUint32(frame[1:portForwardFrameHeaderSize], streamID)
+	copy(frame[portForwardFrameHeaderSize:], payload)
+	return frame
+}
+
+// decodePortForwardFrame splits a frame of the port-forward WebSocket protocol
+func decodePortForwardFrame(frame []byte) (byte, uint32, []byte, error) {
+	if len(frame) < portForwardFrameHeaderSize {
+		return 0, 0, nil, errShortPortForwardFrame
+	}
+	return frame[0], binary.BigEndian.Uint32(frame[1:portForwardFrameHeaderSize]), frame[portForwardFrameHeaderSize:], nil
+}
+
+// PodPortForwardHandler handles pod port-forward requests tunneled over WebSocket
+type PodPortForwardHandler struct {
+	service           *service.PodPortForwardService
+	permissionService *service.PermissionService
+	clusterManager    *k8s.ClusterManager
+	upgrader          websocket.Upgrader
+}
+
+// NewPodPortForwardHandler creates a new PodPortForwardHandler
+func NewPodPortForwardHandler(svc *service.PodPortForwardService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *PodPortForwardHandler {
+	return &PodPortForwardHandler{
+		service:           svc,
+		permissionService: permissionService,
+		clusterManager:    cm,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  32 * 1024,
+			WriteBufferSize: 32 * 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		},
+	}
+}
+
+// PortForward handles WebSocket requests forwarding ?port= of a pod
+func (h *PodPortForwardHandler) PortForward(c *gin.Context) {
+	port, err := strconv.Atoi(c.Query("port"))
+	if err != nil || port < 1 || port > 65535 {
+		utils.ApiError(c, http.StatusBadRequest, "invalid parameters", "query parameter 'port' must be a port number between 1 and 65535")
+		return
+	}
+	if !h.authorize(c) {
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	conn, err := h.service.Dial(k8sClient.Config, k8sClient.Clientset, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		utils.ApiError(c, http.StatusBadGateway, "failed to start port forwarding", err.Error())
+		return
+	}
+
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade to websocket: %v", err)
+		conn.Close()
+		return
+	}
+
+	session := &portForwardSession{ws: ws, conn: conn, port: port, streams: make(map[uint32]*service.PortForwardStream)}
+	session.run()
+}
+
+// authorize checks the portforward:pods permission of the current user on the request path
+func (h *PodPortForwardHandler) authorize(c *gin.Context) bool {
+	return authorizeObject(c, h.permissionService, c.Request.URL.Path, ActionPortForward,
+		"port forwarding requires the "+ActionPortForward+" permission")
+}
+
+// portForwardSession relays the streams of one WebSocket to a port-forward connection
+type portForwardSession struct {
+	ws   *websocket.Conn
+	conn *service.PortForwardConnection
+	port int
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	streams map[uint32]*service.PortForwardStream
+	wg      sync.WaitGroup
+}
+
+// run relays frames until either side disconnects, then tears down every stream
+func (s *portForwardSession) run() {
+	defer s.ws.Close()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-s.conn.Done():
+			s.ws.Close()
+		case <-stop:
+		}
+	}()
+
+	for {
+		messageType, message, err := s.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("Port-forward WebSocket read error: %v", err)
+			}
+			break
+		}
+		if messageType != websocket.BinaryMessage {
+			continue
+		}
+
+		frameType, streamID, payload, err := decodePortForwardFrame(message)
+		if err != nil {
+			s.sendError(0, err)
+			continue
+		}
+		switch frameType {
+		case PortForwardFrameOpen:
+			s.openStream(streamID)
+		case PortForwardFrameData:
+			s.writeStream(streamID, payload)
+		case PortForwardFrameClose:
+			if stream := s.removeStream(streamID); stream != nil {
+				stream.Close()
+			}
+		default:
+			s.sendError(streamID, fmt.Errorf("unknown frame type %d", frameType))
+		}
+	}
+
+	s.mu.Lock()
+	for id, stream := range s.streams {
+		stream.Close()
+		delete(s.streams, id)
+	}
+	s.mu.Unlock()
+	s.conn.Close()
+	s.wg.Wait()
+}
+
+// openStream opens a new connection to the pod port and starts copying its output to the WebSocket
+func (s *portForwardSession) openStream(streamID uint32) {
+	s.mu.Lock()
+	_, exists := s.streams[streamID]
+	s.mu.Unlock()
+	if exists {
+		s.sendError(streamID, fmt.Errorf("stream %d is already open", streamID))
+		return
+	}
+
+	stream, err := s.conn.OpenStream(s.port)
+	if err != nil {
+		s.sendError(streamID, err)
+		return
+	}
+	s.mu.Lock()
+	s.streams[streamID] = stream
+	s.mu.Unlock()
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		for err := range stream.Errors {
+			s.sendError(streamID, err)
+		}
+	}()
+	go func() {
+		defer s.wg.Done()
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := stream.Data.Read(buf)
+			if n > 0 {
+				if s.send(PortForwardFrameData, streamID, buf[:n]) != nil {
+					break
+				}
+			}
+			if err != nil {
+				break
+			}
+		}
+		// Only report the close when the client did not close the stream itself
+		if s.removeStream(streamID) != nil {
+			stream.Close()
+			_ = s.send(PortForwardFrameClose, streamID, nil)
+		}
+	}()
+}
+
+// writeStream sends client bytes to the pod port
+func (s *portForwardSession) writeStream(streamID uint32, payload []byte) {
+	s.mu.Lock()
+	stream := s.streams[streamID]
+	s.mu.Unlock()
+	if stream == nil {
+		s.sendError(streamID, fmt.Errorf("stream %d is not open", streamID))
+		return
+	}
+	if _, err := stream.Data.Write(payload); err != nil {
+		s.sendError(streamID, err)
+		if s.removeStream(streamID) != nil {
+			stream.Close()
+		}
+	}
+}
+
+// removeStream forgets a stream, returning nil when it was already removed
+func (s *portForwardSession) removeStream(streamID uint32) *service.PortForwardStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stream := s.streams[streamID]
+	delete(s.streams, streamID)
+	return stream
+}
+
+func (s *portForwardSession) sendError(streamID uint32, err error) {
+	_ = s.send(PortForwardFrameError, streamID, []byte(err.Error()))
+}
+
+// send writes a frame to the WebSocket; gorilla connections allow only one concurrent writer
+func (s *portForwardSession) send(frameType byte, streamID uint32, payload []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.ws.WriteMessage(websocket.BinaryMessage, encodePortForwardFrame(frameType, streamID, payload))
+}
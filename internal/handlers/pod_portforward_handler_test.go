@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortForwardFrames(t *testing.T) {
+	frame := encodePortForwardFrame(PortForwardFrameData, 258, []byte("GET / HTTP/1.1\r\n"))
+	assert.Equal(t, []byte{PortForwardFrameData, 0, 0, 1, 2}, frame[:portForwardFrameHeaderSize])
+
+	frameType, streamID, payload, err := decodePortForwardFrame(frame)
+	require.NoError(t, err)
+	assert.Equal(t, PortForwardFrameData, frameType)
+	assert.Equal(t, uint32(258), streamID)
+	assert.Equal(t, "GET / HTTP/1.1\r\n", string(payload))
+
+	_, _, payload, err = decodePortForwardFrame(encodePortForwardFrame(PortForwardFrameOpen, 1, nil))
+	require.NoError(t, err)
+	assert.Empty(t, payload)
+
+	_, _, _, err = decodePortForwardFrame([]byte{PortForwardFrameClose, 0})
+	assert.ErrorIs(t, err, errShortPortForwardFrame)
+}
+
+func TestPodPortForwardHandler_Guards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/namespaces/:namespace/pods/:name/portforward", NewPodPortForwardHandler(nil, nil, nil).PortForward)
+
+	for _, query := range []string{"", "?port=http", "?port=0", "?port=70000"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/namespaces/default/pods/web-0/portforward"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/namespaces/default/pods/web-0/portforward?port=8080", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Without a permission service, only admins may forward ports
+	router = gin.New()
+	router.GET("/namespaces/:namespace/pods/:name/portforward", func(c *gin.Context) {
+		c.Set("user_id", uint(5))
+		c.Set("username", "someone")
+		c.Set("user_role", "editor")
+	}, NewPodPortForwardHandler(nil, nil, nil).PortForward)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/namespaces/default/pods/web-0/portforward?port=8080", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
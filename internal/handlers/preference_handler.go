@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PreferenceHandler handles the caller's per-cluster preferences
+type PreferenceHandler struct {
+	service *service.PreferenceService
+}
+
+// NewPreferenceHandler creates a new PreferenceHandler
+func NewPreferenceHandler(svc *service.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{service: svc}
+}
+
+// ListPreferences handles GET /api/v1/preferences, returning the caller's preferences for all clusters
+func (h *PreferenceHandler) ListPreferences(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information not found", "")
+		return
+	}
+	prefs, err := h.service.ListPreferences(userID)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to list preferences", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, prefs, "successfully retrieved preferences")
+}
+
+// GetPreference handles GET /api/v1/clusters/:id/preferences
+func (h *PreferenceHandler) GetPreference(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information not found", "")
+		return
+	}
+	pref, err := h.service.GetPreference(userID, c.Param("id"))
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to get preferences", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, pref, "successfully retrieved preferences")
+}
+
+// UpdatePreference handles PUT /api/v1/clusters/:id/preferences
+func (h *PreferenceHandler) UpdatePreference(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information not found", "")
+		return
+	}
+	var req models.UpdateUserPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+	pref, err := h.service.UpdatePreference(userID, c.Param("id"), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPreference) {
+			utils.ApiError(c, http.StatusBadRequest, "invalid preferences", err.Error())
+			return
+		}
+		utils.ApiError(c, http.StatusInternalServerError, "failed to update preferences", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, pref, "successfully updated preferences")
+}
+
+// DeletePreference handles DELETE /api/v1/clusters/:id/preferences, resetting the caller's preferences for the cluster
+func (h *PreferenceHandler) DeletePreference(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information not found", "")
+		return
+	}
+	if err := h.service.DeletePreference(userID, c.Param("id")); err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to delete preferences", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, nil, "successfully deleted preferences")
+}
+
+// preferenceServiceKey holds the preference service of PreferredNamespaces in the gin context
+const preferenceServiceKey = "preference_service"
+
+// PreferredNamespaces lets the handlers it serves fall back to the caller's default namespace when a
+// request names none, see preferredNamespace
+func PreferredNamespaces(preferences *service.PreferenceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if preferences != nil {
+			c.Set(preferenceServiceKey, preferences)
+		}
+		c.Next()
+	}
+}
+
+// preferredNamespace returns namespace, or when it is empty the authenticated caller's default
+// namespace for the target cluster of the request. An empty result means the global default applies,
+// as it does without PreferredNamespaces.
+func preferredNamespace(c *gin.Context, namespace string) string {
+	if namespace != "" {
+		return namespace
+	}
+	value, _ := c.Get(preferenceServiceKey)
+	preferences, ok := value.(*service.PreferenceService)
+	if !ok {
+		return namespace
+	}
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		return namespace
+	}
+	clusterID := c.Param("id")
+	if rc := k8s.RequestClusterFrom(c.Request.Context()); clusterID == "" && rc != nil {
+		clusterID = rc.ID()
+	}
+	return preferences.ResolveNamespace(userID, clusterID, namespace)
+}
+
+// PreferredCluster returns the cluster lookup used by k8s.ClusterContext: the default cluster the
+// caller set in their preferences. Anonymous callers have none.
+func PreferredCluster(preferences *service.PreferenceService) k8s.PreferredClusterFunc {
+	return func(c *gin.Context) string {
+		if preferences == nil {
+			return ""
+		}
+		userID, ok := auth.CurrentUserID(c)
+		if !ok {
+			return ""
+		}
+		return preferences.PreferredClusterID(userID)
+	}
+}
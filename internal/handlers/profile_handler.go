@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -15,6 +16,8 @@ import (
 
 	"github.com/ciliverse/cilikube/internal/models"
 	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/k8s"
 	"github.com/ciliverse/cilikube/pkg/utils"
 )
 
@@ -93,11 +96,11 @@ func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
 
 	var req models.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	user, err := h.authService.UpdateProfile(uid, &req)
+	user, err := h.authService.UpdateProfile(c.Request.Context(), uid, &req)
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "Failed to update profile", err.Error())
 		return
@@ -122,11 +125,11 @@ func (h *ProfileHandler) ChangePassword(c *gin.Context) {
 
 	var req models.ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	if err := h.authService.ChangePassword(uid, &req); err != nil {
+	if err := h.authService.ChangePassword(c.Request.Context(), uid, &req); err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "Failed to change password", err.Error())
 		return
 	}
@@ -202,8 +205,8 @@ func (h *ProfileHandler) UploadAvatar(c *gin.Context) {
 		return
 	}
 
-	// Update user avatar URL
-	avatarURL := fmt.Sprintf("/uploads/avatars/%s", filename)
+	// Update user avatar URL, including the path prefix the server is reached under
+	avatarURL := utils.ExternalPath(c, "/uploads/avatars/"+filename)
 	updateReq := models.UpdateProfileRequest{
 		AvatarURL: avatarURL,
 	}
@@ -218,7 +221,7 @@ func (h *ProfileHandler) UploadAvatar(c *gin.Context) {
 	updateReq.Email = profile.Email
 	updateReq.DisplayName = profile.DisplayName
 
-	_, err = h.authService.UpdateProfile(uid, &updateReq)
+	_, err = h.authService.UpdateProfile(c.Request.Context(), uid, &updateReq)
 	if err != nil {
 		// Clean up uploaded file if profile update fails
 		os.Remove(filePath)
@@ -256,8 +259,9 @@ func (h *ProfileHandler) DeleteAvatar(c *gin.Context) {
 	}
 
 	// Remove avatar file if it exists
-	if profile.AvatarURL != "" && strings.HasPrefix(profile.AvatarURL, "/uploads/avatars/") {
-		filePath := "." + profile.AvatarURL
+	// Uploaded avatars may be stored with the path prefix the server was reached under
+	if strings.Contains(profile.AvatarURL, "/uploads/avatars/") {
+		filePath := filepath.Join("uploads/avatars", path.Base(profile.AvatarURL))
 		if _, err := os.Stat(filePath); err == nil {
 			os.Remove(filePath)
 		}
@@ -270,7 +274,7 @@ func (h *ProfileHandler) DeleteAvatar(c *gin.Context) {
 		AvatarURL:   "", // Clear avatar URL
 	}
 
-	_, err = h.authService.UpdateProfile(uid, &updateReq)
+	_, err = h.authService.UpdateProfile(c.Request.Context(), uid, &updateReq)
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "Failed to update profile", err.Error())
 		return
@@ -295,7 +299,7 @@ func (h *ProfileHandler) UpdateAvatar(c *gin.Context) {
 
 	var req models.UpdateAvatarRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -313,7 +317,7 @@ func (h *ProfileHandler) UpdateAvatar(c *gin.Context) {
 		AvatarURL:   req.AvatarURL,
 	}
 
-	updatedProfile, err := h.authService.UpdateProfile(uid, &updateReq)
+	updatedProfile, err := h.authService.UpdateProfile(c.Request.Context(), uid, &updateReq)
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "Failed to update avatar", err.Error())
 		return
@@ -385,6 +389,57 @@ func (h *ProfileHandler) GetUserPermissions(c *gin.Context) {
 	utils.ApiSuccess(c, response, "User permissions retrieved successfully")
 }
 
+// WhoAmI describes the authenticated caller
+// @Summary Who am I
+// @Description Get the caller's identity, roles, effective permissions, active cluster, token validity and authentication method
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.WhoAmIResponse
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/auth/whoami [get]
+func (h *ProfileHandler) WhoAmI(c *gin.Context) {
+	uid, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	profile, err := h.authService.GetProfile(uid)
+	if err != nil {
+		utils.ApiError(c, http.StatusUnauthorized, "User not found", err.Error())
+		return
+	}
+	permissions, err := h.roleService.UserPermissions(uid)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to get user permissions", err.Error())
+		return
+	}
+
+	response := models.WhoAmIResponse{
+		ID:          profile.ID,
+		Username:    profile.Username,
+		Email:       profile.Email,
+		DisplayName: profile.DisplayName,
+		Roles:       profile.Roles,
+		Permissions: permissions,
+		AuthMethod:  auth.CurrentAuthMethod(c),
+	}
+	if rc := k8s.RequestClusterFrom(c.Request.Context()); rc != nil {
+		response.ActiveClusterID = rc.ID()
+	}
+	if claims, ok := auth.CurrentClaims(c); ok {
+		if claims.IssuedAt != nil {
+			response.IssuedAt = &claims.IssuedAt.Time
+		}
+		if claims.ExpiresAt != nil {
+			response.ExpiresAt = &claims.ExpiresAt.Time
+		}
+	}
+
+	utils.ApiSuccess(c, response, "")
+}
+
 // GetActivityLog gets user activity log (placeholder for future implementation)
 func (h *ProfileHandler) GetActivityLog(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWhoAmIRouter serves whoami for alice, an editor and viewer, the way the API does for JWTs, and
+// behind a stand-in for API key authentication on /apikey/whoami
+func newWhoAmIRouter(t *testing.T) (*gin.Engine, *models.User) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	previous := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() { configs.GlobalConfig = previous })
+
+	memoryStore := store.NewMemoryStore()
+	user := &store.User{Username: "alice", Email: "alice@example.com", DisplayName: "Alice", IsActive: true}
+	require.NoError(t, memoryStore.CreateUser(user))
+	for _, name := range []string{"editor", "viewer"} {
+		role := &store.Role{Name: name, DisplayName: name}
+		require.NoError(t, memoryStore.CreateRole(role))
+		require.NoError(t, memoryStore.AssignRole(user.ID, role.ID))
+	}
+
+	enforcer, err := casbin.NewEnforcer("../../pkg/auth/model.conf")
+	require.NoError(t, err)
+	for _, policy := range [][]string{
+		{"editor", "/api/v1/pods/*", "*"},
+		{"editor", "/api/v1/auth/profile", "GET"},
+		{"viewer", "/api/v1/auth/profile", "GET"},
+		{"viewer", "/api/v1/nodes/*", "GET"},
+	} {
+		_, err := enforcer.AddPolicy(policy[0], policy[1], policy[2])
+		require.NoError(t, err)
+	}
+	roleService := service.NewRoleService(memoryStore)
+	roleService.SetPermissionService(service.NewPermissionService(memoryStore, enforcer))
+	handler := NewProfileHandler(service.NewAuthService(memoryStore, configs.GlobalConfig), roleService)
+
+	clusterManager, err := k8s.NewClusterManager(nil, &configs.Config{})
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(k8s.ClusterContext(clusterManager, nil))
+	router.GET("/auth/whoami", auth.JWTAuthMiddleware(), handler.WhoAmI)
+	router.GET("/apikey/whoami", func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		c.Set("username", user.Username)
+		c.Set("user_role", "editor")
+		c.Set("auth_method", auth.AuthMethodAPIKey)
+	}, handler.WhoAmI)
+
+	return router, &models.User{ID: user.ID, Username: user.Username, Role: "editor"}
+}
+
+func whoAmI(t *testing.T, router *gin.Engine, path string, header http.Header) (int, models.WhoAmIResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Data models.WhoAmIResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w.Code, resp.Data
+}
+
+func TestProfileHandler_WhoAmI(t *testing.T) {
+	router, user := newWhoAmIRouter(t)
+
+	t.Run("password login", func(t *testing.T) {
+		token, expiresAt, err := auth.GenerateToken(user)
+		require.NoError(t, err)
+
+		status, resp := whoAmI(t, router, "/auth/whoami", http.Header{
+			"Authorization":     {"Bearer " + token},
+			k8s.ClusterIDHeader: {"cls-prod"},
+		})
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, user.ID, resp.ID)
+		assert.Equal(t, "alice", resp.Username)
+		assert.Equal(t, "alice@example.com", resp.Email)
+		assert.Equal(t, "Alice", resp.DisplayName)
+		assert.ElementsMatch(t, []string{"editor", "viewer"}, resp.Roles)
+		assert.Equal(t, []string{"* /api/v1/pods/*", "GET /api/v1/auth/profile", "GET /api/v1/nodes/*"}, resp.Permissions,
+			"permissions of all roles, without duplicates")
+		assert.Equal(t, "cls-prod", resp.ActiveClusterID)
+		assert.Equal(t, auth.AuthMethodPassword, resp.AuthMethod)
+		require.NotNil(t, resp.ExpiresAt)
+		assert.WithinDuration(t, expiresAt, *resp.ExpiresAt, time.Second)
+		require.NotNil(t, resp.IssuedAt)
+		assert.WithinDuration(t, expiresAt.Add(-time.Hour), *resp.IssuedAt, time.Second)
+	})
+
+	t.Run("oauth login", func(t *testing.T) {
+		oauthUser := *user
+		oauthUser.AuthMethod = auth.AuthMethodOAuth
+		token, _, err := auth.GenerateToken(&oauthUser)
+		require.NoError(t, err)
+
+		status, resp := whoAmI(t, router, "/auth/whoami", http.Header{"Authorization": {"Bearer " + token}})
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, auth.AuthMethodOAuth, resp.AuthMethod)
+	})
+
+	t.Run("api key", func(t *testing.T) {
+		status, resp := whoAmI(t, router, "/apikey/whoami", nil)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "alice", resp.Username)
+		assert.Equal(t, auth.AuthMethodAPIKey, resp.AuthMethod)
+		assert.Nil(t, resp.IssuedAt, "no token was presented")
+		assert.Nil(t, resp.ExpiresAt)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, _ := whoAmI(t, router, "/auth/whoami", nil)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/ciliverse/cilikube/pkg/version"
+	"github.com/gin-gonic/gin"
+)
+
+// PublicPasswordPolicy is the password policy shown as hints on the registration and password forms
+type PublicPasswordPolicy struct {
+	MinLength        int  `json:"minLength"`
+	RequireUppercase bool `json:"requireUppercase"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireNumbers   bool `json:"requireNumbers"`
+	RequireSymbols   bool `json:"requireSymbols"`
+}
+
+// PublicConfig is the non-sensitive configuration the web UI adapts to before anyone logs in. Every field
+// is picked from the configuration one by one, so settings added later are never exposed by accident.
+type PublicConfig struct {
+	Version             string               `json:"version"`
+	OAuthProviders      []string             `json:"oauthProviders"` // Providers with client credentials, e.g. github
+	RegistrationEnabled bool                 `json:"registrationEnabled"`
+	PasswordPolicy      PublicPasswordPolicy `json:"passwordPolicy"`
+	StorageType         string               `json:"storageType"` // memory, database or mongodb
+}
+
+// PublicConfigHandler serves the public configuration to unauthenticated clients
+type PublicConfigHandler struct {
+	config      *configs.Config
+	storageType string
+}
+
+// NewPublicConfigHandler creates a new PublicConfigHandler
+func NewPublicConfigHandler(config *configs.Config, storageType string) *PublicConfigHandler {
+	return &PublicConfigHandler{config: config, storageType: storageType}
+}
+
+// GetPublicConfig handles GET /api/v1/config/public
+func (h *PublicConfigHandler) GetPublicConfig(c *gin.Context) {
+	password := h.config.Security.Password
+	utils.ApiSuccess(c, PublicConfig{
+		Version:             version.Version,
+		OAuthProviders:      h.config.OAuth.EnabledProviders(),
+		RegistrationEnabled: h.config.Security.RegistrationEnabled(),
+		PasswordPolicy: PublicPasswordPolicy{
+			MinLength:        password.MinLength,
+			RequireUppercase: password.RequireUppercase,
+			RequireLowercase: password.RequireLowercase,
+			RequireNumbers:   password.RequireNumbers,
+			RequireSymbols:   password.RequireSymbols,
+		},
+		StorageType: h.storageType,
+	}, "successfully retrieved public configuration")
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicConfigHandler_OnlyWhitelistedFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	closed := false
+	cfg := &configs.Config{
+		Server: configs.ServerConfig{EncryptionKey: "server-encryption-key"},
+		JWT:    configs.JWTConfig{SecretKey: "jwt-secret-key"},
+		OAuth: configs.OAuthConfig{GitHub: configs.GitHubOAuthConfig{
+			ClientID: "github-client-id", ClientSecret: "github-client-secret", RedirectURL: "https://cilikube.internal/callback",
+		}},
+		Database:    configs.DatabaseConfig{Enabled: true, Type: "postgresql", Password: "database-password"},
+		AuthWebhook: configs.AuthWebhookConfig{Enabled: true, URL: "https://idp.internal/login", Secret: "webhook-secret"},
+		Admin:       configs.AdminConfig{Password: "admin-password"},
+		Security: configs.SecurityConfig{
+			AllowRegistration: &closed,
+			Password:          configs.PasswordConfig{MinLength: 12, RequireUppercase: true, RequireNumbers: true},
+		},
+	}
+	router := gin.New()
+	router.GET("/config/public", NewPublicConfigHandler(cfg, "database").GetPublicConfig)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config/public", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	for _, secret := range []string{
+		"server-encryption-key", "jwt-secret-key", "github-client-id", "github-client-secret", "cilikube.internal",
+		"database-password", "idp.internal", "webhook-secret", "admin-password",
+	} {
+		assert.NotContains(t, w.Body.String(), secret)
+	}
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	keys := make([]string, 0, len(response.Data))
+	for key := range response.Data {
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, []string{"version", "oauthProviders", "registrationEnabled", "passwordPolicy", "storageType"}, keys)
+	assert.Equal(t, []interface{}{"github"}, response.Data["oauthProviders"])
+	assert.Equal(t, false, response.Data["registrationEnabled"])
+	assert.Equal(t, "database", response.Data["storageType"])
+	assert.Equal(t, map[string]interface{}{
+		"minLength": float64(12), "requireUppercase": true, "requireLowercase": false, "requireNumbers": true, "requireSymbols": false,
+	}, response.Data["passwordPolicy"])
+}
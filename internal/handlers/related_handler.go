@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// RelatedHandler handles owner-reference graph requests
+type RelatedHandler struct {
+	service        *service.RelatedService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewRelatedHandler creates a new RelatedHandler
+func NewRelatedHandler(svc *service.RelatedService, cm *k8s.ClusterManager) *RelatedHandler {
+	return &RelatedHandler{
+		service:        svc,
+		clusterManager: cm,
+	}
+}
+
+// GetRelated handles GET /api/v1/clusters/:id/namespaces/:namespace/:resource/:name/related
+func (h *RelatedHandler) GetRelated(c *gin.Context) {
+	kind, ok := service.RelatedKindFor(c.Param("resource"))
+	if !ok {
+		utils.ApiError(c, http.StatusBadRequest, "invalid parameters", "related resources are not supported for "+c.Param("resource"))
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	listers, err := h.service.ListersFor(c.Param("id"), k8sClient.Clientset)
+	if err != nil {
+		utils.ApiError(c, http.StatusServiceUnavailable, "failed to prepare resource cache", err.Error())
+		return
+	}
+
+	related, err := h.service.Related(listers, kind, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "resource not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to get related resources", err)
+		return
+	}
+	utils.ApiSuccess(c, related, "successfully retrieved related resources")
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// resourceAuditKey holds the audit service of ResourceAudit in the gin context
+const resourceAuditKey = "resource_audit"
+
+// ResourceAudit records the mutating Kubernetes operations of the requests it serves in the audit log
+func ResourceAudit(auditService *service.AuditService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if auditService != nil {
+			c.Set(resourceAuditKey, auditService)
+		}
+		c.Next()
+	}
+}
+
+// auditResourceChange records an operation on a Kubernetes resource with the target cluster and its
+// outcome, err being nil when it succeeded. Without ResourceAudit nothing is recorded.
+func auditResourceChange(c *gin.Context, gvr schema.GroupVersionResource, namespace, name, action string, err error) {
+	auditResourceOperation(c, gvr, namespace, name, action, nil, err)
+}
+
+// auditResourceOperation is auditResourceChange recording extra details, such as the command of an exec
+func auditResourceOperation(c *gin.Context, gvr schema.GroupVersionResource, namespace, name, action string, extra map[string]interface{}, err error) {
+	value, _ := c.Get(resourceAuditKey)
+	auditService, ok := value.(*service.AuditService)
+	if !ok {
+		return
+	}
+
+	_, username, role, _ := auth.GetCurrentUser(c)
+	details := map[string]interface{}{
+		"group":     gvr.Group,
+		"version":   gvr.Version,
+		"resource":  gvr.Resource,
+		"namespace": namespace,
+		"name":      name,
+		"username":  username,
+		"role":      role,
+		"result":    "success",
+	}
+	for key, value := range extra {
+		details[key] = value
+	}
+	if rc := k8s.RequestClusterFrom(c.Request.Context()); rc != nil {
+		details["cluster_id"] = rc.ID()
+	}
+	if err != nil {
+		details["result"] = "failure"
+		details["error"] = err.Error()
+	}
+
+	resource := gvr.Resource
+	if gv := gvr.GroupVersion().String(); gv != "" {
+		resource = gv + "/" + gvr.Resource
+	}
+	if logErr := auditService.LogResourceAccessEvent(c.Request.Context(), resource, action, err == nil, details); logErr != nil {
+		log.Printf("Warning: failed to audit %s of %s %s/%s: %v", action, resource, namespace, name, logErr)
+	}
+}
+
+// builtinGVR returns the group, version and resource of the built-in type T
+func builtinGVR[T runtime.Object](resource string) schema.GroupVersionResource {
+	gvr := schema.GroupVersionResource{Resource: resource}
+	var zero T
+	if t := reflect.TypeOf(zero); t != nil && t.Kind() == reflect.Pointer {
+		if obj, ok := reflect.New(t.Elem()).Interface().(runtime.Object); ok {
+			if gvks, _, err := scheme.Scheme.ObjectKinds(obj); err == nil && len(gvks) > 0 {
+				gvr.Group, gvr.Version = gvks[0].Group, gvks[0].Version
+			}
+		}
+	}
+	return gvr
+}
+
+// resolveGVR completes the group and version of a resource named by its plural, leaving them empty when
+// the cluster doesn't serve it
+func resolveGVR(mapper meta.RESTMapper, resource string) schema.GroupVersionResource {
+	gvr := schema.GroupVersionResource{Resource: resource}
+	if mapper != nil {
+		if resolved, err := mapper.ResourceFor(gvr); err == nil {
+			return resolved
+		}
+	}
+	return gvr
+}
+
+// kindGVR returns the resource serving the kind of apiVersion, falling back to the lowercased kind when
+// the cluster doesn't serve it
+func kindGVR(mapper meta.RESTMapper, apiVersion, kind string) schema.GroupVersionResource {
+	gvk := schema.FromAPIVersionAndKind(apiVersion, kind)
+	if mapper != nil {
+		if mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+			return mapping.Resource
+		}
+	}
+	return gvk.GroupVersion().WithResource(strings.ToLower(kind))
+}
+
+// objectName returns the name of obj, empty when it has no metadata
+func objectName(obj interface{}) string {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		return accessor.GetName()
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// newFakeAPIServer serves deleting the deployment default/web and answers 404 to everything else
+func newFakeAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete && r.URL.Path == "/apis/apps/v1/namespaces/default/deployments/web" {
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404,"message":"not found"}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newFakeCluster registers a cluster served by the API server at serverURL, returning its manager, the
+// store it is kept in and its ID
+func newFakeCluster(t *testing.T, serverURL string) (*k8s.ClusterManager, store.Store, string) {
+	t.Helper()
+	memoryStore := store.NewMemoryStore()
+
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: %s
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: prod
+current-context: prod
+users:
+- name: prod
+  user:
+    token: test
+`, serverURL)
+	clusterManager, err := k8s.NewClusterManager(memoryStore, &configs.Config{})
+	require.NoError(t, err)
+	cluster := &store.Cluster{Name: "prod", KubeconfigData: []byte(kubeconfig)}
+	require.NoError(t, clusterManager.AddDBCluster(cluster))
+	return clusterManager, memoryStore, cluster.ID
+}
+
+// newAuditedDeploymentsRouter serves deleting deployments of a cluster backed by a fake API server,
+// recording the audit log in the returned store
+func newAuditedDeploymentsRouter(t *testing.T) (*gin.Engine, store.Store, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	clusterManager, memoryStore, clusterID := newFakeCluster(t, newFakeAPIServer(t).URL)
+
+	handler := NewResourceHandler(service.NewBaseResourceService[*appsv1.Deployment](new(service.DeploymentClient)), clusterManager, "deployments")
+	router := gin.New()
+	router.Use(RequestAuditContext(), k8s.ClusterContext(clusterManager, nil), ResourceAudit(service.NewAuditService(memoryStore, &configs.Config{})))
+	router.DELETE("/namespaces/:namespace/deployments/:name", func(c *gin.Context) {
+		c.Set("user_id", uint(7))
+		c.Set("username", "alice")
+		c.Set("user_role", "editor")
+	}, handler.Delete)
+	return router, memoryStore, clusterID
+}
+
+func TestResourceHandler_DeleteIsAudited(t *testing.T) {
+	router, memoryStore, clusterID := newAuditedDeploymentsRouter(t)
+
+	deleteDeployment := func(name string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/namespaces/default/deployments/"+name, nil)
+		req.Header.Set(k8s.ClusterIDHeader, clusterID)
+		req.Header.Set("User-Agent", "kubectl-test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, deleteDeployment("web"))
+	require.Equal(t, http.StatusNotFound, deleteDeployment("missing"))
+
+	logs, total, err := memoryStore.GetAuditLogsByAction(string(service.EventTypeResourceDelete), 0, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, total, "failed deletions are audited too")
+
+	entries := make(map[string]map[string]interface{})
+	for _, entry := range logs {
+		assert.Equal(t, "apps/v1/deployments", entry.Resource)
+		assert.Equal(t, service.ResourceActionDelete, entry.ResourceID)
+		require.NotNil(t, entry.UserID)
+		assert.Equal(t, uint(7), *entry.UserID)
+		assert.Equal(t, "192.0.2.1", entry.IPAddress)
+		assert.Equal(t, "kubectl-test", entry.UserAgent)
+
+		var details map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(entry.Details), &details))
+		assert.Equal(t, "apps", details["group"])
+		assert.Equal(t, "v1", details["version"])
+		assert.Equal(t, "deployments", details["resource"])
+		assert.Equal(t, "default", details["namespace"])
+		assert.Equal(t, "alice", details["username"])
+		assert.Equal(t, clusterID, details["cluster_id"])
+		entries[details["name"].(string)] = details
+	}
+
+	require.Contains(t, entries, "web")
+	assert.Equal(t, "success", entries["web"]["result"])
+	assert.NotContains(t, entries["web"], "error")
+	require.Contains(t, entries, "missing")
+	assert.Equal(t, "failure", entries["missing"]["result"])
+	assert.Contains(t, entries["missing"]["error"], "not found")
+}
+
+func TestStorageClassHandler_SetDefaultIsAudited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clusterManager, memoryStore, clusterID := newFakeCluster(t, newFakeAPIServer(t).URL)
+	router := gin.New()
+	router.Use(RequestAuditContext(), k8s.ClusterContext(clusterManager, nil), ResourceAudit(service.NewAuditService(memoryStore, &configs.Config{})))
+	router.POST("/storageclasses/:name/default", NewStorageClassHandler(clusterManager).SetDefault)
+
+	req := httptest.NewRequest(http.MethodPost, "/storageclasses/fast/default", nil)
+	req.Header.Set(k8s.ClusterIDHeader, clusterID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	logs, total, err := memoryStore.GetAuditLogsByAction(string(service.EventTypeResourceUpdate), 0, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	assert.Equal(t, "storage.k8s.io/v1/storageclasses", logs[0].Resource)
+	assert.Equal(t, service.ResourceActionPatch, logs[0].ResourceID)
+	assert.Contains(t, logs[0].Details, `"name":"fast"`)
+}
@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ciliverse/cilikube/internal/service"
 	"github.com/ciliverse/cilikube/pkg/k8s"
 	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // ResourceHandler generic handler
@@ -16,6 +21,7 @@ type ResourceHandler[T runtime.Object] struct {
 	service        service.ResourceService[T]
 	clusterManager *k8s.ClusterManager
 	resourceType   string
+	gvr            schema.GroupVersionResource
 }
 
 // NewResourceHandler creates generic handler
@@ -24,10 +30,11 @@ func NewResourceHandler[T runtime.Object](svc service.ResourceService[T], k8sMan
 		service:        svc,
 		clusterManager: k8sManager,
 		resourceType:   resourceType,
+		gvr:            builtinGVR[T](resourceType),
 	}
 }
 
-// List handles list requests
+// List handles list requests, answering in YAML or JSON according to the Accept header
 func (h *ResourceHandler[T]) List(c *gin.Context) {
 	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
 	if !ok {
@@ -35,21 +42,36 @@ func (h *ResourceHandler[T]) List(c *gin.Context) {
 	}
 
 	// For namespaced resources, get from path; for cluster resources, this parameter is empty
-	namespace := c.Param("namespace")
+	h.list(c, k8sClient, c.Param("namespace"))
+}
+
+// ListAcrossNamespaces handles list requests on a namespaced resource outside a namespace path, such as
+// /pods. The list spans all namespaces unless the namespace query parameter or the caller's default
+// namespace narrows it.
+func (h *ResourceHandler[T]) ListAcrossNamespaces(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	h.list(c, k8sClient, preferredNamespace(c, c.Query("namespace")))
+}
+
+// list answers a list request on namespace, all namespaces when it is empty
+func (h *ResourceHandler[T]) list(c *gin.Context, k8sClient *k8s.Client, namespace string) {
 	selector := c.Query("labelSelector")
 	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 64)
 	continueToken := c.Query("continue")
 
 	items, err := h.service.List(k8sClient.Clientset, namespace, selector, limit, continueToken)
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to get resource list", err.Error())
+		respondKubernetesError(c, "failed to get resource list", err)
 		return
 	}
 
-	utils.ApiSuccess(c, items, "successfully retrieved resource list")
+	respondResource(c, items, "successfully retrieved resource list", resourceVersionOf(items))
 }
 
-// Get handles single resource retrieval requests
+// Get handles single resource retrieval requests, answering in YAML or JSON according to the Accept header
 func (h *ResourceHandler[T]) Get(c *gin.Context) {
 	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
 	if !ok {
@@ -60,10 +82,10 @@ func (h *ResourceHandler[T]) Get(c *gin.Context) {
 
 	item, err := h.service.Get(k8sClient.Clientset, namespace, name)
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to get resource", err.Error())
+		respondKubernetesError(c, "failed to get resource", err)
 		return
 	}
-	utils.ApiSuccess(c, item, "successfully retrieved resource")
+	respondResource(c, item, "successfully retrieved resource", resourceVersionOf(item))
 }
 
 // Create handles resource creation requests
@@ -77,19 +99,32 @@ func (h *ResourceHandler[T]) Create(c *gin.Context) {
 	var obj T
 	// Kubernetes Create API requires a complete object, so we bind from request body
 	if err := c.ShouldBindJSON(&obj); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "invalid request body format", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	created, err := h.service.Create(k8sClient.Clientset, namespace, obj)
+	dryRun := isDryRun(c)
+	create, message := h.service.Create, "resource created successfully"
+	if dryRun {
+		create, message = h.service.DryRunCreate, "resource validated successfully (dry run)"
+	}
+	created, err := create(k8sClient.Clientset, namespace, obj)
+	if !dryRun {
+		h.audit(c, namespace, objectName(obj), service.ResourceActionCreate, err)
+	}
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to create resource", err.Error())
+		if errors.Is(err, service.ErrInvalidResource) {
+			respondKubernetesError(c, "resource validation failed", err)
+			return
+		}
+		respondKubernetesError(c, "failed to create resource", err)
 		return
 	}
-	utils.ApiSuccess(c, created, "resource created successfully")
+	utils.ApiSuccess(c, created, message)
 }
 
-// Update handles resource update requests
+// Update handles resource update requests with a JSON or YAML body. The update only applies to the version
+// of the object the client edited, see requireResourceVersion.
 func (h *ResourceHandler[T]) Update(c *gin.Context) {
 	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
 	if !ok {
@@ -99,17 +134,38 @@ func (h *ResourceHandler[T]) Update(c *gin.Context) {
 	name := c.Param("name")
 
 	var obj T
-	if err := c.ShouldBindJSON(&obj); err != nil {
+	if err := bindResource(c, &obj); err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "invalid request body format", err.Error())
 		return
 	}
+	if !requireResourceVersion(c, obj) {
+		return
+	}
 
-	updated, err := h.service.Update(k8sClient.Clientset, namespace, name, obj)
+	dryRun := isDryRun(c)
+	update, message := h.service.Update, "resource updated successfully"
+	if dryRun {
+		update, message = h.service.DryRunUpdate, "resource validated successfully (dry run)"
+	}
+	updated, err := update(k8sClient.Clientset, namespace, name, obj)
+	if !dryRun {
+		h.audit(c, namespace, name, service.ResourceActionUpdate, err)
+	}
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to update resource", err.Error())
+		if errors.Is(err, service.ErrInvalidResource) {
+			respondKubernetesError(c, "resource validation failed", err)
+			return
+		}
+		respondUpdateError(c, "failed to update resource", err)
 		return
 	}
-	utils.ApiSuccess(c, updated, "resource updated successfully")
+	utils.ApiSuccess(c, updated, message)
+}
+
+// isDryRun reports whether the request asks for a server-side dry run with ?dryRun=true (or kubectl's dryRun=All)
+func isDryRun(c *gin.Context) bool {
+	value := c.Query("dryRun")
+	return strings.EqualFold(value, "true") || value == metav1.DryRunAll
 }
 
 // Patch handles resource patch requests (for partial updates like scaling)
@@ -124,22 +180,26 @@ func (h *ResourceHandler[T]) Patch(c *gin.Context) {
 	// For PATCH requests, we expect a partial update object
 	var patchData map[string]interface{}
 	if err := c.ShouldBindJSON(&patchData); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "invalid patch data format", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
+	action := service.MergePatchAction(patchData)
+
 	// Get the current resource first
 	current, err := h.service.Get(k8sClient.Clientset, namespace, name)
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to get current resource", err.Error())
+		h.audit(c, namespace, name, action, err)
+		respondKubernetesError(c, "failed to get current resource", err)
 		return
 	}
 
 	// Apply patch to the current resource
 	// This is a simplified patch implementation - in production you might want to use strategic merge patch
 	updated, err := h.service.Patch(k8sClient.Clientset, namespace, name, current, patchData)
+	h.audit(c, namespace, name, action, err)
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to patch resource", err.Error())
+		respondKubernetesError(c, "failed to patch resource", err)
 		return
 	}
 	utils.ApiSuccess(c, updated, "resource patched successfully")
@@ -155,13 +215,33 @@ func (h *ResourceHandler[T]) Delete(c *gin.Context) {
 	name := c.Param("name")
 
 	err := h.service.Delete(k8sClient.Clientset, namespace, name)
+	h.audit(c, namespace, name, service.ResourceActionDelete, err)
 	if err != nil {
-		utils.ApiError(c, http.StatusInternalServerError, "failed to delete resource", err.Error())
+		respondKubernetesError(c, "failed to delete resource", err)
 		return
 	}
 	utils.ApiSuccess(c, nil, "resource deleted successfully")
 }
 
+// audit records a mutating operation on a resource of the handler's type
+func (h *ResourceHandler[T]) audit(c *gin.Context, namespace, name, action string, err error) {
+	auditResourceChange(c, h.gvr, namespace, name, action, err)
+}
+
+// resourceVersionOf returns the resourceVersion of an object or list, which changes whenever the data does
+func resourceVersionOf(obj runtime.Object) string {
+	if meta.IsListType(obj) {
+		if list, err := meta.ListAccessor(obj); err == nil {
+			return list.GetResourceVersion()
+		}
+		return ""
+	}
+	if accessor, err := meta.Accessor(obj); err == nil {
+		return accessor.GetResourceVersion()
+	}
+	return ""
+}
+
 // Watch handles resource watch requests
 func (h *ResourceHandler[T]) Watch(c *gin.Context) {
 	utils.ApiError(c, http.StatusNotImplemented, "Watch not yet implemented", "")
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+func TestResourceVersionOf(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", ResourceVersion: "42"}}
+	assert.Equal(t, "42", resourceVersionOf(pod))
+
+	list := &corev1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "1001"}, Items: []corev1.Pod{*pod}}
+	assert.Equal(t, "1001", resourceVersionOf(list), "lists use the collection's resourceVersion")
+
+	assert.Empty(t, resourceVersionOf(&corev1.PodList{}))
+}
+
+// serveResource answers a GET with respondResource for obj, sending accept as the Accept header when set
+func serveResource(obj runtime.Object, accept string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/resource", func(c *gin.Context) {
+		respondResource(c, obj, "successfully retrieved resource", resourceVersionOf(obj))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRespondResource_ContentNegotiation(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "web", Namespace: "default", ResourceVersion: "42", Labels: map[string]string{"app": "web"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx:1.27"}}},
+	}
+
+	jsonResponse := serveResource(pod, "application/json")
+	require.Equal(t, http.StatusOK, jsonResponse.Code)
+	assert.Contains(t, jsonResponse.Header().Get("Content-Type"), "application/json")
+	assert.Equal(t, `"42"`, jsonResponse.Header().Get("ETag"))
+	assert.Contains(t, jsonResponse.Header().Values("Vary"), "Accept")
+	var envelope struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(jsonResponse.Body.Bytes(), &envelope))
+
+	yamlResponse := serveResource(pod, "application/yaml")
+	require.Equal(t, http.StatusOK, yamlResponse.Code)
+	assert.Contains(t, yamlResponse.Header().Get("Content-Type"), "application/yaml")
+	assert.Contains(t, yamlResponse.Body.String(), "kind: Pod\n")
+	var manifest map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(yamlResponse.Body.Bytes(), &manifest))
+
+	assert.Equal(t, envelope.Data, manifest, "both formats carry the same object")
+	assert.Equal(t, "v1", manifest["apiVersion"])
+	metadata := manifest["metadata"].(map[string]interface{})
+	assert.Equal(t, "web", metadata["name"])
+	assert.NotContains(t, metadata, "managedFields")
+	assert.Len(t, pod.ManagedFields, 1, "the object passed in is left untouched")
+
+	for accept, wantType := range map[string]string{
+		"":                                   "application/json",
+		"application/x-yaml":                 "application/yaml",
+		"application/yaml, application/json": "application/yaml",
+		"text/html":                          "application/json",
+		"*/*":                                "application/json",
+	} {
+		assert.Contains(t, serveResource(pod, accept).Header().Get("Content-Type"), wantType, "Accept: %q", accept)
+	}
+}
+
+func TestRespondResource_List(t *testing.T) {
+	managed := []metav1.ManagedFieldsEntry{{Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate}}
+	list := &corev1.PodList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "1001"},
+		Items: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-1", ManagedFields: managed}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "web-2", ManagedFields: managed}},
+		},
+	}
+
+	var envelope struct {
+		Data corev1.PodList `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(serveResource(list, "").Body.Bytes(), &envelope))
+	var manifest corev1.PodList
+	require.NoError(t, yaml.Unmarshal(serveResource(list, "application/yaml").Body.Bytes(), &manifest))
+
+	assert.Equal(t, envelope.Data, manifest)
+	assert.Equal(t, "PodList", manifest.Kind)
+	require.Len(t, manifest.Items, 2)
+	for _, item := range manifest.Items {
+		assert.Empty(t, item.ManagedFields)
+	}
+}
+
+func TestResourceHandler_ListAcrossNamespacesUsesPreferredNamespace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var requested string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`)
+	}))
+	t.Cleanup(apiServer.Close)
+	clusterManager, memoryStore, clusterID := newFakeCluster(t, apiServer.URL)
+
+	preferences := service.NewPreferenceService(memoryStore)
+	_, err := preferences.UpdatePreference(7, clusterID, &models.UpdateUserPreferenceRequest{DefaultNamespace: "payments"})
+	require.NoError(t, err)
+
+	handler := NewResourceHandler(service.NewBaseResourceService[*corev1.Pod](new(service.PodClient)), clusterManager, "pods")
+	router := gin.New()
+	router.Use(k8s.ClusterContext(clusterManager, nil), PreferredNamespaces(preferences))
+	router.GET("/pods", func(c *gin.Context) {
+		if c.GetHeader("X-Test-User") != "" {
+			c.Set("user_id", uint(7))
+			c.Set("username", "alice")
+			c.Set("user_role", "viewer")
+		}
+	}, handler.ListAcrossNamespaces)
+
+	list := func(query string, authenticated bool) string {
+		req := httptest.NewRequest(http.MethodGet, "/pods"+query, nil)
+		req.Header.Set(k8s.ClusterIDHeader, clusterID)
+		if authenticated {
+			req.Header.Set("X-Test-User", "alice")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return requested
+	}
+	assert.Equal(t, "/api/v1/namespaces/payments/pods", list("", true), "the caller's default namespace applies")
+	assert.Equal(t, "/api/v1/namespaces/kube-system/pods", list("?namespace=kube-system", true), "an explicit namespace wins")
+	assert.Equal(t, "/api/v1/pods", list("", false), "anonymous callers list all namespaces")
+}
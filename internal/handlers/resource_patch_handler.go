@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// patchContentTypes maps the accepted Content-Type of a patch request to its patch type
+var patchContentTypes = map[string]types.PatchType{
+	string(types.JSONPatchType):           types.JSONPatchType,
+	string(types.MergePatchType):          types.MergePatchType,
+	string(types.StrategicMergePatchType): types.StrategicMergePatchType,
+}
+
+// ResourcePatchHandler handles patching namespaced resources
+type ResourcePatchHandler struct {
+	service               *service.ResourcePatchService
+	customResourceService *service.CustomResourceService
+	permissionService     *service.PermissionService
+	clusterManager        *k8s.ClusterManager
+}
+
+// NewResourcePatchHandler creates a new ResourcePatchHandler
+func NewResourcePatchHandler(svc *service.ResourcePatchService, customResourceService *service.CustomResourceService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *ResourcePatchHandler {
+	return &ResourcePatchHandler{
+		service:               svc,
+		customResourceService: customResourceService,
+		permissionService:     permissionService,
+		clusterManager:        cm,
+	}
+}
+
+// Patch handles PATCH /api/v1/clusters/:id/namespaces/:namespace/:resource/:name. The Content-Type selects
+// the patch type: application/json-patch+json, application/merge-patch+json or
+// application/strategic-merge-patch+json.
+func (h *ResourcePatchHandler) Patch(c *gin.Context) {
+	namespace := c.Param("namespace")
+	resource := c.Param("resource")
+	name := c.Param("name")
+
+	patchType, ok := patchContentTypes[c.ContentType()]
+	if !ok {
+		utils.ApiError(c, http.StatusUnsupportedMediaType, "unsupported patch content type",
+			fmt.Sprintf("expected %s, %s or %s", types.JSONPatchType, types.MergePatchType, types.StrategicMergePatchType))
+		return
+	}
+	data, err := c.GetRawData()
+	if err != nil {
+		status := http.StatusBadRequest
+		if utils.IsBodyTooLarge(err) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		utils.ApiError(c, status, "failed to read request body", err.Error())
+		return
+	}
+	if !authorizeNamespacedResource(c, h.permissionService, namespace, resource, http.MethodPatch) {
+		return
+	}
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	mapper := h.customResourceService.MapperFor(c.Param("id"), k8sClient.DiscoveryClient)
+	patched, err := h.service.Patch(c.Request.Context(), k8sClient.DynamicClient, mapper, namespace, resource, name, patchType, data)
+	auditResourceChange(c, resolveGVR(mapper, resource), namespace, name, service.PatchAction(patchType, data), err)
+	if err != nil {
+		respondKubernetesError(c, "failed to patch resource", err)
+		return
+	}
+	utils.ApiSuccess(c, patched, fmt.Sprintf("patched %s %s", resource, name))
+}
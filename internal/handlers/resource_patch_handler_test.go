@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourcePatchHandler_Guards(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/clusters/:id/namespaces/:namespace/:resource/:name", NewResourcePatchHandler(nil, nil, nil, nil).Patch)
+
+	for contentType, status := range map[string]int{
+		"application/json":             http.StatusUnsupportedMediaType,
+		"application/apply-patch+yaml": http.StatusUnsupportedMediaType,
+		"":                             http.StatusUnsupportedMediaType,
+		// Without an authenticated user nothing is patched whatever the patch type
+		"application/json-patch+json":                           http.StatusUnauthorized,
+		"application/merge-patch+json":                          http.StatusUnauthorized,
+		"application/strategic-merge-patch+json; charset=utf-8": http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/clusters/c1/namespaces/test/deployments/web", strings.NewReader(`{"spec":{}}`))
+		req.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, contentType)
+	}
+}
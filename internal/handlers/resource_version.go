@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// staleResourceMessage is the message of the 409 answered to an update based on an outdated copy
+const staleResourceMessage = "resource changed, please reload"
+
+// requireResourceVersion makes an update conditional on the version of the object the client edited: the
+// metadata.resourceVersion of the body, or the ETag of the fetched object sent back as If-Match. The API
+// server then rejects the update with a conflict when the object changed since, instead of overwriting
+// it. Updates naming no version are refused with 428 Precondition Required. Reports whether the update
+// may proceed, the error response being written otherwise.
+func requireResourceVersion(c *gin.Context, obj runtime.Object) bool {
+	if value := reflect.ValueOf(obj); !value.IsValid() || value.IsNil() {
+		utils.ApiError(c, http.StatusBadRequest, "invalid request body format", "the body holds no object")
+		return false
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid request body format", err.Error())
+		return false
+	}
+	ifMatch, err := ifMatchResourceVersion(c.GetHeader("If-Match"))
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid If-Match header", err.Error())
+		return false
+	}
+
+	version := accessor.GetResourceVersion()
+	switch {
+	case version == "" && ifMatch == "":
+		utils.ApiError(c, http.StatusPreconditionRequired, "resourceVersion is required",
+			"send the metadata.resourceVersion of the object you edited, or its ETag as If-Match")
+		return false
+	case version != "" && ifMatch != "" && version != ifMatch:
+		utils.ApiError(c, http.StatusBadRequest, "If-Match does not match metadata.resourceVersion",
+			fmt.Sprintf("If-Match names %s, the body %s", ifMatch, version))
+		return false
+	case version == "":
+		accessor.SetResourceVersion(ifMatch)
+	}
+	return true
+}
+
+// ifMatchResourceVersion returns the resourceVersion of an If-Match header holding a single ETag as set by
+// utils.ApiSuccessWithETag, or "" when the header is missing or "*", which name no version
+func ifMatchResourceVersion(header string) (string, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return "", nil
+	}
+	if strings.HasPrefix(header, "W/") {
+		return "", fmt.Errorf("weak ETag %s cannot make an update conditional", header)
+	}
+	version, err := strconv.Unquote(header)
+	if err != nil || version == "" || strings.ContainsAny(version, `",`) {
+		return "", fmt.Errorf("expected a single quoted ETag, got %s", header)
+	}
+	return version, nil
+}
+
+// respondUpdateError writes the error response of a failed update, telling the client to reload the
+// object when it changed since it was read
+func respondUpdateError(c *gin.Context, message string, err error) {
+	if k8serrors.IsConflict(err) {
+		utils.ApiErrorFrom(c, utils.NewAPIError(http.StatusConflict, utils.ErrCodeConflict, staleResourceMessage, err.Error()))
+		return
+	}
+	respondKubernetesError(c, message, err)
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+)
+
+func TestIfMatchResourceVersion(t *testing.T) {
+	for header, want := range map[string]string{"": "", "*": "", `"42"`: "42", ` "42" `: "42"} {
+		version, err := ifMatchResourceVersion(header)
+		require.NoError(t, err, header)
+		assert.Equal(t, want, version, header)
+	}
+	for _, header := range []string{"42", `W/"42"`, `""`, `"41", "42"`} {
+		_, err := ifMatchResourceVersion(header)
+		assert.Error(t, err, header)
+	}
+}
+
+// fakeDeploymentServer is an API server holding the deployment default/web, which rejects updates that
+// don't name its current resourceVersion as a real one does
+type fakeDeploymentServer struct {
+	mu         sync.Mutex
+	deployment appsv1.Deployment
+	updates    int
+}
+
+func newFakeDeploymentServer(t *testing.T) (*fakeDeploymentServer, *httptest.Server) {
+	t.Helper()
+	f := &fakeDeploymentServer{}
+	f.deployment.APIVersion, f.deployment.Kind = "apps/v1", "Deployment"
+	f.deployment.Name, f.deployment.Namespace, f.deployment.ResourceVersion = "web", "default", "1"
+	f.deployment.Spec.Replicas = ptr.To[int32](1)
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+// modify changes the deployment as another client would, bumping its resourceVersion
+func (f *fakeDeploymentServer) modify(replicas int32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deployment.Spec.Replicas = ptr.To(replicas)
+	f.bumpVersion()
+}
+
+func (f *fakeDeploymentServer) bumpVersion() {
+	version, _ := strconv.Atoi(f.deployment.ResourceVersion)
+	f.deployment.ResourceVersion = strconv.Itoa(version + 1)
+}
+
+func (f *fakeDeploymentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path != "/apis/apps/v1/namespaces/default/deployments/web" {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404,"message":"not found"}`)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.deployment)
+	case http.MethodPut:
+		// Built-in types are sent as protobuf
+		data, _ := io.ReadAll(r.Body)
+		var update appsv1.Deployment
+		if _, _, err := scheme.Codecs.UniversalDeserializer().Decode(data, nil, &update); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if update.ResourceVersion != f.deployment.ResourceVersion {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Conflict","code":409,`+
+				`"message":"Operation cannot be fulfilled on deployments.apps \"web\": the object has been modified; please apply your changes to the latest version and try again"}`)
+			return
+		}
+		f.deployment.Spec = update.Spec
+		f.bumpVersion()
+		f.updates++
+		_ = json.NewEncoder(w).Encode(f.deployment)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestResourceHandler_UpdateIsConditional(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	apiServer, server := newFakeDeploymentServer(t)
+	clusterManager, _, clusterID := newFakeCluster(t, server.URL)
+	handler := NewResourceHandler(service.NewBaseResourceService[*appsv1.Deployment](new(service.DeploymentClient)), clusterManager, "deployments")
+	router := gin.New()
+	router.Use(k8s.ClusterContext(clusterManager, nil))
+	router.GET("/namespaces/:namespace/deployments/:name", handler.Get)
+	router.PUT("/namespaces/:namespace/deployments/:name", handler.Update)
+
+	send := func(method, contentType, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/namespaces/default/deployments/web", strings.NewReader(body))
+		req.Header.Set(k8s.ClusterIDHeader, clusterID)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	update := func(version string, replicas int, headers map[string]string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"metadata":{"name":"web","namespace":"default","resourceVersion":%q},"spec":{"replicas":%d}}`, version, replicas)
+		return send(http.MethodPut, "application/json", body, headers)
+	}
+	errorOf := func(w *httptest.ResponseRecorder) (string, string) {
+		var response struct {
+			ErrorCode string `json:"errorCode"`
+			Message   string `json:"message"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.ErrorCode, response.Message
+	}
+
+	t.Run("a missing resourceVersion is refused", func(t *testing.T) {
+		w := update("", 3, nil)
+		assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+		code, _ := errorOf(w)
+		assert.Equal(t, "PRECONDITION_REQUIRED", code)
+		assert.Equal(t, 0, apiServer.updates, "nothing reaches the API server")
+	})
+
+	t.Run("a concurrent modification makes the update conflict", func(t *testing.T) {
+		read := send(http.MethodGet, "", "", nil)
+		require.Equal(t, http.StatusOK, read.Code)
+		etag := read.Header().Get("ETag")
+		require.Equal(t, `"1"`, etag)
+
+		apiServer.modify(5)
+
+		w := update("1", 3, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		code, message := errorOf(w)
+		assert.Equal(t, "CONFLICT", code)
+		assert.Equal(t, staleResourceMessage, message)
+
+		w = update("", 3, map[string]string{"If-Match": etag})
+		assert.Equal(t, http.StatusConflict, w.Code, "the ETag read before the change is stale too")
+		assert.Equal(t, 0, apiServer.updates)
+		assert.EqualValues(t, 5, *apiServer.deployment.Spec.Replicas, "the other client's change is kept")
+	})
+
+	t.Run("an update of the current version applies", func(t *testing.T) {
+		w := update("", 3, map[string]string{"If-Match": `"2"`})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, 1, apiServer.updates)
+
+		w = update("2", 4, map[string]string{"If-Match": `"3"`})
+		assert.Equal(t, http.StatusBadRequest, w.Code, "If-Match and the body must agree")
+	})
+
+	t.Run("the YAML edit flow keeps the resourceVersion it read", func(t *testing.T) {
+		read := send(http.MethodGet, "", "", map[string]string{"Accept": "application/yaml"})
+		require.Equal(t, http.StatusOK, read.Code)
+		assert.Equal(t, `"3"`, read.Header().Get("ETag"))
+		manifest := read.Body.String()
+		require.Contains(t, manifest, "resourceVersion: \"3\"")
+
+		edited := strings.Replace(manifest, "replicas: 3", "replicas: 6", 1)
+		w := send(http.MethodPut, "application/yaml", edited, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.EqualValues(t, 6, *apiServer.deployment.Spec.Replicas)
+
+		w = send(http.MethodPut, "application/yaml", manifest, nil)
+		assert.Equal(t, http.StatusConflict, w.Code, "saving the same copy again would overwrite the first save")
+	})
+}
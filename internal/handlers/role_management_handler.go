@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -24,17 +27,25 @@ func NewRoleManagementHandler(roleService *service.RoleService) *RoleManagementH
 	}
 }
 
-// ListRoles gets all roles in the system
+// ListRoles gets a page of the roles in the system
 func (h *RoleManagementHandler) ListRoles(c *gin.Context) {
+	page, ok := utils.ParsePage(c)
+	if !ok {
+		return
+	}
+
 	roles, err := h.roleService.ListRoles()
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "Failed to get roles", err.Error())
 		return
 	}
 
+	start, end := page.Bounds(len(roles))
 	utils.ApiSuccess(c, gin.H{
-		"roles": roles,
-		"total": len(roles),
+		"roles":     roles[start:end],
+		"total":     len(roles),
+		"page":      page.Page,
+		"page_size": page.PageSize,
 	}, "Roles retrieved successfully")
 }
 
@@ -79,11 +90,11 @@ func (h *RoleManagementHandler) GetRole(c *gin.Context) {
 func (h *RoleManagementHandler) CreateRole(c *gin.Context) {
 	var req models.CreateRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	role, err := h.roleService.CreateRole(&req)
+	role, err := h.roleService.CreateRole(c.Request.Context(), &req)
 	if err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "Failed to create role", err.Error())
 		return
@@ -103,11 +114,11 @@ func (h *RoleManagementHandler) UpdateRole(c *gin.Context) {
 
 	var req models.UpdateRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	role, err := h.roleService.UpdateRole(uint(roleID), &req)
+	role, err := h.roleService.UpdateRole(c.Request.Context(), uint(roleID), &req)
 	if err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "Failed to update role", err.Error())
 		return
@@ -125,7 +136,7 @@ func (h *RoleManagementHandler) DeleteRole(c *gin.Context) {
 		return
 	}
 
-	err = h.roleService.DeleteRole(uint(roleID))
+	err = h.roleService.DeleteRole(c.Request.Context(), uint(roleID))
 	if err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "Failed to delete role", err.Error())
 		return
@@ -141,7 +152,7 @@ func (h *RoleManagementHandler) DeleteRole(c *gin.Context) {
 func (h *RoleManagementHandler) AssignRoleToUser(c *gin.Context) {
 	var req models.AssignRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -152,7 +163,7 @@ func (h *RoleManagementHandler) AssignRoleToUser(c *gin.Context) {
 		return
 	}
 
-	err := h.roleService.AssignRoleToUser(req.UserID, req.RoleID, currentUserID)
+	err := h.roleService.AssignRoleToUser(c.Request.Context(), req.UserID, req.RoleID, currentUserID)
 	if err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "Failed to assign role", err.Error())
 		return
@@ -169,7 +180,7 @@ func (h *RoleManagementHandler) AssignRoleToUser(c *gin.Context) {
 func (h *RoleManagementHandler) RemoveRoleFromUser(c *gin.Context) {
 	var req models.RemoveRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -180,7 +191,7 @@ func (h *RoleManagementHandler) RemoveRoleFromUser(c *gin.Context) {
 		return
 	}
 
-	err := h.roleService.RemoveRoleFromUser(req.UserID, req.RoleID, currentUserID)
+	err := h.roleService.RemoveRoleFromUser(c.Request.Context(), req.UserID, req.RoleID, currentUserID)
 	if err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "Failed to remove role", err.Error())
 		return
@@ -217,7 +228,7 @@ func (h *RoleManagementHandler) GetUserRoles(c *gin.Context) {
 
 // GetRoleUsers gets all users assigned to a specific role
 func (h *RoleManagementHandler) GetRoleUsers(c *gin.Context) {
-	roleIDStr := c.Param("roleId")
+	roleIDStr := c.Param("id")
 	roleID, err := strconv.ParseUint(roleIDStr, 10, 32)
 	if err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "Invalid role ID")
@@ -237,6 +248,50 @@ func (h *RoleManagementHandler) GetRoleUsers(c *gin.Context) {
 	}, "Role users retrieved successfully")
 }
 
+// AssignRoleToUsers handles POST /roles/:id/users, assigning the role to every listed user
+func (h *RoleManagementHandler) AssignRoleToUsers(c *gin.Context) {
+	h.changeRoleUsers(c, h.roleService.AssignRoleToUsers, "Role assigned to users")
+}
+
+// RemoveRoleFromUsers handles DELETE /roles/:id/users, removing the role from every listed user
+func (h *RoleManagementHandler) RemoveRoleFromUsers(c *gin.Context) {
+	h.changeRoleUsers(c, h.roleService.RemoveRoleFromUsers, "Role removed from users")
+}
+
+// changeRoleUsers applies a bulk role change and answers with the result of each user
+func (h *RoleManagementHandler) changeRoleUsers(c *gin.Context, change func(ctx context.Context, roleID uint, userIDs []uint, changedBy uint) (*models.RoleUsersResponse, error), message string) {
+	roleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "Invalid role ID")
+		return
+	}
+
+	var req models.RoleUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	// Get current user ID for audit
+	currentUserID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	result, err := change(c.Request.Context(), uint(roleID), req.UserIDs, currentUserID)
+	if err != nil {
+		if errors.Is(err, service.ErrRoleNotFound) {
+			utils.ApiError(c, http.StatusNotFound, "Role not found", err.Error())
+			return
+		}
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to update role users", err.Error())
+		return
+	}
+
+	utils.ApiSuccess(c, result, fmt.Sprintf("%s: %d succeeded, %d failed", message, result.Succeeded, result.Failed))
+}
+
 // GetAvailablePermissions gets all available permissions in the system
 func (h *RoleManagementHandler) GetAvailablePermissions(c *gin.Context) {
 	// Define available permission categories
@@ -343,7 +398,7 @@ func (h *RoleManagementHandler) UpdateRolePermissions(c *gin.Context) {
 		Permissions []string `json:"permissions" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler handles cluster-wide resource search requests
+type SearchHandler struct {
+	service           *service.SearchService
+	permissionService *service.PermissionService
+	clusterManager    *k8s.ClusterManager
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(svc *service.SearchService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *SearchHandler {
+	return &SearchHandler{
+		service:           svc,
+		permissionService: permissionService,
+		clusterManager:    cm,
+	}
+}
+
+// Search handles GET /api/v1/clusters/:id/search
+func (h *SearchHandler) Search(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	req := models.SearchRequest{Query: c.Query("q")}
+	if kinds := c.Query("kinds"); kinds != "" {
+		req.Kinds = strings.Split(kinds, ",")
+	}
+	if page, err := strconv.Atoi(c.Query("page")); err == nil {
+		req.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.Query("pageSize")); err == nil {
+		req.PageSize = pageSize
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		utils.ApiError(c, http.StatusBadRequest, "invalid parameters", "query parameter 'q' is required")
+		return
+	}
+
+	listers, err := h.service.ListersFor(c.Param("id"), k8sClient.Clientset)
+	if err != nil {
+		utils.ApiError(c, http.StatusServiceUnavailable, "failed to prepare search cache", err.Error())
+		return
+	}
+
+	allowed, ok := namespacedResourceFilter(c, h.permissionService, http.MethodGet)
+	if !ok {
+		return
+	}
+	result, err := h.service.Search(listers, req, allowed)
+	if err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "failed to search resources", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, result, "successfully searched resources")
+}
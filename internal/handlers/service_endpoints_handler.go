@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ServiceEndpointsHandler handles Service endpoints requests
+type ServiceEndpointsHandler struct {
+	service        *service.ServiceEndpointsService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewServiceEndpointsHandler creates a new ServiceEndpointsHandler
+func NewServiceEndpointsHandler(svc *service.ServiceEndpointsService, cm *k8s.ClusterManager) *ServiceEndpointsHandler {
+	return &ServiceEndpointsHandler{
+		service:        svc,
+		clusterManager: cm,
+	}
+}
+
+// GetServiceEndpoints handles GET /api/v1/clusters/:id/namespaces/:namespace/services/:name/endpoints
+func (h *ServiceEndpointsHandler) GetServiceEndpoints(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	endpoints, err := h.service.GetServiceEndpoints(k8sClient.Clientset, namespace, name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "service not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to get service endpoints", err)
+		return
+	}
+	utils.ApiSuccess(c, endpoints, "successfully retrieved service endpoints")
+}
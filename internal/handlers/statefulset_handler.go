@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// StatefulSetHandler handles StatefulSet requests beyond generic CRUD
+type StatefulSetHandler struct {
+	clusterManager *k8s.ClusterManager
+}
+
+// NewStatefulSetHandler creates a new StatefulSetHandler
+func NewStatefulSetHandler(cm *k8s.ClusterManager) *StatefulSetHandler {
+	return &StatefulSetHandler{clusterManager: cm}
+}
+
+// ListItems handles GET /namespaces/:namespace/statefulsets/summary
+func (h *StatefulSetHandler) ListItems(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	items, err := service.ListStatefulSetItems(c.Request.Context(), k8sClient.Clientset, c.Param("namespace"))
+	if err != nil {
+		respondKubernetesError(c, "failed to get stateful set list", err)
+		return
+	}
+	utils.ApiSuccess(c, items, "successfully retrieved stateful set list")
+}
+
+// GetStatus handles GET /namespaces/:namespace/statefulsets/:name/status
+func (h *StatefulSetHandler) GetStatus(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	item, err := service.GetStatefulSetItem(c.Request.Context(), k8sClient.Clientset, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "stateful set not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to get stateful set status", err)
+		return
+	}
+	utils.ApiSuccess(c, item, "successfully retrieved stateful set status")
+}
+
+// SetPartition handles PUT /namespaces/:namespace/statefulsets/:name/partition
+func (h *StatefulSetHandler) SetPartition(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	var req models.UpdateStatefulSetPartitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	sts, err := service.SetStatefulSetPartition(c.Request.Context(), k8sClient.Clientset, namespace, name, *req.Partition)
+	auditResourceChange(c, appsv1.SchemeGroupVersion.WithResource("statefulsets"), namespace, name, service.ResourceActionUpdate, err)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "stateful set not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to set stateful set partition", err)
+		return
+	}
+	utils.ApiSuccess(c, service.StatefulSetItemFrom(sts), "stateful set partition updated successfully")
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// StorageClassHandler handles StorageClass requests beyond generic CRUD
+type StorageClassHandler struct {
+	clusterManager *k8s.ClusterManager
+}
+
+// NewStorageClassHandler creates a new StorageClassHandler
+func NewStorageClassHandler(cm *k8s.ClusterManager) *StorageClassHandler {
+	return &StorageClassHandler{clusterManager: cm}
+}
+
+// ListItems handles GET /api/v1/storageclasses/summary
+func (h *StorageClassHandler) ListItems(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+
+	items, err := service.ListStorageClassItems(k8sClient.Clientset)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to get storage class list", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, items, "successfully retrieved storage class list")
+}
+
+// SetDefault handles POST /api/v1/storageclasses/:name/default
+func (h *StorageClassHandler) SetDefault(c *gin.Context) {
+	k8sClient, ok := k8s.GetClientFromQuery(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	name := c.Param("name")
+
+	sc, err := service.SetDefaultStorageClass(k8sClient.Clientset, name)
+	auditResourceChange(c, storagev1.SchemeGroupVersion.WithResource("storageclasses"), "", name, service.ResourceActionPatch, err)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			utils.ApiError(c, http.StatusNotFound, "storage class not found", err.Error())
+			return
+		}
+		respondKubernetesError(c, "failed to set default storage class", err)
+		return
+	}
+	utils.ApiSuccess(c, sc, "default storage class updated successfully")
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ciliverse/cilikube/pkg/k8s"
 
 	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -55,11 +56,7 @@ func (h *SummaryHandler) GetResourceSummary(c *gin.Context) {
 func (h *SummaryHandler) GetBackendDependencies(c *gin.Context) {
 	dependencies, err := h.service.GetBackendDependencies()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    http.StatusInternalServerError,
-			"data":    nil,
-			"message": "Failed to get backend dependencies: " + err.Error(),
-		})
+		utils.ApiError(c, http.StatusInternalServerError, "Failed to get backend dependencies", err.Error())
 		return
 	}
 	// Use a different response structure if needed, but returning the slice directly is fine
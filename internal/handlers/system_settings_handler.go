@@ -1,8 +1,6 @@
 package handlers
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 
 	"github.com/ciliverse/cilikube/pkg/utils"
@@ -67,7 +65,7 @@ func (h *SystemSettingsHandler) UpdateOAuthSettings(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -135,7 +133,7 @@ func (h *SystemSettingsHandler) UpdateSecuritySettings(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -197,7 +195,7 @@ func (h *SystemSettingsHandler) UpdateSystemPreferences(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateHandler handles resource templates and creating resources from them
+type TemplateHandler struct {
+	service               *service.TemplateService
+	customResourceService *service.CustomResourceService
+	permissionService     *service.PermissionService
+	clusterManager        *k8s.ClusterManager
+}
+
+// NewTemplateHandler creates a new TemplateHandler
+func NewTemplateHandler(svc *service.TemplateService, customResourceService *service.CustomResourceService, permissionService *service.PermissionService, cm *k8s.ClusterManager) *TemplateHandler {
+	return &TemplateHandler{
+		service:               svc,
+		customResourceService: customResourceService,
+		permissionService:     permissionService,
+		clusterManager:        cm,
+	}
+}
+
+// ListTemplates handles GET /api/v1/templates, returning the built-in and stored templates
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.service.ListTemplates()
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to list templates", err.Error())
+		return
+	}
+	utils.ApiSuccess(c, templates, "successfully retrieved templates")
+}
+
+// GetTemplate handles GET /api/v1/templates/:name
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	template, err := h.service.GetTemplate(c.Param("name"))
+	if err != nil {
+		respondTemplateError(c, "failed to get template", err)
+		return
+	}
+	utils.ApiSuccess(c, template, "successfully retrieved template")
+}
+
+// CreateTemplate handles POST /api/v1/templates (admin). The manifest is a Go template whose
+// parameters are used as {{ .name }}; it is checked when stored.
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	userID, _, _, ok := auth.GetCurrentUser(c)
+	if !ok {
+		utils.ApiError(c, http.StatusUnauthorized, "user information not found", "")
+		return
+	}
+	var req models.CreateResourceTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	template, err := h.service.CreateTemplate(&req, userID)
+	if err != nil {
+		respondTemplateError(c, "failed to create template", err)
+		return
+	}
+	utils.ApiSuccess(c, template, "template created successfully")
+}
+
+// InstantiateTemplate handles POST /api/v1/clusters/:id/templates/:name/instantiate. The template is
+// rendered with the parameter values in the body and the objects are applied into its namespace with
+// server-side apply, each object being reported as applied or failed.
+func (h *TemplateHandler) InstantiateTemplate(c *gin.Context) {
+	allowed, ok := namespacedResourceFilter(c, h.permissionService, http.MethodPost)
+	if !ok {
+		return
+	}
+	var req models.InstantiateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ApiBindError(c, err)
+		return
+	}
+
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return
+	}
+	if k8sClient.DynamicClient == nil {
+		utils.ApiError(c, http.StatusInternalServerError, "dynamic client not available", "")
+		return
+	}
+
+	opts := service.ImportOptions{DryRun: req.DryRun}
+	if allowed != nil {
+		opts.Allowed = func(resource string) bool {
+			return allowed(req.Namespace, resource)
+		}
+	}
+
+	mapper := h.customResourceService.MapperFor(c.Param("id"), k8sClient.DiscoveryClient)
+	result, err := h.service.Instantiate(c.Request.Context(), k8sClient.DynamicClient, mapper, c.Param("name"), req.Namespace, req.Params, opts)
+	if err != nil {
+		respondTemplateError(c, "failed to instantiate template", err)
+		return
+	}
+	if !result.DryRun {
+		for _, object := range result.Objects {
+			var err error
+			if object.Status == service.ImportFailed {
+				err = errors.New(object.Error)
+			}
+			auditResourceChange(c, kindGVR(mapper, object.APIVersion, object.Kind), req.Namespace, object.Name, service.ResourceActionApply, err)
+		}
+	}
+	utils.ApiSuccess(c, result, fmt.Sprintf("applied %d of %d objects", result.Applied, len(result.Objects)))
+}
+
+// respondTemplateError maps template service errors to HTTP statuses
+func respondTemplateError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrTemplateNotFound):
+		utils.ApiError(c, http.StatusNotFound, message, err.Error())
+	case errors.Is(err, service.ErrTemplateExists):
+		utils.ApiError(c, http.StatusConflict, message, err.Error())
+	case errors.Is(err, service.ErrInvalidTemplate), errors.Is(err, service.ErrInvalidTemplateParams):
+		utils.ApiError(c, http.StatusBadRequest, message, err.Error())
+	default:
+		utils.ApiError(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/internal/service"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// TopHandler handles requests ranking pods and nodes by current resource usage
+type TopHandler struct {
+	service        *service.TopService
+	clusterManager *k8s.ClusterManager
+}
+
+// NewTopHandler creates a new TopHandler
+func NewTopHandler(svc *service.TopService, cm *k8s.ClusterManager) *TopHandler {
+	return &TopHandler{
+		service:        svc,
+		clusterManager: cm,
+	}
+}
+
+// TopPods handles GET /api/v1/clusters/:id/top/pods?sortBy=cpu|memory&namespace=&limit=
+func (h *TopHandler) TopPods(c *gin.Context) {
+	opts, ok := parseTopOptions(c)
+	if !ok {
+		return
+	}
+	opts.Namespace = preferredNamespace(c, opts.Namespace)
+	k8sClient, metricsClient, ok := h.clients(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.TopPods(metricsClient, k8sClient.Clientset, opts)
+	if err != nil {
+		respondTopError(c, "failed to get top pods", err)
+		return
+	}
+	utils.ApiSuccess(c, result, "successfully retrieved top pods")
+}
+
+// TopNodes handles GET /api/v1/clusters/:id/top/nodes?sortBy=cpu|memory&limit=
+func (h *TopHandler) TopNodes(c *gin.Context) {
+	opts, ok := parseTopOptions(c)
+	if !ok {
+		return
+	}
+	k8sClient, metricsClient, ok := h.clients(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.TopNodes(metricsClient, k8sClient.Clientset, opts)
+	if err != nil {
+		respondTopError(c, "failed to get top nodes", err)
+		return
+	}
+	utils.ApiSuccess(c, result, "successfully retrieved top nodes")
+}
+
+// clients returns the cluster client and a metrics client for the cluster addressed by the request
+func (h *TopHandler) clients(c *gin.Context) (*k8s.Client, versioned.Interface, bool) {
+	k8sClient, ok := k8s.GetClientFromPath(c, h.clusterManager)
+	if !ok {
+		return nil, nil, false
+	}
+	metricsClient, err := versioned.NewForConfig(k8sClient.Config)
+	if err != nil {
+		utils.ApiError(c, http.StatusInternalServerError, "failed to create metrics client", err.Error())
+		return nil, nil, false
+	}
+	return k8sClient, metricsClient, true
+}
+
+// parseTopOptions reads sortBy, namespace and limit from the query
+func parseTopOptions(c *gin.Context) (service.TopOptions, bool) {
+	opts := service.TopOptions{SortBy: c.Query("sortBy"), Namespace: c.Query("namespace")}
+	if err := service.ValidateTopSortBy(opts.SortBy); err != nil {
+		utils.ApiError(c, http.StatusBadRequest, "invalid parameters", err.Error())
+		return opts, false
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			utils.ApiError(c, http.StatusBadRequest, "invalid parameters", "limit must be a non-negative integer")
+			return opts, false
+		}
+		opts.Limit = n
+	}
+	return opts, true
+}
+
+// respondTopError answers 503 when metrics-server is not available
+func respondTopError(c *gin.Context, message string, err error) {
+	if errors.Is(err, service.ErrMetricsUnavailable) {
+		utils.ApiError(c, http.StatusServiceUnavailable, message, err.Error())
+		return
+	}
+	utils.ApiError(c, http.StatusInternalServerError, message, err.Error())
+}
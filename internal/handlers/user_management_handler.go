@@ -29,20 +29,15 @@ func NewUserManagementHandler(authService *service.AuthService, roleService *ser
 // ListUsers gets paginated list of users with optional search
 func (h *UserManagementHandler) ListUsers(c *gin.Context) {
 	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	page, ok := utils.ParsePage(c)
+	if !ok {
+		return
+	}
 	search := strings.TrimSpace(c.Query("search"))
 	status := c.Query("status") // active, inactive, all
 
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
-	}
-
 	// Get users from auth service
-	users, total, err := h.authService.GetUserList(page, pageSize)
+	users, total, err := h.authService.GetUserList(page.Page, page.PageSize)
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "Failed to get user list", err.Error())
 		return
@@ -109,10 +104,10 @@ func (h *UserManagementHandler) ListUsers(c *gin.Context) {
 	response := gin.H{
 		"data": enhancedUsers,
 		"pagination": gin.H{
-			"page":        page,
-			"page_size":   pageSize,
+			"page":        page.Page,
+			"page_size":   page.PageSize,
 			"total":       total,
-			"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+			"total_pages": (total + int64(page.PageSize) - 1) / int64(page.PageSize),
 		},
 		"filters": gin.H{
 			"search": search,
@@ -169,7 +164,7 @@ func (h *UserManagementHandler) GetUser(c *gin.Context) {
 func (h *UserManagementHandler) CreateUser(c *gin.Context) {
 	var req models.CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -179,14 +174,14 @@ func (h *UserManagementHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	// Create user using auth service register functionality
+	// Create the user even when self-registration is closed
 	registerReq := models.RegisterRequest{
 		Username: req.Username,
 		Email:    req.Email,
 		Password: req.Password,
 	}
 
-	createdUser, err := h.authService.Register(&registerReq)
+	createdUser, err := h.authService.CreateUser(c.Request.Context(), &registerReq)
 	if err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "Failed to create user", err.Error())
 		return
@@ -200,7 +195,7 @@ func (h *UserManagementHandler) CreateUser(c *gin.Context) {
 			AvatarURL:   "",
 		}
 
-		_, err = h.authService.UpdateProfile(createdUser.ID, &updateReq)
+		_, err = h.authService.UpdateProfile(c.Request.Context(), createdUser.ID, &updateReq)
 		if err != nil {
 			utils.ApiError(c, http.StatusInternalServerError, "User created but failed to update display name", err.Error())
 			return
@@ -228,7 +223,7 @@ func (h *UserManagementHandler) UpdateUser(c *gin.Context) {
 
 	var req models.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
@@ -248,7 +243,7 @@ func (h *UserManagementHandler) UpdateUser(c *gin.Context) {
 		AvatarURL:   req.AvatarURL,
 	}
 
-	_, err = h.authService.UpdateProfile(uid, &updateProfileReq)
+	_, err = h.authService.UpdateProfile(c.Request.Context(), uid, &updateProfileReq)
 	if err != nil {
 		utils.ApiError(c, http.StatusBadRequest, "Failed to update user", err.Error())
 		return
@@ -256,7 +251,7 @@ func (h *UserManagementHandler) UpdateUser(c *gin.Context) {
 
 	// Update user status if provided
 	if req.IsActive != nil {
-		err = h.authService.UpdateUserStatus(uid, *req.IsActive)
+		err = h.authService.UpdateUserStatus(c.Request.Context(), uid, *req.IsActive)
 		if err != nil {
 			utils.ApiError(c, http.StatusInternalServerError, "Failed to update user status", err.Error())
 			return
@@ -281,7 +276,7 @@ func (h *UserManagementHandler) UpdateUser(c *gin.Context) {
 		}
 
 		for _, role := range existingRoles {
-			if err := h.roleService.RemoveRoleFromUser(uid, role.ID, currentUserID); err != nil {
+			if err := h.roleService.RemoveRoleFromUser(c.Request.Context(), uid, role.ID, currentUserID); err != nil {
 				utils.ApiError(c, http.StatusInternalServerError, "Failed to remove existing role", err.Error())
 				return
 			}
@@ -295,7 +290,7 @@ func (h *UserManagementHandler) UpdateUser(c *gin.Context) {
 				return
 			}
 
-			if err := h.roleService.AssignRoleToUser(uid, role.ID, currentUserID); err != nil {
+			if err := h.roleService.AssignRoleToUser(c.Request.Context(), uid, role.ID, currentUserID); err != nil {
 				utils.ApiError(c, http.StatusInternalServerError, "Failed to assign role", err.Error())
 				return
 			}
@@ -348,11 +343,11 @@ func (h *UserManagementHandler) UpdateUserStatus(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ApiError(c, http.StatusBadRequest, "Invalid request data", err.Error())
+		utils.ApiBindError(c, err)
 		return
 	}
 
-	err = h.authService.UpdateUserStatus(uint(userID), req.IsActive)
+	err = h.authService.UpdateUserStatus(c.Request.Context(), uint(userID), req.IsActive)
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "Failed to update user status", err.Error())
 		return
@@ -398,7 +393,7 @@ func (h *UserManagementHandler) DeleteUser(c *gin.Context) {
 	}
 
 	// Delete user
-	err = h.authService.DeleteUser(uid)
+	err = h.authService.DeleteUser(c.Request.Context(), uid)
 	if err != nil {
 		utils.ApiError(c, http.StatusInternalServerError, "Failed to delete user", err.Error())
 		return
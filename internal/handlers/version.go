@@ -1,43 +1,42 @@
 package handlers
 
 import (
-	"net/http"
-	"runtime"
-
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/ciliverse/cilikube/pkg/version"
 	"github.com/gin-gonic/gin"
 )
 
-// These variables will be set at compile time via ldflags
-var (
-	Version   = "dev"
-	BuildTime = "unknown"
-	GitCommit = "unknown"
-)
+// activeClusterSource is the subset of the cluster manager used by VersionHandler
+type activeClusterSource interface {
+	GetActiveClusterID() string
+}
 
-// VersionInfo version information structure
+// VersionInfo describes the running build and the deployment it serves
 type VersionInfo struct {
-	Version   string `json:"version"`
-	BuildTime string `json:"build_time"`
-	GitCommit string `json:"git_commit"`
-	GoVersion string `json:"go_version"`
-	Platform  string `json:"platform"`
-	Arch      string `json:"arch"`
+	version.Info
+	ActiveClusterID string `json:"active_cluster_id"`
+	StorageType     string `json:"storage_type"`
 }
 
-// GetVersion retrieves version information
-func GetVersion(c *gin.Context) {
-	versionInfo := VersionInfo{
-		Version:   Version,
-		BuildTime: BuildTime,
-		GitCommit: GitCommit,
-		GoVersion: runtime.Version(),
-		Platform:  runtime.GOOS,
-		Arch:      runtime.GOARCH,
-	}
+// VersionHandler handles build information requests
+type VersionHandler struct {
+	clusters    activeClusterSource
+	storageType string
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"data":    versionInfo,
-		"message": "success",
-	})
+// NewVersionHandler creates a new VersionHandler
+func NewVersionHandler(clusters activeClusterSource, storageType string) *VersionHandler {
+	return &VersionHandler{clusters: clusters, storageType: storageType}
+}
+
+// GetVersion handles GET /api/v1/version
+func (h *VersionHandler) GetVersion(c *gin.Context) {
+	info := VersionInfo{
+		Info:        version.Get(),
+		StorageType: h.storageType,
+	}
+	if h.clusters != nil {
+		info.ActiveClusterID = h.clusters.GetActiveClusterID()
+	}
+	utils.ApiSuccess(c, info, "successfully retrieved version information")
 }
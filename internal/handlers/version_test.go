@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/pkg/version"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubActiveCluster string
+
+func (s stubActiveCluster) GetActiveClusterID() string { return string(s) }
+
+func TestGetVersion(t *testing.T) {
+	oldVersion, oldCommit, oldDate := version.Version, version.GitCommit, version.BuildDate
+	defer func() { version.Version, version.GitCommit, version.BuildDate = oldVersion, oldCommit, oldDate }()
+	version.Version, version.GitCommit, version.BuildDate = "v0.5.0", "abc1234", "2026-10-16T00:00:00Z"
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/version", NewVersionHandler(stubActiveCluster("cls-1"), "database").GetVersion)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data map[string]string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "v0.5.0", resp.Data["version"])
+	assert.Equal(t, "abc1234", resp.Data["git_commit"])
+	assert.Equal(t, "2026-10-16T00:00:00Z", resp.Data["build_date"])
+	assert.NotEmpty(t, resp.Data["go_version"])
+	assert.Equal(t, "cls-1", resp.Data["active_cluster_id"])
+	assert.Equal(t, "database", resp.Data["storage_type"])
+}
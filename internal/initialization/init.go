@@ -9,7 +9,9 @@ import (
 	"github.com/ciliverse/cilikube/internal/routes"
 	"github.com/ciliverse/cilikube/internal/service"
 	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
 	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -19,23 +21,50 @@ func InitializeServices(k8sManager *k8s.ClusterManager, store store.Store, cfg *
 	resourceFactory := service.NewResourceServiceFactory()
 	resourceFactory.InitializeDefaultServices()
 	appServices := &service.AppServices{
-		ClusterService:     service.NewClusterService(k8sManager),
-		InstallerService:   service.NewInstallerService(cfg),
-		NodeMetricsService: service.NewNodeMetricsService(),
-		PodLogsService:     service.NewPodLogsService(),
-		SummaryService:     service.NewSummaryService(),
-		EventService:       service.NewEventService(k8sManager),
-		CRDService:         service.NewCRDService(),
-		AuthService:        service.NewAuthService(store, cfg),
-		OAuthService:       service.NewOAuthService(store, cfg),
-		RoleService:        service.NewRoleService(store),
-	}
-	// PodExecService requires rest.Config
-	if activeClient, err := k8sManager.GetActiveClient(); err == nil && activeClient != nil {
-		appServices.PodExecService = service.NewPodExecService(activeClient.Config)
-	} else {
-		appServices.PodExecService = nil // or can panic/log
+		ClusterService:      service.NewClusterService(k8sManager),
+		InstallerService:    service.NewInstallerService(cfg),
+		NodeMetricsService:  service.NewNodeMetricsService(),
+		PodLogsService:      service.NewPodLogsService(),
+		SummaryService:      service.NewSummaryService(),
+		EventService:        service.NewEventService(),
+		CRDService:          service.NewCRDService(),
+		SearchService:       service.NewSearchService(k8sManager.Informers()),
+		OverviewService:     service.NewOverviewService(k8sManager),
+		AuditService:        service.NewAuditService(store, cfg),
+		JanitorService:      service.NewJanitorService(store, cfg.Security.Cleanup),
+		InactiveUserService: service.NewInactiveUserService(store, cfg.Security.InactiveUsers),
+		AuthService:         service.NewAuthService(store, cfg),
+		OAuthService:        service.NewOAuthService(store, cfg),
+		RoleService:         service.NewRoleService(store),
+		PreferenceService:   service.NewPreferenceService(store),
+		TemplateService:     service.NewTemplateService(store),
+
+		DeploymentRolloutService:  service.NewDeploymentRolloutService(),
+		ServiceEndpointsService:   service.NewServiceEndpointsService(),
+		CustomResourceService:     service.NewCustomResourceService(),
+		DiffService:               service.NewDiffService(),
+		ManifestValidationService: service.NewManifestValidationService(),
+		BatchDeleteService:        service.NewBatchDeleteService(),
+		MetadataService:           service.NewMetadataService(),
+		ResourcePatchService:      service.NewResourcePatchService(),
+		PodEvictionService:        service.NewPodEvictionService(),
+		RelatedService:            service.NewRelatedService(k8sManager.Informers()),
+		DescribeService:           service.NewDescribeService(),
+		ContainerInfoService:      service.NewContainerInfoService(),
+		ImageScanService:          service.NewImageScanService(cfg.Security.ImageScan),
+		CapacityService:           service.NewCapacityService(k8sManager.Informers()),
+		NamespaceSummaryService:   service.NewNamespaceSummaryService(k8sManager.Informers()),
+		ExportService:             service.NewExportService(),
+		ImportService:             service.NewImportService(),
+		PodPortForwardService:     service.NewPodPortForwardService(),
+		TopService:                service.NewTopService(),
+		HelmService:               service.NewHelmService(),
+		KubeconfigService:         service.NewKubeconfigService(),
 	}
+	appServices.MonitoringService = service.NewMonitoringService(store, cfg, appServices.AuditService)
+	appServices.FavoriteService = service.NewFavoriteService(store, k8sManager, appServices.CustomResourceService)
+	// PodExecService uses the REST config of the cluster in each request, so it works without clusters at startup
+	appServices.PodExecService = service.NewPodExecService()
 	initializeResourceService(resourceFactory, "nodes", &appServices.NodeService)
 	initializeResourceService(resourceFactory, "pods", &appServices.PodService)
 	initializeResourceService(resourceFactory, "deployments", &appServices.DeploymentService)
@@ -46,7 +75,9 @@ func InitializeServices(k8sManager *k8s.ClusterManager, store store.Store, cfg *
 	initializeResourceService(resourceFactory, "secrets", &appServices.SecretService)
 	initializeResourceService(resourceFactory, "persistentvolumeclaims", &appServices.PVCService)
 	initializeResourceService(resourceFactory, "persistentvolumes", &appServices.PVService)
+	initializeResourceService(resourceFactory, "storageclasses", &appServices.StorageClassService)
 	initializeResourceService(resourceFactory, "statefulsets", &appServices.StatefulSetService)
+	initializeResourceService(resourceFactory, "endpointslices", &appServices.EndpointSliceService)
 	initializeResourceService(resourceFactory, "namespaces", &appServices.NamespaceService)
 	return appServices
 }
@@ -78,15 +109,90 @@ func InitializeHandlers(router *gin.RouterGroup, services *service.AppServices,
 	routes.RegisterSummaryRoutes(router, handlers.NewSummaryHandler(services.SummaryService, k8sManager))
 
 	// --- Register event routes ---
-	routes.RegisterEventRoutes(router, handlers.NewEventHandler(services.EventService))
+	routes.RegisterEventRoutes(router, handlers.NewEventHandler(services.EventService, k8sManager))
 
 	// --- Register CRD routes ---
 	routes.SetupCRDRoutes(router, handlers.NewCRDHandler(services.CRDService, k8sManager))
+	routes.RegisterCustomResourceRoutes(router, handlers.NewCustomResourceHandler(services.CustomResourceService, services.CRDService, k8sManager))
+
+	// --- Register manifest diff routes ---
+	routes.RegisterDiffRoutes(router, handlers.NewDiffHandler(services.DiffService, services.CustomResourceService, k8sManager))
+
+	// --- Register manifest validation routes ---
+	routes.RegisterManifestValidationRoutes(router, handlers.NewManifestValidationHandler(services.ManifestValidationService, services.CustomResourceService, k8sManager))
+
+	// --- Register user preference routes ---
+	routes.RegisterPreferenceRoutes(router, handlers.NewPreferenceHandler(services.PreferenceService))
+
+	// --- Register favorite resource routes ---
+	routes.RegisterFavoriteRoutes(router, handlers.NewFavoriteHandler(services.FavoriteService, services.PermissionService))
+
+	// --- Register batch delete routes ---
+	routes.RegisterBatchDeleteRoutes(router, handlers.NewBatchDeleteHandler(services.BatchDeleteService, services.CustomResourceService, services.PermissionService, k8sManager))
+
+	// --- Register label and annotation patch routes ---
+	routes.RegisterMetadataRoutes(router, handlers.NewMetadataHandler(services.MetadataService, services.CustomResourceService, services.PermissionService, k8sManager))
+
+	// --- Register resource patch routes ---
+	routes.RegisterResourcePatchRoutes(router, handlers.NewResourcePatchHandler(services.ResourcePatchService, services.CustomResourceService, services.PermissionService, k8sManager))
+
+	// --- Register pod eviction routes ---
+	routes.RegisterPodEvictionRoutes(router, handlers.NewPodEvictionHandler(services.PodEvictionService, services.PermissionService, k8sManager))
+
+	// --- Register related resources routes ---
+	routes.RegisterRelatedRoutes(router, handlers.NewRelatedHandler(services.RelatedService, k8sManager))
+
+	// --- Register describe routes ---
+	routes.RegisterDescribeRoutes(router, handlers.NewDescribeHandler(services.DescribeService, services.CustomResourceService, k8sManager))
+
+	// --- Register workload container inspection routes ---
+	routes.RegisterContainerInfoRoutes(router, handlers.NewContainerInfoHandler(services.ContainerInfoService, k8sManager))
+
+	// --- Register pod image scan routes ---
+	routes.RegisterImageScanRoutes(router, handlers.NewImageScanHandler(services.ImageScanService, k8sManager))
+
+	// --- Register cluster capacity and node problem routes ---
+	routes.RegisterCapacityRoutes(router, handlers.NewCapacityHandler(services.CapacityService, k8sManager))
+
+	// --- Register namespace summary routes ---
+	routes.RegisterNamespaceSummaryRoutes(router, handlers.NewNamespaceSummaryHandler(services.NamespaceSummaryService, k8sManager))
+
+	// --- Register namespace export and import routes ---
+	routes.RegisterExportRoutes(router, handlers.NewExportHandler(services.ExportService, services.PermissionService, k8sManager))
+	routes.RegisterImportRoutes(router, handlers.NewImportHandler(services.ImportService, services.CustomResourceService, services.PermissionService, k8sManager))
+
+	// --- Register resource template routes ---
+	routes.RegisterTemplateRoutes(router, handlers.NewTemplateHandler(services.TemplateService, services.CustomResourceService, services.PermissionService, k8sManager))
+
+	// --- Register top pods and nodes routes ---
+	routes.RegisterTopRoutes(router, handlers.NewTopHandler(services.TopService, k8sManager))
+
+	// --- Register Helm routes ---
+	routes.RegisterHelmRoutes(router, handlers.NewHelmHandler(services.HelmService, k8sManager))
+
+	// --- Register audit report routes ---
+	routes.RegisterAuditRoutes(router, handlers.NewAuditHandler(services.AuditService))
+
+	// --- Register monitoring routes ---
+	routes.RegisterMonitoringRoutes(router, handlers.NewMonitoringHandler(services.MonitoringService))
+
+	// --- Register multi-cluster overview routes ---
+	routes.RegisterOverviewRoutes(router, handlers.NewOverviewHandler(services.OverviewService))
+
+	// --- Register service endpoints routes ---
+	routes.RegisterServiceEndpointsRoutes(router, handlers.NewServiceEndpointsHandler(services.ServiceEndpointsService, k8sManager))
+
+	// --- Register ServiceAccount kubeconfig routes ---
+	routes.RegisterKubeconfigRoutes(router, handlers.NewKubeconfigHandler(services.KubeconfigService, services.PermissionService, k8sManager))
+
+	// --- Register search routes ---
+	routes.RegisterSearchRoutes(router, handlers.NewSearchHandler(services.SearchService, services.PermissionService, k8sManager))
 
 	// --- 2. Create Handler instances for all resources ---
 	nodesHandler := handlers.NewResourceHandler(services.NodeService, k8sManager, "nodes")
 	pvHandler := handlers.NewResourceHandler(services.PVService, k8sManager, "persistentvolumes")
-	namespacesHandler := handlers.NewResourceHandler(services.NamespaceService, k8sManager, "namespaces")
+	storageClassHandler := handlers.NewResourceHandler(services.StorageClassService, k8sManager, "storageclasses")
+	namespacesHandler := handlers.NewNamespaceHandler(services.NamespaceService, services.PermissionService, k8sManager)
 	podsHandler := handlers.NewResourceHandler(services.PodService, k8sManager, "pods")
 	deploymentsHandler := handlers.NewResourceHandler(services.DeploymentService, k8sManager, "deployments")
 	servicesHandler := handlers.NewResourceHandler(services.ServiceService, k8sManager, "services")
@@ -96,11 +202,25 @@ func InitializeHandlers(router *gin.RouterGroup, services *service.AppServices,
 	secretsHandler := handlers.NewResourceHandler(services.SecretService, k8sManager, "secrets")
 	pvcHandler := handlers.NewResourceHandler(services.PVCService, k8sManager, "persistentvolumeclaims")
 	statefulsetsHandler := handlers.NewResourceHandler(services.StatefulSetService, k8sManager, "statefulsets")
+	endpointSlicesHandler := handlers.NewResourceHandler(services.EndpointSliceService, k8sManager, "endpointslices")
 	nodeMetricsHandler := handlers.NewNodeMetricsHandler(services.NodeMetricsService, k8sManager)
+	persistentVolumeHandler := handlers.NewPersistentVolumeHandler(k8sManager)
+	storageClassDefaultHandler := handlers.NewStorageClassHandler(k8sManager)
 
 	// Pod logs and terminal Handler
 	podLogsHandler := handlers.NewPodLogsHandler(services.PodLogsService, k8sManager)
 	podExecHandler := handlers.NewPodExecHandler(services.PodExecService, k8sManager)
+	podPortForwardHandler := handlers.NewPodPortForwardHandler(services.PodPortForwardService, services.PermissionService, k8sManager)
+
+	// Deployment rollout history and rollback Handler
+	deploymentRolloutHandler := handlers.NewDeploymentRolloutHandler(services.DeploymentRolloutService, k8sManager)
+
+	// DaemonSet rollout status and update strategy Handler
+	daemonSetHandler := handlers.NewDaemonSetHandler(k8sManager)
+	statefulSetHandler := handlers.NewStatefulSetHandler(k8sManager)
+
+	// Namespaces the caller may access, for UI dropdowns
+	routes.RegisterNamespaceRoutes(router, namespacesHandler)
 
 	// a. Cluster-scoped resources
 	nodesRoutes := router.Group("/nodes")
@@ -124,6 +244,8 @@ func InitializeHandlers(router *gin.RouterGroup, services *service.AppServices,
 	pvRoutes := router.Group("/persistentvolumes")
 	{
 		pvRoutes.GET("", pvHandler.List)
+		// Summary list including the bound/available/released phase of each volume
+		pvRoutes.GET("/summary", persistentVolumeHandler.ListItems)
 		pvRoutes.POST("", pvHandler.Create)
 		pvRoutes.GET("/:name", pvHandler.Get)
 		pvRoutes.PUT("/:name", pvHandler.Update)
@@ -131,15 +253,29 @@ func InitializeHandlers(router *gin.RouterGroup, services *service.AppServices,
 		pvRoutes.GET("/:name/watch", pvHandler.Watch)
 	}
 
+	storageClassRoutes := router.Group("/storageclasses")
+	{
+		storageClassRoutes.GET("", storageClassHandler.List)
+		storageClassRoutes.POST("", storageClassHandler.Create)
+		// Summary list flagging the default class
+		storageClassRoutes.GET("/summary", storageClassDefaultHandler.ListItems)
+		storageClassRoutes.GET("/:name", storageClassHandler.Get)
+		storageClassRoutes.PUT("/:name", storageClassHandler.Update)
+		storageClassRoutes.DELETE("/:name", storageClassHandler.Delete)
+		storageClassRoutes.GET("/:name/watch", storageClassHandler.Watch)
+		storageClassRoutes.POST("/:name/default", storageClassDefaultHandler.SetDefault)
+	}
+
 	podsTopLevelRoutes := router.Group("/pods")
 	{
-		podsTopLevelRoutes.GET("", podsHandler.List)
+		podsTopLevelRoutes.GET("", podsHandler.ListAcrossNamespaces)
 	}
 
 	// b. Namespace resources themselves, and all resources nested under them
 	namespacesRoutes := router.Group("/namespaces")
 	{
-		namespacesRoutes.GET("", namespacesHandler.List)
+		// Authenticated callers only see the namespaces they may access
+		namespacesRoutes.GET("", auth.OptionalAuthMiddleware(), namespacesHandler.List)
 		namespacesRoutes.POST("", namespacesHandler.Create)
 
 		// Operations for individual Namespace
@@ -159,12 +295,40 @@ func InitializeHandlers(router *gin.RouterGroup, services *service.AppServices,
 			registerResourceInNamespace(nsMemberRoutes, "secrets", secretsHandler)
 			registerResourceInNamespace(nsMemberRoutes, "persistentvolumeclaims", pvcHandler)
 			registerResourceInNamespace(nsMemberRoutes, "statefulsets", statefulsetsHandler)
+			registerResourceInNamespace(nsMemberRoutes, "endpointslices", endpointSlicesHandler)
 
 			// New: Pod logs and terminal routes
 			podsMemberRoutes := nsMemberRoutes.Group("/pods/:name")
 			{
-				podsMemberRoutes.GET("/logs", podLogsHandler.GetPodLogs)
-				podsMemberRoutes.GET("/exec", podExecHandler.ExecPod)
+				// Log follow, exec and port forward sessions stay open past the server write timeout
+				podsMemberRoutes.GET("/logs", utils.NoTimeout(), podLogsHandler.GetPodLogs)
+				podsMemberRoutes.GET("/exec", utils.NoTimeout(), podExecHandler.ExecPod)
+				// Port forwarding opens network access to the pod, so the caller must be authenticated and authorized
+				podsMemberRoutes.GET("/portforward", auth.JWTAuthMiddleware(), utils.NoTimeout(), podPortForwardHandler.PortForward)
+			}
+
+			// Deployment rollout history and rollback routes
+			deploymentsMemberRoutes := nsMemberRoutes.Group("/deployments/:name")
+			{
+				deploymentsMemberRoutes.GET("/revisions", deploymentRolloutHandler.ListRevisions)
+				deploymentsMemberRoutes.POST("/rollback", deploymentRolloutHandler.Rollback)
+			}
+
+			// DaemonSet rollout status and update strategy routes
+			// Summary list with the desired/current/ready/updated numbers of each DaemonSet
+			nsMemberRoutes.GET("/daemonsets/summary", daemonSetHandler.ListItems)
+			daemonSetsMemberRoutes := nsMemberRoutes.Group("/daemonsets/:name")
+			{
+				daemonSetsMemberRoutes.GET("/status", daemonSetHandler.GetStatus)
+				daemonSetsMemberRoutes.PUT("/strategy", daemonSetHandler.UpdateStrategy)
+			}
+
+			// StatefulSet rollout status and partitioned rollout routes
+			nsMemberRoutes.GET("/statefulsets/summary", statefulSetHandler.ListItems)
+			statefulSetsMemberRoutes := nsMemberRoutes.Group("/statefulsets/:name")
+			{
+				statefulSetsMemberRoutes.GET("/status", statefulSetHandler.GetStatus)
+				statefulSetsMemberRoutes.PUT("/partition", statefulSetHandler.SetPartition)
 			}
 		}
 	}
@@ -190,31 +354,61 @@ func registerResourceInNamespace[T runtime.Object](nsRouter *gin.RouterGroup, re
 	}
 }
 
+// registerDebugRoutes serves profiling to admins in debug mode or when enable_pprof is set
+func registerDebugRoutes(router gin.IRouter, server configs.ServerConfig) {
+	if server.PprofEnabled() {
+		routes.RegisterDebugRoutes(router, handlers.NewDebugHandler())
+	}
+}
+
+// trustProxies makes c.ClientIP() take the client IP from X-Forwarded-For only on requests coming from
+// one of the trusted proxies, gin would otherwise accept the header from any peer
+func trustProxies(router *gin.Engine, proxies []string) {
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		log.Printf("Warning: ignoring invalid server.trusted_proxies: %v", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+}
+
 // SetupRouter sets up and returns Gin engine
 func SetupRouter(cfg *configs.Config, services *service.AppServices, k8sManager *k8s.ClusterManager, e *casbin.Enforcer) *gin.Engine {
 	router := gin.New()
+	trustProxies(router, cfg.Server.TrustedProxies)
 	router.Use(gin.Recovery(), gin.Logger())
+	// Audit logs record the client, request and caller of the request they are written for
+	router.Use(handlers.RequestAuditContext())
+	router.Use(utils.SecurityHeaders(cfg.Server.SecurityHeaders))
+	router.Use(utils.Compression(cfg.Server.Compression.Level, cfg.Server.Compression.MinSize))
 
-	// Configure custom CORS middleware, allow all required headers
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	})
+	router.Use(utils.Cors(cfg.Server.CORS))
+	router.Use(utils.MaxBodySize(cfg.Server.MaxBodyBytes))
+	router.Use(utils.PaginationLimits(cfg.Server.Pagination))
 
 	// Serve static files for uploaded avatars
 	router.Static("/uploads", "./uploads")
 
+	// Publish the token verification keys when tokens are signed with RS256
+	if cfg.JWT.Algorithm == auth.AlgorithmRS256 {
+		router.GET("/.well-known/jwks.json", auth.JWKSHandler())
+	}
+
+	// Serve the OpenAPI spec and Swagger UI, off in release mode unless enabled explicitly
+	if cfg.Server.SwaggerEnabled() {
+		routes.RegisterSwaggerRoutes(router)
+	}
+
+	registerDebugRoutes(router, cfg.Server)
+
 	apiV1 := router.Group("/api/v1")
+	// Each request targets its own cluster instead of sharing the global active one
+	apiV1.Use(k8s.ClusterContext(k8sManager, handlers.PreferredCluster(services.PreferenceService)))
+	// Mutating Kubernetes operations are recorded in the audit log
+	apiV1.Use(handlers.ResourceAudit(services.AuditService))
+	// Lists and diffs naming no namespace start from the caller's default namespace
+	apiV1.Use(handlers.PreferredNamespaces(services.PreferenceService))
 	{
+		routes.RegisterVersionRoutes(apiV1, handlers.NewVersionHandler(k8sManager, cfg.GetStorageType()))
+		routes.RegisterPublicConfigRoutes(apiV1, handlers.NewPublicConfigHandler(cfg, cfg.GetStorageType()))
 		InitializeHandlers(apiV1, services, k8sManager)
 	}
 
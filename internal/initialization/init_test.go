@@ -0,0 +1,105 @@
+package initialization
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterDebugRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() { configs.GlobalConfig = previous })
+
+	adminToken, _, err := auth.GenerateToken(&models.User{ID: 1, Username: "admin", Role: "admin"})
+	require.NoError(t, err)
+	viewerToken, _, err := auth.GenerateToken(&models.User{ID: 2, Username: "viewer", Role: "viewer"})
+	require.NoError(t, err)
+
+	get := func(router *gin.Engine, path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("absent in release mode", func(t *testing.T) {
+		router := gin.New()
+		registerDebugRoutes(router, configs.ServerConfig{Mode: "release"})
+		for _, path := range []string{"/debug/pprof/", "/debug/vars", "/debug/runtime"} {
+			assert.Equal(t, http.StatusNotFound, get(router, path, adminToken), path)
+		}
+	})
+
+	t.Run("present when enabled", func(t *testing.T) {
+		router := gin.New()
+		registerDebugRoutes(router, configs.ServerConfig{Mode: "release", EnablePprof: true})
+		for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/debug/vars", "/debug/runtime"} {
+			assert.Equal(t, http.StatusOK, get(router, path, adminToken), path)
+		}
+		assert.Equal(t, http.StatusUnauthorized, get(router, "/debug/runtime", ""))
+		assert.Equal(t, http.StatusForbidden, get(router, "/debug/runtime", viewerToken), "profiling is admin only")
+	})
+
+	t.Run("present in debug mode", func(t *testing.T) {
+		router := gin.New()
+		registerDebugRoutes(router, configs.ServerConfig{Mode: "debug"})
+		assert.Equal(t, http.StatusOK, get(router, "/debug/pprof/", adminToken))
+	})
+}
+
+func TestTrustProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clientIP := func(proxies []string, remoteAddr, forwardedFor string) string {
+		router := gin.New()
+		trustProxies(router, proxies)
+		router.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	t.Run("no trusted proxies ignores X-Forwarded-For", func(t *testing.T) {
+		assert.Equal(t, "192.0.2.1", clientIP(nil, "192.0.2.1:40000", "203.0.113.7"))
+	})
+
+	t.Run("trusted load balancer", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.7", clientIP([]string{"10.0.0.0/8"}, "10.1.2.3:40000", "203.0.113.7"))
+		assert.Equal(t, "10.1.2.3", clientIP([]string{"10.0.0.0/8"}, "10.1.2.3:40000", ""), "direct requests keep the peer address")
+	})
+
+	t.Run("untrusted peer", func(t *testing.T) {
+		assert.Equal(t, "192.0.2.1", clientIP([]string{"10.0.0.0/8"}, "192.0.2.1:40000", "203.0.113.7"))
+	})
+
+	t.Run("proxy chain", func(t *testing.T) {
+		// The client forged the first entry, the trusted ingress and load balancer appended the others
+		proxies := []string{"10.0.0.0/8", "172.16.0.5"}
+		assert.Equal(t, "203.0.113.7", clientIP(proxies, "172.16.0.5:40000", "198.51.100.9, 203.0.113.7, 10.4.0.2"))
+		assert.Equal(t, "10.4.0.2", clientIP([]string{"172.16.0.5"}, "172.16.0.5:40000", "198.51.100.9, 203.0.113.7, 10.4.0.2"),
+			"resolution stops at the first untrusted hop")
+	})
+
+	t.Run("invalid configuration trusts none", func(t *testing.T) {
+		assert.Equal(t, "10.1.2.3", clientIP([]string{"10.0.0.0/8", "lb.internal"}, "10.1.2.3:40000", "203.0.113.7"))
+	})
+}
@@ -8,14 +8,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ciliverse/cilikube/pkg/version"
 	"github.com/fatih/color"
 )
 
-// Version allows injection via build parameters (go build -ldflags "-X 'github.com/ciliverse/cilikube/internal/initialization.Version=v0.3.1'")
-var Version = ""
-
 // DisplayServerInfo prints service startup information, including local/LAN addresses, mode, version, Go version, startup time, etc.
-func DisplayServerInfo(serverAddr, mode string) {
+// scheme is http, or https when TLS is served directly.
+func DisplayServerInfo(scheme, serverAddr, mode string) {
 	version := getVersion()
 	goVersion := runtime.Version()
 	buildTime := getBuildTime()
@@ -29,8 +28,8 @@ func DisplayServerInfo(serverAddr, mode string) {
 		startTime = time.Now().Format("2006-01-02 15:04:05")
 	}
 	color.Cyan("🚀 CiliKube Server is running!")
-	color.Green("   ➜  Local:       http://127.0.0.1%s", serverAddr)
-	color.Green("   ➜  Network:     http://%s%s", getLocalIP(), serverAddr)
+	color.Green("   ➜  Local:       %s://127.0.0.1%s", scheme, serverAddr)
+	color.Green("   ➜  Network:     %s://%s%s", scheme, getLocalIP(), serverAddr)
 	color.Yellow("  ➜  Mode:        %s", mode)
 	color.Magenta("  ➜  Version:     %s", version)
 	color.Cyan("   ➜  Go Version:   %s", goVersion)
@@ -47,8 +46,8 @@ func getVersion() string {
 	if v := os.Getenv("CILIKUBE_VERSION"); v != "" {
 		return v
 	}
-	if Version != "" {
-		return Version
+	if version.Version != "dev" {
+		return version.Version
 	}
 	data, err := os.ReadFile("VERSION")
 	if err == nil {
@@ -58,11 +57,12 @@ func getVersion() string {
 	return "v0.0.1"
 }
 
-// getBuildTime supports injecting build time via build parameters (go build -ldflags "-X 'github.com/ciliverse/cilikube/internal/initialization.BuildTime=2025-06-24T12:00:00Z'")
-var BuildTime = ""
-
+// getBuildTime returns the build date injected into the version package, or "" when not set
 func getBuildTime() string {
-	return BuildTime
+	if version.BuildDate == "unknown" {
+		return ""
+	}
+	return version.BuildDate
 }
 
 // getLocalIP gets the first non-loopback IPv4 address of the local machine, commonly used for LAN access
@@ -1,15 +1,20 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
-	"os"
+
+	"github.com/ciliverse/cilikube/configs"
 )
 
-// New initializes and returns a configured slog.Logger
-func New(level slog.Level) *slog.Logger {
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+// New initializes and returns a slog.Logger writing to w at level, as JSON lines or, with the text
+// format, as key=value lines
+func New(w io.Writer, level slog.Level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{
 		Level: level,
-	})
-	logger := slog.New(handler)
-	return logger
+	}
+	if format == configs.LogFormatText {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+	return slog.New(slog.NewJSONHandler(w, options))
 }
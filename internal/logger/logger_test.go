@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_WarnLevelSuppressesInfo(t *testing.T) {
+	var out bytes.Buffer
+	log := New(&out, slog.LevelWarn, configs.LogFormatJSON)
+
+	log.Debug("cache refreshed")
+	log.Info("request served", "status", 200)
+	assert.Empty(t, out.String(), "debug and info are below warn")
+
+	log.Warn("cluster unreachable", "cluster", "prod")
+	log.Error("shutdown failed")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry), "JSON lines for log aggregation")
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "cluster unreachable", entry["msg"])
+	assert.Equal(t, "prod", entry["cluster"])
+}
+
+func TestNew_TextFormat(t *testing.T) {
+	var out bytes.Buffer
+	New(&out, slog.LevelDebug, configs.LogFormatText).Debug("cache refreshed", "entries", 3)
+	assert.Contains(t, out.String(), `level=DEBUG msg="cache refreshed" entries=3`)
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// ResourcePercent is an amount of CPU and memory as a percentage of allocatable resources
+type ResourcePercent struct {
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
+}
+
+// CapacityUsage compares the allocatable resources of one or more nodes with the requests, limits and
+// usage of the pods running on them. Percentages are relative to Allocatable.
+type CapacityUsage struct {
+	Allocatable     ResourceAmount   `json:"allocatable"`
+	Requests        ResourceAmount   `json:"requests"`
+	Limits          ResourceAmount   `json:"limits"`
+	Usage           *ResourceAmount  `json:"usage,omitempty"` // Unset when metrics are not available
+	RequestsPercent ResourcePercent  `json:"requestsPercent"`
+	LimitsPercent   ResourcePercent  `json:"limitsPercent"`
+	UsagePercent    *ResourcePercent `json:"usagePercent,omitempty"`
+	Pods            int              `json:"pods"`
+}
+
+// NodeCapacity is the capacity report of a single node
+type NodeCapacity struct {
+	Name        string `json:"name"`
+	Ready       bool   `json:"ready"`
+	Schedulable bool   `json:"schedulable"` // Ready and not cordoned
+	CapacityUsage
+}
+
+// ClusterCapacity is the capacity report of a cluster. Total covers every node, Schedulable only the
+// nodes new pods can be placed on, which is the capacity actually available.
+type ClusterCapacity struct {
+	Nodes            int            `json:"nodes"`
+	SchedulableNodes int            `json:"schedulableNodes"`
+	Total            CapacityUsage  `json:"total"`
+	Schedulable      CapacityUsage  `json:"schedulable"`
+	PendingPods      int            `json:"pendingPods"` // Pods not bound to a node, not counted in the requests
+	MetricsError     string         `json:"metricsError,omitempty"`
+	NodeBreakdown    []NodeCapacity `json:"nodeBreakdown"`
+}
+
+// NodeConditionProblem is a node condition in a problematic state
+type NodeConditionProblem struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// NodeProblem is a node that is not ready, under pressure, without network or cordoned
+type NodeProblem struct {
+	Name       string                 `json:"name"`
+	Ready      bool                   `json:"ready"`
+	Cordoned   bool                   `json:"cordoned"`
+	Conditions []NodeConditionProblem `json:"conditions"`
+}
+
+// NodeProblemSummary lists the nodes of a cluster with problems; healthy nodes are only counted
+type NodeProblemSummary struct {
+	Nodes         int           `json:"nodes"`
+	ProblemNodes  int           `json:"problemNodes"`
+	CordonedNodes int           `json:"cordonedNodes"`
+	Problems      []NodeProblem `json:"problems"`
+}
@@ -9,6 +9,9 @@ type CreateClusterRequest struct {
 	Description    string `json:"description"`
 	Environment    string `json:"environment"`
 	Region         string `json:"region"`
+	// QPS and Burst override kubernetes.qps and kubernetes.burst for the cluster when positive
+	QPS   float32 `json:"qps" binding:"gte=0"`
+	Burst int     `json:"burst" binding:"gte=0"`
 }
 
 type UpdateClusterRequest struct {
@@ -20,6 +23,9 @@ type UpdateClusterRequest struct {
 	Status         string            `json:"status"`
 	Labels         map[string]string `json:"labels"`
 	KubeconfigData string            `json:"kubeconfigData,omitempty"`
+	// QPS and Burst replace the rate limits of the cluster when given, 0 restores the defaults
+	QPS   *float32 `json:"qps,omitempty" binding:"omitempty,gte=0"`
+	Burst *int     `json:"burst,omitempty" binding:"omitempty,gte=0"`
 }
 
 type ClusterResponse struct {
@@ -35,6 +41,9 @@ type ClusterResponse struct {
 	Labels      map[string]string `json:"labels"`
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
+	// CredentialExpiresAt is when the kubeconfig client certificate expires, for clusters authenticating with one
+	CredentialExpiresAt *time.Time `json:"credential_expires_at,omitempty"`
+	Warning             string     `json:"warning,omitempty"`
 }
 
 type ClusterListResponse struct {
@@ -45,4 +54,7 @@ type ClusterListResponse struct {
 	Status      string `json:"status"`
 	Source      string `json:"source"`
 	Environment string `json:"environment"`
+	// CredentialExpiresAt is when the kubeconfig client certificate expires, for clusters authenticating with one
+	CredentialExpiresAt *time.Time `json:"credential_expires_at,omitempty"`
+	Warning             string     `json:"warning,omitempty"`
 }
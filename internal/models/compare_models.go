@@ -0,0 +1,13 @@
+package models
+
+// CompareRequest names an object to compare between two clusters, such as a deployment in staging and prod
+type CompareRequest struct {
+	FromClusterID       string `json:"fromClusterId" binding:"required"`
+	ToClusterID         string `json:"toClusterId" binding:"required"`
+	Group               string `json:"group"` // Empty for the core group
+	Version             string `json:"version" binding:"required"`
+	Resource            string `json:"resource" binding:"required"` // Plural resource name, e.g. deployments
+	Namespace           string `json:"namespace"`                   // Empty for cluster-scoped resources
+	Name                string `json:"name" binding:"required"`
+	IncludeServerFields bool   `json:"includeServerFields"` // Also compare status and other server-managed fields
+}
@@ -0,0 +1,62 @@
+package models
+
+// Container env value sources
+const (
+	EnvSourceValue            = "value"
+	EnvSourceSecretKeyRef     = "secretKeyRef"
+	EnvSourceConfigMapKeyRef  = "configMapKeyRef"
+	EnvSourceFieldRef         = "fieldRef"
+	EnvSourceResourceFieldRef = "resourceFieldRef"
+	EnvSourceSecretRef        = "secretRef"
+	EnvSourceConfigMapRef     = "configMapRef"
+)
+
+// MaskedEnvValue replaces literal env values when masking is requested
+const MaskedEnvValue = "******"
+
+// ContainerEnvVar is an environment variable of a container and where its value comes from
+type ContainerEnvVar struct {
+	Name     string `json:"name"`
+	Source   string `json:"source"`          // value, secretKeyRef, configMapKeyRef, fieldRef or resourceFieldRef
+	Value    string `json:"value,omitempty"` // Literal value, MaskedEnvValue when masked
+	Masked   bool   `json:"masked,omitempty"`
+	Ref      string `json:"ref,omitempty"` // name/key of the referenced Secret or ConfigMap, or the referenced field
+	Optional bool   `json:"optional,omitempty"`
+	Secret   bool   `json:"secret"` // The value is read from a Secret
+}
+
+// ContainerEnvFrom is a Secret or ConfigMap whose keys are all imported as env variables
+type ContainerEnvFrom struct {
+	Source   string `json:"source"` // secretRef or configMapRef
+	Name     string `json:"name"`
+	Prefix   string `json:"prefix,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+	Secret   bool   `json:"secret"`
+}
+
+// ContainerResources are the resource requests and limits of a container
+type ContainerResources struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+// ContainerInfo describes the image, resources and env of a container
+type ContainerInfo struct {
+	Name            string             `json:"name"`
+	Init            bool               `json:"init,omitempty"`
+	Image           string             `json:"image"`
+	ImagePullPolicy string             `json:"imagePullPolicy,omitempty"`
+	Resources       ContainerResources `json:"resources"`
+	Env             []ContainerEnvVar  `json:"env"`
+	EnvFrom         []ContainerEnvFrom `json:"envFrom"`
+}
+
+// WorkloadContainersResponse lists the containers of a workload's pod template
+type WorkloadContainersResponse struct {
+	Kind             string          `json:"kind"`
+	Namespace        string          `json:"namespace"`
+	Name             string          `json:"name"`
+	ImagePullSecrets []string        `json:"imagePullSecrets,omitempty"`
+	Containers       []ContainerInfo `json:"containers"`
+	SecretRefs       int             `json:"secretRefs"` // Env variables and envFrom sources read from Secrets
+}
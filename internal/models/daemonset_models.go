@@ -0,0 +1,38 @@
+package models
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DaemonSetItem represents a DaemonSet with its rollout progress
+type DaemonSetItem struct {
+	Name                   string      `json:"name"`
+	Namespace              string      `json:"namespace"`
+	DesiredNumberScheduled int32       `json:"desiredNumberScheduled"` // Nodes that should run the daemon pod
+	CurrentNumberScheduled int32       `json:"currentNumberScheduled"` // Nodes running the daemon pod
+	NumberReady            int32       `json:"numberReady"`
+	UpdatedNumberScheduled int32       `json:"updatedNumberScheduled"` // Nodes running the pod of the current template
+	NumberAvailable        int32       `json:"numberAvailable"`
+	NumberUnavailable      int32       `json:"numberUnavailable"`
+	NumberMisscheduled     int32       `json:"numberMisscheduled"` // Nodes running the daemon pod that shouldn't
+	UpdateStrategy         string      `json:"updateStrategy"`     // RollingUpdate or OnDelete
+	MaxUnavailable         string      `json:"maxUnavailable,omitempty"`
+	MaxSurge               string      `json:"maxSurge,omitempty"`
+	RolloutComplete        bool        `json:"rolloutComplete"` // The current template is running and available on every node
+	CreatedAt              metav1.Time `json:"createdAt"`
+}
+
+// DaemonSetListResponse represents the response for DaemonSet list
+type DaemonSetListResponse struct {
+	Items []DaemonSetItem `json:"items"`
+	Total int             `json:"total"`
+}
+
+// UpdateDaemonSetStrategyRequest replaces the update strategy of a DaemonSet. The rolling update
+// limits are a number of nodes or a percentage such as "10%"; when omitted the API server defaults
+// apply (maxUnavailable 1, maxSurge 0).
+type UpdateDaemonSetStrategyRequest struct {
+	Type           string `json:"type" binding:"required,oneof=RollingUpdate OnDelete"`
+	MaxUnavailable string `json:"maxUnavailable"` // RollingUpdate only
+	MaxSurge       string `json:"maxSurge"`       // RollingUpdate only
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeploymentRevision represents a single rollout revision of a Deployment, backed by a ReplicaSet
+type DeploymentRevision struct {
+	Revision       int64             `json:"revision"`
+	ReplicaSetName string            `json:"replicaSetName"`
+	Replicas       int32             `json:"replicas"`
+	ReadyReplicas  int32             `json:"readyReplicas"`
+	Current        bool              `json:"current"`
+	ChangeCause    string            `json:"changeCause,omitempty"`
+	Images         []string          `json:"images"`
+	TemplateLabels map[string]string `json:"templateLabels,omitempty"`
+	CreatedAt      metav1.Time       `json:"createdAt"`
+}
+
+// DeploymentRevisionListResponse represents the response for Deployment revision history
+type DeploymentRevisionListResponse struct {
+	Items []DeploymentRevision `json:"items"`
+	Total int                  `json:"total"`
+}
+
+// DeploymentRollbackRequest represents the request to roll a Deployment back to a revision
+type DeploymentRollbackRequest struct {
+	Revision int64 `json:"revision" binding:"required,min=1"`
+}
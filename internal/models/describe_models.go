@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// DescribeOwner is an owner reference of a described object
+type DescribeOwner struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Controller bool   `json:"controller,omitempty"`
+}
+
+// DescribeMetadata is the metadata section of a description
+type DescribeMetadata struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	UID               string            `json:"uid"`
+	CreationTimestamp time.Time         `json:"creationTimestamp"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"` // Without the last applied configuration
+	OwnerReferences   []DescribeOwner   `json:"ownerReferences,omitempty"`
+}
+
+// DescribeCondition is a status condition of a described object
+type DescribeCondition struct {
+	Type               string     `json:"type"`
+	Status             string     `json:"status"`
+	Reason             string     `json:"reason,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	LastTransitionTime *time.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// DescribeContainerPort is a port exposed by a container
+type DescribeContainerPort struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int32  `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+}
+
+// DescribeVolumeMount is a volume mounted in a container
+type DescribeVolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	SubPath   string `json:"subPath,omitempty"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// DescribeContainer is a container of a described pod with its status, which is empty until the
+// container is created
+type DescribeContainer struct {
+	Name   string                  `json:"name"`
+	Init   bool                    `json:"init,omitempty"`
+	Image  string                  `json:"image"`
+	Ports  []DescribeContainerPort `json:"ports,omitempty"`
+	Mounts []DescribeVolumeMount   `json:"mounts,omitempty"`
+	ContainerStatusInfo
+}
+
+// DescribeVolume is a volume of a described pod and the object it comes from
+type DescribeVolume struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`             // Volume source, e.g. persistentVolumeClaim, configMap or emptyDir
+	Source string `json:"source,omitempty"` // Claim, ConfigMap or Secret name, or host path
+}
+
+// DescribePod is the pod section of a description
+type DescribePod struct {
+	Node           string              `json:"node,omitempty"`
+	Phase          string              `json:"phase"`
+	PodIP          string              `json:"podIP,omitempty"`
+	QOSClass       string              `json:"qosClass,omitempty"`
+	ServiceAccount string              `json:"serviceAccount,omitempty"`
+	StartTime      *time.Time          `json:"startTime,omitempty"`
+	Containers     []DescribeContainer `json:"containers"` // Init containers first
+	Volumes        []DescribeVolume    `json:"volumes"`
+}
+
+// ResourceDescription assembles what kubectl describe shows of an object in sections: its metadata, the
+// scalar fields and conditions of its status, the pod details for pods, and its events, oldest first
+type ResourceDescription struct {
+	Kind       string              `json:"kind"`
+	APIVersion string              `json:"apiVersion"`
+	Resource   string              `json:"resource"`
+	Metadata   DescribeMetadata    `json:"metadata"`
+	Status     map[string]string   `json:"status,omitempty"`
+	Conditions []DescribeCondition `json:"conditions"`
+	Pod        *DescribePod        `json:"pod,omitempty"`
+	Events     []ClusterEvent      `json:"events"`
+}
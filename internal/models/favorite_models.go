@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// AddFavoriteRequest bookmarks a Kubernetes resource for the caller
+type AddFavoriteRequest struct {
+	ClusterID string `json:"cluster_id" binding:"required,max=100"`
+	Namespace string `json:"namespace" binding:"max=63"`      // Empty for cluster-scoped resources
+	Kind      string `json:"kind" binding:"required,max=253"` // e.g. Deployment, deployments or Certificate.cert-manager.io
+	Name      string `json:"name" binding:"required,max=253"`
+}
+
+// FavoriteResponse describes one of the caller's favorite resources
+type FavoriteResponse struct {
+	ID        uint      `json:"id"`
+	ClusterID string    `json:"cluster_id"`
+	Namespace string    `json:"namespace,omitempty"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResolvedFavorite is a favorite with the current status of its object
+type ResolvedFavorite struct {
+	FavoriteResponse
+	Found        bool              `json:"found"`                   // False when the object no longer exists
+	Status       string            `json:"status,omitempty"`        // Summary such as "Running" or "2/3 ready"
+	Ready        *bool             `json:"ready,omitempty"`         // Set for objects reporting replicas, readiness or availability
+	StatusFields map[string]string `json:"status_fields,omitempty"` // Scalar fields of the object's status
+	Error        string            `json:"error,omitempty"`         // Why the status could not be read
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// HelmRelease represents a Helm 3 release as stored in the cluster
+type HelmRelease struct {
+	Name         string    `json:"name"`
+	Namespace    string    `json:"namespace"`
+	Revision     int       `json:"revision"`
+	Chart        string    `json:"chart"`
+	ChartVersion string    `json:"chartVersion"`
+	AppVersion   string    `json:"appVersion"`
+	Status       string    `json:"status"`
+	Description  string    `json:"description,omitempty"`
+	Updated      time.Time `json:"updated"`
+}
+
+// HelmReleaseListResponse represents the response for Helm release list
+type HelmReleaseListResponse struct {
+	Items []HelmRelease `json:"items"`
+	Total int           `json:"total"`
+}
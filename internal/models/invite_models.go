@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// CreateInviteRequest invites the owner of an email address to create an account
+type CreateInviteRequest struct {
+	Email   string `json:"email" binding:"required,email,max=100"`
+	RoleIDs []uint `json:"role_ids"` // Roles given to the account, the viewer role when empty
+}
+
+// InviteResponse describes an invite. The token is only returned when the invite is created.
+type InviteResponse struct {
+	ID         uint       `json:"id"`
+	Email      string     `json:"email"`
+	RoleIDs    []uint     `json:"role_ids"`
+	Status     string     `json:"status"` // pending, accepted or expired
+	InvitedBy  *uint      `json:"invited_by,omitempty"`
+	AcceptedBy *uint      `json:"accepted_by,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Token      string     `json:"token,omitempty"`
+}
+
+// AcceptInviteRequest creates the account of an invite, with the invited email
+type AcceptInviteRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"required,min=3,max=50,username"` // Letters, digits, '.', '_' and '-'
+	Password string `json:"password" binding:"required,min=6"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// ServiceAccountKubeconfigRequest represents the request for generating a ServiceAccount kubeconfig
+type ServiceAccountKubeconfigRequest struct {
+	// ExpirationSeconds is the requested token lifetime; the API server may shorten it
+	ExpirationSeconds int64 `json:"expirationSeconds" binding:"omitempty,min=600,max=31536000"`
+}
@@ -0,0 +1,116 @@
+package models
+
+import "time"
+
+// ResourceAmount is an amount of CPU and memory
+type ResourceAmount struct {
+	CPUMilli    int64  `json:"cpuMilli"`
+	MemoryBytes int64  `json:"memoryBytes"`
+	CPU         string `json:"cpu"`    // Formatted amount, e.g. "250m"
+	Memory      string `json:"memory"` // Formatted amount, e.g. "128Mi"
+}
+
+// QuotaResourceUsage is the usage of one resource limited by a ResourceQuota
+type QuotaResourceUsage struct {
+	Resource    string   `json:"resource"`
+	Hard        string   `json:"hard"`
+	Used        string   `json:"used"`
+	UsedPercent *float64 `json:"usedPercent,omitempty"` // Unset when the hard limit is zero
+}
+
+// ResourceQuotaSummary is the state of a ResourceQuota, sorted by resource name
+type ResourceQuotaSummary struct {
+	Name      string               `json:"name"`
+	Resources []QuotaResourceUsage `json:"resources"`
+}
+
+// NamespaceSummary aggregates the quotas, pod resources and workloads of a namespace
+type NamespaceSummary struct {
+	Namespace string                 `json:"namespace"`
+	Quotas    []ResourceQuotaSummary `json:"quotas"`
+	// Requests and Limits are summed from the containers of the pods that are not finished
+	Requests ResourceAmount `json:"requests"`
+	Limits   ResourceAmount `json:"limits"`
+	// Usage is the current usage reported by metrics-server, unset when metrics are not available
+	Usage        *ResourceAmount `json:"usage,omitempty"`
+	MetricsError string          `json:"metricsError,omitempty"`
+	// Workloads counts the objects of each workload kind, including finished pods
+	Workloads map[string]int `json:"workloads"`
+}
+
+// QuotaHeadroom is the CPU and memory a namespace can still request before reaching the tightest of its
+// ResourceQuotas. A resource is unset when no quota limits its requests.
+type QuotaHeadroom struct {
+	CPUMilli    *int64 `json:"cpuMilli,omitempty"`
+	MemoryBytes *int64 `json:"memoryBytes,omitempty"`
+	CPU         string `json:"cpu,omitempty"`
+	Memory      string `json:"memory,omitempty"`
+}
+
+// NamespaceUsage is the resource footprint of one namespace in a cluster-wide usage report
+type NamespaceUsage struct {
+	Namespace string `json:"namespace"`
+	// Pods and Requests only count the pods that are not finished
+	Pods     int             `json:"pods"`
+	Requests ResourceAmount  `json:"requests"`
+	Usage    *ResourceAmount `json:"usage,omitempty"` // Unset when metrics are not available
+	// QuotaHeadroom is unset when no ResourceQuota of the namespace limits CPU or memory requests
+	QuotaHeadroom *QuotaHeadroom `json:"quotaHeadroom,omitempty"`
+}
+
+// NamespaceUsageReport lists the footprint of every namespace of a cluster, sorted descending by SortBy
+type NamespaceUsageReport struct {
+	SortBy string `json:"sortBy"`
+	// Pods, Requests and Usage are the totals of all namespaces
+	Pods         int              `json:"pods"`
+	Requests     ResourceAmount   `json:"requests"`
+	Usage        *ResourceAmount  `json:"usage,omitempty"`
+	MetricsError string           `json:"metricsError,omitempty"`
+	Namespaces   []NamespaceUsage `json:"namespaces"`
+}
+
+// Reasons a pod is reported as unhealthy, besides the waiting reason of its containers
+const (
+	UnhealthyPodNotReady     = "NotReady"
+	UnhealthyPodHighRestarts = "HighRestarts"
+)
+
+// ContainerStatusInfo is the current state and the last termination of a container
+type ContainerStatusInfo struct {
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restartCount"`
+	State        string `json:"state,omitempty"`   // waiting, running or terminated
+	Reason       string `json:"reason,omitempty"`  // Reason of the current state, e.g. CrashLoopBackOff
+	Message      string `json:"message,omitempty"` // Message of the current state
+	// Last termination of the container, unset when it never restarted
+	LastExitCode *int32     `json:"lastExitCode,omitempty"`
+	LastReason   string     `json:"lastReason,omitempty"`
+	LastMessage  string     `json:"lastMessage,omitempty"`
+	LastFinished *time.Time `json:"lastFinished,omitempty"`
+}
+
+// UnhealthyContainer is the status of a container of an unhealthy pod that is not ready or has restarted
+type UnhealthyContainer struct {
+	Name string `json:"name"`
+	Init bool   `json:"init,omitempty"`
+	ContainerStatusInfo
+}
+
+// UnhealthyPod is a pod that is not ready, has a container in CrashLoopBackOff or ImagePullBackOff,
+// or restarted at least the restart threshold
+type UnhealthyPod struct {
+	Name         string               `json:"name"`
+	Phase        string               `json:"phase"`
+	Node         string               `json:"node,omitempty"`
+	Ready        bool                 `json:"ready"`
+	RestartCount int32                `json:"restartCount"` // Restarts of all its containers
+	Reasons      []string             `json:"reasons"`
+	Containers   []UnhealthyContainer `json:"containers"`
+}
+
+// UnhealthyPodReport lists the unhealthy pods of a namespace, most restarted first
+type UnhealthyPodReport struct {
+	Namespace        string         `json:"namespace"`
+	RestartThreshold int32          `json:"restartThreshold"`
+	Pods             []UnhealthyPod `json:"pods"`
+}
@@ -64,6 +64,11 @@ type LinkOAuthAccountRequest struct {
 	ExpiresAt      *time.Time `json:"expires_at"`
 }
 
+// OAuthCodeRequest request carrying an OAuth authorization code for a provider given in the path
+type OAuthCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
 // UnlinkOAuthAccountRequest request for unlinking OAuth account
 type UnlinkOAuthAccountRequest struct {
 	Provider string `json:"provider" binding:"required"`
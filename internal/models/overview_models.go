@@ -0,0 +1,28 @@
+package models
+
+// ResourceCounts represents aggregated resource counts of one or more clusters
+type ResourceCounts struct {
+	Nodes       int            `json:"nodes"`
+	Pods        int            `json:"pods"`
+	PodsByPhase map[string]int `json:"podsByPhase"`
+	Namespaces  int            `json:"namespaces"`
+	Deployments int            `json:"deployments"`
+}
+
+// ClusterOverview represents the resource counts of a single cluster
+type ClusterOverview struct {
+	ID     string          `json:"id"`
+	Name   string          `json:"name"`
+	Status string          `json:"status"`
+	Counts *ResourceCounts `json:"counts,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// MultiClusterOverviewResponse represents the aggregated overview of all registered clusters
+type MultiClusterOverviewResponse struct {
+	Clusters            []ClusterOverview `json:"clusters"`
+	Totals              ResourceCounts    `json:"totals"`
+	TotalClusters       int               `json:"totalClusters"`
+	ReachableClusters   int               `json:"reachableClusters"`
+	UnreachableClusters int               `json:"unreachableClusters"`
+}
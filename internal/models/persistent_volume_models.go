@@ -0,0 +1,24 @@
+package models
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PersistentVolumeItem represents the list view of a PersistentVolume
+type PersistentVolumeItem struct {
+	Name          string      `json:"name"`
+	Capacity      string      `json:"capacity"`
+	AccessModes   []string    `json:"accessModes"`
+	ReclaimPolicy string      `json:"reclaimPolicy"`
+	Phase         string      `json:"phase"` // Available, Bound, Released, Failed or Pending
+	StorageClass  string      `json:"storageClass,omitempty"`
+	Claim         string      `json:"claim,omitempty"` // namespace/name of the bound claim
+	VolumeSource  string      `json:"volumeSource"`
+	CreatedAt     metav1.Time `json:"createdAt"`
+}
+
+// PersistentVolumeListResponse represents the response for PersistentVolume list
+type PersistentVolumeListResponse struct {
+	Items []PersistentVolumeItem `json:"items"`
+	Total int                    `json:"total"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// UpdateUserPreferenceRequest sets the caller's preferences for a cluster. The request replaces
+// the stored preferences, an empty default namespace clears it. DefaultCluster makes the cluster the
+// one used for the caller's requests that name none, in place of any other cluster.
+type UpdateUserPreferenceRequest struct {
+	DefaultNamespace string                 `json:"default_namespace" binding:"max=63"`
+	DefaultCluster   bool                   `json:"default_cluster"`
+	Settings         map[string]interface{} `json:"settings"`
+}
+
+// UserPreferenceResponse represents the caller's preferences for a cluster
+type UserPreferenceResponse struct {
+	ClusterID        string                 `json:"cluster_id"`
+	DefaultNamespace string                 `json:"default_namespace"`
+	DefaultCluster   bool                   `json:"default_cluster"`
+	Settings         map[string]interface{} `json:"settings"`
+	UpdatedAt        *time.Time             `json:"updated_at,omitempty"`
+}
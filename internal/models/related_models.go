@@ -0,0 +1,12 @@
+package models
+
+// RelatedResource is a node of an owner-reference graph
+type RelatedResource struct {
+	Kind      string             `json:"kind"`
+	Namespace string             `json:"namespace"`
+	Name      string             `json:"name"`
+	UID       string             `json:"uid,omitempty"`
+	Missing   bool               `json:"missing,omitempty"` // Referenced as owner but not found in the cache
+	Owners    []*RelatedResource `json:"owners,omitempty"`  // Objects this object is owned by
+	Owned     []*RelatedResource `json:"owned,omitempty"`   // Objects owned by this object
+}
@@ -113,6 +113,34 @@ type RemoveRoleRequest struct {
 	RoleID uint `json:"role_id" binding:"required"`
 }
 
+// RoleUsersRequest request for assigning a role to, or removing it from, several users
+type RoleUsersRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1,max=500"`
+}
+
+// Outcomes of a bulk role change for one user
+const (
+	RoleUserAssigned  = "assigned"
+	RoleUserRemoved   = "removed"
+	RoleUserUnchanged = "unchanged" // The user already had, or already lacked, the role
+	RoleUserFailed    = "failed"
+)
+
+// RoleUserResult is the outcome of a bulk role change for one user
+type RoleUserResult struct {
+	UserID uint   `json:"user_id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RoleUsersResponse response for bulk role changes
+type RoleUsersResponse struct {
+	RoleID    uint             `json:"role_id"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []RoleUserResult `json:"results"`
+}
+
 // UserRoleResponse response for user role operations
 type UserRoleResponse struct {
 	UserID     uint           `json:"user_id"`
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// SearchRequest represents the query parameters for a cluster-wide resource search
+type SearchRequest struct {
+	Query    string   `form:"q" json:"q"`               // Name substring to match (case-insensitive)
+	Kinds    []string `form:"kinds" json:"kinds"`       // Kinds to search, e.g. pod, deployment, service
+	Page     int      `form:"page" json:"page"`         // Page number, starting from 1
+	PageSize int      `form:"pageSize" json:"pageSize"` // Number of results per page
+}
+
+// SearchResult represents a single object matched by a search
+type SearchResult struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Age       string    `json:"age"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SearchResponse represents the response for a cluster-wide resource search
+type SearchResponse struct {
+	Items     []SearchResult `json:"items"`
+	Total     int            `json:"total"`     // Number of matches, capped at the search limit
+	Page      int            `json:"page"`      // Current page number
+	PageSize  int            `json:"pageSize"`  // Number of results per page
+	Truncated bool           `json:"truncated"` // True when more objects matched than the cap allows
+}
@@ -0,0 +1,35 @@
+package models
+
+// EndpointTargetRef references the object (usually a Pod) backing an endpoint address
+type EndpointTargetRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// EndpointAddress represents a single backend address of a Service
+type EndpointAddress struct {
+	IP        string             `json:"ip"`
+	Hostname  string             `json:"hostname,omitempty"`
+	NodeName  string             `json:"nodeName,omitempty"`
+	Zone      string             `json:"zone,omitempty"`
+	TargetRef *EndpointTargetRef `json:"targetRef,omitempty"`
+	Source    string             `json:"source"` // Name of the EndpointSlice or Endpoints object
+}
+
+// EndpointPort represents a port exposed by Service endpoints
+type EndpointPort struct {
+	Name     string `json:"name,omitempty"`
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// ServiceEndpointsResponse represents the resolved backends of a Service
+type ServiceEndpointsResponse struct {
+	Service   string            `json:"service"`
+	Namespace string            `json:"namespace"`
+	Source    string            `json:"source"` // EndpointSlice or Endpoints
+	Ready     []EndpointAddress `json:"ready"`
+	NotReady  []EndpointAddress `json:"notReady"`
+	Ports     []EndpointPort    `json:"ports"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatefulSetItem represents a StatefulSet with its rollout progress
+type StatefulSetItem struct {
+	Name              string      `json:"name"`
+	Namespace         string      `json:"namespace"`
+	Replicas          int32       `json:"replicas"` // Desired replicas
+	ReadyReplicas     int32       `json:"readyReplicas"`
+	CurrentReplicas   int32       `json:"currentReplicas"` // Pods running the current revision
+	UpdatedReplicas   int32       `json:"updatedReplicas"` // Pods running the update revision
+	AvailableReplicas int32       `json:"availableReplicas"`
+	CurrentRevision   string      `json:"currentRevision"`
+	UpdateRevision    string      `json:"updateRevision"`
+	UpdateStrategy    string      `json:"updateStrategy"`      // RollingUpdate or OnDelete
+	Partition         *int32      `json:"partition,omitempty"` // Ordinals below it keep the current revision
+	RolloutComplete   bool        `json:"rolloutComplete"`     // Every pod above the partition runs the update revision
+	CreatedAt         metav1.Time `json:"createdAt"`
+}
+
+// StatefulSetListResponse represents the response for StatefulSet list
+type StatefulSetListResponse struct {
+	Items []StatefulSetItem `json:"items"`
+	Total int               `json:"total"`
+}
+
+// UpdateStatefulSetPartitionRequest sets the rolling update partition of a StatefulSet. Pods with an
+// ordinal greater than or equal to the partition are updated; 0 rolls out to every pod.
+type UpdateStatefulSetPartitionRequest struct {
+	Partition *int32 `json:"partition" binding:"required,min=0"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StorageClassItem represents the list view of a StorageClass
+type StorageClassItem struct {
+	Name                 string      `json:"name"`
+	Provisioner          string      `json:"provisioner"`
+	ReclaimPolicy        string      `json:"reclaimPolicy"`
+	VolumeBindingMode    string      `json:"volumeBindingMode"`
+	AllowVolumeExpansion bool        `json:"allowVolumeExpansion"`
+	IsDefault            bool        `json:"isDefault"`
+	CreatedAt            metav1.Time `json:"createdAt"`
+}
+
+// StorageClassListResponse represents the response for StorageClass list
+type StorageClassListResponse struct {
+	Items []StorageClassItem `json:"items"`
+	Total int                `json:"total"`
+}
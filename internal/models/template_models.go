@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// TemplateParameter declares a value a resource template is rendered with, used as {{ .name }} in its manifest
+type TemplateParameter struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Default     string `json:"default,omitempty"` // Used when an optional parameter is not given
+}
+
+// CreateResourceTemplateRequest stores a named template of Kubernetes manifests
+type CreateResourceTemplateRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	Parameters  []TemplateParameter `json:"parameters" binding:"dive"`
+	Manifest    string              `json:"manifest" binding:"required"` // Go template of one or more YAML documents
+}
+
+// ResourceTemplateResponse describes a resource template
+type ResourceTemplateResponse struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Parameters  []TemplateParameter `json:"parameters"`
+	Manifest    string              `json:"manifest"`
+	BuiltIn     bool                `json:"builtIn"` // Shipped with the server, cannot be replaced
+	CreatedAt   *time.Time          `json:"createdAt,omitempty"`
+}
+
+// InstantiateTemplateRequest renders a template with parameter values and applies the result into a namespace
+type InstantiateTemplateRequest struct {
+	Namespace string            `json:"namespace" binding:"required"`
+	Params    map[string]string `json:"params"`
+	DryRun    bool              `json:"dryRun"` // Validate the objects with the API server without persisting them
+}
@@ -22,6 +22,13 @@ type User struct {
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// PasswordExpired marks tokens that may only be used to change the password
+	PasswordExpired bool `json:"-" gorm:"-"`
+	// AuthMethod records in the token how the user logged in, empty for password
+	AuthMethod string `json:"-" gorm:"-"`
+	// SessionID records in the token the login session it belongs to, empty when sessions aren't tracked
+	SessionID string `json:"-" gorm:"-"`
 }
 
 //// UserRole user role association table
@@ -44,8 +51,8 @@ type LoginRequest struct {
 
 // LoginResponse returns jwt token after successful login
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=50"`
-	Email    string `json:"email" binding:"required,email"`
+	Username string `json:"username" binding:"required,min=3,max=50,username"` // Letters, digits, '.', '_' and '-'
+	Email    string `json:"email" binding:"required,email,max=100"`
 	Password string `json:"password" binding:"required,min=6"`
 }
 
@@ -55,7 +62,7 @@ type ChangePasswordRequest struct {
 }
 
 type UpdateProfileRequest struct {
-	Email       string `json:"email" binding:"required,email"`
+	Email       string `json:"email" binding:"required,email,max=100"`
 	DisplayName string `json:"display_name" binding:"max=100"`
 	AvatarURL   string `json:"avatar_url" binding:"max=10000"`
 }
@@ -77,6 +84,10 @@ type LoginResponse struct {
 	Token     string       `json:"token"`
 	ExpiresAt time.Time    `json:"expires_at"`
 	User      UserResponse `json:"user"`
+	// PasswordExpired means the token only allows changing the password until it is changed
+	PasswordExpired bool `json:"password_expired,omitempty"`
+	// MustChangePassword means the password was never changed since the account was bootstrapped
+	MustChangePassword bool `json:"must_change_password,omitempty"`
 }
 
 type TokenResponse struct {
@@ -195,6 +206,20 @@ type UserProfileResponse struct {
 	OAuthProviders []OAuthProviderInfo `json:"oauth_providers,omitempty"`
 }
 
+// WhoAmIResponse describes the authenticated caller, everything the frontend needs to bootstrap
+type WhoAmIResponse struct {
+	ID              uint       `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	DisplayName     string     `json:"display_name"`
+	Roles           []string   `json:"roles"`
+	Permissions     []string   `json:"permissions"`       // Effective permissions of all roles as "ACTION object"
+	ActiveClusterID string     `json:"active_cluster_id"` // Cluster requests of the caller go to by default, empty when none
+	AuthMethod      string     `json:"auth_method"`       // password, oauth, webhook or apikey
+	IssuedAt        *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"` // Set when authenticated by a token
+}
+
 // OAuthProviderInfo basic OAuth provider information for user profile
 type OAuthProviderInfo struct {
 	Provider       string     `json:"provider"`
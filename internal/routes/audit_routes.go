@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAuditRoutes registers the audit report routes for administrators
+func RegisterAuditRoutes(router *gin.RouterGroup, handler *handlers.AuditHandler) {
+	audit := router.Group("/audit")
+	audit.Use(auth.JWTAuthMiddleware(), auth.AdminRequiredMiddleware())
+	{
+		// Who changed which resources, for access reviews
+		audit.GET("/report/mutations", handler.GetMutationReport)
+	}
+}
@@ -17,6 +17,7 @@ func RegisterAuthRoutes(authGroup *gin.RouterGroup, authService *service.AuthSer
 	// Public routes (no authentication required)
 	authGroup.POST("/login", authHandler.Login)
 	authGroup.POST("/register", authHandler.Register)
+	authGroup.POST("/accept-invite", authHandler.AcceptInvite)
 
 	// OAuth routes (public)
 	oauth := authGroup.Group("/oauth")
@@ -33,12 +34,17 @@ func RegisterAuthRoutes(authGroup *gin.RouterGroup, authService *service.AuthSer
 		authenticated.GET("/profile/detailed", authHandler.GetDetailedProfile)
 		authenticated.PUT("/profile", authHandler.UpdateProfile)
 		authenticated.POST("/change-password", authHandler.ChangePassword)
+		authenticated.GET("/password/expiry", authHandler.GetPasswordExpiry)
 		authenticated.POST("/refresh", authHandler.RefreshToken)
 		authenticated.POST("/logout", authHandler.Logout)
+		authenticated.POST("/logout-all", authHandler.LogoutAll)
+		authenticated.GET("/sessions", authHandler.GetUserSessions)
 
 		// OAuth account management (authenticated)
 		authenticated.POST("/oauth/link", oauthHandler.LinkAccount)
 		authenticated.POST("/oauth/unlink", oauthHandler.UnlinkAccount)
+		authenticated.POST("/oauth/:provider/link", oauthHandler.LinkProvider)
+		authenticated.DELETE("/oauth/:provider", oauthHandler.UnlinkProvider)
 	}
 
 	// Admin-only routes
@@ -48,5 +54,8 @@ func RegisterAuthRoutes(authGroup *gin.RouterGroup, authService *service.AuthSer
 		admin.GET("/users", authHandler.GetUserList)
 		admin.PUT("/users/:id/status", authHandler.UpdateUserStatus)
 		admin.DELETE("/users/:id", authHandler.DeleteUser)
+		admin.POST("/invites", authHandler.CreateInvite)
+		admin.GET("/invites", authHandler.ListInvites)
+		admin.POST("/users/:id/unlock", authHandler.UnlockUser)
 	}
 }
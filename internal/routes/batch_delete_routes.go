@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterBatchDeleteRoutes registers the label selector batch delete route
+func RegisterBatchDeleteRoutes(router *gin.RouterGroup, handler *handlers.BatchDeleteHandler) {
+	// Bulk deletion is checked against the caller's permissions, so authentication is required
+	router.DELETE("/clusters/:id/namespaces/:namespace/:resource", auth.JWTAuthMiddleware(), handler.DeleteCollection)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCapacityRoutes registers the cluster capacity report and node problem summary routes
+func RegisterCapacityRoutes(router *gin.RouterGroup, handler *handlers.CapacityHandler) {
+	router.GET("/clusters/:id/capacity", handler.GetCapacity)
+	router.GET("/clusters/:id/nodes/problems", handler.GetNodeProblems)
+}
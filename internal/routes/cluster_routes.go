@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,10 +11,13 @@ func RegisterClusterRoutes(router *gin.RouterGroup, handler *handlers.ClusterHan
 	clusterRoutes := router.Group("/clusters")
 	{
 		clusterRoutes.GET("", handler.ListClusters)
-		clusterRoutes.POST("", handler.CreateCluster)
+		// Adding, changing and removing clusters is for administrators: a kubeconfig may run an exec plugin on the server
+		clusterRoutes.POST("", auth.JWTAuthMiddleware(), auth.AdminRequiredMiddleware(), handler.CreateCluster)
 		clusterRoutes.GET("/:id", handler.GetCluster)
-		clusterRoutes.PUT("/:id", handler.UpdateCluster)
-		clusterRoutes.DELETE("/:id", handler.DeleteCluster)
+		clusterRoutes.PUT("/:id", auth.JWTAuthMiddleware(), auth.AdminRequiredMiddleware(), handler.UpdateCluster)
+		clusterRoutes.DELETE("/:id", auth.JWTAuthMiddleware(), auth.AdminRequiredMiddleware(), handler.DeleteCluster)
+		// Rebuilds the cluster's client from its current kubeconfig, e.g. after a certificate renewal
+		clusterRoutes.POST("/:id/reload", auth.JWTAuthMiddleware(), auth.AdminRequiredMiddleware(), handler.ReloadCluster)
 
 		// Active cluster API
 		activeRoutes := clusterRoutes.Group("/active")
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterContainerInfoRoutes registers the container image and env inspection route
+func RegisterContainerInfoRoutes(router *gin.RouterGroup, handler *handlers.ContainerInfoHandler) {
+	router.GET("/clusters/:id/namespaces/:namespace/:resource/:name/containers", handler.GetContainers)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCustomResourceRoutes registers CRD discovery and generic custom resource routes
+func RegisterCustomResourceRoutes(router *gin.RouterGroup, handler *handlers.CustomResourceHandler) {
+	router.GET("/clusters/:id/crds", handler.ListCRDs)
+
+	apis := router.Group("/clusters/:id/apis/:group/:version")
+	{
+		// Namespaced custom resources
+		apis.GET("/namespaces/:namespace/:plural", handler.List)
+		apis.POST("/namespaces/:namespace/:plural", handler.Create)
+		apis.GET("/namespaces/:namespace/:plural/:name", handler.Get)
+		apis.PUT("/namespaces/:namespace/:plural/:name", handler.Update)
+		apis.DELETE("/namespaces/:namespace/:plural/:name", handler.Delete)
+
+		// Cluster-scoped custom resources
+		apis.GET("/:plural", handler.List)
+		apis.POST("/:plural", handler.Create)
+		apis.GET("/:plural/:name", handler.Get)
+		apis.PUT("/:plural/:name", handler.Update)
+		apis.DELETE("/:plural/:name", handler.Delete)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDebugRoutes registers the admin-only profiling and runtime statistics routes
+func RegisterDebugRoutes(router gin.IRouter, handler *handlers.DebugHandler) {
+	debug := router.Group("/debug")
+	debug.Use(auth.JWTAuthMiddleware(), auth.AdminRequiredMiddleware())
+	{
+		debug.GET("/pprof/*profile", handler.Pprof)
+		debug.POST("/pprof/*profile", handler.Pprof)
+		debug.GET("/vars", handler.Vars)
+		debug.GET("/runtime", handler.GetRuntimeStats)
+	}
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDescribeRoutes registers the kubectl describe style route
+func RegisterDescribeRoutes(router *gin.RouterGroup, handler *handlers.DescribeHandler) {
+	router.GET("/clusters/:id/namespaces/:namespace/:resource/:name/describe", handler.Describe)
+}
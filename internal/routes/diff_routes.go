@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDiffRoutes registers the manifest diff and cluster comparison routes
+func RegisterDiffRoutes(router *gin.RouterGroup, handler *handlers.DiffHandler) {
+	// Authenticated callers get their preferred namespace when the manifest and query name none
+	router.POST("/clusters/:id/diff", auth.OptionalAuthMiddleware(), handler.Diff)
+	router.POST("/compare", handler.Compare)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,4 +18,7 @@ func RegisterEventRoutes(router *gin.RouterGroup, handler *handlers.EventHandler
 		// Get events related to a specific object
 		eventRoutes.GET("/object/:kind/:name", handler.GetEventsByObject)
 	}
+
+	// Live stream of the events of a cluster
+	router.GET("/clusters/:id/events/stream", utils.NoTimeout(), handler.StreamClusterEvents)
 }
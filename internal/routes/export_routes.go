@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterExportRoutes registers the namespace export (backup) route
+func RegisterExportRoutes(router *gin.RouterGroup, handler *handlers.ExportHandler) {
+	// Exports can include secrets and are limited to readable kinds, so authentication is required
+	router.GET("/clusters/:id/namespaces/:namespace/export", auth.JWTAuthMiddleware(), handler.ExportNamespace)
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterFavoriteRoutes registers the routes managing the caller's favorite resources
+func RegisterFavoriteRoutes(router *gin.RouterGroup, handler *handlers.FavoriteHandler) {
+	favorites := router.Group("/favorites")
+	favorites.Use(auth.JWTAuthMiddleware())
+	{
+		favorites.GET("", handler.ListFavorites)
+		favorites.POST("", handler.AddFavorite)
+		favorites.GET("/resolved", handler.ResolveFavorites)
+		favorites.DELETE("/:id", handler.RemoveFavorite)
+	}
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterHelmRoutes registers Helm release routes
+func RegisterHelmRoutes(router *gin.RouterGroup, handler *handlers.HelmHandler) {
+	helm := router.Group("/clusters/:id/helm")
+	{
+		helm.GET("/releases", handler.ListReleases)
+	}
+}
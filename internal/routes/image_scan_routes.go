@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterImageScanRoutes registers the pod image scan route
+func RegisterImageScanRoutes(router *gin.RouterGroup, handler *handlers.ImageScanHandler) {
+	// Scanning uncached images can take longer than the server write timeout, up to the scan timeout
+	timeout := handler.Timeout()
+	router.GET("/clusters/:id/namespaces/:namespace/pods/:name/scan", auth.JWTAuthMiddleware(), utils.RouteTimeout(timeout, timeout), handler.ScanPod)
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterImportRoutes registers the namespace import (restore) route
+func RegisterImportRoutes(router *gin.RouterGroup, handler *handlers.ImportHandler) {
+	// Imported objects are limited to writable kinds, so authentication is required
+	router.POST("/clusters/:id/namespaces/:namespace/import", auth.JWTAuthMiddleware(), handler.ImportNamespace)
+}
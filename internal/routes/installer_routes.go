@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,6 +13,6 @@ func RegisterInstallerRoutes(router *gin.RouterGroup, installerHandler *handlers
 
 	installerRoutes := router.Group("/system") // Group under /system or choose another name
 	{
-		installerRoutes.GET("/install-minikube", installerHandler.StreamMinikubeInstallation)
+		installerRoutes.GET("/install-minikube", utils.NoTimeout(), installerHandler.StreamMinikubeInstallation)
 	}
 }
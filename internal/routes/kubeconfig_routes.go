@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterKubeconfigRoutes registers ServiceAccount kubeconfig routes
+func RegisterKubeconfigRoutes(router *gin.RouterGroup, handler *handlers.KubeconfigHandler) {
+	// Generated kubeconfigs grant cluster access, so the caller must be authenticated and authorized
+	router.POST("/clusters/:id/serviceaccounts/:namespace/:name/kubeconfig", auth.JWTAuthMiddleware(), handler.GenerateServiceAccountKubeconfig)
+}
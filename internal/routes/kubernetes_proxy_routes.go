@@ -4,11 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/utils"
 )
 
 func KubernetesProxyRoutes(router *gin.RouterGroup, handler *handlers.ProxyHandler) {
 	proxyGroup := router.Group("/proxy")
 	{
-		proxyGroup.Any("/*act", handler.Proxy)
+		// Proxied watches and WebSocket sessions are not cut off by the server timeouts
+		proxyGroup.Any("/*act", utils.StreamingNoTimeout(), handler.Proxy)
 	}
 }
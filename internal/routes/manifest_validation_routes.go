@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterManifestValidationRoutes registers the manifest validation (lint) route
+func RegisterManifestValidationRoutes(router *gin.RouterGroup, handler *handlers.ManifestValidationHandler) {
+	router.POST("/validate", handler.Validate)
+}
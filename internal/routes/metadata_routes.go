@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterMetadataRoutes registers the label and annotation patch route
+func RegisterMetadataRoutes(router *gin.RouterGroup, handler *handlers.MetadataHandler) {
+	// Patches are checked against the caller's permissions, so authentication is required
+	router.PATCH("/clusters/:id/namespaces/:namespace/:resource/:name/metadata", auth.JWTAuthMiddleware(), handler.PatchMetadata)
+}
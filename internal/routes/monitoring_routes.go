@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterMonitoringRoutes registers security and system monitoring routes
+func RegisterMonitoringRoutes(router *gin.RouterGroup, handler *handlers.MonitoringHandler) {
+	monitoring := router.Group("/monitoring")
+	monitoring.Use(auth.JWTAuthMiddleware(), auth.AdminRequiredMiddleware())
+	{
+		monitoring.GET("/metrics", handler.GetRealTimeMetrics)
+		monitoring.GET("/metrics/history", handler.GetMetricsHistory)
+		monitoring.GET("/health", handler.GetSystemHealth)
+		monitoring.GET("/dashboard", handler.GetDashboardData)
+		monitoring.GET("/security", handler.GetSecurityOverview)
+		monitoring.GET("/alerts", handler.GetAlerts)
+		monitoring.POST("/alerts/:id/acknowledge", handler.AcknowledgeAlert)
+		monitoring.POST("/alerts/:id/resolve", handler.ResolveAlert)
+		monitoring.GET("/stream", utils.NoTimeout(), handler.StreamUpdates)
+	}
+}
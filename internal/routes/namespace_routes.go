@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterNamespaceRoutes registers the accessible namespaces route
+func RegisterNamespaceRoutes(router *gin.RouterGroup, handler *handlers.NamespaceHandler) {
+	// The result depends on the caller's permissions, so authentication is required
+	router.GET("/clusters/:id/my-namespaces", auth.JWTAuthMiddleware(), handler.MyNamespaces)
+}
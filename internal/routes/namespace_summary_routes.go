@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterNamespaceSummaryRoutes registers the namespace quota, usage and workload summary route, the
+// unhealthy pods of a namespace and the cluster-wide namespace usage report
+func RegisterNamespaceSummaryRoutes(router *gin.RouterGroup, handler *handlers.NamespaceSummaryHandler) {
+	router.GET("/clusters/:id/namespaces/usage", handler.GetUsage)
+	router.GET("/clusters/:id/namespaces/:namespace/summary", handler.GetSummary)
+	router.GET("/clusters/:id/namespaces/:namespace/pods/unhealthy", handler.GetUnhealthyPods)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterOverviewRoutes registers multi-cluster overview routes
+func RegisterOverviewRoutes(router *gin.RouterGroup, handler *handlers.OverviewHandler) {
+	router.GET("/overview", handler.GetOverview)
+}
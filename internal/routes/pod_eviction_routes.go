@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPodEvictionRoutes registers the pod eviction route
+func RegisterPodEvictionRoutes(router *gin.RouterGroup, handler *handlers.PodEvictionHandler) {
+	// Evictions are checked against the caller's delete permission on pods, so authentication is required
+	router.POST("/clusters/:id/namespaces/:namespace/pods/:name/evict", auth.JWTAuthMiddleware(), handler.EvictPod)
+}
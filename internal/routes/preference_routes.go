@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPreferenceRoutes registers the routes managing the caller's per-cluster preferences
+func RegisterPreferenceRoutes(router *gin.RouterGroup, handler *handlers.PreferenceHandler) {
+	router.GET("/preferences", auth.JWTAuthMiddleware(), handler.ListPreferences)
+
+	preferences := router.Group("/clusters/:id/preferences")
+	preferences.Use(auth.JWTAuthMiddleware())
+	{
+		preferences.GET("", handler.GetPreference)
+		preferences.PUT("", handler.UpdatePreference)
+		preferences.DELETE("", handler.DeletePreference)
+	}
+}
@@ -34,4 +34,7 @@ func RegisterProfileRoutes(router *gin.RouterGroup, authService *service.AuthSer
 		// Activity log
 		profileRoutes.GET("/activity", profileHandler.GetActivityLog)
 	}
+
+	// Identity of the caller for the frontend to bootstrap from, next to the other auth routes
+	router.GET("/auth/whoami", auth.JWTAuthMiddleware(), profileHandler.WhoAmI)
 }
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPublicConfigRoutes registers the unauthenticated configuration route read by the web UI
+func RegisterPublicConfigRoutes(router *gin.RouterGroup, handler *handlers.PublicConfigHandler) {
+	router.GET("/config/public", handler.GetPublicConfig)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRelatedRoutes registers the owner-reference graph route
+func RegisterRelatedRoutes(router *gin.RouterGroup, handler *handlers.RelatedHandler) {
+	router.GET("/clusters/:id/namespaces/:namespace/:resource/:name/related", handler.GetRelated)
+}
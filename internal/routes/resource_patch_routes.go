@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterResourcePatchRoutes registers the JSON, merge and strategic merge patch route of namespaced resources
+func RegisterResourcePatchRoutes(router *gin.RouterGroup, handler *handlers.ResourcePatchHandler) {
+	// Patches are checked against the caller's permissions, so authentication is required
+	router.PATCH("/clusters/:id/namespaces/:namespace/:resource/:name", auth.JWTAuthMiddleware(), handler.Patch)
+}
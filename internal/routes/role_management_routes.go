@@ -34,6 +34,8 @@ func RegisterRoleManagementRoutes(router *gin.RouterGroup, roleService *service.
 		// Role-user relationship queries
 		roleRoutes.GET("/users/:userId", roleHandler.GetUserRoles)
 		roleRoutes.GET("/:id/users", roleHandler.GetRoleUsers)
+		roleRoutes.POST("/:id/users", auth.AdminRequiredMiddleware(), roleHandler.AssignRoleToUsers)
+		roleRoutes.DELETE("/:id/users", auth.AdminRequiredMiddleware(), roleHandler.RemoveRoleFromUsers)
 	}
 
 	// Permission management routes
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSearchRoutes registers cluster-wide resource search routes
+func RegisterSearchRoutes(router *gin.RouterGroup, handler *handlers.SearchHandler) {
+	// Search results are filtered by the caller's permissions, so authentication is required
+	router.GET("/clusters/:id/search", auth.JWTAuthMiddleware(), handler.Search)
+}
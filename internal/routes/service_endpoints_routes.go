@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterServiceEndpointsRoutes registers Service endpoints routes
+func RegisterServiceEndpointsRoutes(router *gin.RouterGroup, handler *handlers.ServiceEndpointsHandler) {
+	router.GET("/clusters/:id/namespaces/:namespace/services/:name/endpoints", handler.GetServiceEndpoints)
+}
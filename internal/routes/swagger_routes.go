@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/ciliverse/cilikube/internal/swagger"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSwaggerRoutes registers the OpenAPI spec and Swagger UI routes
+func RegisterSwaggerRoutes(router gin.IRouter) {
+	swaggerRoutes := router.Group("/swagger")
+	{
+		swaggerRoutes.GET("/*any", swagger.Handler())
+		swaggerRoutes.GET("", func(c *gin.Context) {
+			c.Redirect(http.StatusFound, utils.ExternalPath(c, "/swagger/index.html"))
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterTemplateRoutes registers the resource template routes
+func RegisterTemplateRoutes(router *gin.RouterGroup, handler *handlers.TemplateHandler) {
+	templates := router.Group("/templates")
+	templates.Use(auth.JWTAuthMiddleware())
+	{
+		templates.GET("", handler.ListTemplates)
+		templates.GET("/:name", handler.GetTemplate)
+		// Templates are shared by all users, so only administrators add them
+		templates.POST("", auth.AdminRequiredMiddleware(), handler.CreateTemplate)
+	}
+
+	// Created objects are limited to writable kinds, so authentication is required
+	router.POST("/clusters/:id/templates/:name/instantiate", auth.JWTAuthMiddleware(), handler.InstantiateTemplate)
+}
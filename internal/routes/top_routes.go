@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterTopRoutes registers the routes ranking pods and nodes by resource usage
+func RegisterTopRoutes(router *gin.RouterGroup, handler *handlers.TopHandler) {
+	top := router.Group("/clusters/:id/top")
+	{
+		top.GET("/pods", handler.TopPods)
+		top.GET("/nodes", handler.TopNodes)
+	}
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/ciliverse/cilikube/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterVersionRoutes registers build information routes
+func RegisterVersionRoutes(router *gin.RouterGroup, handler *handlers.VersionHandler) {
+	router.GET("/version", handler.GetVersion)
+}
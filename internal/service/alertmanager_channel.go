@@ -0,0 +1,185 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+)
+
+// Defaults used for unset Alertmanager settings
+const (
+	defaultAlertManagerTimeout   = 10 * time.Second
+	defaultAlertManagerQueueSize = 100
+)
+
+// AlertManagerAlert is an alert in the Alertmanager v2 API format, as posted to /api/v2/alerts
+type AlertManagerAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       *time.Time        `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// AlertManagerChannel posts alerts to a Prometheus Alertmanager. Repeats of an alert carry the same labels,
+// which Alertmanager identifies alerts by, so they update the alert there instead of adding another.
+// Alerts are delivered from a bounded queue in the background, so a slow or unavailable Alertmanager never
+// blocks raising or resolving an alert.
+type AlertManagerChannel struct {
+	url    string
+	client *http.Client
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan Alert
+	done   chan struct{}
+}
+
+// NewAlertManagerChannel creates the channel of the configured Alertmanager, nil when no url is configured
+func NewAlertManagerChannel(config configs.AlertManagerConfig) *AlertManagerChannel {
+	if config.URL == "" {
+		return nil
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultAlertManagerTimeout
+	}
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultAlertManagerQueueSize
+	}
+	c := &AlertManagerChannel{
+		url:    strings.TrimSuffix(config.URL, "/") + "/api/v2/alerts",
+		client: &http.Client{Timeout: timeout},
+		queue:  make(chan Alert, queueSize),
+		done:   make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// SendAlert queues an alert for delivery without blocking. It fails when the queue is full and the alert is dropped.
+func (c *AlertManagerChannel) SendAlert(alert Alert) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return fmt.Errorf("alertmanager channel is closed, dropping %s alert", alert.Type)
+	}
+	select {
+	case c.queue <- alert:
+		return nil
+	default:
+		return fmt.Errorf("alertmanager queue is full, dropping %s alert", alert.Type)
+	}
+}
+
+// Close stops accepting alerts and waits until the queued ones have been delivered
+func (c *AlertManagerChannel) Close() {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.mu.Unlock()
+	<-c.done
+}
+
+func (c *AlertManagerChannel) run() {
+	defer close(c.done)
+	for alert := range c.queue {
+		if err := c.deliver(alert); err != nil {
+			log.Printf("Warning: failed to deliver %s alert to Alertmanager: %v", alert.Type, err)
+		}
+	}
+}
+
+// deliver posts an alert to the Alertmanager
+func (c *AlertManagerChannel) deliver(alert Alert) error {
+	body, err := json.Marshal([]AlertManagerAlert{ToAlertManagerAlert(alert)})
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alertmanager responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *AlertManagerChannel) GetName() string {
+	return "alertmanager"
+}
+
+// ToAlertManagerAlert converts an alert to the Alertmanager format. The labels only hold what identifies
+// the alert, its stored ID included so an alert raised again after being resolved is a new one, while
+// details that change between repeats go in the annotations. A resolved alert ends when it was resolved.
+func ToAlertManagerAlert(alert Alert) AlertManagerAlert {
+	labels := map[string]string{
+		"alertname": alert.Type,
+		"severity":  alertManagerSeverity(alert.Level),
+		"level":     string(alert.Level),
+		"service":   "cilikube",
+	}
+	if alert.Source != "" {
+		labels["source"] = alert.Source
+	}
+	if alert.ID != "" {
+		labels["alert_id"] = alert.ID
+	}
+
+	annotations := map[string]string{
+		"summary":     alert.Title,
+		"description": alert.Description,
+	}
+	if alert.Count > 0 {
+		annotations["count"] = strconv.Itoa(alert.Count)
+	}
+
+	amAlert := AlertManagerAlert{
+		Labels:      labels,
+		Annotations: annotations,
+		StartsAt:    alert.Timestamp,
+	}
+	if alert.Resolved {
+		endsAt := time.Now()
+		if alert.ResolvedAt != nil {
+			endsAt = *alert.ResolvedAt
+		}
+		amAlert.EndsAt = &endsAt
+	}
+	return amAlert
+}
+
+// alertManagerSeverity maps an alert level to the severity label Alertmanager routes commonly match:
+// critical, warning or info
+func alertManagerSeverity(level AlertLevel) string {
+	switch level {
+	case AlertLevelCritical, AlertLevelError:
+		return "critical"
+	case AlertLevelInfo:
+		return "info"
+	default:
+		return "warning"
+	}
+}
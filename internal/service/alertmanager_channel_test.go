@@ -0,0 +1,168 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAlertManager records the alerts posted to /api/v2/alerts, decoded as generic JSON so the test checks
+// the wire format rather than the types used to encode it
+type fakeAlertManager struct {
+	mu     sync.Mutex
+	alerts []map[string]interface{}
+}
+
+func (f *fakeAlertManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/api/v2/alerts" || r.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var alerts []map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.alerts = append(f.alerts, alerts...)
+	f.mu.Unlock()
+}
+
+func (f *fakeAlertManager) received() []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]interface{}(nil), f.alerts...)
+}
+
+// assertAlertManagerSchema checks an alert against the postableAlert schema of the Alertmanager v2 API:
+// string labels and annotations, RFC3339 startsAt and endsAt, and no other fields
+func assertAlertManagerSchema(t *testing.T, alert map[string]interface{}) {
+	t.Helper()
+	for field, value := range alert {
+		switch field {
+		case "labels", "annotations":
+			set, ok := value.(map[string]interface{})
+			require.True(t, ok, "%s is an object", field)
+			for name, v := range set {
+				assert.IsType(t, "", v, "%s.%s is a string", field, name)
+			}
+		case "startsAt", "endsAt":
+			s, ok := value.(string)
+			require.True(t, ok, "%s is a string", field)
+			_, err := time.Parse(time.RFC3339, s)
+			assert.NoError(t, err, "%s is an RFC3339 time", field)
+		case "generatorURL":
+			assert.IsType(t, "", value)
+		default:
+			t.Errorf("unexpected field %s", field)
+		}
+	}
+	require.Contains(t, alert, "labels", "labels are required")
+}
+
+func TestAlertManagerChannel_SendAlert(t *testing.T) {
+	alertManager := &fakeAlertManager{}
+	server := httptest.NewServer(alertManager)
+	t.Cleanup(server.Close)
+
+	assert.Nil(t, NewAlertManagerChannel(configs.AlertManagerConfig{}), "disabled without url")
+	channel := NewAlertManagerChannel(configs.AlertManagerConfig{URL: server.URL + "/"})
+	raisedAt := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	require.NoError(t, channel.SendAlert(Alert{
+		ID: "12", Level: AlertLevelError, Type: "security_violations", Title: "Security Violations Detected",
+		Description: "6 violations", Source: "monitoring_service", Timestamp: raisedAt, Count: 3,
+	}))
+	channel.Close()
+	assert.Error(t, channel.SendAlert(Alert{Type: "x"}), "a closed channel drops alerts")
+
+	received := alertManager.received()
+	require.Len(t, received, 1)
+	assertAlertManagerSchema(t, received[0])
+	assert.Equal(t, map[string]interface{}{
+		"alertname": "security_violations",
+		"severity":  "critical",
+		"level":     "error",
+		"service":   "cilikube",
+		"source":    "monitoring_service",
+		"alert_id":  "12",
+	}, received[0]["labels"])
+	assert.Equal(t, map[string]interface{}{
+		"summary":     "Security Violations Detected",
+		"description": "6 violations",
+		"count":       "3",
+	}, received[0]["annotations"])
+	assert.Equal(t, raisedAt.Format(time.RFC3339), received[0]["startsAt"])
+	assert.NotContains(t, received[0], "endsAt", "an open alert doesn't end")
+
+	t.Run("a slow Alertmanager doesn't block senders", func(t *testing.T) {
+		started, release := make(chan struct{}, 1), make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		}))
+		t.Cleanup(slow.Close)
+		channel := NewAlertManagerChannel(configs.AlertManagerConfig{URL: slow.URL, QueueSize: 1})
+
+		require.NoError(t, channel.SendAlert(Alert{Type: "first"}))
+		<-started
+		assert.NoError(t, channel.SendAlert(Alert{Type: "second"}), "queued while the first is delivered")
+		assert.Error(t, channel.SendAlert(Alert{Type: "third"}), "dropped when the queue is full")
+		close(release)
+		channel.Close()
+	})
+}
+
+func TestAlertManagerSeverity(t *testing.T) {
+	assert.Equal(t, "info", alertManagerSeverity(AlertLevelInfo))
+	assert.Equal(t, "warning", alertManagerSeverity(AlertLevelWarning))
+	assert.Equal(t, "critical", alertManagerSeverity(AlertLevelError))
+	assert.Equal(t, "critical", alertManagerSeverity(AlertLevelCritical))
+}
+
+func TestMonitoringService_AlertManagerUpdatesRepeatedAlerts(t *testing.T) {
+	alertManager := &fakeAlertManager{}
+	server := httptest.NewServer(alertManager)
+	t.Cleanup(server.Close)
+
+	m := setupTestMonitoringService(t)
+	m.AddAlertChannel(NewAlertManagerChannel(configs.AlertManagerConfig{URL: server.URL}))
+	// receivedN waits for the background delivery of n alerts
+	receivedN := func(n int) []map[string]interface{} {
+		t.Helper()
+		require.Eventually(t, func() bool { return len(alertManager.received()) >= n }, 5*time.Second, 10*time.Millisecond)
+		return alertManager.received()
+	}
+
+	m.createAlert(AlertLevelWarning, "high_failed_logins", "High Failed Login Rate", "12 failed logins", nil)
+	m.createAlert(AlertLevelWarning, "high_failed_logins", "High Failed Login Rate", "15 failed logins", nil)
+
+	received := receivedN(2)
+	require.Len(t, received, 2)
+	assert.Equal(t, received[0]["labels"], received[1]["labels"], "a repeat has the labels of the alert it updates")
+	assert.Equal(t, "15 failed logins", received[1]["annotations"].(map[string]interface{})["description"])
+	assert.Equal(t, "2", received[1]["annotations"].(map[string]interface{})["count"])
+
+	alertID, err := strconv.ParseUint(received[0]["labels"].(map[string]interface{})["alert_id"].(string), 10, 64)
+	require.NoError(t, err)
+	_, err = m.ResolveAlert(uint(alertID), 1)
+	require.NoError(t, err)
+
+	received = receivedN(3)
+	require.Len(t, received, 3, "resolving closes the alert in Alertmanager")
+	assertAlertManagerSchema(t, received[2])
+	assert.Equal(t, received[0]["labels"], received[2]["labels"])
+	assert.Contains(t, received[2], "endsAt")
+
+	m.createAlert(AlertLevelWarning, "high_failed_logins", "High Failed Login Rate", "20 failed logins", nil)
+	received = receivedN(4)
+	require.Len(t, received, 4)
+	assert.NotEqual(t, received[0]["labels"], received[3]["labels"], "an alert raised again after being resolved is a new one")
+}
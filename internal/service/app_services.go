@@ -3,7 +3,9 @@ package service
 import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
 )
 
 // AppServices serves as a collection of all application services, defined here uniformly
@@ -15,6 +17,9 @@ type AppServices struct {
 	// [Added] Node metrics service
 	NodeMetricsService *NodeMetricsService
 
+	// Top pods and nodes by usage service
+	TopService *TopService
+
 	// [Added] Summary service
 	SummaryService *SummaryService
 
@@ -24,6 +29,81 @@ type AppServices struct {
 	// [Added] CRD service
 	CRDService CRDService
 
+	// Generic custom resource service
+	CustomResourceService *CustomResourceService
+
+	// Manifest diff service
+	DiffService *DiffService
+
+	// Offline manifest validation (lint) service
+	ManifestValidationService *ManifestValidationService
+
+	// Label selector batch delete service
+	BatchDeleteService *BatchDeleteService
+
+	// Label and annotation patch service
+	MetadataService *MetadataService
+
+	// JSON, merge and strategic merge patch service
+	ResourcePatchService *ResourcePatchService
+
+	// Owner-reference graph service
+	RelatedService *RelatedService
+
+	// kubectl describe style description service
+	DescribeService *DescribeService
+
+	// Workload container image and env inspection service
+	ContainerInfoService *ContainerInfoService
+
+	// Pod image vulnerability scan service
+	ImageScanService *ImageScanService
+
+	// Cluster allocatable, requested and used capacity service
+	CapacityService *CapacityService
+
+	// Namespace quota, usage and workload summary service
+	NamespaceSummaryService *NamespaceSummaryService
+
+	// Namespace export (backup) service
+	ExportService *ExportService
+
+	// Namespace import (restore) service
+	ImportService *ImportService
+
+	// Resource templates and creating resources from them
+	TemplateService *TemplateService
+
+	// Helm release service
+	HelmService *HelmService
+
+	// ServiceAccount kubeconfig service
+	KubeconfigService *KubeconfigService
+
+	// Cluster-wide resource search service
+	SearchService *SearchService
+
+	// Audit log of security events and Kubernetes resource changes
+	AuditService *AuditService
+
+	// Security and system monitoring service
+	MonitoringService *MonitoringService
+
+	// Background cleanup of expired sessions and old login attempts
+	JanitorService *JanitorService
+
+	// Opt-in deactivation of accounts unused for a configured number of days
+	InactiveUserService *InactiveUserService
+
+	// Multi-cluster overview service
+	OverviewService *OverviewService
+
+	// Per-cluster user preferences service
+	PreferenceService *PreferenceService
+
+	// Per-user favorite resources service
+	FavoriteService *FavoriteService
+
 	// Authentication and authorization services
 	AuthService       *AuthService
 	OAuthService      *OAuthService
@@ -31,20 +111,34 @@ type AppServices struct {
 	PermissionService *PermissionService
 
 	// Kubernetes resource services
-	NodeService        ResourceService[*corev1.Node]
-	NamespaceService   ResourceService[*corev1.Namespace]
-	PVService          ResourceService[*corev1.PersistentVolume]
-	PodService         ResourceService[*corev1.Pod]
-	DeploymentService  ResourceService[*appsv1.Deployment]
-	ServiceService     ResourceService[*corev1.Service]
-	DaemonSetService   ResourceService[*appsv1.DaemonSet]
-	IngressService     ResourceService[*networkingv1.Ingress]
-	ConfigMapService   ResourceService[*corev1.ConfigMap]
-	SecretService      ResourceService[*corev1.Secret]
-	PVCService         ResourceService[*corev1.PersistentVolumeClaim]
-	StatefulSetService ResourceService[*appsv1.StatefulSet]
+	NodeService          ResourceService[*corev1.Node]
+	NamespaceService     ResourceService[*corev1.Namespace]
+	PVService            ResourceService[*corev1.PersistentVolume]
+	StorageClassService  ResourceService[*storagev1.StorageClass]
+	PodService           ResourceService[*corev1.Pod]
+	DeploymentService    ResourceService[*appsv1.Deployment]
+	ServiceService       ResourceService[*corev1.Service]
+	DaemonSetService     ResourceService[*appsv1.DaemonSet]
+	IngressService       ResourceService[*networkingv1.Ingress]
+	ConfigMapService     ResourceService[*corev1.ConfigMap]
+	SecretService        ResourceService[*corev1.Secret]
+	PVCService           ResourceService[*corev1.PersistentVolumeClaim]
+	StatefulSetService   ResourceService[*appsv1.StatefulSet]
+	EndpointSliceService ResourceService[*discoveryv1.EndpointSlice]
 
 	// Pod logs and terminal services
 	PodLogsService *PodLogsService
 	PodExecService *PodExecService
+
+	// Pod port-forward service
+	PodPortForwardService *PodPortForwardService
+
+	// PodDisruptionBudget-aware pod eviction service
+	PodEvictionService *PodEvictionService
+
+	// Service endpoints resolution service
+	ServiceEndpointsService *ServiceEndpointsService
+
+	// Deployment rollout history and rollback service
+	DeploymentRolloutService *DeploymentRolloutService
 }
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ciliverse/cilikube/internal/store"
+)
+
+// AuditContext describes the request an audited operation is made in, so services record who made it and
+// from where without every caller passing it along
+type AuditContext struct {
+	UserID    *uint
+	Username  string
+	IPAddress string
+	UserAgent string
+	RequestID string
+	SessionID string
+}
+
+type auditContextKey struct{}
+
+// auditContextValue is the audit context attached to a request, with the details known only later
+type auditContextValue struct {
+	audit   AuditContext
+	resolve func(*AuditContext)
+}
+
+// WithAuditContext returns a copy of ctx carrying audit. resolve, which may be nil, completes the audit
+// context each time it is read, for details such as the caller that are only known once authentication
+// has run.
+func WithAuditContext(ctx context.Context, audit AuditContext, resolve func(*AuditContext)) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, &auditContextValue{audit: audit, resolve: resolve})
+}
+
+// AuditContextFrom returns the audit context attached to ctx, empty when there is none
+func AuditContextFrom(ctx context.Context) AuditContext {
+	if ctx == nil {
+		return AuditContext{}
+	}
+	value, ok := ctx.Value(auditContextKey{}).(*auditContextValue)
+	if !ok {
+		return AuditContext{}
+	}
+	audit := value.audit
+	if value.resolve != nil {
+		value.resolve(&audit)
+	}
+	return audit
+}
+
+// newAuditLog builds the audit log entry of an operation made in the request of ctx, recording its
+// client, request and session. userID defaults to the caller of the request.
+func newAuditLog(ctx context.Context, userID *uint, action, resource, resourceID, details string) *store.AuditLog {
+	audit := AuditContextFrom(ctx)
+	if userID == nil {
+		userID = audit.UserID
+	}
+	return &store.AuditLog{
+		UserID:     userID,
+		Action:     action,
+		Resource:   resource,
+		ResourceID: resourceID,
+		IPAddress:  audit.IPAddress,
+		UserAgent:  audit.UserAgent,
+		RequestID:  audit.RequestID,
+		SessionID:  audit.SessionID,
+		Details:    details,
+	}
+}
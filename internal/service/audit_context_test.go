@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestContext returns the context of a request from ipAddress with userAgent
+func requestContext(ipAddress, userAgent string) context.Context {
+	return WithAuditContext(context.Background(), AuditContext{IPAddress: ipAddress, UserAgent: userAgent}, nil)
+}
+
+func TestAuditContextFrom(t *testing.T) {
+	assert.Equal(t, AuditContext{}, AuditContextFrom(context.Background()))
+
+	callerID := uint(7)
+	authenticated := false
+	ctx := WithAuditContext(context.Background(), AuditContext{IPAddress: "203.0.113.9", RequestID: "req-1"}, func(audit *AuditContext) {
+		if authenticated {
+			audit.UserID = &callerID
+			audit.Username = "alice"
+		}
+	})
+	assert.Nil(t, AuditContextFrom(ctx).UserID, "the caller is not known yet")
+
+	authenticated = true
+	audit := AuditContextFrom(ctx)
+	require.NotNil(t, audit.UserID, "the caller is resolved when read")
+	assert.Equal(t, callerID, *audit.UserID)
+	assert.Equal(t, "alice", audit.Username)
+	assert.Equal(t, "203.0.113.9", audit.IPAddress)
+	assert.Equal(t, "req-1", audit.RequestID)
+}
+
+func TestRoleService_AuditLogFromContext(t *testing.T) {
+	svc, memoryStore, role, ids := newTestRoleService(t, "alice")
+	adminID := uint(1)
+	ctx := WithAuditContext(context.Background(), AuditContext{
+		UserID:    &adminID,
+		IPAddress: "203.0.113.9",
+		UserAgent: "Mozilla/5.0",
+		RequestID: "req-42",
+		SessionID: "sess-1",
+	}, nil)
+
+	_, err := svc.CreateRole(ctx, &models.CreateRoleRequest{Name: "auditor", DisplayName: "Auditor"})
+	require.NoError(t, err)
+	require.NoError(t, svc.AssignRoleToUser(ctx, ids[0], role.ID, adminID))
+
+	for _, action := range []string{"role_create", "role_assign"} {
+		logs, _, err := memoryStore.GetAuditLogsByAction(action, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, logs, 1, action)
+		entry := logs[0]
+		require.NotNil(t, entry.UserID, "%s is recorded for the caller", action)
+		assert.Equal(t, adminID, *entry.UserID)
+		assert.Equal(t, "203.0.113.9", entry.IPAddress, action)
+		assert.Equal(t, "Mozilla/5.0", entry.UserAgent, action)
+		assert.Equal(t, "req-42", entry.RequestID, action)
+		assert.Equal(t, "sess-1", entry.SessionID, action)
+	}
+}
+
+func TestAuthService_LoginAuditLogFromContext(t *testing.T) {
+	previousConfig := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() { configs.GlobalConfig = previousConfig })
+
+	authService, testStore := setupTestAuthService()
+	user := &store.User{Username: "audited", Email: "audited@example.com", IsActive: true}
+	require.NoError(t, user.HashPassword("password123"))
+	require.NoError(t, testStore.CreateUser(user))
+
+	ctx := WithAuditContext(context.Background(), AuditContext{IPAddress: "198.51.100.4", UserAgent: "cli/1.0", RequestID: "req-7"}, nil)
+	resp, err := authService.Login(ctx, &models.LoginRequest{Username: "audited", Password: "password123"})
+	require.NoError(t, err)
+
+	claims, err := auth.ParseToken(resp.Token)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.ID, "the token names its session")
+
+	logs, _, err := testStore.GetAuditLogsByAction("login", 0, 10)
+	require.NoError(t, err)
+	var sessionLogins int
+	for _, entry := range logs {
+		assert.Equal(t, "198.51.100.4", entry.IPAddress)
+		assert.Equal(t, "cli/1.0", entry.UserAgent)
+		if entry.RequestID == "req-7" && entry.SessionID == claims.ID {
+			sessionLogins++
+		}
+	}
+	assert.Equal(t, 1, sessionLogins, "the login is recorded in the session it started")
+}
+
+func TestAuditService_LogResourceAccessEventFromContext(t *testing.T) {
+	s, testStore := setupTestAuditService(t)
+	callerID := uint(3)
+	ctx := WithAuditContext(context.Background(), AuditContext{
+		UserID: &callerID, Username: "bob", IPAddress: "192.0.2.10", UserAgent: "kubectl", RequestID: "req-9",
+	}, nil)
+
+	require.NoError(t, s.LogResourceAccessEvent(ctx, "v1/configmaps", ResourceActionUpdate, true, map[string]interface{}{"name": "settings"}))
+
+	logs, _, err := testStore.GetAuditLogsByAction(string(EventTypeResourceUpdate), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.NotNil(t, logs[0].UserID)
+	assert.Equal(t, callerID, *logs[0].UserID)
+	assert.Equal(t, "192.0.2.10", logs[0].IPAddress)
+	assert.Equal(t, "kubectl", logs[0].UserAgent)
+	assert.Equal(t, "req-9", logs[0].RequestID)
+	var details map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(logs[0].Details), &details))
+	assert.Equal(t, "settings", details["name"])
+}
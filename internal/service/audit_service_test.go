@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestAuditService_GetMutationReport(t *testing.T) {
+	testStore := store.NewMemoryStore()
+	require.NoError(t, testStore.Initialize())
+	s := NewAuditService(testStore, nil)
+
+	aliceUser, bobUser := &store.User{Username: "alice", Email: "alice@example.com"}, &store.User{Username: "bob", Email: "bob@example.com"}
+	require.NoError(t, testStore.CreateUser(aliceUser))
+	require.NoError(t, testStore.CreateUser(bobUser))
+	aliceID, bobID := aliceUser.ID, bobUser.ID
+	alice := WithAuditContext(context.Background(), AuditContext{UserID: &aliceID, Username: "alice"}, nil)
+	bob := WithAuditContext(context.Background(), AuditContext{UserID: &bobID, Username: "bob"}, nil)
+	change := func(ctx context.Context, username, namespace, action string, err error) {
+		details := map[string]interface{}{"namespace": namespace, "name": "web", "username": username, "result": "success"}
+		if err != nil {
+			details["result"] = "failure"
+		}
+		require.NoError(t, s.LogResourceAccessEvent(ctx, "apps/v1/deployments", action, err == nil, details))
+	}
+
+	// A mutation before the period
+	change(bob, "bob", "prod", ResourceActionDelete, nil)
+	time.Sleep(10 * time.Millisecond)
+	periodStart := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	change(alice, "alice", "prod", ResourceActionUpdate, nil)
+	change(alice, "alice", "staging", ResourceActionUpdate, nil)
+	change(alice, "alice", "prod", ResourceActionUpdate, errors.New("conflict"))
+	change(alice, "alice", "prod", ResourceActionDelete, nil)
+	change(bob, "bob", "dev", ResourceActionCreate, nil)
+	change(bob, "bob", "dev", ResourceActionScale, nil)
+	change(bob, "bob", "dev", ResourceActionRestart, nil)
+	// Reads and authentication events are not mutations
+	require.NoError(t, s.LogResourceAccessEvent(bob, "v1/pods", "get", true, map[string]interface{}{"namespace": "dev", "username": "bob"}))
+	require.NoError(t, s.LogAuthenticationEvent(EventTypeLogin, &bobID, "bob", "203.0.113.7", "curl", true, nil))
+
+	report, err := s.GetMutationReport(periodStart, time.Now().Add(time.Hour), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 7, report.TotalMutations)
+	assert.Equal(t, 1, report.FailedMutations)
+	require.Len(t, report.Users, 2)
+
+	first := report.Users[0]
+	require.NotNil(t, first.UserID)
+	assert.Equal(t, aliceID, *first.UserID)
+	assert.Equal(t, "alice", first.Username)
+	assert.Equal(t, 4, first.Total)
+	assert.Equal(t, 1, first.Failed)
+	assert.Equal(t, []string{"prod", "staging"}, first.Namespaces)
+	require.Len(t, first.Actions, 2)
+	assert.Equal(t, ActionMutationSummary{Action: "update", Count: 3, Failed: 1, Namespaces: []string{"prod", "staging"}}, *first.Actions[0])
+	assert.Equal(t, ActionMutationSummary{Action: "delete", Count: 1, Namespaces: []string{"prod"}}, *first.Actions[1])
+
+	second := report.Users[1]
+	assert.Equal(t, "bob", second.Username)
+	assert.Equal(t, 3, second.Total)
+	assert.Equal(t, []string{"dev"}, second.Namespaces)
+	require.Len(t, second.Actions, 3)
+	assert.Equal(t, []string{"create", "restart", "scale"},
+		[]string{second.Actions[0].Action, second.Actions[1].Action, second.Actions[2].Action}, "scaling and restarts are told apart")
+
+	// A report of one user, over a period including the earlier deletion
+	report, err = s.GetMutationReport(periodStart.Add(-time.Hour), time.Now().Add(time.Hour), &bobID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.TotalMutations)
+	require.Len(t, report.Users, 1)
+	assert.Equal(t, []string{"dev", "prod"}, report.Users[0].Namespaces)
+	require.Len(t, report.Users[0].Actions, 4)
+}
+
+func TestPatchAction(t *testing.T) {
+	tests := []struct {
+		name      string
+		patchType types.PatchType
+		patch     string
+		want      string
+	}{
+		{"merge scale", types.MergePatchType, `{"spec":{"replicas":3}}`, ResourceActionScale},
+		{"strategic restart", types.StrategicMergePatchType,
+			`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"2025-01-01T00:00:00Z"}}}}}`, ResourceActionRestart},
+		{"merge with more than replicas", types.MergePatchType, `{"spec":{"replicas":3,"paused":true}}`, ResourceActionPatch},
+		{"merge labels", types.MergePatchType, `{"metadata":{"labels":{"app":"web"}}}`, ResourceActionPatch},
+		{"json scale", types.JSONPatchType, `[{"op":"replace","path":"/spec/replicas","value":3}]`, ResourceActionScale},
+		{"json restart", types.JSONPatchType,
+			`[{"op":"add","path":"/spec/template/metadata/annotations/kubectl.kubernetes.io~1restartedAt","value":"now"}]`, ResourceActionRestart},
+		{"json restart with annotations", types.JSONPatchType,
+			`[{"op":"add","path":"/spec/template/metadata/annotations","value":{"kubectl.kubernetes.io/restartedAt":"now"}}]`, ResourceActionRestart},
+		{"json image", types.JSONPatchType, `[{"op":"replace","path":"/spec/replicas","value":3},{"op":"replace","path":"/spec/template/spec/containers/0/image","value":"nginx"}]`, ResourceActionPatch},
+		{"invalid", types.JSONPatchType, `{`, ResourceActionPatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PatchAction(tt.patchType, []byte(tt.patch)))
+		})
+	}
+}
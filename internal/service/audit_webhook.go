@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+)
+
+// AuditWebhookSignatureHeader carries the hex encoded HMAC-SHA256 of the request body, prefixed with "sha256="
+const AuditWebhookSignatureHeader = "X-Cilikube-Signature"
+
+// Defaults used for unset audit webhook settings
+const (
+	defaultAuditWebhookQueueSize = 1000
+	defaultAuditWebhookTimeout   = 10 * time.Second
+	defaultAuditWebhookBackoff   = time.Second
+)
+
+// AuditWebhookDispatcher delivers audit events to a webhook from a bounded queue in the background,
+// so a slow or unavailable receiver never blocks the request path
+type AuditWebhookDispatcher struct {
+	url        string
+	secret     []byte
+	maxRetries int
+	backoff    time.Duration
+	client     *http.Client
+
+	// mu guards closed, so events logged during shutdown are dropped rather than sent on the closed queue
+	mu     sync.RWMutex
+	closed bool
+	queue  chan SecurityEvent
+	done   chan struct{}
+}
+
+// NewAuditWebhookDispatcher creates a dispatcher and starts its delivery worker.
+// It returns nil without error when the webhook is disabled.
+func NewAuditWebhookDispatcher(config configs.AuditWebhookConfig) (*AuditWebhookDispatcher, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("audit webhook is enabled but no url is configured")
+	}
+
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultAuditWebhookQueueSize
+	}
+	// Zero disables retries, the first failed delivery drops the event
+	maxRetries := config.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultAuditWebhookTimeout
+	}
+
+	d := &AuditWebhookDispatcher{
+		url:        config.URL,
+		secret:     []byte(config.Secret),
+		maxRetries: maxRetries,
+		backoff:    defaultAuditWebhookBackoff,
+		client:     &http.Client{Timeout: timeout},
+		queue:      make(chan SecurityEvent, queueSize),
+		done:       make(chan struct{}),
+	}
+	go d.run()
+	return d, nil
+}
+
+// Enqueue queues an event for delivery without blocking. The event is dropped and logged when the queue is
+// full or the dispatcher is closed.
+func (d *AuditWebhookDispatcher) Enqueue(event SecurityEvent) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		log.Printf("Warning: audit webhook is closed, dropping %s event", event.Type)
+		return false
+	}
+	select {
+	case d.queue <- event:
+		return true
+	default:
+		log.Printf("Warning: audit webhook queue is full, dropping %s event", event.Type)
+		return false
+	}
+}
+
+// Close stops accepting events and waits until the queued events have been delivered
+func (d *AuditWebhookDispatcher) Close() {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	<-d.done
+}
+
+func (d *AuditWebhookDispatcher) run() {
+	defer close(d.done)
+	for event := range d.queue {
+		if err := d.deliver(event); err != nil {
+			log.Printf("Warning: failed to deliver %s audit event to webhook: %v", event.Type, err)
+		}
+	}
+}
+
+// deliver posts an event, retrying with exponential backoff on network errors and non-2xx responses
+func (d *AuditWebhookDispatcher) deliver(event SecurityEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	signature := SignAuditWebhookPayload(d.secret, body)
+
+	backoff := d.backoff
+	for attempt := 0; ; attempt++ {
+		err = d.post(body, signature)
+		if err == nil || attempt >= d.maxRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (d *AuditWebhookDispatcher) post(body []byte, signature string) error {
+	req, err := http.NewRequest("POST", d.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(AuditWebhookSignatureHeader, signature)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignAuditWebhookPayload returns the signature header value receivers use to verify a payload
+func SignAuditWebhookPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditWebhook_DeliversSignedEvents(t *testing.T) {
+	received := make(chan SecurityEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, SignAuditWebhookPayload([]byte("s3cret"), body), r.Header.Get(AuditWebhookSignatureHeader))
+
+		var event SecurityEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		received <- event
+	}))
+	defer server.Close()
+
+	testStore := store.NewMemoryStore()
+	require.NoError(t, testStore.Initialize())
+	config := &configs.Config{}
+	config.Security.AuditWebhook = configs.AuditWebhookConfig{Enabled: true, URL: server.URL, Secret: "s3cret"}
+	s := NewAuditService(testStore, config)
+	require.NotNil(t, s.webhook)
+	defer s.webhook.Close()
+
+	userID := uint(7)
+	require.NoError(t, s.LogAuthenticationEvent(EventTypeLogin, &userID, "alice", "203.0.113.7", "curl", true, map[string]interface{}{"method": "password"}))
+
+	select {
+	case event := <-received:
+		assert.Equal(t, string(EventTypeLogin), event.Type)
+		assert.Equal(t, "alice", event.Username)
+		assert.Equal(t, "203.0.113.7", event.IPAddress)
+		assert.Equal(t, "password", event.Details["method"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook did not receive the event")
+	}
+}
+
+func TestAuditWebhook_RetriesWithBackoff(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	d, err := NewAuditWebhookDispatcher(configs.AuditWebhookConfig{Enabled: true, URL: server.URL, MaxRetries: 2})
+	require.NoError(t, err)
+	d.backoff = time.Millisecond
+
+	require.NoError(t, d.deliver(SecurityEvent{Type: "login"}))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	atomic.StoreInt32(&attempts, -10)
+	assert.Error(t, d.deliver(SecurityEvent{Type: "login"}), "gives up after max retries")
+	assert.Equal(t, int32(-7), atomic.LoadInt32(&attempts))
+	d.Close()
+}
+
+func TestAuditWebhook_ZeroMaxRetriesDisablesRetries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	d, err := NewAuditWebhookDispatcher(configs.AuditWebhookConfig{Enabled: true, URL: server.URL})
+	require.NoError(t, err)
+	d.backoff = time.Millisecond
+
+	assert.Error(t, d.deliver(SecurityEvent{Type: "login"}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+	d.Close()
+}
+
+func TestAuditWebhook_DropsOnOverflow(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	d, err := NewAuditWebhookDispatcher(configs.AuditWebhookConfig{Enabled: true, URL: server.URL, QueueSize: 1})
+	require.NoError(t, err)
+
+	// The worker takes the first event and blocks on the server, the second fills the queue
+	assert.True(t, d.Enqueue(SecurityEvent{Type: "first"}))
+	require.Eventually(t, func() bool { return len(d.queue) == 0 }, 5*time.Second, time.Millisecond)
+	assert.True(t, d.Enqueue(SecurityEvent{Type: "second"}))
+	assert.False(t, d.Enqueue(SecurityEvent{Type: "third"}), "events are dropped when the queue is full")
+
+	close(release)
+	d.Close()
+}
+
+func TestAuditWebhook_DropsAfterClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	d, err := NewAuditWebhookDispatcher(configs.AuditWebhookConfig{Enabled: true, URL: server.URL})
+	require.NoError(t, err)
+	d.Close()
+	assert.False(t, d.Enqueue(SecurityEvent{Type: "late"}), "events logged after Close are dropped")
+	d.Close()
+}
+
+func TestAuditWebhook_DisabledByDefault(t *testing.T) {
+	d, err := NewAuditWebhookDispatcher(configs.AuditWebhookConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, d)
+
+	_, err = NewAuditWebhookDispatcher(configs.AuditWebhookConfig{Enabled: true})
+	assert.Error(t, err)
+
+	s, _ := setupTestAuditService(t)
+	assert.Nil(t, s.webhook)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+)
+
+// defaultInviteTTL is how long invites stay valid when security.invite_ttl is unset
+const defaultInviteTTL = 72 * time.Hour
+
+var (
+	// ErrInvalidInvite is returned for an invite token that wasn't signed by this server or names no invite
+	ErrInvalidInvite = errors.New("invalid invite token")
+	// ErrInviteExpired is returned when accepting an invite past its expiry
+	ErrInviteExpired = errors.New("invite has expired, ask an administrator for a new one")
+	// ErrInviteAccepted is returned when accepting an invite a second time
+	ErrInviteAccepted = errors.New("invite has already been accepted")
+)
+
+// CreateInvite creates a pending invite for email giving the roles of roleIDs, the viewer role when
+// empty, and returns it with the signed token the invitee accepts it with (admin function)
+func (s *AuthService) CreateInvite(ctx context.Context, email string, roleIDs []uint, invitedBy uint) (*models.InviteResponse, error) {
+	if s.config.JWT.SecretKey == "" {
+		return nil, errors.New("jwt.secret_key is required to sign invites")
+	}
+	if _, err := s.store.GetUserByEmail(email); err == nil {
+		return nil, errors.New("email already exists")
+	}
+	if len(roleIDs) == 0 {
+		viewer, err := s.store.GetRoleByName("viewer")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get viewer role: %w", err)
+		}
+		roleIDs = []uint{viewer.ID}
+	}
+	for _, roleID := range roleIDs {
+		if _, err := s.store.GetRoleByID(roleID); err != nil {
+			return nil, fmt.Errorf("%w: %d", ErrRoleNotFound, roleID)
+		}
+	}
+
+	ttl := s.config.Security.InviteTTL
+	if ttl <= 0 {
+		ttl = defaultInviteTTL
+	}
+	invite := &store.Invite{
+		Email:     email,
+		RoleIDs:   roleIDs,
+		Status:    store.InviteStatusPending,
+		InvitedBy: &invitedBy,
+		// Second precision, as the expiry is part of the token
+		ExpiresAt: time.Now().Add(ttl).Truncate(time.Second),
+	}
+	if err := s.store.CreateInvite(invite); err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	response := inviteResponse(invite, time.Now())
+	response.Token = s.inviteToken(invite)
+	s.createAuditLog(ctx, &invitedBy, "invite_create", "invite", fmt.Sprintf("%d", invite.ID), fmt.Sprintf("Invited %s", email))
+	return &response, nil
+}
+
+// ListInvites gets a paginated list of invites, newest first (admin function)
+func (s *AuthService) ListInvites(page, pageSize int) ([]models.InviteResponse, int64, error) {
+	invites, total, err := s.store.ListInvites((page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get invite list: %w", err)
+	}
+	now := time.Now()
+	responses := make([]models.InviteResponse, len(invites))
+	for i, invite := range invites {
+		responses[i] = inviteResponse(invite, now)
+	}
+	return responses, total, nil
+}
+
+// AcceptInvite creates the account of the invite named by the token, with the invited email and roles.
+// An invite can be accepted once and only before it expires.
+func (s *AuthService) AcceptInvite(ctx context.Context, req *models.AcceptInviteRequest) (*models.UserResponse, error) {
+	inviteID, expiresAt, err := s.parseInviteToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+	invite, err := s.store.GetInviteByID(inviteID)
+	if errors.Is(err, store.ErrInviteNotFound) {
+		return nil, ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, err
+	}
+	if invite.ExpiresAt.Unix() != expiresAt {
+		return nil, ErrInvalidInvite
+	}
+	switch invite.StatusAt(time.Now()) {
+	case store.InviteStatusAccepted:
+		return nil, ErrInviteAccepted
+	case store.InviteStatusExpired:
+		if invite.Status == store.InviteStatusPending {
+			invite.Status = store.InviteStatusExpired
+			if err := s.store.UpdateInvite(invite); err != nil {
+				log.Printf("Warning: failed to mark invite %d as expired: %v", invite.ID, err)
+			}
+		}
+		return nil, ErrInviteExpired
+	}
+
+	if err := s.validateNewUser(&models.RegisterRequest{Username: req.Username, Email: invite.Email, Password: req.Password}); err != nil {
+		return nil, err
+	}
+
+	storeUser := &store.User{
+		Username:     req.Username,
+		Email:        invite.Email,
+		PasswordHash: req.Password, // Will be hashed by store
+		DisplayName:  req.Username,
+		IsActive:     true,
+	}
+	// The invite is read again in the transaction so that it is accepted only once
+	err = s.store.Transaction(func(tx store.Store) error {
+		invite, err := tx.GetInviteByID(inviteID)
+		if err != nil {
+			return err
+		}
+		if invite.Status != store.InviteStatusPending {
+			return ErrInviteAccepted
+		}
+		if err := tx.CreateUser(storeUser); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		for _, roleID := range invite.RoleIDs {
+			if invite.InvitedBy != nil {
+				err = tx.AssignRoleBy(storeUser.ID, roleID, *invite.InvitedBy)
+			} else {
+				err = tx.AssignRole(storeUser.ID, roleID)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to assign role: %w", err)
+			}
+		}
+		acceptedAt := time.Now()
+		invite.Status, invite.AcceptedBy, invite.AcceptedAt = store.InviteStatusAccepted, &storeUser.ID, &acceptedAt
+		return tx.UpdateInvite(invite)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.permissionService != nil {
+		if err := s.permissionService.SyncUserRoles(storeUser.ID); err != nil {
+			log.Printf("Warning: failed to sync roles of user %s with Casbin: %v", storeUser.Username, err)
+		}
+	}
+	s.createAuditLog(ctx, &storeUser.ID, "invite_accept", "user", fmt.Sprintf("%d", storeUser.ID), fmt.Sprintf("New user registered with invite %d", inviteID))
+
+	user := s.convertStoreUserToModelsUser(storeUser)
+	response := user.ToResponse()
+	return &response, nil
+}
+
+// inviteToken returns the token of an invite: its ID and expiry, signed with the JWT secret key
+func (s *AuthService) inviteToken(invite *store.Invite) string {
+	payload := fmt.Sprintf("%d.%d", invite.ID, invite.ExpiresAt.Unix())
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(s.inviteSignature(payload))
+}
+
+// parseInviteToken checks the signature of an invite token and returns the invite ID and expiry it holds
+func (s *AuthService) parseInviteToken(token string) (uint, int64, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok || s.config.JWT.SecretKey == "" {
+		return 0, 0, ErrInvalidInvite
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return 0, 0, ErrInvalidInvite
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.inviteSignature(string(payload))) {
+		return 0, 0, ErrInvalidInvite
+	}
+
+	id, expiry, _ := strings.Cut(string(payload), ".")
+	inviteID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, 0, ErrInvalidInvite
+	}
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidInvite
+	}
+	return uint(inviteID), expiresAt, nil
+}
+
+// inviteSignature is the HMAC-SHA256 of an invite token payload, keyed apart from login tokens
+func (s *AuthService) inviteSignature(payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(s.config.JWT.SecretKey))
+	mac.Write([]byte("invite." + payload))
+	return mac.Sum(nil)
+}
+
+func inviteResponse(invite *store.Invite, now time.Time) models.InviteResponse {
+	return models.InviteResponse{
+		ID:         invite.ID,
+		Email:      invite.Email,
+		RoleIDs:    invite.RoleIDs,
+		Status:     invite.StatusAt(now),
+		InvitedBy:  invite.InvitedBy,
+		AcceptedBy: invite.AcceptedBy,
+		AcceptedAt: invite.AcceptedAt,
+		ExpiresAt:  invite.ExpiresAt,
+		CreatedAt:  invite.CreatedAt,
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestInvites(t *testing.T) (*AuthService, store.Store, *store.Role) {
+	t.Helper()
+	authService, testStore := setupTestAuthService()
+	authService.config.JWT.SecretKey = "invite-test-secret"
+	editor, err := testStore.GetRoleByName("editor")
+	require.NoError(t, err)
+	return authService, testStore, editor
+}
+
+func TestAuthService_AcceptInvite(t *testing.T) {
+	authService, testStore, editor := setupTestInvites(t)
+	ctx := context.Background()
+
+	invite, err := authService.CreateInvite(ctx, "carol@example.com", []uint{editor.ID}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, store.InviteStatusPending, invite.Status)
+	assert.WithinDuration(t, time.Now().Add(defaultInviteTTL), invite.ExpiresAt, time.Minute)
+	require.NotEmpty(t, invite.Token)
+
+	user, err := authService.AcceptInvite(ctx, &models.AcceptInviteRequest{Token: invite.Token, Username: "carol", Password: "k7#Rt9mQ2x"})
+	require.NoError(t, err)
+	assert.Equal(t, "carol@example.com", user.Email, "the account gets the invited email")
+
+	roles, err := testStore.GetUserRoles(user.ID)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "editor", roles[0].Name)
+
+	stored, err := testStore.GetInviteByID(invite.ID)
+	require.NoError(t, err)
+	assert.Equal(t, store.InviteStatusAccepted, stored.Status)
+	assert.Equal(t, &user.ID, stored.AcceptedBy)
+
+	t.Run("reuse is rejected", func(t *testing.T) {
+		_, err := authService.AcceptInvite(ctx, &models.AcceptInviteRequest{Token: invite.Token, Username: "mallory", Password: "k7#Rt9mQ2x"})
+		assert.ErrorIs(t, err, ErrInviteAccepted)
+		_, err = testStore.GetUserByUsername("mallory")
+		assert.Error(t, err, "no second account is created")
+	})
+
+	t.Run("viewer by default", func(t *testing.T) {
+		invite, err := authService.CreateInvite(ctx, "dave@example.com", nil, 1)
+		require.NoError(t, err)
+		viewer, err := testStore.GetRoleByName("viewer")
+		require.NoError(t, err)
+		assert.Equal(t, []uint{viewer.ID}, invite.RoleIDs)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := authService.CreateInvite(ctx, "carol@example.com", nil, 1)
+		assert.EqualError(t, err, "email already exists")
+		_, err = authService.CreateInvite(ctx, "erin@example.com", []uint{999}, 1)
+		assert.ErrorIs(t, err, ErrRoleNotFound)
+	})
+}
+
+func TestAuthService_AcceptInvite_Expired(t *testing.T) {
+	authService, testStore, editor := setupTestInvites(t)
+
+	invite := &store.Invite{
+		Email:     "carol@example.com",
+		RoleIDs:   store.UintList{editor.ID},
+		Status:    store.InviteStatusPending,
+		ExpiresAt: time.Now().Add(-time.Minute).Truncate(time.Second),
+	}
+	require.NoError(t, testStore.CreateInvite(invite))
+
+	_, err := authService.AcceptInvite(context.Background(), &models.AcceptInviteRequest{Token: authService.inviteToken(invite), Username: "carol", Password: "k7#Rt9mQ2x"})
+	assert.ErrorIs(t, err, ErrInviteExpired)
+	_, err = testStore.GetUserByUsername("carol")
+	assert.Error(t, err, "no account is created")
+
+	stored, err := testStore.GetInviteByID(invite.ID)
+	require.NoError(t, err)
+	assert.Equal(t, store.InviteStatusExpired, stored.Status, "the expiry is recorded")
+}
+
+func TestAuthService_AcceptInvite_InvalidToken(t *testing.T) {
+	authService, _, _ := setupTestInvites(t)
+	invite, err := authService.CreateInvite(context.Background(), "carol@example.com", nil, 1)
+	require.NoError(t, err)
+
+	payload, _, _ := strings.Cut(invite.Token, ".")
+	for _, token := range []string{"", "garbage", payload, payload + ".c2lnbmF0dXJl"} {
+		_, err := authService.AcceptInvite(context.Background(), &models.AcceptInviteRequest{Token: token, Username: "carol", Password: "k7#Rt9mQ2x"})
+		assert.ErrorIs(t, err, ErrInvalidInvite, token)
+	}
+
+	// A token signed with another key is rejected too
+	other, _ := setupTestAuthService()
+	other.config.JWT.SecretKey = "another-secret"
+	_, err = other.AcceptInvite(context.Background(), &models.AcceptInviteRequest{Token: invite.Token, Username: "carol", Password: "k7#Rt9mQ2x"})
+	assert.ErrorIs(t, err, ErrInvalidInvite)
+}
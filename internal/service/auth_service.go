@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -11,28 +12,61 @@ import (
 	"github.com/ciliverse/cilikube/pkg/auth"
 )
 
+// ErrUserNotFound is returned when an admin operation targets a user that does not exist
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrRegistrationClosed is returned by Register when security.allow_registration is off
+var ErrRegistrationClosed = errors.New("registration is closed, ask an administrator for an account")
+
 // AuthService provides authentication and user management functionality
 type AuthService struct {
 	store           store.Store
 	config          *configs.Config
 	securityService *SecurityService
 	auditService    *AuditService
+
+	authWebhook       *AuthWebhookClient // Set when logins are delegated to an external service
+	permissionService *PermissionService
 }
 
 // NewAuthService creates a new AuthService instance
 func NewAuthService(store store.Store, config *configs.Config) *AuthService {
 	securityService := NewSecurityService(store, config)
 	auditService := NewAuditService(store, config)
-	return &AuthService{
+	s := &AuthService{
 		store:           store,
 		config:          config,
 		securityService: securityService,
 		auditService:    auditService,
 	}
+	if config != nil {
+		s.authWebhook = NewAuthWebhookClient(config.AuthWebhook)
+	}
+	return s
+}
+
+// SetAuditService replaces the audit service created by NewAuthService, so the application logs
+// through a single audit service and webhook queue
+func (s *AuthService) SetAuditService(auditService *AuditService) {
+	s.auditService.Close()
+	s.auditService = auditService
+}
+
+// SetPermissionService sets the permission service syncing the roles given to webhook users with Casbin
+func (s *AuthService) SetPermissionService(permissionService *PermissionService) {
+	s.permissionService = permissionService
 }
 
-// Login authenticates a user with username/password and returns JWT token
-func (s *AuthService) Login(req *models.LoginRequest, ipAddress, userAgent string) (*models.LoginResponse, error) {
+// Login authenticates a user with username/password and returns JWT token.
+// The password is checked by the auth webhook instead of locally when one is configured, except for local accounts.
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+	if s.authWebhook != nil && !s.isLocalAccount(req.Username) {
+		return s.loginWithWebhook(ctx, req)
+	}
+
+	audit := AuditContextFrom(ctx)
+	ipAddress, userAgent := audit.IPAddress, audit.UserAgent
+
 	// Get user by username
 	storeUser, err := s.store.GetUserByUsername(req.Username)
 	if err != nil {
@@ -65,6 +99,15 @@ func (s *AuthService) Login(req *models.LoginRequest, ipAddress, userAgent strin
 		return nil, errors.New("invalid username or password")
 	}
 
+	return s.completeLogin(ctx, storeUser, auth.AuthMethodPassword, "")
+}
+
+// completeLogin records the successful login of an authenticated user, starts its session and issues its token.
+// primaryRole is the role put in the token, the first of the user's roles when empty.
+func (s *AuthService) completeLogin(ctx context.Context, storeUser *store.User, authMethod, primaryRole string) (*models.LoginResponse, error) {
+	audit := AuditContextFrom(ctx)
+	ipAddress, userAgent := audit.IPAddress, audit.UserAgent
+
 	// Record successful login
 	if err := s.securityService.RecordSuccessfulLogin(storeUser.ID, ipAddress, userAgent); err != nil {
 		fmt.Printf("Failed to record successful login: %v\n", err)
@@ -88,36 +131,57 @@ func (s *AuthService) Login(req *models.LoginRequest, ipAddress, userAgent strin
 	}
 
 	// Set primary role (for backward compatibility)
-	if len(roles) > 0 {
+	if primaryRole != "" {
+		user.Role = primaryRole
+	} else if len(roles) > 0 {
 		user.Role = roles[0].Name
 	} else {
 		user.Role = "viewer" // Default role
 	}
 
-	// Generate JWT token
-	token, expiresAt, err := auth.GenerateToken(&user)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+	// An expired password, or one that must be changed, still lets the user in, but the token only allows changing it.
+	// Passwords checked by the auth webhook are managed by the external service.
+	mustChangePassword := false
+	if authMethod == auth.AuthMethodPassword {
+		user.PasswordExpired = s.passwordChangeRequired(storeUser)
+		mustChangePassword = storeUser.MustChangePassword
 	}
+	user.AuthMethod = authMethod
 
 	// Create session if session management is enabled
 	sessionID, err := s.securityService.CreateSession(storeUser.ID, ipAddress, userAgent)
 	if err != nil {
 		fmt.Printf("Failed to create session: %v\n", err)
 	}
+	user.SessionID = sessionID
 
-	// Create audit log
-	s.createAuditLog(&storeUser.ID, "login", "user", fmt.Sprintf("%d", storeUser.ID), ipAddress, userAgent, fmt.Sprintf("User logged in successfully, session: %s", sessionID))
+	// Generate JWT token
+	token, expiresAt, err := auth.GenerateToken(&user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	// Create audit log, in the session just started
+	audit.SessionID = sessionID
+	s.createAuditLog(WithAuditContext(ctx, audit, nil), &storeUser.ID, "login", "user", fmt.Sprintf("%d", storeUser.ID), fmt.Sprintf("User logged in successfully, session: %s", sessionID))
 
 	return &models.LoginResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      user.ToResponse(),
+		Token:              token,
+		ExpiresAt:          expiresAt,
+		User:               user.ToResponse(),
+		PasswordExpired:    user.PasswordExpired,
+		MustChangePassword: mustChangePassword,
 	}, nil
 }
 
+// passwordChangeRequired reports whether the user's tokens may only be used to change the password,
+// because it expired or, as for the bootstrapped admin, must be changed before first use
+func (s *AuthService) passwordChangeRequired(user *store.User) bool {
+	return user.MustChangePassword || s.securityService.GetPasswordExpiry(user).Expired
+}
+
 // RefreshToken generates a new JWT token from a valid existing token
-func (s *AuthService) RefreshToken(tokenString string) (*models.TokenResponse, error) {
+func (s *AuthService) RefreshToken(ctx context.Context, tokenString string) (*models.TokenResponse, error) {
 	// Parse the existing token
 	claims, err := auth.ParseToken(tokenString)
 	if err != nil {
@@ -148,11 +212,19 @@ func (s *AuthService) RefreshToken(tokenString string) (*models.TokenResponse, e
 		return nil, fmt.Errorf("failed to get user roles: %w", err)
 	}
 
-	if len(roles) > 0 {
-		user.Role = roles[0].Name
-	} else {
-		user.Role = "viewer"
+	// The refreshed token keeps the role of the old one while the user still has it
+	user.Role = "viewer"
+	for i, role := range roles {
+		if i == 0 || role.Name == claims.Role {
+			user.Role = role.Name
+		}
 	}
+	if claims.AuthMethod != auth.AuthMethodWebhook {
+		user.PasswordExpired = s.passwordChangeRequired(storeUser)
+	}
+	// The refreshed token keeps the login method and session of the old one
+	user.AuthMethod = claims.AuthMethod
+	user.SessionID = claims.ID
 
 	// Generate new token
 	newToken, expiresAt, err := auth.GenerateToken(&user)
@@ -161,7 +233,7 @@ func (s *AuthService) RefreshToken(tokenString string) (*models.TokenResponse, e
 	}
 
 	// Create audit log
-	s.createAuditLog(&storeUser.ID, "token_refresh", "user", fmt.Sprintf("%d", storeUser.ID), "", "", "Token refreshed successfully")
+	s.createAuditLog(ctx, &storeUser.ID, "token_refresh", "user", fmt.Sprintf("%d", storeUser.ID), "Token refreshed successfully")
 
 	return &models.TokenResponse{
 		Token:     newToken,
@@ -170,31 +242,48 @@ func (s *AuthService) RefreshToken(tokenString string) (*models.TokenResponse, e
 }
 
 // Logout invalidates a user session (placeholder for future session management)
-func (s *AuthService) Logout(userID uint) error {
+func (s *AuthService) Logout(ctx context.Context, userID uint) error {
 	// Create audit log
-	s.createAuditLog(&userID, "logout", "user", fmt.Sprintf("%d", userID), "", "", "User logged out")
+	s.createAuditLog(ctx, &userID, "logout", "user", fmt.Sprintf("%d", userID), "User logged out")
 
 	// In the future, we could implement token blacklisting here
 	return nil
 }
 
-// Register creates a new user account
-func (s *AuthService) Register(req *models.RegisterRequest) (*models.UserResponse, error) {
-	// Validate password against security policy
-	if validationErrors := s.securityService.ValidatePassword(req.Password); len(validationErrors) > 0 {
-		return nil, fmt.Errorf("password validation failed: %s", validationErrors[0].Message)
+// LogoutAll ends every session of the user and revokes all tokens issued to it, including the caller's,
+// so other devices are logged out immediately. It returns the number of sessions terminated.
+func (s *AuthService) LogoutAll(ctx context.Context, userID uint) (int, error) {
+	storeUser, err := s.store.GetUserByID(userID)
+	if err != nil {
+		return 0, ErrUserNotFound
 	}
 
-	// Check if username already exists
-	_, err := s.store.GetUserByUsername(req.Username)
-	if err == nil {
-		return nil, errors.New("username already exists")
+	terminated := len(s.securityService.GetUserSessions(userID))
+	if err := s.securityService.InvalidateAllUserSessions(userID); err != nil {
+		return 0, fmt.Errorf("failed to invalidate sessions: %w", err)
 	}
+	auth.RevokeUserTokens(userID)
+
+	audit := AuditContextFrom(ctx)
+	s.auditService.LogAuthenticationEvent(EventTypeLogoutAll, &userID, storeUser.Username, audit.IPAddress, audit.UserAgent, true, map[string]interface{}{
+		"sessions_terminated": terminated,
+	})
+	return terminated, nil
+}
 
-	// Check if email already exists
-	_, err = s.store.GetUserByEmail(req.Email)
-	if err == nil {
-		return nil, errors.New("email already exists")
+// Register creates a new user account for a visitor signing up, unless registration is closed
+func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest) (*models.UserResponse, error) {
+	if !s.config.Security.RegistrationEnabled() {
+		return nil, ErrRegistrationClosed
+	}
+	return s.CreateUser(ctx, req)
+}
+
+// CreateUser creates a new user account with the viewer role. Administrators may create accounts even
+// when registration is closed.
+func (s *AuthService) CreateUser(ctx context.Context, req *models.RegisterRequest) (*models.UserResponse, error) {
+	if err := s.validateNewUser(req); err != nil {
+		return nil, err
 	}
 
 	// Create new store user
@@ -207,23 +296,28 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.UserRespons
 		EmailVerified: false,
 	}
 
-	// Create user in store
-	if err := s.store.CreateUser(storeUser); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
+	// Create the user with the default viewer role in one transaction
+	err := s.store.Transaction(func(tx store.Store) error {
+		if err := tx.CreateUser(storeUser); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
 
-	// Assign default viewer role
-	viewerRole, err := s.store.GetRoleByName("viewer")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get viewer role: %w", err)
-	}
+		viewerRole, err := tx.GetRoleByName("viewer")
+		if err != nil {
+			return fmt.Errorf("failed to get viewer role: %w", err)
+		}
 
-	if err := s.store.AssignRole(storeUser.ID, viewerRole.ID); err != nil {
-		return nil, fmt.Errorf("failed to assign default role: %w", err)
+		if err := tx.AssignRole(storeUser.ID, viewerRole.ID); err != nil {
+			return fmt.Errorf("failed to assign default role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Create audit log
-	s.createAuditLog(nil, "user_register", "user", fmt.Sprintf("%d", storeUser.ID), "", "", "New user registered")
+	s.createAuditLog(ctx, nil, "user_register", "user", fmt.Sprintf("%d", storeUser.ID), "New user registered")
 
 	// Convert to response
 	user := s.convertStoreUserToModelsUser(storeUser)
@@ -231,6 +325,21 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.UserRespons
 	return &response, nil
 }
 
+// validateNewUser checks the password of a new account against the security policy, and that its
+// username and email are not taken
+func (s *AuthService) validateNewUser(req *models.RegisterRequest) error {
+	if validationErrors := s.securityService.ValidatePassword(req.Password); len(validationErrors) > 0 {
+		return fmt.Errorf("password validation failed: %s", validationErrors[0].Message)
+	}
+	if _, err := s.store.GetUserByUsername(req.Username); err == nil {
+		return errors.New("username already exists")
+	}
+	if _, err := s.store.GetUserByEmail(req.Email); err == nil {
+		return errors.New("email already exists")
+	}
+	return nil
+}
+
 // GetProfile gets detailed user profile information
 func (s *AuthService) GetProfile(userID uint) (*models.UserProfileResponse, error) {
 	// Get user from store
@@ -311,7 +420,7 @@ func (s *AuthService) GetProfileLegacy(userID uint) (map[string]interface{}, err
 }
 
 // UpdateProfile updates user profile information
-func (s *AuthService) UpdateProfile(userID uint, req *models.UpdateProfileRequest) (*models.UserResponse, error) {
+func (s *AuthService) UpdateProfile(ctx context.Context, userID uint, req *models.UpdateProfileRequest) (*models.UserResponse, error) {
 	// Get user from store
 	storeUser, err := s.store.GetUserByID(userID)
 	if err != nil {
@@ -336,7 +445,7 @@ func (s *AuthService) UpdateProfile(userID uint, req *models.UpdateProfileReques
 	}
 
 	// Create audit log
-	s.createAuditLog(&userID, "profile_update", "user", fmt.Sprintf("%d", userID), "", "", "User profile updated")
+	s.createAuditLog(ctx, &userID, "profile_update", "user", fmt.Sprintf("%d", userID), "User profile updated")
 
 	// Convert and return response
 	user := s.convertStoreUserToModelsUser(storeUser)
@@ -345,7 +454,7 @@ func (s *AuthService) UpdateProfile(userID uint, req *models.UpdateProfileReques
 }
 
 // ChangePassword changes user password
-func (s *AuthService) ChangePassword(userID uint, req *models.ChangePasswordRequest) error {
+func (s *AuthService) ChangePassword(ctx context.Context, userID uint, req *models.ChangePasswordRequest) error {
 	// Get user from store
 	storeUser, err := s.store.GetUserByID(userID)
 	if err != nil {
@@ -366,6 +475,7 @@ func (s *AuthService) ChangePassword(userID uint, req *models.ChangePasswordRequ
 	if err := storeUser.HashPassword(req.NewPassword); err != nil {
 		return fmt.Errorf("failed to hash new password: %w", err)
 	}
+	storeUser.MustChangePassword = false
 
 	if err := s.store.UpdateUser(storeUser); err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
@@ -377,11 +487,20 @@ func (s *AuthService) ChangePassword(userID uint, req *models.ChangePasswordRequ
 	}
 
 	// Create audit log
-	s.createAuditLog(&userID, "password_change", "user", fmt.Sprintf("%d", userID), "", "", "User password changed")
+	s.createAuditLog(ctx, &userID, "password_change", "user", fmt.Sprintf("%d", userID), "User password changed")
 
 	return nil
 }
 
+// GetPasswordExpiry reports when the user's password expires
+func (s *AuthService) GetPasswordExpiry(userID uint) (*PasswordExpiryStatus, error) {
+	storeUser, err := s.store.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.securityService.GetPasswordExpiry(storeUser), nil
+}
+
 // GetUserList gets paginated user list (admin function)
 func (s *AuthService) GetUserList(page, pageSize int) ([]models.UserResponse, int64, error) {
 	offset := (page - 1) * pageSize
@@ -410,7 +529,7 @@ func (s *AuthService) GetUserList(page, pageSize int) ([]models.UserResponse, in
 }
 
 // UpdateUserStatus updates user active status (admin function)
-func (s *AuthService) UpdateUserStatus(userID uint, isActive bool) error {
+func (s *AuthService) UpdateUserStatus(ctx context.Context, userID uint, isActive bool) error {
 	storeUser, err := s.store.GetUserByID(userID)
 	if err != nil {
 		return errors.New("user not found")
@@ -420,25 +539,36 @@ func (s *AuthService) UpdateUserStatus(userID uint, isActive bool) error {
 	if err := s.store.UpdateUser(storeUser); err != nil {
 		return fmt.Errorf("failed to update user status: %w", err)
 	}
+	if !isActive {
+		s.securityService.RevokeUserAccess(userID)
+	}
 
 	// Create audit log
 	status := "activated"
 	if !isActive {
 		status = "deactivated"
 	}
-	s.createAuditLog(nil, "user_status_change", "user", fmt.Sprintf("%d", userID), "", "", fmt.Sprintf("User %s", status))
+	s.createAuditLog(ctx, nil, "user_status_change", "user", fmt.Sprintf("%d", userID), fmt.Sprintf("User %s", status))
 
 	return nil
 }
 
+// UnlockAccount clears the failed login lockout of a user (admin function)
+func (s *AuthService) UnlockAccount(ctx context.Context, userID uint) (*AccountLockStatus, error) {
+	if _, err := s.store.GetUserByID(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.securityService.UnlockAccount(ctx, userID)
+}
+
 // DeleteUser deletes a user (admin function)
-func (s *AuthService) DeleteUser(userID uint) error {
+func (s *AuthService) DeleteUser(ctx context.Context, userID uint) error {
 	if err := s.store.DeleteUser(userID); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
 	// Create audit log
-	s.createAuditLog(nil, "user_delete", "user", fmt.Sprintf("%d", userID), "", "", "User deleted")
+	s.createAuditLog(ctx, nil, "user_delete", "user", fmt.Sprintf("%d", userID), "User deleted")
 
 	return nil
 }
@@ -524,17 +654,9 @@ func (s *AuthService) ValidatePassword(password string) (*models.ValidatePasswor
 	return response, nil
 }
 
-// createAuditLog creates an audit log entry
-func (s *AuthService) createAuditLog(userID *uint, action, resource, resourceID, ipAddress, userAgent, details string) {
-	auditLog := &store.AuditLog{
-		UserID:     userID,
-		Action:     action,
-		Resource:   resource,
-		ResourceID: resourceID,
-		IPAddress:  ipAddress,
-		UserAgent:  userAgent,
-		Details:    details,
-	}
+// createAuditLog creates an audit log entry for the request in ctx
+func (s *AuthService) createAuditLog(ctx context.Context, userID *uint, action, resource, resourceID, details string) {
+	auditLog := newAuditLog(ctx, userID, action, resource, resourceID, details)
 
 	// Don't fail the main operation if audit logging fails
 	if err := s.store.CreateAuditLog(auditLog); err != nil {
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/ciliverse/cilikube/configs"
 	"github.com/ciliverse/cilikube/internal/models"
 	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -94,7 +96,7 @@ func TestAuthService_Register(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			response, err := authService.Register(tt.request)
+			response, err := authService.Register(context.Background(), tt.request)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -112,6 +114,36 @@ func TestAuthService_Register(t *testing.T) {
 	}
 }
 
+func TestAuthService_AllowRegistration(t *testing.T) {
+	open, closed := true, false
+	request := func(username string) *models.RegisterRequest {
+		return &models.RegisterRequest{Username: username, Email: username + "@example.com", Password: "k7#Rt9mQ2x"}
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		authService, _ := setupTestAuthService()
+		authService.config.Security.AllowRegistration = &open
+
+		user, err := authService.Register(context.Background(), request("visitor"))
+		require.NoError(t, err)
+		assert.Equal(t, "visitor", user.Username)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		authService, testStore := setupTestAuthService()
+		authService.config.Security.AllowRegistration = &closed
+
+		_, err := authService.Register(context.Background(), request("visitor"))
+		assert.ErrorIs(t, err, ErrRegistrationClosed)
+		_, err = testStore.GetUserByUsername("visitor")
+		assert.Error(t, err, "no account is created")
+
+		user, err := authService.CreateUser(context.Background(), request("colleague"))
+		require.NoError(t, err, "administrators still create accounts")
+		assert.Equal(t, "colleague", user.Username)
+	})
+}
+
 func TestAuthService_Login(t *testing.T) {
 	authService, testStore := setupTestAuthService()
 
@@ -177,7 +209,7 @@ func TestAuthService_Login(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			response, err := authService.Login(tt.request, tt.ipAddress, tt.userAgent)
+			response, err := authService.Login(requestContext(tt.ipAddress, tt.userAgent), tt.request)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -260,7 +292,7 @@ func TestAuthService_ChangePassword(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := authService.ChangePassword(tt.userID, tt.request)
+			err := authService.ChangePassword(context.Background(), tt.userID, tt.request)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -412,7 +444,7 @@ func TestAuthService_UpdateProfile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			response, err := authService.UpdateProfile(tt.userID, tt.request)
+			response, err := authService.UpdateProfile(context.Background(), tt.userID, tt.request)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -465,18 +497,18 @@ func TestAuthService_AccountLockout(t *testing.T) {
 
 	// First 4 attempts should fail normally
 	for i := 0; i < 4; i++ {
-		_, err := authService.Login(loginRequest, "127.0.0.1", "test-agent")
+		_, err := authService.Login(requestContext("127.0.0.1", "test-agent"), loginRequest)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid username or password")
 	}
 
 	// 5th attempt should trigger account lockout
-	_, err = authService.Login(loginRequest, "127.0.0.1", "test-agent")
+	_, err = authService.Login(requestContext("127.0.0.1", "test-agent"), loginRequest)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid username or password")
 
 	// 6th attempt should show account is locked
-	_, err = authService.Login(loginRequest, "127.0.0.1", "test-agent")
+	_, err = authService.Login(requestContext("127.0.0.1", "test-agent"), loginRequest)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "account is temporarily locked")
 
@@ -485,7 +517,7 @@ func TestAuthService_AccountLockout(t *testing.T) {
 		Username: "testuser",
 		Password: "password123",
 	}
-	_, err = authService.Login(correctRequest, "127.0.0.1", "test-agent")
+	_, err = authService.Login(requestContext("127.0.0.1", "test-agent"), correctRequest)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "account is temporarily locked")
 }
@@ -588,7 +620,7 @@ func TestAuthService_UserManagement(t *testing.T) {
 		originalStatus := users[0].IsActive
 
 		// Update status
-		err = authService.UpdateUserStatus(userID, !originalStatus)
+		err = authService.UpdateUserStatus(context.Background(), userID, !originalStatus)
 		assert.NoError(t, err)
 
 		// Verify status was changed
@@ -606,7 +638,7 @@ func TestAuthService_UserManagement(t *testing.T) {
 		userID := users[0].ID
 
 		// Delete user
-		err = authService.DeleteUser(userID)
+		err = authService.DeleteUser(context.Background(), userID)
 		assert.NoError(t, err)
 
 		// Verify user was deleted
@@ -615,3 +647,214 @@ func TestAuthService_UserManagement(t *testing.T) {
 		assert.Contains(t, err.Error(), "not found")
 	})
 }
+
+func TestAuthService_UnlockAccount(t *testing.T) {
+	authService, testStore := setupTestAuthService()
+
+	// A successful login signs a JWT with the global configuration
+	previousConfig := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() { configs.GlobalConfig = previousConfig })
+
+	testUser := &store.User{
+		Username: "lockeduser",
+		Email:    "locked@example.com",
+		IsActive: true,
+	}
+	require.NoError(t, testUser.HashPassword("password123"))
+	require.NoError(t, testStore.CreateUser(testUser))
+
+	wrongRequest := &models.LoginRequest{Username: "lockeduser", Password: "wrongpassword"}
+	for i := 0; i < 5; i++ {
+		_, err := authService.Login(requestContext("127.0.0.1", "test-agent"), wrongRequest)
+		require.Error(t, err)
+	}
+	correctRequest := &models.LoginRequest{Username: "lockeduser", Password: "password123"}
+	_, err := authService.Login(requestContext("127.0.0.1", "test-agent"), correctRequest)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account is temporarily locked")
+
+	adminID := uint(1)
+	adminContext := WithAuditContext(context.Background(), AuditContext{UserID: &adminID, Username: "admin", IPAddress: "10.0.0.5"}, nil)
+	status, err := authService.UnlockAccount(adminContext, testUser.ID)
+	require.NoError(t, err)
+	assert.False(t, status.Locked)
+	assert.Nil(t, status.LockedUntil)
+	assert.Zero(t, status.FailedAttempts)
+
+	// The account can log in right away instead of waiting out the lockout
+	resp, err := authService.Login(requestContext("127.0.0.1", "test-agent"), correctRequest)
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+
+	logs, _, err := testStore.GetAuditLogsByAction("account_unlocked", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, testUser.ID, *logs[0].UserID)
+	assert.Contains(t, logs[0].Details, "unlocked by admin (user 1)", "the acting admin is recorded")
+	assert.Equal(t, "10.0.0.5", logs[0].IPAddress)
+
+	_, err = authService.UnlockAccount(adminContext, 9999)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestAuthService_PasswordExpiry(t *testing.T) {
+	previousConfig := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() { configs.GlobalConfig = previousConfig })
+
+	createUser := func(t *testing.T, testStore store.Store, username string, changedAt time.Time) *store.User {
+		user := &store.User{Username: username, Email: username + "@example.com", IsActive: true}
+		require.NoError(t, user.HashPassword("password123"))
+		user.PasswordChangedAt = &changedAt
+		require.NoError(t, testStore.CreateUser(user))
+		return user
+	}
+	login := func(authService *AuthService, username string) (*models.LoginResponse, error) {
+		return authService.Login(requestContext("127.0.0.1", "test-agent"), &models.LoginRequest{Username: username, Password: "password123"})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		authService, testStore := setupTestAuthService()
+		authService.config.Security.Password.MaxAge = 90
+		user := createUser(t, testStore, "olduser", time.Now().AddDate(0, 0, -91))
+
+		resp, err := login(authService, "olduser")
+		require.NoError(t, err)
+		assert.True(t, resp.PasswordExpired)
+		claims, err := auth.ParseToken(resp.Token)
+		require.NoError(t, err)
+		assert.True(t, claims.PasswordExpired, "the token only allows changing the password")
+
+		status, err := authService.GetPasswordExpiry(user.ID)
+		require.NoError(t, err)
+		assert.True(t, status.Expires)
+		assert.True(t, status.Expired)
+		assert.Zero(t, status.DaysUntilExpiry)
+	})
+
+	t.Run("not expired", func(t *testing.T) {
+		authService, testStore := setupTestAuthService()
+		authService.config.Security.Password.MaxAge = 90
+		user := createUser(t, testStore, "recentuser", time.Now().AddDate(0, 0, -80))
+
+		resp, err := login(authService, "recentuser")
+		require.NoError(t, err)
+		assert.False(t, resp.PasswordExpired)
+		claims, err := auth.ParseToken(resp.Token)
+		require.NoError(t, err)
+		assert.False(t, claims.PasswordExpired)
+
+		status, err := authService.GetPasswordExpiry(user.ID)
+		require.NoError(t, err)
+		assert.False(t, status.Expired)
+		assert.Equal(t, 10, status.DaysUntilExpiry)
+		assert.Equal(t, 90, status.MaxAgeDays)
+	})
+
+	t.Run("no max age", func(t *testing.T) {
+		authService, testStore := setupTestAuthService()
+		user := createUser(t, testStore, "ancientuser", time.Now().AddDate(-5, 0, 0))
+
+		resp, err := login(authService, "ancientuser")
+		require.NoError(t, err)
+		assert.False(t, resp.PasswordExpired)
+
+		status, err := authService.GetPasswordExpiry(user.ID)
+		require.NoError(t, err)
+		assert.False(t, status.Expires)
+		assert.Nil(t, status.ExpiresAt)
+	})
+
+	t.Run("change time not recorded", func(t *testing.T) {
+		authService, testStore := setupTestAuthService()
+		authService.config.Security.Password.MaxAge = 90
+		user := &store.User{Username: "legacyuser", Email: "legacyuser@example.com", IsActive: true, CreatedAt: time.Now().AddDate(-1, 0, 0)}
+		require.NoError(t, user.HashPassword("password123"))
+		user.PasswordChangedAt = nil
+		require.NoError(t, testStore.CreateUser(user))
+
+		resp, err := login(authService, "legacyuser")
+		require.NoError(t, err)
+		assert.False(t, resp.PasswordExpired, "the account age does not expire a password")
+
+		status, err := authService.GetPasswordExpiry(user.ID)
+		require.NoError(t, err)
+		assert.False(t, status.Expired)
+		assert.Nil(t, status.ExpiresAt)
+	})
+}
+
+func TestAuthService_BootstrapAdminMustChangePassword(t *testing.T) {
+	previousConfig := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() { configs.GlobalConfig = previousConfig })
+	t.Setenv("CILIKUBE_ADMIN_PASSWORD", "")
+
+	authService, _ := setupTestAuthService()
+	login := func(password string) *models.LoginResponse {
+		resp, err := authService.Login(requestContext("127.0.0.1", "test-agent"), &models.LoginRequest{Username: "admin", Password: password})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := login(configs.DefaultAdminPassword)
+	assert.True(t, resp.MustChangePassword)
+	assert.True(t, resp.PasswordExpired)
+	claims, err := auth.ParseToken(resp.Token)
+	require.NoError(t, err)
+	assert.True(t, claims.PasswordExpired, "the token only allows changing the password")
+
+	require.NoError(t, authService.ChangePassword(context.Background(), resp.User.ID, &models.ChangePasswordRequest{
+		OldPassword: configs.DefaultAdminPassword,
+		NewPassword: "changed-password-1",
+	}))
+	resp = login("changed-password-1")
+	assert.False(t, resp.MustChangePassword)
+	assert.False(t, resp.PasswordExpired)
+}
+
+func TestAuthService_LogoutAll(t *testing.T) {
+	previousConfig := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() { configs.GlobalConfig = previousConfig })
+
+	authService, testStore := setupTestAuthService()
+	user := &store.User{Username: "logoutuser", Email: "logoutuser@example.com", IsActive: true}
+	require.NoError(t, user.HashPassword("password123"))
+	require.NoError(t, testStore.CreateUser(user))
+
+	login := func() *models.LoginResponse {
+		resp, err := authService.Login(requestContext("127.0.0.1", "test-agent"), &models.LoginRequest{Username: "logoutuser", Password: "password123"})
+		require.NoError(t, err)
+		return resp
+	}
+	laptop, phone := login(), login()
+	sessions, err := authService.GetUserSessions(user.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(sessions), 2)
+
+	terminated, err := authService.LogoutAll(requestContext("127.0.0.1", "test-agent"), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, len(sessions), terminated)
+
+	sessions, err = authService.GetUserSessions(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	for _, resp := range []*models.LoginResponse{laptop, phone} {
+		_, err := auth.ParseToken(resp.Token)
+		assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+	}
+
+	// Logging in again works right away
+	_, err = auth.ParseToken(login().Token)
+	assert.NoError(t, err)
+
+	logs, _, err := testStore.GetAuditLogsByAction(string(EventTypeLogoutAll), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, user.ID, *logs[0].UserID)
+
+	_, err = authService.LogoutAll(context.Background(), 9999)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
+)
+
+// defaultAuthWebhookTimeout is the timeout of a login request when auth_webhook.timeout is unset
+const defaultAuthWebhookTimeout = 10 * time.Second
+
+const (
+	// AuthWebhookSignatureHeader carries the signature of a login request, see SignAuthWebhookRequest
+	AuthWebhookSignatureHeader = "X-Cilikube-Auth-Signature"
+	// AuthWebhookTimestampHeader carries the Unix time a login request was sent at, which is signed along
+	// with the body so receivers can refuse replayed requests
+	AuthWebhookTimestampHeader = "X-Cilikube-Auth-Timestamp"
+)
+
+// webhookIdentityProvider names the links between local users and the identities of the auth webhook,
+// kept with the OAuth provider links
+const webhookIdentityProvider = "webhook"
+
+var (
+	// ErrAuthWebhookRejected is returned when the auth webhook doesn't accept the credentials
+	ErrAuthWebhookRejected = errors.New("credentials rejected by auth webhook")
+	// ErrAuthServiceUnavailable is returned by Login when the auth webhook can't be reached or answers with an error
+	ErrAuthServiceUnavailable = errors.New("authentication service is unavailable")
+	// ErrWebhookUsernameTaken is returned when an identity of the auth webhook has the username of a local account
+	ErrWebhookUsernameTaken = errors.New("username belongs to a local account")
+)
+
+// AuthWebhookRequest is the body posted to the auth webhook for a login
+type AuthWebhookRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// AuthWebhookResponse is the answer of the auth webhook. A 401 or 403 status rejects the credentials too.
+type AuthWebhookResponse struct {
+	Authenticated bool              `json:"authenticated"`
+	User          *ExternalIdentity `json:"user,omitempty"`
+}
+
+// ExternalIdentity is the identity of a user authenticated by the auth webhook
+type ExternalIdentity struct {
+	ID          string   `json:"id"`       // Stable ID of the user in the identity service, defaults to the username
+	Username    string   `json:"username"` // Defaults to the username logged in with
+	Email       string   `json:"email"`    // Required the first time the user logs in, when the account is created
+	DisplayName string   `json:"display_name"`
+	Groups      []string `json:"groups"`
+}
+
+// AuthWebhookClient checks login credentials against an external service instead of local passwords
+type AuthWebhookClient struct {
+	url         string
+	secret      []byte
+	client      *http.Client
+	groupRoles  []configs.GroupRoleMapping
+	defaultRole string
+}
+
+// NewAuthWebhookClient creates the client of the configured webhook, nil when the webhook is disabled
+func NewAuthWebhookClient(config configs.AuthWebhookConfig) *AuthWebhookClient {
+	if !config.Enabled {
+		return nil
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultAuthWebhookTimeout
+	}
+	return &AuthWebhookClient{
+		url:         config.URL,
+		secret:      []byte(config.Secret),
+		client:      &http.Client{Timeout: timeout},
+		groupRoles:  config.GroupRoles,
+		defaultRole: config.DefaultRole,
+	}
+}
+
+// Authenticate posts the credentials to the webhook and returns the identity it authenticated.
+// It returns ErrAuthWebhookRejected when the webhook refuses them.
+func (w *AuthWebhookClient) Authenticate(ctx context.Context, username, password string) (*ExternalIdentity, error) {
+	body, err := json.Marshal(AuthWebhookRequest{Username: username, Password: password})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(AuthWebhookTimestampHeader, timestamp)
+	req.Header.Set(AuthWebhookSignatureHeader, SignAuthWebhookRequest(w.secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("auth webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, ErrAuthWebhookRejected
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("auth webhook responded with status %d", resp.StatusCode)
+	}
+
+	var result AuthWebhookResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode auth webhook response: %w", err)
+	}
+	if !result.Authenticated {
+		return nil, ErrAuthWebhookRejected
+	}
+	identity := result.User
+	if identity == nil {
+		identity = &ExternalIdentity{}
+	}
+	if identity.Username == "" {
+		identity.Username = username
+	}
+	if identity.ID == "" {
+		identity.ID = identity.Username
+	}
+	return identity, nil
+}
+
+// SignAuthWebhookRequest returns the signature header value of a login request: the hex encoded HMAC-SHA256
+// of its timestamp, a dot and its body, prefixed with "sha256="
+func SignAuthWebhookRequest(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Roles returns the roles of the members of groups in the order they are mapped, the default role when
+// none of the groups is mapped, and nil when there is no default role either
+func (w *AuthWebhookClient) Roles(groups []string) []string {
+	member := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		member[group] = true
+	}
+	var roles []string
+	seen := make(map[string]bool)
+	for _, mapping := range w.groupRoles {
+		if member[mapping.Group] && !seen[mapping.Role] {
+			seen[mapping.Role] = true
+			roles = append(roles, mapping.Role)
+		}
+	}
+	if len(roles) == 0 && w.defaultRole != "" {
+		roles = []string{w.defaultRole}
+	}
+	return roles
+}
+
+// isLocalAccount reports whether username names a local account not linked to the auth webhook, such as the
+// initial admin. Those keep logging in with their local password, so admins can get in while the webhook is down.
+func (s *AuthService) isLocalAccount(username string) bool {
+	storeUser, err := s.store.GetUserByUsername(username)
+	if err != nil {
+		return false
+	}
+	_, err = s.store.GetOAuthProvider(storeUser.ID, webhookIdentityProvider)
+	return errors.Is(err, store.ErrOAuthProviderNotFound)
+}
+
+// loginWithWebhook logs in a user whose credentials are checked by the auth webhook. The user is created
+// on first login and its roles are replaced by those of its groups on every login.
+func (s *AuthService) loginWithWebhook(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+	audit := AuditContextFrom(ctx)
+	ipAddress, userAgent := audit.IPAddress, audit.UserAgent
+
+	// Failed logins of known users count towards their lockout as with local passwords
+	var knownUserID *uint
+	if existingUser, err := s.store.GetUserByUsername(req.Username); err == nil {
+		knownUserID = &existingUser.ID
+		isLocked, lockoutEnd, err := s.securityService.CheckAccountLockout(existingUser.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check account lockout: %w", err)
+		}
+		if isLocked {
+			return nil, fmt.Errorf("account is temporarily locked until %s due to multiple failed login attempts", lockoutEnd.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	identity, err := s.authWebhook.Authenticate(ctx, req.Username, req.Password)
+	if errors.Is(err, ErrAuthWebhookRejected) {
+		s.securityService.RecordFailedLogin(knownUserID, req.Username, ipAddress, userAgent)
+		return nil, errors.New("invalid username or password")
+	}
+	if err != nil {
+		log.Printf("Warning: auth webhook login of %s failed: %v", req.Username, err)
+		return nil, ErrAuthServiceUnavailable
+	}
+
+	roles := s.authWebhook.Roles(identity.Groups)
+	if len(roles) == 0 {
+		s.securityService.RecordFailedLogin(knownUserID, req.Username, ipAddress, userAgent)
+		s.auditService.LogAuthenticationEvent(AuditEventType("login_failed"), knownUserID, identity.Username, ipAddress, userAgent, false, map[string]interface{}{
+			"reason": "no_mapped_group",
+			"groups": identity.Groups,
+		})
+		return nil, errors.New("user is not in a group allowed to log in")
+	}
+
+	storeUser, err := s.syncWebhookUser(ctx, identity, roles)
+	if errors.Is(err, ErrWebhookUsernameTaken) {
+		s.auditService.LogAuthenticationEvent(AuditEventType("login_failed"), nil, identity.Username, ipAddress, userAgent, false, map[string]interface{}{
+			"reason":      "username_taken",
+			"external_id": identity.ID,
+		})
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !storeUser.IsActive {
+		s.securityService.RecordFailedLogin(&storeUser.ID, req.Username, ipAddress, userAgent)
+		return nil, errors.New("account is disabled")
+	}
+
+	return s.completeLogin(ctx, storeUser, auth.AuthMethodWebhook, roles[0])
+}
+
+// syncWebhookUser returns the local user linked to an identity authenticated by the auth webhook, creating and
+// linking it when missing and updating its profile, and gives it exactly roles. A local account is never taken
+// over because its username matches: ErrWebhookUsernameTaken is returned instead.
+func (s *AuthService) syncWebhookUser(ctx context.Context, identity *ExternalIdentity, roles []string) (*store.User, error) {
+	var storeUser *store.User
+	created := false
+	err := s.store.Transaction(func(tx store.Store) error {
+		if link, err := tx.GetOAuthProviderByProviderUserID(webhookIdentityProvider, identity.ID); err == nil {
+			if storeUser, err = tx.GetUserByID(link.UserID); err != nil {
+				return fmt.Errorf("failed to get linked user: %w", err)
+			}
+			changed := false
+			if identity.Email != "" && identity.Email != storeUser.Email {
+				storeUser.Email = identity.Email
+				changed = true
+			}
+			if identity.DisplayName != "" && identity.DisplayName != storeUser.DisplayName {
+				storeUser.DisplayName = identity.DisplayName
+				changed = true
+			}
+			if changed {
+				if err := tx.UpdateUser(storeUser); err != nil {
+					return fmt.Errorf("failed to update user: %w", err)
+				}
+			}
+		} else {
+			if _, err := tx.GetUserByUsername(identity.Username); err == nil {
+				return ErrWebhookUsernameTaken
+			}
+			if identity.Email == "" {
+				return fmt.Errorf("auth webhook returned no email for new user %s", identity.Username)
+			}
+			storeUser = &store.User{
+				Username:    identity.Username,
+				Email:       identity.Email,
+				DisplayName: identity.DisplayName,
+				IsActive:    true,
+			}
+			// The password is checked by the webhook, the local one is random so it can never be used
+			if err := storeUser.HashPassword(randomPassword()); err != nil {
+				return fmt.Errorf("failed to hash password: %w", err)
+			}
+			if err := tx.CreateUser(storeUser); err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+			link := &store.OAuthProvider{UserID: storeUser.ID, Provider: webhookIdentityProvider, ProviderUserID: identity.ID}
+			if err := tx.CreateOAuthProvider(link); err != nil {
+				return fmt.Errorf("failed to link user: %w", err)
+			}
+			created = true
+		}
+		return replaceUserRoles(tx, storeUser.ID, roles)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.permissionService != nil {
+		if err := s.permissionService.SyncUserRoles(storeUser.ID); err != nil {
+			log.Printf("Warning: failed to sync roles of user %s with Casbin: %v", storeUser.Username, err)
+		}
+	}
+	if created {
+		s.createAuditLog(ctx, &storeUser.ID, "webhook_register", "user", fmt.Sprintf("%d", storeUser.ID), "New user registered via auth webhook")
+	}
+	return storeUser, nil
+}
+
+// replaceUserRoles gives the user exactly the named roles
+func replaceUserRoles(tx store.Store, userID uint, roleNames []string) error {
+	wanted := make(map[uint]bool, len(roleNames))
+	for _, name := range roleNames {
+		role, err := tx.GetRoleByName(name)
+		if err != nil {
+			return fmt.Errorf("failed to get role %s: %w", name, err)
+		}
+		wanted[role.ID] = true
+	}
+
+	current, err := tx.GetUserRoles(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user roles: %w", err)
+	}
+	for _, role := range current {
+		if wanted[role.ID] {
+			delete(wanted, role.ID)
+			continue
+		}
+		if err := tx.RemoveRole(userID, role.ID); err != nil {
+			return fmt.Errorf("failed to remove role %s: %w", role.Name, err)
+		}
+	}
+	for roleID := range wanted {
+		if err := tx.AssignRole(userID, roleID); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+	}
+	return nil
+}
+
+// randomPassword returns a password nobody knows
+func randomPassword() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAuthWebhook authenticates the users it knows, by password, with their groups
+type stubAuthWebhook struct {
+	secret string
+
+	mu        sync.Mutex
+	passwords map[string]string
+	groups    map[string][]string
+	usernames map[string]string // Usernames returned for logins, when they differ
+}
+
+func (w *stubAuthWebhook) setGroups(username string, groups ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.groups[username] = groups
+}
+
+func (w *stubAuthWebhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	timestamp := r.Header.Get(AuthWebhookTimestampHeader)
+	if timestamp == "" || r.Header.Get(AuthWebhookSignatureHeader) != SignAuthWebhookRequest([]byte(w.secret), timestamp, body) {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var req AuthWebhookRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if password, ok := w.passwords[req.Username]; !ok || password != req.Password {
+		json.NewEncoder(rw).Encode(AuthWebhookResponse{Authenticated: false})
+		return
+	}
+	json.NewEncoder(rw).Encode(AuthWebhookResponse{Authenticated: true, User: &ExternalIdentity{
+		Username:    w.usernames[req.Username],
+		Email:       req.Username + "@corp.example.com",
+		DisplayName: "Corp " + req.Username,
+		Groups:      w.groups[req.Username],
+	}})
+}
+
+// setupWebhookAuthService returns an auth service delegating logins to a stub webhook
+func setupWebhookAuthService(t *testing.T, defaultRole string) (*AuthService, store.Store, *stubAuthWebhook) {
+	t.Helper()
+	previousConfig := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() { configs.GlobalConfig = previousConfig })
+
+	webhook := &stubAuthWebhook{
+		secret:    "shared",
+		passwords: map[string]string{"carol": "corp-password", "dave": "dave-password"},
+		groups:    map[string][]string{},
+		usernames: map[string]string{},
+	}
+	server := httptest.NewServer(webhook)
+	t.Cleanup(server.Close)
+
+	testStore := store.NewMemoryStore()
+	require.NoError(t, testStore.Initialize())
+	config := &configs.Config{AuthWebhook: configs.AuthWebhookConfig{
+		Enabled: true,
+		URL:     server.URL,
+		Secret:  "shared",
+		GroupRoles: []configs.GroupRoleMapping{
+			{Group: "platform-admins", Role: "admin"},
+			{Group: "developers", Role: "editor"},
+		},
+		DefaultRole: defaultRole,
+	}}
+	return NewAuthService(testStore, config), testStore, webhook
+}
+
+func userRoleNames(t *testing.T, s store.Store, userID uint) []string {
+	t.Helper()
+	roles, err := s.GetUserRoles(userID)
+	require.NoError(t, err)
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+func TestAuthService_LoginWithWebhook(t *testing.T) {
+	authService, testStore, webhook := setupWebhookAuthService(t, "")
+	webhook.setGroups("carol", "developers", "platform-admins")
+
+	resp, err := authService.Login(context.Background(), &models.LoginRequest{Username: "carol", Password: "corp-password"})
+	require.NoError(t, err)
+
+	claims, err := auth.ParseToken(resp.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", claims.Role, "the first mapped group gives the primary role")
+	assert.Equal(t, auth.AuthMethodWebhook, claims.AuthMethod)
+
+	user, err := testStore.GetUserByUsername("carol")
+	require.NoError(t, err, "the user is created on first login")
+	assert.Equal(t, "carol@corp.example.com", user.Email)
+	assert.Equal(t, "Corp carol", user.DisplayName)
+	assert.False(t, user.CheckPassword("corp-password"), "the password isn't stored locally")
+	assert.ElementsMatch(t, []string{"admin", "editor"}, userRoleNames(t, testStore, user.ID))
+
+	registered, _, err := testStore.GetAuditLogsByAction("webhook_register", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, registered, 1)
+
+	t.Run("roles follow the groups on every login", func(t *testing.T) {
+		webhook.setGroups("carol", "developers")
+		resp, err := authService.Login(context.Background(), &models.LoginRequest{Username: "carol", Password: "corp-password"})
+		require.NoError(t, err)
+		assert.Equal(t, "editor", resp.User.Role)
+		assert.Equal(t, []string{"editor"}, userRoleNames(t, testStore, user.ID))
+	})
+
+	t.Run("rejected credentials", func(t *testing.T) {
+		_, err := authService.Login(context.Background(), &models.LoginRequest{Username: "carol", Password: "wrong"})
+		assert.EqualError(t, err, "invalid username or password")
+		_, err = authService.Login(context.Background(), &models.LoginRequest{Username: "mallory", Password: "guess"})
+		assert.EqualError(t, err, "invalid username or password")
+		_, err = testStore.GetUserByUsername("mallory")
+		assert.Error(t, err, "no user is created for rejected credentials")
+	})
+
+	t.Run("users in no mapped group are refused", func(t *testing.T) {
+		webhook.setGroups("dave", "contractors")
+		_, err := authService.Login(context.Background(), &models.LoginRequest{Username: "dave", Password: "dave-password"})
+		assert.Error(t, err)
+		_, err = testStore.GetUserByUsername("dave")
+		assert.Error(t, err)
+	})
+
+	t.Run("deactivated users are refused", func(t *testing.T) {
+		user, err := testStore.GetUserByUsername("carol")
+		require.NoError(t, err)
+		user.IsActive = false
+		require.NoError(t, testStore.UpdateUser(user))
+		t.Cleanup(func() {
+			user.IsActive = true
+			testStore.UpdateUser(user)
+		})
+		_, err = authService.Login(context.Background(), &models.LoginRequest{Username: "carol", Password: "corp-password"})
+		assert.EqualError(t, err, "account is disabled")
+	})
+}
+
+func TestAuthService_LoginWithWebhookLocalAccounts(t *testing.T) {
+	authService, testStore, webhook := setupWebhookAuthService(t, "viewer")
+	admin, err := testStore.GetUserByUsername("admin")
+	require.NoError(t, err)
+	require.NoError(t, admin.HashPassword("break-glass"))
+	admin.IsActive = true
+	require.NoError(t, testStore.UpdateUser(admin))
+
+	resp, err := authService.Login(context.Background(), &models.LoginRequest{Username: "admin", Password: "break-glass"})
+	require.NoError(t, err, "local accounts log in with their password")
+	claims, err := auth.ParseToken(resp.Token)
+	require.NoError(t, err)
+	assert.Equal(t, auth.AuthMethodPassword, claims.AuthMethod)
+
+	webhook.usernames["dave"] = "admin"
+	_, err = authService.Login(context.Background(), &models.LoginRequest{Username: "dave", Password: "dave-password"})
+	assert.ErrorIs(t, err, ErrWebhookUsernameTaken, "an identity is not bound to a local account by its username")
+	_, err = authService.Login(context.Background(), &models.LoginRequest{Username: "admin", Password: "break-glass"})
+	assert.NoError(t, err, "the local account is left alone")
+}
+
+func TestAuthService_LoginWithWebhookDefaultRole(t *testing.T) {
+	authService, testStore, webhook := setupWebhookAuthService(t, "viewer")
+	webhook.setGroups("dave", "contractors")
+
+	resp, err := authService.Login(context.Background(), &models.LoginRequest{Username: "dave", Password: "dave-password"})
+	require.NoError(t, err)
+	assert.Equal(t, "viewer", resp.User.Role)
+	user, err := testStore.GetUserByUsername("dave")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"viewer"}, userRoleNames(t, testStore, user.ID))
+}
+
+func TestAuthService_LoginWithWebhookUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+	testStore := store.NewMemoryStore()
+	require.NoError(t, testStore.Initialize())
+	authService := NewAuthService(testStore, &configs.Config{AuthWebhook: configs.AuthWebhookConfig{Enabled: true, URL: server.URL, DefaultRole: "viewer"}})
+
+	_, err := authService.Login(context.Background(), &models.LoginRequest{Username: "carol", Password: "corp-password"})
+	assert.ErrorIs(t, err, ErrAuthServiceUnavailable)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -10,6 +11,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// ErrInvalidResource is returned when a resource fails validation before being submitted to the cluster
+var ErrInvalidResource = errors.New("invalid resource")
+
 // ResourceClient resource client interface
 // For consistency, all methods accept namespace parameter. For non-namespaced resources, implementations can ignore this parameter.
 type ResourceClient[T runtime.Object] interface {
@@ -27,6 +31,8 @@ type ResourceService[T runtime.Object] interface {
 	Get(clientset kubernetes.Interface, namespace, name string) (T, error)
 	Create(clientset kubernetes.Interface, namespace string, obj T) (T, error)
 	Update(clientset kubernetes.Interface, namespace, name string, obj T) (T, error)
+	DryRunCreate(clientset kubernetes.Interface, namespace string, obj T) (T, error)
+	DryRunUpdate(clientset kubernetes.Interface, namespace, name string, obj T) (T, error)
 	Patch(clientset kubernetes.Interface, namespace, name string, current T, patchData map[string]interface{}) (T, error)
 	Delete(clientset kubernetes.Interface, namespace, name string) error
 	Watch(clientset kubernetes.Interface, namespace, selector string, resourceVersion string, timeoutSeconds int64) (watch.Interface, error)
@@ -73,6 +79,18 @@ func (s *BaseResourceService[T]) Update(clientset kubernetes.Interface, namespac
 	return s.client.Update(ctx, clientset, namespace, obj, metav1.UpdateOptions{})
 }
 
+// DryRunCreate submits a create with server-side dry run and returns the object the server would have created
+func (s *BaseResourceService[T]) DryRunCreate(clientset kubernetes.Interface, namespace string, obj T) (T, error) {
+	ctx := context.Background()
+	return s.client.Create(ctx, clientset, namespace, obj, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+}
+
+// DryRunUpdate submits an update with server-side dry run and returns the object the server would have stored
+func (s *BaseResourceService[T]) DryRunUpdate(clientset kubernetes.Interface, namespace, name string, obj T) (T, error) {
+	ctx := context.Background()
+	return s.client.Update(ctx, clientset, namespace, obj, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+}
+
 // Patch patches resource (for partial updates like scaling)
 func (s *BaseResourceService[T]) Patch(clientset kubernetes.Interface, namespace, name string, current T, patchData map[string]interface{}) (T, error) {
 	// For now, we'll implement a simple patch by modifying the current object
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ErrEmptySelector is returned when a batch delete is requested without a label selector
+var ErrEmptySelector = errors.New("a non-empty label selector is required")
+
+// BatchDeleteError describes an object that could not be deleted
+type BatchDeleteError struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// BatchDeleteResult is the outcome of deleting all objects matching a label selector
+type BatchDeleteResult struct {
+	Resource  string             `json:"resource"`
+	Namespace string             `json:"namespace"`
+	Selector  string             `json:"selector"`
+	Deleted   int                `json:"deleted"`
+	Names     []string           `json:"names"`
+	Errors    []BatchDeleteError `json:"errors,omitempty"`
+}
+
+// BatchDeleteService deletes all namespaced objects of a resource that match a label selector
+type BatchDeleteService struct{}
+
+// NewBatchDeleteService creates a new BatchDeleteService instance
+func NewBatchDeleteService() *BatchDeleteService {
+	return &BatchDeleteService{}
+}
+
+// DeleteBySelector deletes the objects of resource in namespace matching selector. It uses a collection
+// delete and falls back to deleting the listed objects one by one when the resource does not support it.
+// An empty selector is refused so a whole namespace cannot be wiped by accident.
+func (s *BatchDeleteService) DeleteBySelector(client dynamic.Interface, mapper meta.RESTMapper, namespace, resource, selector string) (*BatchDeleteResult, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return nil, ErrEmptySelector
+	}
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid label selector: %v", ErrInvalidResource, err)
+	}
+	if parsed.Empty() {
+		return nil, ErrEmptySelector
+	}
+
+	gvr, err := namespacedResource(mapper, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.TODO()
+	ri := client.Resource(gvr).Namespace(namespace)
+	listOpts := metav1.ListOptions{LabelSelector: parsed.String()}
+	list, err := ri.List(ctx, listOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BatchDeleteResult{
+		Resource:  gvr.Resource,
+		Namespace: namespace,
+		Selector:  parsed.String(),
+		Names:     make([]string, 0, len(list.Items)),
+	}
+	if len(list.Items) == 0 {
+		return result, nil
+	}
+
+	err = ri.DeleteCollection(ctx, metav1.DeleteOptions{}, listOpts)
+	if err == nil {
+		for _, item := range list.Items {
+			result.Names = append(result.Names, item.GetName())
+		}
+		result.Deleted = len(result.Names)
+		return result, nil
+	}
+	if !k8serrors.IsMethodNotSupported(err) {
+		return nil, err
+	}
+
+	for _, item := range list.Items {
+		name := item.GetName()
+		if err := ri.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			result.Errors = append(result.Errors, BatchDeleteError{Name: name, Error: err.Error()})
+			continue
+		}
+		result.Names = append(result.Names, name)
+	}
+	result.Deleted = len(result.Names)
+	return result, nil
+}
+
+// namespacedResource resolves a resource name such as "deployments" or "certificates.cert-manager.io"
+// and checks that the resource is namespaced
+func namespacedResource(mapper meta.RESTMapper, resource string) (schema.GroupVersionResource, error) {
+	gvr, err := mapper.ResourceFor(schema.ParseGroupResource(resource).WithVersion(""))
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return schema.GroupVersionResource{}, fmt.Errorf("%w: %s is cluster-scoped", ErrResourceScopeMismatch, gvr.GroupResource())
+	}
+	return mapping.Resource, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func newTestLabeledConfigMap(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	return obj
+}
+
+// newTestBatchDeleteEnv returns a dynamic client whose collection delete removes the seeded objects matching
+// the selector, like the API server does
+func newTestBatchDeleteEnv(objects ...*unstructured.Unstructured) (*dynamicfake.FakeDynamicClient, meta.RESTMapper) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Version: "v1"}})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Node"}, meta.RESTScopeRoot)
+
+	seeded := make([]runtime.Object, 0, len(objects))
+	for _, obj := range objects {
+		seeded = append(seeded, obj)
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMapGVR: "ConfigMapList",
+	}, seeded...)
+
+	client.PrependReactor("delete-collection", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleteCollection := action.(k8stesting.DeleteCollectionActionImpl)
+		selector := deleteCollection.GetListRestrictions().Labels
+		for _, obj := range objects {
+			if obj.GetNamespace() != action.GetNamespace() || !selector.Matches(labels.Set(obj.GetLabels())) {
+				continue
+			}
+			if err := client.Tracker().Delete(configMapGVR, obj.GetNamespace(), obj.GetName()); err != nil && !k8serrors.IsNotFound(err) {
+				return true, nil, err
+			}
+		}
+		return true, nil, nil
+	})
+	return client, mapper
+}
+
+func remainingConfigMaps(t *testing.T, client *dynamicfake.FakeDynamicClient, namespace string) []string {
+	t.Helper()
+	list, err := client.Resource(configMapGVR).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return names
+}
+
+func TestBatchDeleteService_DeletesMatchingObjects(t *testing.T) {
+	client, mapper := newTestBatchDeleteEnv(
+		newTestLabeledConfigMap("test", "tmp-1", map[string]string{"purpose": "e2e"}),
+		newTestLabeledConfigMap("test", "tmp-2", map[string]string{"purpose": "e2e"}),
+		newTestLabeledConfigMap("test", "keep", map[string]string{"purpose": "prod"}),
+		newTestLabeledConfigMap("other", "tmp-3", map[string]string{"purpose": "e2e"}),
+	)
+
+	result, err := NewBatchDeleteService().DeleteBySelector(client, mapper, "test", "configmaps", "purpose=e2e")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Deleted)
+	assert.ElementsMatch(t, []string{"tmp-1", "tmp-2"}, result.Names)
+	assert.Empty(t, result.Errors)
+
+	assert.Equal(t, []string{"keep"}, remainingConfigMaps(t, client, "test"))
+	assert.Equal(t, []string{"tmp-3"}, remainingConfigMaps(t, client, "other"), "other namespaces are untouched")
+}
+
+func TestBatchDeleteService_FallsBackToSingleDeletes(t *testing.T) {
+	client, mapper := newTestBatchDeleteEnv(
+		newTestLabeledConfigMap("test", "tmp-1", map[string]string{"purpose": "e2e"}),
+		newTestLabeledConfigMap("test", "keep", nil),
+	)
+	client.PrependReactor("delete-collection", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewMethodNotSupported(configMapGVR.GroupResource(), "deletecollection")
+	})
+
+	result, err := NewBatchDeleteService().DeleteBySelector(client, mapper, "test", "configmaps", "purpose=e2e")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Deleted)
+	assert.Equal(t, []string{"keep"}, remainingConfigMaps(t, client, "test"))
+}
+
+func TestBatchDeleteService_RefusesEmptySelector(t *testing.T) {
+	client, mapper := newTestBatchDeleteEnv(newTestLabeledConfigMap("test", "keep", map[string]string{"purpose": "e2e"}))
+	svc := NewBatchDeleteService()
+
+	for _, selector := range []string{"", "   "} {
+		_, err := svc.DeleteBySelector(client, mapper, "test", "configmaps", selector)
+		assert.ErrorIs(t, err, ErrEmptySelector)
+	}
+	_, err := svc.DeleteBySelector(client, mapper, "test", "configmaps", "purpose in (")
+	assert.ErrorIs(t, err, ErrInvalidResource)
+	_, err = svc.DeleteBySelector(client, mapper, "test", "nodes", "purpose=e2e")
+	assert.ErrorIs(t, err, ErrResourceScopeMismatch)
+
+	assert.Equal(t, []string{"keep"}, remainingConfigMaps(t, client, "test"))
+}
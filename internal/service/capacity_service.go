@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// CapacityListers groups the listers read when building a capacity report
+type CapacityListers struct {
+	Nodes corelisters.NodeLister
+	Pods  corelisters.PodLister
+}
+
+// CapacityService reports the allocatable resources of a cluster against the requests, limits and usage of its pods
+type CapacityService struct {
+	informers *k8s.InformerRegistry
+}
+
+// NewCapacityService creates a new CapacityService instance
+func NewCapacityService(informers *k8s.InformerRegistry) *CapacityService {
+	return &CapacityService{
+		informers: informers,
+	}
+}
+
+// ListersFor returns the listers of a cluster, starting and syncing its informers on first use
+func (s *CapacityService) ListersFor(clusterID string, clientset kubernetes.Interface) (*CapacityListers, error) {
+	var listers *CapacityListers
+	err := s.informers.Sync(clusterID, clientset, func(factory informers.SharedInformerFactory) {
+		listers = &CapacityListers{
+			Nodes: factory.Core().V1().Nodes().Lister(),
+			Pods:  factory.Core().V1().Pods().Lister(),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listers, nil
+}
+
+// nodeResources accumulates the resources of a node and the pods bound to it
+type nodeResources struct {
+	allocatable corev1.ResourceList
+	requests    corev1.ResourceList
+	limits      corev1.ResourceList
+	usage       corev1.ResourceList // nil without metrics for the node
+	pods        int
+}
+
+func (r *nodeResources) add(other *nodeResources) {
+	addResourceList(r.allocatable, other.allocatable)
+	addResourceList(r.requests, other.requests)
+	addResourceList(r.limits, other.limits)
+	if other.usage != nil {
+		if r.usage == nil {
+			r.usage = corev1.ResourceList{}
+		}
+		addResourceList(r.usage, other.usage)
+	}
+	r.pods += other.pods
+}
+
+func newNodeResources(allocatable corev1.ResourceList) *nodeResources {
+	return &nodeResources{allocatable: allocatable, requests: corev1.ResourceList{}, limits: corev1.ResourceList{}}
+}
+
+// Capacity sums the allocatable resources of the nodes and the requests and limits of the pods bound to them
+// from the informer caches. Cordoned and not ready nodes are reported but left out of the schedulable capacity.
+// The current usage is read from metrics-server when metricsClient is set; a metrics failure is reported in
+// the result instead of failing it.
+func (s *CapacityService) Capacity(listers *CapacityListers, metricsClient versioned.Interface) (*models.ClusterCapacity, error) {
+	nodes, err := listers.Nodes.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := listers.Pods.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	result := &models.ClusterCapacity{Nodes: len(nodes), NodeBreakdown: []models.NodeCapacity{}}
+	byNode := make(map[string]*nodeResources, len(nodes))
+	for _, node := range nodes {
+		byNode[node.Name] = newNodeResources(node.Status.Allocatable)
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if pod.Spec.NodeName == "" {
+			result.PendingPods++
+			continue
+		}
+		resources, ok := byNode[pod.Spec.NodeName]
+		if !ok {
+			// Bound to a node that no longer exists
+			continue
+		}
+		requests, limits := podContainerResources(pod)
+		addResourceList(resources.requests, requests)
+		addResourceList(resources.limits, limits)
+		resources.pods++
+	}
+
+	if metricsClient != nil {
+		if err := addNodeUsage(metricsClient, byNode); err != nil {
+			result.MetricsError = err.Error()
+		}
+	}
+
+	total, schedulable := newNodeResources(corev1.ResourceList{}), newNodeResources(corev1.ResourceList{})
+	for _, node := range nodes {
+		resources := byNode[node.Name]
+		item := models.NodeCapacity{
+			Name:          node.Name,
+			Ready:         isNodeReady(node),
+			CapacityUsage: capacityUsage(resources),
+		}
+		item.Schedulable = item.Ready && !node.Spec.Unschedulable
+		result.NodeBreakdown = append(result.NodeBreakdown, item)
+
+		total.add(resources)
+		if item.Schedulable {
+			schedulable.add(resources)
+			result.SchedulableNodes++
+		}
+	}
+	result.Total = capacityUsage(total)
+	result.Schedulable = capacityUsage(schedulable)
+	return result, nil
+}
+
+// addNodeUsage records the current usage reported by metrics-server for each known node
+func addNodeUsage(metricsClient versioned.Interface, byNode map[string]*nodeResources) error {
+	nodeMetrics, err := metricsClient.MetricsV1beta1().NodeMetricses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return metricsError(err)
+	}
+	for _, metrics := range nodeMetrics.Items {
+		if resources, ok := byNode[metrics.Name]; ok {
+			resources.usage = metrics.Usage
+		}
+	}
+	return nil
+}
+
+// capacityUsage converts accumulated resources into amounts and percentages of the allocatable resources
+func capacityUsage(resources *nodeResources) models.CapacityUsage {
+	allocatable := resourceAmount(resources.allocatable)
+	result := models.CapacityUsage{
+		Allocatable: allocatable,
+		Requests:    resourceAmount(resources.requests),
+		Limits:      resourceAmount(resources.limits),
+		Pods:        resources.pods,
+	}
+	result.RequestsPercent = resourcePercent(result.Requests, allocatable)
+	result.LimitsPercent = resourcePercent(result.Limits, allocatable)
+	if resources.usage != nil {
+		usage := resourceAmount(resources.usage)
+		percent := resourcePercent(usage, allocatable)
+		result.Usage = &usage
+		result.UsagePercent = &percent
+	}
+	return result
+}
+
+// resourcePercent returns amount as a percentage of allocatable
+func resourcePercent(amount, allocatable models.ResourceAmount) models.ResourcePercent {
+	return models.ResourcePercent{
+		CPU:    usagePercent(amount.CPUMilli, allocatable.CPUMilli),
+		Memory: usagePercent(amount.MemoryBytes, allocatable.MemoryBytes),
+	}
+}
+
+// isNodeReady reports whether the Ready condition of a node is true
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
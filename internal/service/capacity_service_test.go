@@ -0,0 +1,152 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+const gi = 1024 * 1024 * 1024
+
+func testCapacityNode(name, cpu, memory string, ready, cordoned bool) *corev1.Node {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: cordoned},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func testCapacityPod(namespace, name, node, cpu, memory string) *corev1.Pod {
+	pod := testTopPod(namespace, name, cpu, memory)
+	pod.Spec.NodeName = node
+	return pod
+}
+
+func newTestCapacityListers(t *testing.T) *CapacityListers {
+	t.Helper()
+	finished := testCapacityPod("batch", "done", "node-1", "4", "4Gi")
+	finished.Status.Phase = corev1.PodSucceeded
+
+	clientset := fake.NewSimpleClientset(
+		testCapacityNode("node-1", "4", "8Gi", true, false),
+		testCapacityNode("node-2", "4", "8Gi", true, true),
+		testCapacityNode("node-3", "2", "4Gi", false, false),
+		testCapacityPod("team-a", "web-1", "node-1", "1", "2Gi"),
+		testCapacityPod("team-b", "web-2", "node-1", "1", "2Gi"),
+		testCapacityPod("batch", "job", "node-2", "2", "1Gi"),
+		testCapacityPod("team-a", "pending", "", "8", "8Gi"),
+		finished,
+	)
+	informers := k8s.NewInformerRegistry()
+	t.Cleanup(func() { informers.Stop("test") })
+	svc := NewCapacityService(informers)
+	listers, err := svc.ListersFor("test", clientset)
+	require.NoError(t, err)
+	return listers
+}
+
+func newTestNodeMetricsClient(items ...metricsv1beta1.NodeMetrics) *metricsfake.Clientset {
+	client := metricsfake.NewSimpleClientset()
+	client.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.NodeMetricsList{Items: items}, nil
+	})
+	return client
+}
+
+func testNodeMetrics(name, cpu, memory string) metricsv1beta1.NodeMetrics {
+	return metricsv1beta1.NodeMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Usage:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+	}
+}
+
+func TestCapacityService_Capacity(t *testing.T) {
+	listers := newTestCapacityListers(t)
+	metricsClient := newTestNodeMetricsClient(
+		testNodeMetrics("node-1", "1", "2Gi"),
+		testNodeMetrics("node-2", "2", "1Gi"),
+	)
+
+	result, err := NewCapacityService(nil).Capacity(listers, metricsClient)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Nodes)
+	assert.Equal(t, 1, result.SchedulableNodes)
+	assert.Equal(t, 1, result.PendingPods)
+	assert.Empty(t, result.MetricsError)
+
+	// Every node, including the cordoned and the not ready one
+	total := result.Total
+	assert.Equal(t, int64(10000), total.Allocatable.CPUMilli)
+	assert.Equal(t, int64(20*gi), total.Allocatable.MemoryBytes)
+	assert.Equal(t, int64(4000), total.Requests.CPUMilli, "finished and pending pods are not counted")
+	assert.Equal(t, int64(5*gi), total.Requests.MemoryBytes)
+	assert.Equal(t, int64(3*gi), total.Limits.MemoryBytes)
+	assert.Equal(t, 3, total.Pods)
+	assert.Equal(t, models.ResourcePercent{CPU: 40, Memory: 25}, total.RequestsPercent)
+	assert.Equal(t, models.ResourcePercent{CPU: 0, Memory: 15}, total.LimitsPercent)
+	require.NotNil(t, total.Usage)
+	assert.Equal(t, int64(3000), total.Usage.CPUMilli)
+	assert.Equal(t, models.ResourcePercent{CPU: 30, Memory: 15}, *total.UsagePercent)
+
+	// Only node-1 can take new pods
+	schedulable := result.Schedulable
+	assert.Equal(t, int64(4000), schedulable.Allocatable.CPUMilli)
+	assert.Equal(t, int64(8*gi), schedulable.Allocatable.MemoryBytes)
+	assert.Equal(t, int64(2000), schedulable.Requests.CPUMilli)
+	assert.Equal(t, 2, schedulable.Pods)
+	assert.Equal(t, models.ResourcePercent{CPU: 50, Memory: 50}, schedulable.RequestsPercent)
+	assert.Equal(t, models.ResourcePercent{CPU: 25, Memory: 25}, *schedulable.UsagePercent)
+
+	require.Len(t, result.NodeBreakdown, 3)
+	node1, node2, node3 := result.NodeBreakdown[0], result.NodeBreakdown[1], result.NodeBreakdown[2]
+	assert.Equal(t, "node-1", node1.Name)
+	assert.True(t, node1.Ready)
+	assert.True(t, node1.Schedulable)
+	assert.Equal(t, models.ResourcePercent{CPU: 50, Memory: 50}, node1.RequestsPercent)
+	assert.True(t, node2.Ready)
+	assert.False(t, node2.Schedulable, "cordoned")
+	assert.Equal(t, models.ResourcePercent{CPU: 50, Memory: 12.5}, node2.RequestsPercent)
+	assert.False(t, node3.Ready)
+	assert.False(t, node3.Schedulable)
+	assert.Zero(t, node3.Pods)
+	assert.Nil(t, node3.Usage, "no metrics for the node")
+}
+
+func TestCapacityService_WithoutMetrics(t *testing.T) {
+	listers := newTestCapacityListers(t)
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewServiceUnavailable("metrics-server is unavailable")
+	})
+
+	result, err := NewCapacityService(nil).Capacity(listers, metricsClient)
+	require.NoError(t, err, "missing metrics do not fail the report")
+	assert.Contains(t, result.MetricsError, "metrics API is not available")
+	assert.Nil(t, result.Total.Usage)
+	assert.Nil(t, result.Total.UsagePercent)
+	assert.Equal(t, int64(4000), result.Total.Requests.CPUMilli)
+
+	result, err = NewCapacityService(nil).Capacity(listers, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result.MetricsError)
+	assert.Nil(t, result.Schedulable.Usage)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// clusterSpecificFields are set differently by each cluster for the same manifest, and are ignored when
+// comparing clusters along with the server-managed fields
+var clusterSpecificFields = [][]string{
+	{"metadata", "annotations", "deployment.kubernetes.io/revision"},
+	{"metadata", "annotations", lastAppliedAnnotation},
+	{"spec", "clusterIP"},
+	{"spec", "clusterIPs"},
+}
+
+// CompareTarget is a cluster taking part in a comparison
+type CompareTarget struct {
+	ClusterID string
+	Client    dynamic.Interface
+	Mapper    meta.RESTMapper
+}
+
+// ComparedObject tells whether the compared object exists in one of the clusters
+type ComparedObject struct {
+	ClusterID       string `json:"clusterId"`
+	Found           bool   `json:"found"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// ResourceComparison is the result of comparing the same object in two clusters
+type ResourceComparison struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Resource   string         `json:"resource"`
+	Namespace  string         `json:"namespace,omitempty"`
+	Name       string         `json:"name"`
+	From       ComparedObject `json:"from"`
+	To         ComparedObject `json:"to"`
+	Identical  bool           `json:"identical"`
+	Changes    []FieldChange  `json:"changes"` // What turns the object of From into the one of To
+	Unified    string         `json:"unified"`
+}
+
+// Compare fetches the object of gvr named namespace/name from both clusters and returns how the one of to
+// differs from the one of from. Server-managed and cluster-specific fields such as a service's clusterIP are
+// ignored unless opts asks for them. An object missing from one cluster, or whose resource that cluster
+// doesn't serve, is reported as not found with all its fields added or removed; missing from both it is
+// a NotFound error.
+func (s *DiffService) Compare(ctx context.Context, from, to CompareTarget, gvr schema.GroupVersionResource, namespace, name string, opts DiffOptions) (*ResourceComparison, error) {
+	if gvr.Version == "" || gvr.Resource == "" {
+		return nil, fmt.Errorf("%w: version and resource are required", ErrInvalidResource)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidResource)
+	}
+
+	fromObject, err := compareObject(ctx, from, gvr, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("cluster %s: %w", from.ClusterID, err)
+	}
+	toObject, err := compareObject(ctx, to, gvr, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("cluster %s: %w", to.ClusterID, err)
+	}
+	if fromObject == nil && toObject == nil {
+		return nil, k8serrors.NewNotFound(gvr.GroupResource(), name)
+	}
+
+	result := &ResourceComparison{
+		APIVersion: gvr.GroupVersion().String(),
+		Resource:   gvr.Resource,
+		Namespace:  namespace,
+		Name:       name,
+		From:       comparedObject(from.ClusterID, fromObject),
+		To:         comparedObject(to.ClusterID, toObject),
+	}
+	for _, obj := range []*unstructured.Unstructured{fromObject, toObject} {
+		if obj != nil {
+			result.Kind = obj.GetKind()
+		}
+	}
+
+	fromFields, toFields := comparableFields(fromObject, opts), comparableFields(toObject, opts)
+	result.Changes = DiffObjects(fromFields, toFields)
+	result.Identical = result.From.Found && result.To.Found && len(result.Changes) == 0
+	result.Unified, err = unifiedDiff(fromFields, toFields, from.ClusterID, to.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// compareObject fetches the compared object from a cluster, nil when the cluster doesn't have it
+func compareObject(ctx context.Context, target CompareTarget, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	gvk, err := target.Mapper.KindFor(gvr)
+	if meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := target.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var ri dynamic.ResourceInterface = target.Client.Resource(mapping.Resource)
+	switch namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace; {
+	case namespaced && namespace == "":
+		return nil, fmt.Errorf("%w: %s is namespaced, a namespace is required", ErrResourceScopeMismatch, gvr.GroupResource())
+	case !namespaced && namespace != "":
+		return nil, fmt.Errorf("%w: %s is cluster-scoped", ErrResourceScopeMismatch, gvr.GroupResource())
+	case namespaced:
+		ri = target.Client.Resource(mapping.Resource).Namespace(namespace)
+	}
+
+	obj, err := ri.Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return obj, err
+}
+
+func comparedObject(clusterID string, obj *unstructured.Unstructured) ComparedObject {
+	if obj == nil {
+		return ComparedObject{ClusterID: clusterID}
+	}
+	return ComparedObject{ClusterID: clusterID, Found: true, ResourceVersion: obj.GetResourceVersion()}
+}
+
+// comparableFields returns the fields of obj to compare across clusters, nil for a missing object
+func comparableFields(obj *unstructured.Unstructured, opts DiffOptions) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	if opts.IncludeServerFields {
+		return obj.Object
+	}
+	fields := withoutServerFields(obj.Object)
+	for _, path := range clusterSpecificFields {
+		unstructured.RemoveNestedField(fields, path...)
+	}
+	if annotations, ok, _ := unstructured.NestedMap(fields, "metadata", "annotations"); ok && len(annotations) == 0 {
+		unstructured.RemoveNestedField(fields, "metadata", "annotations")
+	}
+	return fields
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newTestCompareTarget(clusterID string, objects ...runtime.Object) CompareTarget {
+	client, mapper := newTestDiffEnv(objects...)
+	return CompareTarget{ClusterID: clusterID, Client: client, Mapper: mapper}
+}
+
+func TestDiffService_CompareClusters(t *testing.T) {
+	deploymentsGVR := appsv1.SchemeGroupVersion.WithResource("deployments")
+	svc := NewDiffService()
+
+	staging := newTestDiffDeployment("nginx:1.27", 1)
+	staging.ResourceVersion, staging.UID = "812", "staging-uid"
+	staging.Annotations = map[string]string{"deployment.kubernetes.io/revision": "7"}
+	staging.Status.ReadyReplicas = 1
+	prod := newTestDiffDeployment("nginx:1.27", 3)
+	prod.ResourceVersion, prod.UID = "90411", "prod-uid"
+	prod.Annotations = map[string]string{"deployment.kubernetes.io/revision": "2"}
+	prod.Status.ReadyReplicas = 3
+
+	from := newTestCompareTarget("staging", toUnstructured(t, staging))
+	to := newTestCompareTarget("prod", toUnstructured(t, prod))
+
+	comparison, err := svc.Compare(context.Background(), from, to, deploymentsGVR, "default", "web", DiffOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment", comparison.Kind)
+	assert.Equal(t, "apps/v1", comparison.APIVersion)
+	assert.Equal(t, ComparedObject{ClusterID: "staging", Found: true, ResourceVersion: "812"}, comparison.From)
+	assert.Equal(t, ComparedObject{ClusterID: "prod", Found: true, ResourceVersion: "90411"}, comparison.To)
+	assert.False(t, comparison.Identical)
+	assert.Equal(t, []FieldChange{{Path: "spec.replicas", Type: FieldChanged, Old: int64(1), New: int64(3)}}, comparison.Changes,
+		"server-managed and cluster-specific fields are ignored")
+	assert.Contains(t, comparison.Unified, "--- staging")
+	assert.Contains(t, comparison.Unified, "-  replicas: 1")
+	assert.Contains(t, comparison.Unified, "+  replicas: 3")
+
+	t.Run("server fields on request", func(t *testing.T) {
+		comparison, err := svc.Compare(context.Background(), from, to, deploymentsGVR, "default", "web", DiffOptions{IncludeServerFields: true})
+		require.NoError(t, err)
+		changes := changesByPath(comparison.Changes)
+		assert.Contains(t, changes, "status.readyReplicas")
+		assert.Contains(t, changes, "metadata.resourceVersion")
+	})
+
+	t.Run("identical objects", func(t *testing.T) {
+		comparison, err := svc.Compare(context.Background(), from, from, deploymentsGVR, "default", "web", DiffOptions{})
+		require.NoError(t, err)
+		assert.True(t, comparison.Identical)
+		assert.Empty(t, comparison.Changes)
+	})
+
+	t.Run("missing in one cluster", func(t *testing.T) {
+		empty := newTestCompareTarget("dev")
+		comparison, err := svc.Compare(context.Background(), empty, to, deploymentsGVR, "default", "web", DiffOptions{})
+		require.NoError(t, err)
+		assert.False(t, comparison.From.Found)
+		assert.True(t, comparison.To.Found)
+		assert.False(t, comparison.Identical)
+		assert.Equal(t, FieldAdded, changesByPath(comparison.Changes)["spec"].Type)
+
+		// A cluster that doesn't serve the resource at all doesn't have the object either
+		_, widgetsOnly := newTestCustomResourceEnv()
+		unserved := CompareTarget{ClusterID: "edge", Client: empty.Client, Mapper: widgetsOnly}
+		comparison, err = svc.Compare(context.Background(), to, unserved, deploymentsGVR, "default", "web", DiffOptions{})
+		require.NoError(t, err)
+		assert.False(t, comparison.To.Found)
+		assert.Equal(t, FieldRemoved, changesByPath(comparison.Changes)["spec"].Type)
+
+		_, err = svc.Compare(context.Background(), empty, unserved, deploymentsGVR, "default", "web", DiffOptions{})
+		assert.True(t, k8serrors.IsNotFound(err), "missing from both clusters")
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := svc.Compare(context.Background(), from, to, deploymentsGVR, "", "web", DiffOptions{})
+		assert.True(t, errors.Is(err, ErrResourceScopeMismatch), "deployments need a namespace")
+		_, err = svc.Compare(context.Background(), from, to, deploymentsGVR, "default", "", DiffOptions{})
+		assert.True(t, errors.Is(err, ErrInvalidResource))
+	})
+}
@@ -25,20 +25,22 @@ func NewClusterService(k8sManager *k8s.ClusterManager) *ClusterService {
 	}
 }
 
-// ListClusters returns a list of summary information for all managed clusters.
-func (s *ClusterService) ListClusters() []models.ClusterListResponse {
+// ListClusters returns a list of summary information for the managed clusters matching the filter.
+func (s *ClusterService) ListClusters(filter k8s.ClusterFilter) []models.ClusterListResponse {
 	// The information structure returned by k8sManager is already suitable for the list page, we just convert it
-	managerInfo := s.k8sManager.ListClusterInfo()
+	managerInfo := s.k8sManager.ListClusterInfoFiltered(filter)
 	response := make([]models.ClusterListResponse, len(managerInfo))
 	for i, info := range managerInfo {
 		response[i] = models.ClusterListResponse{
-			ID:          info.ID, // Ensure k8s.ClusterInfoResponse has ID field
-			Name:        info.Name,
-			Server:      info.Server,
-			Version:     info.Version,
-			Status:      info.Status,
-			Source:      info.Source,
-			Environment: info.Environment,
+			ID:                  info.ID, // Ensure k8s.ClusterInfoResponse has ID field
+			Name:                info.Name,
+			Server:              info.Server,
+			Version:             info.Version,
+			Status:              info.Status,
+			Source:              info.Source,
+			Environment:         info.Environment,
+			CredentialExpiresAt: info.CredentialExpiresAt,
+			Warning:             info.Warning,
 		}
 	}
 	return response
@@ -51,18 +53,20 @@ func (s *ClusterService) GetClusterByID(id string) (*models.ClusterResponse, err
 		// If not in database, it might be a file-type cluster, we assemble a simple version from cache
 		if info, ok := s.k8sManager.GetStatusFromCache(id); ok {
 			return &models.ClusterResponse{
-				ID:          info.ID,
-				Name:        info.Name,
-				Version:     info.Version,
-				Status:      info.Status,
-				Environment: info.Environment,
-				Source:      info.Source,
+				ID:                  info.ID,
+				Name:                info.Name,
+				Version:             info.Version,
+				Status:              info.Status,
+				Environment:         info.Environment,
+				Source:              info.Source,
+				CredentialExpiresAt: info.CredentialExpiresAt,
+				Warning:             info.Warning,
 			}, nil
 		}
 		return nil, fmt.Errorf("cluster ID '%s' not found: %w", id, err)
 	}
 
-	return &models.ClusterResponse{
+	response := &models.ClusterResponse{
 		ID:          cluster.ID,
 		Name:        cluster.Name,
 		Provider:    cluster.Provider,
@@ -74,7 +78,12 @@ func (s *ClusterService) GetClusterByID(id string) (*models.ClusterResponse, err
 		Labels:      cluster.Labels,
 		CreatedAt:   cluster.CreatedAt,
 		UpdatedAt:   cluster.UpdatedAt,
-	}, nil
+	}
+	if info, ok := s.k8sManager.GetStatusFromCache(id); ok {
+		response.CredentialExpiresAt = info.CredentialExpiresAt
+		response.Warning = info.Warning
+	}
+	return response, nil
 }
 
 // CreateCluster handles the logic for creating a new cluster.
@@ -85,7 +94,10 @@ func (s *ClusterService) CreateCluster(req models.CreateClusterRequest) error {
 		return fmt.Errorf("invalid kubeconfig: %w", err)
 	}
 
-	// 2. Test connection
+	// 2. Test connection, which runs the exec credential plugin if the kubeconfig has one
+	if err := s.k8sManager.CheckExecPlugin(config); err != nil {
+		return err
+	}
 	if err := s.testConnection(config); err != nil {
 		return fmt.Errorf("failed to connect to cluster: %w", err)
 	}
@@ -102,6 +114,8 @@ func (s *ClusterService) CreateCluster(req models.CreateClusterRequest) error {
 		Description:    req.Description,
 		Environment:    req.Environment,
 		Region:         req.Region,
+		QPS:            req.QPS,
+		Burst:          req.Burst,
 	}
 	return s.k8sManager.AddDBCluster(cluster)
 }
@@ -116,6 +130,12 @@ func (s *ClusterService) DeleteClusterByID(id string) error {
 	return s.k8sManager.RemoveDBClusterByID(id)
 }
 
+// ReloadCluster rebuilds the client of a cluster from its current kubeconfig, keeping the previous client
+// when the new one cannot reach the API server.
+func (s *ClusterService) ReloadCluster(id string) (k8s.ClusterInfoResponse, error) {
+	return s.k8sManager.ReloadClient(id)
+}
+
 // SetActiveCluster handles the logic for switching the active cluster.
 func (s *ClusterService) SetActiveCluster(id string) error {
 	return s.k8sManager.SetActiveClusterByID(id)
@@ -154,10 +174,11 @@ func (s *ClusterService) testConnection(config *rest.Config) error {
 			Insecure: true,
 		},
 		// Preserve authentication information (if any)
-		Username:    config.Username,
-		Password:    config.Password,
-		BearerToken: config.BearerToken,
-		Timeout:     config.Timeout,
+		Username:     config.Username,
+		Password:     config.Password,
+		BearerToken:  config.BearerToken,
+		ExecProvider: config.ExecProvider,
+		Timeout:      config.Timeout,
 	}
 
 	// Create a clientset
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ContainerInfoService inspects the container images, resources and env of workloads
+type ContainerInfoService struct{}
+
+// NewContainerInfoService creates a new ContainerInfoService instance
+func NewContainerInfoService() *ContainerInfoService {
+	return &ContainerInfoService{}
+}
+
+// GetContainers returns the containers of the pod template of a workload, or of a pod. resource is one of
+// pods, deployments, statefulsets, daemonsets, replicasets, jobs and cronjobs. When maskValues is set literal
+// env values are replaced by models.MaskedEnvValue.
+func (s *ContainerInfoService) GetContainers(ctx context.Context, clientset kubernetes.Interface, resource, namespace, name string, maskValues bool) (*models.WorkloadContainersResponse, error) {
+	kind, spec, err := s.podSpec(ctx, clientset, resource, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	response := podSpecContainers(spec, maskValues)
+	response.Kind = kind
+	response.Namespace = namespace
+	response.Name = name
+	return response, nil
+}
+
+// podSpec fetches the workload and returns its kind and pod spec
+func (s *ContainerInfoService) podSpec(ctx context.Context, clientset kubernetes.Interface, resource, namespace, name string) (string, *corev1.PodSpec, error) {
+	opts := metav1.GetOptions{}
+	switch resource {
+	case "pods":
+		pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		return "Pod", &pod.Spec, nil
+	case "deployments":
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		return "Deployment", &deployment.Spec.Template.Spec, nil
+	case "statefulsets":
+		statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		return "StatefulSet", &statefulSet.Spec.Template.Spec, nil
+	case "daemonsets":
+		daemonSet, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		return "DaemonSet", &daemonSet.Spec.Template.Spec, nil
+	case "replicasets":
+		replicaSet, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, name, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		return "ReplicaSet", &replicaSet.Spec.Template.Spec, nil
+	case "jobs":
+		job, err := clientset.BatchV1().Jobs(namespace).Get(ctx, name, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		return "Job", &job.Spec.Template.Spec, nil
+	case "cronjobs":
+		cronJob, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, name, opts)
+		if err != nil {
+			return "", nil, err
+		}
+		return "CronJob", &cronJob.Spec.JobTemplate.Spec.Template.Spec, nil
+	}
+	return "", nil, fmt.Errorf("%w: %s have no pod template", ErrInvalidResource, resource)
+}
+
+// podSpecContainers describes the init and regular containers of a pod spec
+func podSpecContainers(spec *corev1.PodSpec, maskValues bool) *models.WorkloadContainersResponse {
+	response := &models.WorkloadContainersResponse{
+		Containers: make([]models.ContainerInfo, 0, len(spec.InitContainers)+len(spec.Containers)),
+	}
+	for _, secret := range spec.ImagePullSecrets {
+		response.ImagePullSecrets = append(response.ImagePullSecrets, secret.Name)
+	}
+	for _, container := range spec.InitContainers {
+		info := containerInfo(&container, maskValues)
+		info.Init = true
+		response.Containers = append(response.Containers, info)
+	}
+	for _, container := range spec.Containers {
+		response.Containers = append(response.Containers, containerInfo(&container, maskValues))
+	}
+
+	for _, container := range response.Containers {
+		for _, env := range container.Env {
+			if env.Secret {
+				response.SecretRefs++
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.Secret {
+				response.SecretRefs++
+			}
+		}
+	}
+	return response
+}
+
+// containerInfo describes a single container
+func containerInfo(container *corev1.Container, maskValues bool) models.ContainerInfo {
+	info := models.ContainerInfo{
+		Name:            container.Name,
+		Image:           container.Image,
+		ImagePullPolicy: string(container.ImagePullPolicy),
+		Resources: models.ContainerResources{
+			Requests: resourceListStrings(container.Resources.Requests),
+			Limits:   resourceListStrings(container.Resources.Limits),
+		},
+		Env:     make([]models.ContainerEnvVar, 0, len(container.Env)),
+		EnvFrom: make([]models.ContainerEnvFrom, 0, len(container.EnvFrom)),
+	}
+	for _, env := range container.Env {
+		info.Env = append(info.Env, containerEnvVar(env, maskValues))
+	}
+	for _, envFrom := range container.EnvFrom {
+		switch {
+		case envFrom.SecretRef != nil:
+			info.EnvFrom = append(info.EnvFrom, models.ContainerEnvFrom{
+				Source:   models.EnvSourceSecretRef,
+				Name:     envFrom.SecretRef.Name,
+				Prefix:   envFrom.Prefix,
+				Optional: isOptional(envFrom.SecretRef.Optional),
+				Secret:   true,
+			})
+		case envFrom.ConfigMapRef != nil:
+			info.EnvFrom = append(info.EnvFrom, models.ContainerEnvFrom{
+				Source:   models.EnvSourceConfigMapRef,
+				Name:     envFrom.ConfigMapRef.Name,
+				Prefix:   envFrom.Prefix,
+				Optional: isOptional(envFrom.ConfigMapRef.Optional),
+			})
+		}
+	}
+	return info
+}
+
+// containerEnvVar describes an env variable and flags values read from Secrets
+func containerEnvVar(env corev1.EnvVar, maskValues bool) models.ContainerEnvVar {
+	item := models.ContainerEnvVar{Name: env.Name}
+	from := env.ValueFrom
+	switch {
+	case from == nil:
+		item.Source = models.EnvSourceValue
+		item.Value = env.Value
+		if maskValues && env.Value != "" {
+			item.Value = models.MaskedEnvValue
+			item.Masked = true
+		}
+	case from.SecretKeyRef != nil:
+		item.Source = models.EnvSourceSecretKeyRef
+		item.Ref = from.SecretKeyRef.Name + "/" + from.SecretKeyRef.Key
+		item.Optional = isOptional(from.SecretKeyRef.Optional)
+		item.Secret = true
+	case from.ConfigMapKeyRef != nil:
+		item.Source = models.EnvSourceConfigMapKeyRef
+		item.Ref = from.ConfigMapKeyRef.Name + "/" + from.ConfigMapKeyRef.Key
+		item.Optional = isOptional(from.ConfigMapKeyRef.Optional)
+	case from.FieldRef != nil:
+		item.Source = models.EnvSourceFieldRef
+		item.Ref = from.FieldRef.FieldPath
+	case from.ResourceFieldRef != nil:
+		item.Source = models.EnvSourceResourceFieldRef
+		item.Ref = from.ResourceFieldRef.Resource
+	}
+	return item
+}
+
+func resourceListStrings(resources corev1.ResourceList) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		values[string(name)] = quantity.String()
+	}
+	return values
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestMultiContainerPodSpec() corev1.PodSpec {
+	optional := true
+	return corev1.PodSpec{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		InitContainers: []corev1.Container{{
+			Name:  "migrate",
+			Image: "registry.example.com/web-migrate:1.4.0",
+			Env:   []corev1.EnvVar{{Name: "MODE", Value: "up"}},
+		}},
+		Containers: []corev1.Container{
+			{
+				Name:            "web",
+				Image:           "registry.example.com/web:1.4.0",
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+				},
+				Env: []corev1.EnvVar{
+					{Name: "LOG_LEVEL", Value: "debug"},
+					{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password",
+					}}},
+					{Name: "FEATURES", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "flags"}, Key: "features", Optional: &optional,
+					}}},
+					{Name: "POD_IP", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"}}},
+				},
+				EnvFrom: []corev1.EnvFromSource{
+					{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "api-keys"}}},
+					{Prefix: "APP_", ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app"}}},
+				},
+			},
+			{Name: "proxy", Image: "envoyproxy/envoy:v1.31.0", ImagePullPolicy: corev1.PullAlways},
+		},
+	}
+}
+
+func TestContainerInfoService_Deployment(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: newTestMultiContainerPodSpec()}},
+	}
+	clientset := fake.NewSimpleClientset(deployment)
+
+	result, err := NewContainerInfoService().GetContainers(context.Background(), clientset, "deployments", "test", "web", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment", result.Kind)
+	assert.Equal(t, []string{"registry"}, result.ImagePullSecrets)
+	assert.Equal(t, 2, result.SecretRefs, "one secretKeyRef and one envFrom secret")
+	require.Len(t, result.Containers, 3)
+
+	migrate := result.Containers[0]
+	assert.True(t, migrate.Init)
+	assert.Equal(t, "registry.example.com/web-migrate:1.4.0", migrate.Image)
+
+	web := result.Containers[1]
+	assert.False(t, web.Init)
+	assert.Equal(t, "IfNotPresent", web.ImagePullPolicy)
+	assert.Equal(t, map[string]string{"cpu": "250m", "memory": "256Mi"}, web.Resources.Requests)
+	assert.Equal(t, map[string]string{"memory": "512Mi"}, web.Resources.Limits)
+	assert.Equal(t, []models.ContainerEnvVar{
+		{Name: "LOG_LEVEL", Source: models.EnvSourceValue, Value: "debug"},
+		{Name: "DB_PASSWORD", Source: models.EnvSourceSecretKeyRef, Ref: "db/password", Secret: true},
+		{Name: "FEATURES", Source: models.EnvSourceConfigMapKeyRef, Ref: "flags/features", Optional: true},
+		{Name: "POD_IP", Source: models.EnvSourceFieldRef, Ref: "status.podIP"},
+	}, web.Env)
+	assert.Equal(t, []models.ContainerEnvFrom{
+		{Source: models.EnvSourceSecretRef, Name: "api-keys", Secret: true},
+		{Source: models.EnvSourceConfigMapRef, Name: "app", Prefix: "APP_"},
+	}, web.EnvFrom)
+
+	proxy := result.Containers[2]
+	assert.Equal(t, "envoyproxy/envoy:v1.31.0", proxy.Image)
+	assert.Empty(t, proxy.Env)
+	assert.Nil(t, proxy.Resources.Requests)
+}
+
+func TestContainerInfoService_MaskValues(t *testing.T) {
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "test"},
+		Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{Spec: newTestMultiContainerPodSpec()},
+		}}},
+	}
+	clientset := fake.NewSimpleClientset(cronJob)
+
+	result, err := NewContainerInfoService().GetContainers(context.Background(), clientset, "cronjobs", "test", "report", true)
+	require.NoError(t, err)
+	assert.Equal(t, "CronJob", result.Kind)
+	env := result.Containers[1].Env
+	assert.Equal(t, models.ContainerEnvVar{Name: "LOG_LEVEL", Source: models.EnvSourceValue, Value: models.MaskedEnvValue, Masked: true}, env[0])
+	assert.Equal(t, "db/password", env[1].Ref, "references are not masked")
+}
+
+func TestContainerInfoService_Errors(t *testing.T) {
+	svc := NewContainerInfoService()
+	clientset := fake.NewSimpleClientset()
+
+	_, err := svc.GetContainers(context.Background(), clientset, "services", "test", "web", false)
+	assert.ErrorIs(t, err, ErrInvalidResource)
+
+	_, err = svc.GetContainers(context.Background(), clientset, "statefulsets", "test", "missing", false)
+	assert.True(t, k8serrors.IsNotFound(err))
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// ErrResourceScopeMismatch is returned when a namespaced resource is addressed without a namespace or vice versa
+var ErrResourceScopeMismatch = errors.New("resource scope does not match request path")
+
+// CustomResourceRef identifies a custom resource collection or object
+type CustomResourceRef struct {
+	Group     string
+	Version   string
+	Plural    string
+	Namespace string // Empty for cluster-scoped resources
+	Name      string // Empty for collection requests
+}
+
+// CustomResourceService provides generic CRUD for custom resources through the dynamic client.
+// Resources are resolved with a per-cluster discovery-backed RESTMapper.
+type CustomResourceService struct {
+	mu      sync.Mutex
+	mappers map[string]meta.ResettableRESTMapper
+}
+
+// NewCustomResourceService creates a new CustomResourceService instance
+func NewCustomResourceService() *CustomResourceService {
+	return &CustomResourceService{
+		mappers: make(map[string]meta.ResettableRESTMapper),
+	}
+}
+
+// MapperFor returns the RESTMapper of a cluster, creating it on first use.
+// The mapper caches discovery and refreshes itself when a resource is not found, so newly installed CRDs are picked up.
+func (s *CustomResourceService) MapperFor(clusterID string, discoveryClient discovery.DiscoveryInterface) meta.RESTMapper {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if mapper, ok := s.mappers[clusterID]; ok {
+		return mapper
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	s.mappers[clusterID] = mapper
+	return mapper
+}
+
+// ForgetCluster drops the cached RESTMapper of a cluster
+func (s *CustomResourceService) ForgetCluster(clusterID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mappers, clusterID)
+}
+
+// resolve maps the reference to its RESTMapping and checks that the request scope matches the resource scope
+func (s *CustomResourceService) resolve(mapper meta.RESTMapper, ref CustomResourceRef) (*meta.RESTMapping, error) {
+	gvr := schema.GroupVersionResource{Group: ref.Group, Version: ref.Version, Resource: ref.Plural}
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	if namespaced && ref.Namespace == "" {
+		return nil, fmt.Errorf("%w: %s is namespaced", ErrResourceScopeMismatch, gvr.GroupResource())
+	}
+	if !namespaced && ref.Namespace != "" {
+		return nil, fmt.Errorf("%w: %s is cluster-scoped", ErrResourceScopeMismatch, gvr.GroupResource())
+	}
+	return mapping, nil
+}
+
+// resourceInterface returns the dynamic client interface for the reference
+func (s *CustomResourceService) resourceInterface(client dynamic.Interface, mapper meta.RESTMapper, ref CustomResourceRef) (dynamic.ResourceInterface, *meta.RESTMapping, error) {
+	mapping, err := s.resolve(mapper, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ref.Namespace != "" {
+		return client.Resource(mapping.Resource).Namespace(ref.Namespace), mapping, nil
+	}
+	return client.Resource(mapping.Resource), mapping, nil
+}
+
+// List lists the custom resources of a collection
+func (s *CustomResourceService) List(client dynamic.Interface, mapper meta.RESTMapper, ref CustomResourceRef, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	ri, _, err := s.resourceInterface(client, mapper, ref)
+	if err != nil {
+		return nil, err
+	}
+	return ri.List(context.TODO(), opts)
+}
+
+// Get retrieves a single custom resource
+func (s *CustomResourceService) Get(client dynamic.Interface, mapper meta.RESTMapper, ref CustomResourceRef) (*unstructured.Unstructured, error) {
+	ri, _, err := s.resourceInterface(client, mapper, ref)
+	if err != nil {
+		return nil, err
+	}
+	return ri.Get(context.TODO(), ref.Name, metav1.GetOptions{})
+}
+
+// Create creates a custom resource. apiVersion, kind and namespace default to the values of the request path.
+func (s *CustomResourceService) Create(client dynamic.Interface, mapper meta.RESTMapper, ref CustomResourceRef, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	ri, mapping, err := s.resourceInterface(client, mapper, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareCustomResource(obj, mapping, ref); err != nil {
+		return nil, err
+	}
+	return ri.Create(context.TODO(), obj, metav1.CreateOptions{})
+}
+
+// Update replaces a custom resource. The API server rejects the update with a conflict when the
+// resourceVersion of obj is no longer the current one.
+func (s *CustomResourceService) Update(client dynamic.Interface, mapper meta.RESTMapper, ref CustomResourceRef, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	ri, mapping, err := s.resourceInterface(client, mapper, ref)
+	if err != nil {
+		return nil, err
+	}
+	if obj.GetName() == "" {
+		obj.SetName(ref.Name)
+	}
+	if obj.GetName() != ref.Name {
+		return nil, fmt.Errorf("%w: metadata.name %q does not match %q", ErrInvalidResource, obj.GetName(), ref.Name)
+	}
+	if err := prepareCustomResource(obj, mapping, ref); err != nil {
+		return nil, err
+	}
+	return ri.Update(context.TODO(), obj, metav1.UpdateOptions{})
+}
+
+// Delete deletes a custom resource
+func (s *CustomResourceService) Delete(client dynamic.Interface, mapper meta.RESTMapper, ref CustomResourceRef) error {
+	ri, _, err := s.resourceInterface(client, mapper, ref)
+	if err != nil {
+		return err
+	}
+	return ri.Delete(context.TODO(), ref.Name, metav1.DeleteOptions{})
+}
+
+// prepareCustomResource fills in type and namespace from the request path and rejects conflicting values
+func prepareCustomResource(obj *unstructured.Unstructured, mapping *meta.RESTMapping, ref CustomResourceRef) error {
+	gvk := mapping.GroupVersionKind
+	if obj.GetAPIVersion() == "" {
+		obj.SetAPIVersion(gvk.GroupVersion().String())
+	}
+	if obj.GetKind() == "" {
+		obj.SetKind(gvk.Kind)
+	}
+	if obj.GroupVersionKind() != gvk {
+		return fmt.Errorf("%w: object type %s does not match %s", ErrInvalidResource, obj.GroupVersionKind(), gvk)
+	}
+
+	if ref.Namespace == "" {
+		if obj.GetNamespace() != "" {
+			return fmt.Errorf("%w: cluster-scoped resource must not set metadata.namespace", ErrInvalidResource)
+		}
+		return nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(ref.Namespace)
+	}
+	if obj.GetNamespace() != ref.Namespace {
+		return fmt.Errorf("%w: metadata.namespace %q does not match %q", ErrInvalidResource, obj.GetNamespace(), ref.Namespace)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	widgetGVR  = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	clusterGVR = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "gadgets"}
+)
+
+func newTestCustomResource(kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("example.com/v1")
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	_ = unstructured.SetNestedField(obj.Object, int64(3), "spec", "size")
+	return obj
+}
+
+func newTestCustomResourceEnv(objects ...runtime.Object) (*dynamicfake.FakeDynamicClient, meta.RESTMapper) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Group: "example.com", Version: "v1"}})
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gadget"}, meta.RESTScopeRoot)
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		widgetGVR:  "WidgetList",
+		clusterGVR: "GadgetList",
+	}, objects...)
+	return client, mapper
+}
+
+func TestCustomResourceService_NamespacedCRUD(t *testing.T) {
+	client, mapper := newTestCustomResourceEnv(newTestCustomResource("Widget", "default", "existing"))
+	svc := NewCustomResourceService()
+	ref := CustomResourceRef{Group: "example.com", Version: "v1", Plural: "widgets", Namespace: "default"}
+
+	list, err := svc.List(client, mapper, ref, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, list.Items, 1)
+
+	// apiVersion, kind and namespace default to the request path
+	body := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "created"},
+	}}
+	created, err := svc.Create(client, mapper, ref, body)
+	require.NoError(t, err)
+	assert.Equal(t, "Widget", created.GetKind())
+	assert.Equal(t, "default", created.GetNamespace())
+
+	ref.Name = "existing"
+	update := newTestCustomResource("Widget", "", "")
+	_ = unstructured.SetNestedField(update.Object, int64(5), "spec", "size")
+	updated, err := svc.Update(client, mapper, ref, update)
+	require.NoError(t, err)
+	size, _, _ := unstructured.NestedInt64(updated.Object, "spec", "size")
+	assert.Equal(t, int64(5), size)
+
+	require.NoError(t, svc.Delete(client, mapper, ref))
+	_, err = svc.Get(client, mapper, ref)
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestCustomResourceService_ClusterScoped(t *testing.T) {
+	client, mapper := newTestCustomResourceEnv(newTestCustomResource("Gadget", "", "global"))
+	svc := NewCustomResourceService()
+
+	obj, err := svc.Get(client, mapper, CustomResourceRef{Group: "example.com", Version: "v1", Plural: "gadgets", Name: "global"})
+	require.NoError(t, err)
+	assert.Equal(t, "global", obj.GetName())
+
+	// A cluster-scoped resource cannot be addressed through a namespace
+	_, err = svc.Get(client, mapper, CustomResourceRef{Group: "example.com", Version: "v1", Plural: "gadgets", Namespace: "default", Name: "global"})
+	assert.True(t, errors.Is(err, ErrResourceScopeMismatch))
+
+	// A namespaced resource requires a namespace
+	_, err = svc.List(client, mapper, CustomResourceRef{Group: "example.com", Version: "v1", Plural: "widgets"}, metav1.ListOptions{})
+	assert.True(t, errors.Is(err, ErrResourceScopeMismatch))
+}
+
+func TestCustomResourceService_Errors(t *testing.T) {
+	client, mapper := newTestCustomResourceEnv()
+	svc := NewCustomResourceService()
+
+	_, err := svc.List(client, mapper, CustomResourceRef{Group: "example.com", Version: "v1", Plural: "unknowns", Namespace: "default"}, metav1.ListOptions{})
+	assert.True(t, meta.IsNoMatchError(err))
+
+	ref := CustomResourceRef{Group: "example.com", Version: "v1", Plural: "widgets", Namespace: "default", Name: "w"}
+	_, err = svc.Update(client, mapper, ref, newTestCustomResource("Widget", "default", "other"))
+	assert.True(t, errors.Is(err, ErrInvalidResource))
+
+	_, err = svc.Create(client, mapper, ref, newTestCustomResource("Widget", "kube-system", "w"))
+	assert.True(t, errors.Is(err, ErrInvalidResource))
+
+	client.PrependReactor("get", "widgets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewForbidden(widgetGVR.GroupResource(), "w", errors.New("denied"))
+	})
+	_, err = svc.Get(client, mapper, ref)
+	assert.True(t, k8serrors.IsForbidden(err))
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DaemonSetItemFrom summarizes a DaemonSet with the rollout numbers of its status
+func DaemonSetItemFrom(ds *appsv1.DaemonSet) models.DaemonSetItem {
+	status := ds.Status
+	item := models.DaemonSetItem{
+		Name:                   ds.Name,
+		Namespace:              ds.Namespace,
+		DesiredNumberScheduled: status.DesiredNumberScheduled,
+		CurrentNumberScheduled: status.CurrentNumberScheduled,
+		NumberReady:            status.NumberReady,
+		UpdatedNumberScheduled: status.UpdatedNumberScheduled,
+		NumberAvailable:        status.NumberAvailable,
+		NumberUnavailable:      status.NumberUnavailable,
+		NumberMisscheduled:     status.NumberMisscheduled,
+		UpdateStrategy:         string(ds.Spec.UpdateStrategy.Type),
+		// As kubectl rollout status: the controller saw the current template and every node runs it available
+		RolloutComplete: status.ObservedGeneration >= ds.Generation &&
+			status.UpdatedNumberScheduled >= status.DesiredNumberScheduled &&
+			status.NumberAvailable >= status.DesiredNumberScheduled,
+		CreatedAt: ds.CreationTimestamp,
+	}
+	if rollingUpdate := ds.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil {
+		if rollingUpdate.MaxUnavailable != nil {
+			item.MaxUnavailable = rollingUpdate.MaxUnavailable.String()
+		}
+		if rollingUpdate.MaxSurge != nil {
+			item.MaxSurge = rollingUpdate.MaxSurge.String()
+		}
+	}
+	return item
+}
+
+// ListDaemonSetItems lists the DaemonSets of a namespace, all namespaces when empty, with their rollout progress
+func ListDaemonSetItems(ctx context.Context, clientset kubernetes.Interface, namespace string) (*models.DaemonSetListResponse, error) {
+	list, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemon sets: %w", err)
+	}
+
+	items := make([]models.DaemonSetItem, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, DaemonSetItemFrom(&list.Items[i]))
+	}
+	return &models.DaemonSetListResponse{
+		Items: items,
+		Total: len(items),
+	}, nil
+}
+
+// GetDaemonSetItem returns a DaemonSet with its rollout progress
+func GetDaemonSetItem(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*models.DaemonSetItem, error) {
+	ds, err := new(DaemonSetClient).Get(ctx, clientset, namespace, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	item := DaemonSetItemFrom(ds)
+	return &item, nil
+}
+
+// SetDaemonSetUpdateStrategy replaces the update strategy of a DaemonSet with RollingUpdate, optionally
+// limited by maxUnavailable and maxSurge, or OnDelete, which takes no limits
+func SetDaemonSetUpdateStrategy(ctx context.Context, clientset kubernetes.Interface, namespace, name string, req *models.UpdateDaemonSetStrategyRequest) (*appsv1.DaemonSet, error) {
+	strategy, err := daemonSetUpdateStrategy(req)
+	if err != nil {
+		return nil, err
+	}
+
+	client := new(DaemonSetClient)
+	ds, err := client.Get(ctx, clientset, namespace, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ds.Spec.UpdateStrategy = strategy
+	return client.Update(ctx, clientset, namespace, ds, metav1.UpdateOptions{})
+}
+
+// daemonSetUpdateStrategy validates a strategy request and builds the strategy it describes
+func daemonSetUpdateStrategy(req *models.UpdateDaemonSetStrategyRequest) (appsv1.DaemonSetUpdateStrategy, error) {
+	switch appsv1.DaemonSetUpdateStrategyType(req.Type) {
+	case appsv1.OnDeleteDaemonSetStrategyType:
+		if req.MaxUnavailable != "" || req.MaxSurge != "" {
+			return appsv1.DaemonSetUpdateStrategy{}, fmt.Errorf("%w: maxUnavailable and maxSurge only apply to the RollingUpdate strategy", ErrInvalidResource)
+		}
+		return appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}, nil
+	case appsv1.RollingUpdateDaemonSetStrategyType:
+		rollingUpdate := &appsv1.RollingUpdateDaemonSet{}
+		var err error
+		if rollingUpdate.MaxUnavailable, err = parseRollingUpdateLimit("maxUnavailable", req.MaxUnavailable); err != nil {
+			return appsv1.DaemonSetUpdateStrategy{}, err
+		}
+		if rollingUpdate.MaxSurge, err = parseRollingUpdateLimit("maxSurge", req.MaxSurge); err != nil {
+			return appsv1.DaemonSetUpdateStrategy{}, err
+		}
+		// The API server defaults maxUnavailable to 1 and maxSurge to 0, and rejects both being zero
+		if rollingUpdate.MaxUnavailable != nil && isZeroLimit(rollingUpdate.MaxUnavailable) &&
+			(rollingUpdate.MaxSurge == nil || isZeroLimit(rollingUpdate.MaxSurge)) {
+			return appsv1.DaemonSetUpdateStrategy{}, fmt.Errorf("%w: maxUnavailable and maxSurge cannot both be zero", ErrInvalidResource)
+		}
+		return appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType, RollingUpdate: rollingUpdate}, nil
+	default:
+		return appsv1.DaemonSetUpdateStrategy{}, fmt.Errorf("%w: update strategy must be RollingUpdate or OnDelete, got '%s'", ErrInvalidResource, req.Type)
+	}
+}
+
+// parseRollingUpdateLimit parses a rolling update limit given as a number of nodes or a percentage,
+// returning nil when it is empty
+func parseRollingUpdateLimit(field, value string) (*intstr.IntOrString, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	limit := intstr.Parse(value)
+	if limit.Type == intstr.String && !strings.HasSuffix(value, "%") {
+		return nil, fmt.Errorf("%w: %s must be a number or a percentage, got '%s'", ErrInvalidResource, field, value)
+	}
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(&limit, 100, true)
+	if err != nil || scaled < 0 || (limit.Type == intstr.String && scaled > 100) {
+		return nil, fmt.Errorf("%w: %s must be a non-negative number or a percentage up to 100%%, got '%s'", ErrInvalidResource, field, value)
+	}
+	return &limit, nil
+}
+
+// isZeroLimit reports whether a parsed rolling update limit is 0 or 0%
+func isZeroLimit(limit *intstr.IntOrString) bool {
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(limit, 100, true)
+	return err == nil && scaled == 0
+}
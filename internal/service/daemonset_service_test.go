@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestDaemonSet(name string, generation int64, status appsv1.DaemonSetStatus) *appsv1.DaemonSet {
+	maxUnavailable := intstr.FromString("25%")
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", Generation: generation},
+		Spec: appsv1.DaemonSetSpec{
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+			},
+		},
+		Status: status,
+	}
+}
+
+func TestListDaemonSetItems(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newTestDaemonSet("fluentd", 2, appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 3, CurrentNumberScheduled: 3, NumberReady: 2, UpdatedNumberScheduled: 1,
+			NumberAvailable: 2, NumberUnavailable: 1, ObservedGeneration: 2,
+		}),
+		newTestDaemonSet("node-exporter", 1, appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 3, CurrentNumberScheduled: 3, NumberReady: 3, UpdatedNumberScheduled: 3,
+			NumberAvailable: 3, ObservedGeneration: 1,
+		}),
+	)
+
+	list, err := ListDaemonSetItems(context.Background(), clientset, "kube-system")
+	require.NoError(t, err)
+	require.Equal(t, 2, list.Total)
+
+	rolling := list.Items[0]
+	assert.Equal(t, "fluentd", rolling.Name)
+	assert.EqualValues(t, 3, rolling.DesiredNumberScheduled)
+	assert.EqualValues(t, 3, rolling.CurrentNumberScheduled)
+	assert.EqualValues(t, 2, rolling.NumberReady)
+	assert.EqualValues(t, 1, rolling.UpdatedNumberScheduled)
+	assert.EqualValues(t, 1, rolling.NumberUnavailable)
+	assert.Equal(t, "RollingUpdate", rolling.UpdateStrategy)
+	assert.Equal(t, "25%", rolling.MaxUnavailable)
+	assert.False(t, rolling.RolloutComplete)
+
+	done, err := GetDaemonSetItem(context.Background(), clientset, "kube-system", "node-exporter")
+	require.NoError(t, err)
+	assert.True(t, done.RolloutComplete)
+}
+
+func TestSetDaemonSetUpdateStrategy(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestDaemonSet("fluentd", 1, appsv1.DaemonSetStatus{}))
+
+	_, err := SetDaemonSetUpdateStrategy(context.Background(), clientset, "kube-system", "fluentd", &models.UpdateDaemonSetStrategyRequest{Type: "OnDelete"})
+	require.NoError(t, err)
+	stored, err := clientset.AppsV1().DaemonSets("kube-system").Get(t.Context(), "fluentd", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.OnDeleteDaemonSetStrategyType, stored.Spec.UpdateStrategy.Type)
+	assert.Nil(t, stored.Spec.UpdateStrategy.RollingUpdate)
+
+	_, err = SetDaemonSetUpdateStrategy(context.Background(), clientset, "kube-system", "fluentd",
+		&models.UpdateDaemonSetStrategyRequest{Type: "RollingUpdate", MaxUnavailable: "0", MaxSurge: "10%"})
+	require.NoError(t, err)
+	stored, err = clientset.AppsV1().DaemonSets("kube-system").Get(t.Context(), "fluentd", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, appsv1.RollingUpdateDaemonSetStrategyType, stored.Spec.UpdateStrategy.Type)
+	require.NotNil(t, stored.Spec.UpdateStrategy.RollingUpdate)
+	assert.Equal(t, intstr.FromInt32(0), *stored.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable)
+	assert.Equal(t, intstr.FromString("10%"), *stored.Spec.UpdateStrategy.RollingUpdate.MaxSurge)
+
+	for _, invalid := range []models.UpdateDaemonSetStrategyRequest{
+		{Type: "OnDelete", MaxSurge: "1"},
+		{Type: "RollingUpdate", MaxUnavailable: "0"},
+		{Type: "RollingUpdate", MaxUnavailable: "half"},
+		{Type: "RollingUpdate", MaxSurge: "150%"},
+		{Type: "Recreate"},
+	} {
+		_, err := SetDaemonSetUpdateStrategy(context.Background(), clientset, "kube-system", "fluentd", &invalid)
+		assert.True(t, errors.Is(err, ErrInvalidResource), "%+v", invalid)
+	}
+
+	_, err = SetDaemonSetUpdateStrategy(context.Background(), clientset, "kube-system", "missing", &models.UpdateDaemonSetStrategyRequest{Type: "OnDelete"})
+	assert.Error(t, err)
+}
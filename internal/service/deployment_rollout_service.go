@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// deploymentRevisionAnnotation is set by the deployment controller on each ReplicaSet
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+	// changeCauseAnnotation records the reason of a rollout when provided by the user
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// ErrRevisionNotFound is returned when the requested rollout revision no longer exists
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// DeploymentRolloutService handles Deployment rollout history and rollback
+type DeploymentRolloutService struct{}
+
+// NewDeploymentRolloutService creates Deployment rollout service
+func NewDeploymentRolloutService() *DeploymentRolloutService {
+	return &DeploymentRolloutService{}
+}
+
+// ListRevisions lists the ReplicaSets owned by a Deployment as rollout revisions, newest first
+func (s *DeploymentRolloutService) ListRevisions(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*models.DeploymentRevisionListResponse, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	replicaSets, err := s.ownedReplicaSets(ctx, clientset, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	currentRevision := deployment.Annotations[deploymentRevisionAnnotation]
+	items := make([]models.DeploymentRevision, 0, len(replicaSets))
+	for _, rs := range replicaSets {
+		revision, err := replicaSetRevision(rs)
+		if err != nil {
+			continue
+		}
+
+		images := make([]string, 0, len(rs.Spec.Template.Spec.Containers))
+		for _, container := range rs.Spec.Template.Spec.Containers {
+			images = append(images, container.Image)
+		}
+
+		var replicas int32
+		if rs.Spec.Replicas != nil {
+			replicas = *rs.Spec.Replicas
+		}
+
+		items = append(items, models.DeploymentRevision{
+			Revision:       revision,
+			ReplicaSetName: rs.Name,
+			Replicas:       replicas,
+			ReadyReplicas:  rs.Status.ReadyReplicas,
+			Current:        rs.Annotations[deploymentRevisionAnnotation] == currentRevision,
+			ChangeCause:    rs.Annotations[changeCauseAnnotation],
+			Images:         images,
+			TemplateLabels: rs.Spec.Template.Labels,
+			CreatedAt:      rs.CreationTimestamp,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Revision > items[j].Revision
+	})
+
+	return &models.DeploymentRevisionListResponse{
+		Items: items,
+		Total: len(items),
+	}, nil
+}
+
+// Rollback patches the Deployment pod template to the one recorded in the given revision
+func (s *DeploymentRolloutService) Rollback(ctx context.Context, clientset kubernetes.Interface, namespace, name string, revision int64) (*appsv1.Deployment, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	replicaSets, err := s.ownedReplicaSets(ctx, clientset, deployment)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *appsv1.ReplicaSet
+	for _, rs := range replicaSets {
+		if v, err := replicaSetRevision(rs); err == nil && v == revision {
+			target = rs
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: deployment %s/%s has no revision %d", ErrRevisionNotFound, namespace, name, revision)
+	}
+
+	// Rolling back to the revision that is already running is a no-op
+	if deployment.Annotations[deploymentRevisionAnnotation] == target.Annotations[deploymentRevisionAnnotation] {
+		return deployment, nil
+	}
+
+	template := target.Spec.Template.DeepCopy()
+	// The pod-template-hash label is added by the controller and must not be copied back
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/spec/template", "value": template},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rollback patch: %w", err)
+	}
+
+	return clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{})
+}
+
+// ownedReplicaSets returns the ReplicaSets in the Deployment namespace controlled by the Deployment
+func (s *DeploymentRolloutService) ownedReplicaSets(ctx context.Context, clientset kubernetes.Interface, deployment *appsv1.Deployment) ([]*appsv1.ReplicaSet, error) {
+	opts := metav1.ListOptions{}
+	if deployment.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deployment selector: %w", err)
+		}
+		opts.LabelSelector = selector.String()
+	}
+
+	rsList, err := clientset.AppsV1().ReplicaSets(deployment.Namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+
+	owned := make([]*appsv1.ReplicaSet, 0, len(rsList.Items))
+	for i := range rsList.Items {
+		rs := &rsList.Items[i]
+		if ref := metav1.GetControllerOf(rs); ref != nil && ref.UID == deployment.UID {
+			owned = append(owned, rs)
+		}
+	}
+	return owned, nil
+}
+
+// replicaSetRevision parses the revision annotation of a ReplicaSet
+func replicaSetRevision(rs *appsv1.ReplicaSet) (int64, error) {
+	value, ok := rs.Annotations[deploymentRevisionAnnotation]
+	if !ok {
+		return 0, fmt.Errorf("replicaset %s has no revision annotation", rs.Name)
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestReplicaSet(deployment *appsv1.Deployment, name, revision, image string) *appsv1.ReplicaSet {
+	controller := true
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   deployment.Namespace,
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{deploymentRevisionAnnotation: revision},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deployment.Name,
+				UID:        deployment.UID,
+				Controller: &controller,
+			}},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: name},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "web", Image: image}},
+				},
+			},
+		},
+	}
+}
+
+func setupTestRolloutClientset() *fake.Clientset {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			UID:         types.UID("web-uid"),
+			Annotations: map[string]string{deploymentRevisionAnnotation: "2"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "web", Image: "nginx:1.25"}},
+				},
+			},
+		},
+	}
+
+	// A ReplicaSet matching the selector but owned by another Deployment must be ignored
+	other := newTestReplicaSet(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", UID: "other-uid"}}, "other-1", "1", "busybox")
+
+	objects := []runtime.Object{
+		deployment,
+		newTestReplicaSet(deployment, "web-1", "1", "nginx:1.24"),
+		newTestReplicaSet(deployment, "web-2", "2", "nginx:1.25"),
+		other,
+	}
+	return fake.NewSimpleClientset(objects...)
+}
+
+func TestDeploymentRolloutService_ListRevisions(t *testing.T) {
+	svc := NewDeploymentRolloutService()
+	clientset := setupTestRolloutClientset()
+
+	revisions, err := svc.ListRevisions(context.Background(), clientset, "default", "web")
+	require.NoError(t, err)
+	require.Equal(t, 2, revisions.Total)
+
+	assert.Equal(t, int64(2), revisions.Items[0].Revision)
+	assert.Equal(t, "web-2", revisions.Items[0].ReplicaSetName)
+	assert.True(t, revisions.Items[0].Current)
+	assert.Equal(t, []string{"nginx:1.25"}, revisions.Items[0].Images)
+
+	assert.Equal(t, int64(1), revisions.Items[1].Revision)
+	assert.False(t, revisions.Items[1].Current)
+	assert.Equal(t, []string{"nginx:1.24"}, revisions.Items[1].Images)
+}
+
+func TestDeploymentRolloutService_Rollback(t *testing.T) {
+	svc := NewDeploymentRolloutService()
+	clientset := setupTestRolloutClientset()
+
+	_, err := svc.Rollback(context.Background(), clientset, "default", "web", 1)
+	require.NoError(t, err)
+
+	updated, err := clientset.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1.24", updated.Spec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, updated.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+}
+
+func TestDeploymentRolloutService_RollbackMissingRevision(t *testing.T) {
+	svc := NewDeploymentRolloutService()
+	clientset := setupTestRolloutClientset()
+
+	_, err := svc.Rollback(context.Background(), clientset, "default", "web", 5)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRevisionNotFound))
+
+	unchanged, err := clientset.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "nginx:1.25", unchanged.Spec.Template.Spec.Containers[0].Image)
+}
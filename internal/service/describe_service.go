@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// lastAppliedAnnotation holds the whole manifest last applied by kubectl, which kubectl describe hides too
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// DescribeService assembles kubectl describe style descriptions of objects
+type DescribeService struct{}
+
+// NewDescribeService creates a new DescribeService instance
+func NewDescribeService() *DescribeService {
+	return &DescribeService{}
+}
+
+// Describe returns the description of a namespaced object of resource, built-in or custom: its metadata, the
+// scalar fields and conditions of its status, and the events involving it. Pods also get their containers
+// with their state and the volumes they mount.
+func (s *DescribeService) Describe(ctx context.Context, clientset kubernetes.Interface, client dynamic.Interface, mapper meta.RESTMapper, namespace, resource, name string) (*models.ResourceDescription, error) {
+	gvr, err := namespacedResource(mapper, resource)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	description := &models.ResourceDescription{
+		Kind:       obj.GetKind(),
+		APIVersion: obj.GetAPIVersion(),
+		Resource:   gvr.Resource,
+		Metadata:   describeMetadata(obj),
+		Status:     describeStatusFields(obj),
+		Conditions: describeConditions(obj),
+	}
+
+	if gvr.Group == "" && gvr.Resource == "pods" {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return nil, fmt.Errorf("failed to decode pod: %w", err)
+		}
+		description.Pod = describePod(&pod)
+	}
+
+	description.Events, err = s.objectEvents(ctx, clientset, obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	return description, nil
+}
+
+// objectEvents lists the events involving an object, oldest first
+func (s *DescribeService) objectEvents(ctx context.Context, clientset kubernetes.Interface, obj *unstructured.Unstructured) ([]models.ClusterEvent, error) {
+	selector := fields.Set{
+		"involvedObject.kind":      obj.GetKind(),
+		"involvedObject.name":      obj.GetName(),
+		"involvedObject.namespace": obj.GetNamespace(),
+		"involvedObject.uid":       string(obj.GetUID()),
+	}.AsSelector().String()
+	list, err := clientset.CoreV1().Events(obj.GetNamespace()).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.ClusterEvent, 0, len(list.Items))
+	for i := range list.Items {
+		events = append(events, models.ConvertK8sEventToClusterEvent(&list.Items[i]))
+	}
+	sort.SliceStable(events, func(i, j int) bool { return eventTime(events[i]).Before(eventTime(events[j])) })
+	return events, nil
+}
+
+// eventTime is when an event was last seen, its creation for events that don't record it
+func eventTime(event models.ClusterEvent) time.Time {
+	if event.LastTime.IsZero() {
+		return event.CreatedAt
+	}
+	return event.LastTime
+}
+
+func describeMetadata(obj *unstructured.Unstructured) models.DescribeMetadata {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[lastAppliedAnnotation]; ok {
+		annotations = make(map[string]string, len(obj.GetAnnotations()))
+		for key, value := range obj.GetAnnotations() {
+			if key != lastAppliedAnnotation {
+				annotations[key] = value
+			}
+		}
+	}
+
+	metadata := models.DescribeMetadata{
+		Name:              obj.GetName(),
+		Namespace:         obj.GetNamespace(),
+		UID:               string(obj.GetUID()),
+		CreationTimestamp: obj.GetCreationTimestamp().Time,
+		Labels:            obj.GetLabels(),
+		Annotations:       annotations,
+	}
+	for _, owner := range obj.GetOwnerReferences() {
+		metadata.OwnerReferences = append(metadata.OwnerReferences, models.DescribeOwner{
+			Kind:       owner.Kind,
+			Name:       owner.Name,
+			Controller: owner.Controller != nil && *owner.Controller,
+		})
+	}
+	return metadata
+}
+
+// describeStatusFields returns the scalar top-level fields of the status of an object, such as its phase
+// or replica counts
+func describeStatusFields(obj *unstructured.Unstructured) map[string]string {
+	status, ok := obj.Object["status"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	scalars := make(map[string]string)
+	for key, value := range status {
+		switch value.(type) {
+		case string, bool, int64, float64:
+			scalars[key] = fmt.Sprint(value)
+		}
+	}
+	if len(scalars) == 0 {
+		return nil
+	}
+	return scalars
+}
+
+// describeConditions returns the conditions in the status of an object
+func describeConditions(obj *unstructured.Unstructured) []models.DescribeCondition {
+	conditions := []models.DescribeCondition{}
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range items {
+		values, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condition := models.DescribeCondition{}
+		condition.Type, _, _ = unstructured.NestedString(values, "type")
+		condition.Status, _, _ = unstructured.NestedString(values, "status")
+		condition.Reason, _, _ = unstructured.NestedString(values, "reason")
+		condition.Message, _, _ = unstructured.NestedString(values, "message")
+		if value, _, _ := unstructured.NestedString(values, "lastTransitionTime"); value != "" {
+			if transition, err := time.Parse(time.RFC3339, value); err == nil {
+				condition.LastTransitionTime = &transition
+			}
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+func describePod(pod *corev1.Pod) *models.DescribePod {
+	result := &models.DescribePod{
+		Node:           pod.Spec.NodeName,
+		Phase:          string(pod.Status.Phase),
+		PodIP:          pod.Status.PodIP,
+		QOSClass:       string(pod.Status.QOSClass),
+		ServiceAccount: pod.Spec.ServiceAccountName,
+		Containers:     []models.DescribeContainer{},
+		Volumes:        []models.DescribeVolume{},
+	}
+	if pod.Status.StartTime != nil {
+		startTime := pod.Status.StartTime.Time
+		result.StartTime = &startTime
+	}
+
+	addContainers := func(containers []corev1.Container, statuses []corev1.ContainerStatus, init bool) {
+		byName := make(map[string]corev1.ContainerStatus, len(statuses))
+		for _, status := range statuses {
+			byName[status.Name] = status
+		}
+		for _, container := range containers {
+			item := models.DescribeContainer{Name: container.Name, Init: init, Image: container.Image}
+			for _, port := range container.Ports {
+				item.Ports = append(item.Ports, models.DescribeContainerPort{Name: port.Name, ContainerPort: port.ContainerPort, Protocol: string(port.Protocol)})
+			}
+			for _, mount := range container.VolumeMounts {
+				item.Mounts = append(item.Mounts, models.DescribeVolumeMount{Name: mount.Name, MountPath: mount.MountPath, SubPath: mount.SubPath, ReadOnly: mount.ReadOnly})
+			}
+			if status, ok := byName[container.Name]; ok {
+				item.ContainerStatusInfo = containerStatusInfo(status)
+			}
+			result.Containers = append(result.Containers, item)
+		}
+	}
+	addContainers(pod.Spec.InitContainers, pod.Status.InitContainerStatuses, true)
+	addContainers(pod.Spec.Containers, pod.Status.ContainerStatuses, false)
+
+	for _, volume := range pod.Spec.Volumes {
+		result.Volumes = append(result.Volumes, describeVolume(volume))
+	}
+	return result
+}
+
+// describeVolume returns the source type of a volume and the object or path it comes from
+func describeVolume(volume corev1.Volume) models.DescribeVolume {
+	item := models.DescribeVolume{Name: volume.Name}
+	source := volume.VolumeSource
+	switch {
+	case source.PersistentVolumeClaim != nil:
+		item.Type, item.Source = "persistentVolumeClaim", source.PersistentVolumeClaim.ClaimName
+	case source.ConfigMap != nil:
+		item.Type, item.Source = "configMap", source.ConfigMap.Name
+	case source.Secret != nil:
+		item.Type, item.Source = "secret", source.Secret.SecretName
+	case source.HostPath != nil:
+		item.Type, item.Source = "hostPath", source.HostPath.Path
+	case source.EmptyDir != nil:
+		item.Type = "emptyDir"
+	case source.Projected != nil:
+		item.Type = "projected"
+	case source.DownwardAPI != nil:
+		item.Type = "downwardAPI"
+	case source.Ephemeral != nil:
+		item.Type = "ephemeral"
+	case source.CSI != nil:
+		item.Type, item.Source = "csi", source.CSI.Driver
+	case source.NFS != nil:
+		item.Type, item.Source = "nfs", source.NFS.Server+":"+source.NFS.Path
+	default:
+		item.Type = "other"
+	}
+	return item
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+var describeStarted = time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+func testDescribePod() *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "shop", Name: "web-7d9f-abcde", UID: "web-7d9f-abcde-uid",
+			Labels: map[string]string{"app": "web"},
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"team": "checkout",
+			},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-7d9f", Controller: ptr.To(true)}},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:       "node-1",
+			InitContainers: []corev1.Container{{Name: "migrate", Image: "web:1.2"}},
+			Containers: []corev1.Container{{
+				Name:         "web",
+				Image:        "web:1.2",
+				Ports:        []corev1.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}, {Name: "config", MountPath: "/etc/web", ReadOnly: true}},
+			}},
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "web-data"}}},
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"}}}},
+				{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase:     corev1.PodRunning,
+			PodIP:     "10.0.0.12",
+			QOSClass:  corev1.PodQOSBestEffort,
+			StartTime: &metav1.Time{Time: describeStarted},
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady", LastTransitionTime: metav1.NewTime(describeStarted)},
+			},
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "migrate",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}},
+			}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "web",
+				RestartCount: 3,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 40s restarting failed container"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1, Reason: "Error", Message: "listen tcp :8080: bind: address already in use",
+				}},
+			}},
+		},
+	}
+}
+
+func testDescribeEvent(name, object, reason string, lastSeen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", Name: name},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: object, UID: types.UID(object + "-uid")},
+		Reason:         reason,
+		Type:           corev1.EventTypeWarning,
+		Count:          1,
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
+
+// newTestDescribeEnv returns clients holding a crash looping pod and events of it and of another pod. The
+// fake clientset ignores field selectors, so events are filtered on the involved object like the API server
+// does and the selector of each list is recorded.
+func newTestDescribeEnv(t *testing.T) (*fake.Clientset, *dynamicfake.FakeDynamicClient, meta.RESTMapper, *[]string) {
+	t.Helper()
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Version: "v1"}})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Node"}, meta.RESTScopeRoot)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	client := dynamicfake.NewSimpleDynamicClient(scheme, testDescribePod())
+
+	events := []*corev1.Event{
+		testDescribeEvent("backoff", "web-7d9f-abcde", "BackOff", describeStarted.Add(3*time.Minute)),
+		testDescribeEvent("scheduled", "web-7d9f-abcde", "Scheduled", describeStarted),
+		testDescribeEvent("other", "api-5c8b-fghij", "BackOff", describeStarted),
+	}
+	clientset := fake.NewSimpleClientset()
+	var selectors []string
+	clientset.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		selector := action.(k8stesting.ListAction).GetListRestrictions().Fields
+		selectors = append(selectors, selector.String())
+		list := &corev1.EventList{}
+		for _, event := range events {
+			if selector.Matches(fields.Set{
+				"involvedObject.kind":      event.InvolvedObject.Kind,
+				"involvedObject.name":      event.InvolvedObject.Name,
+				"involvedObject.namespace": event.InvolvedObject.Namespace,
+				"involvedObject.uid":       string(event.InvolvedObject.UID),
+			}) {
+				list.Items = append(list.Items, *event)
+			}
+		}
+		return true, list, nil
+	})
+	return clientset, client, mapper, &selectors
+}
+
+func TestDescribeService_DescribePod(t *testing.T) {
+	clientset, client, mapper, selectors := newTestDescribeEnv(t)
+
+	description, err := NewDescribeService().Describe(context.Background(), clientset, client, mapper, "shop", "pods", "web-7d9f-abcde")
+	require.NoError(t, err)
+	assert.Equal(t, "Pod", description.Kind)
+	assert.Equal(t, "pods", description.Resource)
+	assert.Equal(t, "web-7d9f-abcde-uid", description.Metadata.UID)
+	assert.Equal(t, map[string]string{"team": "checkout"}, description.Metadata.Annotations, "the last applied configuration is hidden")
+	require.Len(t, description.Metadata.OwnerReferences, 1)
+	assert.Equal(t, "web-7d9f", description.Metadata.OwnerReferences[0].Name)
+	assert.True(t, description.Metadata.OwnerReferences[0].Controller)
+	assert.Equal(t, "Running", description.Status["phase"])
+
+	require.Len(t, description.Conditions, 1)
+	assert.Equal(t, "Ready", description.Conditions[0].Type)
+	assert.Equal(t, "ContainersNotReady", description.Conditions[0].Reason)
+	require.NotNil(t, description.Conditions[0].LastTransitionTime)
+	assert.True(t, describeStarted.Equal(*description.Conditions[0].LastTransitionTime))
+
+	// Events of the pod only, oldest first, selected by the pod's kind, name, namespace and uid
+	require.Len(t, *selectors, 1)
+	for _, term := range []string{"involvedObject.kind=Pod", "involvedObject.name=web-7d9f-abcde", "involvedObject.namespace=shop", "involvedObject.uid=web-7d9f-abcde-uid"} {
+		assert.Contains(t, (*selectors)[0], term)
+	}
+	require.Len(t, description.Events, 2)
+	assert.Equal(t, "Scheduled", description.Events[0].Reason)
+	assert.Equal(t, "BackOff", description.Events[1].Reason)
+
+	pod := description.Pod
+	require.NotNil(t, pod)
+	assert.Equal(t, "node-1", pod.Node)
+	assert.Equal(t, "10.0.0.12", pod.PodIP)
+	assert.Equal(t, "BestEffort", pod.QOSClass)
+	require.NotNil(t, pod.StartTime)
+
+	require.Len(t, pod.Containers, 2)
+	migrate, web := pod.Containers[0], pod.Containers[1]
+	assert.Equal(t, "migrate", migrate.Name)
+	assert.True(t, migrate.Init, "init containers first")
+	assert.Equal(t, "terminated", migrate.State)
+	assert.Equal(t, "Completed", migrate.Reason)
+
+	assert.Equal(t, "web:1.2", web.Image)
+	assert.False(t, web.Ready)
+	assert.Equal(t, "waiting", web.State)
+	assert.Equal(t, "CrashLoopBackOff", web.Reason)
+	assert.EqualValues(t, 3, web.RestartCount)
+	require.NotNil(t, web.LastExitCode)
+	assert.EqualValues(t, 1, *web.LastExitCode)
+	assert.Equal(t, "listen tcp :8080: bind: address already in use", web.LastMessage)
+	require.Len(t, web.Ports, 1)
+	assert.EqualValues(t, 8080, web.Ports[0].ContainerPort)
+	require.Len(t, web.Mounts, 2)
+	assert.True(t, web.Mounts[1].ReadOnly)
+
+	require.Len(t, pod.Volumes, 3)
+	assert.Equal(t, "persistentVolumeClaim", pod.Volumes[0].Type)
+	assert.Equal(t, "web-data", pod.Volumes[0].Source)
+	assert.Equal(t, "configMap", pod.Volumes[1].Type)
+	assert.Equal(t, "web-config", pod.Volumes[1].Source)
+	assert.Equal(t, "emptyDir", pod.Volumes[2].Type)
+}
+
+func TestDescribeService_Errors(t *testing.T) {
+	clientset, client, mapper, _ := newTestDescribeEnv(t)
+	svc := NewDescribeService()
+
+	_, err := svc.Describe(context.Background(), clientset, client, mapper, "shop", "pods", "missing")
+	assert.True(t, k8serrors.IsNotFound(err))
+	_, err = svc.Describe(context.Background(), clientset, client, mapper, "shop", "nodes", "node-1")
+	assert.ErrorIs(t, err, ErrResourceScopeMismatch)
+	_, err = svc.Describe(context.Background(), clientset, client, mapper, "shop", "widgets", "w")
+	assert.True(t, meta.IsNoMatchError(err))
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// Kinds of field changes reported by a resource diff
+const (
+	FieldAdded   = "added"
+	FieldRemoved = "removed"
+	FieldChanged = "changed"
+)
+
+// serverManagedFields are maintained by the API server and ignored when diffing unless asked otherwise
+var serverManagedFields = [][]string{
+	{"status"},
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "uid"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "selfLink"},
+}
+
+// FieldChange describes a single field that differs between the live and the desired object
+type FieldChange struct {
+	Path string      `json:"path"`
+	Type string      `json:"type"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// ResourceDiff is the result of diffing a manifest against the live object
+type ResourceDiff struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Namespace  string        `json:"namespace,omitempty"`
+	Name       string        `json:"name"`
+	Creation   bool          `json:"creation"` // The object does not exist yet and would be created
+	Changes    []FieldChange `json:"changes"`
+	Unified    string        `json:"unified"`
+}
+
+// DiffOptions controls how a manifest is compared with the live object
+type DiffOptions struct {
+	IncludeServerFields bool // Also compare status, managedFields, resourceVersion and other server-managed fields
+}
+
+// DiffService compares desired manifests with the objects currently stored in a cluster
+type DiffService struct{}
+
+// NewDiffService creates a new DiffService instance
+func NewDiffService() *DiffService {
+	return &DiffService{}
+}
+
+// Diff fetches the live object addressed by the manifest and returns what applying the manifest would change.
+// The namespace defaults to defaultNamespace for namespaced resources without metadata.namespace.
+func (s *DiffService) Diff(client dynamic.Interface, mapper meta.RESTMapper, desired *unstructured.Unstructured, defaultNamespace string, opts DiffOptions) (*ResourceDiff, error) {
+	gvk := desired.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return nil, fmt.Errorf("%w: apiVersion and kind are required", ErrInvalidResource)
+	}
+	if desired.GetName() == "" {
+		return nil, fmt.Errorf("%w: metadata.name is required", ErrInvalidResource)
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var ri dynamic.ResourceInterface = client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if desired.GetNamespace() == "" {
+			if defaultNamespace == "" {
+				defaultNamespace = metav1.NamespaceDefault
+			}
+			desired.SetNamespace(defaultNamespace)
+		}
+		ri = client.Resource(mapping.Resource).Namespace(desired.GetNamespace())
+	} else if desired.GetNamespace() != "" {
+		return nil, fmt.Errorf("%w: %s is cluster-scoped", ErrResourceScopeMismatch, mapping.Resource.GroupResource())
+	}
+
+	result := &ResourceDiff{
+		APIVersion: desired.GetAPIVersion(),
+		Kind:       desired.GetKind(),
+		Namespace:  desired.GetNamespace(),
+		Name:       desired.GetName(),
+	}
+
+	var liveObject map[string]interface{}
+	live, err := ri.Get(context.TODO(), desired.GetName(), metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		result.Creation = true
+	case err != nil:
+		return nil, err
+	default:
+		liveObject = live.Object
+	}
+
+	desiredObject := desired.Object
+	if !opts.IncludeServerFields {
+		liveObject = withoutServerFields(liveObject)
+		desiredObject = withoutServerFields(desiredObject)
+	}
+
+	result.Changes = DiffObjects(liveObject, desiredObject)
+	result.Unified, err = unifiedDiff(liveObject, desiredObject, "live", "desired")
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DiffObjects returns the field-level changes needed to turn live into desired, sorted by path
+func DiffObjects(live, desired map[string]interface{}) []FieldChange {
+	changes := []FieldChange{}
+	diffValues("", toDiffValue(live), toDiffValue(desired), &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// toDiffValue turns a nil object into an empty one so a creation is reported field by field
+func toDiffValue(obj map[string]interface{}) interface{} {
+	if obj == nil {
+		return map[string]interface{}{}
+	}
+	return obj
+}
+
+func diffValues(path string, live, desired interface{}, changes *[]FieldChange) {
+	liveMap, liveIsMap := live.(map[string]interface{})
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	if liveIsMap && desiredIsMap {
+		for key, liveValue := range liveMap {
+			desiredValue, ok := desiredMap[key]
+			if !ok {
+				*changes = append(*changes, FieldChange{Path: joinFieldPath(path, key), Type: FieldRemoved, Old: liveValue})
+				continue
+			}
+			diffValues(joinFieldPath(path, key), liveValue, desiredValue, changes)
+		}
+		for key, desiredValue := range desiredMap {
+			if _, ok := liveMap[key]; !ok {
+				*changes = append(*changes, FieldChange{Path: joinFieldPath(path, key), Type: FieldAdded, New: desiredValue})
+			}
+		}
+		return
+	}
+
+	liveList, liveIsList := live.([]interface{})
+	desiredList, desiredIsList := desired.([]interface{})
+	if liveIsList && desiredIsList {
+		for i := 0; i < len(liveList) || i < len(desiredList); i++ {
+			itemPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(desiredList):
+				*changes = append(*changes, FieldChange{Path: itemPath, Type: FieldRemoved, Old: liveList[i]})
+			case i >= len(liveList):
+				*changes = append(*changes, FieldChange{Path: itemPath, Type: FieldAdded, New: desiredList[i]})
+			default:
+				diffValues(itemPath, liveList[i], desiredList[i], changes)
+			}
+		}
+		return
+	}
+
+	if !scalarEqual(live, desired) {
+		*changes = append(*changes, FieldChange{Path: path, Type: FieldChanged, Old: live, New: desired})
+	}
+}
+
+// scalarEqual compares leaf values, treating numbers decoded as different Go types as equal
+func scalarEqual(a, b interface{}) bool {
+	if af, ok := toFloat(a); ok {
+		if bf, ok := toFloat(b); ok {
+			return af == bf
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// joinFieldPath appends a map key to a dotted field path, quoting keys such as annotation names that contain dots
+func joinFieldPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// withoutServerFields returns a copy of obj without the fields maintained by the API server
+func withoutServerFields(obj map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	copied := (&unstructured.Unstructured{Object: obj}).DeepCopy().Object
+	for _, fields := range serverManagedFields {
+		unstructured.RemoveNestedField(copied, fields...)
+	}
+	if metadata, ok := copied["metadata"].(map[string]interface{}); ok && len(metadata) == 0 {
+		delete(copied, "metadata")
+	}
+	return copied
+}
+
+// unifiedDiff renders both objects as YAML and returns a unified text diff of them, labelled liveLabel and
+// desiredLabel
+func unifiedDiff(live, desired map[string]interface{}, liveLabel, desiredLabel string) (string, error) {
+	toYAML := func(obj map[string]interface{}) (string, error) {
+		if obj == nil {
+			return "", nil
+		}
+		data, err := yaml.Marshal(obj)
+		return string(data), err
+	}
+
+	liveYAML, err := toYAML(live)
+	if err != nil {
+		return "", err
+	}
+	desiredYAML, err := toYAML(desired)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(liveYAML),
+		B:        splitLines(desiredYAML),
+		FromFile: liveLabel,
+		ToFile:   desiredLabel,
+		Context:  3,
+	})
+}
+
+// splitLines splits text into lines for diffing, returning no lines for empty text
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return difflib.SplitLines(text)
+}
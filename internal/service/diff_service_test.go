@@ -0,0 +1,123 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newTestDiffDeployment(image string, replicas int32) *appsv1.Deployment {
+	labels := map[string]string{"app": "web"}
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}},
+			},
+		},
+	}
+}
+
+func toUnstructured(t *testing.T, obj runtime.Object) *unstructured.Unstructured {
+	t.Helper()
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	return &unstructured.Unstructured{Object: content}
+}
+
+func newTestDiffEnv(objects ...runtime.Object) (*dynamicfake.FakeDynamicClient, meta.RESTMapper) {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{appsv1.SchemeGroupVersion})
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		appsv1.SchemeGroupVersion.WithResource("deployments"): "DeploymentList",
+	}, objects...)
+	return client, mapper
+}
+
+func changesByPath(changes []FieldChange) map[string]FieldChange {
+	byPath := make(map[string]FieldChange, len(changes))
+	for _, change := range changes {
+		byPath[change.Path] = change
+	}
+	return byPath
+}
+
+func TestDiffService_ModifiedDeployment(t *testing.T) {
+	live := newTestDiffDeployment("nginx:1.25", 2)
+	live.ResourceVersion = "42"
+	live.UID = "1234"
+	live.Status.ReadyReplicas = 2
+	client, mapper := newTestDiffEnv(toUnstructured(t, live))
+
+	desired := newTestDiffDeployment("nginx:1.27", 3)
+	desired.Annotations = map[string]string{"example.com/owner": "team-a"}
+
+	diff, err := NewDiffService().Diff(client, mapper, toUnstructured(t, desired), "", DiffOptions{})
+	require.NoError(t, err)
+	assert.False(t, diff.Creation)
+	assert.Equal(t, "Deployment", diff.Kind)
+
+	changes := changesByPath(diff.Changes)
+	assert.Len(t, changes, 3, "server-managed fields are ignored: %v", diff.Changes)
+	assert.Equal(t, FieldChange{Path: "spec.replicas", Type: FieldChanged, Old: int64(2), New: int64(3)}, changes["spec.replicas"])
+	assert.Equal(t, FieldChange{Path: "spec.template.spec.containers[0].image", Type: FieldChanged, Old: "nginx:1.25", New: "nginx:1.27"},
+		changes["spec.template.spec.containers[0].image"])
+	assert.Equal(t, FieldAdded, changes["metadata.annotations"].Type)
+
+	assert.Contains(t, diff.Unified, "--- live")
+	assert.Contains(t, diff.Unified, "+++ desired")
+	assert.Contains(t, diff.Unified, "-  replicas: 2")
+	assert.Contains(t, diff.Unified, "+  replicas: 3")
+	assert.NotContains(t, diff.Unified, "resourceVersion")
+
+	diff, err = NewDiffService().Diff(client, mapper, toUnstructured(t, desired), "", DiffOptions{IncludeServerFields: true})
+	require.NoError(t, err)
+	changes = changesByPath(diff.Changes)
+	assert.Equal(t, FieldRemoved, changes["metadata.resourceVersion"].Type)
+	assert.Equal(t, FieldRemoved, changes["status.readyReplicas"].Type)
+}
+
+func TestDiffService_Creation(t *testing.T) {
+	client, mapper := newTestDiffEnv()
+
+	desired := toUnstructured(t, newTestDiffDeployment("nginx:1.27", 1))
+	desired.SetNamespace("")
+	diff, err := NewDiffService().Diff(client, mapper, desired, "staging", DiffOptions{})
+	require.NoError(t, err)
+	assert.True(t, diff.Creation)
+	assert.Equal(t, "staging", diff.Namespace)
+	for _, change := range diff.Changes {
+		assert.Equal(t, FieldAdded, change.Type)
+	}
+	assert.Contains(t, diff.Unified, "+kind: Deployment")
+}
+
+func TestDiffService_InvalidManifest(t *testing.T) {
+	client, mapper := newTestDiffEnv()
+	_, err := NewDiffService().Diff(client, mapper, &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Deployment"}}, "", DiffOptions{})
+	assert.ErrorIs(t, err, ErrInvalidResource)
+}
+
+func TestDiffObjects_Lists(t *testing.T) {
+	live := map[string]interface{}{"items": []interface{}{"a", "b"}}
+	desired := map[string]interface{}{"items": []interface{}{"a", "c", "d"}, "meta": map[string]interface{}{"example.com/key": "v"}}
+
+	assert.Equal(t, []FieldChange{
+		{Path: "items[1]", Type: FieldChanged, Old: "b", New: "c"},
+		{Path: "items[2]", Type: FieldAdded, New: "d"},
+		{Path: "meta", Type: FieldAdded, New: map[string]interface{}{"example.com/key": "v"}},
+	}, DiffObjects(live, desired))
+}
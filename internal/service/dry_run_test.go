@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// newDryRunClientset returns a fake clientset that, like a real API server, answers dry-run
+// creates and updates with the submitted object without persisting it
+func newDryRunClientset(objects ...runtime.Object) *fake.Clientset {
+	clientset := fake.NewSimpleClientset(objects...)
+	clientset.PrependReactor("create", "*", func(action ktesting.Action) (bool, runtime.Object, error) {
+		create := action.(ktesting.CreateActionImpl)
+		if len(create.CreateOptions.DryRun) == 0 {
+			return false, nil, nil
+		}
+		return true, create.GetObject(), nil
+	})
+	clientset.PrependReactor("update", "*", func(action ktesting.Action) (bool, runtime.Object, error) {
+		update := action.(ktesting.UpdateActionImpl)
+		if len(update.UpdateOptions.DryRun) == 0 {
+			return false, nil, nil
+		}
+		return true, update.GetObject(), nil
+	})
+	return clientset
+}
+
+func TestBaseResourceService_DryRunCreate(t *testing.T) {
+	svc := NewBaseResourceService[*corev1.ConfigMap](new(ConfigMapClient))
+	clientset := newDryRunClientset()
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}, Data: map[string]string{"mode": "dev"}}
+	created, err := svc.DryRunCreate(clientset, "default", cm)
+	require.NoError(t, err)
+	assert.Equal(t, "settings", created.Name)
+	assert.Equal(t, "dev", created.Data["mode"])
+
+	list, err := clientset.CoreV1().ConfigMaps("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items, "a dry-run create must not persist the object")
+
+	actions := clientset.Actions()
+	require.NotEmpty(t, actions)
+	assert.Equal(t, []string{metav1.DryRunAll}, actions[0].(ktesting.CreateActionImpl).CreateOptions.DryRun)
+}
+
+func TestBaseResourceService_DryRunUpdate(t *testing.T) {
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}, Data: map[string]string{"mode": "dev"}}
+	svc := NewBaseResourceService[*corev1.ConfigMap](new(ConfigMapClient))
+	clientset := newDryRunClientset(existing)
+
+	changed := existing.DeepCopy()
+	changed.Data["mode"] = "prod"
+	updated, err := svc.DryRunUpdate(clientset, "default", "settings", changed)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", updated.Data["mode"])
+
+	stored, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "dev", stored.Data["mode"], "a dry-run update must not change the stored object")
+
+	_, err = svc.Update(clientset, "default", "settings", changed)
+	require.NoError(t, err)
+	stored, err = clientset.CoreV1().ConfigMaps("default").Get(context.Background(), "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "prod", stored.Data["mode"])
+}
@@ -3,34 +3,43 @@ package service
 import (
 	"context"
 	"fmt"
+	"log"
 	"sort"
 	"time"
 
 	"github.com/ciliverse/cilikube/internal/models"
-	"github.com/ciliverse/cilikube/pkg/k8s"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
 )
 
-// EventService provides business logic for cluster events
-type EventService struct {
-	k8sManager *k8s.ClusterManager
+const (
+	// defaultEventCoalesceWindow drops repeats of an event for the same object and reason
+	defaultEventCoalesceWindow = 10 * time.Second
+	// eventWatchRetryDelay is the wait before reopening an event watch that could not be opened
+	eventWatchRetryDelay = 2 * time.Second
+)
+
+// EventStreamOptions filters and de-duplicates a cluster event stream
+type EventStreamOptions struct {
+	Type           string        // Only stream events of this type (Normal, Warning), empty streams all
+	CoalesceWindow time.Duration // Repeats of an object and reason within the window are dropped, 0 uses the default
 }
 
+// EventService provides business logic for cluster events
+type EventService struct{}
+
 // NewEventService creates a new EventService instance
-func NewEventService(k8sManager *k8s.ClusterManager) *EventService {
-	return &EventService{
-		k8sManager: k8sManager,
-	}
+func NewEventService() *EventService {
+	return &EventService{}
 }
 
 // ListEvents retrieves cluster events based on the provided filters
-func (s *EventService) ListEvents(req models.EventListRequest) (*models.EventListResponse, error) {
-	client, err := s.k8sManager.GetActiveClient()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get active cluster client: %w", err)
-	}
-
+func (s *EventService) ListEvents(clientset kubernetes.Interface, req models.EventListRequest) (*models.EventListResponse, error) {
 	// Set default limit if not specified
 	if req.Limit <= 0 {
 		req.Limit = 50
@@ -46,14 +55,14 @@ func (s *EventService) ListEvents(req models.EventListRequest) (*models.EventLis
 
 	if req.Namespace != "" {
 		// Get events from specific namespace
-		eventList, err := client.Clientset.CoreV1().Events(req.Namespace).List(ctx, metav1.ListOptions{})
+		eventList, err := clientset.CoreV1().Events(req.Namespace).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return nil, fmt.Errorf("failed to list events in namespace %s: %w", req.Namespace, err)
 		}
 		events = eventList.Items
 	} else {
 		// Get events from all namespaces
-		eventList, err := client.Clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{})
+		eventList, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return nil, fmt.Errorf("failed to list events from all namespaces: %w", err)
 		}
@@ -87,7 +96,7 @@ func (s *EventService) ListEvents(req models.EventListRequest) (*models.EventLis
 }
 
 // GetRecentEvents retrieves the most recent cluster events (for dashboard)
-func (s *EventService) GetRecentEvents(limit int) ([]models.ClusterEvent, error) {
+func (s *EventService) GetRecentEvents(clientset kubernetes.Interface, limit int) ([]models.ClusterEvent, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -96,7 +105,7 @@ func (s *EventService) GetRecentEvents(limit int) ([]models.ClusterEvent, error)
 		Limit: limit,
 	}
 
-	response, err := s.ListEvents(req)
+	response, err := s.ListEvents(clientset, req)
 	if err != nil {
 		return nil, err
 	}
@@ -135,12 +144,7 @@ func (s *EventService) filterEvents(events []corev1.Event, req models.EventListR
 }
 
 // GetEventsByObject retrieves events related to a specific Kubernetes object
-func (s *EventService) GetEventsByObject(namespace, kind, name string) ([]models.ClusterEvent, error) {
-	client, err := s.k8sManager.GetActiveClient()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get active cluster client: %w", err)
-	}
-
+func (s *EventService) GetEventsByObject(clientset kubernetes.Interface, namespace, kind, name string) ([]models.ClusterEvent, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -150,7 +154,7 @@ func (s *EventService) GetEventsByObject(namespace, kind, name string) ([]models
 		searchNamespace = metav1.NamespaceAll
 	}
 
-	eventList, err := client.Clientset.CoreV1().Events(searchNamespace).List(ctx, metav1.ListOptions{})
+	eventList, err := clientset.CoreV1().Events(searchNamespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list events: %w", err)
 	}
@@ -177,3 +181,158 @@ func (s *EventService) GetEventsByObject(namespace, kind, name string) ([]models
 
 	return clusterEvents, nil
 }
+
+// StreamEvents watches core/v1 Events in all namespaces and sends those occurring after the call until
+// ctx is done, then closes the channel. Watches closed by the API server are reopened where they left off.
+func (s *EventService) StreamEvents(ctx context.Context, clientset kubernetes.Interface, opts EventStreamOptions) (<-chan models.ClusterEvent, error) {
+	listOpts := metav1.ListOptions{}
+	if opts.Type != "" {
+		listOpts.FieldSelector = fields.OneTermEqualSelector("type", opts.Type).String()
+	}
+	window := opts.CoalesceWindow
+	if window <= 0 {
+		window = defaultEventCoalesceWindow
+	}
+
+	// Start from the current resource version so existing events are not replayed
+	resourceVersion, err := latestEventsResourceVersion(ctx, clientset, listOpts)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := watchEvents(ctx, clientset, listOpts, resourceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan models.ClusterEvent, 16)
+	go func() {
+		defer close(out)
+		coalescer := newEventCoalescer(window, time.Now)
+		for {
+			resourceVersion = forwardEvents(ctx, watcher, out, opts.Type, coalescer, resourceVersion)
+			watcher.Stop()
+			if ctx.Err() != nil {
+				return
+			}
+
+			for watcher = nil; watcher == nil; {
+				if resourceVersion == "" {
+					resourceVersion, err = latestEventsResourceVersion(ctx, clientset, listOpts)
+				}
+				if err == nil {
+					watcher, err = watchEvents(ctx, clientset, listOpts, resourceVersion)
+				}
+				if err != nil {
+					log.Printf("failed to reopen event watch: %v", err)
+					resourceVersion = ""
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(eventWatchRetryDelay):
+					}
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// latestEventsResourceVersion returns the resource version of the events list
+func latestEventsResourceVersion(ctx context.Context, clientset kubernetes.Interface, listOpts metav1.ListOptions) (string, error) {
+	listOpts.Limit = 1
+	list, err := clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, listOpts)
+	if err != nil {
+		return "", fmt.Errorf("failed to list events: %w", err)
+	}
+	return list.ResourceVersion, nil
+}
+
+// watchEvents opens a watch on the events of all namespaces from a resource version
+func watchEvents(ctx context.Context, clientset kubernetes.Interface, listOpts metav1.ListOptions, resourceVersion string) (watch.Interface, error) {
+	listOpts.ResourceVersion = resourceVersion
+	listOpts.AllowWatchBookmarks = true
+	watcher, err := clientset.CoreV1().Events(metav1.NamespaceAll).Watch(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch events: %w", err)
+	}
+	return watcher, nil
+}
+
+// forwardEvents sends the added and updated events of a watch until it ends and returns the last
+// resource version seen, or "" when it expired and the stream must restart from the current state
+func forwardEvents(ctx context.Context, watcher watch.Interface, out chan<- models.ClusterEvent, eventType string, coalescer *eventCoalescer, resourceVersion string) string {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion
+		case result, ok := <-watcher.ResultChan():
+			if !ok {
+				return resourceVersion
+			}
+			switch result.Type {
+			case watch.Error:
+				if err := k8serrors.FromObject(result.Object); k8serrors.IsResourceExpired(err) || k8serrors.IsGone(err) {
+					return ""
+				}
+				return resourceVersion
+			case watch.Bookmark:
+				if object, err := meta.Accessor(result.Object); err == nil {
+					resourceVersion = object.GetResourceVersion()
+				}
+				continue
+			case watch.Deleted:
+				continue
+			}
+
+			event, ok := result.Object.(*corev1.Event)
+			if !ok {
+				continue
+			}
+			resourceVersion = event.ResourceVersion
+			// Field selectors are not honoured everywhere, so filter again
+			if eventType != "" && event.Type != eventType {
+				continue
+			}
+			if !coalescer.allow(event) {
+				continue
+			}
+			select {
+			case out <- models.ConvertK8sEventToClusterEvent(event):
+			case <-ctx.Done():
+				return resourceVersion
+			}
+		}
+	}
+}
+
+// eventCoalescer drops events repeating the involved object and reason of an event sent within the window
+type eventCoalescer struct {
+	window time.Duration
+	now    func() time.Time
+	sent   map[string]time.Time
+}
+
+func newEventCoalescer(window time.Duration, now func() time.Time) *eventCoalescer {
+	return &eventCoalescer{window: window, now: now, sent: make(map[string]time.Time)}
+}
+
+// allow reports whether an event should be sent and records it
+func (c *eventCoalescer) allow(event *corev1.Event) bool {
+	object := event.InvolvedObject
+	key := fmt.Sprintf("%s/%s/%s/%s/%s", object.Kind, object.Namespace, object.Name, object.UID, event.Reason)
+	now := c.now()
+	if last, ok := c.sent[key]; ok && now.Sub(last) < c.window {
+		return false
+	}
+	c.sent[key] = now
+
+	// Forget keys outside the window so long streams don't grow without bound
+	if len(c.sent) > 1024 {
+		for k, last := range c.sent {
+			if now.Sub(last) >= c.window {
+				delete(c.sent, k)
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testEvent(name, namespace, eventType, pod, reason string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Pod", Namespace: namespace, Name: pod, UID: types.UID(pod + "-uid"),
+		},
+		Type:    eventType,
+		Reason:  reason,
+		Message: reason + " for " + pod,
+	}
+}
+
+// receiveEvents reads events from the stream until none arrives for a short while
+func receiveEvents(t *testing.T, events <-chan models.ClusterEvent) []models.ClusterEvent {
+	t.Helper()
+	var received []models.ClusterEvent
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return received
+			}
+			received = append(received, event)
+		case <-time.After(200 * time.Millisecond):
+			return received
+		}
+	}
+}
+
+func TestEventService_StreamEvents(t *testing.T) {
+	existing := testEvent("old", "shop", corev1.EventTypeWarning, "web-0", "BackOff")
+	clientset := fake.NewSimpleClientset(existing)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	svc := NewEventService()
+	events, err := svc.StreamEvents(ctx, clientset, EventStreamOptions{Type: corev1.EventTypeWarning, CoalesceWindow: time.Minute})
+	require.NoError(t, err)
+
+	for _, event := range []*corev1.Event{
+		testEvent("scheduled", "shop", corev1.EventTypeNormal, "web-1", "Scheduled"),
+		testEvent("backoff-1", "shop", corev1.EventTypeWarning, "web-1", "BackOff"),
+		testEvent("backoff-2", "shop", corev1.EventTypeWarning, "web-1", "BackOff"),
+		testEvent("oom", "billing", corev1.EventTypeWarning, "api-0", "OOMKilling"),
+	} {
+		_, err := clientset.CoreV1().Events(event.Namespace).Create(ctx, event, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	received := receiveEvents(t, events)
+	require.Len(t, received, 2, "Normal events are filtered and the repeated BackOff is coalesced")
+	assert.Equal(t, "backoff-1", received[0].Name)
+	assert.Equal(t, "web-1", received[0].Object)
+	assert.Equal(t, "Warning", received[0].Type)
+	assert.Equal(t, "oom", received[1].Name)
+	assert.Equal(t, "billing", received[1].Namespace)
+
+	// Cancelling the request stops the watch and closes the stream
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("stream was not closed after the context was cancelled")
+	}
+}
+
+func TestEventCoalescer(t *testing.T) {
+	now := time.Now()
+	coalescer := newEventCoalescer(10*time.Second, func() time.Time { return now })
+	backOff := testEvent("a", "shop", corev1.EventTypeWarning, "web-0", "BackOff")
+
+	assert.True(t, coalescer.allow(backOff))
+	assert.False(t, coalescer.allow(backOff))
+	assert.True(t, coalescer.allow(testEvent("b", "shop", corev1.EventTypeWarning, "web-0", "Unhealthy")), "other reasons are not coalesced")
+	assert.True(t, coalescer.allow(testEvent("c", "shop", corev1.EventTypeWarning, "web-1", "BackOff")), "other objects are not coalesced")
+
+	now = now.Add(11 * time.Second)
+	assert.True(t, coalescer.allow(backOff), "repeats are sent again once the window has passed")
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Namespace export archive formats
+const (
+	ExportFormatTarGz = "tar.gz"
+	ExportFormatYAML  = "yaml"
+)
+
+// ErrInvalidExport is returned for export requests naming unknown kinds or formats
+var ErrInvalidExport = errors.New("invalid export request")
+
+// ExportKind is a namespaced resource kind that can be exported
+type ExportKind struct {
+	Resource string
+	Kind     string
+	GVR      schema.GroupVersionResource
+}
+
+// exportKinds are the exported kinds, in an order that lets the manifests be applied as they are listed
+var exportKinds = []ExportKind{
+	{Resource: "serviceaccounts", Kind: "ServiceAccount", GVR: schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}},
+	{Resource: "configmaps", Kind: "ConfigMap", GVR: schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}},
+	{Resource: "secrets", Kind: "Secret", GVR: schema.GroupVersionResource{Version: "v1", Resource: "secrets"}},
+	{Resource: "persistentvolumeclaims", Kind: "PersistentVolumeClaim", GVR: schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}},
+	{Resource: "roles", Kind: "Role", GVR: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"}},
+	{Resource: "rolebindings", Kind: "RoleBinding", GVR: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"}},
+	{Resource: "services", Kind: "Service", GVR: schema.GroupVersionResource{Version: "v1", Resource: "services"}},
+	{Resource: "deployments", Kind: "Deployment", GVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}},
+	{Resource: "statefulsets", Kind: "StatefulSet", GVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}},
+	{Resource: "daemonsets", Kind: "DaemonSet", GVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}},
+	{Resource: "cronjobs", Kind: "CronJob", GVR: schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}},
+	{Resource: "jobs", Kind: "Job", GVR: schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}},
+	{Resource: "ingresses", Kind: "Ingress", GVR: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}},
+	{Resource: "networkpolicies", Kind: "NetworkPolicy", GVR: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}},
+	{Resource: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler", GVR: schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}},
+	{Resource: "poddisruptionbudgets", Kind: "PodDisruptionBudget", GVR: schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}},
+}
+
+// exportDroppedAnnotations are set by the cluster and would be stale or misleading when restoring
+var exportDroppedAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"volume.beta.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/selected-node",
+}
+
+// jobGeneratedLabels are added to Job pod templates by the job controller
+var jobGeneratedLabels = []string{"controller-uid", "batch.kubernetes.io/controller-uid"}
+
+// ExportService exports the resources of a namespace as cleaned manifests for backups
+type ExportService struct{}
+
+// NewExportService creates a new ExportService instance
+func NewExportService() *ExportService {
+	return &ExportService{}
+}
+
+// ExportKinds returns every kind that can be exported
+func (s *ExportService) ExportKinds() []ExportKind {
+	return append([]ExportKind(nil), exportKinds...)
+}
+
+// ResolveKinds maps requested resource names or kinds (case-insensitive) to export kinds,
+// keeping the export order. No request selects every kind.
+func (s *ExportService) ResolveKinds(requested []string) ([]ExportKind, error) {
+	if len(requested) == 0 {
+		return s.ExportKinds(), nil
+	}
+
+	selected := make(map[string]bool, len(requested))
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, kind := range exportKinds {
+			if strings.EqualFold(name, kind.Resource) || strings.EqualFold(name, kind.Kind) {
+				selected[kind.Resource] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: unsupported kind %q", ErrInvalidExport, name)
+		}
+	}
+	if len(selected) == 0 {
+		return s.ExportKinds(), nil
+	}
+
+	kinds := make([]ExportKind, 0, len(selected))
+	for _, kind := range exportKinds {
+		if selected[kind.Resource] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// Collect lists the selected kinds in a namespace and returns cleaned copies of the objects worth
+// restoring. Objects managed by a controller or created automatically by the cluster are left out,
+// and kinds the cluster does not serve are skipped.
+func (s *ExportService) Collect(ctx context.Context, client dynamic.Interface, namespace string, kinds []ExportKind) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, kind := range kinds {
+		list, err := client.Resource(kind.GVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s: %w", kind.Resource, err)
+		}
+
+		items := make([]*unstructured.Unstructured, 0, len(list.Items))
+		for i := range list.Items {
+			item := &list.Items[i]
+			if skipExport(kind, item) {
+				continue
+			}
+			items = append(items, cleanForExport(kind, item))
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].GetName() < items[j].GetName() })
+		objects = append(objects, items...)
+	}
+	return objects, nil
+}
+
+// Write writes the objects to w as a gzipped tar archive with one manifest per object,
+// or as a single multi-document YAML stream
+func (s *ExportService) Write(w io.Writer, format, namespace string, objects []*unstructured.Unstructured) error {
+	switch format {
+	case ExportFormatTarGz:
+		return writeExportTarGz(w, namespace, objects)
+	case ExportFormatYAML:
+		return writeExportYAML(w, objects)
+	default:
+		return fmt.Errorf("%w: unsupported format %q", ErrInvalidExport, format)
+	}
+}
+
+// skipExport reports whether an object is recreated by the cluster and so left out of the export
+func skipExport(kind ExportKind, obj *unstructured.Unstructured) bool {
+	if metav1.GetControllerOf(obj) != nil {
+		return true
+	}
+	switch kind.Kind {
+	case "ConfigMap":
+		return obj.GetName() == "kube-root-ca.crt"
+	case "ServiceAccount":
+		return obj.GetName() == "default"
+	case "Secret":
+		secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+		return secretType == "kubernetes.io/service-account-token"
+	case "Service":
+		return obj.GetNamespace() == metav1.NamespaceDefault && obj.GetName() == "kubernetes"
+	}
+	return false
+}
+
+// cleanForExport returns a copy of obj without server-managed and cluster-specific fields
+func cleanForExport(kind ExportKind, obj *unstructured.Unstructured) *unstructured.Unstructured {
+	cleaned := &unstructured.Unstructured{Object: withoutServerFields(obj.Object)}
+	cleaned.SetAPIVersion(kind.GVR.GroupVersion().String())
+	cleaned.SetKind(kind.Kind)
+	cleaned.SetOwnerReferences(nil)
+
+	if annotations := cleaned.GetAnnotations(); len(annotations) > 0 {
+		for _, key := range exportDroppedAnnotations {
+			delete(annotations, key)
+		}
+		cleaned.SetAnnotations(annotations)
+	}
+
+	switch kind.Kind {
+	case "Service":
+		// Headless services keep their "None" cluster IP, allocated ones are assigned again on restore
+		if clusterIP, _, _ := unstructured.NestedString(cleaned.Object, "spec", "clusterIP"); clusterIP != "None" {
+			unstructured.RemoveNestedField(cleaned.Object, "spec", "clusterIP")
+			unstructured.RemoveNestedField(cleaned.Object, "spec", "clusterIPs")
+		}
+		unstructured.RemoveNestedField(cleaned.Object, "spec", "healthCheckNodePort")
+	case "PersistentVolumeClaim":
+		unstructured.RemoveNestedField(cleaned.Object, "spec", "volumeName")
+	case "Job":
+		unstructured.RemoveNestedField(cleaned.Object, "spec", "selector")
+		for _, label := range jobGeneratedLabels {
+			unstructured.RemoveNestedField(cleaned.Object, "spec", "template", "metadata", "labels", label)
+		}
+	}
+	return cleaned
+}
+
+// exportManifest renders an object as a YAML manifest
+func exportManifest(obj *unstructured.Unstructured) ([]byte, error) {
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return data, nil
+}
+
+// writeExportYAML writes the objects as one YAML stream separated by document markers
+func writeExportYAML(w io.Writer, objects []*unstructured.Unstructured) error {
+	for i, obj := range objects {
+		data, err := exportManifest(obj)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeExportTarGz writes one <namespace>/<resource>/<name>.yaml entry per object
+func writeExportTarGz(w io.Writer, namespace string, objects []*unstructured.Unstructured) error {
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	modTime := time.Now()
+
+	for _, obj := range objects {
+		data, err := exportManifest(obj)
+		if err != nil {
+			return err
+		}
+		resource := strings.ToLower(obj.GetKind())
+		for _, kind := range exportKinds {
+			if kind.Kind == obj.GetKind() {
+				resource = kind.Resource
+				break
+			}
+		}
+		header := &tar.Header{
+			Name:    fmt.Sprintf("%s/%s/%s.yaml", namespace, resource, obj.GetName()),
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := archive.Write(data); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
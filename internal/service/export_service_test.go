@@ -0,0 +1,210 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/utils/ptr"
+)
+
+func testExportListKinds() map[schema.GroupVersionResource]string {
+	listKinds := make(map[schema.GroupVersionResource]string, len(exportKinds))
+	for _, kind := range exportKinds {
+		listKinds[kind.GVR] = kind.Kind + "List"
+	}
+	return listKinds
+}
+
+func newTestExportClient(t *testing.T, objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, testExportListKinds(), objects...)
+}
+
+func testExportObjects() []runtime.Object {
+	labels := map[string]string{"app": "web"}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web",
+			Namespace:       "shop",
+			UID:             "deploy-uid",
+			ResourceVersion: "42",
+			Generation:      3,
+			Labels:          labels,
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"deployment.kubernetes.io/revision":                "2",
+				"team":                                             "checkout",
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](2),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx:1.27"}}},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 2},
+	}
+	ownedConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-generated",
+			Namespace:       "shop",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "deploy-uid", Controller: ptr.To(true)}},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "svc-uid"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:  "10.96.0.15",
+			ClusterIPs: []string{"10.96.0.15"},
+			Selector:   labels,
+			Ports:      []corev1.ServicePort{{Port: 80}},
+		},
+	}
+	headless := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-headless", Namespace: "shop"},
+		Spec:       corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone, Selector: labels},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "shop"},
+		Data:       map[string]string{"mode": "production"},
+	}
+	rootCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "shop"},
+	}
+	defaultAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "shop"},
+	}
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-token", Namespace: "shop"},
+		Type:       corev1.SecretTypeServiceAccountToken,
+	}
+	otherNamespace := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "billing"},
+	}
+	return []runtime.Object{deployment, ownedConfigMap, service, headless, configMap, rootCA, defaultAccount, tokenSecret, otherNamespace}
+}
+
+func readExportTarGz(t *testing.T, data []byte) map[string]map[string]interface{} {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	archive := tar.NewReader(gz)
+
+	manifests := make(map[string]map[string]interface{})
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(archive)
+		require.NoError(t, err)
+
+		var manifest map[string]interface{}
+		require.NoError(t, yaml.Unmarshal(content, &manifest))
+		manifests[header.Name] = manifest
+	}
+	return manifests
+}
+
+func TestExportService_TarGzContainsCleanedObjects(t *testing.T) {
+	svc := NewExportService()
+	client := newTestExportClient(t, testExportObjects()...)
+
+	objects, err := svc.Collect(context.Background(), client, "shop", svc.ExportKinds())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.Write(&buf, ExportFormatTarGz, "shop", objects))
+	manifests := readExportTarGz(t, buf.Bytes())
+
+	names := make([]string, 0, len(manifests))
+	for name := range manifests {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{
+		"shop/configmaps/web-config.yaml",
+		"shop/services/web.yaml",
+		"shop/services/web-headless.yaml",
+		"shop/deployments/web.yaml",
+	}, names, "owned, automatic and foreign objects are left out")
+
+	deployment := manifests["shop/deployments/web.yaml"]
+	assert.Equal(t, "apps/v1", deployment["apiVersion"])
+	assert.Equal(t, "Deployment", deployment["kind"])
+	assert.NotContains(t, deployment, "status")
+	metadata := deployment["metadata"].(map[string]interface{})
+	for _, field := range []string{"uid", "resourceVersion", "generation", "managedFields", "creationTimestamp"} {
+		assert.NotContains(t, metadata, field)
+	}
+	assert.Equal(t, map[string]interface{}{"team": "checkout"}, metadata["annotations"])
+	spec := deployment["spec"].(map[string]interface{})
+	assert.Equal(t, 2, spec["replicas"])
+
+	service := manifests["shop/services/web.yaml"]
+	assert.Equal(t, "v1", service["apiVersion"])
+	serviceSpec := service["spec"].(map[string]interface{})
+	assert.NotContains(t, serviceSpec, "clusterIP")
+	assert.NotContains(t, serviceSpec, "clusterIPs")
+	assert.Equal(t, "None", manifests["shop/services/web-headless.yaml"]["spec"].(map[string]interface{})["clusterIP"])
+
+	configMap := manifests["shop/configmaps/web-config.yaml"]
+	assert.Equal(t, map[string]interface{}{"mode": "production"}, configMap["data"])
+}
+
+func TestExportService_YAMLSelectedKinds(t *testing.T) {
+	svc := NewExportService()
+	client := newTestExportClient(t, testExportObjects()...)
+
+	kinds, err := svc.ResolveKinds([]string{"Deployment", "configmaps"})
+	require.NoError(t, err)
+	require.Len(t, kinds, 2)
+	assert.Equal(t, "configmaps", kinds[0].Resource, "export order is kept")
+
+	objects, err := svc.Collect(context.Background(), client, "shop", kinds)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.Write(&buf, ExportFormatYAML, "shop", objects))
+
+	decoder := yaml.NewDecoder(strings.NewReader(buf.String()))
+	var kindsSeen []string
+	for {
+		var manifest map[string]interface{}
+		err := decoder.Decode(&manifest)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		kindsSeen = append(kindsSeen, manifest["kind"].(string)+"/"+manifest["metadata"].(map[string]interface{})["name"].(string))
+	}
+	assert.Equal(t, []string{"ConfigMap/web-config", "Deployment/web"}, kindsSeen)
+}
+
+func TestExportService_ResolveKindsRejectsUnknown(t *testing.T) {
+	_, err := NewExportService().ResolveKinds([]string{"pods"})
+	assert.ErrorIs(t, err, ErrInvalidExport)
+
+	kinds, err := NewExportService().ResolveKinds(nil)
+	require.NoError(t, err)
+	assert.Len(t, kinds, len(exportKinds))
+}
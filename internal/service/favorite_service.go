@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// defaultFavoriteWorkers bounds how many clusters are queried concurrently when resolving favorites
+	defaultFavoriteWorkers = 5
+	// defaultFavoriteClusterTimeout bounds the time spent reading the favorites of one cluster
+	defaultFavoriteClusterTimeout = 10 * time.Second
+)
+
+// ErrInvalidFavorite is returned for favorites that cannot be stored
+var ErrInvalidFavorite = errors.New("invalid favorite")
+
+// favoriteClusterSource is the subset of the cluster manager used by FavoriteService
+type favoriteClusterSource interface {
+	GetClientByID(id string) (*k8s.Client, error)
+}
+
+// FavoriteService manages the resources users bookmarked and reads their current status
+type FavoriteService struct {
+	store          store.Store
+	clusters       favoriteClusterSource
+	mapperFor      func(clusterID string, client *k8s.Client) meta.RESTMapper
+	workers        int
+	clusterTimeout time.Duration
+}
+
+// NewFavoriteService creates a new FavoriteService instance
+func NewFavoriteService(store store.Store, k8sManager *k8s.ClusterManager, customResourceService *CustomResourceService) *FavoriteService {
+	return &FavoriteService{
+		store:    store,
+		clusters: k8sManager,
+		mapperFor: func(clusterID string, client *k8s.Client) meta.RESTMapper {
+			return customResourceService.MapperFor(clusterID, client.DiscoveryClient)
+		},
+		workers:        defaultFavoriteWorkers,
+		clusterTimeout: defaultFavoriteClusterTimeout,
+	}
+}
+
+// ListFavorites returns the user's favorites in the order they were added
+func (s *FavoriteService) ListFavorites(userID uint) ([]models.FavoriteResponse, error) {
+	favorites, err := s.store.ListFavorites(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list favorites: %w", err)
+	}
+	responses := make([]models.FavoriteResponse, len(favorites))
+	for i, favorite := range favorites {
+		responses[i] = toFavoriteResponse(favorite)
+	}
+	return responses, nil
+}
+
+// AddFavorite bookmarks a resource for the user. Adding a resource that is already a favorite returns
+// the existing favorite. The object doesn't have to exist yet.
+func (s *FavoriteService) AddFavorite(userID uint, req *models.AddFavoriteRequest) (*models.FavoriteResponse, error) {
+	favorite := &store.Favorite{
+		UserID:    userID,
+		ClusterID: strings.TrimSpace(req.ClusterID),
+		Namespace: strings.TrimSpace(req.Namespace),
+		Kind:      strings.TrimSpace(req.Kind),
+		Name:      strings.TrimSpace(req.Name),
+	}
+	if favorite.ClusterID == "" || favorite.Kind == "" || favorite.Name == "" {
+		return nil, fmt.Errorf("%w: cluster_id, kind and name are required", ErrInvalidFavorite)
+	}
+	if favorite.Namespace != "" {
+		if errs := validation.IsDNS1123Label(favorite.Namespace); len(errs) > 0 {
+			return nil, fmt.Errorf("%w: namespace '%s': %s", ErrInvalidFavorite, favorite.Namespace, strings.Join(errs, ", "))
+		}
+	}
+	if strings.Contains(favorite.Kind, "/") {
+		return nil, fmt.Errorf("%w: kind '%s' must not contain '/'", ErrInvalidFavorite, favorite.Kind)
+	}
+
+	existing, err := s.store.ListFavorites(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list favorites: %w", err)
+	}
+	for _, other := range existing {
+		if other.ClusterID == favorite.ClusterID && other.Namespace == favorite.Namespace && other.Kind == favorite.Kind && other.Name == favorite.Name {
+			response := toFavoriteResponse(other)
+			return &response, nil
+		}
+	}
+	if err := s.store.CreateFavorite(favorite); err != nil {
+		return nil, fmt.Errorf("failed to add favorite: %w", err)
+	}
+	response := toFavoriteResponse(favorite)
+	return &response, nil
+}
+
+// RemoveFavorite removes one of the user's favorites, returning store.ErrFavoriteNotFound if the user
+// has no favorite with that ID
+func (s *FavoriteService) RemoveFavorite(userID, id uint) error {
+	return s.store.DeleteFavorite(userID, id)
+}
+
+// ResolveFavorites returns the user's favorites with the current status of their objects. Clusters are
+// queried concurrently and the favorites of one resource in a namespace are read with a single list.
+// Favorites of an unreachable cluster, of an unknown kind or that allowed rejects are reported with an
+// error instead of failing the response. allowed may be nil.
+func (s *FavoriteService) ResolveFavorites(ctx context.Context, userID uint, allowed NamespaceFilter) ([]models.ResolvedFavorite, error) {
+	favorites, err := s.store.ListFavorites(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list favorites: %w", err)
+	}
+
+	results := make([]models.ResolvedFavorite, len(favorites))
+	byCluster := make(map[string][]int)
+	var clusterIDs []string
+	for i, favorite := range favorites {
+		results[i].FavoriteResponse = toFavoriteResponse(favorite)
+		if _, seen := byCluster[favorite.ClusterID]; !seen {
+			clusterIDs = append(clusterIDs, favorite.ClusterID)
+		}
+		byCluster[favorite.ClusterID] = append(byCluster[favorite.ClusterID], i)
+	}
+
+	// The filter is shared by the workers and need not be safe for concurrent use
+	if allowed != nil {
+		var mu sync.Mutex
+		filter := allowed
+		allowed = func(namespace, resource string) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return filter(namespace, resource)
+		}
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < s.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for clusterID := range jobs {
+				s.resolveCluster(ctx, clusterID, byCluster[clusterID], results, allowed)
+			}
+		}()
+	}
+	for _, clusterID := range clusterIDs {
+		jobs <- clusterID
+	}
+	close(jobs)
+	wg.Wait()
+	return results, nil
+}
+
+// favoriteGroup identifies the favorites read with one request: those of a resource in a namespace
+type favoriteGroup struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+// resolveCluster fills in the status of the favorites of one cluster, at the given indices of results
+func (s *FavoriteService) resolveCluster(ctx context.Context, clusterID string, indices []int, results []models.ResolvedFavorite, allowed NamespaceFilter) {
+	fail := func(indices []int, err error) {
+		for _, i := range indices {
+			results[i].Error = err.Error()
+		}
+	}
+
+	client, err := s.clusters.GetClientByID(clusterID)
+	if err != nil {
+		fail(indices, err)
+		return
+	}
+	if client.DynamicClient == nil {
+		fail(indices, errors.New("dynamic client not available"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.clusterTimeout)
+	defer cancel()
+
+	mapper := s.mapperFor(clusterID, client)
+	groups := make(map[favoriteGroup][]int)
+	for _, i := range indices {
+		favorite := &results[i]
+		gvr, err := favoriteResource(mapper, favorite.Kind, favorite.Namespace)
+		if err != nil {
+			favorite.Error = err.Error()
+			continue
+		}
+		if allowed != nil && !allowed(favorite.Namespace, gvr.Resource) {
+			favorite.Error = fmt.Sprintf("permission denied: reading %s is not permitted", gvr.Resource)
+			continue
+		}
+		group := favoriteGroup{gvr: gvr, namespace: favorite.Namespace}
+		groups[group] = append(groups[group], i)
+	}
+
+	for group, indices := range groups {
+		resource := client.DynamicClient.Resource(group.gvr).Namespace(group.namespace)
+		if len(indices) == 1 {
+			favorite := &results[indices[0]]
+			obj, err := resource.Get(ctx, favorite.Name, metav1.GetOptions{})
+			switch {
+			case k8serrors.IsNotFound(err):
+			case err != nil:
+				favorite.Error = err.Error()
+			default:
+				setFavoriteStatus(favorite, obj)
+			}
+			continue
+		}
+
+		list, err := resource.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			fail(indices, err)
+			continue
+		}
+		byName := make(map[string]*unstructured.Unstructured, len(list.Items))
+		for j := range list.Items {
+			byName[list.Items[j].GetName()] = &list.Items[j]
+		}
+		for _, i := range indices {
+			if obj, found := byName[results[i].Name]; found {
+				setFavoriteStatus(&results[i], obj)
+			}
+		}
+	}
+}
+
+// favoriteResource resolves the kind of a favorite, given as a kind or resource name optionally
+// qualified by its group, to the resource serving it. The scope of the resource must match whether the
+// favorite has a namespace.
+func favoriteResource(mapper meta.RESTMapper, kind, namespace string) (schema.GroupVersionResource, error) {
+	gvr, err := mapper.ResourceFor(schema.ParseGroupResource(strings.ToLower(kind)).WithVersion(""))
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	if namespaced && namespace == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("%w: %s is namespaced", ErrResourceScopeMismatch, gvr.GroupResource())
+	}
+	if !namespaced && namespace != "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("%w: %s is cluster-scoped", ErrResourceScopeMismatch, gvr.GroupResource())
+	}
+	return mapping.Resource, nil
+}
+
+// setFavoriteStatus records the status of the object of a favorite
+func setFavoriteStatus(favorite *models.ResolvedFavorite, obj *unstructured.Unstructured) {
+	favorite.Found = true
+	favorite.StatusFields = describeStatusFields(obj)
+	favorite.Status, favorite.Ready = summarizeObjectStatus(obj)
+}
+
+// summarizeObjectStatus returns a short status of an object and whether it is ready: replica counts for
+// workloads, the phase for objects that have one, else the Ready or Available condition
+func summarizeObjectStatus(obj *unstructured.Unstructured) (string, *bool) {
+	if desired, found, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled"); found {
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+		return replicaStatus(ready, desired)
+	}
+	if desired, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+		ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		return replicaStatus(ready, desired)
+	}
+
+	var ready *bool
+	for _, condition := range describeConditions(obj) {
+		if condition.Type == "Ready" || (condition.Type == "Available" && ready == nil) {
+			isReady := condition.Status == string(metav1.ConditionTrue)
+			ready = &isReady
+		}
+	}
+	if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase != "" {
+		return phase, ready
+	}
+	if ready != nil {
+		if *ready {
+			return "Ready", ready
+		}
+		return "NotReady", ready
+	}
+	return "", nil
+}
+
+func replicaStatus(ready, desired int64) (string, *bool) {
+	isReady := ready >= desired
+	return fmt.Sprintf("%d/%d ready", ready, desired), &isReady
+}
+
+func toFavoriteResponse(favorite *store.Favorite) models.FavoriteResponse {
+	return models.FavoriteResponse{
+		ID:        favorite.ID,
+		ClusterID: favorite.ClusterID,
+		Namespace: favorite.Namespace,
+		Kind:      favorite.Kind,
+		Name:      favorite.Name,
+		CreatedAt: favorite.CreatedAt,
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func testFavoriteDeployment(name string, replicas, ready int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "shop"},
+		"spec":       map[string]interface{}{"replicas": replicas},
+		"status":     map[string]interface{}{"replicas": replicas, "readyReplicas": ready},
+	}}
+}
+
+func TestFavoriteService_ScopedPerUser(t *testing.T) {
+	svc := NewFavoriteService(store.NewMemoryStore(), nil, nil)
+
+	web, err := svc.AddFavorite(1, &models.AddFavoriteRequest{ClusterID: "prod", Namespace: "shop", Kind: "Deployment", Name: "web"})
+	require.NoError(t, err)
+	again, err := svc.AddFavorite(1, &models.AddFavoriteRequest{ClusterID: "prod", Namespace: "shop", Kind: "Deployment", Name: "web"})
+	require.NoError(t, err)
+	assert.Equal(t, web.ID, again.ID, "adding a favorite twice keeps one")
+	_, err = svc.AddFavorite(2, &models.AddFavoriteRequest{ClusterID: "prod", Namespace: "shop", Kind: "Deployment", Name: "api"})
+	require.NoError(t, err)
+
+	_, err = svc.AddFavorite(1, &models.AddFavoriteRequest{ClusterID: "prod", Namespace: "Not_A_Namespace", Kind: "Deployment", Name: "web"})
+	assert.True(t, errors.Is(err, ErrInvalidFavorite))
+
+	mine, err := svc.ListFavorites(1)
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, "web", mine[0].Name)
+	theirs, err := svc.ListFavorites(2)
+	require.NoError(t, err)
+	require.Len(t, theirs, 1)
+	assert.Equal(t, "api", theirs[0].Name)
+
+	assert.ErrorIs(t, svc.RemoveFavorite(2, web.ID), store.ErrFavoriteNotFound)
+	require.NoError(t, svc.RemoveFavorite(1, web.ID))
+	mine, err = svc.ListFavorites(1)
+	require.NoError(t, err)
+	assert.Empty(t, mine)
+}
+
+func TestFavoriteService_ResolveFavorites(t *testing.T) {
+	client, mapper := newTestImportEnv()
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	require.NoError(t, client.Tracker().Create(deployments, testFavoriteDeployment("web", 3, 2), "shop"))
+	require.NoError(t, client.Tracker().Create(deployments, testFavoriteDeployment("api", 2, 2), "shop"))
+	require.NoError(t, client.Tracker().Create(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": "shop"},
+		"status":     map[string]interface{}{"phase": "Active"},
+	}}, ""))
+
+	svc := NewFavoriteService(store.NewMemoryStore(), nil, nil)
+	svc.clusters = &fakeOverviewClusters{clients: map[string]*k8s.Client{"prod": {DynamicClient: client}}}
+	svc.mapperFor = func(string, *k8s.Client) meta.RESTMapper { return mapper }
+
+	for _, req := range []models.AddFavoriteRequest{
+		{ClusterID: "prod", Namespace: "shop", Kind: "Deployment", Name: "web"},
+		{ClusterID: "prod", Namespace: "shop", Kind: "deployments", Name: "api"},
+		{ClusterID: "prod", Namespace: "shop", Kind: "Deployment", Name: "gone"},
+		{ClusterID: "prod", Kind: "Namespace", Name: "shop"},
+		{ClusterID: "prod", Namespace: "shop", Kind: "ConfigMap", Name: "settings"},
+		{ClusterID: "prod", Namespace: "shop", Kind: "Widget", Name: "w"},
+		{ClusterID: "staging", Namespace: "shop", Kind: "Deployment", Name: "web"},
+	} {
+		_, err := svc.AddFavorite(1, &req)
+		require.NoError(t, err)
+	}
+	_, err := svc.AddFavorite(2, &models.AddFavoriteRequest{ClusterID: "prod", Namespace: "shop", Kind: "Deployment", Name: "api"})
+	require.NoError(t, err)
+
+	denyConfigMaps := func(namespace, resource string) bool { return resource != "configmaps" }
+	resolved, err := svc.ResolveFavorites(context.Background(), 1, denyConfigMaps)
+	require.NoError(t, err)
+	require.Len(t, resolved, 7, "only the caller's favorites are resolved")
+
+	web := resolved[0]
+	assert.True(t, web.Found)
+	assert.Equal(t, "2/3 ready", web.Status)
+	require.NotNil(t, web.Ready)
+	assert.False(t, *web.Ready)
+
+	api := resolved[1]
+	assert.True(t, api.Found)
+	assert.Equal(t, "2/2 ready", api.Status)
+	assert.True(t, *api.Ready)
+
+	gone := resolved[2]
+	assert.False(t, gone.Found)
+	assert.Empty(t, gone.Error, "a deleted object is not an error")
+
+	namespace := resolved[3]
+	assert.True(t, namespace.Found)
+	assert.Equal(t, "Active", namespace.Status)
+
+	assert.Contains(t, resolved[4].Error, "permission denied")
+	assert.NotEmpty(t, resolved[5].Error, "unknown kinds are reported")
+	assert.Contains(t, resolved[6].Error, "staging", "unknown clusters are reported")
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoIPInfo holds the location and network owner resolved for an IP address
+type GeoIPInfo struct {
+	Country        string `json:"country,omitempty"`
+	ASN            uint   `json:"asn,omitempty"`
+	ASOrganization string `json:"as_organization,omitempty"`
+	// Latitude and Longitude are only resolved from GeoLite2-City databases
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// GeoIPProvider resolves IP addresses to country and ASN information.
+// Lookup returns nil without error when the address is unknown.
+type GeoIPProvider interface {
+	Lookup(ip net.IP) (*GeoIPInfo, error)
+}
+
+// MaxMindGeoIPProvider resolves addresses using GeoLite2 Country (or City) and ASN databases
+type MaxMindGeoIPProvider struct {
+	country *maxminddb.Reader
+	asn     *maxminddb.Reader
+}
+
+// countryRecord holds the fields read from GeoLite2 Country and City records
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+// asnRecord holds the fields read from GeoLite2 ASN records
+type asnRecord struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// NewMaxMindGeoIPProvider opens the configured GeoLite2 databases.
+// It returns nil without error when no database is configured.
+func NewMaxMindGeoIPProvider(config configs.GeoIPConfig) (*MaxMindGeoIPProvider, error) {
+	if config.CountryDatabase == "" && config.ASNDatabase == "" {
+		return nil, nil
+	}
+
+	provider := &MaxMindGeoIPProvider{}
+	if config.CountryDatabase != "" {
+		reader, err := maxminddb.Open(config.CountryDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open GeoIP country database: %w", err)
+		}
+		provider.country = reader
+	}
+	if config.ASNDatabase != "" {
+		reader, err := maxminddb.Open(config.ASNDatabase)
+		if err != nil {
+			provider.Close()
+			return nil, fmt.Errorf("failed to open GeoIP ASN database: %w", err)
+		}
+		provider.asn = reader
+	}
+	return provider, nil
+}
+
+// Lookup implements GeoIPProvider
+func (p *MaxMindGeoIPProvider) Lookup(ip net.IP) (*GeoIPInfo, error) {
+	info := &GeoIPInfo{}
+
+	if p.country != nil {
+		var record countryRecord
+		if err := p.country.Lookup(ip, &record); err != nil {
+			return nil, err
+		}
+		info.Country = record.Country.ISOCode
+		if info.Country == "" {
+			info.Country = record.RegisteredCountry.ISOCode
+		}
+		if record.Location.Latitude != nil && record.Location.Longitude != nil {
+			info.Latitude, info.Longitude = record.Location.Latitude, record.Location.Longitude
+		}
+	}
+	if p.asn != nil {
+		var record asnRecord
+		if err := p.asn.Lookup(ip, &record); err != nil {
+			return nil, err
+		}
+		info.ASN = record.Number
+		info.ASOrganization = record.Organization
+	}
+
+	if *info == (GeoIPInfo{}) {
+		return nil, nil
+	}
+	return info, nil
+}
+
+// Close releases the opened databases
+func (p *MaxMindGeoIPProvider) Close() error {
+	var errs []error
+	for _, reader := range []*maxminddb.Reader{p.country, p.asn} {
+		if reader != nil {
+			errs = append(errs, reader.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String formats the info for threat descriptions, e.g. "DE, AS3320 Deutsche Telekom AG"
+func (i *GeoIPInfo) String() string {
+	s := i.Country
+	if i.ASN != 0 {
+		if s != "" {
+			s += ", "
+		}
+		s += fmt.Sprintf("AS%d", i.ASN)
+		if i.ASOrganization != "" {
+			s += " " + i.ASOrganization
+		}
+	}
+	return s
+}
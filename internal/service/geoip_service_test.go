@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubGeoIPProvider resolves addresses from a fixed table
+type stubGeoIPProvider map[string]*GeoIPInfo
+
+func (p stubGeoIPProvider) Lookup(ip net.IP) (*GeoIPInfo, error) {
+	return p[ip.String()], nil
+}
+
+func setupTestAuditService(t *testing.T) (*AuditService, store.Store) {
+	t.Helper()
+	testStore := store.NewMemoryStore()
+	require.NoError(t, testStore.Initialize())
+	return NewAuditService(testStore, &configs.Config{}), testStore
+}
+
+func logFailedLogins(t *testing.T, s *AuditService, ip string, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		require.NoError(t, s.LogAuthenticationEvent(EventTypeLoginFailed, nil, "admin", ip, "curl", false, map[string]interface{}{"reason": "invalid password"}))
+	}
+}
+
+func TestAuditService_GeoIPEnrichment(t *testing.T) {
+	s, testStore := setupTestAuditService(t)
+	s.SetGeoIPProvider(stubGeoIPProvider{
+		"203.0.113.7": {Country: "DE", ASN: 3320, ASOrganization: "Deutsche Telekom AG"},
+	})
+
+	logFailedLogins(t, s, "203.0.113.7", 10)
+	logFailedLogins(t, s, "198.51.100.1", 1)
+
+	logs, _, err := testStore.GetAuditLogsByAction(string(EventTypeLoginFailed), 0, 100)
+	require.NoError(t, err)
+	require.Len(t, logs, 11)
+	for _, entry := range logs {
+		var details map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(entry.Details), &details))
+		assert.Equal(t, "invalid password", details["reason"])
+		if entry.IPAddress == "203.0.113.7" {
+			assert.Equal(t, "DE", details["country"])
+			assert.Equal(t, float64(3320), details["asn"])
+		} else {
+			assert.NotContains(t, details, "country", "unknown addresses are not enriched")
+		}
+	}
+
+	threats, err := s.detectFailedLoginsByIP()
+	require.NoError(t, err)
+	require.Len(t, threats, 1)
+	assert.Equal(t, "Multiple failed login attempts (10) from IP 203.0.113.7 (DE, AS3320 Deutsche Telekom AG)", threats[0].Description)
+	assert.Equal(t, "DE", threats[0].Details["country"])
+}
+
+// encodeMMDBValue encodes maps, strings, doubles and unsigned integers in the MaxMind DB data format
+func encodeMMDBValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		writeMMDBControl(buf, 2, len(v))
+		buf.WriteString(v)
+	case float64:
+		writeMMDBControl(buf, 3, 8)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case uint32:
+		var raw []byte
+		for n := v; n > 0; n >>= 8 {
+			raw = append([]byte{byte(n)}, raw...)
+		}
+		writeMMDBControl(buf, 6, len(raw))
+		buf.Write(raw)
+	case uint16:
+		writeMMDBControl(buf, 5, 2)
+		buf.Write([]byte{byte(v >> 8), byte(v)})
+	case map[string]interface{}:
+		writeMMDBControl(buf, 7, len(v))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			encodeMMDBValue(buf, key)
+			encodeMMDBValue(buf, v[key])
+		}
+	default:
+		panic("unsupported fixture value")
+	}
+}
+
+func writeMMDBControl(buf *bytes.Buffer, fieldType, size int) {
+	if size >= 29+256 {
+		panic("fixture values must be shorter than 285 bytes")
+	}
+	sizeBits, extra := size, []byte(nil)
+	if size >= 29 {
+		sizeBits, extra = 29, []byte{byte(size - 29)}
+	}
+	if fieldType <= 7 {
+		buf.WriteByte(byte(fieldType<<5 | sizeBits))
+	} else {
+		buf.WriteByte(byte(sizeBits))
+		buf.WriteByte(byte(fieldType - 7))
+	}
+	buf.Write(extra)
+}
+
+// writeIPv4Database writes an IPv4 MaxMind DB with 24-bit records mapping a single network to record
+func writeIPv4Database(t *testing.T, network string, record map[string]interface{}) string {
+	t.Helper()
+	_, ipNet, err := net.ParseCIDR(network)
+	require.NoError(t, err)
+	prefix, _ := ipNet.Mask.Size()
+	ip := ipNet.IP.To4()
+
+	const separatorSize = 16
+	nodeCount := uint32(prefix)
+	var tree bytes.Buffer
+	for i := 0; i < prefix; i++ {
+		next := uint32(i + 1)
+		if i == prefix-1 {
+			next = nodeCount + separatorSize
+		}
+		records := [2]uint32{nodeCount, nodeCount}
+		records[ip[i/8]>>(7-uint(i%8))&1] = next
+		for _, r := range records {
+			tree.Write([]byte{byte(r >> 16), byte(r >> 8), byte(r)})
+		}
+	}
+
+	var db bytes.Buffer
+	db.Write(tree.Bytes())
+	db.Write(make([]byte, separatorSize))
+	encodeMMDBValue(&db, record)
+	db.WriteString("\xAB\xCD\xEFMaxMind.com")
+	encodeMMDBValue(&db, map[string]interface{}{
+		"node_count":                  nodeCount,
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(4),
+		"database_type":               "Test",
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint32(1),
+	})
+
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	require.NoError(t, os.WriteFile(path, db.Bytes(), 0o600))
+	return path
+}
+
+func TestMaxMindGeoIPProvider_Lookup(t *testing.T) {
+	provider, err := NewMaxMindGeoIPProvider(configs.GeoIPConfig{
+		CountryDatabase: writeIPv4Database(t, "81.2.69.0/24", map[string]interface{}{
+			"registered_country": map[string]interface{}{"iso_code": "GB"},
+			"location":           map[string]interface{}{"latitude": 51.5, "longitude": -0.13},
+		}),
+		ASNDatabase: writeIPv4Database(t, "81.2.69.0/24", map[string]interface{}{
+			"autonomous_system_number":       uint32(20712),
+			"autonomous_system_organization": "Andrews & Arnold",
+		}),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { provider.Close() })
+
+	info, err := provider.Lookup(net.ParseIP("81.2.69.160"))
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, "GB", info.Country, "the registered country is used when the country is missing")
+	assert.Equal(t, uint(20712), info.ASN)
+	assert.Equal(t, "Andrews & Arnold", info.ASOrganization)
+	require.NotNil(t, info.Latitude)
+	assert.Equal(t, 51.5, *info.Latitude)
+	assert.Equal(t, -0.13, *info.Longitude)
+
+	info, err = provider.Lookup(net.ParseIP("81.2.70.1"))
+	require.NoError(t, err)
+	assert.Nil(t, info, "unknown addresses resolve to nil")
+
+	_, err = provider.Lookup(net.ParseIP("2001:db8::1"))
+	assert.Error(t, err)
+}
+
+func TestAuditService_GeoIPNotConfigured(t *testing.T) {
+	provider, err := NewMaxMindGeoIPProvider(configs.GeoIPConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+
+	_, err = NewMaxMindGeoIPProvider(configs.GeoIPConfig{CountryDatabase: "/nonexistent/GeoLite2-Country.mmdb"})
+	assert.Error(t, err)
+
+	s, testStore := setupTestAuditService(t)
+	assert.Nil(t, s.geoIP)
+	logFailedLogins(t, s, "203.0.113.7", 10)
+
+	logs, _, err := testStore.GetAuditLogsByAction(string(EventTypeLoginFailed), 0, 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"reason":"invalid password"}`, logs[0].Details)
+
+	threats, err := s.detectFailedLoginsByIP()
+	require.NoError(t, err)
+	require.Len(t, threats, 1)
+	assert.Equal(t, "Multiple failed login attempts (10) from IP 203.0.113.7", threats[0].Description)
+	assert.NotContains(t, threats[0].Details, "country")
+}
+
+func loginAt(userID uint, ip string, at time.Time, lat, lon float64) store.AuditLog {
+	details, _ := json.Marshal(map[string]interface{}{"latitude": lat, "longitude": lon})
+	return store.AuditLog{UserID: &userID, Action: string(EventTypeLogin), IPAddress: ip, Details: string(details), CreatedAt: at}
+}
+
+func TestFindImpossibleTravel(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	logins := []store.AuditLog{
+		// Berlin, then New York ten minutes later
+		loginAt(1, "203.0.113.7", start, 52.52, 13.40),
+		loginAt(1, "198.51.100.1", start.Add(10*time.Minute), 40.71, -74.01),
+		// Berlin, then Potsdam ten minutes later is plausible
+		loginAt(2, "203.0.113.8", start, 52.52, 13.40),
+		loginAt(2, "203.0.113.9", start.Add(10*time.Minute), 52.39, 13.06),
+		// Missing coordinates are skipped
+		{UserID: &[]uint{3}[0], Action: string(EventTypeLogin), IPAddress: "203.0.113.10", Details: `{"country":"DE"}`, CreatedAt: start},
+		{UserID: &[]uint{3}[0], Action: string(EventTypeLogin), IPAddress: "198.51.100.2", Details: "User logged in successfully", CreatedAt: start.Add(time.Minute)},
+		loginAt(3, "198.51.100.3", start.Add(2*time.Minute), 40.71, -74.01),
+	}
+
+	threats := findImpossibleTravel(logins, 1000, nil)
+	require.Len(t, threats, 1)
+	threat := threats[0]
+	assert.Equal(t, "impossible_travel", threat.Type)
+	require.NotNil(t, threat.UserID)
+	assert.Equal(t, uint(1), *threat.UserID)
+	assert.Equal(t, "198.51.100.1", threat.IPAddress)
+	assert.Equal(t, "203.0.113.7", threat.Details["from_ip"])
+	assert.InDelta(t, 6385, threat.Details["distance_km"], 20)
+	assert.Greater(t, threat.Details["speed_kmh"], float64(30000))
+
+	assert.Empty(t, findImpossibleTravel(logins[:2], 50000, nil), "a higher speed threshold allows the trip")
+}
+
+func TestAuditService_DetectImpossibleTravel(t *testing.T) {
+	s, _ := setupTestAuditService(t)
+	berlin, newYork := 52.52, 40.71
+	berlinLon, newYorkLon := 13.40, -74.01
+	s.SetGeoIPProvider(stubGeoIPProvider{
+		"203.0.113.7":  {Country: "DE", Latitude: &berlin, Longitude: &berlinLon},
+		"198.51.100.1": {Country: "US", Latitude: &newYork, Longitude: &newYorkLon},
+	})
+
+	userID := uint(42)
+	require.NoError(t, s.LogAuthenticationEvent(EventTypeLogin, &userID, "alice", "203.0.113.7", "curl", true, nil))
+	require.NoError(t, s.LogAuthenticationEvent(EventTypeLogin, &userID, "alice", "198.51.100.1", "curl", true, nil))
+
+	threats, err := s.DetectAnomalousActivity()
+	require.NoError(t, err)
+	var travel []SecurityThreat
+	for _, threat := range threats {
+		if threat.Type == "impossible_travel" {
+			travel = append(travel, threat)
+		}
+	}
+	require.Len(t, travel, 1)
+	assert.Equal(t, uint(42), *travel[0].UserID)
+}
+
+func TestAuthService_LoginTriggersImpossibleTravel(t *testing.T) {
+	previousConfig := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() { configs.GlobalConfig = previousConfig })
+
+	authService, testStore := setupTestAuthService()
+	berlin, newYork := 52.52, 40.71
+	berlinLon, newYorkLon := 13.40, -74.01
+	authService.auditService.SetGeoIPProvider(stubGeoIPProvider{
+		"203.0.113.7":  {Country: "DE", Latitude: &berlin, Longitude: &berlinLon},
+		"198.51.100.1": {Country: "US", Latitude: &newYork, Longitude: &newYorkLon},
+	})
+
+	user := &store.User{Username: "alice", Email: "alice@example.com", PasswordHash: "password123", IsActive: true}
+	require.NoError(t, testStore.CreateUser(user))
+	viewerRole, err := testStore.GetRoleByName("viewer")
+	require.NoError(t, err)
+	require.NoError(t, testStore.AssignRole(user.ID, viewerRole.ID))
+
+	request := &models.LoginRequest{Username: "alice", Password: "password123"}
+	_, err = authService.Login(requestContext("203.0.113.7", "curl"), request)
+	require.NoError(t, err)
+	_, err = authService.Login(requestContext("198.51.100.1", "curl"), request)
+	require.NoError(t, err)
+
+	threats, err := authService.auditService.DetectAnomalousActivity()
+	require.NoError(t, err)
+	var travel []SecurityThreat
+	for _, threat := range threats {
+		if threat.Type == "impossible_travel" {
+			travel = append(travel, threat)
+		}
+	}
+	require.Len(t, travel, 1, "logins recorded by the auth service are located from their IP address")
+	assert.Equal(t, user.ID, *travel[0].UserID)
+	assert.Equal(t, "198.51.100.1", travel[0].IPAddress)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// helmReleaseSecretType is the Secret type Helm 3 uses for its storage driver
+const helmReleaseSecretType = "helm.sh/release.v1"
+
+// gzipMagic is the header Helm writes in front of compressed release payloads
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// storedHelmRelease mirrors the subset of Helm's release JSON that is shown in the UI
+type storedHelmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status       string    `json:"status"`
+		Description  string    `json:"description"`
+		LastDeployed time.Time `json:"last_deployed"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// HelmService reads Helm 3 release state from release Secrets. It is read-only.
+type HelmService struct{}
+
+// NewHelmService creates a new HelmService instance
+func NewHelmService() *HelmService {
+	return &HelmService{}
+}
+
+// ListReleases returns the latest revision of every release in a namespace, or in all namespaces when namespace is empty
+func (s *HelmService) ListReleases(clientset kubernetes.Interface, namespace string) (*models.HelmReleaseListResponse, error) {
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("type", helmReleaseSecretType).String(),
+		LabelSelector: "owner=helm",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list helm release secrets: %w", err)
+	}
+
+	latest := make(map[string]models.HelmRelease)
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		// The field selector is not honoured by every client, so check the type again
+		if secret.Type != helmReleaseSecretType {
+			continue
+		}
+		release, err := decodeHelmReleaseSecret(secret)
+		if err != nil {
+			// A single corrupt release should not hide the others
+			continue
+		}
+		key := release.Namespace + "/" + release.Name
+		if current, ok := latest[key]; !ok || release.Revision > current.Revision {
+			latest[key] = *release
+		}
+	}
+
+	items := make([]models.HelmRelease, 0, len(latest))
+	for _, release := range latest {
+		items = append(items, release)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	return &models.HelmReleaseListResponse{
+		Items: items,
+		Total: len(items),
+	}, nil
+}
+
+// decodeHelmReleaseSecret decodes the release stored in a Helm Secret.
+// The payload is base64 encoded and, since Helm 3, gzip compressed JSON.
+func decodeHelmReleaseSecret(secret *corev1.Secret) (*models.HelmRelease, error) {
+	payload, ok := secret.Data["release"]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no release data", secret.Namespace, secret.Name)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(string(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode release payload: %w", err)
+	}
+	if bytes.HasPrefix(data, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress release payload: %w", err)
+		}
+		defer reader.Close()
+		if data, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to decompress release payload: %w", err)
+		}
+	}
+
+	var stored storedHelmRelease
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse release payload: %w", err)
+	}
+
+	namespace := stored.Namespace
+	if namespace == "" {
+		namespace = secret.Namespace
+	}
+	return &models.HelmRelease{
+		Name:         stored.Name,
+		Namespace:    namespace,
+		Revision:     stored.Version,
+		Chart:        stored.Chart.Metadata.Name,
+		ChartVersion: stored.Chart.Metadata.Version,
+		AppVersion:   stored.Chart.Metadata.AppVersion,
+		Status:       stored.Info.Status,
+		Description:  stored.Info.Description,
+		Updated:      stored.Info.LastDeployed,
+	}, nil
+}
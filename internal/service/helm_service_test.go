@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// encodeTestRelease encodes a release payload the way Helm's Secret driver does
+func encodeTestRelease(t *testing.T, payload string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+func newTestReleaseSecret(t *testing.T, namespace, name string, revision int, status string) *corev1.Secret {
+	payload := fmt.Sprintf(`{
+		"name": %q,
+		"namespace": %q,
+		"version": %d,
+		"info": {"status": %q, "description": "Install complete", "last_deployed": "2024-05-01T10:00:00Z"},
+		"chart": {"metadata": {"name": "nginx", "version": "15.1.0", "appVersion": "1.25.3"}}
+	}`, name, namespace, revision, status)
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("sh.helm.release.v1.%s.v%d", name, revision),
+			Namespace: namespace,
+			Labels:    map[string]string{"owner": "helm", "name": name, "status": status},
+		},
+		Type: helmReleaseSecretType,
+		Data: map[string][]byte{"release": encodeTestRelease(t, payload)},
+	}
+}
+
+func TestDecodeHelmReleaseSecret(t *testing.T) {
+	release, err := decodeHelmReleaseSecret(newTestReleaseSecret(t, "web", "frontend", 3, "deployed"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "frontend", release.Name)
+	assert.Equal(t, "web", release.Namespace)
+	assert.Equal(t, 3, release.Revision)
+	assert.Equal(t, "nginx", release.Chart)
+	assert.Equal(t, "15.1.0", release.ChartVersion)
+	assert.Equal(t, "1.25.3", release.AppVersion)
+	assert.Equal(t, "deployed", release.Status)
+	assert.Equal(t, 2024, release.Updated.Year())
+
+	// Payloads written without compression are still readable
+	plain := &corev1.Secret{Data: map[string][]byte{
+		"release": []byte(base64.StdEncoding.EncodeToString([]byte(`{"name":"old","version":1}`))),
+	}}
+	release, err = decodeHelmReleaseSecret(plain)
+	require.NoError(t, err)
+	assert.Equal(t, "old", release.Name)
+
+	_, err = decodeHelmReleaseSecret(&corev1.Secret{})
+	assert.Error(t, err)
+}
+
+func TestHelmService_ListReleases(t *testing.T) {
+	corrupt := newTestReleaseSecret(t, "web", "broken", 1, "deployed")
+	corrupt.Data["release"] = []byte("not base64!")
+	unrelated := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "web", Labels: map[string]string{"owner": "helm"}},
+		Type:       corev1.SecretTypeOpaque,
+	}
+
+	clientset := fake.NewSimpleClientset(
+		newTestReleaseSecret(t, "web", "frontend", 1, "superseded"),
+		newTestReleaseSecret(t, "web", "frontend", 2, "deployed"),
+		newTestReleaseSecret(t, "db", "postgres", 1, "failed"),
+		corrupt,
+		unrelated,
+	)
+
+	svc := NewHelmService()
+	result, err := svc.ListReleases(clientset, "")
+	require.NoError(t, err)
+
+	require.Equal(t, 2, result.Total)
+	assert.Equal(t, "postgres", result.Items[0].Name)
+	assert.Equal(t, "failed", result.Items[0].Status)
+	// Only the latest revision of a release is listed
+	assert.Equal(t, "frontend", result.Items[1].Name)
+	assert.Equal(t, 2, result.Items[1].Revision)
+	assert.Equal(t, "deployed", result.Items[1].Status)
+
+	result, err = svc.ListReleases(clientset, "db")
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrScannerNotConfigured is returned when an image scan is requested but no scanner is configured
+var ErrScannerNotConfigured = errors.New("no image scanner is configured")
+
+// Vulnerability severities counted in scan results
+const (
+	VulnSeverityCritical = "CRITICAL"
+	VulnSeverityHigh     = "HIGH"
+	VulnSeverityMedium   = "MEDIUM"
+	VulnSeverityLow      = "LOW"
+	VulnSeverityUnknown  = "UNKNOWN"
+)
+
+// Defaults used for unset image scan settings
+const (
+	defaultImageScanTimeout       = 5 * time.Minute
+	defaultImageScanCacheTTL      = 24 * time.Hour
+	defaultImageScanMaxConcurrent = 2
+)
+
+// ScanResult counts the vulnerabilities found in an image by severity
+type ScanResult struct {
+	Image      string         `json:"image"`
+	Digest     string         `json:"digest,omitempty"`
+	Severities map[string]int `json:"severities"`
+	ScannedAt  time.Time      `json:"scannedAt"`
+}
+
+// Total returns the number of vulnerabilities of all severities
+func (r ScanResult) Total() int {
+	total := 0
+	for _, count := range r.Severities {
+		total += count
+	}
+	return total
+}
+
+// ImageScanner scans container images for known vulnerabilities, giving up when ctx is done
+type ImageScanner interface {
+	Scan(ctx context.Context, image string) (ScanResult, error)
+}
+
+// TrivyScanner scans images with the Trivy CLI, locally or against a Trivy server in client mode
+type TrivyScanner struct {
+	path      string
+	serverURL string
+
+	// run executes the Trivy command and returns its standard output, replaced in tests
+	run func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// NewTrivyScanner creates a scanner running the configured Trivy binary
+func NewTrivyScanner(config configs.ImageScanConfig) (*TrivyScanner, error) {
+	path := config.TrivyPath
+	if path == "" {
+		path = "trivy"
+	}
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("trivy binary not found: %w", err)
+	}
+	return &TrivyScanner{
+		path:      resolved,
+		serverURL: config.ServerURL,
+		run:       runCommand,
+	}, nil
+}
+
+// runCommand runs a command and returns its standard output, including standard error in failures
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return output, err
+}
+
+// args returns the Trivy command line scanning image. The image comes from the pod spec, "--" keeps
+// one starting with a dash from being read as an option.
+func (s *TrivyScanner) args(image string) []string {
+	args := []string{"image", "--quiet", "--format", "json", "--scanners", "vuln"}
+	if s.serverURL != "" {
+		args = append(args, "--server", s.serverURL)
+	}
+	return append(args, "--", image)
+}
+
+// Scan implements ImageScanner
+func (s *TrivyScanner) Scan(ctx context.Context, image string) (ScanResult, error) {
+	output, err := s.run(ctx, s.path, s.args(image)...)
+	if err != nil {
+		return ScanResult{}, fmt.Errorf("trivy scan of %s failed: %w", image, err)
+	}
+	return parseTrivyReport(image, output)
+}
+
+// trivyReport is the part of Trivy's JSON report needed to count vulnerabilities
+type trivyReport struct {
+	Metadata struct {
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []struct {
+		Vulnerabilities []struct {
+			Severity string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// parseTrivyReport counts the vulnerabilities of a Trivy JSON report by severity
+func parseTrivyReport(image string, data []byte) (ScanResult, error) {
+	var report trivyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return ScanResult{}, fmt.Errorf("failed to parse trivy report: %w", err)
+	}
+
+	result := ScanResult{
+		Image: image,
+		Severities: map[string]int{
+			VulnSeverityCritical: 0,
+			VulnSeverityHigh:     0,
+			VulnSeverityMedium:   0,
+			VulnSeverityLow:      0,
+			VulnSeverityUnknown:  0,
+		},
+		ScannedAt: time.Now(),
+	}
+	if len(report.Metadata.RepoDigests) > 0 {
+		result.Digest = imageDigest(report.Metadata.RepoDigests[0])
+	}
+	for _, target := range report.Results {
+		for _, vulnerability := range target.Vulnerabilities {
+			severity := strings.ToUpper(vulnerability.Severity)
+			if _, ok := result.Severities[severity]; !ok {
+				severity = VulnSeverityUnknown
+			}
+			result.Severities[severity]++
+		}
+	}
+	return result, nil
+}
+
+// PodImageScan is the scan result of one of a pod's images
+type PodImageScan struct {
+	Image      string         `json:"image"`
+	Digest     string         `json:"digest,omitempty"`
+	Containers []string       `json:"containers"`
+	Severities map[string]int `json:"severities,omitempty"`
+	Total      int            `json:"total"`
+	ScannedAt  *time.Time     `json:"scannedAt,omitempty"`
+	Cached     bool           `json:"cached"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// PodScanResult is the scan result of all images of a pod
+type PodScanResult struct {
+	Namespace  string         `json:"namespace"`
+	Name       string         `json:"name"`
+	Images     []PodImageScan `json:"images"`
+	Severities map[string]int `json:"severities"` // Totals across all images
+	Failed     int            `json:"failed"`     // Images that could not be scanned
+}
+
+// cachedScan is a scan result reused until it expires
+type cachedScan struct {
+	result  ScanResult
+	expires time.Time
+}
+
+// ImageScanService scans the images of pods and caches the results by image digest. Scans are bounded
+// by a timeout per pod and a number running at once across all requests.
+type ImageScanService struct {
+	scanner ImageScanner
+	ttl     time.Duration
+	timeout time.Duration
+	slots   chan struct{}
+
+	mu    sync.Mutex
+	cache map[string]cachedScan
+}
+
+// NewImageScanService creates the service with the configured scanner. Scanning stays disabled when no
+// scanner is configured or the configured one can't be created.
+func NewImageScanService(config configs.ImageScanConfig) *ImageScanService {
+	ttl := config.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultImageScanCacheTTL
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultImageScanTimeout
+	}
+	maxConcurrent := config.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultImageScanMaxConcurrent
+	}
+	s := &ImageScanService{ttl: ttl, timeout: timeout, slots: make(chan struct{}, maxConcurrent), cache: make(map[string]cachedScan)}
+
+	switch strings.ToLower(config.Scanner) {
+	case "":
+	case "trivy":
+		scanner, err := NewTrivyScanner(config)
+		if err != nil {
+			log.Printf("Warning: image scanning disabled: %v", err)
+		} else {
+			s.scanner = scanner
+		}
+	default:
+		log.Printf("Warning: image scanning disabled: unknown scanner %q", config.Scanner)
+	}
+	return s
+}
+
+// SetScanner sets the scanner used for new scans and drops cached results, nil disables scanning
+func (s *ImageScanService) SetScanner(scanner ImageScanner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scanner = scanner
+	s.cache = make(map[string]cachedScan)
+}
+
+// Timeout returns how long the scan of a pod's images may take
+func (s *ImageScanService) Timeout() time.Duration {
+	return s.timeout
+}
+
+// Enabled reports whether a scanner is configured
+func (s *ImageScanService) Enabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scanner != nil
+}
+
+// ScanPod scans each distinct image of a pod's containers within the scan timeout. Images with a known digest
+// are scanned by digest and their results are reused until the cache TTL expires. A failed scan is reported on
+// its image without failing the others.
+func (s *ImageScanService) ScanPod(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*PodScanResult, error) {
+	s.mu.Lock()
+	scanner := s.scanner
+	s.mu.Unlock()
+	if scanner == nil {
+		return nil, ErrScannerNotConfigured
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PodScanResult{
+		Namespace:  namespace,
+		Name:       name,
+		Images:     podImages(pod),
+		Severities: make(map[string]int),
+	}
+	for i := range result.Images {
+		image := &result.Images[i]
+		scan, cached, err := s.scan(ctx, scanner, image.Image, image.Digest)
+		if err != nil {
+			image.Error = err.Error()
+			result.Failed++
+			continue
+		}
+		if image.Digest == "" {
+			image.Digest = scan.Digest
+		}
+		image.Severities = scan.Severities
+		image.Total = scan.Total()
+		scannedAt := scan.ScannedAt
+		image.ScannedAt = &scannedAt
+		image.Cached = cached
+		for severity, count := range scan.Severities {
+			result.Severities[severity] += count
+		}
+	}
+	return result, nil
+}
+
+// scan returns the cached result of digest or scans the image, pinned to digest when it is known, once one
+// of the scan slots is free
+func (s *ImageScanService) scan(ctx context.Context, scanner ImageScanner, image, digest string) (ScanResult, bool, error) {
+	if digest != "" {
+		s.mu.Lock()
+		entry, ok := s.cache[digest]
+		s.mu.Unlock()
+		if ok && time.Now().Before(entry.expires) {
+			return entry.result, true, nil
+		}
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return ScanResult{}, false, ctx.Err()
+	}
+	result, err := scanner.Scan(ctx, pinnedImage(image, digest))
+	if err != nil {
+		return ScanResult{}, false, err
+	}
+	if digest != "" {
+		s.mu.Lock()
+		s.cache[digest] = cachedScan{result: result, expires: time.Now().Add(s.ttl)}
+		s.mu.Unlock()
+	}
+	return result, false, nil
+}
+
+// podImages returns the distinct images of a pod's init and regular containers with the repository digests
+// the kubelet reports for them. Images without one, such as those still being pulled, are not cached.
+func podImages(pod *corev1.Pod) []PodImageScan {
+	digests := make(map[string]string)
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			// A bare sha256 image ID identifies the local image config, not a pullable digest
+			if strings.Contains(status.ImageID, "@") {
+				digests[status.Name] = imageDigest(status.ImageID)
+			}
+		}
+	}
+
+	var images []PodImageScan
+	index := make(map[string]int)
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			digest := imageDigest(container.Image)
+			if digest == "" {
+				digest = digests[container.Name]
+			}
+			key := container.Image + "@" + digest
+			if i, ok := index[key]; ok {
+				images[i].Containers = append(images[i].Containers, container.Name)
+				continue
+			}
+			index[key] = len(images)
+			images = append(images, PodImageScan{Image: container.Image, Digest: digest, Containers: []string{container.Name}})
+		}
+	}
+	return images
+}
+
+// imageDigest returns the sha256 digest of an image reference or image ID, or "" when it has none
+func imageDigest(ref string) string {
+	if i := strings.LastIndex(ref, "sha256:"); i >= 0 {
+		return ref[i:]
+	}
+	return ""
+}
+
+// pinnedImage returns image referenced by digest, so the scanned image is the one that is running
+func pinnedImage(image, digest string) string {
+	if digest == "" || strings.Contains(image, "@") {
+		return image
+	}
+	// Drop the tag, which follows the last colon after the last slash
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + "@" + digest
+}
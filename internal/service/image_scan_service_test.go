@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testWebDigest     = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	testSidecarDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// stubImageScanner returns canned results and records the scanned images
+type stubImageScanner struct {
+	results map[string]ScanResult
+	scanned []string
+}
+
+func (s *stubImageScanner) Scan(ctx context.Context, image string) (ScanResult, error) {
+	s.scanned = append(s.scanned, image)
+	result, ok := s.results[image]
+	if !ok {
+		return ScanResult{}, errors.New("manifest unknown")
+	}
+	result.Image = image
+	result.ScannedAt = time.Now()
+	return result, nil
+}
+
+func newTestScanPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "test"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate", Image: "registry.example.com/web:1.4.0"}},
+			Containers: []corev1.Container{
+				{Name: "web", Image: "registry.example.com/web:1.4.0"},
+				{Name: "sidecar", Image: "registry.example.com:5000/proxy:2"},
+				{Name: "debug", Image: "busybox:latest"},
+			},
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "migrate", ImageID: "registry.example.com/web@" + testWebDigest}},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "web", ImageID: "docker-pullable://registry.example.com/web@" + testWebDigest},
+				{Name: "sidecar", ImageID: "registry.example.com:5000/proxy@" + testSidecarDigest},
+				// A bare image ID is not a pullable digest
+				{Name: "debug", ImageID: "sha256:3333333333333333333333333333333333333333333333333333333333333333"},
+			},
+		},
+	}
+}
+
+func newTestImageScanService(scanner ImageScanner) *ImageScanService {
+	svc := NewImageScanService(configs.ImageScanConfig{})
+	svc.SetScanner(scanner)
+	return svc
+}
+
+func TestImageScanService_ScanPod(t *testing.T) {
+	scanner := &stubImageScanner{results: map[string]ScanResult{
+		"registry.example.com/web@" + testWebDigest:            {Severities: map[string]int{VulnSeverityCritical: 1, VulnSeverityHigh: 2}},
+		"registry.example.com:5000/proxy@" + testSidecarDigest: {Severities: map[string]int{VulnSeverityLow: 4}},
+		"busybox:latest": {Severities: map[string]int{VulnSeverityMedium: 1}},
+	}}
+	svc := newTestImageScanService(scanner)
+	clientset := fake.NewSimpleClientset(newTestScanPod())
+
+	result, err := svc.ScanPod(context.Background(), clientset, "test", "web-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"registry.example.com/web@" + testWebDigest,
+		"registry.example.com:5000/proxy@" + testSidecarDigest,
+		"busybox:latest",
+	}, scanner.scanned, "each image is scanned once, pinned to its running digest")
+
+	require.Len(t, result.Images, 3)
+	web := result.Images[0]
+	assert.Equal(t, []string{"migrate", "web"}, web.Containers)
+	assert.Equal(t, testWebDigest, web.Digest)
+	assert.Equal(t, 3, web.Total)
+	assert.False(t, web.Cached)
+	assert.Empty(t, result.Images[2].Digest)
+	assert.Equal(t, map[string]int{VulnSeverityCritical: 1, VulnSeverityHigh: 2, VulnSeverityMedium: 1, VulnSeverityLow: 4}, result.Severities)
+	assert.Zero(t, result.Failed)
+
+	// Images with a digest are served from the cache, others are scanned again
+	result, err = svc.ScanPod(context.Background(), clientset, "test", "web-1")
+	require.NoError(t, err)
+	assert.Len(t, scanner.scanned, 4)
+	assert.Equal(t, "busybox:latest", scanner.scanned[3])
+	assert.True(t, result.Images[0].Cached)
+	assert.True(t, result.Images[1].Cached)
+	assert.False(t, result.Images[2].Cached)
+	assert.Equal(t, 3, result.Images[0].Total)
+}
+
+func TestImageScanService_FailedImage(t *testing.T) {
+	scanner := &stubImageScanner{results: map[string]ScanResult{
+		"registry.example.com/web@" + testWebDigest: {Severities: map[string]int{VulnSeverityHigh: 1}},
+	}}
+	svc := newTestImageScanService(scanner)
+
+	result, err := svc.ScanPod(context.Background(), fake.NewSimpleClientset(newTestScanPod()), "test", "web-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, 1, result.Images[0].Total)
+	assert.Equal(t, "manifest unknown", result.Images[1].Error)
+	assert.Equal(t, map[string]int{VulnSeverityHigh: 1}, result.Severities)
+}
+
+func TestImageScanService_NotConfigured(t *testing.T) {
+	svc := NewImageScanService(configs.ImageScanConfig{})
+	assert.False(t, svc.Enabled())
+	_, err := svc.ScanPod(context.Background(), fake.NewSimpleClientset(newTestScanPod()), "test", "web-1")
+	assert.ErrorIs(t, err, ErrScannerNotConfigured)
+
+	svc = NewImageScanService(configs.ImageScanConfig{Scanner: "trivy", TrivyPath: "/nonexistent/trivy"})
+	assert.False(t, svc.Enabled(), "a scanner that can't be created leaves scanning disabled")
+}
+
+func TestTrivyScanner_Scan(t *testing.T) {
+	var gotArgs []string
+	scanner := &TrivyScanner{path: "trivy", serverURL: "http://trivy:4954",
+		run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			gotArgs = args
+			return []byte(`{
+				"Metadata": {"RepoDigests": ["nginx@` + testWebDigest + `"]},
+				"Results": [
+					{"Target": "nginx (debian 12.5)", "Vulnerabilities": [
+						{"VulnerabilityID": "CVE-2024-0001", "Severity": "CRITICAL"},
+						{"VulnerabilityID": "CVE-2024-0002", "Severity": "HIGH"},
+						{"VulnerabilityID": "CVE-2024-0003", "Severity": "HIGH"}
+					]},
+					{"Target": "usr/local/bin/app", "Vulnerabilities": [
+						{"VulnerabilityID": "GHSA-xxxx", "Severity": "MEDIUM"},
+						{"VulnerabilityID": "CVE-2024-0004", "Severity": "NEGLIGIBLE"}
+					]},
+					{"Target": "empty"}
+				]
+			}`), nil
+		}}
+
+	result, err := scanner.Scan(context.Background(), "nginx:1.27")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"image", "--quiet", "--format", "json", "--scanners", "vuln", "--server", "http://trivy:4954", "--", "nginx:1.27"}, gotArgs)
+	assert.Equal(t, testWebDigest, result.Digest)
+	assert.Equal(t, map[string]int{
+		VulnSeverityCritical: 1, VulnSeverityHigh: 2, VulnSeverityMedium: 1, VulnSeverityLow: 0, VulnSeverityUnknown: 1,
+	}, result.Severities)
+	assert.Equal(t, 5, result.Total())
+}
+
+// blockingImageScanner holds each scan until release is closed or the scan's context is done
+type blockingImageScanner struct {
+	running chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingImageScanner) Scan(ctx context.Context, image string) (ScanResult, error) {
+	s.running <- struct{}{}
+	select {
+	case <-s.release:
+		return ScanResult{Image: image, Severities: map[string]int{}}, nil
+	case <-ctx.Done():
+		return ScanResult{}, ctx.Err()
+	}
+}
+
+func TestImageScanService_BoundsScans(t *testing.T) {
+	scanner := &blockingImageScanner{running: make(chan struct{}, 10), release: make(chan struct{})}
+	svc := NewImageScanService(configs.ImageScanConfig{MaxConcurrent: 1, Timeout: 200 * time.Millisecond})
+	svc.SetScanner(scanner)
+	clientset := fake.NewSimpleClientset(newTestScanPod())
+
+	done := make(chan *PodScanResult)
+	for i := 0; i < 2; i++ {
+		go func() {
+			result, err := svc.ScanPod(context.Background(), clientset, "test", "web-1")
+			assert.NoError(t, err)
+			done <- result
+		}()
+	}
+	<-scanner.running
+	select {
+	case <-scanner.running:
+		t.Fatal("a second scan started while the only slot was taken")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Both pod scans give up at the timeout, the images they could not scan are reported as failed
+	for i := 0; i < 2; i++ {
+		result := <-done
+		assert.Equal(t, 3, result.Failed)
+		assert.Equal(t, context.DeadlineExceeded.Error(), result.Images[2].Error)
+	}
+}
+
+func TestPinnedImage(t *testing.T) {
+	for image, want := range map[string]string{
+		"nginx":                      "nginx@" + testWebDigest,
+		"nginx:1.27":                 "nginx@" + testWebDigest,
+		"registry:5000/team/app":     "registry:5000/team/app@" + testWebDigest,
+		"registry:5000/team/app:v2":  "registry:5000/team/app@" + testWebDigest,
+		"nginx@" + testSidecarDigest: "nginx@" + testSidecarDigest,
+	} {
+		assert.Equal(t, want, pinnedImage(image, testWebDigest), image)
+	}
+	assert.Equal(t, "nginx:1.27", pinnedImage("nginx:1.27", ""))
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// ImportFieldManager is the server-side apply field manager used when restoring manifests
+const ImportFieldManager = "cilikube-import"
+
+// maxImportArchiveBytes bounds the decompressed size of an import archive
+const maxImportArchiveBytes = 64 << 20
+
+// Outcomes of importing a single object
+const (
+	ImportApplied = "applied"
+	ImportFailed  = "failed"
+)
+
+// ErrInvalidArchive is returned for import archives that cannot be read
+var ErrInvalidArchive = errors.New("invalid import archive")
+
+// ImportOptions controls how an archive is restored
+type ImportOptions struct {
+	// DryRun validates every object with the API server without persisting it
+	DryRun bool
+	// Allowed reports whether the caller may write a resource; nil allows everything
+	Allowed func(resource string) bool
+}
+
+// ImportObjectResult is the outcome of restoring one object of an archive
+type ImportObjectResult struct {
+	APIVersion      string `json:"apiVersion"`
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	SourceNamespace string `json:"sourceNamespace,omitempty"`
+	Status          string `json:"status"`
+	Error           string `json:"error,omitempty"`
+}
+
+// ImportResult reports the outcome of restoring an archive into a namespace
+type ImportResult struct {
+	Namespace string               `json:"namespace"`
+	DryRun    bool                 `json:"dryRun"`
+	Applied   int                  `json:"applied"`
+	Failed    int                  `json:"failed"`
+	Objects   []ImportObjectResult `json:"objects"`
+}
+
+// ImportService restores manifests produced by the namespace export into a namespace
+type ImportService struct{}
+
+// NewImportService creates a new ImportService instance
+func NewImportService() *ImportService {
+	return &ImportService{}
+}
+
+// ParseArchive reads the objects of a gzipped tar archive or of a multi-document YAML stream.
+// Archives are recognized by their gzip header; every .yaml, .yml or .json entry is read in order.
+func (s *ImportService) ParseArchive(data []byte) ([]*unstructured.Unstructured, error) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		objects, err := decodeManifests(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		return objects, nil
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	archive := tar.NewReader(io.LimitReader(gz, maxImportArchiveBytes))
+	var objects []*unstructured.Unstructured
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		switch path.Ext(header.Name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, header.Name, err)
+		}
+		entryObjects, err := decodeManifests(content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidArchive, header.Name, err)
+		}
+		objects = append(objects, entryObjects...)
+	}
+	return objects, nil
+}
+
+// Import applies each object into namespace with server-side apply, whatever namespace it was
+// exported from. Failures are recorded per object and do not stop the remaining objects.
+func (s *ImportService) Import(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, namespace string, objects []*unstructured.Unstructured, opts ImportOptions) *ImportResult {
+	result := &ImportResult{
+		Namespace: namespace,
+		DryRun:    opts.DryRun,
+		Objects:   make([]ImportObjectResult, 0, len(objects)),
+	}
+
+	applyOpts := metav1.ApplyOptions{FieldManager: ImportFieldManager, Force: true}
+	if opts.DryRun {
+		applyOpts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	for _, obj := range objects {
+		entry := ImportObjectResult{
+			APIVersion:      obj.GetAPIVersion(),
+			Kind:            obj.GetKind(),
+			Name:            obj.GetName(),
+			SourceNamespace: obj.GetNamespace(),
+			Status:          ImportApplied,
+		}
+		if err := applyNamespacedObject(ctx, client, mapper, namespace, prepareImportObject(obj, namespace), applyOpts, opts.Allowed); err != nil {
+			entry.Status = ImportFailed
+			entry.Error = err.Error()
+			result.Failed++
+		} else {
+			result.Applied++
+		}
+		result.Objects = append(result.Objects, entry)
+	}
+	return result
+}
+
+// applyNamespacedObject applies obj, which must be of a namespaced kind, into namespace with server-side apply
+func applyNamespacedObject(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, namespace string, obj *unstructured.Unstructured, applyOpts metav1.ApplyOptions, allowed func(string) bool) error {
+	gvk := obj.GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return fmt.Errorf("%w: apiVersion and kind are required", ErrInvalidResource)
+	}
+	if obj.GetName() == "" {
+		return fmt.Errorf("%w: metadata.name is required", ErrInvalidResource)
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return fmt.Errorf("%w: %s is cluster-scoped", ErrResourceScopeMismatch, mapping.Resource.GroupResource())
+	}
+	if allowed != nil && !allowed(mapping.Resource.Resource) {
+		return fmt.Errorf("permission denied: writing %s in namespace %s is not permitted", mapping.Resource.Resource, namespace)
+	}
+
+	_, err = client.Resource(mapping.Resource).Namespace(namespace).Apply(ctx, obj.GetName(), obj, applyOpts)
+	return err
+}
+
+// prepareImportObject returns a copy of obj moved into namespace and without fields that server-side
+// apply rejects or that point at objects of the source cluster. ServiceAccount subjects of bindings
+// that referred to the source namespace follow the objects into the target namespace.
+func prepareImportObject(obj *unstructured.Unstructured, namespace string) *unstructured.Unstructured {
+	sourceNamespace := obj.GetNamespace()
+	desired := &unstructured.Unstructured{Object: withoutServerFields(obj.Object)}
+	desired.SetNamespace(namespace)
+	desired.SetOwnerReferences(nil)
+
+	if desired.GetKind() != "RoleBinding" || sourceNamespace == "" || sourceNamespace == namespace {
+		return desired
+	}
+	subjects, found, _ := unstructured.NestedSlice(desired.Object, "subjects")
+	if !found {
+		return desired
+	}
+	for _, subject := range subjects {
+		fields, ok := subject.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if fields["kind"] == "ServiceAccount" && fields["namespace"] == sourceNamespace {
+			fields["namespace"] = namespace
+		}
+	}
+	_ = unstructured.SetNestedSlice(desired.Object, subjects, "subjects")
+	return desired
+}
+
+// decodeManifests decodes a YAML or JSON stream of manifests, skipping empty documents
+func decodeManifests(data []byte) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	var objects []*unstructured.Unstructured
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
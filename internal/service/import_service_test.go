@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestImportEnv returns an empty fake dynamic client whose server-side apply creates missing
+// objects, and a RESTMapper knowing the exported kinds plus the cluster-scoped Namespace kind
+func newTestImportEnv() (*dynamicfake.FakeDynamicClient, meta.RESTMapper) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), testExportListKinds())
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patch.GetPatch()); err != nil {
+			return true, nil, err
+		}
+		tracker := client.Tracker()
+		_, err := tracker.Get(action.GetResource(), action.GetNamespace(), patch.GetName())
+		switch {
+		case k8serrors.IsNotFound(err):
+			err = tracker.Create(action.GetResource(), obj, action.GetNamespace())
+		case err == nil:
+			err = tracker.Update(action.GetResource(), obj, action.GetNamespace())
+		}
+		return true, obj, err
+	})
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, kind := range exportKinds {
+		mapper.AddSpecific(kind.GVR.GroupVersion().WithKind(kind.Kind), kind.GVR, kind.GVR.GroupVersion().WithResource(strings.ToLower(kind.Kind)), meta.RESTScopeNamespace)
+	}
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	return client, mapper
+}
+
+func TestImportService_RestoresExportArchive(t *testing.T) {
+	exporter := NewExportService()
+	source := newTestExportClient(t, testExportObjects()...)
+	objects, err := exporter.Collect(context.Background(), source, "shop", exporter.ExportKinds())
+	require.NoError(t, err)
+	var archive bytes.Buffer
+	require.NoError(t, exporter.Write(&archive, ExportFormatTarGz, "shop", objects))
+
+	svc := NewImportService()
+	parsed, err := svc.ParseArchive(archive.Bytes())
+	require.NoError(t, err)
+	require.Len(t, parsed, 4)
+
+	client, mapper := newTestImportEnv()
+	result := svc.Import(context.Background(), client, mapper, "shop-restore", parsed, ImportOptions{})
+	assert.Equal(t, 4, result.Applied)
+	assert.Zero(t, result.Failed)
+	assert.Equal(t, "shop", result.Objects[0].SourceNamespace)
+
+	deployment, err := client.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}).
+		Namespace("shop-restore").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	replicas, _, _ := unstructured.NestedFieldNoCopy(deployment.Object, "spec", "replicas")
+	assert.EqualValues(t, 2, replicas)
+
+	services, err := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "services"}).
+		Namespace("shop-restore").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, services.Items, 2)
+
+	configMap, err := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+		Namespace("shop-restore").Get(context.Background(), "web-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "shop-restore", configMap.GetNamespace())
+}
+
+func TestImportService_ContinuesPastFailures(t *testing.T) {
+	manifests := `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: shop
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: reader
+  namespace: shop
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: reader
+subjects:
+- kind: ServiceAccount
+  name: web
+  namespace: shop
+`
+	svc := NewImportService()
+	objects, err := svc.ParseArchive([]byte(manifests))
+	require.NoError(t, err)
+	require.Len(t, objects, 4)
+
+	client, mapper := newTestImportEnv()
+	result := svc.Import(context.Background(), client, mapper, "staging", objects, ImportOptions{
+		Allowed: func(resource string) bool { return resource != "secrets" },
+	})
+
+	assert.Equal(t, 1, result.Applied)
+	assert.Equal(t, 3, result.Failed)
+	statuses := make(map[string]string, len(result.Objects))
+	for _, entry := range result.Objects {
+		statuses[entry.Kind] = entry.Status
+		if entry.Status == ImportFailed {
+			assert.NotEmpty(t, entry.Error)
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"Namespace":   ImportFailed,
+		"Widget":      ImportFailed,
+		"Secret":      ImportFailed,
+		"RoleBinding": ImportApplied,
+	}, statuses)
+
+	binding, err := client.Resource(schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"}).
+		Namespace("staging").Get(context.Background(), "reader", metav1.GetOptions{})
+	require.NoError(t, err)
+	subjects, _, _ := unstructured.NestedSlice(binding.Object, "subjects")
+	require.Len(t, subjects, 1)
+	assert.Equal(t, "staging", subjects[0].(map[string]interface{})["namespace"], "ServiceAccount subjects follow the namespace")
+}
+
+func TestImportService_DryRun(t *testing.T) {
+	svc := NewImportService()
+	objects, err := svc.ParseArchive([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n"))
+	require.NoError(t, err)
+
+	client, mapper := newTestImportEnv()
+	result := svc.Import(context.Background(), client, mapper, "shop", objects, ImportOptions{DryRun: true})
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 1, result.Applied)
+
+	var patches int
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "patch" {
+			patches++
+		}
+	}
+	assert.Equal(t, 1, patches, "dry runs are still sent to the API server for validation")
+}
+
+func TestImportService_ParseArchiveRejectsGarbage(t *testing.T) {
+	_, err := NewImportService().ParseArchive([]byte{0x1f, 0x8b, 0x00})
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+
+	_, err = NewImportService().ParseArchive([]byte("kind: [unterminated"))
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+}
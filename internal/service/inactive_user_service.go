@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/store"
+)
+
+// Defaults used for unset inactive user settings
+const (
+	defaultInactiveDays         = 90
+	defaultInactiveUserInterval = 24 * time.Hour
+	inactiveUserPageSize        = 100
+)
+
+// InactiveUserService periodically deactivates accounts that haven't logged in for the configured number of days.
+// Admins and exempt users are never deactivated.
+type InactiveUserService struct {
+	store    store.Store
+	security *SecurityService
+	enabled  bool
+	dryRun   bool
+	inactive time.Duration
+	interval time.Duration
+	exempt   map[string]bool
+
+	mu       sync.Mutex
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+// NewInactiveUserService creates the job with the configured threshold, interval and exempt users
+func NewInactiveUserService(store store.Store, config configs.InactiveUsersConfig) *InactiveUserService {
+	days := config.InactiveDays
+	if days <= 0 {
+		days = defaultInactiveDays
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = defaultInactiveUserInterval
+	}
+	exempt := make(map[string]bool, len(config.ExemptUsers))
+	for _, username := range config.ExemptUsers {
+		exempt[username] = true
+	}
+	return &InactiveUserService{
+		store:    store,
+		security: NewSecurityService(store, nil),
+		enabled:  config.Enabled,
+		dryRun:   config.DryRun,
+		inactive: time.Duration(days) * 24 * time.Hour,
+		interval: interval,
+		exempt:   exempt,
+	}
+}
+
+// Start checks accounts right away and then on every interval until Stop is called. It does nothing when the job is disabled.
+func (s *InactiveUserService) Start() {
+	if !s.enabled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopChan != nil {
+		return // Already running
+	}
+	s.stopChan = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.run(s.stopChan, s.done)
+}
+
+// Stop stops the job and waits for a check in progress to finish
+func (s *InactiveUserService) Stop() {
+	s.mu.Lock()
+	stopChan, done := s.stopChan, s.done
+	s.stopChan, s.done = nil, nil
+	s.mu.Unlock()
+	if stopChan == nil {
+		return // Not running
+	}
+	close(stopChan)
+	<-done
+}
+
+func (s *InactiveUserService) run(stopChan <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.DeactivateInactiveUsers()
+	for {
+		select {
+		case <-ticker.C:
+			s.DeactivateInactiveUsers()
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+// DeactivateInactiveUsers deactivates the active users whose last login, or creation when they never logged in,
+// is older than the threshold, logging them out and writing an audit log entry for each. In dry-run mode the users
+// are only logged.
+// It returns the usernames deactivated, or that would be in dry-run mode.
+func (s *InactiveUserService) DeactivateInactiveUsers() []string {
+	cutoff := time.Now().Add(-s.inactive)
+	stale, err := s.staleUsers(cutoff)
+	if err != nil {
+		log.Printf("Warning: failed to list users for inactivity deactivation: %v", err)
+	}
+
+	var deactivated []string
+	for _, user := range stale {
+		if s.dryRun {
+			log.Printf("Dry run: would deactivate user %s, inactive since %s", user.Username, lastActivity(user).Format(time.RFC3339))
+			deactivated = append(deactivated, user.Username)
+			continue
+		}
+		user.IsActive = false
+		if err := s.store.UpdateUser(user); err != nil {
+			log.Printf("Warning: failed to deactivate inactive user %s: %v", user.Username, err)
+			continue
+		}
+		s.security.RevokeUserAccess(user.ID)
+		details := fmt.Sprintf("User deactivated after %d days without login, inactive since %s",
+			int(s.inactive/(24*time.Hour)), lastActivity(user).Format(time.RFC3339))
+		auditLog := newAuditLog(context.Background(), nil, "user_status_change", "user", fmt.Sprintf("%d", user.ID), details)
+		if err := s.store.CreateAuditLog(auditLog); err != nil {
+			log.Printf("Warning: failed to audit deactivation of user %s: %v", user.Username, err)
+		}
+		deactivated = append(deactivated, user.Username)
+	}
+
+	log.Printf("Inactivity check deactivated %d users (dry run: %t)", len(deactivated), s.dryRun)
+	return deactivated
+}
+
+// staleUsers returns the active, non-exempt users inactive since before cutoff
+func (s *InactiveUserService) staleUsers(cutoff time.Time) ([]*store.User, error) {
+	var stale []*store.User
+	for offset := 0; ; offset += inactiveUserPageSize {
+		users, total, err := s.store.ListUsers(offset, inactiveUserPageSize)
+		if err != nil {
+			return stale, err
+		}
+		for _, user := range users {
+			if !user.IsActive || user.DeletedAt != nil || s.exempt[user.Username] || !lastActivity(user).Before(cutoff) {
+				continue
+			}
+			admin, err := s.isAdmin(user.ID)
+			if err != nil {
+				log.Printf("Warning: skipping inactive user %s, failed to get roles: %v", user.Username, err)
+				continue
+			}
+			if !admin {
+				stale = append(stale, user)
+			}
+		}
+		if len(users) == 0 || int64(offset+len(users)) >= total {
+			return stale, nil
+		}
+	}
+}
+
+func (s *InactiveUserService) isAdmin(userID uint) (bool, error) {
+	roles, err := s.store.GetUserRoles(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role.Name == "admin" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// lastActivity is when the user last logged in, or was created when they never did
+func lastActivity(user *store.User) time.Time {
+	if user.LastLoginAt != nil {
+		return *user.LastLoginAt
+	}
+	return user.CreatedAt
+}
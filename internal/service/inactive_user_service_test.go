@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newInactiveUsersStore returns a store with users in different states of activity, keyed by username
+func newInactiveUsersStore(t *testing.T) (store.Store, map[string]uint) {
+	t.Helper()
+	memoryStore := store.NewMemoryStore()
+	adminRole := &store.Role{Name: "admin", DisplayName: "Administrator"}
+	require.NoError(t, memoryStore.CreateRole(adminRole))
+
+	now := time.Now()
+	days := func(n int) *time.Time {
+		at := now.AddDate(0, 0, -n)
+		return &at
+	}
+	users := []struct {
+		username  string
+		lastLogin *time.Time
+		created   time.Time
+		inactive  bool
+		admin     bool
+	}{
+		{username: "stale", lastLogin: days(100), created: now.AddDate(-1, 0, 0)},
+		{username: "recent", lastLogin: days(5), created: now.AddDate(-1, 0, 0)},
+		{username: "never-logged-in", created: now.AddDate(0, 0, -100)},
+		{username: "new", created: now.AddDate(0, 0, -1)},
+		{username: "stale-admin", lastLogin: days(200), created: now.AddDate(-1, 0, 0), admin: true},
+		{username: "system", lastLogin: days(200), created: now.AddDate(-1, 0, 0)},
+		{username: "already-inactive", lastLogin: days(200), created: now.AddDate(-1, 0, 0), inactive: true},
+	}
+	ids := make(map[string]uint, len(users))
+	for _, u := range users {
+		user := &store.User{Username: u.username, Email: u.username + "@example.com", PasswordHash: "password123", IsActive: true}
+		require.NoError(t, memoryStore.CreateUser(user))
+		user.LastLoginAt = u.lastLogin
+		user.CreatedAt = u.created
+		user.IsActive = !u.inactive
+		require.NoError(t, memoryStore.UpdateUser(user))
+		if u.admin {
+			require.NoError(t, memoryStore.AssignRole(user.ID, adminRole.ID))
+		}
+		ids[u.username] = user.ID
+	}
+	return memoryStore, ids
+}
+
+func TestInactiveUserService_DeactivateInactiveUsers(t *testing.T) {
+	config := configs.InactiveUsersConfig{Enabled: true, InactiveDays: 30, ExemptUsers: []string{"system"}}
+
+	t.Run("only stale non-exempt users are deactivated", func(t *testing.T) {
+		previousConfig := configs.GlobalConfig
+		configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour}}
+		t.Cleanup(func() { configs.GlobalConfig = previousConfig })
+		memoryStore, ids := newInactiveUsersStore(t)
+		token, _, err := auth.GenerateToken(&models.User{ID: ids["stale"], Username: "stale"})
+		require.NoError(t, err)
+
+		deactivated := NewInactiveUserService(memoryStore, config).DeactivateInactiveUsers()
+		assert.ElementsMatch(t, []string{"stale", "never-logged-in"}, deactivated)
+		_, err = auth.ParseToken(token)
+		assert.ErrorIs(t, err, auth.ErrTokenRevoked, "deactivated users are logged out")
+
+		for username, id := range ids {
+			user, err := memoryStore.GetUserByID(id)
+			require.NoError(t, err)
+			wantActive := username != "stale" && username != "never-logged-in" && username != "already-inactive"
+			assert.Equal(t, wantActive, user.IsActive, username)
+		}
+
+		logs, total, err := memoryStore.GetAuditLogsByAction("user_status_change", 0, 10)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, total, "each deactivation is audited")
+		audited := make([]string, 0, len(logs))
+		for _, entry := range logs {
+			assert.Equal(t, "user", entry.Resource)
+			assert.Contains(t, entry.Details, "30 days without login")
+			audited = append(audited, entry.ResourceID)
+		}
+		assert.ElementsMatch(t, []string{fmt.Sprint(ids["stale"]), fmt.Sprint(ids["never-logged-in"])}, audited)
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		memoryStore, ids := newInactiveUsersStore(t)
+		dryRun := config
+		dryRun.DryRun = true
+
+		deactivated := NewInactiveUserService(memoryStore, dryRun).DeactivateInactiveUsers()
+		assert.ElementsMatch(t, []string{"stale", "never-logged-in"}, deactivated)
+
+		user, err := memoryStore.GetUserByID(ids["stale"])
+		require.NoError(t, err)
+		assert.True(t, user.IsActive)
+		_, total, err := memoryStore.GetAuditLogsByAction("user_status_change", 0, 10)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+}
+
+func TestInactiveUserService_StartDisabled(t *testing.T) {
+	memoryStore, ids := newInactiveUsersStore(t)
+	job := NewInactiveUserService(memoryStore, configs.InactiveUsersConfig{InactiveDays: 30, Interval: 10 * time.Millisecond})
+
+	job.Start()
+	time.Sleep(50 * time.Millisecond)
+	job.Stop()
+
+	user, err := memoryStore.GetUserByID(ids["stale"])
+	require.NoError(t, err)
+	assert.True(t, user.IsActive, "the job is opt-in")
+}
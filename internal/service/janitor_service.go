@@ -0,0 +1,100 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/store"
+)
+
+// Defaults used for unset cleanup settings
+const (
+	defaultCleanupInterval       = time.Hour
+	defaultLoginAttemptRetention = 30 * 24 * time.Hour
+)
+
+// JanitorService periodically removes expired sessions and old login attempts from the store,
+// so they don't grow without bound
+type JanitorService struct {
+	store     store.Store
+	interval  time.Duration
+	retention time.Duration
+
+	mu       sync.Mutex
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+// NewJanitorService creates the janitor with the configured interval and login attempt retention
+func NewJanitorService(store store.Store, config configs.CleanupConfig) *JanitorService {
+	interval := config.Interval
+	if interval <= 0 {
+		interval = defaultCleanupInterval
+	}
+	retention := config.LoginAttemptRetention
+	if retention <= 0 {
+		retention = defaultLoginAttemptRetention
+	}
+	return &JanitorService{store: store, interval: interval, retention: retention}
+}
+
+// Start runs a cleanup right away and then on every interval until Stop is called
+func (j *JanitorService) Start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.stopChan != nil {
+		return // Already running
+	}
+	j.stopChan = make(chan struct{})
+	j.done = make(chan struct{})
+	go j.run(j.stopChan, j.done)
+}
+
+// Stop stops the janitor and waits for a cleanup in progress to finish
+func (j *JanitorService) Stop() {
+	j.mu.Lock()
+	stopChan, done := j.stopChan, j.done
+	j.stopChan, j.done = nil, nil
+	j.mu.Unlock()
+	if stopChan == nil {
+		return // Not running
+	}
+	close(stopChan)
+	<-done
+}
+
+func (j *JanitorService) run(stopChan <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.Cleanup()
+	for {
+		select {
+		case <-ticker.C:
+			j.Cleanup()
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+// Cleanup removes expired sessions and login attempts older than the retention, and logs how many were removed.
+// A failure of one cleanup is logged without skipping the other.
+func (j *JanitorService) Cleanup() (sessions, loginAttempts int64) {
+	now := time.Now()
+
+	sessions, err := j.store.CleanupExpiredSessions(now)
+	if err != nil {
+		log.Printf("Warning: failed to clean up expired sessions: %v", err)
+	}
+	loginAttempts, err = j.store.CleanupOldLoginAttempts(now.Add(-j.retention))
+	if err != nil {
+		log.Printf("Warning: failed to clean up old login attempts: %v", err)
+	}
+
+	log.Printf("Store cleanup removed %d expired sessions and %d old login attempts", sessions, loginAttempts)
+	return sessions, loginAttempts
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCleanupStore records the cleanup calls made to the store
+type countingCleanupStore struct {
+	store.Store
+	failSessions bool
+
+	mu              sync.Mutex
+	sessionCleanups int
+	attemptsBefore  []time.Time
+}
+
+func (s *countingCleanupStore) CleanupExpiredSessions(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessionCleanups++
+	if s.failSessions {
+		return 0, errors.New("database is locked")
+	}
+	return s.Store.CleanupExpiredSessions(before)
+}
+
+func (s *countingCleanupStore) CleanupOldLoginAttempts(before time.Time) (int64, error) {
+	s.mu.Lock()
+	s.attemptsBefore = append(s.attemptsBefore, before)
+	s.mu.Unlock()
+	return s.Store.CleanupOldLoginAttempts(before)
+}
+
+func (s *countingCleanupStore) calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessionCleanups
+}
+
+func TestJanitorService_Cleanup(t *testing.T) {
+	memoryStore := store.NewMemoryStore()
+	now := time.Now()
+	require.NoError(t, memoryStore.CreateLoginAttempt(&store.LoginAttempt{Username: "alice", CreatedAt: now.AddDate(0, 0, -10)}))
+	require.NoError(t, memoryStore.CreateLoginAttempt(&store.LoginAttempt{Username: "alice", CreatedAt: now.AddDate(0, 0, -2)}))
+	require.NoError(t, memoryStore.CreateUserSession(&store.UserSession{SessionID: "janitor-expired", UserID: 77, ExpiresAt: now.Add(-time.Minute), IsActive: true}))
+	require.NoError(t, memoryStore.CreateUserSession(&store.UserSession{SessionID: "janitor-live", UserID: 77, ExpiresAt: now.Add(time.Hour), IsActive: true}))
+	t.Cleanup(func() { memoryStore.DeleteUserSessions(77) })
+
+	janitor := NewJanitorService(memoryStore, configs.CleanupConfig{LoginAttemptRetention: 7 * 24 * time.Hour})
+	sessions, loginAttempts := janitor.Cleanup()
+	assert.Equal(t, int64(1), sessions)
+	assert.Equal(t, int64(1), loginAttempts)
+
+	remaining, err := memoryStore.GetLoginAttemptsByUsername("alice", time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, remaining, 1)
+	live, err := memoryStore.GetUserSessions(77)
+	require.NoError(t, err)
+	require.Len(t, live, 1)
+	assert.Equal(t, "janitor-live", live[0].SessionID)
+
+	t.Run("a failing cleanup doesn't skip the other", func(t *testing.T) {
+		countingStore := &countingCleanupStore{Store: store.NewMemoryStore(), failSessions: true}
+		NewJanitorService(countingStore, configs.CleanupConfig{}).Cleanup()
+		assert.Equal(t, 1, countingStore.calls())
+		require.Len(t, countingStore.attemptsBefore, 1)
+		assert.WithinDuration(t, time.Now().Add(-defaultLoginAttemptRetention), countingStore.attemptsBefore[0], time.Minute)
+	})
+}
+
+func TestJanitorService_StartStop(t *testing.T) {
+	countingStore := &countingCleanupStore{Store: store.NewMemoryStore()}
+	janitor := NewJanitorService(countingStore, configs.CleanupConfig{Interval: 10 * time.Millisecond})
+
+	janitor.Start()
+	janitor.Start() // Starting twice runs a single loop
+	assert.Eventually(t, func() bool { return countingStore.calls() >= 3 }, time.Second, 5*time.Millisecond,
+		"cleanup runs on start and on every interval")
+
+	janitor.Stop()
+	stopped := countingStore.calls()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, countingStore.calls(), "no cleanup runs after Stop returns")
+	janitor.Stop() // Stopping again is a no-op
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// defaultKubeconfigTokenTTL is used when the caller does not request a token lifetime
+	defaultKubeconfigTokenTTL int64 = 3600
+	// rootCAConfigMap is published into every namespace by kube-controller-manager
+	rootCAConfigMap = "kube-root-ca.crt"
+)
+
+// KubeconfigService generates kubeconfig files for ServiceAccounts
+type KubeconfigService struct{}
+
+// NewKubeconfigService creates a new KubeconfigService instance
+func NewKubeconfigService() *KubeconfigService {
+	return &KubeconfigService{}
+}
+
+// GenerateServiceAccountKubeconfig requests a token for the ServiceAccount and returns a kubeconfig in YAML format.
+// The cluster CA is read from the kube-root-ca.crt ConfigMap, falling back to the CA data or file of the given rest config.
+func (s *KubeconfigService) GenerateServiceAccountKubeconfig(clientset kubernetes.Interface, config *rest.Config, clusterName, namespace, name string, ttlSeconds int64) ([]byte, error) {
+	ctx := context.TODO()
+
+	if _, err := clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	if ttlSeconds <= 0 {
+		ttlSeconds = defaultKubeconfigTokenTTL
+	}
+	tokenRequest, err := clientset.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &ttlSeconds},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request service account token: %w", err)
+	}
+	if tokenRequest.Status.Token == "" {
+		return nil, fmt.Errorf("token request for service account %s/%s returned no token", namespace, name)
+	}
+
+	caData, err := s.clusterCA(clientset, config, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfig := buildServiceAccountKubeconfig(config.Host, caData, clusterName, namespace, name, tokenRequest.Status.Token)
+	return clientcmd.Write(*kubeconfig)
+}
+
+// clusterCA returns the PEM encoded CA bundle of the cluster
+func (s *KubeconfigService) clusterCA(clientset kubernetes.Interface, config *rest.Config, namespace string) ([]byte, error) {
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), rootCAConfigMap, metav1.GetOptions{})
+	if err == nil && cm.Data["ca.crt"] != "" {
+		return []byte(cm.Data["ca.crt"]), nil
+	}
+	if len(config.CAData) > 0 {
+		return config.CAData, nil
+	}
+	if config.CAFile != "" {
+		caData, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cluster CA file: %w", err)
+		}
+		return caData, nil
+	}
+	return nil, fmt.Errorf("unable to determine cluster CA")
+}
+
+// buildServiceAccountKubeconfig assembles a single-context kubeconfig authenticating with a bearer token
+func buildServiceAccountKubeconfig(server string, caData []byte, clusterName, namespace, name, token string) *clientcmdapi.Config {
+	userName := fmt.Sprintf("%s-%s", namespace, name)
+	contextName := fmt.Sprintf("%s@%s", userName, clusterName)
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = &clientcmdapi.Cluster{
+		Server:                   server,
+		CertificateAuthorityData: caData,
+	}
+	config.AuthInfos[userName] = &clientcmdapi.AuthInfo{Token: token}
+	config.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:   clusterName,
+		AuthInfo:  userName,
+		Namespace: namespace,
+	}
+	config.CurrentContext = contextName
+	return config
+}
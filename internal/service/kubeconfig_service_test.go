@@ -0,0 +1,115 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testCAPEM = "-----BEGIN CERTIFICATE-----\nMIIBfake\n-----END CERTIFICATE-----\n"
+
+// newTokenClientset returns a fake clientset whose TokenRequest API issues the given token
+func newTokenClientset(token string, objects ...runtime.Object) (*fake.Clientset, *int64) {
+	clientset := fake.NewSimpleClientset(objects...)
+	requestedTTL := new(int64)
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		req := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		*requestedTTL = *req.Spec.ExpirationSeconds
+		req.Status.Token = token
+		return true, req, nil
+	})
+	return clientset, requestedTTL
+}
+
+func TestKubeconfigService_GenerateServiceAccountKubeconfig(t *testing.T) {
+	clientset, requestedTTL := newTokenClientset("sa-token",
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "team-a"}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: rootCAConfigMap, Namespace: "team-a"},
+			Data:       map[string]string{"ca.crt": testCAPEM},
+		},
+	)
+	config := &rest.Config{Host: "https://10.0.0.1:6443"}
+
+	svc := NewKubeconfigService()
+	data, err := svc.GenerateServiceAccountKubeconfig(clientset, config, "prod", "team-a", "deployer", 7200)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7200), *requestedTTL)
+
+	kubeconfig, err := clientcmd.Load(data)
+	require.NoError(t, err)
+
+	require.Equal(t, "team-a-deployer@prod", kubeconfig.CurrentContext)
+	ctx := kubeconfig.Contexts[kubeconfig.CurrentContext]
+	require.NotNil(t, ctx)
+	assert.Equal(t, "prod", ctx.Cluster)
+	assert.Equal(t, "team-a", ctx.Namespace)
+
+	cluster := kubeconfig.Clusters["prod"]
+	require.NotNil(t, cluster)
+	assert.Equal(t, "https://10.0.0.1:6443", cluster.Server)
+	assert.Equal(t, testCAPEM, string(cluster.CertificateAuthorityData))
+
+	user := kubeconfig.AuthInfos[ctx.AuthInfo]
+	require.NotNil(t, user)
+	assert.Equal(t, "sa-token", user.Token)
+}
+
+func TestKubeconfigService_CAFallbackAndDefaults(t *testing.T) {
+	clientset, requestedTTL := newTokenClientset("sa-token",
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "team-a"}},
+	)
+	config := &rest.Config{Host: "https://10.0.0.1:6443", TLSClientConfig: rest.TLSClientConfig{CAData: []byte(testCAPEM)}}
+
+	svc := NewKubeconfigService()
+	data, err := svc.GenerateServiceAccountKubeconfig(clientset, config, "prod", "team-a", "deployer", 0)
+	require.NoError(t, err)
+	assert.Equal(t, defaultKubeconfigTokenTTL, *requestedTTL)
+
+	kubeconfig, err := clientcmd.Load(data)
+	require.NoError(t, err)
+	assert.Equal(t, testCAPEM, string(kubeconfig.Clusters["prod"].CertificateAuthorityData))
+}
+
+func TestKubeconfigService_CAFile(t *testing.T) {
+	clientset, _ := newTokenClientset("sa-token",
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "team-a"}},
+	)
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, []byte(testCAPEM), 0o600))
+	config := &rest.Config{Host: "https://10.0.0.1:6443", TLSClientConfig: rest.TLSClientConfig{CAFile: caFile}}
+
+	svc := NewKubeconfigService()
+	data, err := svc.GenerateServiceAccountKubeconfig(clientset, config, "prod", "team-a", "deployer", 0)
+	require.NoError(t, err)
+	kubeconfig, err := clientcmd.Load(data)
+	require.NoError(t, err)
+	assert.Equal(t, testCAPEM, string(kubeconfig.Clusters["prod"].CertificateAuthorityData))
+
+	config.CAFile = filepath.Join(t.TempDir(), "missing.crt")
+	_, err = svc.GenerateServiceAccountKubeconfig(clientset, config, "prod", "team-a", "deployer", 0)
+	assert.Error(t, err)
+}
+
+func TestKubeconfigService_MissingServiceAccount(t *testing.T) {
+	clientset, _ := newTokenClientset("sa-token")
+
+	svc := NewKubeconfigService()
+	_, err := svc.GenerateServiceAccountKubeconfig(clientset, &rest.Config{}, "prod", "team-a", "deployer", 0)
+	assert.True(t, k8serrors.IsNotFound(err))
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// ValidateFieldManager is the server-side apply field manager of validation dry-runs
+const ValidateFieldManager = "cilikube-validate"
+
+// ErrEmptyManifest is returned when a manifest stream holds no document
+var ErrEmptyManifest = errors.New("the manifest contains no objects")
+
+// ManifestDocumentResult is the validation outcome of one document of a manifest stream
+type ManifestDocumentResult struct {
+	Document   int      `json:"document"` // Position in the stream, from 1, counting empty documents
+	APIVersion string   `json:"apiVersion,omitempty"`
+	Kind       string   `json:"kind,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Namespace  string   `json:"namespace,omitempty"`
+	Valid      bool     `json:"valid"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// ManifestValidationResult reports the validation of every document of a manifest stream
+type ManifestValidationResult struct {
+	Valid     bool                     `json:"valid"`
+	DryRun    bool                     `json:"dryRun"` // Documents were also checked by the API server
+	Documents int                      `json:"documents"`
+	Invalid   int                      `json:"invalid"`
+	Results   []ManifestDocumentResult `json:"results"`
+}
+
+// ManifestValidationTarget is the cluster checking manifests in addition to the local checks: its mapper
+// resolves custom resources, and its API server validates every object with a dry-run apply
+type ManifestValidationTarget struct {
+	Client    dynamic.Interface
+	Mapper    meta.RESTMapper
+	Namespace string // Namespace of namespaced objects that name none, "default" when empty
+}
+
+// ManifestValidationService lints multi-document YAML or JSON manifests without applying them
+type ManifestValidationService struct {
+	decoder runtime.Decoder
+}
+
+// NewManifestValidationService creates a new ManifestValidationService instance
+func NewManifestValidationService() *ManifestValidationService {
+	return &ManifestValidationService{
+		// Strict decoding reports unknown and duplicate fields, which the API server would silently drop
+		decoder: json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, json.SerializerOptions{Strict: true}),
+	}
+}
+
+// Validate checks each document of a manifest stream on its own, so one malformed document doesn't hide
+// the errors of the others. A document must parse, name its apiVersion, kind and name, and, for the
+// built-in kinds, decode strictly into its Go type. Without a target nothing leaves the process and kinds
+// outside the built-in scheme are reported as unknown; with one they are resolved by its mapper and every
+// locally valid object is applied to its API server as a dry-run.
+func (s *ManifestValidationService) Validate(ctx context.Context, data []byte, target *ManifestValidationTarget) (*ManifestValidationResult, error) {
+	result := &ManifestValidationResult{Valid: true, DryRun: target != nil, Results: []ManifestDocumentResult{}}
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for document := 1; ; document++ {
+		raw, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document %d: %w", document, err)
+		}
+		if isEmptyDocument(raw) {
+			continue
+		}
+
+		entry := s.validateDocument(ctx, raw, target)
+		entry.Document = document
+		entry.Valid = len(entry.Errors) == 0
+		if !entry.Valid {
+			result.Valid = false
+			result.Invalid++
+		}
+		result.Results = append(result.Results, entry)
+	}
+
+	result.Documents = len(result.Results)
+	if result.Documents == 0 {
+		return nil, ErrEmptyManifest
+	}
+	return result, nil
+}
+
+// validateDocument checks a single non-empty document
+func (s *ManifestValidationService) validateDocument(ctx context.Context, raw []byte, target *ManifestValidationTarget) ManifestDocumentResult {
+	entry := ManifestDocumentResult{}
+	addError := func(format string, args ...interface{}) {
+		entry.Errors = append(entry.Errors, fmt.Sprintf(format, args...))
+	}
+
+	jsonData, err := yaml.YAMLToJSONStrict(raw)
+	if err != nil {
+		addError("parse error: %v", err)
+		return entry
+	}
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(jsonData, &obj.Object); err != nil {
+		addError("parse error: the document is not an object: %v", err)
+		return entry
+	}
+	entry.APIVersion, entry.Kind = obj.GetAPIVersion(), obj.GetKind()
+	entry.Name, entry.Namespace = obj.GetName(), obj.GetNamespace()
+	if entry.APIVersion == "" || entry.Kind == "" {
+		addError("apiVersion and kind are required")
+		return entry
+	}
+	if obj.GetName() == "" && obj.GetGenerateName() == "" {
+		addError("metadata.name is required")
+	}
+
+	gvk := obj.GroupVersionKind()
+	var mapping *meta.RESTMapping
+	switch {
+	case scheme.Scheme.Recognizes(gvk):
+		if _, _, err := s.decoder.Decode(jsonData, &gvk, nil); err != nil {
+			addError("schema error: %v", err)
+		}
+		if target != nil {
+			mapping, err = target.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				addError("%v", err)
+			}
+		}
+	case target != nil:
+		mapping, err = target.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			addError("unknown kind: %v", err)
+		}
+	default:
+		addError("unknown kind %s in %s, specify a cluster to validate custom resources", gvk.Kind, gvk.GroupVersion())
+	}
+
+	if len(entry.Errors) > 0 || target == nil {
+		return entry
+	}
+	if err := dryRunApply(ctx, target, mapping, obj); err != nil {
+		addError("dry-run: %v", err)
+	}
+	return entry
+}
+
+// dryRunApply applies obj to the target cluster as a dry-run, in the target namespace when obj names none
+func dryRunApply(ctx context.Context, target *ManifestValidationTarget, mapping *meta.RESTMapping, obj *unstructured.Unstructured) error {
+	if obj.GetName() == "" {
+		// Server-side apply needs a name, generated names are only given on create
+		_, err := dryRunResourceClient(target, mapping, obj).Create(ctx, obj, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}, FieldManager: ValidateFieldManager})
+		return err
+	}
+	_, err := dryRunResourceClient(target, mapping, obj).Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+		DryRun:       []string{metav1.DryRunAll},
+		FieldManager: ValidateFieldManager,
+		Force:        true,
+	})
+	return err
+}
+
+// dryRunResourceClient returns the dynamic client of the resource of obj, in its namespace for namespaced kinds
+func dryRunResourceClient(target *ManifestValidationTarget, mapping *meta.RESTMapping, obj *unstructured.Unstructured) dynamic.ResourceInterface {
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return target.Client.Resource(mapping.Resource)
+	}
+	if obj.GetNamespace() == "" {
+		namespace := target.Namespace
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		obj.SetNamespace(namespace)
+	}
+	return target.Client.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+}
+
+// isEmptyDocument reports whether a document holds nothing but blank lines and comments
+func isEmptyDocument(raw []byte) bool {
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testManifests = `# web application
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  mode: production
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: "two"
+  selector:
+    matchLabels: {app: web}
+  template:
+    metadata:
+      labels: {app: web}
+    spec:
+      containers:
+      - name: web
+        image: web:1.2
+---
+# nothing but a comment
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  prots:
+  - port: 80
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: [unclosed
+---
+kind: ConfigMap
+metadata:
+  name: no-version
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  generateName: settings-
+data:
+  a: "1"
+  a: "2"
+`
+
+func documentErrors(result *ManifestValidationResult) map[int][]string {
+	errs := make(map[int][]string)
+	for _, document := range result.Results {
+		if !document.Valid {
+			errs[document.Document] = document.Errors
+		}
+	}
+	return errs
+}
+
+func TestManifestValidationService_ValidateOffline(t *testing.T) {
+	result, err := NewManifestValidationService().Validate(context.Background(), []byte(testManifests), nil)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.False(t, result.DryRun)
+	assert.Equal(t, 7, result.Documents, "the comment-only document is skipped")
+	assert.Equal(t, 6, result.Invalid)
+
+	require.Len(t, result.Results, 7)
+	configMap := result.Results[0]
+	assert.True(t, configMap.Valid)
+	assert.Equal(t, 1, configMap.Document)
+	assert.Equal(t, "ConfigMap", configMap.Kind)
+	assert.Equal(t, "web-config", configMap.Name)
+	assert.Equal(t, 4, result.Results[2].Document, "documents keep their position in the stream")
+
+	errs := documentErrors(result)
+	require.Len(t, errs, 6)
+	assertDocumentError := func(document int, contains string) {
+		t.Helper()
+		require.Len(t, errs[document], 1, "document %d", document)
+		assert.Contains(t, errs[document][0], contains, "document %d", document)
+	}
+	assertDocumentError(2, "schema error")
+	assertDocumentError(4, `unknown field "spec.prots"`)
+	assertDocumentError(5, "parse error")
+	assertDocumentError(6, "apiVersion and kind are required")
+	assertDocumentError(7, "unknown kind Widget in example.com/v1")
+	assertDocumentError(8, `key "a" already set`)
+
+	t.Run("valid manifests", func(t *testing.T) {
+		manifests := "---\n" + testManifests[:strings.Index(testManifests, "---")]
+		result, err := NewManifestValidationService().Validate(context.Background(), []byte(manifests), nil)
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Equal(t, 1, result.Documents)
+		assert.Zero(t, result.Invalid)
+	})
+
+	t.Run("empty manifests", func(t *testing.T) {
+		_, err := NewManifestValidationService().Validate(context.Background(), []byte("# nothing\n---\n"), nil)
+		assert.ErrorIs(t, err, ErrEmptyManifest)
+	})
+}
+
+func TestManifestValidationService_ValidateWithCluster(t *testing.T) {
+	widgetGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Version: "v1"}, {Group: "example.com", Version: "v1"}})
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, meta.RESTScopeNamespace)
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMapGVR: "ConfigMapList",
+		widgetGVR:    "WidgetList",
+	})
+	var dryRuns []string
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		dryRuns = append(dryRuns, patch.GetNamespace()+"/"+patch.GetName())
+		if patch.GetName() == "broken" {
+			return true, nil, k8serrors.NewInvalid(schema.GroupKind{Group: "example.com", Kind: "Widget"}, "broken", nil)
+		}
+		return true, nil, nil
+	})
+
+	manifests := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+  namespace: tools
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: broken
+---
+apiVersion: example.com/v1
+kind: Gizmo
+metadata:
+  name: unknown
+`
+	target := &ManifestValidationTarget{Client: client, Mapper: mapper, Namespace: "team-a"}
+	result, err := NewManifestValidationService().Validate(context.Background(), []byte(manifests), target)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 2, result.Invalid)
+
+	assert.Equal(t, []string{"team-a/settings", "tools/gadget", "team-a/broken"}, dryRuns, "objects unknown to the cluster are not sent")
+	errs := documentErrors(result)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[3][0], "dry-run")
+	assert.Contains(t, errs[4][0], "unknown kind")
+}
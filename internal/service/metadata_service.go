@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+// MetadataPatch adds, changes and removes labels and annotations of an object
+type MetadataPatch struct {
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	RemoveLabels      []string          `json:"removeLabels,omitempty"`
+	RemoveAnnotations []string          `json:"removeAnnotations,omitempty"`
+}
+
+// MetadataPatchResult is the metadata of an object after a patch
+type MetadataPatchResult struct {
+	Resource        string            `json:"resource"`
+	Namespace       string            `json:"namespace"`
+	Name            string            `json:"name"`
+	Labels          map[string]string `json:"labels"`
+	Annotations     map[string]string `json:"annotations"`
+	ResourceVersion string            `json:"resourceVersion"`
+}
+
+// Validate checks the syntax of the keys and label values, that no key is both set and removed
+// and that the patch changes something
+func (p MetadataPatch) Validate() error {
+	if len(p.Labels)+len(p.Annotations)+len(p.RemoveLabels)+len(p.RemoveAnnotations) == 0 {
+		return fmt.Errorf("%w: the patch sets or removes no labels or annotations", ErrInvalidResource)
+	}
+
+	var problems []string
+	for _, key := range sortedKeys(p.Labels) {
+		problems = append(problems, describeProblems("label key "+key, validation.IsQualifiedName(key))...)
+		problems = append(problems, describeProblems("label value of "+key, validation.IsValidLabelValue(p.Labels[key]))...)
+	}
+	for _, key := range p.RemoveLabels {
+		problems = append(problems, describeProblems("label key "+key, validation.IsQualifiedName(key))...)
+		if _, ok := p.Labels[key]; ok {
+			problems = append(problems, fmt.Sprintf("label %s is both set and removed", key))
+		}
+	}
+	// Annotation keys follow the label key syntax, case-insensitively. Their values are free-form.
+	for _, key := range sortedKeys(p.Annotations) {
+		problems = append(problems, describeProblems("annotation key "+key, validation.IsQualifiedName(strings.ToLower(key)))...)
+	}
+	for _, key := range p.RemoveAnnotations {
+		problems = append(problems, describeProblems("annotation key "+key, validation.IsQualifiedName(strings.ToLower(key)))...)
+		if _, ok := p.Annotations[key]; ok {
+			problems = append(problems, fmt.Sprintf("annotation %s is both set and removed", key))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidResource, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// MergePatch returns the JSON merge patch of the change. Removed keys are set to null.
+func (p MetadataPatch) MergePatch() ([]byte, error) {
+	metadata := make(map[string]interface{})
+	if keys := mergePatchKeys(p.Labels, p.RemoveLabels); keys != nil {
+		metadata["labels"] = keys
+	}
+	if keys := mergePatchKeys(p.Annotations, p.RemoveAnnotations); keys != nil {
+		metadata["annotations"] = keys
+	}
+	return json.Marshal(map[string]interface{}{"metadata": metadata})
+}
+
+// mergePatchKeys merges the set and removed keys of a map into its merge patch
+func mergePatchKeys(set map[string]string, remove []string) map[string]interface{} {
+	if len(set)+len(remove) == 0 {
+		return nil
+	}
+	keys := make(map[string]interface{}, len(set)+len(remove))
+	for key, value := range set {
+		keys[key] = value
+	}
+	for _, key := range remove {
+		keys[key] = nil
+	}
+	return keys
+}
+
+// describeProblems prefixes the validation messages of a key with what was validated
+func describeProblems(subject string, messages []string) []string {
+	problems := make([]string, 0, len(messages))
+	for _, message := range messages {
+		problems = append(problems, subject+": "+message)
+	}
+	return problems
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MetadataService changes the labels and annotations of objects without replacing them
+type MetadataService struct{}
+
+// NewMetadataService creates a new MetadataService instance
+func NewMetadataService() *MetadataService {
+	return &MetadataService{}
+}
+
+// PatchMetadata applies patch to a namespaced object of resource. A JSON merge patch is used because, unlike a
+// strategic merge patch, it works for custom resources too. Removing a key the object doesn't have is not an error.
+func (s *MetadataService) PatchMetadata(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, namespace, resource, name string, patch MetadataPatch) (*MetadataPatchResult, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	gvr, err := namespacedResource(mapper, resource)
+	if err != nil {
+		return nil, err
+	}
+	data, err := patch.MergePatch()
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := client.Resource(gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &MetadataPatchResult{
+		Resource:        gvr.Resource,
+		Namespace:       obj.GetNamespace(),
+		Name:            obj.GetName(),
+		Labels:          obj.GetLabels(),
+		Annotations:     obj.GetAnnotations(),
+		ResourceVersion: obj.GetResourceVersion(),
+	}, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestMetadataEnv returns a dynamic client holding a labeled and annotated ConfigMap
+func newTestMetadataEnv(t *testing.T) (*dynamicfake.FakeDynamicClient, meta.RESTMapper) {
+	t.Helper()
+	cm := newTestLabeledConfigMap("test", "settings", map[string]string{"app": "web", "tier": "frontend"})
+	cm.SetAnnotations(map[string]string{"owner": "team-a"})
+	return newTestBatchDeleteEnv(cm)
+}
+
+func TestMetadataPatch_MergePatch(t *testing.T) {
+	patch := MetadataPatch{
+		Labels:            map[string]string{"env": "prod"},
+		RemoveLabels:      []string{"tier"},
+		RemoveAnnotations: []string{"owner"},
+	}
+	data, err := patch.MergePatch()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"metadata":{"labels":{"env":"prod","tier":null},"annotations":{"owner":null}}}`, string(data))
+}
+
+func TestMetadataService_PatchMetadata(t *testing.T) {
+	client, mapper := newTestMetadataEnv(t)
+	svc := NewMetadataService()
+
+	result, err := svc.PatchMetadata(context.Background(), client, mapper, "test", "configmaps", "settings", MetadataPatch{
+		Labels:            map[string]string{"env": "prod", "app": "api"},
+		Annotations:       map[string]string{"example.com/reviewed-by": "alice"},
+		RemoveLabels:      []string{"tier", "missing"},
+		RemoveAnnotations: []string{"owner"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "configmaps", result.Resource)
+	assert.Equal(t, map[string]string{"app": "api", "env": "prod"}, result.Labels)
+	assert.Equal(t, map[string]string{"example.com/reviewed-by": "alice"}, result.Annotations)
+
+	var patches []k8stesting.PatchAction
+	for _, action := range client.Actions() {
+		if patch, ok := action.(k8stesting.PatchAction); ok {
+			patches = append(patches, patch)
+		}
+	}
+	require.Len(t, patches, 1)
+	assert.Equal(t, types.MergePatchType, patches[0].GetPatchType())
+	assert.Equal(t, "settings", patches[0].GetName())
+	assert.JSONEq(t, `{"metadata":{
+		"labels":{"env":"prod","app":"api","tier":null,"missing":null},
+		"annotations":{"example.com/reviewed-by":"alice","owner":null}
+	}}`, string(patches[0].GetPatch()))
+}
+
+func TestMetadataService_Validation(t *testing.T) {
+	client, mapper := newTestMetadataEnv(t)
+	svc := NewMetadataService()
+
+	for name, patch := range map[string]MetadataPatch{
+		"empty":                  {},
+		"invalid label key":      {Labels: map[string]string{"bad key": "x"}},
+		"invalid label value":    {Labels: map[string]string{"app": "not a value"}},
+		"invalid removed label":  {RemoveLabels: []string{"-tier"}},
+		"invalid annotation key": {Annotations: map[string]string{"example.com/a/b": "x"}},
+		"set and removed":        {Labels: map[string]string{"app": "api"}, RemoveLabels: []string{"app"}},
+	} {
+		_, err := svc.PatchMetadata(context.Background(), client, mapper, "test", "configmaps", "settings", patch)
+		assert.ErrorIs(t, err, ErrInvalidResource, name)
+	}
+	assert.Empty(t, client.Actions(), "invalid patches are not sent")
+
+	// Annotation values are free-form
+	_, err := svc.PatchMetadata(context.Background(), client, mapper, "test", "configmaps", "settings", MetadataPatch{
+		Annotations: map[string]string{"description": "any text, even with spaces"},
+	})
+	require.NoError(t, err)
+}
+
+func TestMetadataService_ClusterScopedResource(t *testing.T) {
+	client, mapper := newTestMetadataEnv(t)
+	_, err := NewMetadataService().PatchMetadata(context.Background(), client, mapper, "test", "nodes", "node-1", MetadataPatch{
+		Labels: map[string]string{"env": "prod"},
+	})
+	assert.ErrorIs(t, err, ErrResourceScopeMismatch)
+}
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -20,11 +23,17 @@ type MonitoringService struct {
 	metricsMutex sync.RWMutex
 
 	// Alert channels
-	alertChannels []AlertChannel
+	alertChannels      []AlertChannel
+	alertChannelsMutex sync.RWMutex
+
+	// Stream subscribers receiving metrics and alert events
+	subscribers      map[chan MonitoringEvent]struct{}
+	subscribersMutex sync.RWMutex
 
 	// Monitoring state
-	isRunning bool
-	stopChan  chan bool
+	isRunning  bool
+	stopChan   chan bool
+	collectors sync.WaitGroup
 }
 
 // NewMonitoringService creates a new monitoring service
@@ -35,6 +44,7 @@ func NewMonitoringService(store store.Store, config *configs.Config, auditServic
 		auditService:  auditService,
 		metrics:       NewRealTimeMetrics(),
 		alertChannels: make([]AlertChannel, 0),
+		subscribers:   make(map[chan MonitoringEvent]struct{}),
 		stopChan:      make(chan bool),
 	}
 }
@@ -63,7 +73,7 @@ type RealTimeMetrics struct {
 
 	// Timestamps
 	LastUpdated    time.Time     `json:"last_updated"`
-	UpdateInterval time.Duration `json:"update_interval"`
+	UpdateInterval time.Duration `json:"update_interval" swaggertype:"integer"`
 }
 
 // NewRealTimeMetrics creates a new real-time metrics instance
@@ -94,10 +104,32 @@ type Alert struct {
 	Source      string                 `json:"source"`
 	Timestamp   time.Time              `json:"timestamp"`
 	Data        map[string]interface{} `json:"data"`
+	Count       int                    `json:"count"`
 	Resolved    bool                   `json:"resolved"`
 	ResolvedAt  *time.Time             `json:"resolved_at,omitempty"`
 }
 
+// alertDedupWindow is how long an unresolved alert absorbs repeats of the same type
+const alertDedupWindow = 30 * time.Minute
+
+// MonitoringEventType identifies the payload of a MonitoringEvent
+type MonitoringEventType string
+
+const (
+	MonitoringEventMetrics MonitoringEventType = "metrics"
+	MonitoringEventAlert   MonitoringEventType = "alert"
+)
+
+// monitoringSubscriberBuffer is the number of events buffered per subscriber before events are dropped
+const monitoringSubscriberBuffer = 16
+
+// MonitoringEvent is pushed to stream subscribers when metrics are updated or an alert is raised
+type MonitoringEvent struct {
+	Type    MonitoringEventType `json:"type"`
+	Metrics *RealTimeMetrics    `json:"metrics,omitempty"`
+	Alert   *Alert              `json:"alert,omitempty"`
+}
+
 // AlertChannel defines an interface for alert delivery
 type AlertChannel interface {
 	SendAlert(alert Alert) error
@@ -114,6 +146,10 @@ func NewLogAlertChannel() *LogAlertChannel {
 }
 
 func (c *LogAlertChannel) SendAlert(alert Alert) error {
+	if alert.Resolved {
+		fmt.Printf("[RESOLVED] %s - %s: %s\n", alert.Level, alert.Type, alert.Description)
+		return nil
+	}
 	fmt.Printf("[ALERT] %s - %s: %s\n", alert.Level, alert.Type, alert.Description)
 	return nil
 }
@@ -131,12 +167,21 @@ func (m *MonitoringService) Start() error {
 	m.isRunning = true
 
 	// Add default alert channel
-	m.alertChannels = append(m.alertChannels, NewLogAlertChannel())
+	m.AddAlertChannel(NewLogAlertChannel())
+	if m.config != nil {
+		if channel := NewAlertManagerChannel(m.config.Monitoring.Alertmanager); channel != nil {
+			m.AddAlertChannel(channel)
+		}
+	}
 
 	// Start monitoring goroutines
-	go m.metricsCollector()
-	go m.threatDetector()
-	go m.alertProcessor()
+	for _, collector := range []func(){m.metricsCollector, m.threatDetector, m.alertProcessor} {
+		m.collectors.Add(1)
+		go func(collector func()) {
+			defer m.collectors.Done()
+			collector()
+		}(collector)
+	}
 
 	return nil
 }
@@ -149,6 +194,20 @@ func (m *MonitoringService) Stop() error {
 
 	m.isRunning = false
 	close(m.stopChan)
+	// Wait for alerts being raised by the collectors before the channels are closed
+	m.collectors.Wait()
+
+	m.alertChannelsMutex.Lock()
+	channels := m.alertChannels
+	m.alertChannels = nil
+	m.alertChannelsMutex.Unlock()
+
+	// Flush the channels delivering in the background, such as Alertmanager
+	for _, channel := range channels {
+		if closer, ok := channel.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
 
 	return nil
 }
@@ -171,13 +230,62 @@ func (m *MonitoringService) metricsCollector() {
 	for {
 		select {
 		case <-ticker.C:
-			m.updateMetrics()
+			m.collectMetrics()
 		case <-m.stopChan:
 			return
 		}
 	}
 }
 
+// collectMetrics updates the metrics and pushes the new snapshot to subscribers
+func (m *MonitoringService) collectMetrics() {
+	m.updateMetrics()
+	m.publish(MonitoringEvent{Type: MonitoringEventMetrics, Metrics: m.GetRealTimeMetrics()})
+}
+
+// Subscribe registers a stream subscriber. Events are shared by all subscribers, so adding
+// clients does not trigger additional metric computation. Slow subscribers miss events
+// instead of blocking the collector. The returned function unsubscribes and closes the channel.
+func (m *MonitoringService) Subscribe() (<-chan MonitoringEvent, func()) {
+	ch := make(chan MonitoringEvent, monitoringSubscriberBuffer)
+
+	m.subscribersMutex.Lock()
+	m.subscribers[ch] = struct{}{}
+	m.subscribersMutex.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			m.subscribersMutex.Lock()
+			delete(m.subscribers, ch)
+			m.subscribersMutex.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// SubscriberCount returns the number of active stream subscribers
+func (m *MonitoringService) SubscriberCount() int {
+	m.subscribersMutex.RLock()
+	defer m.subscribersMutex.RUnlock()
+	return len(m.subscribers)
+}
+
+// publish delivers an event to all subscribers without blocking
+func (m *MonitoringService) publish(event MonitoringEvent) {
+	m.subscribersMutex.RLock()
+	defer m.subscribersMutex.RUnlock()
+
+	for ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is not keeping up; drop the event for it
+		}
+	}
+}
+
 // updateMetrics updates the real-time metrics
 func (m *MonitoringService) updateMetrics() {
 	m.metricsMutex.Lock()
@@ -212,7 +320,7 @@ func (m *MonitoringService) updateMetrics() {
 				if log.Action == "login_failed" {
 					failedLogins++
 				}
-			case "resource_access":
+			case "resource_access", "resource_create", "resource_update", "resource_delete":
 				resourceAccess++
 			}
 			apiRequests++
@@ -259,40 +367,50 @@ func (m *MonitoringService) updateMetrics() {
 
 // checkAlertConditions checks if any alert conditions are met
 func (m *MonitoringService) checkAlertConditions() {
+	thresholds := m.thresholds()
+
 	// Check for high failed login rate
-	if m.metrics.FailedLoginsPerMinute > 10 {
+	if m.metrics.FailedLoginsPerMinute > thresholds.FailedLoginsPerMinute {
 		m.createAlert(AlertLevelWarning, "high_failed_logins",
 			"High Failed Login Rate",
 			fmt.Sprintf("%.0f failed logins per minute detected", m.metrics.FailedLoginsPerMinute),
 			map[string]interface{}{
 				"rate":      m.metrics.FailedLoginsPerMinute,
-				"threshold": 10,
+				"threshold": thresholds.FailedLoginsPerMinute,
 			})
 	}
 
 	// Check for high permission denials
-	if m.metrics.PermissionDenialsPerHour > 50 {
+	if m.metrics.PermissionDenialsPerHour > thresholds.PermissionDenialsPerHour {
 		m.createAlert(AlertLevelWarning, "high_permission_denials",
 			"High Permission Denials",
 			fmt.Sprintf("%d permission denials per hour detected", m.metrics.PermissionDenialsPerHour),
 			map[string]interface{}{
 				"count":     m.metrics.PermissionDenialsPerHour,
-				"threshold": 50,
+				"threshold": thresholds.PermissionDenialsPerHour,
 			})
 	}
 
 	// Check for security violations
-	if m.metrics.SecurityViolationsPerHour > 5 {
+	if m.metrics.SecurityViolationsPerHour > thresholds.SecurityViolationsPerHour {
 		m.createAlert(AlertLevelError, "security_violations",
 			"Security Violations Detected",
 			fmt.Sprintf("%d security violations per hour detected", m.metrics.SecurityViolationsPerHour),
 			map[string]interface{}{
 				"count":     m.metrics.SecurityViolationsPerHour,
-				"threshold": 5,
+				"threshold": thresholds.SecurityViolationsPerHour,
 			})
 	}
 }
 
+// thresholds returns the configured alert thresholds, falling back to defaults for unset values
+func (m *MonitoringService) thresholds() configs.MonitoringThresholds {
+	if m.config == nil {
+		return configs.DefaultMonitoringThresholds()
+	}
+	return m.config.Monitoring.Thresholds.WithDefaults()
+}
+
 // threatDetector runs threat detection periodically
 func (m *MonitoringService) threatDetector() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -373,16 +491,22 @@ func (m *MonitoringService) createAlert(level AlertLevel, alertType, title, desc
 		Source:      "monitoring_service",
 		Timestamp:   time.Now(),
 		Data:        data,
+		Count:       1,
 		Resolved:    false,
 	}
 
-	// Send alert through all channels
-	for _, channel := range m.alertChannels {
-		if err := channel.SendAlert(alert); err != nil {
-			fmt.Printf("Error sending alert through channel %s: %v\n", channel.GetName(), err)
-		}
+	// Persist the alert, folding repeats into the open alert of the same type
+	if stored, err := m.recordAlert(alert); err != nil {
+		fmt.Printf("Error persisting alert %s: %v\n", alertType, err)
+	} else {
+		alert.ID = strconv.FormatUint(uint64(stored.ID), 10)
+		alert.Count = stored.Count
 	}
 
+	m.sendAlert(alert)
+
+	m.publish(MonitoringEvent{Type: MonitoringEventAlert, Alert: &alert})
+
 	// Log the alert as a security event
 	m.auditService.LogSecurityEvent(SecurityEvent{
 		Type:      "alert_generated",
@@ -395,9 +519,141 @@ func (m *MonitoringService) createAlert(level AlertLevel, alertType, title, desc
 	})
 }
 
+// sendAlert sends an alert through all channels
+func (m *MonitoringService) sendAlert(alert Alert) {
+	m.alertChannelsMutex.RLock()
+	defer m.alertChannelsMutex.RUnlock()
+
+	for _, channel := range m.alertChannels {
+		if err := channel.SendAlert(alert); err != nil {
+			fmt.Printf("Error sending alert through channel %s: %v\n", channel.GetName(), err)
+		}
+	}
+}
+
+// recordAlert stores an alert. If an unresolved alert of the same type was seen within
+// alertDedupWindow, its count and last-seen time are bumped instead of inserting a new row.
+func (m *MonitoringService) recordAlert(alert Alert) (*store.Alert, error) {
+	data, err := json.Marshal(alert.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode alert data: %w", err)
+	}
+
+	existing, err := m.store.FindOpenAlert(alert.Type, alert.Timestamp.Add(-alertDedupWindow))
+	if err == nil {
+		existing.Count++
+		existing.LastSeen = alert.Timestamp
+		existing.Level = string(alert.Level)
+		existing.Title = alert.Title
+		existing.Description = alert.Description
+		existing.Data = string(data)
+		if err := m.store.UpdateAlert(existing); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+	if !errors.Is(err, store.ErrAlertNotFound) {
+		return nil, err
+	}
+
+	stored := &store.Alert{
+		Level:       string(alert.Level),
+		Type:        alert.Type,
+		Title:       alert.Title,
+		Description: alert.Description,
+		Source:      alert.Source,
+		Data:        string(data),
+		Count:       1,
+		FirstSeen:   alert.Timestamp,
+		LastSeen:    alert.Timestamp,
+	}
+	if err := m.store.CreateAlert(stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// ListAlerts returns stored alerts matching the filter, most recently seen first
+func (m *MonitoringService) ListAlerts(filter store.AlertFilter, offset, limit int) ([]*store.Alert, int64, error) {
+	return m.store.ListAlerts(filter, offset, limit)
+}
+
+// AcknowledgeAlert marks an alert as acknowledged by a user
+func (m *MonitoringService) AcknowledgeAlert(id, userID uint) (*store.Alert, error) {
+	alert, err := m.store.GetAlertByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !alert.Acknowledged {
+		now := time.Now()
+		alert.Acknowledged = true
+		alert.AcknowledgedBy = &userID
+		alert.AcknowledgedAt = &now
+		if err := m.store.UpdateAlert(alert); err != nil {
+			return nil, fmt.Errorf("failed to acknowledge alert: %w", err)
+		}
+	}
+	return alert, nil
+}
+
+// ResolveAlert marks an alert as resolved by a user. Resolving implies acknowledging.
+// A later alert of the same type starts a new row.
+func (m *MonitoringService) ResolveAlert(id, userID uint) (*store.Alert, error) {
+	alert, err := m.store.GetAlertByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !alert.Resolved {
+		now := time.Now()
+		if !alert.Acknowledged {
+			alert.Acknowledged = true
+			alert.AcknowledgedBy = &userID
+			alert.AcknowledgedAt = &now
+		}
+		alert.Resolved = true
+		alert.ResolvedBy = &userID
+		alert.ResolvedAt = &now
+		if err := m.store.UpdateAlert(alert); err != nil {
+			return nil, fmt.Errorf("failed to resolve alert: %w", err)
+		}
+		// Let the channels close the alert, such as Alertmanager
+		m.sendAlert(alertFromStore(alert))
+	}
+	return alert, nil
+}
+
+// alertFromStore converts a stored alert to the alert sent through the channels
+func alertFromStore(stored *store.Alert) Alert {
+	var data map[string]interface{}
+	if stored.Data != "" {
+		if err := json.Unmarshal([]byte(stored.Data), &data); err != nil {
+			fmt.Printf("Error decoding data of alert %d: %v\n", stored.ID, err)
+		}
+	}
+	return Alert{
+		ID:          strconv.FormatUint(uint64(stored.ID), 10),
+		Level:       AlertLevel(stored.Level),
+		Type:        stored.Type,
+		Title:       stored.Title,
+		Description: stored.Description,
+		Source:      stored.Source,
+		Timestamp:   stored.LastSeen,
+		Data:        data,
+		Count:       stored.Count,
+		Resolved:    stored.Resolved,
+		ResolvedAt:  stored.ResolvedAt,
+	}
+}
+
+// healthThresholdFactor scales the alert thresholds to the rates at which system health degrades
+const healthThresholdFactor = 2
+
 // GetSystemHealth returns overall system health status
 func (m *MonitoringService) GetSystemHealth() *SystemHealth {
 	metrics := m.GetRealTimeMetrics()
+	thresholds := m.thresholds()
+	failedLoginThreshold := thresholds.FailedLoginsPerMinute * healthThresholdFactor
+	violationThreshold := thresholds.SecurityViolationsPerHour * healthThresholdFactor
 
 	health := &SystemHealth{
 		Status:    "healthy",
@@ -407,14 +663,14 @@ func (m *MonitoringService) GetSystemHealth() *SystemHealth {
 	}
 
 	// Check for health issues
-	if metrics.FailedLoginsPerMinute > 20 {
+	if metrics.FailedLoginsPerMinute > failedLoginThreshold {
 		health.Status = "warning"
 		health.Issues = append(health.Issues, HealthIssue{
 			Type:        "security",
 			Severity:    "warning",
 			Description: "High rate of failed login attempts",
 			Value:       metrics.FailedLoginsPerMinute,
-			Threshold:   20,
+			Threshold:   failedLoginThreshold,
 		})
 	}
 
@@ -429,7 +685,7 @@ func (m *MonitoringService) GetSystemHealth() *SystemHealth {
 		})
 	}
 
-	if metrics.SecurityViolationsPerHour > 10 {
+	if metrics.SecurityViolationsPerHour > violationThreshold {
 		if health.Status == "healthy" {
 			health.Status = "warning"
 		}
@@ -438,7 +694,7 @@ func (m *MonitoringService) GetSystemHealth() *SystemHealth {
 			Severity:    "warning",
 			Description: "High rate of security violations",
 			Value:       float64(metrics.SecurityViolationsPerHour),
-			Threshold:   10,
+			Threshold:   float64(violationThreshold),
 		})
 	}
 
@@ -464,5 +720,8 @@ type HealthIssue struct {
 
 // AddAlertChannel adds a new alert channel
 func (m *MonitoringService) AddAlertChannel(channel AlertChannel) {
+	m.alertChannelsMutex.Lock()
+	defer m.alertChannelsMutex.Unlock()
+
 	m.alertChannels = append(m.alertChannels, channel)
 }
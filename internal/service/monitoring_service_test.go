@@ -0,0 +1,227 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestMonitoringService(t *testing.T) *MonitoringService {
+	t.Helper()
+	testStore := store.NewMemoryStore()
+	require.NoError(t, testStore.Initialize())
+	config := &configs.Config{}
+	return NewMonitoringService(testStore, config, NewAuditService(testStore, config))
+}
+
+func TestMonitoringService_SubscriberReceivesMetrics(t *testing.T) {
+	m := setupTestMonitoringService(t)
+
+	first, unsubscribeFirst := m.Subscribe()
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := m.Subscribe()
+	defer unsubscribeSecond()
+
+	before := time.Now()
+	m.collectMetrics()
+
+	// Every subscriber receives the same update from a single tick
+	for _, ch := range []<-chan MonitoringEvent{first, second} {
+		select {
+		case event := <-ch:
+			assert.Equal(t, MonitoringEventMetrics, event.Type)
+			require.NotNil(t, event.Metrics)
+			assert.False(t, event.Metrics.LastUpdated.Before(before))
+		case <-time.After(time.Second):
+			t.Fatal("subscriber did not receive a metrics event")
+		}
+	}
+}
+
+func TestMonitoringService_Unsubscribe(t *testing.T) {
+	m := setupTestMonitoringService(t)
+
+	events, unsubscribe := m.Subscribe()
+	assert.Equal(t, 1, m.SubscriberCount())
+
+	unsubscribe()
+	unsubscribe() // Safe to call more than once
+	assert.Equal(t, 0, m.SubscriberCount())
+
+	_, ok := <-events
+	assert.False(t, ok, "channel is closed after unsubscribing")
+
+	// Publishing without subscribers must not block
+	m.collectMetrics()
+}
+
+func TestMonitoringService_SlowSubscriberDoesNotBlock(t *testing.T) {
+	m := setupTestMonitoringService(t)
+	_, unsubscribe := m.Subscribe()
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < monitoringSubscriberBuffer*2; i++ {
+			m.publish(MonitoringEvent{Type: MonitoringEventMetrics})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publishing blocked on a subscriber that does not read")
+	}
+}
+
+func TestMonitoringService_AlertDeduplication(t *testing.T) {
+	m := setupTestMonitoringService(t)
+
+	m.createAlert(AlertLevelWarning, "high_failed_logins", "High Failed Login Rate", "12 failed logins", nil)
+	m.createAlert(AlertLevelWarning, "high_failed_logins", "High Failed Login Rate", "15 failed logins", nil)
+	m.createAlert(AlertLevelError, "security_violations", "Security Violations Detected", "6 violations", nil)
+
+	alerts, total, err := m.ListAlerts(store.AlertFilter{Type: "high_failed_logins"}, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total, "repeated alerts are folded into one row")
+	assert.Equal(t, 2, alerts[0].Count)
+	assert.Equal(t, "15 failed logins", alerts[0].Description)
+	assert.False(t, alerts[0].LastSeen.Before(alerts[0].FirstSeen))
+
+	_, total, err = m.ListAlerts(store.AlertFilter{}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+
+	_, total, err = m.ListAlerts(store.AlertFilter{Level: string(AlertLevelError)}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+}
+
+func TestMonitoringService_AlertOutsideWindowIsNew(t *testing.T) {
+	m := setupTestMonitoringService(t)
+
+	old := time.Now().Add(-2 * alertDedupWindow)
+	require.NoError(t, m.store.CreateAlert(&store.Alert{
+		Level: string(AlertLevelWarning), Type: "high_failed_logins", Count: 1, FirstSeen: old, LastSeen: old,
+	}))
+
+	m.createAlert(AlertLevelWarning, "high_failed_logins", "High Failed Login Rate", "12 failed logins", nil)
+
+	alerts, total, err := m.ListAlerts(store.AlertFilter{Type: "high_failed_logins"}, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+	assert.Equal(t, 1, alerts[0].Count)
+}
+
+func TestMonitoringService_StopWhileAlerting(t *testing.T) {
+	m := setupTestMonitoringService(t)
+	require.NoError(t, m.Start())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				m.createAlert(AlertLevelWarning, "high_failed_logins", "High Failed Login Rate", "12 failed logins", nil)
+			}
+		}()
+	}
+	require.NoError(t, m.Stop())
+	wg.Wait()
+
+	// Alerts raised after stopping are still recorded, there is just no channel to send them to
+	m.createAlert(AlertLevelWarning, "high_failed_logins", "High Failed Login Rate", "15 failed logins", nil)
+	alerts, _, err := m.ListAlerts(store.AlertFilter{Type: "high_failed_logins"}, 0, 10)
+	require.NoError(t, err)
+	assert.NotEmpty(t, alerts)
+}
+
+func TestMonitoringService_AcknowledgeAndResolveAlert(t *testing.T) {
+	m := setupTestMonitoringService(t)
+	m.createAlert(AlertLevelWarning, "high_permission_denials", "High Permission Denials", "60 denials", nil)
+
+	alerts, _, err := m.ListAlerts(store.AlertFilter{}, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	id := alerts[0].ID
+
+	acked, err := m.AcknowledgeAlert(id, 7)
+	require.NoError(t, err)
+	assert.True(t, acked.Acknowledged)
+	require.NotNil(t, acked.AcknowledgedBy)
+	assert.Equal(t, uint(7), *acked.AcknowledgedBy)
+	assert.False(t, acked.Resolved)
+
+	resolved, err := m.ResolveAlert(id, 9)
+	require.NoError(t, err)
+	assert.True(t, resolved.Resolved)
+	require.NotNil(t, resolved.ResolvedAt)
+	assert.Equal(t, uint(9), *resolved.ResolvedBy)
+	assert.Equal(t, uint(7), *resolved.AcknowledgedBy, "the original acknowledger is kept")
+
+	unresolved := false
+	_, total, err := m.ListAlerts(store.AlertFilter{Resolved: &unresolved}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+
+	// A resolved alert no longer absorbs repeats
+	m.createAlert(AlertLevelWarning, "high_permission_denials", "High Permission Denials", "70 denials", nil)
+	_, total, err = m.ListAlerts(store.AlertFilter{Resolved: &unresolved}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	_, err = m.ResolveAlert(999, 9)
+	assert.ErrorIs(t, err, store.ErrAlertNotFound)
+}
+
+func TestMonitoringService_ConfiguredThresholds(t *testing.T) {
+	defaults := setupTestMonitoringService(t)
+	lowered := setupTestMonitoringService(t)
+	lowered.config.Monitoring.Thresholds = configs.MonitoringThresholds{FailedLoginsPerMinute: 3, SecurityViolationsPerHour: 2}
+
+	for _, m := range []*MonitoringService{defaults, lowered} {
+		m.metrics.FailedLoginsPerMinute = 5
+		m.metrics.SecurityViolationsPerHour = 3
+		m.checkAlertConditions()
+	}
+
+	// The default thresholds (10 and 5) are not exceeded
+	_, total, err := defaults.ListAlerts(store.AlertFilter{}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Equal(t, "healthy", defaults.GetSystemHealth().Status)
+
+	alerts, total, err := lowered.ListAlerts(store.AlertFilter{}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	types := []string{alerts[0].Type, alerts[1].Type}
+	assert.ElementsMatch(t, []string{"high_failed_logins", "security_violations"}, types)
+
+	// Unset thresholds keep their defaults
+	assert.Equal(t, 50, lowered.thresholds().PermissionDenialsPerHour)
+
+	health := lowered.GetSystemHealth()
+	assert.Equal(t, "healthy", health.Status, "health degrades at twice the alert threshold")
+	lowered.metrics.FailedLoginsPerMinute = 7
+	health = lowered.GetSystemHealth()
+	assert.Equal(t, "warning", health.Status)
+	require.Len(t, health.Issues, 1)
+	assert.Equal(t, float64(6), health.Issues[0].Threshold)
+}
+
+func TestMonitoringThresholds_Validate(t *testing.T) {
+	assert.NoError(t, configs.DefaultMonitoringThresholds().Validate())
+	assert.NoError(t, configs.MonitoringThresholds{}.WithDefaults().Validate())
+
+	invalid := configs.DefaultMonitoringThresholds()
+	invalid.PermissionDenialsPerHour = -1
+	assert.Error(t, invalid.Validate())
+	assert.Error(t, configs.MonitoringThresholds{}.Validate())
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// namespaceUsageMetricsTimeout bounds the metrics-server request of a namespace usage report, the only part
+// of the report not served from the informer caches
+const namespaceUsageMetricsTimeout = 10 * time.Second
+
+// Sort dimensions of a namespace usage report
+const (
+	NamespaceUsageSortByPods        = "pods"
+	NamespaceUsageSortByCPU         = "cpu"    // CPU requests
+	NamespaceUsageSortByMemory      = "memory" // Memory requests
+	NamespaceUsageSortByCPUUsage    = "cpuUsage"
+	NamespaceUsageSortByMemoryUsage = "memoryUsage"
+)
+
+// NamespaceSummaryListers groups the listers read when summarizing a namespace
+type NamespaceSummaryListers struct {
+	Namespaces     corelisters.NamespaceLister
+	Pods           corelisters.PodLister
+	ResourceQuotas corelisters.ResourceQuotaLister
+	Deployments    appslisters.DeploymentLister
+	StatefulSets   appslisters.StatefulSetLister
+	DaemonSets     appslisters.DaemonSetLister
+	ReplicaSets    appslisters.ReplicaSetLister
+	Jobs           batchlisters.JobLister
+	CronJobs       batchlisters.CronJobLister
+}
+
+// NamespaceSummaryService summarizes the quota, requested resources, usage and workloads of a namespace
+type NamespaceSummaryService struct {
+	informers *k8s.InformerRegistry
+}
+
+// NewNamespaceSummaryService creates a new NamespaceSummaryService instance
+func NewNamespaceSummaryService(informers *k8s.InformerRegistry) *NamespaceSummaryService {
+	return &NamespaceSummaryService{
+		informers: informers,
+	}
+}
+
+// ListersFor returns the listers of a cluster, starting and syncing its informers on first use
+func (s *NamespaceSummaryService) ListersFor(clusterID string, clientset kubernetes.Interface) (*NamespaceSummaryListers, error) {
+	var listers *NamespaceSummaryListers
+	err := s.informers.Sync(clusterID, clientset, func(factory informers.SharedInformerFactory) {
+		listers = &NamespaceSummaryListers{
+			Namespaces:     factory.Core().V1().Namespaces().Lister(),
+			Pods:           factory.Core().V1().Pods().Lister(),
+			ResourceQuotas: factory.Core().V1().ResourceQuotas().Lister(),
+			Deployments:    factory.Apps().V1().Deployments().Lister(),
+			StatefulSets:   factory.Apps().V1().StatefulSets().Lister(),
+			DaemonSets:     factory.Apps().V1().DaemonSets().Lister(),
+			ReplicaSets:    factory.Apps().V1().ReplicaSets().Lister(),
+			Jobs:           factory.Batch().V1().Jobs().Lister(),
+			CronJobs:       factory.Batch().V1().CronJobs().Lister(),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listers, nil
+}
+
+// ValidateNamespaceUsageSortBy checks the sort dimension of a namespace usage report
+func ValidateNamespaceUsageSortBy(sortBy string) error {
+	switch sortBy {
+	case "", NamespaceUsageSortByPods, NamespaceUsageSortByCPU, NamespaceUsageSortByMemory,
+		NamespaceUsageSortByCPUUsage, NamespaceUsageSortByMemoryUsage:
+		return nil
+	}
+	return fmt.Errorf("unsupported sortBy %q, expected %s, %s, %s, %s or %s", sortBy, NamespaceUsageSortByPods,
+		NamespaceUsageSortByCPU, NamespaceUsageSortByMemory, NamespaceUsageSortByCPUUsage, NamespaceUsageSortByMemoryUsage)
+}
+
+// Summary aggregates the ResourceQuotas, pod requests and limits, and workload counts of a namespace
+// from the informer caches. The current usage is read from metrics-server when metricsClient is set;
+// a metrics failure is reported in the summary instead of failing it.
+func (s *NamespaceSummaryService) Summary(listers *NamespaceSummaryListers, metricsClient versioned.Interface, namespace string) (*models.NamespaceSummary, error) {
+	if _, err := listers.Namespaces.Get(namespace); err != nil {
+		return nil, err
+	}
+
+	summary := &models.NamespaceSummary{
+		Namespace: namespace,
+		Quotas:    []models.ResourceQuotaSummary{},
+		Workloads: map[string]int{},
+	}
+
+	quotas, err := listers.ResourceQuotas.ResourceQuotas(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].Name < quotas[j].Name })
+	for _, quota := range quotas {
+		summary.Quotas = append(summary.Quotas, quotaSummary(quota))
+	}
+
+	pods, err := listers.Pods.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podRequests, podLimits := podContainerResources(pod)
+		addResourceList(requests, podRequests)
+		addResourceList(limits, podLimits)
+	}
+	summary.Requests = resourceAmount(requests)
+	summary.Limits = resourceAmount(limits)
+
+	if err := countWorkloads(listers, namespace, summary.Workloads); err != nil {
+		return nil, err
+	}
+	summary.Workloads["Pod"] = len(pods)
+
+	if metricsClient != nil {
+		usage, err := namespaceUsage(metricsClient, namespace)
+		if err != nil {
+			summary.MetricsError = err.Error()
+		} else {
+			summary.Usage = &usage
+		}
+	}
+	return summary, nil
+}
+
+// Usage reports the pods, requests, current usage and quota headroom of every namespace from the informer
+// caches, sorted descending by sortBy (CPU requests by default) and then by name. Usage is read from
+// metrics-server when metricsClient is set; that request is bounded by namespaceUsageMetricsTimeout and a
+// failure is reported in the report instead of failing it.
+func (s *NamespaceSummaryService) Usage(ctx context.Context, listers *NamespaceSummaryListers, metricsClient versioned.Interface, sortBy string) (*models.NamespaceUsageReport, error) {
+	if err := ValidateNamespaceUsageSortBy(sortBy); err != nil {
+		return nil, err
+	}
+	if sortBy == "" {
+		sortBy = NamespaceUsageSortByCPU
+	}
+
+	namespaces, err := listers.Namespaces.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	pods, err := listers.Pods.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	quotas, err := listers.ResourceQuotas.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+
+	podCounts, requests := map[string]int{}, map[string]corev1.ResourceList{}
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podRequests, _ := podContainerResources(pod)
+		if requests[pod.Namespace] == nil {
+			requests[pod.Namespace] = corev1.ResourceList{}
+		}
+		addResourceList(requests[pod.Namespace], podRequests)
+		podCounts[pod.Namespace]++
+	}
+	quotasByNamespace := map[string][]*corev1.ResourceQuota{}
+	for _, quota := range quotas {
+		quotasByNamespace[quota.Namespace] = append(quotasByNamespace[quota.Namespace], quota)
+	}
+
+	report := &models.NamespaceUsageReport{SortBy: sortBy, Namespaces: make([]models.NamespaceUsage, 0, len(namespaces))}
+	var usage map[string]corev1.ResourceList
+	if metricsClient != nil {
+		metricsCtx, cancel := context.WithTimeout(ctx, namespaceUsageMetricsTimeout)
+		usage, err = usageByNamespace(metricsCtx, metricsClient)
+		cancel()
+		if err != nil {
+			report.MetricsError = err.Error()
+			usage = nil
+		}
+	}
+
+	totalRequests, totalUsage := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, namespace := range namespaces {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		item := models.NamespaceUsage{
+			Namespace:     namespace.Name,
+			Pods:          podCounts[namespace.Name],
+			Requests:      resourceAmount(requests[namespace.Name]),
+			QuotaHeadroom: quotaHeadroom(quotasByNamespace[namespace.Name]),
+		}
+		addResourceList(totalRequests, requests[namespace.Name])
+		report.Pods += item.Pods
+		if usage != nil {
+			amount := resourceAmount(usage[namespace.Name])
+			item.Usage = &amount
+			addResourceList(totalUsage, usage[namespace.Name])
+		}
+		report.Namespaces = append(report.Namespaces, item)
+	}
+	report.Requests = resourceAmount(totalRequests)
+	if usage != nil {
+		total := resourceAmount(totalUsage)
+		report.Usage = &total
+	}
+
+	sort.SliceStable(report.Namespaces, func(i, j int) bool {
+		a, b := namespaceUsageSortKey(&report.Namespaces[i], sortBy), namespaceUsageSortKey(&report.Namespaces[j], sortBy)
+		if a != b {
+			return a > b
+		}
+		return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace
+	})
+	return report, nil
+}
+
+// namespaceUsageSortKey returns the value of the sort dimension of a namespace, zero for usage without metrics
+func namespaceUsageSortKey(item *models.NamespaceUsage, sortBy string) int64 {
+	switch sortBy {
+	case NamespaceUsageSortByPods:
+		return int64(item.Pods)
+	case NamespaceUsageSortByMemory:
+		return item.Requests.MemoryBytes
+	case NamespaceUsageSortByCPUUsage:
+		if item.Usage != nil {
+			return item.Usage.CPUMilli
+		}
+		return 0
+	case NamespaceUsageSortByMemoryUsage:
+		if item.Usage != nil {
+			return item.Usage.MemoryBytes
+		}
+		return 0
+	}
+	return item.Requests.CPUMilli
+}
+
+// countWorkloads counts the controller objects of a namespace by kind
+func countWorkloads(listers *NamespaceSummaryListers, namespace string, counts map[string]int) error {
+	deployments, err := listers.Deployments.Deployments(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list deployments: %w", err)
+	}
+	counts["Deployment"] = len(deployments)
+	statefulSets, err := listers.StatefulSets.StatefulSets(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	counts["StatefulSet"] = len(statefulSets)
+	daemonSets, err := listers.DaemonSets.DaemonSets(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	counts["DaemonSet"] = len(daemonSets)
+	replicaSets, err := listers.ReplicaSets.ReplicaSets(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	counts["ReplicaSet"] = len(replicaSets)
+	jobs, err := listers.Jobs.Jobs(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list jobs: %w", err)
+	}
+	counts["Job"] = len(jobs)
+	cronJobs, err := listers.CronJobs.CronJobs(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	counts["CronJob"] = len(cronJobs)
+	return nil
+}
+
+// namespaceUsage sums the current container usage of the pods in a namespace
+func namespaceUsage(metricsClient versioned.Interface, namespace string) (models.ResourceAmount, error) {
+	podMetrics, err := metricsClient.MetricsV1beta1().PodMetricses(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return models.ResourceAmount{}, metricsError(err)
+	}
+	usage := corev1.ResourceList{}
+	for _, metrics := range podMetrics.Items {
+		for _, container := range metrics.Containers {
+			addResourceList(usage, container.Usage)
+		}
+	}
+	return resourceAmount(usage), nil
+}
+
+// usageByNamespace sums the current container usage of all pods by namespace with a single metrics request
+func usageByNamespace(ctx context.Context, metricsClient versioned.Interface) (map[string]corev1.ResourceList, error) {
+	podMetrics, err := metricsClient.MetricsV1beta1().PodMetricses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, metricsError(err)
+	}
+	usage := map[string]corev1.ResourceList{}
+	for _, metrics := range podMetrics.Items {
+		if usage[metrics.Namespace] == nil {
+			usage[metrics.Namespace] = corev1.ResourceList{}
+		}
+		for _, container := range metrics.Containers {
+			addResourceList(usage[metrics.Namespace], container.Usage)
+		}
+	}
+	return usage, nil
+}
+
+// quotaHeadroom returns the CPU and memory requests a namespace can still make under the tightest of its
+// quotas, or nil when no quota limits them. A namespace over its quota has no headroom rather than a negative one.
+func quotaHeadroom(quotas []*corev1.ResourceQuota) *models.QuotaHeadroom {
+	var headroom models.QuotaHeadroom
+	for _, quota := range quotas {
+		// A quota on "cpu" or "memory" limits requests just like "requests.cpu" or "requests.memory"
+		if cpu, ok := quotaRemaining(quota, corev1.ResourceRequestsCPU, corev1.ResourceCPU); ok {
+			milli := cpu.MilliValue()
+			if headroom.CPUMilli == nil || milli < *headroom.CPUMilli {
+				headroom.CPUMilli = &milli
+			}
+		}
+		if memory, ok := quotaRemaining(quota, corev1.ResourceRequestsMemory, corev1.ResourceMemory); ok {
+			bytes := memory.Value()
+			if headroom.MemoryBytes == nil || bytes < *headroom.MemoryBytes {
+				headroom.MemoryBytes = &bytes
+			}
+		}
+	}
+	if headroom.CPUMilli == nil && headroom.MemoryBytes == nil {
+		return nil
+	}
+	if headroom.CPUMilli != nil {
+		headroom.CPU = formatCPU(*headroom.CPUMilli)
+	}
+	if headroom.MemoryBytes != nil {
+		headroom.Memory = formatMemory(*headroom.MemoryBytes)
+	}
+	return &headroom
+}
+
+// quotaRemaining returns the smallest hard limit minus the amount used among the given resources of a quota
+func quotaRemaining(quota *corev1.ResourceQuota, names ...corev1.ResourceName) (resource.Quantity, bool) {
+	var remaining resource.Quantity
+	found := false
+	for _, name := range names {
+		hard, ok := quota.Status.Hard[name]
+		if !ok {
+			continue
+		}
+		left := hard.DeepCopy()
+		left.Sub(quota.Status.Used[name])
+		if !found || left.Cmp(remaining) < 0 {
+			remaining, found = left, true
+		}
+	}
+	if found && remaining.Sign() < 0 {
+		remaining = resource.Quantity{}
+	}
+	return remaining, found
+}
+
+// quotaSummary lists the hard limits of a ResourceQuota with the amount used of each
+func quotaSummary(quota *corev1.ResourceQuota) models.ResourceQuotaSummary {
+	summary := models.ResourceQuotaSummary{Name: quota.Name, Resources: []models.QuotaResourceUsage{}}
+	for name, hard := range quota.Status.Hard {
+		used := quota.Status.Used[name]
+		item := models.QuotaResourceUsage{
+			Resource: string(name),
+			Hard:     hard.String(),
+			Used:     used.String(),
+		}
+		if hard.Sign() > 0 {
+			// Storage quotas can exceed int64 in milli units, so the ratio is computed in floating point
+			percent := math.Round(used.AsApproximateFloat64()/hard.AsApproximateFloat64()*1000) / 10
+			item.UsedPercent = &percent
+		}
+		summary.Resources = append(summary.Resources, item)
+	}
+	sort.Slice(summary.Resources, func(i, j int) bool { return summary.Resources[i].Resource < summary.Resources[j].Resource })
+	return summary
+}
+
+// resourceAmount reads the CPU and memory of a resource list
+func resourceAmount(list corev1.ResourceList) models.ResourceAmount {
+	cpu, memory := list[corev1.ResourceCPU], list[corev1.ResourceMemory]
+	return models.ResourceAmount{
+		CPUMilli:    cpu.MilliValue(),
+		MemoryBytes: memory.Value(),
+		CPU:         formatCPU(cpu.MilliValue()),
+		Memory:      formatMemory(memory.Value()),
+	}
+}
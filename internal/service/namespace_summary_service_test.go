@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestSummaryListers(t *testing.T) *NamespaceSummaryListers {
+	t.Helper()
+	finished := testTopPod("team-a", "migrate", "2", "2Gi")
+	finished.Status.Phase = corev1.PodSucceeded
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		testTopPod("team-a", "web-1", "250m", "256Mi"),
+		testTopPod("team-a", "web-2", "750m", "768Mi"),
+		finished,
+		testTopPod("team-b", "other", "4", "8Gi"),
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "compute"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4"), corev1.ResourcePods: resource.MustParse("10")},
+				Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1"), corev1.ResourcePods: resource.MustParse("2")},
+			},
+		},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "web-7d9f"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "migrate"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "api"}},
+	)
+	informers := k8s.NewInformerRegistry()
+	t.Cleanup(func() { informers.Stop("test") })
+	svc := NewNamespaceSummaryService(informers)
+	listers, err := svc.ListersFor("test", clientset)
+	require.NoError(t, err)
+	return listers
+}
+
+func TestNamespaceSummaryService_Summary(t *testing.T) {
+	listers := newTestSummaryListers(t)
+	metricsClient := newTestMetricsClient(
+		testPodMetrics("team-a", "web-1", "100m", "200Mi"),
+		testPodMetrics("team-a", "web-2", "300m", "300Mi"),
+		testPodMetrics("team-b", "other", "3", "6Gi"),
+	)
+
+	summary, err := NewNamespaceSummaryService(nil).Summary(listers, metricsClient, "team-a")
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", summary.Namespace)
+
+	// Requests and limits of running pods only, the finished migration pod is left out
+	assert.Equal(t, int64(1000), summary.Requests.CPUMilli)
+	assert.Equal(t, int64(1024*1024*1024), summary.Requests.MemoryBytes)
+	assert.Equal(t, "1.0", summary.Requests.CPU)
+	assert.Equal(t, int64(0), summary.Limits.CPUMilli)
+	assert.Equal(t, int64(2*1024*1024*1024), summary.Limits.MemoryBytes)
+
+	require.NotNil(t, summary.Usage)
+	assert.Equal(t, int64(400), summary.Usage.CPUMilli)
+	assert.Equal(t, int64(500*1024*1024), summary.Usage.MemoryBytes)
+	assert.Empty(t, summary.MetricsError)
+
+	require.Len(t, summary.Quotas, 1)
+	quota := summary.Quotas[0]
+	assert.Equal(t, "compute", quota.Name)
+	require.Len(t, quota.Resources, 2)
+	assert.Equal(t, "pods", quota.Resources[0].Resource)
+	assert.Equal(t, "10", quota.Resources[0].Hard)
+	assert.Equal(t, "2", quota.Resources[0].Used)
+	assert.Equal(t, 20.0, *quota.Resources[0].UsedPercent)
+	assert.Equal(t, "requests.cpu", quota.Resources[1].Resource)
+	assert.Equal(t, 25.0, *quota.Resources[1].UsedPercent)
+
+	assert.Equal(t, map[string]int{
+		"Pod": 3, "Deployment": 1, "ReplicaSet": 1, "StatefulSet": 0, "DaemonSet": 0, "Job": 1, "CronJob": 0,
+	}, summary.Workloads)
+}
+
+func TestNamespaceSummaryService_WithoutMetrics(t *testing.T) {
+	listers := newTestSummaryListers(t)
+	metricsClient := newTestMetricsClient()
+	metricsClient.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewServiceUnavailable("metrics-server is unavailable")
+	})
+
+	summary, err := NewNamespaceSummaryService(nil).Summary(listers, metricsClient, "team-b")
+	require.NoError(t, err, "missing metrics do not fail the summary")
+	assert.Nil(t, summary.Usage)
+	assert.Contains(t, summary.MetricsError, "metrics API is not available")
+	assert.Equal(t, int64(4000), summary.Requests.CPUMilli)
+	assert.Empty(t, summary.Quotas)
+	assert.Equal(t, 1, summary.Workloads["Deployment"])
+
+	summary, err = NewNamespaceSummaryService(nil).Summary(listers, nil, "team-b")
+	require.NoError(t, err)
+	assert.Nil(t, summary.Usage)
+	assert.Empty(t, summary.MetricsError)
+}
+
+func TestNamespaceSummaryService_MissingNamespace(t *testing.T) {
+	listers := newTestSummaryListers(t)
+	_, err := NewNamespaceSummaryService(nil).Summary(listers, nil, "missing")
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func newTestUsageListers(t *testing.T) *NamespaceSummaryListers {
+	t.Helper()
+	finished := testTopPod("team-a", "migrate", "2", "2Gi")
+	finished.Status.Phase = corev1.PodSucceeded
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "empty"}},
+		testTopPod("team-a", "web-1", "250m", "256Mi"),
+		testTopPod("team-a", "web-2", "750m", "768Mi"),
+		finished,
+		testTopPod("team-b", "other", "4", "8Gi"),
+		testTopPod("team-c", "worker-1", "100m", "64Mi"),
+		testTopPod("team-c", "worker-2", "100m", "64Mi"),
+		testTopPod("team-c", "worker-3", "100m", "64Mi"),
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "compute"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4"), corev1.ResourceRequestsMemory: resource.MustParse("2Gi")},
+				Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1"), corev1.ResourceRequestsMemory: resource.MustParse("1Gi")},
+			},
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "tight"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+				Used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			},
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "compute"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("2"), corev1.ResourcePods: resource.MustParse("10")},
+				Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("4"), corev1.ResourcePods: resource.MustParse("1")},
+			},
+		},
+	)
+	informers := k8s.NewInformerRegistry()
+	t.Cleanup(func() { informers.Stop("test") })
+	svc := NewNamespaceSummaryService(informers)
+	listers, err := svc.ListersFor("test", clientset)
+	require.NoError(t, err)
+	return listers
+}
+
+// usageOrder returns the namespaces of a usage report in order
+func usageOrder(report *models.NamespaceUsageReport) []string {
+	names := make([]string, 0, len(report.Namespaces))
+	for _, item := range report.Namespaces {
+		names = append(names, item.Namespace)
+	}
+	return names
+}
+
+func TestNamespaceSummaryService_Usage(t *testing.T) {
+	listers := newTestUsageListers(t)
+	metricsClient := newTestMetricsClient(
+		testPodMetrics("team-a", "web-1", "100m", "200Mi"),
+		testPodMetrics("team-a", "web-2", "300m", "300Mi"),
+		testPodMetrics("team-b", "other", "3", "6Gi"),
+		testPodMetrics("team-c", "worker-1", "1500m", "100Mi"),
+	)
+	svc := NewNamespaceSummaryService(nil)
+
+	report, err := svc.Usage(context.Background(), listers, metricsClient, "")
+	require.NoError(t, err)
+	assert.Equal(t, NamespaceUsageSortByCPU, report.SortBy, "CPU requests are the default dimension")
+	assert.Equal(t, []string{"team-b", "team-a", "team-c", "empty"}, usageOrder(report))
+	assert.Empty(t, report.MetricsError)
+
+	// Totals cover every namespace, leaving out the finished pod
+	assert.Equal(t, 6, report.Pods)
+	assert.Equal(t, int64(5300), report.Requests.CPUMilli)
+	assert.Equal(t, int64(9*1024*1024*1024+192*1024*1024), report.Requests.MemoryBytes)
+	require.NotNil(t, report.Usage)
+	assert.Equal(t, int64(4900), report.Usage.CPUMilli)
+	assert.Equal(t, int64(6*1024*1024*1024+600*1024*1024), report.Usage.MemoryBytes)
+
+	teamA := report.Namespaces[1]
+	assert.Equal(t, 2, teamA.Pods)
+	assert.Equal(t, int64(1000), teamA.Requests.CPUMilli)
+	require.NotNil(t, teamA.Usage)
+	assert.Equal(t, int64(400), teamA.Usage.CPUMilli)
+	// The tighter "cpu" quota leaves less CPU headroom than the "requests.cpu" one
+	require.NotNil(t, teamA.QuotaHeadroom)
+	assert.Equal(t, int64(1000), *teamA.QuotaHeadroom.CPUMilli)
+	assert.Equal(t, int64(1024*1024*1024), *teamA.QuotaHeadroom.MemoryBytes)
+
+	teamB := report.Namespaces[0]
+	require.NotNil(t, teamB.QuotaHeadroom)
+	assert.Equal(t, int64(0), *teamB.QuotaHeadroom.CPUMilli, "a namespace over its quota has no headroom")
+	assert.Nil(t, teamB.QuotaHeadroom.MemoryBytes)
+	assert.Empty(t, teamB.QuotaHeadroom.Memory)
+
+	empty := report.Namespaces[3]
+	assert.Zero(t, empty.Pods)
+	assert.Nil(t, empty.QuotaHeadroom)
+	require.NotNil(t, empty.Usage)
+	assert.Zero(t, empty.Usage.CPUMilli)
+
+	for sortBy, want := range map[string][]string{
+		NamespaceUsageSortByPods:        {"team-c", "team-a", "team-b", "empty"},
+		NamespaceUsageSortByMemory:      {"team-b", "team-a", "team-c", "empty"},
+		NamespaceUsageSortByCPUUsage:    {"team-b", "team-c", "team-a", "empty"},
+		NamespaceUsageSortByMemoryUsage: {"team-b", "team-a", "team-c", "empty"},
+	} {
+		report, err := svc.Usage(context.Background(), listers, metricsClient, sortBy)
+		require.NoError(t, err)
+		assert.Equal(t, sortBy, report.SortBy)
+		assert.Equal(t, want, usageOrder(report), sortBy)
+	}
+}
+
+func TestNamespaceSummaryService_UsageWithoutMetrics(t *testing.T) {
+	listers := newTestUsageListers(t)
+	metricsClient := newTestMetricsClient()
+	metricsClient.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewServiceUnavailable("metrics-server is unavailable")
+	})
+	svc := NewNamespaceSummaryService(nil)
+
+	report, err := svc.Usage(context.Background(), listers, metricsClient, NamespaceUsageSortByCPUUsage)
+	require.NoError(t, err, "missing metrics do not fail the report")
+	assert.Contains(t, report.MetricsError, "metrics API is not available")
+	assert.Nil(t, report.Usage)
+	assert.Nil(t, report.Namespaces[0].Usage)
+	assert.Equal(t, int64(5300), report.Requests.CPUMilli)
+	// Without usage every namespace ties, so they are ordered by name
+	assert.Equal(t, []string{"empty", "team-a", "team-b", "team-c"}, usageOrder(report))
+
+	_, err = svc.Usage(context.Background(), listers, nil, "restarts")
+	assert.ErrorContains(t, err, `unsupported sortBy "restarts"`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = svc.Usage(ctx, listers, nil, "")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// nodePressureConditions are the node conditions that signal a problem when true
+var nodePressureConditions = map[corev1.NodeConditionType]bool{
+	corev1.NodeMemoryPressure:     true,
+	corev1.NodeDiskPressure:       true,
+	corev1.NodePIDPressure:        true,
+	corev1.NodeNetworkUnavailable: true,
+}
+
+// NodeProblems lists from the informer cache the nodes that are not ready, have a memory, disk or PID
+// pressure, an unavailable network, or are cordoned. A node that never reported its Ready condition is
+// reported with an Unknown one.
+func (s *CapacityService) NodeProblems(nodes corelisters.NodeLister) (*models.NodeProblemSummary, error) {
+	list, err := nodes.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	result := &models.NodeProblemSummary{Nodes: len(list), Problems: []models.NodeProblem{}}
+	for _, node := range list {
+		problem := models.NodeProblem{
+			Name:       node.Name,
+			Ready:      isNodeReady(node),
+			Cordoned:   node.Spec.Unschedulable,
+			Conditions: nodeConditionProblems(node),
+		}
+		if problem.Cordoned {
+			result.CordonedNodes++
+		}
+		if len(problem.Conditions) == 0 && !problem.Cordoned {
+			continue
+		}
+		result.Problems = append(result.Problems, problem)
+	}
+	result.ProblemNodes = len(result.Problems)
+	return result, nil
+}
+
+// nodeConditionProblems returns the conditions of a node in a problematic state
+func nodeConditionProblems(node *corev1.Node) []models.NodeConditionProblem {
+	problems := []models.NodeConditionProblem{}
+	hasReady := false
+	for _, condition := range node.Status.Conditions {
+		problematic := false
+		switch {
+		case condition.Type == corev1.NodeReady:
+			hasReady = true
+			problematic = condition.Status != corev1.ConditionTrue
+		case nodePressureConditions[condition.Type]:
+			problematic = condition.Status == corev1.ConditionTrue
+		}
+		if problematic {
+			problems = append(problems, models.NodeConditionProblem{
+				Type:               string(condition.Type),
+				Status:             string(condition.Status),
+				Reason:             condition.Reason,
+				Message:            condition.Message,
+				LastTransitionTime: condition.LastTransitionTime.Time,
+			})
+		}
+	}
+	if !hasReady {
+		problems = append([]models.NodeConditionProblem{{
+			Type:    string(corev1.NodeReady),
+			Status:  string(corev1.ConditionUnknown),
+			Message: "node has not reported a Ready condition",
+		}}, problems...)
+	}
+	return problems
+}
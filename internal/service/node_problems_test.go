@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testProblemNode(name string, cordoned bool, conditions ...corev1.NodeCondition) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: cordoned},
+		Status:     corev1.NodeStatus{Conditions: conditions},
+	}
+}
+
+func testNodeCondition(conditionType corev1.NodeConditionType, status corev1.ConditionStatus, reason string, since time.Time) corev1.NodeCondition {
+	return corev1.NodeCondition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            reason + " message",
+		LastTransitionTime: metav1.NewTime(since),
+	}
+}
+
+func TestCapacityService_NodeProblems(t *testing.T) {
+	since := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	ready := testNodeCondition(corev1.NodeReady, corev1.ConditionTrue, "KubeletReady", since)
+	noPressure := testNodeCondition(corev1.NodeMemoryPressure, corev1.ConditionFalse, "KubeletHasSufficientMemory", since)
+
+	clientset := fake.NewSimpleClientset(
+		testProblemNode("healthy", false, ready, noPressure),
+		testProblemNode("cordoned", true, ready),
+		testProblemNode("not-ready", false, testNodeCondition(corev1.NodeReady, corev1.ConditionFalse, "KubeletNotReady", since)),
+		testProblemNode("unknown", true, testNodeCondition(corev1.NodeReady, corev1.ConditionUnknown, "NodeStatusUnknown", since)),
+		testProblemNode("pressure", false, ready,
+			testNodeCondition(corev1.NodeMemoryPressure, corev1.ConditionTrue, "KubeletHasInsufficientMemory", since),
+			testNodeCondition(corev1.NodeDiskPressure, corev1.ConditionTrue, "KubeletHasDiskPressure", since),
+			testNodeCondition(corev1.NodePIDPressure, corev1.ConditionTrue, "KubeletHasInsufficientPID", since),
+		),
+		testProblemNode("network", false, ready, testNodeCondition(corev1.NodeNetworkUnavailable, corev1.ConditionTrue, "NoRouteCreated", since)),
+		testProblemNode("new", false),
+	)
+	informers := k8s.NewInformerRegistry()
+	t.Cleanup(func() { informers.Stop("test") })
+	svc := NewCapacityService(informers)
+	listers, err := svc.ListersFor("test", clientset)
+	require.NoError(t, err)
+
+	result, err := svc.NodeProblems(listers.Nodes)
+	require.NoError(t, err)
+	assert.Equal(t, 7, result.Nodes)
+	assert.Equal(t, 6, result.ProblemNodes, "every node but the healthy one")
+	assert.Equal(t, 2, result.CordonedNodes)
+
+	problems := make(map[string][]string)
+	for _, problem := range result.Problems {
+		for _, condition := range problem.Conditions {
+			problems[problem.Name] = append(problems[problem.Name], condition.Type+"="+condition.Status)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"not-ready": {"Ready=False"},
+		"unknown":   {"Ready=Unknown"},
+		"pressure":  {"MemoryPressure=True", "DiskPressure=True", "PIDPressure=True"},
+		"network":   {"NetworkUnavailable=True"},
+		"new":       {"Ready=Unknown"},
+	}, problems)
+
+	require.Len(t, result.Problems, 6)
+	assert.Equal(t, "cordoned", result.Problems[0].Name, "sorted by name")
+	assert.True(t, result.Problems[0].Cordoned)
+	assert.True(t, result.Problems[0].Ready)
+	assert.Empty(t, result.Problems[0].Conditions, "cordoned but healthy")
+
+	notReady := result.Problems[3]
+	require.Equal(t, "not-ready", notReady.Name)
+	assert.False(t, notReady.Ready)
+	assert.False(t, notReady.Cordoned)
+	assert.Equal(t, "KubeletNotReady", notReady.Conditions[0].Reason)
+	assert.Equal(t, "KubeletNotReady message", notReady.Conditions[0].Message)
+	assert.True(t, since.Equal(notReady.Conditions[0].LastTransitionTime))
+}
@@ -1,12 +1,14 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,17 +18,37 @@ import (
 	"github.com/ciliverse/cilikube/pkg/auth"
 )
 
+const (
+	// defaultGitHubTokenURL is the GitHub endpoint for code exchange and token refresh
+	defaultGitHubTokenURL = "https://github.com/login/oauth/access_token"
+	// oauthTokenExpirySkew refreshes tokens slightly before they actually expire
+	oauthTokenExpirySkew = time.Minute
+	// OAuthCallbackPath is the route providers redirect back to when no redirect URL is configured
+	OAuthCallbackPath = "/api/v1/auth/oauth/callback"
+)
+
+// ErrOAuthProviderNotLinked is returned when unlinking a provider the user has not linked
+var ErrOAuthProviderNotLinked = errors.New("OAuth provider is not linked to this account")
+
+// ErrLastAuthMethod is returned when unlinking would leave the user without a way to sign in
+var ErrLastAuthMethod = errors.New("cannot unlink the only authentication method of this account")
+
+// ErrNoRefreshToken is returned when an expired OAuth token has no refresh token to renew it with
+var ErrNoRefreshToken = errors.New("OAuth token expired and no refresh token is stored")
+
 // OAuthService provides OAuth authentication functionality
 type OAuthService struct {
-	store  store.Store
-	config *configs.Config
+	store          store.Store
+	config         *configs.Config
+	githubTokenURL string
 }
 
 // NewOAuthService creates a new OAuthService instance
 func NewOAuthService(store store.Store, config *configs.Config) *OAuthService {
 	return &OAuthService{
-		store:  store,
-		config: config,
+		store:          store,
+		config:         config,
+		githubTokenURL: defaultGitHubTokenURL,
 	}
 }
 
@@ -45,13 +67,25 @@ type GitHubTokenResponse struct {
 	TokenType    string `json:"token_type"`
 	Scope        string `json:"scope"`
 	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+	ErrorDesc    string `json:"error_description"`
 }
 
-// GetAuthURL generates OAuth authorization URL for the specified provider
-func (s *OAuthService) GetAuthURL(provider, state string) (string, error) {
+// GetAuthURL generates OAuth authorization URL for the specified provider. The provider redirects to the
+// configured redirect URL, or to the OAuthCallbackPath below server.external_url, or else to callbackURL,
+// the OAuthCallbackPath at the address the client reached us with.
+func (s *OAuthService) GetAuthURL(provider, state, callbackURL string) (string, error) {
+	if externalURL := s.config.Server.ExternalURL; externalURL != "" {
+		callbackURL = strings.TrimSuffix(externalURL, "/") + OAuthCallbackPath
+	}
 	switch provider {
 	case "github":
-		return s.getGitHubAuthURL(state), nil
+		redirectURL := s.config.OAuth.GitHub.RedirectURL
+		if redirectURL == "" {
+			redirectURL = callbackURL
+		}
+		return s.getGitHubAuthURL(state, redirectURL), nil
 	default:
 		return "", fmt.Errorf("unsupported OAuth provider: %s", provider)
 	}
@@ -78,7 +112,7 @@ func (s *OAuthService) GetUserInfo(provider, token string) (*OAuthUserInfo, erro
 }
 
 // LoginWithOAuth handles OAuth login flow
-func (s *OAuthService) LoginWithOAuth(provider, code string) (*models.LoginResponse, error) {
+func (s *OAuthService) LoginWithOAuth(ctx context.Context, provider, code string) (*models.LoginResponse, error) {
 	// Exchange code for token
 	tokenResp, err := s.ExchangeToken(provider, code)
 	if err != nil {
@@ -95,22 +129,22 @@ func (s *OAuthService) LoginWithOAuth(provider, code string) (*models.LoginRespo
 	oauthProvider, err := s.store.GetOAuthProviderByProviderUserID(provider, userInfo.ProviderUserID)
 	if err == nil {
 		// Existing OAuth account, login the associated user
-		return s.loginExistingOAuthUser(oauthProvider, tokenResp)
+		return s.loginExistingOAuthUser(ctx, oauthProvider, tokenResp)
 	}
 
 	// New OAuth account, check if user with same email exists
 	existingUser, err := s.store.GetUserByEmail(userInfo.Email)
 	if err == nil {
 		// User exists, link OAuth account
-		return s.linkOAuthToExistingUser(existingUser, provider, userInfo, tokenResp)
+		return s.linkOAuthToExistingUser(ctx, existingUser, provider, userInfo, tokenResp)
 	}
 
 	// Create new user with OAuth account
-	return s.createNewOAuthUser(provider, userInfo, tokenResp)
+	return s.createNewOAuthUser(ctx, provider, userInfo, tokenResp)
 }
 
 // LinkAccount links an OAuth provider to an existing user account
-func (s *OAuthService) LinkAccount(userID uint, provider, code string) error {
+func (s *OAuthService) LinkAccount(ctx context.Context, userID uint, provider, code string) error {
 	// Exchange code for token
 	tokenResp, err := s.ExchangeToken(provider, code)
 	if err != nil {
@@ -159,30 +193,142 @@ func (s *OAuthService) LinkAccount(userID uint, provider, code string) error {
 	}
 
 	// Create audit log
-	s.createAuditLog(&userID, "oauth_link", "oauth_provider", fmt.Sprintf("%s:%s", provider, userInfo.ProviderUserID), "", "", fmt.Sprintf("OAuth account linked: %s", provider))
+	s.createAuditLog(ctx, &userID, "oauth_link", "oauth_provider", fmt.Sprintf("%s:%s", provider, userInfo.ProviderUserID), fmt.Sprintf("OAuth account linked: %s", provider))
 
 	return nil
 }
 
-// UnlinkAccount removes OAuth provider from user account
-func (s *OAuthService) UnlinkAccount(userID uint, provider string) error {
+// UnlinkAccount removes OAuth provider from user account.
+// A provider cannot be unlinked when it is the only way the user can sign in.
+func (s *OAuthService) UnlinkAccount(ctx context.Context, userID uint, provider string) error {
+	if _, err := s.store.GetOAuthProvider(userID, provider); err != nil {
+		if errors.Is(err, store.ErrOAuthProviderNotFound) {
+			return ErrOAuthProviderNotLinked
+		}
+		return fmt.Errorf("failed to get OAuth provider: %w", err)
+	}
+
+	user, err := s.store.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.PasswordHash == "" {
+		providers, err := s.store.ListUserOAuthProviders(userID)
+		if err != nil {
+			return fmt.Errorf("failed to list OAuth providers: %w", err)
+		}
+		if len(providers) <= 1 {
+			return ErrLastAuthMethod
+		}
+	}
+
 	if err := s.store.DeleteOAuthProvider(userID, provider); err != nil {
 		return fmt.Errorf("failed to unlink OAuth provider: %w", err)
 	}
 
 	// Create audit log
-	s.createAuditLog(&userID, "oauth_unlink", "oauth_provider", fmt.Sprintf("%s:%d", provider, userID), "", "", fmt.Sprintf("OAuth account unlinked: %s", provider))
+	s.createAuditLog(ctx, &userID, "oauth_unlink", "oauth_provider", fmt.Sprintf("%s:%d", provider, userID), fmt.Sprintf("OAuth account unlinked: %s", provider))
 
 	return nil
 }
 
+// ListLinkedProviders returns the OAuth providers linked to a user account
+func (s *OAuthService) ListLinkedProviders(userID uint) ([]models.OAuthProviderResponse, error) {
+	providers, err := s.store.ListUserOAuthProviders(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OAuth providers: %w", err)
+	}
+
+	items := make([]models.OAuthProviderResponse, 0, len(providers))
+	for _, p := range providers {
+		items = append(items, models.OAuthProviderResponse{
+			ID:             p.ID,
+			Provider:       p.Provider,
+			ProviderUserID: p.ProviderUserID,
+			ConnectedAt:    p.CreatedAt,
+			ExpiresAt:      p.ExpiresAt,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Provider < items[j].Provider
+	})
+	return items, nil
+}
+
+// RefreshOAuthToken uses the stored refresh token to obtain a new access token and updates the stored provider record
+func (s *OAuthService) RefreshOAuthToken(ctx context.Context, userID uint, provider string) (*store.OAuthProvider, error) {
+	oauthProvider, err := s.store.GetOAuthProvider(userID, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OAuth provider: %w", err)
+	}
+	if oauthProvider.RefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	var tokenResp *OAuthTokenResponse
+	switch provider {
+	case "github":
+		tokenResp, err = s.refreshGitHubToken(oauthProvider.RefreshToken)
+	default:
+		return nil, fmt.Errorf("unsupported OAuth provider: %s", provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	oauthProvider.AccessToken = tokenResp.AccessToken
+	// Providers may rotate the refresh token; keep the old one if none is returned
+	if tokenResp.RefreshToken != "" {
+		oauthProvider.RefreshToken = tokenResp.RefreshToken
+	}
+	oauthProvider.ExpiresAt = nil
+	if tokenResp.ExpiresIn > 0 {
+		expiry := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+		oauthProvider.ExpiresAt = &expiry
+	}
+
+	if err := s.store.UpdateOAuthProvider(oauthProvider); err != nil {
+		return nil, fmt.Errorf("failed to update OAuth provider: %w", err)
+	}
+
+	s.createAuditLog(ctx, &userID, "oauth_refresh", "oauth_provider", fmt.Sprintf("%s:%s", provider, oauthProvider.ProviderUserID), fmt.Sprintf("OAuth token refreshed: %s", provider))
+
+	return oauthProvider, nil
+}
+
+// GetAccessToken returns the stored access token of a user for a provider, refreshing it first if it has expired.
+// Provider API calls made on behalf of a user should obtain their token through this method.
+func (s *OAuthService) GetAccessToken(ctx context.Context, userID uint, provider string) (string, error) {
+	oauthProvider, err := s.store.GetOAuthProvider(userID, provider)
+	if err != nil {
+		return "", fmt.Errorf("failed to get OAuth provider: %w", err)
+	}
+	if !oauthTokenExpired(oauthProvider) {
+		return oauthProvider.AccessToken, nil
+	}
+
+	refreshed, err := s.RefreshOAuthToken(ctx, userID, provider)
+	if err != nil {
+		return "", err
+	}
+	return refreshed.AccessToken, nil
+}
+
+// oauthTokenExpired reports whether a stored token is expired. Tokens without an expiry never expire.
+func oauthTokenExpired(provider *store.OAuthProvider) bool {
+	if provider.ExpiresAt == nil {
+		return false
+	}
+	return time.Now().Add(oauthTokenExpirySkew).After(*provider.ExpiresAt)
+}
+
 // GitHub OAuth implementation
 
-func (s *OAuthService) getGitHubAuthURL(state string) string {
+func (s *OAuthService) getGitHubAuthURL(state, redirectURL string) string {
 	baseURL := "https://github.com/login/oauth/authorize"
 	params := url.Values{}
 	params.Add("client_id", s.config.OAuth.GitHub.ClientID)
-	params.Add("redirect_uri", s.config.OAuth.GitHub.RedirectURL)
+	params.Add("redirect_uri", redirectURL)
 	params.Add("scope", "user:email")
 	params.Add("state", state)
 
@@ -190,14 +336,49 @@ func (s *OAuthService) getGitHubAuthURL(state string) string {
 }
 
 func (s *OAuthService) exchangeGitHubToken(code string) (*OAuthTokenResponse, error) {
-	tokenURL := "https://github.com/login/oauth/access_token"
-
 	data := url.Values{}
 	data.Set("client_id", s.config.OAuth.GitHub.ClientID)
 	data.Set("client_secret", s.config.OAuth.GitHub.ClientSecret)
 	data.Set("code", code)
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
+	tokenResp, err := s.requestGitHubToken(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthTokenResponse{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		TokenType:    tokenResp.TokenType,
+		// Classic GitHub tokens report no expiry; apps with expiring tokens return their lifetime
+		ExpiresIn: tokenResp.ExpiresIn,
+	}, nil
+}
+
+func (s *OAuthService) refreshGitHubToken(refreshToken string) (*OAuthTokenResponse, error) {
+	data := url.Values{}
+	data.Set("client_id", s.config.OAuth.GitHub.ClientID)
+	data.Set("client_secret", s.config.OAuth.GitHub.ClientSecret)
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+
+	tokenResp, err := s.requestGitHubToken(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only apps with expiring tokens have a refresh token, so honor the lifetime they return
+	return &OAuthTokenResponse{
+		AccessToken:  tokenResp.AccessToken,
+		RefreshToken: tokenResp.RefreshToken,
+		TokenType:    tokenResp.TokenType,
+		ExpiresIn:    tokenResp.ExpiresIn,
+	}, nil
+}
+
+// requestGitHubToken posts a form to the GitHub token endpoint
+func (s *OAuthService) requestGitHubToken(data url.Values) (*GitHubTokenResponse, error) {
+	req, err := http.NewRequest("POST", s.githubTokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -221,13 +402,12 @@ func (s *OAuthService) exchangeGitHubToken(code string) (*OAuthTokenResponse, er
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
 		return nil, fmt.Errorf("failed to decode token response: %w", err)
 	}
+	// GitHub reports errors such as a bad verification code or refresh token with status 200
+	if tokenResp.Error != "" {
+		return nil, fmt.Errorf("GitHub token exchange failed: %s: %s", tokenResp.Error, tokenResp.ErrorDesc)
+	}
 
-	return &OAuthTokenResponse{
-		AccessToken:  tokenResp.AccessToken,
-		RefreshToken: tokenResp.RefreshToken,
-		TokenType:    tokenResp.TokenType,
-		ExpiresIn:    0, // GitHub tokens don't expire
-	}, nil
+	return &tokenResp, nil
 }
 
 func (s *OAuthService) getGitHubUserInfo(token string) (*OAuthUserInfo, error) {
@@ -323,7 +503,7 @@ func (s *OAuthService) getGitHubUserEmail(token string) (string, error) {
 
 // Helper methods for OAuth login flow
 
-func (s *OAuthService) loginExistingOAuthUser(oauthProvider *store.OAuthProvider, tokenResp *OAuthTokenResponse) (*models.LoginResponse, error) {
+func (s *OAuthService) loginExistingOAuthUser(ctx context.Context, oauthProvider *store.OAuthProvider, tokenResp *OAuthTokenResponse) (*models.LoginResponse, error) {
 	// Get the associated user
 	storeUser, err := s.store.GetUserByID(oauthProvider.UserID)
 	if err != nil {
@@ -375,13 +555,14 @@ func (s *OAuthService) loginExistingOAuthUser(oauthProvider *store.OAuthProvider
 	}
 
 	// Generate JWT token
+	user.AuthMethod = auth.AuthMethodOAuth
 	token, expiresAtJWT, err := auth.GenerateToken(&user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	// Create audit log
-	s.createAuditLog(&storeUser.ID, "oauth_login", "user", fmt.Sprintf("%d", storeUser.ID), "", "", fmt.Sprintf("User logged in via OAuth: %s", oauthProvider.Provider))
+	s.createAuditLog(ctx, &storeUser.ID, "oauth_login", "user", fmt.Sprintf("%d", storeUser.ID), fmt.Sprintf("User logged in via OAuth: %s", oauthProvider.Provider))
 
 	return &models.LoginResponse{
 		Token:     token,
@@ -390,7 +571,7 @@ func (s *OAuthService) loginExistingOAuthUser(oauthProvider *store.OAuthProvider
 	}, nil
 }
 
-func (s *OAuthService) linkOAuthToExistingUser(existingUser *store.User, provider string, userInfo *OAuthUserInfo, tokenResp *OAuthTokenResponse) (*models.LoginResponse, error) {
+func (s *OAuthService) linkOAuthToExistingUser(ctx context.Context, existingUser *store.User, provider string, userInfo *OAuthUserInfo, tokenResp *OAuthTokenResponse) (*models.LoginResponse, error) {
 	// Check if user is active
 	if !existingUser.IsActive {
 		return nil, errors.New("account is disabled")
@@ -449,13 +630,14 @@ func (s *OAuthService) linkOAuthToExistingUser(existingUser *store.User, provide
 	}
 
 	// Generate JWT token
+	user.AuthMethod = auth.AuthMethodOAuth
 	token, expiresAtJWT, err := auth.GenerateToken(&user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	// Create audit log
-	s.createAuditLog(&existingUser.ID, "oauth_login_link", "user", fmt.Sprintf("%d", existingUser.ID), "", "", fmt.Sprintf("User logged in and linked OAuth: %s", provider))
+	s.createAuditLog(ctx, &existingUser.ID, "oauth_login_link", "user", fmt.Sprintf("%d", existingUser.ID), fmt.Sprintf("User logged in and linked OAuth: %s", provider))
 
 	return &models.LoginResponse{
 		Token:     token,
@@ -464,7 +646,7 @@ func (s *OAuthService) linkOAuthToExistingUser(existingUser *store.User, provide
 	}, nil
 }
 
-func (s *OAuthService) createNewOAuthUser(provider string, userInfo *OAuthUserInfo, tokenResp *OAuthTokenResponse) (*models.LoginResponse, error) {
+func (s *OAuthService) createNewOAuthUser(ctx context.Context, provider string, userInfo *OAuthUserInfo, tokenResp *OAuthTokenResponse) (*models.LoginResponse, error) {
 	// Create new user
 	storeUser := &store.User{
 		Username:      userInfo.Username,
@@ -475,39 +657,42 @@ func (s *OAuthService) createNewOAuthUser(provider string, userInfo *OAuthUserIn
 		EmailVerified: true, // OAuth emails are considered verified
 	}
 
-	// Create user in store
-	if err := s.store.CreateUser(storeUser); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
-
-	// Assign default viewer role
-	viewerRole, err := s.store.GetRoleByName("viewer")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get viewer role: %w", err)
-	}
-
-	if err := s.store.AssignRole(storeUser.ID, viewerRole.ID); err != nil {
-		return nil, fmt.Errorf("failed to assign default role: %w", err)
-	}
-
-	// Create OAuth provider entry
 	var expiresAt *time.Time
 	if tokenResp.ExpiresIn > 0 {
 		expiry := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
 		expiresAt = &expiry
 	}
 
-	oauthProvider := &store.OAuthProvider{
-		UserID:         storeUser.ID,
-		Provider:       provider,
-		ProviderUserID: userInfo.ProviderUserID,
-		AccessToken:    tokenResp.AccessToken,
-		RefreshToken:   tokenResp.RefreshToken,
-		ExpiresAt:      expiresAt,
-	}
+	// Create the user, its default viewer role and the OAuth provider entry in one transaction
+	err := s.store.Transaction(func(tx store.Store) error {
+		if err := tx.CreateUser(storeUser); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
 
-	if err := s.store.CreateOAuthProvider(oauthProvider); err != nil {
-		return nil, fmt.Errorf("failed to create OAuth provider: %w", err)
+		viewerRole, err := tx.GetRoleByName("viewer")
+		if err != nil {
+			return fmt.Errorf("failed to get viewer role: %w", err)
+		}
+
+		if err := tx.AssignRole(storeUser.ID, viewerRole.ID); err != nil {
+			return fmt.Errorf("failed to assign default role: %w", err)
+		}
+
+		oauthProvider := &store.OAuthProvider{
+			UserID:         storeUser.ID,
+			Provider:       provider,
+			ProviderUserID: userInfo.ProviderUserID,
+			AccessToken:    tokenResp.AccessToken,
+			RefreshToken:   tokenResp.RefreshToken,
+			ExpiresAt:      expiresAt,
+		}
+		if err := tx.CreateOAuthProvider(oauthProvider); err != nil {
+			return fmt.Errorf("failed to create OAuth provider: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert to models user
@@ -515,13 +700,14 @@ func (s *OAuthService) createNewOAuthUser(provider string, userInfo *OAuthUserIn
 	user.Role = "viewer"
 
 	// Generate JWT token
+	user.AuthMethod = auth.AuthMethodOAuth
 	token, expiresAtJWT, err := auth.GenerateToken(&user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	// Create audit log
-	s.createAuditLog(&storeUser.ID, "oauth_register", "user", fmt.Sprintf("%d", storeUser.ID), "", "", fmt.Sprintf("New user registered via OAuth: %s", provider))
+	s.createAuditLog(ctx, &storeUser.ID, "oauth_register", "user", fmt.Sprintf("%d", storeUser.ID), fmt.Sprintf("New user registered via OAuth: %s", provider))
 
 	return &models.LoginResponse{
 		Token:     token,
@@ -548,16 +734,8 @@ func (s *OAuthService) convertStoreUserToModelsUser(storeUser *store.User) model
 	}
 }
 
-func (s *OAuthService) createAuditLog(userID *uint, action, resource, resourceID, ipAddress, userAgent, details string) {
-	auditLog := &store.AuditLog{
-		UserID:     userID,
-		Action:     action,
-		Resource:   resource,
-		ResourceID: resourceID,
-		IPAddress:  ipAddress,
-		UserAgent:  userAgent,
-		Details:    details,
-	}
+func (s *OAuthService) createAuditLog(ctx context.Context, userID *uint, action, resource, resourceID, details string) {
+	auditLog := newAuditLog(ctx, userID, action, resource, resourceID, details)
 
 	// Don't fail the main operation if audit logging fails
 	if err := s.store.CreateAuditLog(auditLog); err != nil {
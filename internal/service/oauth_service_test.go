@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestOAuthService creates an OAuthService whose GitHub token endpoint is served by handler
+func setupTestOAuthService(t *testing.T, handler http.HandlerFunc) (*OAuthService, store.Store, uint) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	testStore := store.NewMemoryStore()
+	require.NoError(t, testStore.Initialize())
+
+	user := &store.User{Username: "octocat", Email: "octocat@example.com", IsActive: true}
+	require.NoError(t, testStore.CreateUser(user))
+
+	svc := NewOAuthService(testStore, &configs.Config{
+		OAuth: configs.OAuthConfig{GitHub: configs.GitHubOAuthConfig{ClientID: "id", ClientSecret: "secret"}},
+	})
+	svc.githubTokenURL = server.URL
+	return svc, testStore, user.ID
+}
+
+func createTestOAuthProvider(t *testing.T, testStore store.Store, userID uint, refreshToken string, expiresAt *time.Time) {
+	t.Helper()
+	require.NoError(t, testStore.CreateOAuthProvider(&store.OAuthProvider{
+		UserID:         userID,
+		Provider:       "github",
+		ProviderUserID: "42",
+		AccessToken:    "old-access",
+		RefreshToken:   refreshToken,
+		ExpiresAt:      expiresAt,
+	}))
+}
+
+func TestOAuthService_GetAccessToken_RefreshesExpiredToken(t *testing.T) {
+	var calls int32
+	svc, testStore, userID := setupTestOAuthService(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "old-refresh", r.PostForm.Get("refresh_token"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "new-access",
+			"refresh_token": "new-refresh",
+			"token_type":    "bearer",
+			"expires_in":    28800,
+		})
+	})
+	expired := time.Now().Add(-time.Hour)
+	createTestOAuthProvider(t, testStore, userID, "old-refresh", &expired)
+
+	token, err := svc.GetAccessToken(context.Background(), userID, "github")
+	require.NoError(t, err)
+	assert.Equal(t, "new-access", token)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	stored, err := testStore.GetOAuthProvider(userID, "github")
+	require.NoError(t, err)
+	assert.Equal(t, "new-access", stored.AccessToken)
+	assert.Equal(t, "new-refresh", stored.RefreshToken)
+	require.NotNil(t, stored.ExpiresAt)
+	assert.True(t, stored.ExpiresAt.After(time.Now().Add(7*time.Hour)))
+
+	// The refreshed token is used without another round trip
+	token, err = svc.GetAccessToken(context.Background(), userID, "github")
+	require.NoError(t, err)
+	assert.Equal(t, "new-access", token)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestOAuthService_GetAccessToken_NonExpiringToken(t *testing.T) {
+	svc, testStore, userID := setupTestOAuthService(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("token endpoint must not be called for tokens without expiry")
+	})
+	createTestOAuthProvider(t, testStore, userID, "", nil)
+
+	token, err := svc.GetAccessToken(context.Background(), userID, "github")
+	require.NoError(t, err)
+	assert.Equal(t, "old-access", token)
+}
+
+func TestOAuthService_RefreshOAuthToken_Errors(t *testing.T) {
+	svc, testStore, userID := setupTestOAuthService(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"error":             "bad_refresh_token",
+			"error_description": "The refresh token passed is incorrect or expired.",
+		})
+	})
+
+	_, err := svc.RefreshOAuthToken(context.Background(), userID, "github")
+	assert.Error(t, err, "missing provider record")
+
+	expired := time.Now().Add(-time.Hour)
+	createTestOAuthProvider(t, testStore, userID, "", &expired)
+	_, err = svc.GetAccessToken(context.Background(), userID, "github")
+	assert.ErrorIs(t, err, ErrNoRefreshToken)
+
+	stored, err := testStore.GetOAuthProvider(userID, "github")
+	require.NoError(t, err)
+	stored.RefreshToken = "revoked"
+	require.NoError(t, testStore.UpdateOAuthProvider(stored))
+
+	_, err = svc.RefreshOAuthToken(context.Background(), userID, "github")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad_refresh_token")
+}
+
+func TestOAuthService_ExchangeToken_HonorsExpiry(t *testing.T) {
+	svc, _, _ := setupTestOAuthService(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.PostForm.Get("code") == "expiring" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  "access",
+				"refresh_token": "refresh",
+				"expires_in":    28800,
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "access"})
+	})
+
+	tokenResp, err := svc.ExchangeToken("github", "expiring")
+	require.NoError(t, err)
+	assert.Equal(t, 28800, tokenResp.ExpiresIn)
+	assert.Equal(t, "refresh", tokenResp.RefreshToken)
+
+	// Classic tokens report no expiry and are stored without one
+	tokenResp, err = svc.ExchangeToken("github", "classic")
+	require.NoError(t, err)
+	assert.Zero(t, tokenResp.ExpiresIn)
+}
+
+func TestOAuthService_UnlinkAccount_LastAuthMethodGuard(t *testing.T) {
+	svc, testStore, userID := setupTestOAuthService(t, func(w http.ResponseWriter, r *http.Request) {})
+	createTestOAuthProvider(t, testStore, userID, "", nil)
+
+	// An OAuth-only user cannot remove their only provider
+	err := svc.UnlinkAccount(context.Background(), userID, "github")
+	assert.ErrorIs(t, err, ErrLastAuthMethod)
+	providers, err := svc.ListLinkedProviders(userID)
+	require.NoError(t, err)
+	assert.Len(t, providers, 1)
+
+	// With a second provider linked, one of them may go
+	require.NoError(t, testStore.CreateOAuthProvider(&store.OAuthProvider{UserID: userID, Provider: "gitlab", ProviderUserID: "7"}))
+	require.NoError(t, svc.UnlinkAccount(context.Background(), userID, "gitlab"))
+	assert.ErrorIs(t, svc.UnlinkAccount(context.Background(), userID, "github"), ErrLastAuthMethod)
+
+	assert.ErrorIs(t, svc.UnlinkAccount(context.Background(), userID, "gitlab"), ErrOAuthProviderNotLinked)
+}
+
+// failingOAuthStore fails every OAuth provider lookup, as a database outage would
+type failingOAuthStore struct {
+	store.Store
+}
+
+func (failingOAuthStore) GetOAuthProvider(uint, string) (*store.OAuthProvider, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestOAuthService_UnlinkAccount_StoreError(t *testing.T) {
+	svc, testStore, userID := setupTestOAuthService(t, func(w http.ResponseWriter, r *http.Request) {})
+	svc.store = failingOAuthStore{Store: testStore}
+
+	err := svc.UnlinkAccount(context.Background(), userID, "github")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrOAuthProviderNotLinked, "only a missing link is reported as not linked")
+}
+
+func TestOAuthService_UnlinkAccount_WithPassword(t *testing.T) {
+	svc, testStore, _ := setupTestOAuthService(t, func(w http.ResponseWriter, r *http.Request) {})
+	user := &store.User{Username: "alice", Email: "alice@example.com", PasswordHash: "Secret123", IsActive: true}
+	require.NoError(t, testStore.CreateUser(user))
+	createTestOAuthProvider(t, testStore, user.ID, "", nil)
+
+	// Users with a password can still sign in after unlinking their only provider
+	require.NoError(t, svc.UnlinkAccount(context.Background(), user.ID, "github"))
+	providers, err := svc.ListLinkedProviders(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, providers)
+}
+
+func TestOAuthService_GetAuthURL_RedirectURL(t *testing.T) {
+	svc, _, _ := setupTestOAuthService(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	authURL, err := svc.GetAuthURL("github", "xyz", "https://ops.example.com/cilikube"+OAuthCallbackPath)
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://ops.example.com/cilikube/api/v1/auth/oauth/callback", parsed.Query().Get("redirect_uri"))
+	assert.Equal(t, "xyz", parsed.Query().Get("state"))
+
+	svc.config.Server.ExternalURL = "https://cilikube.example.com/tools/"
+	authURL, err = svc.GetAuthURL("github", "xyz", "https://attacker.example.com"+OAuthCallbackPath)
+	require.NoError(t, err)
+	parsed, err = url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cilikube.example.com/tools/api/v1/auth/oauth/callback", parsed.Query().Get("redirect_uri"), "the external URL wins over the request")
+
+	svc.config.OAuth.GitHub.RedirectURL = "https://cilikube.example.com/oauth/done"
+	authURL, err = svc.GetAuthURL("github", "xyz", "https://ops.example.com/cilikube"+OAuthCallbackPath)
+	require.NoError(t, err)
+	parsed, err = url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cilikube.example.com/oauth/done", parsed.Query().Get("redirect_uri"), "a configured redirect URL is used as is")
+
+	_, err = svc.GetAuthURL("gitlab", "xyz", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// defaultOverviewWorkers bounds how many clusters are queried concurrently
+	defaultOverviewWorkers = 5
+	// defaultOverviewClusterTimeout bounds the time spent counting resources of one cluster
+	defaultOverviewClusterTimeout = 10 * time.Second
+)
+
+// overviewClusterSource is the subset of the cluster manager used by OverviewService
+type overviewClusterSource interface {
+	ListClusterInfo() []k8s.ClusterInfoResponse
+	GetClientByID(id string) (*k8s.Client, error)
+}
+
+// OverviewService aggregates resource counts across all registered clusters
+type OverviewService struct {
+	clusters       overviewClusterSource
+	workers        int
+	clusterTimeout time.Duration
+}
+
+// NewOverviewService creates a new OverviewService instance
+func NewOverviewService(k8sManager *k8s.ClusterManager) *OverviewService {
+	return &OverviewService{
+		clusters:       k8sManager,
+		workers:        defaultOverviewWorkers,
+		clusterTimeout: defaultOverviewClusterTimeout,
+	}
+}
+
+// GetOverview collects per-cluster resource counts and their grand totals.
+// Clusters that fail or are known to be down are reported with an error instead of failing the response.
+func (s *OverviewService) GetOverview(ctx context.Context) *models.MultiClusterOverviewResponse {
+	infos := s.clusters.ListClusterInfo()
+	results := make([]models.ClusterOverview, len(infos))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < s.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.clusterOverview(ctx, infos[i])
+			}
+		}()
+	}
+	for i := range infos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	response := &models.MultiClusterOverviewResponse{
+		Clusters:      results,
+		Totals:        models.ResourceCounts{PodsByPhase: make(map[string]int)},
+		TotalClusters: len(results),
+	}
+	for _, result := range results {
+		if result.Counts == nil {
+			response.UnreachableClusters++
+			continue
+		}
+		response.ReachableClusters++
+		response.Totals.Nodes += result.Counts.Nodes
+		response.Totals.Pods += result.Counts.Pods
+		response.Totals.Namespaces += result.Counts.Namespaces
+		response.Totals.Deployments += result.Counts.Deployments
+		for phase, count := range result.Counts.PodsByPhase {
+			response.Totals.PodsByPhase[phase] += count
+		}
+	}
+	return response
+}
+
+// clusterOverview counts the resources of a single cluster within the per-cluster timeout
+func (s *OverviewService) clusterOverview(ctx context.Context, info k8s.ClusterInfoResponse) models.ClusterOverview {
+	overview := models.ClusterOverview{
+		ID:     info.ID,
+		Name:   info.Name,
+		Status: info.Status,
+	}
+
+	// Skip clusters the status updater already found to be down
+	if isClusterKnownDown(info.Status) {
+		overview.Error = info.Status
+		return overview
+	}
+
+	client, err := s.clusters.GetClientByID(info.ID)
+	if err != nil {
+		overview.Error = err.Error()
+		return overview
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.clusterTimeout)
+	defer cancel()
+
+	counts, err := countClusterResources(ctx, client.Clientset)
+	if err != nil {
+		overview.Error = err.Error()
+		return overview
+	}
+	overview.Counts = counts
+	return overview
+}
+
+// countClusterResources counts nodes, pods by phase, namespaces and deployments of a cluster
+func countClusterResources(ctx context.Context, clientset kubernetes.Interface) (*models.ResourceCounts, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	deployments, err := clientset.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	counts := &models.ResourceCounts{
+		Nodes:       len(nodes.Items),
+		Pods:        len(pods.Items),
+		PodsByPhase: make(map[string]int),
+		Namespaces:  len(namespaces.Items),
+		Deployments: len(deployments.Items),
+	}
+	for _, pod := range pods.Items {
+		phase := string(pod.Status.Phase)
+		if phase == "" {
+			phase = "Unknown"
+		}
+		counts.PodsByPhase[phase]++
+	}
+	return counts, nil
+}
+
+// isClusterKnownDown reports whether a cached cluster status marks the cluster as unreachable
+func isClusterKnownDown(status string) bool {
+	return strings.HasPrefix(status, "Unavailable") || strings.HasPrefix(status, "Initialization failed")
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+type fakeOverviewClusters struct {
+	infos   []k8s.ClusterInfoResponse
+	clients map[string]*k8s.Client
+}
+
+func (f *fakeOverviewClusters) ListClusterInfo() []k8s.ClusterInfoResponse {
+	return f.infos
+}
+
+func (f *fakeOverviewClusters) GetClientByID(id string) (*k8s.Client, error) {
+	client, ok := f.clients[id]
+	if !ok {
+		return nil, fmt.Errorf("client with ID '%s' not found in memory", id)
+	}
+	return client, nil
+}
+
+func newOverviewTestClientset(nodes int, phases ...corev1.PodPhase) *fake.Clientset {
+	var objects []runtime.Object
+	for i := 0; i < nodes; i++ {
+		objects = append(objects, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}})
+	}
+	for i, phase := range phases {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: phase},
+		})
+	}
+	objects = append(objects,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+	)
+	return fake.NewSimpleClientset(objects...)
+}
+
+func TestOverviewService_GetOverview(t *testing.T) {
+	failing := fake.NewSimpleClientset()
+	failing.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	clusters := &fakeOverviewClusters{
+		infos: []k8s.ClusterInfoResponse{
+			{ID: "c1", Name: "alpha", Status: "Available"},
+			{ID: "c2", Name: "beta", Status: "Available"},
+			{ID: "c3", Name: "gamma", Status: "Available"},
+			{ID: "c4", Name: "delta", Status: "Unavailable: dial tcp timeout"},
+		},
+		clients: map[string]*k8s.Client{
+			"c1": {Clientset: newOverviewTestClientset(2, corev1.PodRunning, corev1.PodRunning, corev1.PodPending)},
+			"c2": {Clientset: newOverviewTestClientset(1, corev1.PodRunning, corev1.PodFailed)},
+			"c3": {Clientset: failing},
+			"c4": {Clientset: newOverviewTestClientset(5)},
+		},
+	}
+
+	svc := &OverviewService{clusters: clusters, workers: 2, clusterTimeout: time.Second}
+	overview := svc.GetOverview(context.Background())
+
+	assert.Equal(t, 4, overview.TotalClusters)
+	assert.Equal(t, 2, overview.ReachableClusters)
+	assert.Equal(t, 2, overview.UnreachableClusters)
+
+	assert.Equal(t, 3, overview.Totals.Nodes)
+	assert.Equal(t, 5, overview.Totals.Pods)
+	assert.Equal(t, 3, overview.Totals.PodsByPhase["Running"])
+	assert.Equal(t, 1, overview.Totals.PodsByPhase["Pending"])
+	assert.Equal(t, 1, overview.Totals.PodsByPhase["Failed"])
+	assert.Equal(t, 2, overview.Totals.Namespaces)
+	assert.Equal(t, 2, overview.Totals.Deployments)
+
+	byName := make(map[string]int)
+	for i, cluster := range overview.Clusters {
+		byName[cluster.Name] = i
+	}
+
+	gamma := overview.Clusters[byName["gamma"]]
+	assert.Nil(t, gamma.Counts)
+	assert.Contains(t, gamma.Error, "connection refused")
+
+	// Known-down clusters are skipped without being queried
+	delta := overview.Clusters[byName["delta"]]
+	assert.Nil(t, delta.Counts)
+	assert.Equal(t, "Unavailable: dial tcp timeout", delta.Error)
+
+	alpha := overview.Clusters[byName["alpha"]]
+	require.NotNil(t, alpha.Counts)
+	assert.Equal(t, 2, alpha.Counts.Nodes)
+	assert.Equal(t, 3, alpha.Counts.Pods)
+}
@@ -211,6 +211,31 @@ func (s *PermissionService) CheckPermission(userID uint, object, action string)
 	return allowed, nil
 }
 
+// CanAccessNamespace reports whether a user may read a namespace, either the namespace object itself
+// or resources inside it
+func (s *PermissionService) CanAccessNamespace(userID uint, namespace string) (bool, error) {
+	allowed, err := s.CheckPermission(userID, fmt.Sprintf("/api/v1/namespaces/%s", namespace), "GET")
+	if err != nil || allowed {
+		return allowed, err
+	}
+	return s.CheckPermission(userID, fmt.Sprintf("/api/v1/namespaces/%s/", namespace), "GET")
+}
+
+// AccessibleNamespaces returns the namespaces from the given list that a user may read, keeping their order
+func (s *PermissionService) AccessibleNamespaces(userID uint, namespaces []string) ([]string, error) {
+	accessible := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		allowed, err := s.CanAccessNamespace(userID, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			accessible = append(accessible, namespace)
+		}
+	}
+	return accessible, nil
+}
+
 // AddRolePolicy adds a new policy for a role
 func (s *PermissionService) AddRolePolicy(role, object, action string) error {
 	if s.enforcer == nil {
@@ -254,6 +279,28 @@ func (s *PermissionService) GetRolePolicies(role string) ([][]string, error) {
 	return policies, nil
 }
 
+// RolePermissions returns the policies of a role as "ACTION object" strings, in the order they were added.
+// Without a Casbin enforcer no role has permissions.
+func (s *PermissionService) RolePermissions(role string) ([]string, error) {
+	if s.enforcer == nil {
+		return []string{}, nil
+	}
+
+	policies, err := s.GetRolePolicies(role)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		if len(policy) < 3 {
+			continue
+		}
+		permissions = append(permissions, policy[2]+" "+policy[1])
+	}
+	return permissions, nil
+}
+
 // GetUserPermissions gets all effective permissions for a user
 func (s *PermissionService) GetUserPermissions(userID uint) ([][]string, error) {
 	if s.enforcer == nil {
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPermissionService returns a permission service enforcing the application's Casbin model without persistence
+func newTestPermissionService(t *testing.T) *PermissionService {
+	t.Helper()
+	enforcer, err := casbin.NewEnforcer("../../pkg/auth/model.conf")
+	require.NoError(t, err)
+	return NewPermissionService(nil, enforcer)
+}
+
+func TestPermissionService_AccessibleNamespaces(t *testing.T) {
+	s := newTestPermissionService(t)
+	require.NoError(t, s.AddRolePolicy("team-editor", "/api/v1/namespaces/team-a/*", "*"))
+	require.NoError(t, s.AddRolePolicy("team-editor", "/api/v1/namespaces/team-b/*", "*"))
+	require.NoError(t, s.AddRolePolicy("viewer", "/api/v1/namespaces/*", "GET"))
+	_, err := s.enforcer.AddGroupingPolicy("user:5", "team-editor")
+	require.NoError(t, err)
+	_, err = s.enforcer.AddGroupingPolicy("user:6", "viewer")
+	require.NoError(t, err)
+
+	all := []string{"default", "team-a", "kube-system", "team-b", "team-ab"}
+
+	accessible, err := s.AccessibleNamespaces(5, all)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team-a", "team-b"}, accessible)
+
+	accessible, err = s.AccessibleNamespaces(6, all)
+	require.NoError(t, err)
+	assert.Equal(t, all, accessible, "namespace-wide read access sees every namespace")
+
+	accessible, err = s.AccessibleNamespaces(7, all)
+	require.NoError(t, err)
+	assert.Empty(t, accessible)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ValidatePersistentVolume checks the fields required to provision a PersistentVolume
+func ValidatePersistentVolume(pv *corev1.PersistentVolume) error {
+	if pv == nil {
+		return fmt.Errorf("%w: persistent volume is empty", ErrInvalidResource)
+	}
+
+	var problems []string
+	if pv.Name == "" {
+		problems = append(problems, "metadata.name is required")
+	}
+	if storage, ok := pv.Spec.Capacity[corev1.ResourceStorage]; !ok || storage.IsZero() {
+		problems = append(problems, "spec.capacity.storage is required")
+	}
+	if len(pv.Spec.AccessModes) == 0 {
+		problems = append(problems, "spec.accessModes must contain at least one access mode")
+	}
+	if sources := persistentVolumeSources(pv); len(sources) != 1 {
+		problems = append(problems, fmt.Sprintf("exactly one volume source must be specified, found %d", len(sources)))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidResource, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// persistentVolumeSources returns the json names of the volume sources set on a PersistentVolume
+func persistentVolumeSources(pv *corev1.PersistentVolume) []string {
+	var sources []string
+	value := reflect.ValueOf(pv.Spec.PersistentVolumeSource)
+	for i := 0; i < value.NumField(); i++ {
+		if field := value.Field(i); field.Kind() == reflect.Ptr && !field.IsNil() {
+			name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+			sources = append(sources, name)
+		}
+	}
+	return sources
+}
+
+// ListPersistentVolumeItems lists PersistentVolumes as summary items including their phase
+func ListPersistentVolumeItems(clientset kubernetes.Interface) (*models.PersistentVolumeListResponse, error) {
+	pvList, err := clientset.CoreV1().PersistentVolumes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistent volumes: %w", err)
+	}
+
+	items := make([]models.PersistentVolumeItem, 0, len(pvList.Items))
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+
+		accessModes := make([]string, 0, len(pv.Spec.AccessModes))
+		for _, mode := range pv.Spec.AccessModes {
+			accessModes = append(accessModes, string(mode))
+		}
+
+		capacity := ""
+		if storage, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+			capacity = storage.String()
+		}
+
+		claim := ""
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			claim = ref.Namespace + "/" + ref.Name
+		}
+
+		volumeSource := ""
+		if sources := persistentVolumeSources(pv); len(sources) > 0 {
+			volumeSource = sources[0]
+		}
+
+		items = append(items, models.PersistentVolumeItem{
+			Name:          pv.Name,
+			Capacity:      capacity,
+			AccessModes:   accessModes,
+			ReclaimPolicy: string(pv.Spec.PersistentVolumeReclaimPolicy),
+			Phase:         string(pv.Status.Phase),
+			StorageClass:  pv.Spec.StorageClassName,
+			Claim:         claim,
+			VolumeSource:  volumeSource,
+			CreatedAt:     pv.CreationTimestamp,
+		})
+	}
+
+	return &models.PersistentVolumeListResponse{
+		Items: items,
+		Total: len(items),
+	}, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestPersistentVolume(name string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:                      corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: "/data/" + name},
+			},
+		},
+	}
+}
+
+func TestPersistentVolumeService_CRUD(t *testing.T) {
+	svc := NewBaseResourceService[*corev1.PersistentVolume](new(PVClient))
+	clientset := fake.NewSimpleClientset()
+
+	created, err := svc.Create(clientset, "", newTestPersistentVolume("pv-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "pv-1", created.Name)
+
+	created.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimDelete
+	updated, err := svc.Update(clientset, "", "pv-1", created)
+	require.NoError(t, err)
+	assert.Equal(t, corev1.PersistentVolumeReclaimDelete, updated.Spec.PersistentVolumeReclaimPolicy)
+
+	require.NoError(t, svc.Delete(clientset, "", "pv-1"))
+	_, err = svc.Get(clientset, "", "pv-1")
+	assert.Error(t, err)
+}
+
+func TestPersistentVolumeService_CreateValidation(t *testing.T) {
+	svc := NewBaseResourceService[*corev1.PersistentVolume](new(PVClient))
+	clientset := fake.NewSimpleClientset()
+
+	missingCapacity := newTestPersistentVolume("pv-no-capacity")
+	missingCapacity.Spec.Capacity = nil
+	missingCapacity.Spec.AccessModes = nil
+
+	twoSources := newTestPersistentVolume("pv-two-sources")
+	twoSources.Spec.NFS = &corev1.NFSVolumeSource{Server: "nfs.local", Path: "/exports"}
+
+	noSource := newTestPersistentVolume("pv-no-source")
+	noSource.Spec.HostPath = nil
+
+	for _, pv := range []*corev1.PersistentVolume{missingCapacity, twoSources, noSource} {
+		_, err := svc.Create(clientset, "", pv)
+		require.Error(t, err, pv.Name)
+		assert.True(t, errors.Is(err, ErrInvalidResource), pv.Name)
+	}
+
+	_, err := svc.Create(clientset, "", missingCapacity)
+	assert.Contains(t, err.Error(), "spec.capacity.storage")
+	assert.Contains(t, err.Error(), "spec.accessModes")
+
+	list, err := clientset.CoreV1().PersistentVolumes().List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+}
+
+func TestListPersistentVolumeItems(t *testing.T) {
+	bound := newTestPersistentVolume("pv-bound")
+	bound.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "default", Name: "data"}
+	bound.Status.Phase = corev1.VolumeBound
+
+	available := newTestPersistentVolume("pv-available")
+	available.Status.Phase = corev1.VolumeAvailable
+
+	clientset := fake.NewSimpleClientset(bound, available)
+
+	items, err := ListPersistentVolumeItems(clientset)
+	require.NoError(t, err)
+	require.Equal(t, 2, items.Total)
+
+	phases := make(map[string]string)
+	for _, item := range items.Items {
+		phases[item.Name] = item.Phase
+		assert.Equal(t, "10Gi", item.Capacity)
+		assert.Equal(t, "hostPath", item.VolumeSource)
+		if item.Name == "pv-bound" {
+			assert.Equal(t, "default/data", item.Claim)
+		}
+	}
+	assert.Equal(t, "Bound", phases["pv-bound"])
+	assert.Equal(t, "Available", phases["pv-available"])
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrEvictionBlocked is returned when evicting a pod would violate a PodDisruptionBudget
+var ErrEvictionBlocked = errors.New("eviction blocked by a PodDisruptionBudget")
+
+// PodEvictionService evicts single pods through the Eviction API, so PodDisruptionBudgets are honored
+type PodEvictionService struct{}
+
+// NewPodEvictionService creates a new PodEvictionService instance
+func NewPodEvictionService() *PodEvictionService {
+	return &PodEvictionService{}
+}
+
+// Evict asks the API server to evict the pod. When a PodDisruptionBudget does not allow the disruption the
+// API server refuses with 429, which is returned as ErrEvictionBlocked naming the budgets covering the pod.
+func (s *PodEvictionService) Evict(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	err = clientset.PolicyV1().Evictions(namespace).Evict(ctx, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	})
+	if err == nil || !k8serrors.IsTooManyRequests(err) {
+		return err
+	}
+
+	budgets := s.matchingBudgets(ctx, clientset, namespace, labels.Set(pod.Labels))
+	if len(budgets) == 0 {
+		return fmt.Errorf("%w: %v", ErrEvictionBlocked, err)
+	}
+	return fmt.Errorf("%w: %s (%v)", ErrEvictionBlocked, strings.Join(budgets, ", "), err)
+}
+
+// matchingBudgets describes the PodDisruptionBudgets selecting a pod with podLabels. Errors are ignored
+// because the list only improves the message of a refused eviction.
+func (s *PodEvictionService) matchingBudgets(ctx context.Context, clientset kubernetes.Interface, namespace string, podLabels labels.Set) []string {
+	pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	var budgets []string
+	for _, pdb := range pdbs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !selector.Matches(podLabels) {
+			continue
+		}
+		budgets = append(budgets, fmt.Sprintf("%s allows %d disruptions", pdb.Name, pdb.Status.DisruptionsAllowed))
+	}
+	return budgets
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestEvictionClientset returns a clientset that handles evictions like the API server: the pod is deleted
+// unless a PodDisruptionBudget selecting it allows no disruptions
+func newTestEvictionClientset(objects ...runtime.Object) *fake.Clientset {
+	clientset := fake.NewSimpleClientset(objects...)
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		// The clientset is locked while reactors run, so the tracker is used directly
+		pod, err := clientset.Tracker().Get(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		pdbs, err := clientset.Tracker().List(policyv1.SchemeGroupVersion.WithResource("poddisruptionbudgets"),
+			policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget"), eviction.Namespace)
+		if err != nil {
+			return true, nil, err
+		}
+		for _, pdb := range pdbs.(*policyv1.PodDisruptionBudgetList).Items {
+			selector, _ := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if selector.Matches(labels.Set(pod.(*corev1.Pod).Labels)) && pdb.Status.DisruptionsAllowed == 0 {
+				return true, nil, k8serrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+			}
+		}
+		return true, nil, clientset.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
+	return clientset
+}
+
+func newTestEvictionPod(name string, podLabels map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: podLabels}}
+}
+
+func newTestPDB(name string, matchLabels map[string]string, disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: matchLabels}},
+		Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+	}
+}
+
+func TestPodEvictionService_Evict(t *testing.T) {
+	clientset := newTestEvictionClientset(
+		newTestEvictionPod("web-1", map[string]string{"app": "web"}),
+		newTestPDB("web", map[string]string{"app": "web"}, 1),
+	)
+
+	require.NoError(t, NewPodEvictionService().Evict(context.Background(), clientset, "test", "web-1"))
+
+	var evicted bool
+	for _, action := range clientset.Actions() {
+		if action.Matches("create", "pods") && action.GetSubresource() == "eviction" {
+			evicted = true
+		}
+		assert.False(t, action.Matches("delete", "pods"), "the pod is evicted, not deleted")
+	}
+	assert.True(t, evicted)
+	_, err := clientset.CoreV1().Pods("test").Get(context.Background(), "web-1", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestPodEvictionService_BlockedByPDB(t *testing.T) {
+	clientset := newTestEvictionClientset(
+		newTestEvictionPod("db-0", map[string]string{"app": "db"}),
+		newTestPDB("db", map[string]string{"app": "db"}, 0),
+		newTestPDB("web", map[string]string{"app": "web"}, 0),
+	)
+
+	err := NewPodEvictionService().Evict(context.Background(), clientset, "test", "db-0")
+	require.ErrorIs(t, err, ErrEvictionBlocked)
+	assert.Contains(t, err.Error(), "db allows 0 disruptions")
+	assert.NotContains(t, err.Error(), "web allows", "only budgets selecting the pod are named")
+
+	_, err = clientset.CoreV1().Pods("test").Get(context.Background(), "db-0", metav1.GetOptions{})
+	assert.NoError(t, err, "the pod is kept")
+}
+
+func TestPodEvictionService_PodNotFound(t *testing.T) {
+	err := NewPodEvictionService().Evict(context.Background(), newTestEvictionClientset(), "test", "missing")
+	assert.True(t, k8serrors.IsNotFound(err))
+}
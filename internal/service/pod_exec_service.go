@@ -21,19 +21,15 @@ type ExecOptions struct {
 }
 
 // PodExecService handles Pod execution related operations
-type PodExecService struct {
-	config *rest.Config
-}
+type PodExecService struct{}
 
 // NewPodExecService creates Pod execution service
-func NewPodExecService(config *rest.Config) *PodExecService {
-	return &PodExecService{
-		config: config,
-	}
+func NewPodExecService() *PodExecService {
+	return &PodExecService{}
 }
 
-// Exec executes command in Pod
-func (s *PodExecService) Exec(clientset kubernetes.Interface, namespace, podName string, options *ExecOptions, stdout io.Writer, stdin io.Reader) error {
+// Exec executes command in Pod, config is the REST config of the pod's cluster
+func (s *PodExecService) Exec(config *rest.Config, clientset kubernetes.Interface, namespace, podName string, options *ExecOptions, stdout io.Writer, stdin io.Reader) error {
 	req := clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(podName).
@@ -49,7 +45,7 @@ func (s *PodExecService) Exec(clientset kubernetes.Interface, namespace, podName
 		TTY:       options.TTY,
 	}, scheme.ParameterCodec)
 
-	exec, err := remotecommand.NewSPDYExecutor(s.config, "POST", req.URL())
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
 	if err != nil {
 		return err
 	}
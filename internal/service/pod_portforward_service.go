@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PodPortForwardService opens port-forward tunnels to pods through the pods/portforward subresource
+type PodPortForwardService struct{}
+
+// NewPodPortForwardService creates Pod port-forward service
+func NewPodPortForwardService() *PodPortForwardService {
+	return &PodPortForwardService{}
+}
+
+// Dial upgrades a connection to the pods/portforward subresource of a pod. Each stream opened on the returned
+// connection is a separate TCP connection to a pod port, so many streams can be forwarded at once.
+func (s *PodPortForwardService) Dial(config *rest.Config, clientset kubernetes.Interface, namespace, podName string) (*PortForwardConnection, error) {
+	if config == nil {
+		return nil, errors.New("port forwarding requires the cluster rest config")
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("portforward")
+
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+	conn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upgrade port-forward connection: %w", err)
+	}
+	return &PortForwardConnection{conn: conn}, nil
+}
+
+// PortForwardConnection is an upgraded port-forward connection to a pod
+type PortForwardConnection struct {
+	conn httpstream.Connection
+
+	mu            sync.Mutex
+	nextRequestID int
+}
+
+// PortForwardStream is a single forwarded TCP connection to a pod port
+type PortForwardStream struct {
+	// Data carries the bytes of the forwarded connection in both directions
+	Data io.ReadWriteCloser
+	// Errors receives the error reported by the kubelet for this stream, if any, and is closed afterwards
+	Errors <-chan error
+
+	conn        httpstream.Connection
+	dataStream  httpstream.Stream
+	errorStream httpstream.Stream
+}
+
+// OpenStream opens a new forwarded connection to a pod port
+func (c *PortForwardConnection) OpenStream(port int) (*PortForwardStream, error) {
+	c.mu.Lock()
+	requestID := c.nextRequestID
+	c.nextRequestID++
+	c.mu.Unlock()
+
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(port))
+	headers.Set(corev1.PortForwardRequestIDHeader, strconv.Itoa(requestID))
+	errorStream, err := c.conn.CreateStream(headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create error stream for port %d: %w", port, err)
+	}
+	// The error stream is only read from
+	errorStream.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		message, err := io.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			errCh <- fmt.Errorf("failed to read error stream for port %d: %w", port, err)
+		case len(message) > 0:
+			errCh <- fmt.Errorf("error forwarding port %d: %s", port, message)
+		}
+	}()
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := c.conn.CreateStream(headers)
+	if err != nil {
+		c.conn.RemoveStreams(errorStream)
+		return nil, fmt.Errorf("failed to create data stream for port %d: %w", port, err)
+	}
+
+	return &PortForwardStream{
+		Data:        dataStream,
+		Errors:      errCh,
+		conn:        c.conn,
+		dataStream:  dataStream,
+		errorStream: errorStream,
+	}, nil
+}
+
+// Close closes the forwarded connection and releases its streams
+func (s *PortForwardStream) Close() {
+	s.dataStream.Close()
+	s.conn.RemoveStreams(s.dataStream, s.errorStream)
+}
+
+// Done is closed when the connection to the API server is closed
+func (c *PortForwardConnection) Done() <-chan bool {
+	return c.conn.CloseChan()
+}
+
+// Close closes the connection and all of its streams
+func (c *PortForwardConnection) Close() error {
+	return c.conn.Close()
+}
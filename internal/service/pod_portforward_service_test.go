@@ -0,0 +1,40 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+)
+
+func TestPodPortForwardService_DialWiring(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		http.Error(w, "upgrade refused", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL}
+	clientset, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err)
+
+	_, err = NewPodPortForwardService().Dial(config, clientset, "default", "web-0")
+	require.Error(t, err, "the fake API server refuses the upgrade")
+
+	req := <-requests
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "/api/v1/namespaces/default/pods/web-0/portforward", req.URL.Path)
+	assert.Equal(t, portforward.PortForwardProtocolV1Name, req.Header.Get("X-Stream-Protocol-Version"))
+	assert.Equal(t, "SPDY/3.1", req.Header.Get("Upgrade"))
+}
+
+func TestPodPortForwardService_RequiresConfig(t *testing.T) {
+	_, err := NewPodPortForwardService().Dial(nil, nil, "default", "web-0")
+	assert.Error(t, err)
+}
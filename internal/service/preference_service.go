@@ -0,0 +1,161 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ErrInvalidPreference is returned for preferences that cannot be stored
+var ErrInvalidPreference = errors.New("invalid preference")
+
+// PreferenceService manages the per-cluster preferences of users, such as the namespace used
+// when a request names none
+type PreferenceService struct {
+	store store.Store
+}
+
+// NewPreferenceService creates a new PreferenceService instance
+func NewPreferenceService(store store.Store) *PreferenceService {
+	return &PreferenceService{store: store}
+}
+
+// GetPreference returns the user's preferences for a cluster, empty if none are stored
+func (s *PreferenceService) GetPreference(userID uint, clusterID string) (*models.UserPreferenceResponse, error) {
+	pref, err := s.store.GetUserPreference(userID, clusterID)
+	if errors.Is(err, store.ErrUserPreferenceNotFound) {
+		return &models.UserPreferenceResponse{ClusterID: clusterID, Settings: map[string]interface{}{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	return toPreferenceResponse(pref)
+}
+
+// ListPreferences returns the user's preferences for every cluster they have set them for
+func (s *PreferenceService) ListPreferences(userID uint) ([]*models.UserPreferenceResponse, error) {
+	prefs, err := s.store.ListUserPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list preferences: %w", err)
+	}
+	responses := make([]*models.UserPreferenceResponse, 0, len(prefs))
+	for _, pref := range prefs {
+		response, err := toPreferenceResponse(pref)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// UpdatePreference replaces the user's preferences for a cluster
+func (s *PreferenceService) UpdatePreference(userID uint, clusterID string, req *models.UpdateUserPreferenceRequest) (*models.UserPreferenceResponse, error) {
+	namespace := strings.TrimSpace(req.DefaultNamespace)
+	if namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return nil, fmt.Errorf("%w: namespace '%s': %s", ErrInvalidPreference, namespace, strings.Join(errs, ", "))
+		}
+	}
+
+	settings := req.Settings
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreference, err)
+	}
+
+	pref := &store.UserPreference{
+		UserID:           userID,
+		ClusterID:        clusterID,
+		DefaultNamespace: namespace,
+		DefaultCluster:   req.DefaultCluster,
+		Settings:         string(data),
+	}
+	if err := s.store.SaveUserPreference(pref); err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	if pref.DefaultCluster {
+		if err := s.clearDefaultCluster(userID, clusterID); err != nil {
+			return nil, err
+		}
+	}
+	return toPreferenceResponse(pref)
+}
+
+// clearDefaultCluster unmarks the user's default cluster unless it is keep
+func (s *PreferenceService) clearDefaultCluster(userID uint, keep string) error {
+	prefs, err := s.store.ListUserPreferences(userID)
+	if err != nil {
+		return fmt.Errorf("failed to list preferences: %w", err)
+	}
+	for _, pref := range prefs {
+		if pref.ClusterID == keep || !pref.DefaultCluster {
+			continue
+		}
+		pref.DefaultCluster = false
+		if err := s.store.SaveUserPreference(pref); err != nil {
+			return fmt.Errorf("failed to save preferences: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeletePreference removes the user's preferences for a cluster
+func (s *PreferenceService) DeletePreference(userID uint, clusterID string) error {
+	if err := s.store.DeleteUserPreference(userID, clusterID); err != nil {
+		return fmt.Errorf("failed to delete preferences: %w", err)
+	}
+	return nil
+}
+
+// ResolveNamespace returns namespace if it is set, otherwise the user's default namespace for the
+// cluster. An empty result leaves the choice to the global default.
+func (s *PreferenceService) ResolveNamespace(userID uint, clusterID, namespace string) string {
+	if namespace != "" {
+		return namespace
+	}
+	pref, err := s.store.GetUserPreference(userID, clusterID)
+	if err != nil {
+		return ""
+	}
+	return pref.DefaultNamespace
+}
+
+// PreferredClusterID returns the cluster the user chose as default, empty when there is none
+func (s *PreferenceService) PreferredClusterID(userID uint) string {
+	prefs, err := s.store.ListUserPreferences(userID)
+	if err != nil {
+		return ""
+	}
+	for _, pref := range prefs {
+		if pref.DefaultCluster {
+			return pref.ClusterID
+		}
+	}
+	return ""
+}
+
+func toPreferenceResponse(pref *store.UserPreference) (*models.UserPreferenceResponse, error) {
+	settings := map[string]interface{}{}
+	if pref.Settings != "" {
+		if err := json.Unmarshal([]byte(pref.Settings), &settings); err != nil {
+			return nil, fmt.Errorf("failed to decode stored settings: %w", err)
+		}
+	}
+	updatedAt := pref.UpdatedAt
+	return &models.UserPreferenceResponse{
+		ClusterID:        pref.ClusterID,
+		DefaultNamespace: pref.DefaultNamespace,
+		DefaultCluster:   pref.DefaultCluster,
+		Settings:         settings,
+		UpdatedAt:        &updatedAt,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferenceService_UpdateAndGet(t *testing.T) {
+	svc := NewPreferenceService(store.NewMemoryStore())
+
+	empty, err := svc.GetPreference(1, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "", empty.DefaultNamespace)
+	assert.Empty(t, empty.Settings)
+
+	_, err = svc.UpdatePreference(1, "prod", &models.UpdateUserPreferenceRequest{
+		DefaultNamespace: "payments",
+		Settings:         map[string]interface{}{"theme": "dark", "pageSize": 50},
+	})
+	require.NoError(t, err)
+
+	pref, err := svc.GetPreference(1, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "payments", pref.DefaultNamespace)
+	assert.Equal(t, "dark", pref.Settings["theme"])
+	assert.Equal(t, float64(50), pref.Settings["pageSize"])
+
+	_, err = svc.UpdatePreference(1, "prod", &models.UpdateUserPreferenceRequest{DefaultNamespace: "Not_A_Namespace"})
+	assert.True(t, errors.Is(err, ErrInvalidPreference))
+
+	prefs, err := svc.ListPreferences(1)
+	require.NoError(t, err)
+	require.Len(t, prefs, 1)
+	assert.Equal(t, "prod", prefs[0].ClusterID)
+
+	require.NoError(t, svc.DeletePreference(1, "prod"))
+	pref, err = svc.GetPreference(1, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "", pref.DefaultNamespace)
+}
+
+func TestPreferenceService_ResolveNamespace(t *testing.T) {
+	svc := NewPreferenceService(store.NewMemoryStore())
+	_, err := svc.UpdatePreference(1, "prod", &models.UpdateUserPreferenceRequest{DefaultNamespace: "payments"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "payments", svc.ResolveNamespace(1, "prod", ""), "preference is applied when the namespace is omitted")
+	assert.Equal(t, "orders", svc.ResolveNamespace(1, "prod", "orders"), "an explicit namespace wins")
+	assert.Equal(t, "", svc.ResolveNamespace(1, "dev", ""), "other clusters fall back to the global default")
+	assert.Equal(t, "", svc.ResolveNamespace(2, "prod", ""), "other users fall back to the global default")
+}
+
+func TestPreferenceService_DiffUsesPreferredNamespace(t *testing.T) {
+	svc := NewPreferenceService(store.NewMemoryStore())
+	_, err := svc.UpdatePreference(1, "prod", &models.UpdateUserPreferenceRequest{DefaultNamespace: "payments"})
+	require.NoError(t, err)
+
+	live := newTestDiffDeployment("nginx:1.25", 2)
+	live.Namespace = "payments"
+	client, mapper := newTestDiffEnv(toUnstructured(t, live))
+
+	desired := newTestDiffDeployment("nginx:1.25", 2)
+	desired.Namespace = ""
+	diff, err := NewDiffService().Diff(client, mapper, toUnstructured(t, desired), svc.ResolveNamespace(1, "prod", ""), DiffOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "payments", diff.Namespace)
+	assert.False(t, diff.Creation, "the live object in the preferred namespace is found")
+
+	desired.Namespace = ""
+	diff, err = NewDiffService().Diff(client, mapper, toUnstructured(t, desired), svc.ResolveNamespace(2, "prod", ""), DiffOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "default", diff.Namespace, "without a preference the global default applies")
+	assert.True(t, diff.Creation)
+}
+
+func TestPreferenceService_PreferredClusterID(t *testing.T) {
+	svc := NewPreferenceService(store.NewMemoryStore())
+	assert.Equal(t, "", svc.PreferredClusterID(1))
+
+	_, err := svc.UpdatePreference(1, "prod", &models.UpdateUserPreferenceRequest{DefaultCluster: true})
+	require.NoError(t, err)
+	assert.Equal(t, "prod", svc.PreferredClusterID(1))
+	assert.Equal(t, "", svc.PreferredClusterID(2), "other users keep the global default")
+
+	// Choosing another default cluster replaces the previous one
+	pref, err := svc.UpdatePreference(1, "dev", &models.UpdateUserPreferenceRequest{DefaultNamespace: "sandbox", DefaultCluster: true})
+	require.NoError(t, err)
+	assert.True(t, pref.DefaultCluster)
+	assert.Equal(t, "dev", svc.PreferredClusterID(1))
+	prod, err := svc.GetPreference(1, "prod")
+	require.NoError(t, err)
+	assert.False(t, prod.DefaultCluster)
+
+	_, err = svc.UpdatePreference(1, "dev", &models.UpdateUserPreferenceRequest{DefaultNamespace: "sandbox"})
+	require.NoError(t, err)
+	assert.Equal(t, "", svc.PreferredClusterID(1))
+}
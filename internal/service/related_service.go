@@ -0,0 +1,244 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// maxRelatedDepth bounds how many ownership levels are followed in each direction
+const maxRelatedDepth = 5
+
+// relatedResourceKinds maps the resource plurals supported by the related endpoint to their kinds
+var relatedResourceKinds = map[string]string{
+	"pods":         "Pod",
+	"replicasets":  "ReplicaSet",
+	"deployments":  "Deployment",
+	"statefulsets": "StatefulSet",
+	"daemonsets":   "DaemonSet",
+	"jobs":         "Job",
+	"cronjobs":     "CronJob",
+}
+
+// RelatedKindFor returns the kind of a resource plural supported by the related endpoint
+func RelatedKindFor(resource string) (string, bool) {
+	kind, ok := relatedResourceKinds[resource]
+	return kind, ok
+}
+
+// RelatedListers groups the listers of the workload kinds linked by owner references
+type RelatedListers struct {
+	Pods         corelisters.PodLister
+	ReplicaSets  appslisters.ReplicaSetLister
+	Deployments  appslisters.DeploymentLister
+	StatefulSets appslisters.StatefulSetLister
+	DaemonSets   appslisters.DaemonSetLister
+	Jobs         batchlisters.JobLister
+	CronJobs     batchlisters.CronJobLister
+}
+
+// get returns a single object of a kind from the cache
+func (l *RelatedListers) get(kind, namespace, name string) (metav1.Object, error) {
+	switch kind {
+	case "Pod":
+		return l.Pods.Pods(namespace).Get(name)
+	case "ReplicaSet":
+		return l.ReplicaSets.ReplicaSets(namespace).Get(name)
+	case "Deployment":
+		return l.Deployments.Deployments(namespace).Get(name)
+	case "StatefulSet":
+		return l.StatefulSets.StatefulSets(namespace).Get(name)
+	case "DaemonSet":
+		return l.DaemonSets.DaemonSets(namespace).Get(name)
+	case "Job":
+		return l.Jobs.Jobs(namespace).Get(name)
+	case "CronJob":
+		return l.CronJobs.CronJobs(namespace).Get(name)
+	}
+	return nil, fmt.Errorf("unsupported kind %q", kind)
+}
+
+// ownedObject is an object together with its kind, which cached objects do not carry
+type ownedObject struct {
+	kind string
+	obj  metav1.Object
+}
+
+// listNamespace returns every cached object of the supported kinds in a namespace
+func (l *RelatedListers) listNamespace(namespace string) ([]ownedObject, error) {
+	var objects []ownedObject
+	add := func(kind string, n int, item func(int) metav1.Object) {
+		for i := 0; i < n; i++ {
+			objects = append(objects, ownedObject{kind: kind, obj: item(i)})
+		}
+	}
+
+	pods, err := l.Pods.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	add("Pod", len(pods), func(i int) metav1.Object { return pods[i] })
+	replicaSets, err := l.ReplicaSets.ReplicaSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	add("ReplicaSet", len(replicaSets), func(i int) metav1.Object { return replicaSets[i] })
+	deployments, err := l.Deployments.Deployments(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	add("Deployment", len(deployments), func(i int) metav1.Object { return deployments[i] })
+	statefulSets, err := l.StatefulSets.StatefulSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	add("StatefulSet", len(statefulSets), func(i int) metav1.Object { return statefulSets[i] })
+	daemonSets, err := l.DaemonSets.DaemonSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	add("DaemonSet", len(daemonSets), func(i int) metav1.Object { return daemonSets[i] })
+	jobs, err := l.Jobs.Jobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	add("Job", len(jobs), func(i int) metav1.Object { return jobs[i] })
+	cronJobs, err := l.CronJobs.CronJobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	add("CronJob", len(cronJobs), func(i int) metav1.Object { return cronJobs[i] })
+	return objects, nil
+}
+
+// RelatedService resolves the owner-reference graph around workload objects using informer caches
+type RelatedService struct {
+	informers *k8s.InformerRegistry
+}
+
+// NewRelatedService creates a new RelatedService instance
+func NewRelatedService(informers *k8s.InformerRegistry) *RelatedService {
+	return &RelatedService{
+		informers: informers,
+	}
+}
+
+// ListersFor returns the listers of a cluster, starting and syncing its informers on first use
+func (s *RelatedService) ListersFor(clusterID string, clientset kubernetes.Interface) (*RelatedListers, error) {
+	var listers *RelatedListers
+	err := s.informers.Sync(clusterID, clientset, func(factory informers.SharedInformerFactory) {
+		listers = &RelatedListers{
+			Pods:         factory.Core().V1().Pods().Lister(),
+			ReplicaSets:  factory.Apps().V1().ReplicaSets().Lister(),
+			Deployments:  factory.Apps().V1().Deployments().Lister(),
+			StatefulSets: factory.Apps().V1().StatefulSets().Lister(),
+			DaemonSets:   factory.Apps().V1().DaemonSets().Lister(),
+			Jobs:         factory.Batch().V1().Jobs().Lister(),
+			CronJobs:     factory.Batch().V1().CronJobs().Lister(),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listers, nil
+}
+
+// Related returns the object with its owners walked up and the objects it owns walked down
+func (s *RelatedService) Related(listers *RelatedListers, kind, namespace, name string) (*models.RelatedResource, error) {
+	obj, err := listers.get(kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := listers.listNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	ownedBy := make(map[types.UID][]ownedObject)
+	for _, candidate := range objects {
+		for _, ref := range candidate.obj.GetOwnerReferences() {
+			ownedBy[ref.UID] = append(ownedBy[ref.UID], candidate)
+		}
+	}
+
+	root := newRelatedResource(kind, obj)
+	root.Owners = s.owners(listers, obj, map[types.UID]bool{obj.GetUID(): true}, maxRelatedDepth)
+	root.Owned = s.owned(ownedBy, obj, map[types.UID]bool{obj.GetUID(): true}, maxRelatedDepth)
+	return root, nil
+}
+
+// owners follows the owner references of obj upwards
+func (s *RelatedService) owners(listers *RelatedListers, obj metav1.Object, visited map[types.UID]bool, depth int) []*models.RelatedResource {
+	if depth == 0 {
+		return nil
+	}
+
+	var result []*models.RelatedResource
+	for _, ref := range obj.GetOwnerReferences() {
+		if visited[ref.UID] {
+			continue
+		}
+		visited[ref.UID] = true
+
+		owner, err := listers.get(ref.Kind, obj.GetNamespace(), ref.Name)
+		if err != nil || owner.GetUID() != ref.UID {
+			// Unsupported kinds and owners missing from the cache are reported without following them further
+			node := &models.RelatedResource{Kind: ref.Kind, Namespace: obj.GetNamespace(), Name: ref.Name, UID: string(ref.UID)}
+			node.Missing = err == nil || k8serrors.IsNotFound(err)
+			result = append(result, node)
+			continue
+		}
+
+		node := newRelatedResource(ref.Kind, owner)
+		node.Owners = s.owners(listers, owner, visited, depth-1)
+		result = append(result, node)
+	}
+	return result
+}
+
+// owned follows the objects referencing obj as their owner downwards
+func (s *RelatedService) owned(ownedBy map[types.UID][]ownedObject, obj metav1.Object, visited map[types.UID]bool, depth int) []*models.RelatedResource {
+	if depth == 0 {
+		return nil
+	}
+
+	children := ownedBy[obj.GetUID()]
+	result := make([]*models.RelatedResource, 0, len(children))
+	for _, child := range children {
+		if visited[child.obj.GetUID()] {
+			continue
+		}
+		visited[child.obj.GetUID()] = true
+
+		node := newRelatedResource(child.kind, child.obj)
+		node.Owned = s.owned(ownedBy, child.obj, visited, depth-1)
+		result = append(result, node)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func newRelatedResource(kind string, obj metav1.Object) *models.RelatedResource {
+	return &models.RelatedResource{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		UID:       string(obj.GetUID()),
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+func ownedMeta(name string, uid types.UID, ownerKind, ownerName string, ownerUID types.UID) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{Namespace: "default", Name: name, UID: uid}
+	if ownerKind != "" {
+		controller := true
+		meta.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, UID: ownerUID, Controller: &controller}}
+	}
+	return meta
+}
+
+func setupTestRelatedListers(t *testing.T) *RelatedListers {
+	pods := newTestIndexer()
+	replicaSets := newTestIndexer()
+	deployments := newTestIndexer()
+
+	require.NoError(t, deployments.Add(&appsv1.Deployment{ObjectMeta: ownedMeta("web", "uid-deploy", "", "", "")}))
+	require.NoError(t, replicaSets.Add(&appsv1.ReplicaSet{ObjectMeta: ownedMeta("web-7d9f", "uid-rs", "Deployment", "web", "uid-deploy")}))
+	require.NoError(t, pods.Add(&corev1.Pod{ObjectMeta: ownedMeta("web-7d9f-abc", "uid-pod-1", "ReplicaSet", "web-7d9f", "uid-rs")}))
+	require.NoError(t, pods.Add(&corev1.Pod{ObjectMeta: ownedMeta("web-7d9f-def", "uid-pod-2", "ReplicaSet", "web-7d9f", "uid-rs")}))
+	// Same name in another namespace and an unrelated pod must not be linked
+	require.NoError(t, pods.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "web-7d9f-abc", UID: "uid-other"}}))
+	require.NoError(t, pods.Add(&corev1.Pod{ObjectMeta: ownedMeta("db-0", "uid-db", "StatefulSet", "db", "uid-sts")}))
+
+	return &RelatedListers{
+		Pods:         corelisters.NewPodLister(pods),
+		ReplicaSets:  appslisters.NewReplicaSetLister(replicaSets),
+		Deployments:  appslisters.NewDeploymentLister(deployments),
+		StatefulSets: appslisters.NewStatefulSetLister(newTestIndexer()),
+		DaemonSets:   appslisters.NewDaemonSetLister(newTestIndexer()),
+		Jobs:         batchlisters.NewJobLister(newTestIndexer()),
+		CronJobs:     batchlisters.NewCronJobLister(newTestIndexer()),
+	}
+}
+
+func TestRelatedService_Down(t *testing.T) {
+	listers := setupTestRelatedListers(t)
+
+	root, err := NewRelatedService(nil).Related(listers, "Deployment", "default", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "uid-deploy", root.UID)
+	assert.Empty(t, root.Owners)
+
+	require.Len(t, root.Owned, 1)
+	rs := root.Owned[0]
+	assert.Equal(t, "ReplicaSet", rs.Kind)
+	assert.Equal(t, "web-7d9f", rs.Name)
+
+	require.Len(t, rs.Owned, 2)
+	assert.Equal(t, "Pod", rs.Owned[0].Kind)
+	assert.Equal(t, "web-7d9f-abc", rs.Owned[0].Name)
+	assert.Equal(t, "web-7d9f-def", rs.Owned[1].Name)
+}
+
+func TestRelatedService_Up(t *testing.T) {
+	listers := setupTestRelatedListers(t)
+
+	root, err := NewRelatedService(nil).Related(listers, "Pod", "default", "web-7d9f-abc")
+	require.NoError(t, err)
+	assert.Empty(t, root.Owned)
+
+	require.Len(t, root.Owners, 1)
+	rs := root.Owners[0]
+	assert.Equal(t, "ReplicaSet", rs.Kind)
+	require.Len(t, rs.Owners, 1)
+	assert.Equal(t, "Deployment", rs.Owners[0].Kind)
+	assert.Equal(t, "web", rs.Owners[0].Name)
+	assert.False(t, rs.Owners[0].Missing)
+
+	// Owners missing from the cache are reported but not followed
+	root, err = NewRelatedService(nil).Related(listers, "Pod", "default", "db-0")
+	require.NoError(t, err)
+	require.Len(t, root.Owners, 1)
+	assert.Equal(t, "StatefulSet", root.Owners[0].Kind)
+	assert.True(t, root.Owners[0].Missing)
+
+	_, err = NewRelatedService(nil).Related(listers, "Pod", "default", "missing")
+	assert.True(t, k8serrors.IsNotFound(err))
+}
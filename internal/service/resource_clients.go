@@ -5,7 +5,9 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -220,9 +222,15 @@ func (c *PVClient) List(ctx context.Context, clientset kubernetes.Interface, _ s
 	return clientset.CoreV1().PersistentVolumes().List(ctx, opts)
 }
 func (c *PVClient) Create(ctx context.Context, clientset kubernetes.Interface, _ string, obj *corev1.PersistentVolume, opts metav1.CreateOptions) (*corev1.PersistentVolume, error) {
+	if err := ValidatePersistentVolume(obj); err != nil {
+		return nil, err
+	}
 	return clientset.CoreV1().PersistentVolumes().Create(ctx, obj, opts)
 }
 func (c *PVClient) Update(ctx context.Context, clientset kubernetes.Interface, _ string, obj *corev1.PersistentVolume, opts metav1.UpdateOptions) (*corev1.PersistentVolume, error) {
+	if err := ValidatePersistentVolume(obj); err != nil {
+		return nil, err
+	}
 	return clientset.CoreV1().PersistentVolumes().Update(ctx, obj, opts)
 }
 func (c *PVClient) Delete(ctx context.Context, clientset kubernetes.Interface, _ string, name string, opts metav1.DeleteOptions) error {
@@ -232,6 +240,50 @@ func (c *PVClient) Watch(ctx context.Context, clientset kubernetes.Interface, _
 	return clientset.CoreV1().PersistentVolumes().Watch(ctx, opts)
 }
 
+// --- StorageClassClient (Cluster-scoped) ---
+type StorageClassClient struct{}
+
+func (c *StorageClassClient) Get(ctx context.Context, clientset kubernetes.Interface, _ string, name string, opts metav1.GetOptions) (*storagev1.StorageClass, error) {
+	return clientset.StorageV1().StorageClasses().Get(ctx, name, opts)
+}
+func (c *StorageClassClient) List(ctx context.Context, clientset kubernetes.Interface, _ string, opts metav1.ListOptions) (runtime.Object, error) {
+	return clientset.StorageV1().StorageClasses().List(ctx, opts)
+}
+func (c *StorageClassClient) Create(ctx context.Context, clientset kubernetes.Interface, _ string, obj *storagev1.StorageClass, opts metav1.CreateOptions) (*storagev1.StorageClass, error) {
+	return clientset.StorageV1().StorageClasses().Create(ctx, obj, opts)
+}
+func (c *StorageClassClient) Update(ctx context.Context, clientset kubernetes.Interface, _ string, obj *storagev1.StorageClass, opts metav1.UpdateOptions) (*storagev1.StorageClass, error) {
+	return clientset.StorageV1().StorageClasses().Update(ctx, obj, opts)
+}
+func (c *StorageClassClient) Delete(ctx context.Context, clientset kubernetes.Interface, _ string, name string, opts metav1.DeleteOptions) error {
+	return clientset.StorageV1().StorageClasses().Delete(ctx, name, opts)
+}
+func (c *StorageClassClient) Watch(ctx context.Context, clientset kubernetes.Interface, _ string, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.StorageV1().StorageClasses().Watch(ctx, opts)
+}
+
+// --- EndpointSliceClient (Namespaced) ---
+type EndpointSliceClient struct{}
+
+func (c *EndpointSliceClient) Get(ctx context.Context, clientset kubernetes.Interface, namespace, name string, opts metav1.GetOptions) (*discoveryv1.EndpointSlice, error) {
+	return clientset.DiscoveryV1().EndpointSlices(namespace).Get(ctx, name, opts)
+}
+func (c *EndpointSliceClient) List(ctx context.Context, clientset kubernetes.Interface, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+	return clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, opts)
+}
+func (c *EndpointSliceClient) Create(ctx context.Context, clientset kubernetes.Interface, namespace string, obj *discoveryv1.EndpointSlice, opts metav1.CreateOptions) (*discoveryv1.EndpointSlice, error) {
+	return clientset.DiscoveryV1().EndpointSlices(namespace).Create(ctx, obj, opts)
+}
+func (c *EndpointSliceClient) Update(ctx context.Context, clientset kubernetes.Interface, namespace string, obj *discoveryv1.EndpointSlice, opts metav1.UpdateOptions) (*discoveryv1.EndpointSlice, error) {
+	return clientset.DiscoveryV1().EndpointSlices(namespace).Update(ctx, obj, opts)
+}
+func (c *EndpointSliceClient) Delete(ctx context.Context, clientset kubernetes.Interface, namespace, name string, opts metav1.DeleteOptions) error {
+	return clientset.DiscoveryV1().EndpointSlices(namespace).Delete(ctx, name, opts)
+}
+func (c *EndpointSliceClient) Watch(ctx context.Context, clientset kubernetes.Interface, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return clientset.DiscoveryV1().EndpointSlices(namespace).Watch(ctx, opts)
+}
+
 // --- StatefulSetClient (Namespaced) ---
 type StatefulSetClient struct{}
 
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// ResourcePatchService changes namespaced objects with JSON, merge and strategic merge patches, which only
+// send the fields they change instead of replacing the whole object
+type ResourcePatchService struct{}
+
+// NewResourcePatchService creates a new ResourcePatchService instance
+func NewResourcePatchService() *ResourcePatchService {
+	return &ResourcePatchService{}
+}
+
+// Patch applies a patch of patchType to a namespaced object of resource, built-in or custom, and returns the
+// patched object. A JSON patch must be an array of operations, the merge patches an object. Strategic merge
+// patches need the Go type of the object, so custom resources only take the other two.
+func (s *ResourcePatchService) Patch(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, namespace, resource, name string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	if err := validatePatch(patchType, data); err != nil {
+		return nil, err
+	}
+	gvr, err := namespacedResource(mapper, resource)
+	if err != nil {
+		return nil, err
+	}
+	if patchType == types.StrategicMergePatchType {
+		gvk, err := mapper.KindFor(gvr)
+		if err != nil {
+			return nil, err
+		}
+		if !scheme.Scheme.Recognizes(gvk) {
+			return nil, fmt.Errorf("%w: strategic merge patch is not supported for %s, use a merge patch", ErrInvalidResource, gvr.GroupResource())
+		}
+	}
+	return client.Resource(gvr).Namespace(namespace).Patch(ctx, name, patchType, data, metav1.PatchOptions{})
+}
+
+// validatePatch checks that a patch is the JSON document its type expects
+func validatePatch(patchType types.PatchType, data []byte) error {
+	switch patchType {
+	case types.JSONPatchType:
+		var operations []map[string]interface{}
+		if err := json.Unmarshal(data, &operations); err != nil {
+			return fmt.Errorf("%w: a JSON patch must be an array of operations: %v", ErrInvalidResource, err)
+		}
+		if len(operations) == 0 {
+			return fmt.Errorf("%w: the JSON patch has no operations", ErrInvalidResource)
+		}
+	case types.MergePatchType, types.StrategicMergePatchType:
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+			return fmt.Errorf("%w: a merge patch must be a JSON object", ErrInvalidResource)
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("%w: the merge patch changes nothing", ErrInvalidResource)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported patch type %s", ErrInvalidResource, patchType)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestPatchEnv returns a dynamic client holding a two-container Deployment and a custom Widget
+func newTestPatchEnv(t *testing.T) (*dynamicfake.FakeDynamicClient, meta.RESTMapper) {
+	t.Helper()
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Group: "apps", Version: "v1"}, {Group: "example.com", Version: "v1"}})
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, meta.RESTScopeNamespace)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", Labels: map[string]string{"app": "web"}},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "web", Image: "web:1.2"},
+				{Name: "proxy", Image: "envoy:1.30"},
+			}}},
+		},
+	}
+	widget := &unstructured.Unstructured{}
+	widget.SetAPIVersion("example.com/v1")
+	widget.SetKind("Widget")
+	widget.SetNamespace("shop")
+	widget.SetName("gadget")
+	_ = unstructured.SetNestedField(widget.Object, int64(1), "spec", "size")
+
+	client := dynamicfake.NewSimpleDynamicClient(scheme, deployment, widget)
+	// The fake client can't merge strategically into unstructured objects, so Deployments are merged with
+	// their Go type like the API server does
+	deploymentsGVR := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.StrategicMergePatchType {
+			return false, nil, nil
+		}
+		current, err := client.Tracker().Get(deploymentsGVR, patch.GetNamespace(), patch.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		original, err := json.Marshal(current)
+		if err != nil {
+			return true, nil, err
+		}
+		merged, err := strategicpatch.StrategicMergePatch(original, patch.GetPatch(), &appsv1.Deployment{})
+		if err != nil {
+			return true, nil, err
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(merged); err != nil {
+			return true, nil, err
+		}
+		return true, obj, client.Tracker().Update(deploymentsGVR, obj, patch.GetNamespace())
+	})
+	return client, mapper
+}
+
+func containerImages(t *testing.T, obj *unstructured.Unstructured) map[string]string {
+	t.Helper()
+	containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	images := make(map[string]string, len(containers))
+	for _, container := range containers {
+		fields := container.(map[string]interface{})
+		images[fields["name"].(string)] = fields["image"].(string)
+	}
+	return images
+}
+
+func TestResourcePatchService_Patch(t *testing.T) {
+	ctx := context.Background()
+	svc := NewResourcePatchService()
+
+	t.Run("json patch", func(t *testing.T) {
+		client, mapper := newTestPatchEnv(t)
+		patched, err := svc.Patch(ctx, client, mapper, "shop", "deployments", "web", types.JSONPatchType,
+			[]byte(`[{"op":"test","path":"/metadata/labels/app","value":"web"},{"op":"replace","path":"/spec/template/spec/containers/1/image","value":"envoy:1.31"}]`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"web": "web:1.2", "proxy": "envoy:1.31"}, containerImages(t, patched))
+
+		_, err = svc.Patch(ctx, client, mapper, "shop", "deployments", "web", types.JSONPatchType,
+			[]byte(`[{"op":"test","path":"/metadata/labels/app","value":"api"}]`))
+		assert.Error(t, err, "a failed test operation rejects the patch")
+	})
+
+	t.Run("merge patch", func(t *testing.T) {
+		client, mapper := newTestPatchEnv(t)
+		patched, err := svc.Patch(ctx, client, mapper, "shop", "widgets", "gadget", types.MergePatchType, []byte(`{"spec":{"color":"red"}}`))
+		require.NoError(t, err)
+		size, _, _ := unstructured.NestedInt64(patched.Object, "spec", "size")
+		color, _, _ := unstructured.NestedString(patched.Object, "spec", "color")
+		assert.EqualValues(t, 1, size, "fields left out of a merge patch are kept")
+		assert.Equal(t, "red", color)
+
+		patched, err = svc.Patch(ctx, client, mapper, "shop", "deployments", "web", types.MergePatchType,
+			[]byte(`{"spec":{"template":{"spec":{"containers":[{"name":"web","image":"web:1.3"}]}}}}`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"web": "web:1.3"}, containerImages(t, patched), "a merge patch replaces lists")
+	})
+
+	t.Run("strategic merge patch", func(t *testing.T) {
+		client, mapper := newTestPatchEnv(t)
+		patched, err := svc.Patch(ctx, client, mapper, "shop", "deployments", "web", types.StrategicMergePatchType,
+			[]byte(`{"spec":{"template":{"spec":{"containers":[{"name":"web","image":"web:1.3"}]}}}}`))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"web": "web:1.3", "proxy": "envoy:1.30"}, containerImages(t, patched), "containers are merged by name")
+
+		_, err = svc.Patch(ctx, client, mapper, "shop", "widgets", "gadget", types.StrategicMergePatchType, []byte(`{"spec":{"size":2}}`))
+		assert.ErrorIs(t, err, ErrInvalidResource, "custom resources have no strategic merge patch")
+	})
+}
+
+func TestResourcePatchService_InvalidPatches(t *testing.T) {
+	client, mapper := newTestPatchEnv(t)
+	svc := NewResourcePatchService()
+
+	for name, tt := range map[string]struct {
+		patchType types.PatchType
+		data      string
+	}{
+		"json patch object":      {types.JSONPatchType, `{"op":"remove","path":"/spec"}`},
+		"empty json patch":       {types.JSONPatchType, `[]`},
+		"merge patch array":      {types.MergePatchType, `[{"op":"remove","path":"/spec"}]`},
+		"empty merge patch":      {types.MergePatchType, `{}`},
+		"strategic patch string": {types.StrategicMergePatchType, `"spec"`},
+		"not json":               {types.MergePatchType, `spec: {}`},
+		"apply patch":            {types.ApplyPatchType, `{}`},
+	} {
+		_, err := svc.Patch(context.Background(), client, mapper, "shop", "deployments", "web", tt.patchType, []byte(tt.data))
+		assert.ErrorIs(t, err, ErrInvalidResource, name)
+	}
+	assert.Empty(t, client.Actions(), "invalid patches are not sent")
+}
@@ -5,7 +5,9 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
 )
 
 // ResourceServiceFactory resource service factory
@@ -47,6 +49,8 @@ func (f *ResourceServiceFactory) InitializeDefaultServices() {
 	f.RegisterService("secrets", NewBaseResourceService[*corev1.Secret](new(SecretClient)))
 	f.RegisterService("persistentvolumeclaims", NewBaseResourceService[*corev1.PersistentVolumeClaim](new(PVCClient)))
 	f.RegisterService("persistentvolumes", NewBaseResourceService[*corev1.PersistentVolume](new(PVClient)))
+	f.RegisterService("storageclasses", NewBaseResourceService[*storagev1.StorageClass](new(StorageClassClient)))
+	f.RegisterService("endpointslices", NewBaseResourceService[*discoveryv1.EndpointSlice](new(EndpointSliceClient)))
 	f.RegisterService("statefulsets", NewBaseResourceService[*appsv1.StatefulSet](new(StatefulSetClient)))
 	f.RegisterService("namespaces", NewBaseResourceService[*corev1.Namespace](new(NamespaceClient)))
 }
@@ -1,14 +1,21 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"time"
+	"sort"
 
 	"github.com/ciliverse/cilikube/internal/models"
 	"github.com/ciliverse/cilikube/internal/store"
 )
 
+// ErrRoleNotFound is returned when no role has the requested ID
+var ErrRoleNotFound = errors.New("role not found")
+
+// maxMainPermissions is how many of a role's permissions are listed in role responses
+const maxMainPermissions = 3
+
 // RoleService provides role management functionality
 type RoleService struct {
 	store             store.Store
@@ -28,7 +35,7 @@ func (s *RoleService) SetPermissionService(permissionService *PermissionService)
 }
 
 // CreateRole creates a new role
-func (s *RoleService) CreateRole(req *models.CreateRoleRequest) (*models.RoleResponse, error) {
+func (s *RoleService) CreateRole(ctx context.Context, req *models.CreateRoleRequest) (*models.RoleResponse, error) {
 	// Check if role name already exists
 	_, err := s.store.GetRoleByName(req.Name)
 	if err == nil {
@@ -48,7 +55,7 @@ func (s *RoleService) CreateRole(req *models.CreateRoleRequest) (*models.RoleRes
 	}
 
 	// Create audit log
-	s.createAuditLog(nil, "role_create", "role", fmt.Sprintf("%d", role.ID), "", "", fmt.Sprintf("Role '%s' created", role.Name))
+	s.createAuditLog(ctx, nil, "role_create", "role", fmt.Sprintf("%d", role.ID), fmt.Sprintf("Role '%s' created", role.Name))
 
 	// Convert to response
 	response := s.convertStoreRoleToResponse(role)
@@ -56,11 +63,11 @@ func (s *RoleService) CreateRole(req *models.CreateRoleRequest) (*models.RoleRes
 }
 
 // UpdateRole updates an existing role
-func (s *RoleService) UpdateRole(roleID uint, req *models.UpdateRoleRequest) (*models.RoleResponse, error) {
+func (s *RoleService) UpdateRole(ctx context.Context, roleID uint, req *models.UpdateRoleRequest) (*models.RoleResponse, error) {
 	// Get existing role
 	role, err := s.store.GetRoleByID(roleID)
 	if err != nil {
-		return nil, errors.New("role not found")
+		return nil, ErrRoleNotFound
 	}
 
 	// Check if it's a system role (system roles cannot be modified)
@@ -77,7 +84,7 @@ func (s *RoleService) UpdateRole(roleID uint, req *models.UpdateRoleRequest) (*m
 	}
 
 	// Create audit log
-	s.createAuditLog(nil, "role_update", "role", fmt.Sprintf("%d", role.ID), "", "", fmt.Sprintf("Role '%s' updated", role.Name))
+	s.createAuditLog(ctx, nil, "role_update", "role", fmt.Sprintf("%d", role.ID), fmt.Sprintf("Role '%s' updated", role.Name))
 
 	// Convert to response
 	response := s.convertStoreRoleToResponse(role)
@@ -85,11 +92,11 @@ func (s *RoleService) UpdateRole(roleID uint, req *models.UpdateRoleRequest) (*m
 }
 
 // DeleteRole deletes a role
-func (s *RoleService) DeleteRole(roleID uint) error {
+func (s *RoleService) DeleteRole(ctx context.Context, roleID uint) error {
 	// Get existing role
 	role, err := s.store.GetRoleByID(roleID)
 	if err != nil {
-		return errors.New("role not found")
+		return ErrRoleNotFound
 	}
 
 	// Check if it's a system role (system roles cannot be deleted)
@@ -113,7 +120,7 @@ func (s *RoleService) DeleteRole(roleID uint) error {
 	}
 
 	// Create audit log
-	s.createAuditLog(nil, "role_delete", "role", fmt.Sprintf("%d", roleID), "", "", fmt.Sprintf("Role '%s' deleted", role.Name))
+	s.createAuditLog(ctx, nil, "role_delete", "role", fmt.Sprintf("%d", roleID), fmt.Sprintf("Role '%s' deleted", role.Name))
 
 	return nil
 }
@@ -122,7 +129,7 @@ func (s *RoleService) DeleteRole(roleID uint) error {
 func (s *RoleService) GetRole(roleID uint) (*models.RoleResponse, error) {
 	role, err := s.store.GetRoleByID(roleID)
 	if err != nil {
-		return nil, errors.New("role not found")
+		return nil, ErrRoleNotFound
 	}
 
 	// Get user count for this role
@@ -140,7 +147,7 @@ func (s *RoleService) GetRole(roleID uint) (*models.RoleResponse, error) {
 func (s *RoleService) GetRoleByName(name string) (*models.RoleResponse, error) {
 	role, err := s.store.GetRoleByName(name)
 	if err != nil {
-		return nil, errors.New("role not found")
+		return nil, ErrRoleNotFound
 	}
 
 	// Get user count for this role
@@ -177,7 +184,7 @@ func (s *RoleService) ListRoles() ([]models.RoleResponse, error) {
 }
 
 // AssignRoleToUser assigns a role to a user
-func (s *RoleService) AssignRoleToUser(userID, roleID uint, assignedBy uint) error {
+func (s *RoleService) AssignRoleToUser(ctx context.Context, userID, roleID uint, assignedBy uint) error {
 	// Check if user exists
 	_, err := s.store.GetUserByID(userID)
 	if err != nil {
@@ -187,41 +194,108 @@ func (s *RoleService) AssignRoleToUser(userID, roleID uint, assignedBy uint) err
 	// Check if role exists
 	role, err := s.store.GetRoleByID(roleID)
 	if err != nil {
-		return errors.New("role not found")
+		return ErrRoleNotFound
 	}
 
+	_, err = s.assignRole(ctx, userID, role, assignedBy)
+	return err
+}
+
+// assignRole assigns an existing role to an existing user, syncing Casbin and writing the audit log.
+// It reports false when the user already had the role.
+func (s *RoleService) assignRole(ctx context.Context, userID uint, role *store.Role, assignedBy uint) (bool, error) {
 	// Check if user already has this role
-	hasRole, err := s.store.HasRole(userID, roleID)
+	hasRole, err := s.store.HasRole(userID, role.ID)
 	if err != nil {
-		return fmt.Errorf("failed to check existing role: %w", err)
+		return false, fmt.Errorf("failed to check existing role: %w", err)
 	}
 
 	if hasRole {
-		return nil // Already assigned, no error
+		return false, nil // Already assigned, no error
 	}
 
 	// Assign role
-	if err := s.store.AssignRole(userID, roleID); err != nil {
-		return fmt.Errorf("failed to assign role: %w", err)
+	if err := s.store.AssignRoleBy(userID, role.ID, assignedBy); err != nil {
+		return false, fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	s.syncUserRoles(userID)
+
+	// Create audit log
+	s.createAuditLog(ctx, &assignedBy, "role_assign", "user_role", fmt.Sprintf("%d_%d", userID, role.ID), fmt.Sprintf("Role '%s' assigned to user %d", role.Name, userID))
+
+	return true, nil
+}
+
+// AssignRoleToUsers assigns a role to each of the users. Failures, such as users that don't exist,
+// are reported per user without stopping the others.
+func (s *RoleService) AssignRoleToUsers(ctx context.Context, roleID uint, userIDs []uint, assignedBy uint) (*models.RoleUsersResponse, error) {
+	return s.changeRoleUsers(roleID, userIDs, func(userID uint, role *store.Role) (string, error) {
+		assigned, err := s.assignRole(ctx, userID, role, assignedBy)
+		if err != nil || !assigned {
+			return models.RoleUserUnchanged, err
+		}
+		return models.RoleUserAssigned, nil
+	})
+}
+
+// RemoveRoleFromUsers removes a role from each of the users, reporting the outcome per user
+func (s *RoleService) RemoveRoleFromUsers(ctx context.Context, roleID uint, userIDs []uint, removedBy uint) (*models.RoleUsersResponse, error) {
+	return s.changeRoleUsers(roleID, userIDs, func(userID uint, role *store.Role) (string, error) {
+		removed, err := s.removeRole(ctx, userID, role, removedBy)
+		if err != nil || !removed {
+			return models.RoleUserUnchanged, err
+		}
+		return models.RoleUserRemoved, nil
+	})
+}
+
+// changeRoleUsers applies change to each existing user once and collects the results
+func (s *RoleService) changeRoleUsers(roleID uint, userIDs []uint, change func(userID uint, role *store.Role) (string, error)) (*models.RoleUsersResponse, error) {
+	role, err := s.store.GetRoleByID(roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	response := &models.RoleUsersResponse{RoleID: roleID, Results: make([]models.RoleUserResult, 0, len(userIDs))}
+	seen := make(map[uint]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		result := models.RoleUserResult{UserID: userID}
+		if _, err := s.store.GetUserByID(userID); err != nil {
+			result.Status, result.Error = models.RoleUserFailed, ErrUserNotFound.Error()
+		} else if status, err := change(userID, role); err != nil {
+			result.Status, result.Error = models.RoleUserFailed, err.Error()
+		} else {
+			result.Status = status
+		}
+
+		if result.Status == models.RoleUserFailed {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, result)
 	}
+	return response, nil
+}
 
-	// Sync user roles with Casbin if permission service is available
+// syncUserRoles syncs the roles of a user with Casbin if the permission service is available
+func (s *RoleService) syncUserRoles(userID uint) {
 	if s.permissionService != nil {
 		if err := s.permissionService.SyncUserRoles(userID); err != nil {
 			// Log error but don't fail the operation
 			fmt.Printf("Failed to sync user roles with Casbin: %v\n", err)
 		}
 	}
-
-	// Create audit log
-	s.createAuditLog(&assignedBy, "role_assign", "user_role", fmt.Sprintf("%d_%d", userID, roleID), "", "",
-		fmt.Sprintf("Role '%s' assigned to user %d", role.Name, userID))
-
-	return nil
 }
 
 // RemoveRoleFromUser removes a role from a user
-func (s *RoleService) RemoveRoleFromUser(userID, roleID uint, removedBy uint) error {
+func (s *RoleService) RemoveRoleFromUser(ctx context.Context, userID, roleID uint, removedBy uint) error {
 	// Check if user exists
 	_, err := s.store.GetUserByID(userID)
 	if err != nil {
@@ -231,41 +305,47 @@ func (s *RoleService) RemoveRoleFromUser(userID, roleID uint, removedBy uint) er
 	// Check if role exists
 	role, err := s.store.GetRoleByID(roleID)
 	if err != nil {
-		return errors.New("role not found")
+		return ErrRoleNotFound
+	}
+
+	removed, err := s.removeRole(ctx, userID, role, removedBy)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errors.New("user does not have this role")
 	}
+	return nil
+}
 
+// removeRole removes a role from an existing user, syncing Casbin and writing the audit log.
+// It reports false when the user did not have the role.
+func (s *RoleService) removeRole(ctx context.Context, userID uint, role *store.Role, removedBy uint) (bool, error) {
 	// Check if user has this role
-	hasRole, err := s.store.HasRole(userID, roleID)
+	hasRole, err := s.store.HasRole(userID, role.ID)
 	if err != nil {
-		return fmt.Errorf("failed to check existing role: %w", err)
+		return false, fmt.Errorf("failed to check existing role: %w", err)
 	}
 
 	if !hasRole {
-		return errors.New("user does not have this role")
+		return false, nil
 	}
 
 	// Remove role
-	if err := s.store.RemoveRole(userID, roleID); err != nil {
-		return fmt.Errorf("failed to remove role: %w", err)
+	if err := s.store.RemoveRole(userID, role.ID); err != nil {
+		return false, fmt.Errorf("failed to remove role: %w", err)
 	}
 
-	// Sync user roles with Casbin if permission service is available
-	if s.permissionService != nil {
-		if err := s.permissionService.SyncUserRoles(userID); err != nil {
-			// Log error but don't fail the operation
-			fmt.Printf("Failed to sync user roles with Casbin: %v\n", err)
-		}
-	}
+	s.syncUserRoles(userID)
 
 	// Create audit log
-	s.createAuditLog(&removedBy, "role_remove", "user_role", fmt.Sprintf("%d_%d", userID, roleID), "", "",
-		fmt.Sprintf("Role '%s' removed from user %d", role.Name, userID))
+	s.createAuditLog(ctx, &removedBy, "role_remove", "user_role", fmt.Sprintf("%d_%d", userID, role.ID), fmt.Sprintf("Role '%s' removed from user %d", role.Name, userID))
 
-	return nil
+	return true, nil
 }
 
 // AssignRolesToUser assigns multiple roles to a user (replaces existing roles)
-func (s *RoleService) AssignRolesToUser(userID uint, roleIDs []uint, assignedBy uint) error {
+func (s *RoleService) AssignRolesToUser(ctx context.Context, userID uint, roleIDs []uint, assignedBy uint) error {
 	// Check if user exists
 	_, err := s.store.GetUserByID(userID)
 	if err != nil {
@@ -297,44 +377,54 @@ func (s *RoleService) AssignRolesToUser(userID uint, roleIDs []uint, assignedBy
 		newRoleIDs[roleID] = true
 	}
 
-	// Remove roles that are no longer assigned
-	for _, role := range currentRoles {
-		if !newRoleIDs[role.ID] {
-			if err := s.store.RemoveRole(userID, role.ID); err != nil {
-				return fmt.Errorf("failed to remove role %d: %w", role.ID, err)
+	// Replace the roles in one transaction so the user never ends up with only part of the change
+	var removed []*store.Role
+	var added []uint
+	err = s.store.Transaction(func(tx store.Store) error {
+		// Start over if the store retries the transaction
+		removed, added = nil, nil
+
+		// Remove roles that are no longer assigned
+		for _, role := range currentRoles {
+			if !newRoleIDs[role.ID] {
+				if err := tx.RemoveRole(userID, role.ID); err != nil {
+					return fmt.Errorf("failed to remove role %d: %w", role.ID, err)
+				}
+				removed = append(removed, role)
 			}
-			// Create audit log for removal
-			s.createAuditLog(&assignedBy, "role_remove", "user_role", fmt.Sprintf("%d_%d", userID, role.ID), "", "",
-				fmt.Sprintf("Role '%s' removed from user %d", role.Name, userID))
 		}
-	}
 
-	// Add new roles
-	for _, roleID := range roleIDs {
-		if !currentRoleIDs[roleID] {
-			if err := s.store.AssignRole(userID, roleID); err != nil {
-				return fmt.Errorf("failed to assign role %d: %w", roleID, err)
-			}
-			// Get role name for audit log
-			role, _ := s.store.GetRoleByID(roleID)
-			roleName := fmt.Sprintf("%d", roleID)
-			if role != nil {
-				roleName = role.Name
+		// Add new roles
+		for _, roleID := range roleIDs {
+			if !currentRoleIDs[roleID] {
+				if err := tx.AssignRoleBy(userID, roleID, assignedBy); err != nil {
+					return fmt.Errorf("failed to assign role %d: %w", roleID, err)
+				}
+				added = append(added, roleID)
 			}
-			// Create audit log for assignment
-			s.createAuditLog(&assignedBy, "role_assign", "user_role", fmt.Sprintf("%d_%d", userID, roleID), "", "",
-				fmt.Sprintf("Role '%s' assigned to user %d", roleName, userID))
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Sync user roles with Casbin if permission service is available
-	if s.permissionService != nil {
-		if err := s.permissionService.SyncUserRoles(userID); err != nil {
-			// Log error but don't fail the operation
-			fmt.Printf("Failed to sync user roles with Casbin: %v\n", err)
+	// Create audit logs once the change is committed
+	for _, role := range removed {
+		s.createAuditLog(ctx, &assignedBy, "role_remove", "user_role", fmt.Sprintf("%d_%d", userID, role.ID), fmt.Sprintf("Role '%s' removed from user %d", role.Name, userID))
+	}
+	for _, roleID := range added {
+		// Get role name for audit log
+		role, _ := s.store.GetRoleByID(roleID)
+		roleName := fmt.Sprintf("%d", roleID)
+		if role != nil {
+			roleName = role.Name
 		}
+		s.createAuditLog(ctx, &assignedBy, "role_assign", "user_role", fmt.Sprintf("%d_%d", userID, roleID), fmt.Sprintf("Role '%s' assigned to user %d", roleName, userID))
 	}
 
+	s.syncUserRoles(userID)
+
 	return nil
 }
 
@@ -359,12 +449,41 @@ func (s *RoleService) GetUserRoles(userID uint) ([]models.RoleResponse, error) {
 	return responses, nil
 }
 
+// UserPermissions returns the effective permissions of a user, the permissions of all of its roles
+// as sorted "ACTION object" strings
+func (s *RoleService) UserPermissions(userID uint) ([]string, error) {
+	roles, err := s.store.GetUserRoles(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	permissions := []string{}
+	if s.permissionService == nil {
+		return permissions, nil
+	}
+	seen := make(map[string]bool)
+	for _, role := range roles {
+		rolePermissions, err := s.permissionService.RolePermissions(role.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get permissions of role %s: %w", role.Name, err)
+		}
+		for _, permission := range rolePermissions {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions, nil
+}
+
 // GetRoleUsers gets all users assigned to a role
 func (s *RoleService) GetRoleUsers(roleID uint) ([]models.UserRoleResponse, error) {
 	// Check if role exists
 	_, err := s.store.GetRoleByID(roleID)
 	if err != nil {
-		return nil, errors.New("role not found")
+		return nil, ErrRoleNotFound
 	}
 
 	users, err := s.store.GetRoleUsers(roleID)
@@ -372,6 +491,15 @@ func (s *RoleService) GetRoleUsers(roleID uint) ([]models.UserRoleResponse, erro
 		return nil, fmt.Errorf("failed to get role users: %w", err)
 	}
 
+	assignments, err := s.store.GetRoleAssignments(roleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role assignments: %w", err)
+	}
+	assignmentsByUser := make(map[uint]*store.UserRole, len(assignments))
+	for _, assignment := range assignments {
+		assignmentsByUser[assignment.UserID] = assignment
+	}
+
 	responses := make([]models.UserRoleResponse, len(users))
 	for i, user := range users {
 		// Get all roles for this user
@@ -386,10 +514,13 @@ func (s *RoleService) GetRoleUsers(roleID uint) ([]models.UserRoleResponse, erro
 		}
 
 		responses[i] = models.UserRoleResponse{
-			UserID:     user.ID,
-			Username:   user.Username,
-			Roles:      roleResponses,
-			AssignedAt: time.Now(), // TODO: Get actual assignment time from UserRole table
+			UserID:   user.ID,
+			Username: user.Username,
+			Roles:    roleResponses,
+		}
+		if assignment, ok := assignmentsByUser[user.ID]; ok {
+			responses[i].AssignedAt = assignment.AssignedAt
+			responses[i].AssignedBy = assignment.AssignedBy
 		}
 	}
 
@@ -434,8 +565,7 @@ func (s *RoleService) InitializeDefaultRoles() error {
 		}
 
 		// Create audit log
-		s.createAuditLog(nil, "role_create", "role", fmt.Sprintf("%d", role.ID), "", "",
-			fmt.Sprintf("Default system role '%s' initialized", role.Name))
+		s.createAuditLog(context.Background(), nil, "role_create", "role", fmt.Sprintf("%d", role.ID), fmt.Sprintf("Default system role '%s' initialized", role.Name))
 	}
 
 	return nil
@@ -451,16 +581,17 @@ func (s *RoleService) convertStoreRoleToResponse(role *store.Role) models.RoleRe
 		roleType = "system"
 	}
 
-	// Mock main permissions for now - in a real implementation,
-	// you would fetch these from the database
-	mainPermissions := []string{}
-	if role.Name == "admin" {
-		mainPermissions = []string{"admin:users", "admin:roles", "admin:system"}
-	} else if role.Name == "editor" {
-		mainPermissions = []string{"read:clusters", "write:pods", "write:deployments"}
-	} else if role.Name == "viewer" {
-		mainPermissions = []string{"read:clusters", "read:pods", "read:deployments"}
+	// The first policies of the role stand for it, the count covers all of them
+	permissions := []string{}
+	if s.permissionService != nil {
+		rolePermissions, err := s.permissionService.RolePermissions(role.Name)
+		if err != nil {
+			fmt.Printf("Failed to get permissions of role %s: %v\n", role.Name, err)
+		} else {
+			permissions = rolePermissions
+		}
 	}
+	mainPermissions := permissions[:min(len(permissions), maxMainPermissions)]
 
 	return models.RoleResponse{
 		ID:              role.ID,
@@ -472,21 +603,13 @@ func (s *RoleService) convertStoreRoleToResponse(role *store.Role) models.RoleRe
 		CreatedAt:       role.CreatedAt,
 		UpdatedAt:       role.UpdatedAt,
 		MainPermissions: mainPermissions,
-		PermissionCount: len(mainPermissions),
+		PermissionCount: len(permissions),
 	}
 }
 
-// createAuditLog creates an audit log entry
-func (s *RoleService) createAuditLog(userID *uint, action, resource, resourceID, ipAddress, userAgent, details string) {
-	auditLog := &store.AuditLog{
-		UserID:     userID,
-		Action:     action,
-		Resource:   resource,
-		ResourceID: resourceID,
-		IPAddress:  ipAddress,
-		UserAgent:  userAgent,
-		Details:    details,
-	}
+// createAuditLog creates an audit log entry for the request in ctx
+func (s *RoleService) createAuditLog(ctx context.Context, userID *uint, action, resource, resourceID, details string) {
+	auditLog := newAuditLog(ctx, userID, action, resource, resourceID, details)
 
 	// Don't fail the main operation if audit logging fails
 	if err := s.store.CreateAuditLog(auditLog); err != nil {
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRoleService returns a role service over a memory store holding a "developer" role and the given users
+func newTestRoleService(t *testing.T, usernames ...string) (*RoleService, store.Store, *store.Role, []uint) {
+	t.Helper()
+	memoryStore := store.NewMemoryStore()
+	data := store.SeedData{Roles: []store.SeedRole{{Name: "developer", DisplayName: "Developer"}}}
+	for _, username := range usernames {
+		data.Users = append(data.Users, store.SeedUser{Username: username, Email: username + "@example.com"})
+	}
+	require.NoError(t, store.Seed(memoryStore, data))
+
+	role, err := memoryStore.GetRoleByName("developer")
+	require.NoError(t, err)
+	var ids []uint
+	for _, username := range usernames {
+		user, err := memoryStore.GetUserByUsername(username)
+		require.NoError(t, err)
+		ids = append(ids, user.ID)
+	}
+	return NewRoleService(memoryStore), memoryStore, role, ids
+}
+
+func TestRoleService_AssignRoleToUsers(t *testing.T) {
+	svc, memoryStore, role, ids := newTestRoleService(t, "alice", "bob", "carol")
+	require.NoError(t, memoryStore.AssignRole(ids[2], role.ID))
+
+	const missing = 9999
+	result, err := svc.AssignRoleToUsers(context.Background(), role.ID, []uint{ids[0], missing, ids[1], ids[2], ids[0]}, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, []models.RoleUserResult{
+		{UserID: ids[0], Status: models.RoleUserAssigned},
+		{UserID: missing, Status: models.RoleUserFailed, Error: "user not found"},
+		{UserID: ids[1], Status: models.RoleUserAssigned},
+		{UserID: ids[2], Status: models.RoleUserUnchanged},
+	}, result.Results, "duplicates are applied once and existing assignments are unchanged")
+
+	for _, id := range ids {
+		hasRole, err := memoryStore.HasRole(id, role.ID)
+		require.NoError(t, err)
+		assert.True(t, hasRole)
+	}
+
+	logs, _, err := memoryStore.ListAuditLogs(0, 100)
+	require.NoError(t, err)
+	var assignments int
+	for _, log := range logs {
+		if log.Action == "role_assign" {
+			assignments++
+		}
+	}
+	assert.Equal(t, 2, assignments, "one audit log per new assignment")
+}
+
+func TestRoleService_RemoveRoleFromUsers(t *testing.T) {
+	svc, memoryStore, role, ids := newTestRoleService(t, "alice", "bob")
+	require.NoError(t, memoryStore.AssignRole(ids[0], role.ID))
+
+	result, err := svc.RemoveRoleFromUsers(context.Background(), role.ID, []uint{ids[0], ids[1], 9999}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, models.RoleUserRemoved, result.Results[0].Status)
+	assert.Equal(t, models.RoleUserUnchanged, result.Results[1].Status)
+	assert.Equal(t, models.RoleUserFailed, result.Results[2].Status)
+
+	hasRole, err := memoryStore.HasRole(ids[0], role.ID)
+	require.NoError(t, err)
+	assert.False(t, hasRole)
+}
+
+func TestRoleService_BulkUnknownRole(t *testing.T) {
+	svc, _, _, ids := newTestRoleService(t, "alice")
+	_, err := svc.AssignRoleToUsers(context.Background(), 404, ids, 1)
+	assert.ErrorIs(t, err, ErrRoleNotFound)
+}
+
+func TestRoleService_PermissionsFromPolicies(t *testing.T) {
+	svc, memoryStore, _, _ := newTestRoleService(t)
+	permissionService := newTestPermissionService(t)
+	svc.SetPermissionService(permissionService)
+
+	auditor := &store.Role{Name: "auditor", DisplayName: "Auditor"}
+	require.NoError(t, memoryStore.CreateRole(auditor))
+	require.NoError(t, permissionService.AddRolePolicy("auditor", "/api/v1/audit/*", "GET"))
+	require.NoError(t, permissionService.AddRolePolicy("auditor", "/api/v1/events/*", "GET"))
+	require.NoError(t, permissionService.AddRolePolicy("auditor", "/api/v1/namespaces/*", "GET"))
+	require.NoError(t, permissionService.AddRolePolicy("auditor", "/api/v1/nodes/*", "GET"))
+	require.NoError(t, permissionService.AddRolePolicy("auditor", "/api/v1/summary/*", "GET"))
+
+	role, err := svc.GetRole(auditor.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "custom", role.Type)
+	assert.Equal(t, 5, role.PermissionCount)
+	assert.Equal(t, []string{"GET /api/v1/audit/*", "GET /api/v1/events/*", "GET /api/v1/namespaces/*"}, role.MainPermissions)
+
+	require.NoError(t, permissionService.RemoveRolePolicy("auditor", "/api/v1/audit/*", "GET"))
+	roles, err := svc.ListRoles()
+	require.NoError(t, err)
+	for _, listed := range roles {
+		switch listed.Name {
+		case "auditor":
+			assert.Equal(t, 4, listed.PermissionCount, "counts follow policy changes")
+		case "developer":
+			assert.Zero(t, listed.PermissionCount)
+			assert.Empty(t, listed.MainPermissions)
+		}
+	}
+}
+
+func TestRoleService_GetRoleUsersAssignedAt(t *testing.T) {
+	svc, _, role, ids := newTestRoleService(t, "alice", "bob")
+
+	before := time.Now()
+	require.NoError(t, svc.AssignRoleToUser(context.Background(), ids[0], role.ID, 7))
+	after := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, svc.AssignRoleToUser(context.Background(), ids[1], role.ID, 8))
+
+	users, err := svc.GetRoleUsers(role.ID)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	byUser := make(map[uint]models.UserRoleResponse)
+	for _, user := range users {
+		byUser[user.UserID] = user
+	}
+
+	alice := byUser[ids[0]]
+	assert.WithinRange(t, alice.AssignedAt, before, after)
+	assert.Equal(t, uint(7), alice.AssignedBy)
+
+	// Reading again returns the stored time rather than the current one
+	users, err = svc.GetRoleUsers(role.ID)
+	require.NoError(t, err)
+	for _, user := range users {
+		if user.UserID == ids[0] {
+			assert.True(t, alice.AssignedAt.Equal(user.AssignedAt))
+		}
+	}
+	assert.True(t, byUser[ids[1]].AssignedAt.After(alice.AssignedAt))
+	assert.Equal(t, uint(8), byUser[ids[1]].AssignedBy)
+}
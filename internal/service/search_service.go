@@ -0,0 +1,185 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	// searchResultCap limits how many matches a single search returns
+	searchResultCap = 500
+	// defaultSearchPageSize is used when the caller does not specify a page size
+	defaultSearchPageSize = 20
+	// maxSearchPageSize is the largest page size a caller may request
+	maxSearchPageSize = 100
+)
+
+// SearchableKinds lists the kinds supported by the search endpoint
+var SearchableKinds = []string{"pod", "deployment", "service"}
+
+// kindResources maps searchable kinds to their resource plural for permission checks
+var kindResources = map[string]string{
+	"pod":        "pods",
+	"deployment": "deployments",
+	"service":    "services",
+}
+
+// NamespaceFilter reports whether objects of the given resource in a namespace may be returned.
+// A nil filter allows every namespace.
+type NamespaceFilter func(namespace, resource string) bool
+
+// SearchListers groups the listers used to answer search queries
+type SearchListers struct {
+	Pods        corelisters.PodLister
+	Deployments appslisters.DeploymentLister
+	Services    corelisters.ServiceLister
+}
+
+// SearchService provides name search across namespaces and kinds using informer caches
+type SearchService struct {
+	informers *k8s.InformerRegistry
+}
+
+// NewSearchService creates a new SearchService instance
+func NewSearchService(informers *k8s.InformerRegistry) *SearchService {
+	return &SearchService{
+		informers: informers,
+	}
+}
+
+// ListersFor returns the listers of a cluster, starting and syncing its informers on first use
+func (s *SearchService) ListersFor(clusterID string, clientset kubernetes.Interface) (*SearchListers, error) {
+	var listers *SearchListers
+	err := s.informers.Sync(clusterID, clientset, func(factory informers.SharedInformerFactory) {
+		listers = &SearchListers{
+			Pods:        factory.Core().V1().Pods().Lister(),
+			Deployments: factory.Apps().V1().Deployments().Lister(),
+			Services:    factory.Core().V1().Services().Lister(),
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listers, nil
+}
+
+// Search matches object names against the query across the requested kinds
+func (s *SearchService) Search(listers *SearchListers, req models.SearchRequest, allowed NamespaceFilter) (*models.SearchResponse, error) {
+	query := strings.ToLower(strings.TrimSpace(req.Query))
+	if query == "" {
+		return nil, fmt.Errorf("search query must not be empty")
+	}
+
+	kinds := req.Kinds
+	if len(kinds) == 0 {
+		kinds = SearchableKinds
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultSearchPageSize
+	}
+	if pageSize > maxSearchPageSize {
+		pageSize = maxSearchPageSize
+	}
+
+	now := time.Now()
+	results := make([]models.SearchResult, 0)
+
+	// collect appends a match
+	collect := func(kind, namespace, name string, created time.Time) {
+		if !strings.Contains(strings.ToLower(name), query) {
+			return
+		}
+		if allowed != nil && !allowed(namespace, kindResources[kind]) {
+			return
+		}
+		results = append(results, models.SearchResult{
+			Kind:      kind,
+			Namespace: namespace,
+			Name:      name,
+			Age:       duration.HumanDuration(now.Sub(created)),
+			CreatedAt: created,
+		})
+	}
+
+	for _, kind := range kinds {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		switch kind {
+		case "pod":
+			pods, err := listers.Pods.List(labels.Everything())
+			if err != nil {
+				return nil, fmt.Errorf("failed to list pods: %w", err)
+			}
+			for _, pod := range pods {
+				collect(kind, pod.Namespace, pod.Name, pod.CreationTimestamp.Time)
+			}
+		case "deployment":
+			deployments, err := listers.Deployments.List(labels.Everything())
+			if err != nil {
+				return nil, fmt.Errorf("failed to list deployments: %w", err)
+			}
+			for _, deployment := range deployments {
+				collect(kind, deployment.Namespace, deployment.Name, deployment.CreationTimestamp.Time)
+			}
+		case "service":
+			services, err := listers.Services.List(labels.Everything())
+			if err != nil {
+				return nil, fmt.Errorf("failed to list services: %w", err)
+			}
+			for _, svc := range services {
+				collect(kind, svc.Namespace, svc.Name, svc.CreationTimestamp.Time)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported kind '%s', supported kinds: %s", kind, strings.Join(SearchableKinds, ","))
+		}
+	}
+
+	// Sort for stable pagination, and so the cap always keeps the same matches: kind, namespace, then name
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Kind != results[j].Kind {
+			return results[i].Kind < results[j].Kind
+		}
+		if results[i].Namespace != results[j].Namespace {
+			return results[i].Namespace < results[j].Namespace
+		}
+		return results[i].Name < results[j].Name
+	})
+	truncated := len(results) > searchResultCap
+	if truncated {
+		results = results[:searchResultCap]
+	}
+
+	total := len(results)
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	return &models.SearchResponse{
+		Items:     results[start:end],
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+		Truncated: truncated,
+	}, nil
+}
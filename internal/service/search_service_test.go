@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestIndexer() cache.Indexer {
+	return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+}
+
+func testObjectMeta(namespace, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace:         namespace,
+		Name:              name,
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-5 * time.Hour)),
+	}
+}
+
+func setupTestSearchListers(t *testing.T) *SearchListers {
+	pods := newTestIndexer()
+	deployments := newTestIndexer()
+	services := newTestIndexer()
+
+	require.NoError(t, pods.Add(&corev1.Pod{ObjectMeta: testObjectMeta("default", "web-7d9f-abc")}))
+	require.NoError(t, pods.Add(&corev1.Pod{ObjectMeta: testObjectMeta("team-a", "web-5c4b-xyz")}))
+	require.NoError(t, pods.Add(&corev1.Pod{ObjectMeta: testObjectMeta("default", "db-0")}))
+	require.NoError(t, deployments.Add(&appsv1.Deployment{ObjectMeta: testObjectMeta("default", "web")}))
+	require.NoError(t, deployments.Add(&appsv1.Deployment{ObjectMeta: testObjectMeta("default", "cache")}))
+	require.NoError(t, services.Add(&corev1.Service{ObjectMeta: testObjectMeta("team-a", "WEB-svc")}))
+	require.NoError(t, services.Add(&corev1.Service{ObjectMeta: testObjectMeta("kube-system", "kube-dns")}))
+
+	return &SearchListers{
+		Pods:        corelisters.NewPodLister(pods),
+		Deployments: appslisters.NewDeploymentLister(deployments),
+		Services:    corelisters.NewServiceLister(services),
+	}
+}
+
+func TestSearchService_Search(t *testing.T) {
+	svc := NewSearchService(nil)
+	listers := setupTestSearchListers(t)
+
+	result, err := svc.Search(listers, models.SearchRequest{Query: "web"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Total)
+	assert.False(t, result.Truncated)
+
+	for _, item := range result.Items {
+		assert.Contains(t, []string{"web-7d9f-abc", "web-5c4b-xyz", "web", "WEB-svc"}, item.Name)
+		assert.Equal(t, "5h", item.Age)
+	}
+}
+
+func TestSearchService_SearchKindsAndPagination(t *testing.T) {
+	svc := NewSearchService(nil)
+	listers := setupTestSearchListers(t)
+
+	result, err := svc.Search(listers, models.SearchRequest{Query: "web", Kinds: []string{"pod"}, PageSize: 1, Page: 2}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "pod", result.Items[0].Kind)
+	assert.Equal(t, "team-a", result.Items[0].Namespace)
+
+	_, err = svc.Search(listers, models.SearchRequest{Query: "web", Kinds: []string{"secret"}}, nil)
+	assert.Error(t, err)
+
+	_, err = svc.Search(listers, models.SearchRequest{Query: "  "}, nil)
+	assert.Error(t, err)
+}
+
+func TestSearchService_SearchNamespaceFilter(t *testing.T) {
+	svc := NewSearchService(nil)
+	listers := setupTestSearchListers(t)
+
+	onlyDefault := func(namespace, resource string) bool {
+		return namespace == "default"
+	}
+
+	result, err := svc.Search(listers, models.SearchRequest{Query: "web"}, onlyDefault)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	for _, item := range result.Items {
+		assert.Equal(t, "default", item.Namespace)
+	}
+}
+
+func TestSearchService_SearchResultCap(t *testing.T) {
+	svc := NewSearchService(nil)
+	pods := newTestIndexer()
+	for i := 0; i < searchResultCap+10; i++ {
+		require.NoError(t, pods.Add(&corev1.Pod{ObjectMeta: testObjectMeta("default", fmt.Sprintf("web-%d", i))}))
+	}
+	deployments := newTestIndexer()
+	require.NoError(t, deployments.Add(&appsv1.Deployment{ObjectMeta: testObjectMeta("default", "web")}))
+	listers := &SearchListers{
+		Pods:        corelisters.NewPodLister(pods),
+		Deployments: appslisters.NewDeploymentLister(deployments),
+	}
+
+	result, err := svc.Search(listers, models.SearchRequest{Query: "web", Kinds: []string{"pod"}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, searchResultCap, result.Total)
+	assert.True(t, result.Truncated)
+	assert.Len(t, result.Items, defaultSearchPageSize)
+	assert.Equal(t, "web-0", result.Items[0].Name)
+	assert.Equal(t, "web-1", result.Items[1].Name)
+
+	// Matches are sorted before the cap applies, so the kept ones don't depend on the order kinds are listed in
+	result, err = svc.Search(listers, models.SearchRequest{Query: "web", Kinds: []string{"pod", "deployment"}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, searchResultCap, result.Total)
+	assert.True(t, result.Truncated)
+	assert.Equal(t, "deployment", result.Items[0].Kind)
+}
@@ -1,14 +1,19 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/ciliverse/cilikube/configs"
 	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/auth"
+	"github.com/ciliverse/cilikube/pkg/useragent"
 )
 
 // SecurityService provides security-related functionality
@@ -108,48 +113,113 @@ func (s *SecurityService) ValidatePassword(password string) []PasswordValidation
 	return errors
 }
 
+// PasswordExpiryStatus describes when a password expires under the max age policy
+type PasswordExpiryStatus struct {
+	Expires         bool       `json:"expires"` // False when passwords never expire
+	Expired         bool       `json:"expired"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	DaysUntilExpiry int        `json:"days_until_expiry"`
+	MaxAgeDays      int        `json:"max_age_days"`
+}
+
+// GetPasswordExpiry reports when a user's password expires. Passwords whose change time was never
+// recorded, such as those set before the max age policy existed, do not expire until changed.
+func (s *SecurityService) GetPasswordExpiry(user *store.User) *PasswordExpiryStatus {
+	maxAge := s.config.Security.Password.MaxAge
+	status := &PasswordExpiryStatus{MaxAgeDays: maxAge}
+	if maxAge <= 0 || user.PasswordChangedAt == nil {
+		return status
+	}
+
+	expiresAt := user.PasswordChangedAt.AddDate(0, 0, maxAge)
+	remaining := time.Until(expiresAt)
+
+	status.Expires = true
+	status.ExpiresAt = &expiresAt
+	status.Expired = remaining <= 0
+	if !status.Expired {
+		status.DaysUntilExpiry = int(math.Ceil(remaining.Hours() / 24))
+	}
+	return status
+}
+
+// AccountLockStatus describes whether an account is locked out by failed login attempts
+type AccountLockStatus struct {
+	UserID         uint       `json:"user_id"`
+	Locked         bool       `json:"locked"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	FailedAttempts int        `json:"failed_attempts"`
+}
+
 // CheckAccountLockout checks if an account is locked due to failed login attempts
 func (s *SecurityService) CheckAccountLockout(userID uint) (bool, time.Time, error) {
+	status, err := s.GetAccountLockStatus(userID)
+	if err != nil || !status.Locked {
+		return false, time.Time{}, err
+	}
+	return true, *status.LockedUntil, nil
+}
+
+// GetAccountLockStatus returns the lockout state of an account. Failed logins count until a
+// successful login or an unlock, and only within the reset window.
+func (s *SecurityService) GetAccountLockStatus(userID uint) (*AccountLockStatus, error) {
+	status := &AccountLockStatus{UserID: userID}
 	if !s.config.Security.AccountLock.Enabled {
-		return false, time.Time{}, nil
+		return status, nil
 	}
 
 	// Get recent failed login attempts
 	since := time.Now().Add(-s.config.Security.AccountLock.ResetAfter)
 	attempts, _, err := s.store.GetAuditLogsByUserID(userID, 0, 100)
 	if err != nil {
-		return false, time.Time{}, fmt.Errorf("failed to get audit logs: %w", err)
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
 	}
 
-	// Count failed login attempts within the reset window
-	failedCount := 0
-	var lastFailedAttempt time.Time
-
+	// Stores differ in the order they return logs, so find the latest reset first
 	for _, attempt := range attempts {
-		if attempt.CreatedAt.Before(since) {
-			break // Older than reset window
+		if (attempt.Action == "login" || attempt.Action == "account_unlocked") && attempt.CreatedAt.After(since) {
+			since = attempt.CreatedAt
 		}
+	}
 
-		if attempt.Action == "login_failed" {
-			failedCount++
-			if lastFailedAttempt.IsZero() || attempt.CreatedAt.After(lastFailedAttempt) {
+	// Count failed login attempts after it
+	var lastFailedAttempt time.Time
+	for _, attempt := range attempts {
+		if attempt.Action == "login_failed" && attempt.CreatedAt.After(since) {
+			status.FailedAttempts++
+			if attempt.CreatedAt.After(lastFailedAttempt) {
 				lastFailedAttempt = attempt.CreatedAt
 			}
-		} else if attempt.Action == "login" {
-			// Successful login resets the counter
-			break
 		}
 	}
 
 	// Check if account should be locked
-	if failedCount >= s.config.Security.AccountLock.MaxFailedAttempts {
+	if status.FailedAttempts >= s.config.Security.AccountLock.MaxFailedAttempts {
 		lockoutEnd := lastFailedAttempt.Add(s.config.Security.AccountLock.LockoutDuration)
 		if time.Now().Before(lockoutEnd) {
-			return true, lockoutEnd, nil
+			status.Locked = true
+			status.LockedUntil = &lockoutEnd
 		}
 	}
 
-	return false, time.Time{}, nil
+	return status, nil
+}
+
+// UnlockAccount lifts a lockout before it expires. The account_unlocked audit event it records
+// resets the failed login count the same way a successful login does, and names the acting admin
+// taken from the audit context of ctx.
+func (s *SecurityService) UnlockAccount(ctx context.Context, userID uint) (*AccountLockStatus, error) {
+	details := "Account unlocked, failed login attempts cleared"
+	if admin := AuditContextFrom(ctx); admin.UserID != nil {
+		details = fmt.Sprintf("Account unlocked by %s (user %d), failed login attempts cleared", admin.Username, *admin.UserID)
+	}
+	// The log belongs to the unlocked user, whose failed login count it resets
+	auditLog := newAuditLog(ctx, &userID, "account_unlocked", "user", fmt.Sprintf("%d", userID), details)
+	if err := s.store.CreateAuditLog(auditLog); err != nil {
+		return nil, fmt.Errorf("failed to unlock account: %w", err)
+	}
+
+	return s.GetAccountLockStatus(userID)
 }
 
 // RecordFailedLogin records a failed login attempt
@@ -191,6 +261,9 @@ type SessionInfo struct {
 	CreatedAt time.Time `json:"created_at"`
 	LastSeen  time.Time `json:"last_seen"`
 	ExpiresAt time.Time `json:"expires_at"`
+
+	// Browser, OS and device type parsed from UserAgent, so users can recognize their devices
+	useragent.Info
 }
 
 // In-memory session store (in production, this should be Redis or database)
@@ -210,6 +283,7 @@ func (s *SecurityService) CreateSession(userID uint, ipAddress, userAgent string
 		CreatedAt: now,
 		LastSeen:  now,
 		ExpiresAt: now.Add(s.config.Security.Session.AbsoluteTimeout),
+		Info:      useragent.Parse(userAgent),
 	}
 
 	// Check concurrent session limit
@@ -312,13 +386,23 @@ func (s *SecurityService) InvalidateSession(sessionID string) error {
 
 // InvalidateAllUserSessions invalidates all sessions for a user
 func (s *SecurityService) InvalidateAllUserSessions(userID uint) error {
-	sessionIDs := userSessions[userID]
+	// InvalidateSession removes each session from userSessions, so iterate over a copy
+	sessionIDs := append([]string(nil), userSessions[userID]...)
 	for _, sessionID := range sessionIDs {
 		s.InvalidateSession(sessionID)
 	}
 	return nil
 }
 
+// RevokeUserAccess ends every session of a user and rejects the tokens issued to it, so a deactivated
+// user is logged out right away instead of when its tokens expire
+func (s *SecurityService) RevokeUserAccess(userID uint) {
+	if err := s.InvalidateAllUserSessions(userID); err != nil {
+		log.Printf("Warning: failed to invalidate the sessions of user %d: %v", userID, err)
+	}
+	auth.RevokeUserTokens(userID)
+}
+
 // GetUserSessions returns all active sessions for a user
 func (s *SecurityService) GetUserSessions(userID uint) []*SessionInfo {
 	sessionIDs := userSessions[userID]
@@ -127,6 +127,31 @@ func TestSessionManagement(t *testing.T) {
 	}
 }
 
+func TestSessionDeviceInfo(t *testing.T) {
+	config := &configs.Config{Security: configs.SecurityConfig{Session: configs.SessionConfig{AbsoluteTimeout: time.Hour}}}
+	securityService := NewSecurityService(store.NewMemoryStore(), config)
+
+	userID := uint(4242)
+	userAgent := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	sessionID, err := securityService.CreateSession(userID, "203.0.113.7", userAgent)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	defer securityService.InvalidateAllUserSessions(userID)
+
+	sessions := securityService.GetUserSessions(userID)
+	if len(sessions) != 1 || sessions[0].SessionID != sessionID {
+		t.Fatalf("GetUserSessions = %v, want the created session", sessions)
+	}
+	session := sessions[0]
+	if session.Browser != "Safari" || session.OS != "iOS" || session.OSVersion != "17.4.1" || session.DeviceType != "mobile" {
+		t.Errorf("Parsed device = %+v, want Safari on iOS 17.4.1, mobile", session.Info)
+	}
+	if session.UserAgent != userAgent || session.IPAddress != "203.0.113.7" || session.LastSeen.IsZero() {
+		t.Errorf("Session = %+v, want the raw user agent, IP address and last seen time", session)
+	}
+}
+
 func TestAccountLockout(t *testing.T) {
 	// Create test config with account lockout enabled
 	config := &configs.Config{
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	endpointSourceSlice     = "EndpointSlice"
+	endpointSourceEndpoints = "Endpoints"
+)
+
+// ServiceEndpointsService resolves the backends of a Service
+type ServiceEndpointsService struct{}
+
+// NewServiceEndpointsService creates Service endpoints service
+func NewServiceEndpointsService() *ServiceEndpointsService {
+	return &ServiceEndpointsService{}
+}
+
+// GetServiceEndpoints returns the ready and not-ready addresses of a Service.
+// EndpointSlices are used when available; clusters without discovery.k8s.io/v1 fall back to core/v1 Endpoints.
+func (s *ServiceEndpointsService) GetServiceEndpoints(clientset kubernetes.Interface, namespace, name string) (*models.ServiceEndpointsResponse, error) {
+	ctx := context.Background()
+
+	if _, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	slices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+	})
+	if err == nil {
+		return s.fromEndpointSlices(namespace, name, slices.Items), nil
+	}
+	if !k8serrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to list endpoint slices: %w", err)
+	}
+
+	endpoints, err := clientset.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return s.emptyResponse(namespace, name, endpointSourceEndpoints), nil
+		}
+		return nil, fmt.Errorf("failed to get endpoints: %w", err)
+	}
+	return s.fromEndpoints(endpoints), nil
+}
+
+// fromEndpointSlices converts the EndpointSlices of a Service into a response
+func (s *ServiceEndpointsService) fromEndpointSlices(namespace, name string, slices []discoveryv1.EndpointSlice) *models.ServiceEndpointsResponse {
+	response := s.emptyResponse(namespace, name, endpointSourceSlice)
+	seenPorts := make(map[string]bool)
+
+	for _, slice := range slices {
+		for _, port := range slice.Ports {
+			item := models.EndpointPort{Protocol: string(corev1.ProtocolTCP)}
+			if port.Name != nil {
+				item.Name = *port.Name
+			}
+			if port.Port != nil {
+				item.Port = *port.Port
+			}
+			if port.Protocol != nil {
+				item.Protocol = string(*port.Protocol)
+			}
+			key := fmt.Sprintf("%s/%d/%s", item.Name, item.Port, item.Protocol)
+			if !seenPorts[key] {
+				seenPorts[key] = true
+				response.Ports = append(response.Ports, item)
+			}
+		}
+
+		for _, endpoint := range slice.Endpoints {
+			// A nil ready condition means the endpoint should be treated as ready
+			ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+			for _, ip := range endpoint.Addresses {
+				address := models.EndpointAddress{IP: ip, Source: slice.Name}
+				if endpoint.Hostname != nil {
+					address.Hostname = *endpoint.Hostname
+				}
+				if endpoint.NodeName != nil {
+					address.NodeName = *endpoint.NodeName
+				}
+				if endpoint.Zone != nil {
+					address.Zone = *endpoint.Zone
+				}
+				address.TargetRef = toEndpointTargetRef(endpoint.TargetRef)
+
+				if ready {
+					response.Ready = append(response.Ready, address)
+				} else {
+					response.NotReady = append(response.NotReady, address)
+				}
+			}
+		}
+	}
+	return response
+}
+
+// fromEndpoints converts a core/v1 Endpoints object into a response
+func (s *ServiceEndpointsService) fromEndpoints(endpoints *corev1.Endpoints) *models.ServiceEndpointsResponse {
+	response := s.emptyResponse(endpoints.Namespace, endpoints.Name, endpointSourceEndpoints)
+	seenPorts := make(map[string]bool)
+
+	convert := func(addr corev1.EndpointAddress) models.EndpointAddress {
+		address := models.EndpointAddress{
+			IP:        addr.IP,
+			Hostname:  addr.Hostname,
+			TargetRef: toEndpointTargetRef(addr.TargetRef),
+			Source:    endpoints.Name,
+		}
+		if addr.NodeName != nil {
+			address.NodeName = *addr.NodeName
+		}
+		return address
+	}
+
+	for _, subset := range endpoints.Subsets {
+		for _, port := range subset.Ports {
+			item := models.EndpointPort{Name: port.Name, Port: port.Port, Protocol: string(port.Protocol)}
+			key := fmt.Sprintf("%s/%d/%s", item.Name, item.Port, item.Protocol)
+			if !seenPorts[key] {
+				seenPorts[key] = true
+				response.Ports = append(response.Ports, item)
+			}
+		}
+		for _, addr := range subset.Addresses {
+			response.Ready = append(response.Ready, convert(addr))
+		}
+		for _, addr := range subset.NotReadyAddresses {
+			response.NotReady = append(response.NotReady, convert(addr))
+		}
+	}
+	return response
+}
+
+// emptyResponse creates a response with initialized slices so they serialize as []
+func (s *ServiceEndpointsService) emptyResponse(namespace, name, source string) *models.ServiceEndpointsResponse {
+	return &models.ServiceEndpointsResponse{
+		Service:   name,
+		Namespace: namespace,
+		Source:    source,
+		Ready:     []models.EndpointAddress{},
+		NotReady:  []models.EndpointAddress{},
+		Ports:     []models.EndpointPort{},
+	}
+}
+
+// toEndpointTargetRef converts an object reference into the endpoint target DTO
+func toEndpointTargetRef(ref *corev1.ObjectReference) *models.EndpointTargetRef {
+	if ref == nil {
+		return nil
+	}
+	return &models.EndpointTargetRef{
+		Kind:      ref.Kind,
+		Namespace: ref.Namespace,
+		Name:      ref.Name,
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func newTestService(name string) *corev1.Service {
+	return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+}
+
+func newTestSlice(name, service string, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports: []discoveryv1.EndpointPort{{
+			Name:     ptr.To("http"),
+			Port:     ptr.To(int32(8080)),
+			Protocol: ptr.To(corev1.ProtocolTCP),
+		}},
+		Endpoints: endpoints,
+	}
+}
+
+func newTestEndpoint(ip, pod string, ready bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses:  []string{ip},
+		Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)},
+		NodeName:   ptr.To("node-1"),
+		TargetRef:  &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+	}
+}
+
+func TestServiceEndpointsService_EndpointSlices(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newTestService("web"),
+		newTestSlice("web-abc", "web", newTestEndpoint("10.0.0.1", "web-1", true), newTestEndpoint("10.0.0.2", "web-2", false)),
+		newTestSlice("web-def", "web", newTestEndpoint("10.0.0.3", "web-3", true)),
+		newTestSlice("db-abc", "db", newTestEndpoint("10.0.1.1", "db-0", true)),
+	)
+
+	svc := NewServiceEndpointsService()
+	result, err := svc.GetServiceEndpoints(clientset, "default", "web")
+	require.NoError(t, err)
+
+	assert.Equal(t, endpointSourceSlice, result.Source)
+	assert.Len(t, result.Ready, 2)
+	require.Len(t, result.NotReady, 1)
+	assert.Equal(t, "10.0.0.2", result.NotReady[0].IP)
+	assert.Equal(t, "web-2", result.NotReady[0].TargetRef.Name)
+	assert.Equal(t, "web-abc", result.NotReady[0].Source)
+
+	// Ports shared by several slices are reported once
+	require.Len(t, result.Ports, 1)
+	assert.Equal(t, int32(8080), result.Ports[0].Port)
+}
+
+func TestServiceEndpointsService_FallbackToEndpoints(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newTestService("web"),
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "web-1"}}},
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}},
+				Ports:             []corev1.EndpointPort{{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}},
+			}},
+		},
+	)
+	// Simulate a cluster that does not serve discovery.k8s.io/v1
+	clientset.PrependReactor("list", "endpointslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewNotFound(schema.GroupResource{Group: "discovery.k8s.io", Resource: "endpointslices"}, "")
+	})
+
+	svc := NewServiceEndpointsService()
+	result, err := svc.GetServiceEndpoints(clientset, "default", "web")
+	require.NoError(t, err)
+
+	assert.Equal(t, endpointSourceEndpoints, result.Source)
+	require.Len(t, result.Ready, 1)
+	assert.Equal(t, "web-1", result.Ready[0].TargetRef.Name)
+	assert.Len(t, result.NotReady, 1)
+	assert.Len(t, result.Ports, 1)
+}
+
+func TestServiceEndpointsService_MissingService(t *testing.T) {
+	svc := NewServiceEndpointsService()
+	_, err := svc.GetServiceEndpoints(fake.NewSimpleClientset(), "default", "web")
+	require.Error(t, err)
+	assert.True(t, k8serrors.IsNotFound(err))
+}
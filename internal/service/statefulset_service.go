@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// StatefulSetItemFrom summarizes a StatefulSet with the replica counts and revisions of its status
+func StatefulSetItemFrom(sts *appsv1.StatefulSet) models.StatefulSetItem {
+	status := sts.Status
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	item := models.StatefulSetItem{
+		Name:              sts.Name,
+		Namespace:         sts.Namespace,
+		Replicas:          replicas,
+		ReadyReplicas:     status.ReadyReplicas,
+		CurrentReplicas:   status.CurrentReplicas,
+		UpdatedReplicas:   status.UpdatedReplicas,
+		AvailableReplicas: status.AvailableReplicas,
+		CurrentRevision:   status.CurrentRevision,
+		UpdateRevision:    status.UpdateRevision,
+		UpdateStrategy:    string(sts.Spec.UpdateStrategy.Type),
+		CreatedAt:         sts.CreationTimestamp,
+	}
+
+	// As kubectl rollout status: with a partition only the pods at or above it have to be updated,
+	// otherwise the whole set runs the update revision
+	observed := status.ObservedGeneration >= sts.Generation && status.ReadyReplicas >= replicas
+	if rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		partition := *rollingUpdate.Partition
+		item.Partition = &partition
+		item.RolloutComplete = observed && status.UpdatedReplicas >= replicas-partition
+	} else {
+		item.RolloutComplete = observed && status.UpdateRevision == status.CurrentRevision
+	}
+	return item
+}
+
+// ListStatefulSetItems lists the StatefulSets of a namespace, all namespaces when empty, with their rollout progress
+func ListStatefulSetItems(ctx context.Context, clientset kubernetes.Interface, namespace string) (*models.StatefulSetListResponse, error) {
+	list, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stateful sets: %w", err)
+	}
+
+	items := make([]models.StatefulSetItem, 0, len(list.Items))
+	for i := range list.Items {
+		items = append(items, StatefulSetItemFrom(&list.Items[i]))
+	}
+	return &models.StatefulSetListResponse{
+		Items: items,
+		Total: len(items),
+	}, nil
+}
+
+// GetStatefulSetItem returns a StatefulSet with its rollout progress
+func GetStatefulSetItem(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*models.StatefulSetItem, error) {
+	sts, err := new(StatefulSetClient).Get(ctx, clientset, namespace, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	item := StatefulSetItemFrom(sts)
+	return &item, nil
+}
+
+// SetStatefulSetPartition patches the rolling update partition of a StatefulSet for a canary rollout:
+// only pods with an ordinal at or above the partition receive the new revision. A set using the
+// OnDelete strategy is switched to RollingUpdate, the only strategy with a partition.
+func SetStatefulSetPartition(ctx context.Context, clientset kubernetes.Interface, namespace, name string, partition int32) (*appsv1.StatefulSet, error) {
+	if partition < 0 {
+		return nil, fmt.Errorf("%w: partition must not be negative, got %d", ErrInvalidResource, partition)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"updateStrategy": map[string]interface{}{
+				"type":          appsv1.RollingUpdateStatefulSetStrategyType,
+				"rollingUpdate": map[string]interface{}{"partition": partition},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build partition patch: %w", err)
+	}
+
+	return clientset.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestStatefulSet(name string, replicas int32, strategy appsv1.StatefulSetUpdateStrategy, status appsv1.StatefulSetStatus) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, UpdateStrategy: strategy},
+		Status:     status,
+	}
+}
+
+func TestListStatefulSetItems(t *testing.T) {
+	partition := int32(2)
+	clientset := fake.NewSimpleClientset(
+		newTestStatefulSet("db", 3, appsv1.StatefulSetUpdateStrategy{
+			Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+		}, appsv1.StatefulSetStatus{
+			ObservedGeneration: 1, Replicas: 3, ReadyReplicas: 3, CurrentReplicas: 2, UpdatedReplicas: 1,
+			AvailableReplicas: 3, CurrentRevision: "db-6b8f", UpdateRevision: "db-7c9d",
+		}),
+		newTestStatefulSet("cache", 2, appsv1.StatefulSetUpdateStrategy{
+			Type: appsv1.RollingUpdateStatefulSetStrategyType,
+		}, appsv1.StatefulSetStatus{
+			ObservedGeneration: 1, Replicas: 2, ReadyReplicas: 2, CurrentReplicas: 1, UpdatedReplicas: 1,
+			CurrentRevision: "cache-1a2b", UpdateRevision: "cache-3c4d",
+		}),
+	)
+
+	list, err := ListStatefulSetItems(context.Background(), clientset, "default")
+	require.NoError(t, err)
+	require.Equal(t, 2, list.Total)
+
+	cache := list.Items[0]
+	assert.Equal(t, "cache", cache.Name)
+	assert.Nil(t, cache.Partition)
+	assert.False(t, cache.RolloutComplete)
+
+	db, err := GetStatefulSetItem(context.Background(), clientset, "default", "db")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, db.Replicas)
+	assert.EqualValues(t, 3, db.ReadyReplicas)
+	assert.EqualValues(t, 2, db.CurrentReplicas)
+	assert.EqualValues(t, 1, db.UpdatedReplicas)
+	assert.Equal(t, "db-6b8f", db.CurrentRevision)
+	assert.Equal(t, "db-7c9d", db.UpdateRevision)
+	assert.Equal(t, "RollingUpdate", db.UpdateStrategy)
+	require.NotNil(t, db.Partition)
+	assert.EqualValues(t, 2, *db.Partition)
+	// The canary above the partition runs the update revision
+	assert.True(t, db.RolloutComplete)
+}
+
+func TestSetStatefulSetPartition(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestStatefulSet("db", 3, appsv1.StatefulSetUpdateStrategy{
+		Type: appsv1.OnDeleteStatefulSetStrategyType,
+	}, appsv1.StatefulSetStatus{}))
+
+	for _, partition := range []int32{2, 0} {
+		_, err := SetStatefulSetPartition(context.Background(), clientset, "default", "db", partition)
+		require.NoError(t, err)
+		stored, err := clientset.AppsV1().StatefulSets("default").Get(t.Context(), "db", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, appsv1.RollingUpdateStatefulSetStrategyType, stored.Spec.UpdateStrategy.Type)
+		require.NotNil(t, stored.Spec.UpdateStrategy.RollingUpdate)
+		require.NotNil(t, stored.Spec.UpdateStrategy.RollingUpdate.Partition)
+		assert.Equal(t, partition, *stored.Spec.UpdateStrategy.RollingUpdate.Partition)
+		assert.EqualValues(t, 3, *stored.Spec.Replicas)
+	}
+
+	_, err := SetStatefulSetPartition(context.Background(), clientset, "default", "db", -1)
+	assert.True(t, errors.Is(err, ErrInvalidResource))
+
+	_, err = SetStatefulSetPartition(context.Background(), clientset, "default", "missing", 1)
+	assert.Error(t, err)
+}
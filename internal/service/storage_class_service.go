@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// defaultStorageClassAnnotation marks the cluster default StorageClass
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	// betaDefaultStorageClassAnnotation is the deprecated form still honoured by older clusters
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// IsDefaultStorageClass reports whether a StorageClass is annotated as the cluster default
+func IsDefaultStorageClass(sc *storagev1.StorageClass) bool {
+	return sc.Annotations[defaultStorageClassAnnotation] == "true" || sc.Annotations[betaDefaultStorageClassAnnotation] == "true"
+}
+
+// ListStorageClassItems lists StorageClasses as summary items flagging the default class
+func ListStorageClassItems(clientset kubernetes.Interface) (*models.StorageClassListResponse, error) {
+	scList, err := clientset.StorageV1().StorageClasses().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage classes: %w", err)
+	}
+
+	items := make([]models.StorageClassItem, 0, len(scList.Items))
+	for i := range scList.Items {
+		sc := &scList.Items[i]
+
+		item := models.StorageClassItem{
+			Name:        sc.Name,
+			Provisioner: sc.Provisioner,
+			IsDefault:   IsDefaultStorageClass(sc),
+			CreatedAt:   sc.CreationTimestamp,
+		}
+		// The API server defaults these fields, but fake or old objects may leave them empty
+		item.ReclaimPolicy = "Delete"
+		if sc.ReclaimPolicy != nil {
+			item.ReclaimPolicy = string(*sc.ReclaimPolicy)
+		}
+		item.VolumeBindingMode = string(storagev1.VolumeBindingImmediate)
+		if sc.VolumeBindingMode != nil {
+			item.VolumeBindingMode = string(*sc.VolumeBindingMode)
+		}
+		if sc.AllowVolumeExpansion != nil {
+			item.AllowVolumeExpansion = *sc.AllowVolumeExpansion
+		}
+		items = append(items, item)
+	}
+
+	return &models.StorageClassListResponse{
+		Items: items,
+		Total: len(items),
+	}, nil
+}
+
+// SetDefaultStorageClass makes the named StorageClass the only default class.
+// The previous defaults are cleared first; if marking the new class fails they are restored.
+func SetDefaultStorageClass(clientset kubernetes.Interface, name string) (*storagev1.StorageClass, error) {
+	ctx := context.Background()
+	classes := clientset.StorageV1().StorageClasses()
+
+	target, err := classes.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	scList, err := classes.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage classes: %w", err)
+	}
+
+	var cleared []string
+	for i := range scList.Items {
+		sc := &scList.Items[i]
+		if sc.Name == name || !IsDefaultStorageClass(sc) {
+			continue
+		}
+		if err := patchDefaultStorageClass(ctx, clientset, sc.Name, false); err != nil {
+			restoreDefaultStorageClasses(ctx, clientset, cleared)
+			return nil, fmt.Errorf("failed to clear default flag on storage class %s: %w", sc.Name, err)
+		}
+		cleared = append(cleared, sc.Name)
+	}
+
+	if IsDefaultStorageClass(target) && target.Annotations[betaDefaultStorageClassAnnotation] == "" {
+		return target, nil
+	}
+
+	if err := patchDefaultStorageClass(ctx, clientset, name, true); err != nil {
+		restoreDefaultStorageClasses(ctx, clientset, cleared)
+		return nil, fmt.Errorf("failed to mark storage class %s as default: %w", name, err)
+	}
+	return classes.Get(ctx, name, metav1.GetOptions{})
+}
+
+// patchDefaultStorageClass sets or removes the default annotations of a StorageClass
+func patchDefaultStorageClass(ctx context.Context, clientset kubernetes.Interface, name string, isDefault bool) error {
+	annotations := map[string]interface{}{
+		// The beta annotation is always dropped so it cannot disagree with the GA one
+		betaDefaultStorageClassAnnotation: nil,
+		defaultStorageClassAnnotation:     nil,
+	}
+	if isDefault {
+		annotations[defaultStorageClassAnnotation] = "true"
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = clientset.StorageV1().StorageClasses().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// restoreDefaultStorageClasses re-marks classes whose default flag was cleared during a failed SetDefault
+func restoreDefaultStorageClasses(ctx context.Context, clientset kubernetes.Interface, names []string) {
+	for _, name := range names {
+		_ = patchDefaultStorageClass(ctx, clientset, name, true)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestStorageClass(name string, annotations map[string]string) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: name, Annotations: annotations},
+		Provisioner: "kubernetes.io/no-provisioner",
+	}
+}
+
+func countDefaultStorageClasses(t *testing.T, clientset *fake.Clientset) []string {
+	list, err := clientset.StorageV1().StorageClasses().List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+
+	var defaults []string
+	for i := range list.Items {
+		if IsDefaultStorageClass(&list.Items[i]) {
+			defaults = append(defaults, list.Items[i].Name)
+		}
+	}
+	return defaults
+}
+
+func TestSetDefaultStorageClass(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newTestStorageClass("standard", map[string]string{defaultStorageClassAnnotation: "true"}),
+		newTestStorageClass("legacy", map[string]string{betaDefaultStorageClassAnnotation: "true"}),
+		newTestStorageClass("fast", nil),
+	)
+
+	sc, err := SetDefaultStorageClass(clientset, "fast")
+	require.NoError(t, err)
+	assert.True(t, IsDefaultStorageClass(sc))
+	assert.Equal(t, []string{"fast"}, countDefaultStorageClasses(t, clientset))
+
+	// Switching back keeps exactly one default
+	_, err = SetDefaultStorageClass(clientset, "standard")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"standard"}, countDefaultStorageClasses(t, clientset))
+
+	_, err = SetDefaultStorageClass(clientset, "missing")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"standard"}, countDefaultStorageClasses(t, clientset))
+}
+
+func TestListStorageClassItems(t *testing.T) {
+	retain := corev1.PersistentVolumeReclaimRetain
+	waitForConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+
+	fast := newTestStorageClass("fast", map[string]string{defaultStorageClassAnnotation: "true"})
+	fast.ReclaimPolicy = &retain
+	fast.VolumeBindingMode = &waitForConsumer
+
+	clientset := fake.NewSimpleClientset(fast, newTestStorageClass("slow", nil))
+
+	items, err := ListStorageClassItems(clientset)
+	require.NoError(t, err)
+	require.Equal(t, 2, items.Total)
+
+	for _, item := range items.Items {
+		switch item.Name {
+		case "fast":
+			assert.True(t, item.IsDefault)
+			assert.Equal(t, "Retain", item.ReclaimPolicy)
+			assert.Equal(t, "WaitForFirstConsumer", item.VolumeBindingMode)
+		case "slow":
+			assert.False(t, item.IsDefault)
+			assert.Equal(t, "Delete", item.ReclaimPolicy)
+			assert.Equal(t, "Immediate", item.VolumeBindingMode)
+		}
+		assert.Equal(t, "kubernetes.io/no-provisioner", item.Provisioner)
+	}
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+// TemplateFieldManager is the server-side apply field manager of objects created from templates
+const TemplateFieldManager = "cilikube-template"
+
+var (
+	// ErrTemplateNotFound is returned for a template name that is neither built in nor stored
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrTemplateExists is returned when creating a template under a name already in use
+	ErrTemplateExists = errors.New("template already exists")
+	// ErrInvalidTemplate is returned for templates that cannot be stored or rendered
+	ErrInvalidTemplate = errors.New("invalid template")
+	// ErrInvalidTemplateParams is returned when instantiating a template with missing or unknown parameters
+	ErrInvalidTemplateParams = errors.New("invalid template parameters")
+)
+
+// templateParameterName matches the parameter names usable as {{ .name }} in a template
+var templateParameterName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// builtinTemplates are shipped with the server for common tasks and cannot be replaced
+var builtinTemplates = []models.ResourceTemplateResponse{
+	{
+		Name:        "nginx",
+		Description: "nginx Deployment with a ClusterIP Service in front of it",
+		Parameters: []models.TemplateParameter{
+			{Name: "name", Description: "Name of the Deployment and Service", Required: true},
+			{Name: "image", Description: "Container image", Default: "nginx:1.27"},
+			{Name: "replicas", Description: "Number of pods", Default: "1"},
+			{Name: "port", Description: "Port nginx listens on and the Service exposes", Default: "80"},
+		},
+		Manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .name }}
+  labels:
+    app: {{ .name }}
+spec:
+  replicas: {{ .replicas }}
+  selector:
+    matchLabels:
+      app: {{ .name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .name }}
+    spec:
+      containers:
+        - name: nginx
+          image: {{ .image }}
+          ports:
+            - containerPort: {{ .port }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .name }}
+  labels:
+    app: {{ .name }}
+spec:
+  selector:
+    app: {{ .name }}
+  ports:
+    - port: {{ .port }}
+      targetPort: {{ .port }}
+`,
+		BuiltIn: true,
+	},
+	{
+		Name:        "configmap",
+		Description: "ConfigMap holding a single key",
+		Parameters: []models.TemplateParameter{
+			{Name: "name", Description: "Name of the ConfigMap", Required: true},
+			{Name: "key", Description: "Data key", Required: true},
+			{Name: "value", Description: "Value of the key"},
+		},
+		Manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .name }}
+data:
+  {{ printf "%q" .key }}: {{ printf "%q" .value }}
+`,
+		BuiltIn: true,
+	},
+}
+
+// TemplateResult reports the outcome of instantiating a template into a namespace
+type TemplateResult struct {
+	Template  string                 `json:"template"`
+	Namespace string                 `json:"namespace"`
+	DryRun    bool                   `json:"dryRun"`
+	Applied   int                    `json:"applied"`
+	Failed    int                    `json:"failed"`
+	Objects   []TemplateObjectResult `json:"objects"`
+}
+
+// TemplateObjectResult is the outcome of applying one object rendered from a template
+type TemplateObjectResult struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Status     string `json:"status"` // ImportApplied or ImportFailed
+	Error      string `json:"error,omitempty"`
+}
+
+// TemplateService stores named Go templates of Kubernetes manifests with declared parameters, and
+// creates resources by rendering them
+type TemplateService struct {
+	store store.Store
+}
+
+// NewTemplateService creates a new TemplateService instance
+func NewTemplateService(store store.Store) *TemplateService {
+	return &TemplateService{store: store}
+}
+
+// ListTemplates returns the built-in templates followed by the stored ones, ordered by name
+func (s *TemplateService) ListTemplates() ([]models.ResourceTemplateResponse, error) {
+	stored, err := s.store.ListResourceTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	templates := append([]models.ResourceTemplateResponse{}, builtinTemplates...)
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	for _, tmpl := range stored {
+		templates = append(templates, toTemplateResponse(tmpl))
+	}
+	return templates, nil
+}
+
+// GetTemplate returns the built-in or stored template of that name
+func (s *TemplateService) GetTemplate(name string) (*models.ResourceTemplateResponse, error) {
+	for _, tmpl := range builtinTemplates {
+		if tmpl.Name == name {
+			return &tmpl, nil
+		}
+	}
+	stored, err := s.store.GetResourceTemplate(name)
+	if errors.Is(err, store.ErrResourceTemplateNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	response := toTemplateResponse(stored)
+	return &response, nil
+}
+
+// CreateTemplate validates and stores a template. Its name must be a DNS label not already used by a
+// built-in or stored template, its parameters identifiers, and its manifest a Go template using no
+// other parameters.
+func (s *TemplateService) CreateTemplate(req *models.CreateResourceTemplateRequest, createdBy uint) (*models.ResourceTemplateResponse, error) {
+	if errs := validation.IsDNS1123Label(req.Name); len(errs) > 0 {
+		return nil, fmt.Errorf("%w: name %s", ErrInvalidTemplate, strings.Join(errs, ", "))
+	}
+	if err := validateTemplate(req.Name, req.Parameters, req.Manifest); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetTemplate(req.Name); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateExists, req.Name)
+	} else if !errors.Is(err, ErrTemplateNotFound) {
+		return nil, err
+	}
+
+	stored := &store.ResourceTemplate{
+		Name:        req.Name,
+		Description: req.Description,
+		Parameters:  make(store.TemplateParameters, len(req.Parameters)),
+		Manifest:    req.Manifest,
+		CreatedBy:   &createdBy,
+	}
+	for i, param := range req.Parameters {
+		stored.Parameters[i] = store.TemplateParameter(param)
+	}
+	if err := s.store.CreateResourceTemplate(stored); err != nil {
+		return nil, fmt.Errorf("failed to create template: %w", err)
+	}
+	response := toTemplateResponse(stored)
+	return &response, nil
+}
+
+// Render renders a template with the given parameter values, optional parameters that are not given
+// taking their default, and returns the objects of the resulting manifest.
+//
+// The template is executed with a placeholder for each parameter and parsed as YAML before the values
+// replace the placeholders in its scalars, so a value never adds fields or objects whatever it holds.
+// A plain scalar made of a single parameter takes the type YAML gives the value, as in replicas: {{ .replicas }}.
+func (s *TemplateService) Render(tmpl *models.ResourceTemplateResponse, params map[string]string) ([]*unstructured.Unstructured, error) {
+	values, err := templateValues(tmpl.Parameters, params)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parseTemplate(tmpl.Name, tmpl.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	placeholders := templatePlaceholders(values)
+	var rendered bytes.Buffer
+	if err := parsed.Execute(&rendered, placeholders); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	manifest, err := substituteTemplateValues(rendered.Bytes(), placeholders, values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the rendered manifest is not valid YAML: %v", ErrInvalidTemplate, err)
+	}
+	objects, err := decodeManifests(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the rendered manifest is not valid YAML: %v", ErrInvalidTemplate, err)
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("%w: the rendered manifest contains no objects", ErrInvalidTemplate)
+	}
+	return objects, nil
+}
+
+// Instantiate renders the named template and applies the objects into namespace with server-side
+// apply. Only namespaced kinds are created. Failures are recorded per object and do not stop the
+// remaining objects.
+func (s *TemplateService) Instantiate(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, name, namespace string, params map[string]string, opts ImportOptions) (*TemplateResult, error) {
+	tmpl, err := s.GetTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s.Render(tmpl, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TemplateResult{
+		Template:  name,
+		Namespace: namespace,
+		DryRun:    opts.DryRun,
+		Objects:   make([]TemplateObjectResult, 0, len(objects)),
+	}
+	applyOpts := metav1.ApplyOptions{FieldManager: TemplateFieldManager}
+	if opts.DryRun {
+		applyOpts.DryRun = []string{metav1.DryRunAll}
+	}
+	for _, obj := range objects {
+		entry := TemplateObjectResult{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+			Status:     ImportApplied,
+		}
+		obj.SetNamespace(namespace)
+		if err := applyNamespacedObject(ctx, client, mapper, namespace, obj, applyOpts, opts.Allowed); err != nil {
+			entry.Status = ImportFailed
+			entry.Error = err.Error()
+			result.Failed++
+		} else {
+			result.Applied++
+		}
+		result.Objects = append(result.Objects, entry)
+	}
+	return result, nil
+}
+
+// validateTemplate checks the parameters and manifest of a template before it is stored
+func validateTemplate(name string, params []models.TemplateParameter, manifest string) error {
+	declared := make(map[string]bool, len(params))
+	for _, param := range params {
+		if !templateParameterName.MatchString(param.Name) {
+			return fmt.Errorf("%w: parameter name %q must be a letter or '_' followed by letters, digits or '_'", ErrInvalidTemplate, param.Name)
+		}
+		if declared[param.Name] {
+			return fmt.Errorf("%w: parameter %s is declared twice", ErrInvalidTemplate, param.Name)
+		}
+		if param.Required && param.Default != "" {
+			return fmt.Errorf("%w: required parameter %s cannot have a default", ErrInvalidTemplate, param.Name)
+		}
+		declared[param.Name] = true
+	}
+	parsed, err := parseTemplate(name, manifest)
+	if err != nil {
+		return err
+	}
+
+	// Rendering with placeholder values finds the parameters used without being declared
+	placeholders := make(map[string]string, len(params))
+	for _, param := range params {
+		placeholders[param.Name] = "x"
+	}
+	if err := parsed.Execute(&bytes.Buffer{}, placeholders); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+// parseTemplate parses a manifest template, referring to a parameter without a value being an error
+func parseTemplate(name, manifest string) (*template.Template, error) {
+	parsed, err := template.New(name).Option("missingkey=error").Parse(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return parsed, nil
+}
+
+// templateValues returns the values a template is rendered with: the given ones and the defaults of
+// the optional parameters that are not given. Unknown and missing required parameters are an error.
+func templateValues(declared []models.TemplateParameter, params map[string]string) (map[string]string, error) {
+	known := make(map[string]bool, len(declared))
+	for _, param := range declared {
+		known[param.Name] = true
+	}
+	var unknown []string
+	for name := range params {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%w: unknown parameters %s", ErrInvalidTemplateParams, strings.Join(unknown, ", "))
+	}
+
+	values := make(map[string]string, len(declared))
+	var missing []string
+	for _, param := range declared {
+		value, given := params[param.Name]
+		switch {
+		case given && value != "":
+			values[param.Name] = value
+		case param.Required:
+			missing = append(missing, param.Name)
+		default:
+			values[param.Name] = param.Default
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing required parameters %s", ErrInvalidTemplateParams, strings.Join(missing, ", "))
+	}
+	return values, nil
+}
+
+// templatePlaceholders returns a placeholder per parameter, unique to one rendering so the template can't
+// contain it, and safe in a plain YAML scalar
+func templatePlaceholders(values map[string]string) map[string]string {
+	nonce := make([]byte, 8)
+	_, _ = rand.Read(nonce)
+	placeholders := make(map[string]string, len(values))
+	for name := range values {
+		placeholders[name] = "__cilikube_" + hex.EncodeToString(nonce) + "_" + name + "__"
+	}
+	return placeholders
+}
+
+// substituteTemplateValues replaces the placeholders of a rendered manifest by the parameter values in
+// the scalars of its YAML documents, and returns the documents encoded again
+func substituteTemplateValues(rendered []byte, placeholders, values map[string]string) ([]byte, error) {
+	replacements := make([]string, 0, 2*len(placeholders))
+	whole := make(map[string]bool, len(placeholders))
+	for name, placeholder := range placeholders {
+		replacements = append(replacements, placeholder, values[name])
+		whole[placeholder] = true
+	}
+	replacer := strings.NewReplacer(replacements...)
+
+	var substituted bytes.Buffer
+	encoder := yaml.NewEncoder(&substituted)
+	decoder := yaml.NewDecoder(bytes.NewReader(rendered))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		substituteTemplateNode(&document, replacer, whole)
+		if err := encoder.Encode(&document); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return substituted.Bytes(), nil
+}
+
+// substituteTemplateNode replaces the placeholders in the scalars of node and its children. A plain scalar
+// that is a whole placeholder loses its tag, so its value is typed as YAML would; other scalars stay strings.
+func substituteTemplateNode(node *yaml.Node, replacer *strings.Replacer, whole map[string]bool) {
+	if node.Kind == yaml.ScalarNode {
+		if value := replacer.Replace(node.Value); value != node.Value {
+			node.Tag = "!!str"
+			if whole[node.Value] && node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+				node.Tag = ""
+			}
+			node.Value = value
+		}
+	}
+	for _, child := range node.Content {
+		substituteTemplateNode(child, replacer, whole)
+	}
+}
+
+func toTemplateResponse(stored *store.ResourceTemplate) models.ResourceTemplateResponse {
+	response := models.ResourceTemplateResponse{
+		Name:        stored.Name,
+		Description: stored.Description,
+		Parameters:  make([]models.TemplateParameter, len(stored.Parameters)),
+		Manifest:    stored.Manifest,
+		CreatedAt:   &stored.CreatedAt,
+	}
+	for i, param := range stored.Parameters {
+		response.Parameters[i] = models.TemplateParameter(param)
+	}
+	return response
+}
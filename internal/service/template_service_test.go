@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func testWorkerTemplate() *models.CreateResourceTemplateRequest {
+	return &models.CreateResourceTemplateRequest{
+		Name:        "worker",
+		Description: "Queue worker with its settings",
+		Parameters: []models.TemplateParameter{
+			{Name: "name", Required: true},
+			{Name: "queue", Required: true},
+			{Name: "replicas", Default: "2"},
+		},
+		Manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .name }}-config
+data:
+  queue: {{ printf "%q" .queue }}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .name }}
+  namespace: ignored
+spec:
+  replicas: {{ .replicas }}
+  selector:
+    matchLabels:
+      app: {{ .name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .name }}
+    spec:
+      containers:
+        - name: worker
+          image: busybox
+`,
+	}
+}
+
+func TestTemplateService_RenderWithParams(t *testing.T) {
+	svc := NewTemplateService(store.NewMemoryStore())
+	created, err := svc.CreateTemplate(testWorkerTemplate(), 1)
+	require.NoError(t, err)
+	assert.False(t, created.BuiltIn)
+
+	objects, err := svc.Render(created, map[string]string{"name": "mailer", "queue": "emails: high"})
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "mailer-config", objects[0].GetName())
+	queue, _, _ := unstructured.NestedString(objects[0].Object, "data", "queue")
+	assert.Equal(t, "emails: high", queue)
+	replicas, _, _ := unstructured.NestedFieldNoCopy(objects[1].Object, "spec", "replicas")
+	assert.EqualValues(t, 2, replicas, "optional parameters take their default")
+
+	_, err = svc.Render(created, map[string]string{"name": "mailer"})
+	assert.True(t, errors.Is(err, ErrInvalidTemplateParams))
+	assert.Contains(t, err.Error(), "queue")
+
+	_, err = svc.Render(created, map[string]string{"name": "mailer", "queue": "q", "image": "x"})
+	assert.True(t, errors.Is(err, ErrInvalidTemplateParams))
+	assert.Contains(t, err.Error(), "image")
+}
+
+func TestTemplateService_RenderKeepsParamsInTheirValues(t *testing.T) {
+	svc := NewTemplateService(store.NewMemoryStore())
+	created, err := svc.CreateTemplate(testWorkerTemplate(), 1)
+	require.NoError(t, err)
+
+	objects, err := svc.Render(created, map[string]string{
+		"name":     "mailer\n  namespace: kube-system",
+		"queue":    "q\"\n---\nkind: Secret",
+		"replicas": "{replicas: 10}",
+	})
+	require.NoError(t, err)
+	require.Len(t, objects, 2, "a value can't add objects")
+	assert.Equal(t, "mailer\n  namespace: kube-system-config", objects[0].GetName())
+	assert.Empty(t, objects[0].GetNamespace(), "a value can't add fields")
+	queue, _, _ := unstructured.NestedString(objects[0].Object, "data", "queue")
+	assert.Equal(t, "q\"\n---\nkind: Secret", queue)
+	replicas, _, _ := unstructured.NestedFieldNoCopy(objects[1].Object, "spec", "replicas")
+	assert.Equal(t, "{replicas: 10}", replicas, "a value can't add a mapping")
+
+	objects, err = svc.Render(created, map[string]string{"name": "mailer", "queue": "q", "replicas": "3"})
+	require.NoError(t, err)
+	replicas, _, _ = unstructured.NestedFieldNoCopy(objects[1].Object, "spec", "replicas")
+	assert.EqualValues(t, 3, replicas, "a scalar made of one parameter is typed by YAML")
+}
+
+func TestTemplateService_CreateValidation(t *testing.T) {
+	svc := NewTemplateService(store.NewMemoryStore())
+	_, err := svc.CreateTemplate(testWorkerTemplate(), 1)
+	require.NoError(t, err)
+
+	_, err = svc.CreateTemplate(testWorkerTemplate(), 1)
+	assert.True(t, errors.Is(err, ErrTemplateExists))
+
+	builtIn := testWorkerTemplate()
+	builtIn.Name = "nginx"
+	_, err = svc.CreateTemplate(builtIn, 1)
+	assert.True(t, errors.Is(err, ErrTemplateExists))
+
+	undeclared := testWorkerTemplate()
+	undeclared.Name = "undeclared"
+	undeclared.Parameters = undeclared.Parameters[:2]
+	_, err = svc.CreateTemplate(undeclared, 1)
+	assert.True(t, errors.Is(err, ErrInvalidTemplate), "replicas is used without being declared")
+
+	badSyntax := testWorkerTemplate()
+	badSyntax.Name = "bad-syntax"
+	badSyntax.Manifest = "kind: {{ .name"
+	_, err = svc.CreateTemplate(badSyntax, 1)
+	assert.True(t, errors.Is(err, ErrInvalidTemplate))
+
+	badName := testWorkerTemplate()
+	badName.Name = "Worker_1"
+	_, err = svc.CreateTemplate(badName, 1)
+	assert.True(t, errors.Is(err, ErrInvalidTemplate))
+
+	templates, err := svc.ListTemplates()
+	require.NoError(t, err)
+	names := make([]string, len(templates))
+	for i, tmpl := range templates {
+		names[i] = tmpl.Name
+	}
+	assert.Equal(t, []string{"configmap", "nginx", "worker"}, names)
+}
+
+func TestTemplateService_Instantiate(t *testing.T) {
+	svc := NewTemplateService(store.NewMemoryStore())
+	_, err := svc.CreateTemplate(testWorkerTemplate(), 1)
+	require.NoError(t, err)
+	client, mapper := newTestImportEnv()
+
+	result, err := svc.Instantiate(context.Background(), client, mapper, "worker", "jobs",
+		map[string]string{"name": "mailer", "queue": "emails", "replicas": "3"}, ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Applied)
+	assert.Zero(t, result.Failed)
+
+	deployment, err := client.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}).
+		Namespace("jobs").Get(context.Background(), "mailer", metav1.GetOptions{})
+	require.NoError(t, err, "the namespace in the manifest is replaced")
+	replicas, _, _ := unstructured.NestedFieldNoCopy(deployment.Object, "spec", "replicas")
+	assert.EqualValues(t, 3, replicas)
+	_, err = client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+		Namespace("jobs").Get(context.Background(), "mailer-config", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	denied, err := svc.Instantiate(context.Background(), client, mapper, "nginx", "jobs", map[string]string{"name": "web"},
+		ImportOptions{Allowed: func(resource string) bool { return resource != "services" }})
+	require.NoError(t, err)
+	assert.Equal(t, 1, denied.Applied)
+	assert.Equal(t, 1, denied.Failed)
+	assert.Equal(t, ImportFailed, denied.Objects[1].Status)
+
+	_, err = svc.Instantiate(context.Background(), client, mapper, "missing", "jobs", nil, ImportOptions{})
+	assert.True(t, errors.Is(err, ErrTemplateNotFound))
+}
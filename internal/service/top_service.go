@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// ErrMetricsUnavailable is returned when the metrics API is not served by the cluster
+var ErrMetricsUnavailable = errors.New("metrics API is not available, please confirm that metrics-server is installed and running")
+
+// Sort fields supported by the top endpoints
+const (
+	TopSortByCPU    = "cpu"
+	TopSortByMemory = "memory"
+)
+
+// TopOptions selects and orders the objects returned by the top endpoints
+type TopOptions struct {
+	SortBy    string // cpu (default) or memory
+	Namespace string // Empty for all namespaces, ignored for nodes
+	Limit     int    // Zero or less returns every object
+}
+
+// TopPod is the current usage of a pod joined with the requests and limits of its containers
+type TopPod struct {
+	Namespace          string   `json:"namespace"`
+	Name               string   `json:"name"`
+	NodeName           string   `json:"nodeName,omitempty"`
+	CPUMilli           int64    `json:"cpuMilli"`
+	MemoryBytes        int64    `json:"memoryBytes"`
+	CPU                string   `json:"cpu"`    // Formatted usage, e.g. "250m"
+	Memory             string   `json:"memory"` // Formatted usage, e.g. "128Mi"
+	CPURequestMilli    int64    `json:"cpuRequestMilli"`
+	CPULimitMilli      int64    `json:"cpuLimitMilli"`
+	MemoryRequestBytes int64    `json:"memoryRequestBytes"`
+	MemoryLimitBytes   int64    `json:"memoryLimitBytes"`
+	CPURequestRatio    *float64 `json:"cpuRequestRatio,omitempty"`    // Usage divided by request, unset without a request
+	MemoryRequestRatio *float64 `json:"memoryRequestRatio,omitempty"` // Usage divided by request, unset without a request
+}
+
+// TopNode is the current usage of a node joined with its allocatable resources and the requests of its pods
+type TopNode struct {
+	Name                   string  `json:"name"`
+	CPUMilli               int64   `json:"cpuMilli"`
+	MemoryBytes            int64   `json:"memoryBytes"`
+	CPU                    string  `json:"cpu"`
+	Memory                 string  `json:"memory"`
+	CPUAllocatableMilli    int64   `json:"cpuAllocatableMilli"`
+	MemoryAllocatableBytes int64   `json:"memoryAllocatableBytes"`
+	CPUPercent             float64 `json:"cpuPercent"`
+	MemoryPercent          float64 `json:"memoryPercent"`
+	CPURequestMilli        int64   `json:"cpuRequestMilli"`
+	MemoryRequestBytes     int64   `json:"memoryRequestBytes"`
+}
+
+// TopPodsResponse lists the heaviest pods
+type TopPodsResponse struct {
+	Items  []TopPod `json:"items"`
+	SortBy string   `json:"sortBy"`
+	Total  int      `json:"total"` // Number of pods with metrics before applying the limit
+}
+
+// TopNodesResponse lists the heaviest nodes
+type TopNodesResponse struct {
+	Items  []TopNode `json:"items"`
+	SortBy string    `json:"sortBy"`
+	Total  int       `json:"total"` // Number of nodes with metrics before applying the limit
+}
+
+// TopService ranks pods and nodes by their current usage reported by metrics-server
+type TopService struct{}
+
+// NewTopService creates a new TopService instance
+func NewTopService() *TopService {
+	return &TopService{}
+}
+
+// ValidateTopSortBy checks the sort field of a top request
+func ValidateTopSortBy(sortBy string) error {
+	switch sortBy {
+	case "", TopSortByCPU, TopSortByMemory:
+		return nil
+	}
+	return fmt.Errorf("unsupported sortBy %q, expected %s or %s", sortBy, TopSortByCPU, TopSortByMemory)
+}
+
+// TopPods returns pods sorted by usage together with their requests and limits
+func (s *TopService) TopPods(metricsClient versioned.Interface, clientset kubernetes.Interface, opts TopOptions) (*TopPodsResponse, error) {
+	if err := ValidateTopSortBy(opts.SortBy); err != nil {
+		return nil, err
+	}
+	sortBy := topSortField(opts.SortBy)
+
+	ctx := context.TODO()
+	podMetrics, err := metricsClient.MetricsV1beta1().PodMetricses(opts.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, metricsError(err)
+	}
+	pods, err := clientset.CoreV1().Pods(opts.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	podsByKey := make(map[string]*corev1.Pod, len(pods.Items))
+	for i := range pods.Items {
+		podsByKey[pods.Items[i].Namespace+"/"+pods.Items[i].Name] = &pods.Items[i]
+	}
+
+	items := make([]TopPod, 0, len(podMetrics.Items))
+	for _, metrics := range podMetrics.Items {
+		var cpu, memory resource.Quantity
+		for _, container := range metrics.Containers {
+			cpu.Add(*container.Usage.Cpu())
+			memory.Add(*container.Usage.Memory())
+		}
+
+		item := TopPod{
+			Namespace:   metrics.Namespace,
+			Name:        metrics.Name,
+			CPUMilli:    cpu.MilliValue(),
+			MemoryBytes: memory.Value(),
+			CPU:         formatCPU(cpu.MilliValue()),
+			Memory:      formatMemory(memory.Value()),
+		}
+		if pod, ok := podsByKey[metrics.Namespace+"/"+metrics.Name]; ok {
+			item.NodeName = pod.Spec.NodeName
+			requests, limits := podContainerResources(pod)
+			item.CPURequestMilli = requests.Cpu().MilliValue()
+			item.MemoryRequestBytes = requests.Memory().Value()
+			item.CPULimitMilli = limits.Cpu().MilliValue()
+			item.MemoryLimitBytes = limits.Memory().Value()
+			item.CPURequestRatio = usageRatio(item.CPUMilli, item.CPURequestMilli)
+			item.MemoryRequestRatio = usageRatio(item.MemoryBytes, item.MemoryRequestBytes)
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		av, bv := a.CPUMilli, b.CPUMilli
+		if sortBy == TopSortByMemory {
+			av, bv = a.MemoryBytes, b.MemoryBytes
+		}
+		if av != bv {
+			return av > bv
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	total := len(items)
+	if opts.Limit > 0 && len(items) > opts.Limit {
+		items = items[:opts.Limit]
+	}
+	return &TopPodsResponse{Items: items, SortBy: sortBy, Total: total}, nil
+}
+
+// TopNodes returns nodes sorted by usage together with their allocatable resources and pod requests
+func (s *TopService) TopNodes(metricsClient versioned.Interface, clientset kubernetes.Interface, opts TopOptions) (*TopNodesResponse, error) {
+	if err := ValidateTopSortBy(opts.SortBy); err != nil {
+		return nil, err
+	}
+	sortBy := topSortField(opts.SortBy)
+
+	ctx := context.TODO()
+	nodeMetrics, err := metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, metricsError(err)
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	nodesByName := make(map[string]*corev1.Node, len(nodes.Items))
+	for i := range nodes.Items {
+		nodesByName[nodes.Items[i].Name] = &nodes.Items[i]
+	}
+	requestsByNode := make(map[string]corev1.ResourceList)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		requests, _ := podContainerResources(pod)
+		total := requestsByNode[pod.Spec.NodeName]
+		if total == nil {
+			total = corev1.ResourceList{}
+			requestsByNode[pod.Spec.NodeName] = total
+		}
+		addResourceList(total, requests)
+	}
+
+	items := make([]TopNode, 0, len(nodeMetrics.Items))
+	for _, metrics := range nodeMetrics.Items {
+		cpu, memory := metrics.Usage.Cpu(), metrics.Usage.Memory()
+		item := TopNode{
+			Name:        metrics.Name,
+			CPUMilli:    cpu.MilliValue(),
+			MemoryBytes: memory.Value(),
+			CPU:         formatCPU(cpu.MilliValue()),
+			Memory:      formatMemory(memory.Value()),
+		}
+		if node, ok := nodesByName[metrics.Name]; ok {
+			item.CPUAllocatableMilli = node.Status.Allocatable.Cpu().MilliValue()
+			item.MemoryAllocatableBytes = node.Status.Allocatable.Memory().Value()
+			item.CPUPercent = usagePercent(item.CPUMilli, item.CPUAllocatableMilli)
+			item.MemoryPercent = usagePercent(item.MemoryBytes, item.MemoryAllocatableBytes)
+		}
+		if requests, ok := requestsByNode[metrics.Name]; ok {
+			item.CPURequestMilli = requests.Cpu().MilliValue()
+			item.MemoryRequestBytes = requests.Memory().Value()
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		av, bv := a.CPUMilli, b.CPUMilli
+		if sortBy == TopSortByMemory {
+			av, bv = a.MemoryBytes, b.MemoryBytes
+		}
+		if av != bv {
+			return av > bv
+		}
+		return a.Name < b.Name
+	})
+
+	total := len(items)
+	if opts.Limit > 0 && len(items) > opts.Limit {
+		items = items[:opts.Limit]
+	}
+	return &TopNodesResponse{Items: items, SortBy: sortBy, Total: total}, nil
+}
+
+func topSortField(sortBy string) string {
+	if sortBy == "" {
+		return TopSortByCPU
+	}
+	return sortBy
+}
+
+// metricsError marks errors caused by a missing or unhealthy metrics API
+func metricsError(err error) error {
+	if k8serrors.IsNotFound(err) || k8serrors.IsServiceUnavailable(err) || k8serrors.IsTimeout(err) || meta.IsNoMatchError(err) {
+		return fmt.Errorf("%w: %v", ErrMetricsUnavailable, err)
+	}
+	return fmt.Errorf("failed to get metrics: %w", err)
+}
+
+// podContainerResources sums the requests and limits of the containers of a pod
+func podContainerResources(pod *corev1.Pod) (corev1.ResourceList, corev1.ResourceList) {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResourceList(requests, container.Resources.Requests)
+		addResourceList(limits, container.Resources.Limits)
+	}
+	return requests, limits
+}
+
+func addResourceList(total, add corev1.ResourceList) {
+	for name, quantity := range add {
+		if current, ok := total[name]; ok {
+			current.Add(quantity)
+			total[name] = current
+		} else {
+			total[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// usageRatio returns usage divided by request, or nil when nothing is requested
+func usageRatio(usage, request int64) *float64 {
+	if request == 0 {
+		return nil
+	}
+	ratio := float64(usage) / float64(request)
+	return &ratio
+}
+
+// usagePercent returns usage as a percentage of capacity rounded to one decimal
+func usagePercent(usage, capacity int64) float64 {
+	if capacity == 0 {
+		return 0
+	}
+	return float64(usage*1000/capacity) / 10
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func testPodMetrics(namespace, name, cpu, memory string) metricsv1beta1.PodMetrics {
+	return metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Containers: []metricsv1beta1.ContainerMetrics{{
+			Name:  "app",
+			Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+		}},
+	}
+}
+
+func testTopPod(namespace, name, cpuRequest, memoryRequest string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpuRequest), corev1.ResourceMemory: resource.MustParse(memoryRequest)},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			}},
+		},
+	}
+}
+
+// newTestMetricsClient returns a fake metrics client serving the given pod metrics.
+// The fake tracker stores PodMetrics under the wrong resource name, so lists are answered by a reactor.
+func newTestMetricsClient(podMetrics ...metricsv1beta1.PodMetrics) *metricsfake.Clientset {
+	client := metricsfake.NewSimpleClientset()
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		list := &metricsv1beta1.PodMetricsList{}
+		for _, item := range podMetrics {
+			if ns := action.GetNamespace(); ns == "" || ns == item.Namespace {
+				list.Items = append(list.Items, item)
+			}
+		}
+		return true, list, nil
+	})
+	return client
+}
+
+func TestTopService_TopPods(t *testing.T) {
+	metricsClient := newTestMetricsClient(
+		testPodMetrics("default", "web", "200m", "300Mi"),
+		testPodMetrics("default", "db", "800m", "100Mi"),
+		testPodMetrics("batch", "job", "50m", "900Mi"),
+	)
+	clientset := fake.NewSimpleClientset(
+		testTopPod("default", "web", "100m", "600Mi"),
+		testTopPod("default", "db", "1", "200Mi"),
+	)
+	svc := NewTopService()
+
+	result, err := svc.TopPods(metricsClient, clientset, TopOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, TopSortByCPU, result.SortBy)
+	require.Len(t, result.Items, 3)
+	assert.Equal(t, []string{"db", "web", "job"}, []string{result.Items[0].Name, result.Items[1].Name, result.Items[2].Name})
+
+	web := result.Items[1]
+	assert.Equal(t, int64(200), web.CPUMilli)
+	assert.Equal(t, int64(100), web.CPURequestMilli)
+	assert.Equal(t, int64(1<<30), web.MemoryLimitBytes)
+	assert.Equal(t, "node-1", web.NodeName)
+	require.NotNil(t, web.CPURequestRatio)
+	assert.InDelta(t, 2.0, *web.CPURequestRatio, 0.001, "web uses twice its CPU request")
+	assert.InDelta(t, 0.5, *web.MemoryRequestRatio, 0.001)
+	assert.Nil(t, result.Items[2].CPURequestRatio, "pods without a spec have no ratio")
+
+	result, err = svc.TopPods(metricsClient, clientset, TopOptions{SortBy: TopSortByMemory, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Total)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, "job", result.Items[0].Name)
+	assert.Equal(t, "web", result.Items[1].Name)
+
+	result, err = svc.TopPods(metricsClient, clientset, TopOptions{Namespace: "default"})
+	require.NoError(t, err)
+	assert.Len(t, result.Items, 2)
+
+	_, err = svc.TopPods(metricsClient, clientset, TopOptions{SortBy: "disk"})
+	assert.Error(t, err)
+}
+
+func TestTopService_MetricsUnavailable(t *testing.T) {
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewServiceUnavailable("the server is currently unable to handle the request")
+	})
+	svc := NewTopService()
+
+	_, err := svc.TopPods(metricsClient, fake.NewSimpleClientset(), TopOptions{})
+	assert.ErrorIs(t, err, ErrMetricsUnavailable)
+
+	metricsClient = metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, k8serrors.NewNotFound(schema.GroupResource{Group: "metrics.k8s.io", Resource: "nodes"}, "")
+	})
+	_, err = svc.TopNodes(metricsClient, fake.NewSimpleClientset(), TopOptions{})
+	assert.ErrorIs(t, err, ErrMetricsUnavailable)
+}
+
+func TestTopService_TopNodes(t *testing.T) {
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.NodeMetricsList{Items: []metricsv1beta1.NodeMetrics{
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3"), corev1.ResourceMemory: resource.MustParse("512Mi")}},
+		}}, nil
+	})
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("4Gi"),
+		}},
+	}
+	clientset := fake.NewSimpleClientset(node, testTopPod("default", "web", "500m", "256Mi"))
+
+	result, err := NewTopService().TopNodes(metricsClient, clientset, TopOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, "node-2", result.Items[0].Name)
+
+	node1 := result.Items[1]
+	assert.Equal(t, 25.0, node1.CPUPercent)
+	assert.Equal(t, 25.0, node1.MemoryPercent)
+	assert.Equal(t, int64(500), node1.CPURequestMilli)
+}
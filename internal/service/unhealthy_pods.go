@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DefaultRestartThreshold is the number of restarts from which a pod is reported as unhealthy when the
+// request doesn't set one
+const DefaultRestartThreshold = 5
+
+// backOffReasons are the waiting reasons of a container that can't start
+var backOffReasons = map[string]bool{
+	"CrashLoopBackOff": true,
+	"ImagePullBackOff": true,
+	"ErrImagePull":     true,
+}
+
+// UnhealthyPods lists from the informer cache the pods of a namespace that are not ready, have a container
+// in CrashLoopBackOff or ImagePullBackOff, or restarted at least restartThreshold times, sorted by restart
+// count descending. Succeeded pods are done, not unhealthy, and left out.
+func (s *NamespaceSummaryService) UnhealthyPods(listers *NamespaceSummaryListers, namespace string, restartThreshold int32) (*models.UnhealthyPodReport, error) {
+	if restartThreshold <= 0 {
+		return nil, fmt.Errorf("restart threshold must be positive, got %d", restartThreshold)
+	}
+	if _, err := listers.Namespaces.Get(namespace); err != nil {
+		return nil, err
+	}
+	pods, err := listers.Pods.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	report := &models.UnhealthyPodReport{Namespace: namespace, RestartThreshold: restartThreshold, Pods: []models.UnhealthyPod{}}
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+		if item, unhealthy := unhealthyPod(pod, restartThreshold); unhealthy {
+			report.Pods = append(report.Pods, item)
+		}
+	}
+	sort.Slice(report.Pods, func(i, j int) bool {
+		if report.Pods[i].RestartCount != report.Pods[j].RestartCount {
+			return report.Pods[i].RestartCount > report.Pods[j].RestartCount
+		}
+		return report.Pods[i].Name < report.Pods[j].Name
+	})
+	return report, nil
+}
+
+// unhealthyPod reports whether a pod is unhealthy and why
+func unhealthyPod(pod *corev1.Pod, restartThreshold int32) (models.UnhealthyPod, bool) {
+	item := models.UnhealthyPod{
+		Name:       pod.Name,
+		Phase:      string(pod.Status.Phase),
+		Node:       pod.Spec.NodeName,
+		Ready:      isPodReady(pod),
+		Reasons:    []string{},
+		Containers: []models.UnhealthyContainer{},
+	}
+	if !item.Ready {
+		item.Reasons = append(item.Reasons, models.UnhealthyPodNotReady)
+	}
+
+	seenReasons := make(map[string]bool)
+	addStatuses := func(statuses []corev1.ContainerStatus, init bool) {
+		for _, status := range statuses {
+			item.RestartCount += status.RestartCount
+			if waiting := status.State.Waiting; waiting != nil && backOffReasons[waiting.Reason] && !seenReasons[waiting.Reason] {
+				seenReasons[waiting.Reason] = true
+				item.Reasons = append(item.Reasons, waiting.Reason)
+			}
+			if !status.Ready || status.RestartCount > 0 {
+				item.Containers = append(item.Containers, models.UnhealthyContainer{Name: status.Name, Init: init, ContainerStatusInfo: containerStatusInfo(status)})
+			}
+		}
+	}
+	addStatuses(pod.Status.InitContainerStatuses, true)
+	addStatuses(pod.Status.ContainerStatuses, false)
+
+	if item.RestartCount >= restartThreshold {
+		item.Reasons = append(item.Reasons, models.UnhealthyPodHighRestarts)
+	}
+	return item, len(item.Reasons) > 0
+}
+
+// containerStatusInfo describes the current state and the last termination of a container
+func containerStatusInfo(status corev1.ContainerStatus) models.ContainerStatusInfo {
+	info := models.ContainerStatusInfo{
+		Ready:        status.Ready,
+		RestartCount: status.RestartCount,
+	}
+	switch {
+	case status.State.Waiting != nil:
+		info.State = "waiting"
+		info.Reason = status.State.Waiting.Reason
+		info.Message = status.State.Waiting.Message
+	case status.State.Terminated != nil:
+		info.State = "terminated"
+		info.Reason = status.State.Terminated.Reason
+		info.Message = status.State.Terminated.Message
+	case status.State.Running != nil:
+		info.State = "running"
+	}
+	if last := status.LastTerminationState.Terminated; last != nil {
+		exitCode := last.ExitCode
+		info.LastExitCode = &exitCode
+		info.LastReason = last.Reason
+		info.LastMessage = last.Message
+		if !last.FinishedAt.IsZero() {
+			finished := last.FinishedAt.Time
+			info.LastFinished = &finished
+		}
+	}
+	return info
+}
+
+// isPodReady reports whether the Ready condition of a pod is true
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
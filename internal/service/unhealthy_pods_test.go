@@ -0,0 +1,124 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testStatusPod(name string, phase corev1.PodPhase, ready bool, statuses ...corev1.ContainerStatus) *corev1.Pod {
+	readyStatus := corev1.ConditionTrue
+	if !ready {
+		readyStatus = corev1.ConditionFalse
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: name},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			Phase:             phase,
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+			ContainerStatuses: statuses,
+		},
+	}
+}
+
+func runningContainer(name string, restarts int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name: name, Ready: true, RestartCount: restarts,
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}
+}
+
+func waitingContainer(name, reason string, restarts int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name: name, RestartCount: restarts,
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: reason + " message"}},
+	}
+}
+
+func TestNamespaceSummaryService_UnhealthyPods(t *testing.T) {
+	finishedAt := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	crashing := waitingContainer("app", "CrashLoopBackOff", 12)
+	crashing.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{
+		ExitCode: 137, Reason: "OOMKilled", Message: "out of memory", FinishedAt: metav1.NewTime(finishedAt),
+	}
+	restarted := runningContainer("app", 7)
+	restarted.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		testStatusPod("healthy", corev1.PodRunning, true, runningContainer("app", 0)),
+		testStatusPod("few-restarts", corev1.PodRunning, true, runningContainer("app", 2)),
+		testStatusPod("crashloop", corev1.PodRunning, false, crashing, runningContainer("sidecar", 0)),
+		testStatusPod("image", corev1.PodPending, false, waitingContainer("app", "ImagePullBackOff", 0)),
+		testStatusPod("flapping", corev1.PodRunning, true, restarted, runningContainer("sidecar", 1)),
+		testStatusPod("starting", corev1.PodPending, false),
+		testStatusPod("done", corev1.PodSucceeded, false),
+	)
+	informers := k8s.NewInformerRegistry()
+	t.Cleanup(func() { informers.Stop("test") })
+	svc := NewNamespaceSummaryService(informers)
+	listers, err := svc.ListersFor("test", clientset)
+	require.NoError(t, err)
+
+	report, err := svc.UnhealthyPods(listers, "team-a", DefaultRestartThreshold)
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", report.Namespace)
+	assert.EqualValues(t, DefaultRestartThreshold, report.RestartThreshold)
+
+	names := make([]string, 0, len(report.Pods))
+	reasons := make(map[string][]string)
+	for _, pod := range report.Pods {
+		names = append(names, pod.Name)
+		reasons[pod.Name] = pod.Reasons
+	}
+	assert.Equal(t, []string{"crashloop", "flapping", "image", "starting"}, names, "most restarted first, then by name")
+	assert.Equal(t, map[string][]string{
+		"crashloop": {models.UnhealthyPodNotReady, "CrashLoopBackOff", models.UnhealthyPodHighRestarts},
+		"flapping":  {models.UnhealthyPodHighRestarts},
+		"image":     {models.UnhealthyPodNotReady, "ImagePullBackOff"},
+		"starting":  {models.UnhealthyPodNotReady},
+	}, reasons)
+
+	crashloop := report.Pods[0]
+	assert.EqualValues(t, 12, crashloop.RestartCount)
+	require.Len(t, crashloop.Containers, 1, "the ready sidecar that never restarted is left out")
+	container := crashloop.Containers[0]
+	assert.Equal(t, "waiting", container.State)
+	assert.Equal(t, "CrashLoopBackOff", container.Reason)
+	require.NotNil(t, container.LastExitCode)
+	assert.EqualValues(t, 137, *container.LastExitCode)
+	assert.Equal(t, "OOMKilled", container.LastReason)
+	assert.Equal(t, "out of memory", container.LastMessage)
+	require.NotNil(t, container.LastFinished)
+	assert.True(t, finishedAt.Equal(*container.LastFinished))
+
+	flapping := report.Pods[1]
+	assert.True(t, flapping.Ready)
+	assert.EqualValues(t, 8, flapping.RestartCount, "restarts of all containers")
+	assert.Len(t, flapping.Containers, 2)
+
+	t.Run("threshold", func(t *testing.T) {
+		report, err := svc.UnhealthyPods(listers, "team-a", 2)
+		require.NoError(t, err)
+		require.Len(t, report.Pods, 5)
+		assert.Equal(t, "few-restarts", report.Pods[2].Name)
+		assert.Equal(t, []string{models.UnhealthyPodHighRestarts}, report.Pods[2].Reasons)
+
+		_, err = svc.UnhealthyPods(listers, "team-a", 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing namespace", func(t *testing.T) {
+		_, err := svc.UnhealthyPods(listers, "missing", DefaultRestartThreshold)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+}
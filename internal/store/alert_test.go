@@ -0,0 +1,59 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testListAlerts(t *testing.T, s Store) {
+	// Two alerts share each last seen time, so pages must be ordered by ID too
+	base := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+	for i := 0; i < 6; i++ {
+		require.NoError(t, s.CreateAlert(&Alert{Level: "warning", Type: "pod_restarts", LastSeen: base.Add(time.Duration(i/2) * time.Minute)}))
+	}
+
+	all, total, err := s.ListAlerts(AlertFilter{Type: "pod_restarts"}, 0, 10)
+	require.NoError(t, err)
+	require.EqualValues(t, 6, total)
+	for i := 1; i < len(all); i++ {
+		previous := CursorOf(all[i-1])
+		assert.False(t, previous.Precedes(all[i]), "alerts are listed newest first, then by descending ID")
+	}
+
+	var listed []uint
+	filter := AlertFilter{Type: "pod_restarts"}
+	for {
+		page, total, err := s.ListAlerts(filter, 0, 4)
+		require.NoError(t, err)
+		assert.EqualValues(t, 6, total, "the cursor doesn't narrow the total")
+		for _, alert := range page {
+			listed = append(listed, alert.ID)
+		}
+		if len(page) < 4 {
+			break
+		}
+		cursor := CursorOf(page[len(page)-1])
+		filter.After = &cursor
+	}
+	want := make([]uint, 0, len(all))
+	for _, alert := range all {
+		want = append(want, alert.ID)
+	}
+	assert.Equal(t, want, listed, "pages continue after the cursor without gaps or repeats")
+
+	window, total, err := s.ListAlerts(AlertFilter{Type: "pod_restarts", Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)}, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total, "since is inclusive and until exclusive")
+	assert.Len(t, window, 2)
+}
+
+func TestMemoryStore_ListAlerts(t *testing.T) {
+	testListAlerts(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_ListAlerts(t *testing.T) {
+	testListAlerts(t, newTestDatabaseStore(t))
+}
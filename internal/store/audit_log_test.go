@@ -0,0 +1,77 @@
+package store
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGroupAuditLogs(t *testing.T, s Store) {
+	base := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+	alice, bob := uint(1), uint(2)
+	entries := []*AuditLog{
+		{UserID: &alice, Action: "resource_update", ResourceID: "scale", Namespace: "prod", CreatedAt: base.Add(-time.Minute)},
+		{UserID: &alice, Action: "resource_update", ResourceID: "scale", Namespace: "prod", CreatedAt: base},
+		{UserID: &alice, Action: "resource_update", ResourceID: "scale", Namespace: "prod", Failed: true, CreatedAt: base.Add(time.Minute)},
+		{UserID: &alice, Action: "resource_update", ResourceID: "scale", Namespace: "dev", CreatedAt: base.Add(time.Minute)},
+		{UserID: &alice, Action: "resource_delete", ResourceID: "delete", Namespace: "prod", CreatedAt: base.Add(2 * time.Minute)},
+		{UserID: &bob, Action: "resource_update", ResourceID: "restart", Namespace: "dev", CreatedAt: base.Add(2 * time.Minute)},
+		{Action: "resource_create", ResourceID: "create", Namespace: "dev", CreatedAt: base.Add(2 * time.Minute)},
+		{UserID: &bob, Action: "login", Failed: true, CreatedAt: base.Add(2 * time.Minute)},
+		{UserID: &bob, Action: "resource_update", ResourceID: "patch", Namespace: "dev", CreatedAt: base.Add(time.Hour)},
+	}
+	for _, entry := range entries {
+		require.NoError(t, s.CreateAuditLog(entry))
+	}
+
+	groups, err := s.GroupAuditLogs(AuditLogFilter{
+		Actions: []string{"resource_create", "resource_update", "resource_delete"},
+		Since:   base,
+		Until:   base.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	type row struct {
+		UserID                int
+		ResourceID, Namespace string
+		Count, Failed         int64
+	}
+	rows := make([]row, 0, len(groups))
+	for _, group := range groups {
+		r := row{ResourceID: group.ResourceID, Namespace: group.Namespace, Count: group.Count, Failed: group.Failed}
+		if group.UserID != nil {
+			r.UserID = int(*group.UserID)
+		}
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].UserID != rows[j].UserID {
+			return rows[i].UserID < rows[j].UserID
+		}
+		if rows[i].ResourceID != rows[j].ResourceID {
+			return rows[i].ResourceID < rows[j].ResourceID
+		}
+		return rows[i].Namespace < rows[j].Namespace
+	})
+	assert.Equal(t, []row{
+		{UserID: 0, ResourceID: "create", Namespace: "dev", Count: 1},
+		{UserID: 1, ResourceID: "delete", Namespace: "prod", Count: 1},
+		{UserID: 1, ResourceID: "scale", Namespace: "dev", Count: 1},
+		{UserID: 1, ResourceID: "scale", Namespace: "prod", Count: 2, Failed: 1},
+		{UserID: 2, ResourceID: "restart", Namespace: "dev", Count: 1},
+	}, rows)
+
+	groups, err = s.GroupAuditLogs(AuditLogFilter{UserID: &bob})
+	require.NoError(t, err)
+	assert.Len(t, groups, 3, "without other filters every entry of the user is grouped")
+}
+
+func TestMemoryStore_GroupAuditLogs(t *testing.T) {
+	testGroupAuditLogs(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_GroupAuditLogs(t *testing.T) {
+	testGroupAuditLogs(t, newTestDatabaseStore(t))
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/ciliverse/cilikube/configs"
+)
+
+// adminBootstrap is the admin account Initialize creates when a store has no users
+type adminBootstrap struct {
+	config configs.AdminConfig
+	mode   string // Server mode, release mode refuses the insecure default password
+}
+
+// newAdminBootstrap returns the admin bootstrap settings of config
+func newAdminBootstrap(config *configs.Config) adminBootstrap {
+	return adminBootstrap{config: config.Admin, mode: config.Server.Mode}
+}
+
+// user returns the admin user to create with a hashed password, required to change it on first login
+func (b adminBootstrap) user() (*User, error) {
+	admin := b.config.WithDefaults()
+	if err := admin.Validate(b.mode); err != nil {
+		return nil, err
+	}
+
+	user := &User{
+		Username:           admin.Username,
+		Email:              admin.Email,
+		DisplayName:        "System Administrator",
+		IsActive:           true,
+		EmailVerified:      true,
+		MustChangePassword: true,
+	}
+	if err := user.HashPassword(admin.Password); err != nil {
+		return nil, fmt.Errorf("failed to hash admin password: %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newUninitializedStores returns an empty memory store and an empty migrated database store with admin settings
+func newUninitializedStores(t *testing.T, admin adminBootstrap) map[string]Store {
+	t.Helper()
+	t.Setenv("CILIKUBE_ADMIN_USERNAME", "")
+	t.Setenv("CILIKUBE_ADMIN_EMAIL", "")
+	t.Setenv("CILIKUBE_ADMIN_PASSWORD", "")
+
+	memoryStore := NewMemoryStore().(*MemoryStore)
+	memoryStore.admin = admin
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&User{}, &Role{}, &UserRole{}))
+	databaseStore := &DatabaseStore{db: db, admin: admin}
+
+	return map[string]Store{"memory": memoryStore, "database": databaseStore}
+}
+
+func TestInitialize_BootstrapAdmin(t *testing.T) {
+	admin := adminBootstrap{
+		config: configs.AdminConfig{Username: "root", Email: "root@example.com", Password: "s3cure-password"},
+		mode:   "release",
+	}
+	for name, s := range newUninitializedStores(t, admin) {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, s.Initialize())
+
+			user, err := s.GetUserByUsername("root")
+			require.NoError(t, err)
+			assert.Equal(t, "root@example.com", user.Email)
+			assert.True(t, user.CheckPassword("s3cure-password"))
+			assert.True(t, user.MustChangePassword)
+			roles, err := s.GetUserRoles(user.ID)
+			require.NoError(t, err)
+			require.Len(t, roles, 1)
+			assert.Equal(t, "admin", roles[0].Name)
+
+			_, err = s.GetUserByUsername("admin")
+			assert.Error(t, err, "the default username is not used")
+		})
+	}
+}
+
+func TestInitialize_ReleaseModeRefusesDefaultPassword(t *testing.T) {
+	for name, s := range newUninitializedStores(t, adminBootstrap{mode: "release"}) {
+		t.Run(name, func(t *testing.T) {
+			err := s.Initialize()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "default admin password")
+
+			users, total, err := s.ListUsers(0, 10)
+			require.NoError(t, err)
+			assert.Empty(t, users)
+			assert.Zero(t, total)
+		})
+	}
+}
+
+func TestInitialize_AdminNotRecreatedWhenUsersExist(t *testing.T) {
+	// Existing users skip the bootstrap, so the default password is no obstacle to starting in release mode
+	for name, s := range newUninitializedStores(t, adminBootstrap{mode: "release"}) {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, s.CreateUser(&User{Username: "alice", Email: "alice@example.com", PasswordHash: "password123", IsActive: true}))
+			require.NoError(t, s.Initialize())
+			require.NoError(t, s.Initialize())
+
+			_, total, err := s.ListUsers(0, 10)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+			_, err = s.GetUserByUsername(configs.DefaultAdminUsername)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,9 +16,13 @@ func NewStore(config *configs.Config) (Store, error) {
 
 	switch storageType {
 	case "memory":
-		return NewMemoryStore(), nil
+		memoryStore := NewMemoryStore().(*MemoryStore)
+		memoryStore.admin = newAdminBootstrap(config)
+		return memoryStore, nil
 	case "database":
 		return NewDatabaseStore(config)
+	case "mongodb":
+		return NewMongoStore(config)
 	default:
 		return nil, fmt.Errorf("unsupported storage type: %s", storageType)
 	}
@@ -33,7 +38,8 @@ func NewDatabaseStore(config *configs.Config) (Store, error) {
 
 	// Create database store
 	store := &DatabaseStore{
-		db: db,
+		db:    db,
+		admin: newAdminBootstrap(config),
 	}
 
 	return store, nil
@@ -41,7 +47,8 @@ func NewDatabaseStore(config *configs.Config) (Store, error) {
 
 // DatabaseStore implements Store interface using GORM
 type DatabaseStore struct {
-	db *gorm.DB
+	db    *gorm.DB
+	admin adminBootstrap
 }
 
 // Initialize implements Store interface for database
@@ -54,6 +61,13 @@ func (s *DatabaseStore) Initialize() error {
 		&UserRole{},
 		&OAuthProvider{},
 		&AuditLog{},
+		&LoginAttempt{},
+		&UserSession{},
+		&Alert{},
+		&UserPreference{},
+		&Invite{},
+		&ResourceTemplate{},
+		&Favorite{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
@@ -80,9 +94,16 @@ func (s *DatabaseStore) Close() error {
 	return sqlDB.Close()
 }
 
-// createDefaultRoles creates the default system roles
-func (s *DatabaseStore) createDefaultRoles() error {
-	roles := []*Role{
+// Transaction implements Store interface using a database transaction
+func (s *DatabaseStore) Transaction(fn func(Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&DatabaseStore{db: tx})
+	})
+}
+
+// defaultRoles returns the system roles every store starts with
+func defaultRoles() []*Role {
+	return []*Role{
 		{
 			Name:        "admin",
 			DisplayName: "Administrator",
@@ -102,8 +123,11 @@ func (s *DatabaseStore) createDefaultRoles() error {
 			IsSystem:    true,
 		},
 	}
+}
 
-	for _, role := range roles {
+// createDefaultRoles creates the default system roles
+func (s *DatabaseStore) createDefaultRoles() error {
+	for _, role := range defaultRoles() {
 		// Check if role already exists
 		var existingRole Role
 		result := s.db.Where("name = ?", role.Name).First(&existingRole)
@@ -120,51 +144,43 @@ func (s *DatabaseStore) createDefaultRoles() error {
 	return nil
 }
 
-// createDefaultAdminUser creates the default admin user
+// createDefaultAdminUser creates the bootstrap admin user when no users exist
 func (s *DatabaseStore) createDefaultAdminUser() error {
-	// Check if admin user already exists
-	var existingUser User
-	result := s.db.Where("username = ?", "admin").First(&existingUser)
-	if result.Error == nil {
-		return nil // Admin user already exists
-	}
-
-	// Create admin user
-	adminUser := &User{
-		Username:      "admin",
-		Email:         "admin@cilikube.com",
-		DisplayName:   "System Administrator",
-		IsActive:      true,
-		EmailVerified: true,
+	var users int64
+	if err := s.db.Model(&User{}).Count(&users).Error; err != nil {
+		return fmt.Errorf("failed to count users: %w", err)
 	}
-
-	// Hash password
-	if err := adminUser.HashPassword("12345678"); err != nil {
-		return fmt.Errorf("failed to hash admin password: %w", err)
+	if users > 0 {
+		return nil // The admin was created before, or users were added another way
 	}
 
-	// Create user
-	if err := s.db.Create(adminUser).Error; err != nil {
-		return fmt.Errorf("failed to create admin user: %w", err)
-	}
-
-	// Get admin role
-	var adminRole Role
-	if err := s.db.Where("name = ?", "admin").First(&adminRole).Error; err != nil {
-		return fmt.Errorf("failed to find admin role: %w", err)
+	adminUser, err := s.admin.user()
+	if err != nil {
+		return err
 	}
 
-	// Assign admin role to admin user
-	userRole := &UserRole{
-		UserID: adminUser.ID,
-		RoleID: adminRole.ID,
-	}
+	// Create the user and its role together so a failure leaves no admin without a role
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(adminUser).Error; err != nil {
+			return fmt.Errorf("failed to create admin user: %w", err)
+		}
 
-	if err := s.db.Create(userRole).Error; err != nil {
-		return fmt.Errorf("failed to assign admin role: %w", err)
-	}
+		// Get admin role
+		var adminRole Role
+		if err := tx.Where("name = ?", "admin").First(&adminRole).Error; err != nil {
+			return fmt.Errorf("failed to find admin role: %w", err)
+		}
 
-	return nil
+		// Assign admin role to admin user
+		userRole := &UserRole{
+			UserID: adminUser.ID,
+			RoleID: adminRole.ID,
+		}
+		if err := tx.Create(userRole).Error; err != nil {
+			return fmt.Errorf("failed to assign admin role: %w", err)
+		}
+		return nil
+	})
 }
 
 // === DatabaseStore Cluster Methods ===
@@ -245,7 +261,7 @@ func (s *DatabaseStore) ListUsers(offset, limit int) ([]*User, int64, error) {
 	}
 
 	// Get paginated results
-	err := s.db.Offset(offset).Limit(limit).Find(&users).Error
+	err := s.db.Order("id").Offset(offset).Limit(limit).Find(&users).Error
 	return users, total, err
 }
 
@@ -284,6 +300,10 @@ func (s *DatabaseStore) ListRoles() ([]*Role, error) {
 // === DatabaseStore UserRole Methods ===
 
 func (s *DatabaseStore) AssignRole(userID, roleID uint) error {
+	return s.AssignRoleBy(userID, roleID, 0)
+}
+
+func (s *DatabaseStore) AssignRoleBy(userID, roleID, assignedBy uint) error {
 	// Check if assignment already exists
 	var existingUserRole UserRole
 	result := s.db.Where("user_id = ? AND role_id = ?", userID, roleID).First(&existingUserRole)
@@ -292,8 +312,10 @@ func (s *DatabaseStore) AssignRole(userID, roleID uint) error {
 	}
 
 	userRole := &UserRole{
-		UserID: userID,
-		RoleID: roleID,
+		UserID:     userID,
+		RoleID:     roleID,
+		AssignedBy: assignedBy,
+		AssignedAt: time.Now(),
 	}
 	return s.db.Create(userRole).Error
 }
@@ -328,6 +350,12 @@ func (s *DatabaseStore) HasRole(userID, roleID uint) (bool, error) {
 	return count > 0, err
 }
 
+func (s *DatabaseStore) GetRoleAssignments(roleID uint) ([]*UserRole, error) {
+	var userRoles []*UserRole
+	err := s.db.Where("role_id = ?", roleID).Order("user_id").Find(&userRoles).Error
+	return userRoles, err
+}
+
 // === DatabaseStore OAuth Methods ===
 
 func (s *DatabaseStore) CreateOAuthProvider(provider *OAuthProvider) error {
@@ -336,8 +364,13 @@ func (s *DatabaseStore) CreateOAuthProvider(provider *OAuthProvider) error {
 
 func (s *DatabaseStore) GetOAuthProvider(userID uint, provider string) (*OAuthProvider, error) {
 	var oauthProvider OAuthProvider
-	err := s.db.Where("user_id = ? AND provider = ?", userID, provider).First(&oauthProvider).Error
-	return &oauthProvider, err
+	if err := s.db.Where("user_id = ? AND provider = ?", userID, provider).First(&oauthProvider).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuthProviderNotFound
+		}
+		return nil, err
+	}
+	return &oauthProvider, nil
 }
 
 func (s *DatabaseStore) GetOAuthProviderByProviderUserID(provider, providerUserID string) (*OAuthProvider, error) {
@@ -416,6 +449,28 @@ func (s *DatabaseStore) ListAuditLogs(offset, limit int) ([]*AuditLog, int64, er
 	return logs, total, err
 }
 
+// GroupAuditLogs counts the audit logs matching filter by user, resource ID and namespace
+func (s *DatabaseStore) GroupAuditLogs(filter AuditLogFilter) ([]*AuditLogGroup, error) {
+	query := s.db.Model(&AuditLog{}).
+		Select("user_id, resource_id, namespace, COUNT(*) AS count, SUM(CASE WHEN failed THEN 1 ELSE 0 END) AS failed")
+	if len(filter.Actions) > 0 {
+		query = query.Where("action IN ?", filter.Actions)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+
+	var groups []*AuditLogGroup
+	err := query.Group("user_id, resource_id, namespace").Scan(&groups).Error
+	return groups, err
+}
+
 // === DatabaseStore LoginAttempt Methods ===
 
 func (s *DatabaseStore) CreateLoginAttempt(attempt *LoginAttempt) error {
@@ -446,8 +501,9 @@ func (s *DatabaseStore) GetLoginAttemptsByIP(ipAddress string, since time.Time)
 	return attempts, err
 }
 
-func (s *DatabaseStore) CleanupOldLoginAttempts(before time.Time) error {
-	return s.db.Where("created_at < ?", before).Delete(&LoginAttempt{}).Error
+func (s *DatabaseStore) CleanupOldLoginAttempts(before time.Time) (int64, error) {
+	result := s.db.Where("created_at < ?", before).Delete(&LoginAttempt{})
+	return result.RowsAffected, result.Error
 }
 
 // === DatabaseStore UserSession Methods ===
@@ -482,6 +538,196 @@ func (s *DatabaseStore) DeleteUserSessions(userID uint) error {
 	return s.db.Where("user_id = ?", userID).Delete(&UserSession{}).Error
 }
 
-func (s *DatabaseStore) CleanupExpiredSessions(before time.Time) error {
-	return s.db.Where("expires_at < ? OR is_active = ?", before, false).Delete(&UserSession{}).Error
+func (s *DatabaseStore) CleanupExpiredSessions(before time.Time) (int64, error) {
+	result := s.db.Where("expires_at < ? OR is_active = ?", before, false).Delete(&UserSession{})
+	return result.RowsAffected, result.Error
+}
+
+// === DatabaseStore Alert Methods ===
+
+func (s *DatabaseStore) CreateAlert(alert *Alert) error {
+	return s.db.Create(alert).Error
+}
+
+func (s *DatabaseStore) GetAlertByID(id uint) (*Alert, error) {
+	var alert Alert
+	if err := s.db.First(&alert, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (s *DatabaseStore) UpdateAlert(alert *Alert) error {
+	return s.db.Save(alert).Error
+}
+
+func (s *DatabaseStore) ListAlerts(filter AlertFilter, offset, limit int) ([]*Alert, int64, error) {
+	var alerts []*Alert
+	var total int64
+
+	query := s.db.Model(&Alert{})
+	if filter.Level != "" {
+		query = query.Where("level = ?", filter.Level)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Resolved != nil {
+		query = query.Where("resolved = ?", *filter.Resolved)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("last_seen >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("last_seen < ?", filter.Until)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results
+	if filter.After != nil {
+		query = query.Where("last_seen < ? OR (last_seen = ? AND id < ?)", filter.After.LastSeen, filter.After.LastSeen, filter.After.ID)
+	}
+	err := query.Offset(offset).Limit(limit).
+		Order("last_seen DESC, id DESC").
+		Find(&alerts).Error
+	return alerts, total, err
+}
+
+func (s *DatabaseStore) FindOpenAlert(alertType string, since time.Time) (*Alert, error) {
+	var alert Alert
+	err := s.db.Where("type = ? AND resolved = ? AND last_seen >= ?", alertType, false, since).
+		Order("last_seen DESC").
+		First(&alert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// === DatabaseStore UserPreference Methods ===
+
+func (s *DatabaseStore) GetUserPreference(userID uint, clusterID string) (*UserPreference, error) {
+	var pref UserPreference
+	if err := s.db.Where("user_id = ? AND cluster_id = ?", userID, clusterID).First(&pref).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserPreferenceNotFound
+		}
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (s *DatabaseStore) SaveUserPreference(pref *UserPreference) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existing UserPreference
+		err := tx.Where("user_id = ? AND cluster_id = ?", pref.UserID, pref.ClusterID).First(&existing).Error
+		switch {
+		case err == nil:
+			pref.ID = existing.ID
+			pref.CreatedAt = existing.CreatedAt
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			pref.ID = 0
+		default:
+			return err
+		}
+		return tx.Save(pref).Error
+	})
+}
+
+func (s *DatabaseStore) DeleteUserPreference(userID uint, clusterID string) error {
+	return s.db.Where("user_id = ? AND cluster_id = ?", userID, clusterID).Delete(&UserPreference{}).Error
+}
+
+func (s *DatabaseStore) ListUserPreferences(userID uint) ([]*UserPreference, error) {
+	var prefs []*UserPreference
+	err := s.db.Where("user_id = ?", userID).Order("cluster_id").Find(&prefs).Error
+	return prefs, err
+}
+
+// === DatabaseStore Invite Methods ===
+
+func (s *DatabaseStore) CreateInvite(invite *Invite) error {
+	return s.db.Create(invite).Error
+}
+
+func (s *DatabaseStore) GetInviteByID(id uint) (*Invite, error) {
+	var invite Invite
+	if err := s.db.First(&invite, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInviteNotFound
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (s *DatabaseStore) UpdateInvite(invite *Invite) error {
+	return s.db.Save(invite).Error
+}
+
+func (s *DatabaseStore) ListInvites(offset, limit int) ([]*Invite, int64, error) {
+	var invites []*Invite
+	var total int64
+
+	if err := s.db.Model(&Invite{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := s.db.Order("id DESC").Offset(offset).Limit(limit).Find(&invites).Error
+	return invites, total, err
+}
+
+// === DatabaseStore ResourceTemplate Methods ===
+
+func (s *DatabaseStore) CreateResourceTemplate(template *ResourceTemplate) error {
+	return s.db.Create(template).Error
+}
+
+func (s *DatabaseStore) GetResourceTemplate(name string) (*ResourceTemplate, error) {
+	var template ResourceTemplate
+	if err := s.db.Where("name = ?", name).First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResourceTemplateNotFound
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (s *DatabaseStore) ListResourceTemplates() ([]*ResourceTemplate, error) {
+	var templates []*ResourceTemplate
+	err := s.db.Order("name").Find(&templates).Error
+	return templates, err
+}
+
+// === DatabaseStore Favorite Methods ===
+
+func (s *DatabaseStore) CreateFavorite(favorite *Favorite) error {
+	return s.db.Create(favorite).Error
+}
+
+func (s *DatabaseStore) ListFavorites(userID uint) ([]*Favorite, error) {
+	var favorites []*Favorite
+	err := s.db.Where("user_id = ?", userID).Order("id").Find(&favorites).Error
+	return favorites, err
+}
+
+func (s *DatabaseStore) DeleteFavorite(userID, id uint) error {
+	result := s.db.Where("user_id = ?", userID).Delete(&Favorite{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFavoriteNotFound
+	}
+	return nil
 }
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFavorites(t *testing.T, s Store) {
+	web := &Favorite{UserID: 1, ClusterID: "prod", Namespace: "shop", Kind: "Deployment", Name: "web"}
+	require.NoError(t, s.CreateFavorite(web))
+	require.NoError(t, s.CreateFavorite(&Favorite{UserID: 1, ClusterID: "prod", Kind: "Node", Name: "node-1"}))
+	require.NoError(t, s.CreateFavorite(&Favorite{UserID: 2, ClusterID: "prod", Namespace: "shop", Kind: "Deployment", Name: "web"}))
+	assert.Error(t, s.CreateFavorite(&Favorite{UserID: 1, ClusterID: "prod", Namespace: "shop", Kind: "Deployment", Name: "web"}),
+		"a user bookmarks a resource once")
+
+	favorites, err := s.ListFavorites(1)
+	require.NoError(t, err)
+	require.Len(t, favorites, 2)
+	assert.Equal(t, "web", favorites[0].Name)
+	assert.Equal(t, "node-1", favorites[1].Name)
+	assert.Empty(t, favorites[1].Namespace)
+
+	assert.ErrorIs(t, s.DeleteFavorite(2, web.ID), ErrFavoriteNotFound, "users only remove their own favorites")
+	require.NoError(t, s.DeleteFavorite(1, web.ID))
+	assert.ErrorIs(t, s.DeleteFavorite(1, web.ID), ErrFavoriteNotFound)
+
+	favorites, err = s.ListFavorites(1)
+	require.NoError(t, err)
+	require.Len(t, favorites, 1)
+	other, err := s.ListFavorites(2)
+	require.NoError(t, err)
+	require.Len(t, other, 1, "other users keep their favorites")
+}
+
+func TestMemoryStore_Favorites(t *testing.T) {
+	testFavorites(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_Favorites(t *testing.T) {
+	testFavorites(t, newTestDatabaseStore(t))
+}
@@ -37,10 +37,14 @@ type RoleStore interface {
 // UserRoleStore defines all methods required for managing user-role associations.
 type UserRoleStore interface {
 	AssignRole(userID, roleID uint) error
+	// AssignRoleBy assigns a role and records who assigned it and when
+	AssignRoleBy(userID, roleID, assignedBy uint) error
 	RemoveRole(userID, roleID uint) error
 	GetUserRoles(userID uint) ([]*Role, error)
 	GetRoleUsers(roleID uint) ([]*User, error)
 	HasRole(userID, roleID uint) (bool, error)
+	// GetRoleAssignments returns the assignments of a role, including when and by whom each was made
+	GetRoleAssignments(roleID uint) ([]*UserRole, error)
 }
 
 // OAuthStore defines all methods required for managing OAuth provider data.
@@ -59,6 +63,7 @@ type AuditLogStore interface {
 	GetAuditLogsByUserID(userID uint, offset, limit int) ([]*AuditLog, int64, error)
 	GetAuditLogsByAction(action string, offset, limit int) ([]*AuditLog, int64, error)
 	ListAuditLogs(offset, limit int) ([]*AuditLog, int64, error)
+	GroupAuditLogs(filter AuditLogFilter) ([]*AuditLogGroup, error)
 }
 
 // LoginAttemptStore defines all methods required for managing login attempts.
@@ -67,7 +72,8 @@ type LoginAttemptStore interface {
 	GetLoginAttemptsByUserID(userID uint, since time.Time) ([]*LoginAttempt, error)
 	GetLoginAttemptsByUsername(username string, since time.Time) ([]*LoginAttempt, error)
 	GetLoginAttemptsByIP(ipAddress string, since time.Time) ([]*LoginAttempt, error)
-	CleanupOldLoginAttempts(before time.Time) error
+	// CleanupOldLoginAttempts removes attempts made before the given time and returns how many were removed
+	CleanupOldLoginAttempts(before time.Time) (int64, error)
 }
 
 // UserSessionStore defines all methods required for managing user sessions.
@@ -78,7 +84,58 @@ type UserSessionStore interface {
 	DeleteUserSession(sessionID string) error
 	GetUserSessions(userID uint) ([]*UserSession, error)
 	DeleteUserSessions(userID uint) error
-	CleanupExpiredSessions(before time.Time) error
+	// CleanupExpiredSessions removes inactive sessions and those expired before the given time and returns how many were removed
+	CleanupExpiredSessions(before time.Time) (int64, error)
+}
+
+// AlertStore defines all methods required for managing monitoring alerts.
+type AlertStore interface {
+	CreateAlert(alert *Alert) error
+	GetAlertByID(id uint) (*Alert, error)
+	UpdateAlert(alert *Alert) error
+	ListAlerts(filter AlertFilter, offset, limit int) ([]*Alert, int64, error)
+	// FindOpenAlert returns the most recent unresolved alert of a type last seen at or after since,
+	// or ErrAlertNotFound if there is none.
+	FindOpenAlert(alertType string, since time.Time) (*Alert, error)
+}
+
+// UserPreferenceStore defines all methods required for managing per-cluster user preferences.
+type UserPreferenceStore interface {
+	// GetUserPreference returns ErrUserPreferenceNotFound if the user has no preferences for the cluster.
+	GetUserPreference(userID uint, clusterID string) (*UserPreference, error)
+	// SaveUserPreference creates the preferences of pref.UserID for pref.ClusterID or replaces the existing ones.
+	SaveUserPreference(pref *UserPreference) error
+	DeleteUserPreference(userID uint, clusterID string) error
+	ListUserPreferences(userID uint) ([]*UserPreference, error)
+}
+
+// InviteStore defines all methods required for managing user invites.
+type InviteStore interface {
+	CreateInvite(invite *Invite) error
+	// GetInviteByID returns ErrInviteNotFound if there is no such invite.
+	GetInviteByID(id uint) (*Invite, error)
+	UpdateInvite(invite *Invite) error
+	// ListInvites returns invites newest first.
+	ListInvites(offset, limit int) ([]*Invite, int64, error)
+}
+
+// ResourceTemplateStore defines all methods required for managing resource templates.
+type ResourceTemplateStore interface {
+	CreateResourceTemplate(template *ResourceTemplate) error
+	// GetResourceTemplate returns ErrResourceTemplateNotFound if there is no template of that name.
+	GetResourceTemplate(name string) (*ResourceTemplate, error)
+	// ListResourceTemplates returns templates ordered by name.
+	ListResourceTemplates() ([]*ResourceTemplate, error)
+}
+
+// FavoriteStore defines all methods required for managing users' favorite resources.
+type FavoriteStore interface {
+	// CreateFavorite fails if the user already has the same resource as a favorite.
+	CreateFavorite(favorite *Favorite) error
+	// ListFavorites returns the favorites of a user in the order they were added.
+	ListFavorites(userID uint) ([]*Favorite, error)
+	// DeleteFavorite returns ErrFavoriteNotFound if the user has no favorite with that ID.
+	DeleteFavorite(userID, id uint) error
 }
 
 // Store is the main interface that combines all storage interfaces
@@ -91,6 +148,15 @@ type Store interface {
 	AuditLogStore
 	LoginAttemptStore
 	UserSessionStore
+	AlertStore
+	UserPreferenceStore
+	InviteStore
+	ResourceTemplateStore
+	FavoriteStore
+
+	// Transaction runs fn against a store whose changes are committed only if fn returns nil.
+	// fn must use the store it is given, not the outer one, for the changes to be atomic.
+	Transaction(fn func(Store) error) error
 
 	// Initialize initializes the storage (creates tables, default data, etc.)
 	Initialize() error
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInvites(t *testing.T, s Store) {
+	_, err := s.GetInviteByID(1)
+	assert.ErrorIs(t, err, ErrInviteNotFound)
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	first := &Invite{Email: "alice@example.com", RoleIDs: UintList{2, 3}, Status: InviteStatusPending, ExpiresAt: expiresAt}
+	require.NoError(t, s.CreateInvite(first))
+	require.NoError(t, s.CreateInvite(&Invite{Email: "bob@example.com", Status: InviteStatusPending, ExpiresAt: expiresAt}))
+
+	invite, err := s.GetInviteByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", invite.Email)
+	assert.Equal(t, UintList{2, 3}, invite.RoleIDs)
+	assert.True(t, expiresAt.Equal(invite.ExpiresAt))
+
+	userID, acceptedAt := uint(7), time.Now()
+	invite.Status, invite.AcceptedBy, invite.AcceptedAt = InviteStatusAccepted, &userID, &acceptedAt
+	require.NoError(t, s.UpdateInvite(invite))
+	invite, err = s.GetInviteByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, InviteStatusAccepted, invite.Status)
+	assert.Equal(t, &userID, invite.AcceptedBy)
+
+	invites, total, err := s.ListInvites(0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, invites, 2)
+	assert.Equal(t, "bob@example.com", invites[0].Email, "newest first")
+}
+
+func TestMemoryStore_Invites(t *testing.T) {
+	testInvites(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_Invites(t *testing.T) {
+	testInvites(t, newTestDatabaseStore(t))
+}
+
+func TestInvite_StatusAt(t *testing.T) {
+	now := time.Now()
+	invite := &Invite{Status: InviteStatusPending, ExpiresAt: now.Add(time.Minute)}
+	assert.Equal(t, InviteStatusPending, invite.StatusAt(now))
+	assert.Equal(t, InviteStatusExpired, invite.StatusAt(now.Add(time.Minute)))
+
+	invite.Status = InviteStatusAccepted
+	assert.Equal(t, InviteStatusAccepted, invite.StatusAt(now.Add(time.Hour)), "an accepted invite stays accepted")
+}
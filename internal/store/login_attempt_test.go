@@ -0,0 +1,54 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLoginAttempts(t *testing.T, s Store) {
+	userID := uint(1)
+	start := time.Now().Add(-time.Minute)
+	old := &LoginAttempt{Username: "admin", IPAddress: "10.0.0.1", CreatedAt: time.Now().Add(-48 * time.Hour)}
+	require.NoError(t, s.CreateLoginAttempt(old))
+	require.NoError(t, s.CreateLoginAttempt(&LoginAttempt{Username: "admin", IPAddress: "10.0.0.1", FailReason: "invalid password"}))
+	require.NoError(t, s.CreateLoginAttempt(&LoginAttempt{UserID: &userID, Username: "admin", IPAddress: "10.0.0.2", Success: true}))
+	require.NoError(t, s.CreateLoginAttempt(&LoginAttempt{Username: "ghost", IPAddress: "10.0.0.1", FailReason: "user not found"}))
+
+	byUsername, err := s.GetLoginAttemptsByUsername("admin", start)
+	require.NoError(t, err)
+	require.Len(t, byUsername, 2)
+	assert.True(t, byUsername[0].Success, "newest attempt comes first")
+	assert.Equal(t, "invalid password", byUsername[1].FailReason)
+
+	byIP, err := s.GetLoginAttemptsByIP("10.0.0.1", start)
+	require.NoError(t, err)
+	assert.Len(t, byIP, 2)
+
+	byUser, err := s.GetLoginAttemptsByUserID(userID, start)
+	require.NoError(t, err)
+	require.Len(t, byUser, 1)
+	assert.Equal(t, "10.0.0.2", byUser[0].IPAddress)
+
+	// Login attempts are not audit logs
+	_, total, err := s.ListAuditLogs(0, 100)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	removed, err := s.CleanupOldLoginAttempts(time.Now().Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+	all, err := s.GetLoginAttemptsByUsername("admin", time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, all, 2, "only the old attempt is removed")
+}
+
+func TestMemoryStore_LoginAttempts(t *testing.T) {
+	testLoginAttempts(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_LoginAttempts(t *testing.T) {
+	testLoginAttempts(t, newTestDatabaseStore(t))
+}
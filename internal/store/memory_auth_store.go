@@ -535,7 +535,7 @@ func (s *MemoryAuthStore) GetOAuthProvider(userID uint, provider string) (*OAuth
 	key := fmt.Sprintf("%d_%s", userID, provider)
 	oauthProvider, exists := s.oauthProviders[key]
 	if !exists {
-		return nil, fmt.Errorf("OAuth provider '%s' not found for user %d", provider, userID)
+		return nil, ErrOAuthProviderNotFound
 	}
 
 	providerCopy := *oauthProvider
@@ -712,3 +712,9 @@ func (s *MemoryAuthStore) ListAuditLogs(offset, limit int) ([]*AuditLog, int64,
 
 	return result, total, nil
 }
+
+func (s *MemoryAuthStore) GroupAuditLogs(filter AuditLogFilter) ([]*AuditLogGroup, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return groupAuditLogs(s.auditLogs, filter), nil
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 )
@@ -21,13 +22,29 @@ type MemoryStore struct {
 	roles          map[uint]*Role
 	rolesByName    map[string]*Role
 	userRoles      map[uint][]uint           // userID -> roleIDs
+	assignments    map[userRoleKey]*UserRole // when and by whom each role was assigned
 	oauthProviders map[string]*OAuthProvider // key: userID_provider
 	auditLogs      []*AuditLog
+	loginAttempts  []*LoginAttempt
+	alerts         map[uint]*Alert
+	preferences    map[userPreferenceKey]*UserPreference
+	invites        map[uint]*Invite
+	templates      map[string]*ResourceTemplate // key: name
+	favorites      map[uint]*Favorite
 
 	// ID generators
-	nextUserID     uint
-	nextRoleID     uint
-	nextAuditLogID uint
+	nextUserID         uint
+	nextRoleID         uint
+	nextAuditLogID     uint
+	nextLoginAttemptID uint
+	nextAlertID        uint
+	nextPreferenceID   uint
+	nextInviteID       uint
+	nextTemplateID     uint
+	nextFavoriteID     uint
+
+	// admin is created by Initialize when the store has no users
+	admin adminBootstrap
 
 	mutex sync.RWMutex
 }
@@ -35,18 +52,31 @@ type MemoryStore struct {
 // NewMemoryStore creates a new in-memory store with all interfaces
 func NewMemoryStore() Store {
 	store := &MemoryStore{
-		clusters:       make(map[string]*Cluster),
-		users:          make(map[uint]*User),
-		usersByName:    make(map[string]*User),
-		usersByEmail:   make(map[string]*User),
-		roles:          make(map[uint]*Role),
-		rolesByName:    make(map[string]*Role),
-		userRoles:      make(map[uint][]uint),
-		oauthProviders: make(map[string]*OAuthProvider),
-		auditLogs:      make([]*AuditLog, 0),
-		nextUserID:     1,
-		nextRoleID:     1,
-		nextAuditLogID: 1,
+		clusters:           make(map[string]*Cluster),
+		users:              make(map[uint]*User),
+		usersByName:        make(map[string]*User),
+		usersByEmail:       make(map[string]*User),
+		roles:              make(map[uint]*Role),
+		rolesByName:        make(map[string]*Role),
+		userRoles:          make(map[uint][]uint),
+		assignments:        make(map[userRoleKey]*UserRole),
+		oauthProviders:     make(map[string]*OAuthProvider),
+		auditLogs:          make([]*AuditLog, 0),
+		loginAttempts:      make([]*LoginAttempt, 0),
+		alerts:             make(map[uint]*Alert),
+		preferences:        make(map[userPreferenceKey]*UserPreference),
+		invites:            make(map[uint]*Invite),
+		templates:          make(map[string]*ResourceTemplate),
+		favorites:          make(map[uint]*Favorite),
+		nextUserID:         1,
+		nextRoleID:         1,
+		nextAuditLogID:     1,
+		nextLoginAttemptID: 1,
+		nextAlertID:        1,
+		nextPreferenceID:   1,
+		nextInviteID:       1,
+		nextTemplateID:     1,
+		nextFavoriteID:     1,
 	}
 	return store
 }
@@ -309,7 +339,6 @@ func (s *MemoryStore) UpdateUser(user *User) error {
 			return fmt.Errorf("username '%s' already exists", user.Username)
 		}
 		delete(s.usersByName, existingUser.Username)
-		s.usersByName[user.Username] = user
 	}
 
 	// Check if email is being changed and if it conflicts
@@ -318,13 +347,14 @@ func (s *MemoryStore) UpdateUser(user *User) error {
 			return fmt.Errorf("email '%s' already exists", user.Email)
 		}
 		delete(s.usersByEmail, existingUser.Email)
-		s.usersByEmail[user.Email] = user
 	}
 
-	// Update user
+	// Update user, keeping the username and email indexes on the same copy
 	updatedUser := *user
 	updatedUser.UpdatedAt = time.Now()
 	s.users[user.ID] = &updatedUser
+	s.usersByName[updatedUser.Username] = &updatedUser
+	s.usersByEmail[updatedUser.Email] = &updatedUser
 
 	return nil
 }
@@ -345,6 +375,9 @@ func (s *MemoryStore) DeleteUser(id uint) error {
 	delete(s.usersByEmail, user.Email)
 
 	// Remove user roles
+	for _, roleID := range s.userRoles[id] {
+		delete(s.assignments, userRoleKey{userID: id, roleID: roleID})
+	}
 	delete(s.userRoles, id)
 
 	// Remove OAuth providers
@@ -354,6 +387,20 @@ func (s *MemoryStore) DeleteUser(id uint) error {
 		}
 	}
 
+	// Remove preferences
+	for key := range s.preferences {
+		if key.userID == id {
+			delete(s.preferences, key)
+		}
+	}
+
+	// Remove favorites
+	for favoriteID, favorite := range s.favorites {
+		if favorite.UserID == id {
+			delete(s.favorites, favoriteID)
+		}
+	}
+
 	return nil
 }
 
@@ -371,6 +418,8 @@ func (s *MemoryStore) ListUsers(offset, limit int) ([]*User, int64, error) {
 		userCopy := *user
 		allUsers = append(allUsers, &userCopy)
 	}
+	// Order by ID so consecutive pages don't overlap
+	sort.Slice(allUsers, func(i, j int) bool { return allUsers[i].ID < allUsers[j].ID })
 
 	// Apply pagination
 	start := offset
@@ -495,6 +544,7 @@ func (s *MemoryStore) DeleteRole(id uint) error {
 			}
 		}
 		s.userRoles[userID] = newRoleIDs
+		delete(s.assignments, userRoleKey{userID: userID, roleID: id})
 	}
 
 	return nil
@@ -518,6 +568,11 @@ func (s *MemoryStore) ListRoles() ([]*Role, error) {
 
 // AssignRole implements UserRoleStore interface
 func (s *MemoryStore) AssignRole(userID, roleID uint) error {
+	return s.AssignRoleBy(userID, roleID, 0)
+}
+
+// AssignRoleBy implements UserRoleStore interface
+func (s *MemoryStore) AssignRoleBy(userID, roleID, assignedBy uint) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -531,17 +586,7 @@ func (s *MemoryStore) AssignRole(userID, roleID uint) error {
 		return fmt.Errorf("role with ID %d not found", roleID)
 	}
 
-	// Check if user already has this role
-	userRoles := s.userRoles[userID]
-	for _, existingRoleID := range userRoles {
-		if existingRoleID == roleID {
-			return nil // Already assigned, no error
-		}
-	}
-
-	// Add role to user
-	s.userRoles[userID] = append(userRoles, roleID)
-	return nil
+	return s.assignRoleInternal(userID, roleID, assignedBy)
 }
 
 // RemoveRole implements UserRoleStore interface
@@ -570,6 +615,7 @@ func (s *MemoryStore) RemoveRole(userID, roleID uint) error {
 	}
 
 	s.userRoles[userID] = newRoles
+	delete(s.assignments, userRoleKey{userID: userID, roleID: roleID})
 	return nil
 }
 
@@ -639,6 +685,23 @@ func (s *MemoryStore) HasRole(userID, roleID uint) (bool, error) {
 	return false, nil
 }
 
+// GetRoleAssignments implements UserRoleStore interface
+func (s *MemoryStore) GetRoleAssignments(roleID uint) ([]*UserRole, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	assignments := make([]*UserRole, 0)
+	for key, assignment := range s.assignments {
+		if key.roleID == roleID {
+			assignmentCopy := *assignment
+			assignments = append(assignments, &assignmentCopy)
+		}
+	}
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].UserID < assignments[j].UserID })
+
+	return assignments, nil
+}
+
 // === MemoryStore OAuth Methods ===
 
 // CreateOAuthProvider implements OAuthStore interface
@@ -675,7 +738,7 @@ func (s *MemoryStore) GetOAuthProvider(userID uint, provider string) (*OAuthProv
 	key := fmt.Sprintf("%d_%s", userID, provider)
 	oauthProvider, exists := s.oauthProviders[key]
 	if !exists {
-		return nil, fmt.Errorf("OAuth provider '%s' not found for user %d", provider, userID)
+		return nil, ErrOAuthProviderNotFound
 	}
 
 	providerCopy := *oauthProvider
@@ -757,7 +820,9 @@ func (s *MemoryStore) CreateAuditLog(log *AuditLog) error {
 
 	// Create new audit log
 	newLog := *log
-	newLog.CreatedAt = time.Now()
+	if newLog.CreatedAt.IsZero() {
+		newLog.CreatedAt = time.Now()
+	}
 
 	s.auditLogs = append(s.auditLogs, &newLog)
 	return nil
@@ -848,6 +913,61 @@ func (s *MemoryStore) ListAuditLogs(offset, limit int) ([]*AuditLog, int64, erro
 	return logs, total, nil
 }
 
+// GroupAuditLogs implements AuditLogStore interface
+func (s *MemoryStore) GroupAuditLogs(filter AuditLogFilter) ([]*AuditLogGroup, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return groupAuditLogs(s.auditLogs, filter), nil
+}
+
+// groupAuditLogs counts the logs matching filter by user, resource ID and namespace, for the memory stores
+func groupAuditLogs(logs []*AuditLog, filter AuditLogFilter) []*AuditLogGroup {
+	type groupKey struct {
+		hasUser    bool
+		userID     uint
+		resourceID string
+		namespace  string
+	}
+	actions := make(map[string]bool, len(filter.Actions))
+	for _, action := range filter.Actions {
+		actions[action] = true
+	}
+
+	groups := make([]*AuditLogGroup, 0)
+	byKey := make(map[groupKey]*AuditLogGroup)
+	for _, log := range logs {
+		if len(actions) > 0 && !actions[log.Action] {
+			continue
+		}
+		if filter.UserID != nil && (log.UserID == nil || *log.UserID != *filter.UserID) {
+			continue
+		}
+		if (!filter.Since.IsZero() && log.CreatedAt.Before(filter.Since)) || (!filter.Until.IsZero() && !log.CreatedAt.Before(filter.Until)) {
+			continue
+		}
+
+		key := groupKey{resourceID: log.ResourceID, namespace: log.Namespace}
+		if log.UserID != nil {
+			key.hasUser, key.userID = true, *log.UserID
+		}
+		group, ok := byKey[key]
+		if !ok {
+			group = &AuditLogGroup{ResourceID: log.ResourceID, Namespace: log.Namespace}
+			if key.hasUser {
+				userID := key.userID
+				group.UserID = &userID
+			}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.Count++
+		if log.Failed {
+			group.Failed++
+		}
+	}
+	return groups
+}
+
 // === MemoryStore Management Methods ===
 
 // Initialize implements Store interface
@@ -888,14 +1008,13 @@ func (s *MemoryStore) Initialize() error {
 		return fmt.Errorf("failed to create viewer role: %w", err)
 	}
 
-	// Create default admin user
-	adminUser := &User{
-		Username:      "admin",
-		Email:         "admin@cilikube.com",
-		PasswordHash:  "12345678", // Will be hashed
-		DisplayName:   "System Administrator",
-		IsActive:      true,
-		EmailVerified: true,
+	// Create the bootstrap admin user when no users exist
+	if len(s.users) > 0 {
+		return nil
+	}
+	adminUser, err := s.admin.user()
+	if err != nil {
+		return fmt.Errorf("failed to create admin user: %w", err)
 	}
 
 	if err := s.createUserInternal(adminUser); err != nil {
@@ -903,7 +1022,7 @@ func (s *MemoryStore) Initialize() error {
 	}
 
 	// Assign admin role to admin user
-	if err := s.assignRoleInternal(adminUser.ID, adminRole.ID); err != nil {
+	if err := s.assignRoleInternal(adminUser.ID, adminRole.ID, 0); err != nil {
 		return fmt.Errorf("failed to assign admin role: %w", err)
 	}
 
@@ -916,6 +1035,114 @@ func (s *MemoryStore) Close() error {
 	return nil
 }
 
+// Transaction implements Store interface. The write lock is held while fn runs against a copy of
+// the store, and the copy's data replaces the store's only when fn succeeds.
+func (s *MemoryStore) Transaction(fn func(Store) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx := s.clone()
+	sessions, sessionsByUser := cloneUserSessions()
+	committed := false
+	defer func() {
+		if !committed {
+			memoryUserSessions, memoryUserSessionsByUser = sessions, sessionsByUser
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	s.clusters, s.users, s.usersByName, s.usersByEmail = tx.clusters, tx.users, tx.usersByName, tx.usersByEmail
+	s.roles, s.rolesByName, s.userRoles, s.assignments = tx.roles, tx.rolesByName, tx.userRoles, tx.assignments
+	s.oauthProviders, s.auditLogs, s.loginAttempts, s.alerts = tx.oauthProviders, tx.auditLogs, tx.loginAttempts, tx.alerts
+	s.nextUserID, s.nextRoleID, s.nextAuditLogID, s.nextAlertID = tx.nextUserID, tx.nextRoleID, tx.nextAuditLogID, tx.nextAlertID
+	s.preferences, s.invites, s.templates, s.favorites = tx.preferences, tx.invites, tx.templates, tx.favorites
+	s.nextLoginAttemptID, s.nextPreferenceID, s.nextInviteID, s.nextTemplateID = tx.nextLoginAttemptID, tx.nextPreferenceID, tx.nextInviteID, tx.nextTemplateID
+	s.nextFavoriteID = tx.nextFavoriteID
+	committed = true
+	return nil
+}
+
+// clone copies the store's data into a new store with its own lock. Stored objects are never
+// changed in place, so copying the maps is enough to keep the original untouched.
+// The caller must hold the lock.
+func (s *MemoryStore) clone() *MemoryStore {
+	tx := &MemoryStore{
+		clusters:           make(map[string]*Cluster, len(s.clusters)),
+		users:              make(map[uint]*User, len(s.users)),
+		usersByName:        make(map[string]*User, len(s.usersByName)),
+		usersByEmail:       make(map[string]*User, len(s.usersByEmail)),
+		roles:              make(map[uint]*Role, len(s.roles)),
+		rolesByName:        make(map[string]*Role, len(s.rolesByName)),
+		userRoles:          make(map[uint][]uint, len(s.userRoles)),
+		assignments:        make(map[userRoleKey]*UserRole, len(s.assignments)),
+		oauthProviders:     make(map[string]*OAuthProvider, len(s.oauthProviders)),
+		auditLogs:          append(make([]*AuditLog, 0, len(s.auditLogs)), s.auditLogs...),
+		loginAttempts:      append(make([]*LoginAttempt, 0, len(s.loginAttempts)), s.loginAttempts...),
+		alerts:             make(map[uint]*Alert, len(s.alerts)),
+		preferences:        make(map[userPreferenceKey]*UserPreference, len(s.preferences)),
+		invites:            make(map[uint]*Invite, len(s.invites)),
+		templates:          make(map[string]*ResourceTemplate, len(s.templates)),
+		favorites:          make(map[uint]*Favorite, len(s.favorites)),
+		nextUserID:         s.nextUserID,
+		nextRoleID:         s.nextRoleID,
+		nextAuditLogID:     s.nextAuditLogID,
+		nextLoginAttemptID: s.nextLoginAttemptID,
+		nextAlertID:        s.nextAlertID,
+		nextPreferenceID:   s.nextPreferenceID,
+		nextInviteID:       s.nextInviteID,
+		nextTemplateID:     s.nextTemplateID,
+		nextFavoriteID:     s.nextFavoriteID,
+	}
+	for k, v := range s.clusters {
+		tx.clusters[k] = v
+	}
+	for k, v := range s.users {
+		tx.users[k] = v
+	}
+	for k, v := range s.usersByName {
+		tx.usersByName[k] = v
+	}
+	for k, v := range s.usersByEmail {
+		tx.usersByEmail[k] = v
+	}
+	for k, v := range s.roles {
+		tx.roles[k] = v
+	}
+	for k, v := range s.rolesByName {
+		tx.rolesByName[k] = v
+	}
+	for k, v := range s.userRoles {
+		tx.userRoles[k] = append([]uint(nil), v...)
+	}
+	for k, v := range s.assignments {
+		tx.assignments[k] = v
+	}
+	for k, v := range s.oauthProviders {
+		tx.oauthProviders[k] = v
+	}
+	for k, v := range s.alerts {
+		tx.alerts[k] = v
+	}
+	for k, v := range s.preferences {
+		tx.preferences[k] = v
+	}
+	for k, v := range s.invites {
+		tx.invites[k] = v
+	}
+	for k, v := range s.templates {
+		tx.templates[k] = v
+	}
+	for k, v := range s.favorites {
+		tx.favorites[k] = v
+	}
+	return tx
+}
+
 // Internal helper methods
 
 func (s *MemoryStore) createRoleInternal(role *Role) error {
@@ -981,7 +1208,7 @@ func (s *MemoryStore) createUserInternal(user *User) error {
 	return nil
 }
 
-func (s *MemoryStore) assignRoleInternal(userID, roleID uint) error {
+func (s *MemoryStore) assignRoleInternal(userID, roleID, assignedBy uint) error {
 	// Check if user already has this role
 	userRoles := s.userRoles[userID]
 	for _, existingRoleID := range userRoles {
@@ -992,6 +1219,12 @@ func (s *MemoryStore) assignRoleInternal(userID, roleID uint) error {
 
 	// Add role to user
 	s.userRoles[userID] = append(userRoles, roleID)
+	s.assignments[userRoleKey{userID: userID, roleID: roleID}] = &UserRole{
+		UserID:     userID,
+		RoleID:     roleID,
+		AssignedBy: assignedBy,
+		AssignedAt: time.Now(),
+	}
 	return nil
 }
 
@@ -1002,117 +1235,68 @@ func (s *MemoryStore) CreateLoginAttempt(attempt *LoginAttempt) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// For memory store, we'll just add to audit logs for simplicity
-	// In a real implementation, you might want a separate storage
-	action := "login_failed"
-	if attempt.Success {
-		action = "login"
+	attempt.ID = s.nextLoginAttemptID
+	s.nextLoginAttemptID++
+	if attempt.CreatedAt.IsZero() {
+		attempt.CreatedAt = time.Now()
 	}
 
-	auditLog := &AuditLog{
-		UserID:     attempt.UserID,
-		Action:     action,
-		Resource:   "user",
-		ResourceID: attempt.Username,
-		IPAddress:  attempt.IPAddress,
-		UserAgent:  attempt.UserAgent,
-		Details:    attempt.FailReason,
-		CreatedAt:  attempt.CreatedAt,
-	}
-
-	s.auditLogs = append(s.auditLogs, auditLog)
+	newAttempt := *attempt
+	s.loginAttempts = append(s.loginAttempts, &newAttempt)
 	return nil
 }
 
 // GetLoginAttemptsByUserID implements LoginAttemptStore interface
 func (s *MemoryStore) GetLoginAttemptsByUserID(userID uint, since time.Time) ([]*LoginAttempt, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	attempts := make([]*LoginAttempt, 0)
-	for _, log := range s.auditLogs {
-		if log.UserID != nil && *log.UserID == userID && log.CreatedAt.After(since) {
-			if log.Action == "login" || log.Action == "login_failed" {
-				attempt := &LoginAttempt{
-					UserID:     log.UserID,
-					Username:   log.ResourceID,
-					IPAddress:  log.IPAddress,
-					UserAgent:  log.UserAgent,
-					Success:    log.Action == "login",
-					FailReason: log.Details,
-					CreatedAt:  log.CreatedAt,
-				}
-				attempts = append(attempts, attempt)
-			}
-		}
-	}
-	return attempts, nil
+	return s.findLoginAttempts(since, func(attempt *LoginAttempt) bool {
+		return attempt.UserID != nil && *attempt.UserID == userID
+	}), nil
 }
 
 // GetLoginAttemptsByUsername implements LoginAttemptStore interface
 func (s *MemoryStore) GetLoginAttemptsByUsername(username string, since time.Time) ([]*LoginAttempt, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	attempts := make([]*LoginAttempt, 0)
-	for _, log := range s.auditLogs {
-		if log.ResourceID == username && log.CreatedAt.After(since) {
-			if log.Action == "login" || log.Action == "login_failed" {
-				attempt := &LoginAttempt{
-					UserID:     log.UserID,
-					Username:   log.ResourceID,
-					IPAddress:  log.IPAddress,
-					UserAgent:  log.UserAgent,
-					Success:    log.Action == "login",
-					FailReason: log.Details,
-					CreatedAt:  log.CreatedAt,
-				}
-				attempts = append(attempts, attempt)
-			}
-		}
-	}
-	return attempts, nil
+	return s.findLoginAttempts(since, func(attempt *LoginAttempt) bool {
+		return attempt.Username == username
+	}), nil
 }
 
 // GetLoginAttemptsByIP implements LoginAttemptStore interface
 func (s *MemoryStore) GetLoginAttemptsByIP(ipAddress string, since time.Time) ([]*LoginAttempt, error) {
+	return s.findLoginAttempts(since, func(attempt *LoginAttempt) bool {
+		return attempt.IPAddress == ipAddress
+	}), nil
+}
+
+// findLoginAttempts returns copies of the attempts made after since that match, newest first
+func (s *MemoryStore) findLoginAttempts(since time.Time, match func(*LoginAttempt) bool) []*LoginAttempt {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	attempts := make([]*LoginAttempt, 0)
-	for _, log := range s.auditLogs {
-		if log.IPAddress == ipAddress && log.CreatedAt.After(since) {
-			if log.Action == "login" || log.Action == "login_failed" {
-				attempt := &LoginAttempt{
-					UserID:     log.UserID,
-					Username:   log.ResourceID,
-					IPAddress:  log.IPAddress,
-					UserAgent:  log.UserAgent,
-					Success:    log.Action == "login",
-					FailReason: log.Details,
-					CreatedAt:  log.CreatedAt,
-				}
-				attempts = append(attempts, attempt)
-			}
+	for i := len(s.loginAttempts) - 1; i >= 0; i-- {
+		attempt := s.loginAttempts[i]
+		if attempt.CreatedAt.After(since) && match(attempt) {
+			attemptCopy := *attempt
+			attempts = append(attempts, &attemptCopy)
 		}
 	}
-	return attempts, nil
+	return attempts
 }
 
 // CleanupOldLoginAttempts implements LoginAttemptStore interface
-func (s *MemoryStore) CleanupOldLoginAttempts(before time.Time) error {
+func (s *MemoryStore) CleanupOldLoginAttempts(before time.Time) (int64, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// For memory store, we clean up from audit logs
-	newLogs := make([]*AuditLog, 0)
-	for _, log := range s.auditLogs {
-		if log.CreatedAt.After(before) || (log.Action != "login" && log.Action != "login_failed") {
-			newLogs = append(newLogs, log)
+	remaining := make([]*LoginAttempt, 0, len(s.loginAttempts))
+	for _, attempt := range s.loginAttempts {
+		if !attempt.CreatedAt.Before(before) {
+			remaining = append(remaining, attempt)
 		}
 	}
-	s.auditLogs = newLogs
-	return nil
+	removed := int64(len(s.loginAttempts) - len(remaining))
+	s.loginAttempts = remaining
+	return removed, nil
 }
 
 // === MemoryStore UserSession Methods ===
@@ -1121,6 +1305,19 @@ func (s *MemoryStore) CleanupOldLoginAttempts(before time.Time) error {
 var memoryUserSessions = make(map[string]*UserSession)
 var memoryUserSessionsByUser = make(map[uint][]string)
 
+// cloneUserSessions copies the session storage so a failed transaction can restore it
+func cloneUserSessions() (map[string]*UserSession, map[uint][]string) {
+	sessions := make(map[string]*UserSession, len(memoryUserSessions))
+	for k, v := range memoryUserSessions {
+		sessions[k] = v
+	}
+	sessionsByUser := make(map[uint][]string, len(memoryUserSessionsByUser))
+	for k, v := range memoryUserSessionsByUser {
+		sessionsByUser[k] = append([]string(nil), v...)
+	}
+	return sessions, sessionsByUser
+}
+
 // CreateUserSession implements UserSessionStore interface
 func (s *MemoryStore) CreateUserSession(session *UserSession) error {
 	s.mutex.Lock()
@@ -1228,7 +1425,7 @@ func (s *MemoryStore) DeleteUserSessions(userID uint) error {
 }
 
 // CleanupExpiredSessions implements UserSessionStore interface
-func (s *MemoryStore) CleanupExpiredSessions(before time.Time) error {
+func (s *MemoryStore) CleanupExpiredSessions(before time.Time) (int64, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -1256,5 +1453,358 @@ func (s *MemoryStore) CleanupExpiredSessions(before time.Time) error {
 		memoryUserSessionsByUser[session.UserID] = newUserSessions
 	}
 
+	return int64(len(expiredSessions)), nil
+}
+
+// === MemoryStore Alert Methods ===
+
+// CreateAlert implements AlertStore interface
+func (s *MemoryStore) CreateAlert(alert *Alert) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alert.ID = s.nextAlertID
+	s.nextAlertID++
+	alert.CreatedAt = time.Now()
+	alert.UpdatedAt = alert.CreatedAt
+
+	newAlert := *alert
+	s.alerts[newAlert.ID] = &newAlert
+	return nil
+}
+
+// GetAlertByID implements AlertStore interface
+func (s *MemoryStore) GetAlertByID(id uint) (*Alert, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	alert, exists := s.alerts[id]
+	if !exists {
+		return nil, ErrAlertNotFound
+	}
+	alertCopy := *alert
+	return &alertCopy, nil
+}
+
+// UpdateAlert implements AlertStore interface
+func (s *MemoryStore) UpdateAlert(alert *Alert) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.alerts[alert.ID]; !exists {
+		return ErrAlertNotFound
+	}
+	alert.UpdatedAt = time.Now()
+	updated := *alert
+	s.alerts[alert.ID] = &updated
+	return nil
+}
+
+// ListAlerts implements AlertStore interface
+func (s *MemoryStore) ListAlerts(filter AlertFilter, offset, limit int) ([]*Alert, int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	matched := make([]*Alert, 0)
+	for _, alert := range s.alerts {
+		if filter.Matches(alert) {
+			alertCopy := *alert
+			matched = append(matched, &alertCopy)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].LastSeen.Equal(matched[j].LastSeen) {
+			return matched[i].LastSeen.After(matched[j].LastSeen)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	total := int64(len(matched))
+	if filter.After != nil {
+		skip := 0
+		for skip < len(matched) && filter.After.Precedes(matched[skip]) {
+			skip++
+		}
+		matched = matched[skip:]
+	}
+
+	// Apply pagination
+	start := offset
+	end := offset + limit
+	if start > len(matched) {
+		return []*Alert{}, total, nil
+	}
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	return matched[start:end], total, nil
+}
+
+// FindOpenAlert implements AlertStore interface
+func (s *MemoryStore) FindOpenAlert(alertType string, since time.Time) (*Alert, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var latest *Alert
+	for _, alert := range s.alerts {
+		if alert.Type != alertType || alert.Resolved || alert.LastSeen.Before(since) {
+			continue
+		}
+		if latest == nil || alert.LastSeen.After(latest.LastSeen) {
+			latest = alert
+		}
+	}
+	if latest == nil {
+		return nil, ErrAlertNotFound
+	}
+	alertCopy := *latest
+	return &alertCopy, nil
+}
+
+// === MemoryStore UserPreference Methods ===
+
+// userRoleKey identifies the assignment of a role to a user
+type userRoleKey struct {
+	userID uint
+	roleID uint
+}
+
+// userPreferenceKey identifies the preferences of a user for a cluster
+type userPreferenceKey struct {
+	userID    uint
+	clusterID string
+}
+
+// GetUserPreference implements UserPreferenceStore interface
+func (s *MemoryStore) GetUserPreference(userID uint, clusterID string) (*UserPreference, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pref, exists := s.preferences[userPreferenceKey{userID, clusterID}]
+	if !exists {
+		return nil, ErrUserPreferenceNotFound
+	}
+	prefCopy := *pref
+	return &prefCopy, nil
+}
+
+// SaveUserPreference implements UserPreferenceStore interface
+func (s *MemoryStore) SaveUserPreference(pref *UserPreference) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := userPreferenceKey{pref.UserID, pref.ClusterID}
+	pref.UpdatedAt = time.Now()
+	if existing, exists := s.preferences[key]; exists {
+		pref.ID = existing.ID
+		pref.CreatedAt = existing.CreatedAt
+	} else {
+		pref.ID = s.nextPreferenceID
+		s.nextPreferenceID++
+		pref.CreatedAt = pref.UpdatedAt
+	}
+
+	saved := *pref
+	s.preferences[key] = &saved
+	return nil
+}
+
+// DeleteUserPreference implements UserPreferenceStore interface
+func (s *MemoryStore) DeleteUserPreference(userID uint, clusterID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.preferences, userPreferenceKey{userID, clusterID})
+	return nil
+}
+
+// ListUserPreferences implements UserPreferenceStore interface
+func (s *MemoryStore) ListUserPreferences(userID uint) ([]*UserPreference, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	prefs := make([]*UserPreference, 0)
+	for key, pref := range s.preferences {
+		if key.userID == userID {
+			prefCopy := *pref
+			prefs = append(prefs, &prefCopy)
+		}
+	}
+	sort.Slice(prefs, func(i, j int) bool {
+		return prefs[i].ClusterID < prefs[j].ClusterID
+	})
+	return prefs, nil
+}
+
+// === MemoryStore Invite Methods ===
+
+// CreateInvite implements InviteStore interface
+func (s *MemoryStore) CreateInvite(invite *Invite) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	invite.ID = s.nextInviteID
+	s.nextInviteID++
+	invite.CreatedAt = time.Now()
+	invite.UpdatedAt = invite.CreatedAt
+
+	newInvite := *invite
+	s.invites[newInvite.ID] = &newInvite
+	return nil
+}
+
+// GetInviteByID implements InviteStore interface
+func (s *MemoryStore) GetInviteByID(id uint) (*Invite, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	invite, exists := s.invites[id]
+	if !exists {
+		return nil, ErrInviteNotFound
+	}
+	inviteCopy := *invite
+	return &inviteCopy, nil
+}
+
+// UpdateInvite implements InviteStore interface
+func (s *MemoryStore) UpdateInvite(invite *Invite) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.invites[invite.ID]; !exists {
+		return ErrInviteNotFound
+	}
+	invite.UpdatedAt = time.Now()
+	updated := *invite
+	s.invites[invite.ID] = &updated
+	return nil
+}
+
+// ListInvites implements InviteStore interface
+func (s *MemoryStore) ListInvites(offset, limit int) ([]*Invite, int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	invites := make([]*Invite, 0, len(s.invites))
+	for _, invite := range s.invites {
+		inviteCopy := *invite
+		invites = append(invites, &inviteCopy)
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].ID > invites[j].ID
+	})
+
+	total := int64(len(invites))
+	start := offset
+	end := offset + limit
+	if start > len(invites) {
+		return []*Invite{}, total, nil
+	}
+	if end > len(invites) {
+		end = len(invites)
+	}
+	return invites[start:end], total, nil
+}
+
+// === MemoryStore ResourceTemplate Methods ===
+
+// CreateResourceTemplate implements ResourceTemplateStore interface
+func (s *MemoryStore) CreateResourceTemplate(template *ResourceTemplate) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.templates[template.Name]; exists {
+		return fmt.Errorf("resource template with name '%s' already exists", template.Name)
+	}
+	template.ID = s.nextTemplateID
+	s.nextTemplateID++
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+
+	newTemplate := *template
+	s.templates[newTemplate.Name] = &newTemplate
+	return nil
+}
+
+// GetResourceTemplate implements ResourceTemplateStore interface
+func (s *MemoryStore) GetResourceTemplate(name string) (*ResourceTemplate, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	template, exists := s.templates[name]
+	if !exists {
+		return nil, ErrResourceTemplateNotFound
+	}
+	templateCopy := *template
+	return &templateCopy, nil
+}
+
+// ListResourceTemplates implements ResourceTemplateStore interface
+func (s *MemoryStore) ListResourceTemplates() ([]*ResourceTemplate, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	templates := make([]*ResourceTemplate, 0, len(s.templates))
+	for _, template := range s.templates {
+		templateCopy := *template
+		templates = append(templates, &templateCopy)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// === MemoryStore Favorite Methods ===
+
+// CreateFavorite implements FavoriteStore interface
+func (s *MemoryStore) CreateFavorite(favorite *Favorite) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, existing := range s.favorites {
+		if existing.UserID == favorite.UserID && existing.ClusterID == favorite.ClusterID && existing.Namespace == favorite.Namespace &&
+			existing.Kind == favorite.Kind && existing.Name == favorite.Name {
+			return fmt.Errorf("favorite %s/%s/%s/%s already exists", favorite.ClusterID, favorite.Namespace, favorite.Kind, favorite.Name)
+		}
+	}
+	favorite.ID = s.nextFavoriteID
+	s.nextFavoriteID++
+	favorite.CreatedAt = time.Now()
+
+	newFavorite := *favorite
+	s.favorites[newFavorite.ID] = &newFavorite
+	return nil
+}
+
+// ListFavorites implements FavoriteStore interface
+func (s *MemoryStore) ListFavorites(userID uint) ([]*Favorite, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	favorites := make([]*Favorite, 0)
+	for _, favorite := range s.favorites {
+		if favorite.UserID == userID {
+			favoriteCopy := *favorite
+			favorites = append(favorites, &favoriteCopy)
+		}
+	}
+	sort.Slice(favorites, func(i, j int) bool {
+		return favorites[i].ID < favorites[j].ID
+	})
+	return favorites, nil
+}
+
+// DeleteFavorite implements FavoriteStore interface
+func (s *MemoryStore) DeleteFavorite(userID, id uint) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	favorite, exists := s.favorites[id]
+	if !exists || favorite.UserID != userID {
+		return ErrFavoriteNotFound
+	}
+	delete(s.favorites, id)
 	return nil
 }
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseStore_InitializeCreatesIndexes(t *testing.T) {
+	s := newTestDatabaseStore(t).(*DatabaseStore)
+	// Running the migration again must neither fail nor duplicate anything
+	require.NoError(t, s.Initialize())
+
+	indexes := []struct {
+		model interface{}
+		name  string
+	}{
+		{&AuditLog{}, "idx_audit_logs_user_created"},
+		{&AuditLog{}, "idx_audit_logs_action_created"},
+		{&UserSession{}, "idx_user_sessions_session_id"},
+		{&UserSession{}, "idx_user_sessions_user_active"},
+		{&LoginAttempt{}, "idx_login_attempts_ip_created"},
+	}
+	migrator := s.db.Migrator()
+	for _, index := range indexes {
+		assert.True(t, migrator.HasIndex(index.model, index.name), "index %s is missing", index.name)
+	}
+
+	var admins int64
+	require.NoError(t, s.db.Model(&User{}).Where("username = ?", "admin").Count(&admins).Error)
+	assert.Equal(t, int64(1), admins)
+}
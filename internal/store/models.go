@@ -59,6 +59,9 @@ type Cluster struct {
 	Region string `gorm:"type:varchar(50)" json:"region"`
 	// Version stores the detected Kubernetes Master version number
 	Version string `gorm:"type:varchar(20)" json:"version"`
+	// QPS and Burst override kubernetes.qps and kubernetes.burst for this cluster's client when positive
+	QPS   float32 `json:"qps,omitempty"`
+	Burst int     `json:"burst,omitempty"`
 
 	// --- Status and Labels ---
 	// Status is the cluster status set by administrators, such as "Active", "Maintenance", "Inactive"
@@ -86,6 +89,11 @@ type User struct {
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
 	DeletedAt     *time.Time `gorm:"index" json:"-"`
+
+	// PasswordChangedAt is when the password was last set; nil for users created before it was tracked
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	// MustChangePassword limits the user's logins to changing the password, set on the bootstrapped admin
+	MustChangePassword bool `gorm:"default:false" json:"must_change_password,omitempty"`
 }
 
 // TableName specifies the table name for User model
@@ -93,13 +101,15 @@ func (User) TableName() string {
 	return "users"
 }
 
-// HashPassword hashes the user's password using bcrypt
+// HashPassword hashes the user's password using bcrypt and records when it was changed
 func (u *User) HashPassword(password string) error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 	u.PasswordHash = string(hashedPassword)
+	now := time.Now()
+	u.PasswordChangedAt = &now
 	return nil
 }
 
@@ -143,9 +153,9 @@ type UserRole struct {
 	AssignedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"assigned_at"`
 
 	// Foreign key relationships
-	User           User  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
-	Role           Role  `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE" json:"-"`
-	AssignedByUser *User `gorm:"foreignKey:AssignedBy" json:"-"`
+	User           User  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-" bson:"-"`
+	Role           Role  `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE" json:"-" bson:"-"`
+	AssignedByUser *User `gorm:"foreignKey:AssignedBy" json:"-" bson:"-"`
 }
 
 // TableName specifies the table name for UserRole model
@@ -153,6 +163,9 @@ func (UserRole) TableName() string {
 	return "user_roles"
 }
 
+// ErrOAuthProviderNotFound is returned when a user has not linked the requested OAuth provider
+var ErrOAuthProviderNotFound = errors.New("OAuth provider not found")
+
 // OAuthProvider represents OAuth provider information for a user
 type OAuthProvider struct {
 	ID             uint       `gorm:"primaryKey" json:"id"`
@@ -166,7 +179,7 @@ type OAuthProvider struct {
 	UpdatedAt      time.Time  `json:"updated_at"`
 
 	// Foreign key relationship
-	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-" bson:"-"`
 }
 
 // TableName specifies the table name for OAuthProvider model
@@ -174,20 +187,25 @@ func (OAuthProvider) TableName() string {
 	return "oauth_providers"
 }
 
-// AuditLog represents audit log entries for security and compliance
+// AuditLog represents audit log entries for security and compliance.
+// The composite indexes serve the per-user and per-action listings, which are ordered by time.
 type AuditLog struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
-	UserID     *uint     `gorm:"index" json:"user_id"`
-	Action     string    `gorm:"type:varchar(100);not null;index" json:"action"`
+	UserID     *uint     `gorm:"index;index:idx_audit_logs_user_created,priority:1" json:"user_id"`
+	Action     string    `gorm:"type:varchar(100);not null;index;index:idx_audit_logs_action_created,priority:1" json:"action"`
 	Resource   string    `gorm:"type:varchar(100);index" json:"resource"`
 	ResourceID string    `gorm:"type:varchar(100)" json:"resource_id"`
 	IPAddress  string    `gorm:"type:varchar(45)" json:"ip_address"`
 	UserAgent  string    `gorm:"type:text" json:"user_agent"`
+	RequestID  string    `gorm:"type:varchar(64);index" json:"request_id,omitempty"`
+	SessionID  string    `gorm:"type:varchar(64)" json:"session_id,omitempty"`
+	Namespace  string    `gorm:"type:varchar(253)" json:"namespace,omitempty"` // Namespace of the resource the entry is about
+	Failed     bool      `json:"failed"`
 	Details    string    `gorm:"type:json" json:"details"`
-	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	CreatedAt  time.Time `gorm:"index;index:idx_audit_logs_user_created,priority:2;index:idx_audit_logs_action_created,priority:2" json:"created_at"`
 
 	// Foreign key relationship
-	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-"`
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-" bson:"-"`
 }
 
 // TableName specifies the table name for AuditLog model
@@ -195,19 +213,37 @@ func (AuditLog) TableName() string {
 	return "audit_logs"
 }
 
+// AuditLogFilter narrows down the audit logs that are grouped. Zero values do not filter.
+type AuditLogFilter struct {
+	Actions []string
+	UserID  *uint
+	Since   time.Time // Only entries created at or after this time
+	Until   time.Time // Only entries created before this time
+}
+
+// AuditLogGroup counts the audit logs of a user with the same resource ID, the resource action of
+// resource events, in one namespace
+type AuditLogGroup struct {
+	UserID     *uint
+	ResourceID string
+	Namespace  string
+	Count      int64
+	Failed     int64
+}
+
 // LoginAttempt represents login attempt tracking for security
 type LoginAttempt struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
 	UserID     *uint     `gorm:"index" json:"user_id"`
 	Username   string    `gorm:"type:varchar(50);index" json:"username"`
-	IPAddress  string    `gorm:"type:varchar(45);index" json:"ip_address"`
+	IPAddress  string    `gorm:"type:varchar(45);index;index:idx_login_attempts_ip_created,priority:1" json:"ip_address"`
 	UserAgent  string    `gorm:"type:text" json:"user_agent"`
 	Success    bool      `gorm:"index" json:"success"`
 	FailReason string    `gorm:"type:varchar(255)" json:"fail_reason"`
-	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	CreatedAt  time.Time `gorm:"index;index:idx_login_attempts_ip_created,priority:2" json:"created_at"`
 
 	// Foreign key relationship
-	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-"`
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-" bson:"-"`
 }
 
 // TableName specifies the table name for LoginAttempt model
@@ -218,20 +254,261 @@ func (LoginAttempt) TableName() string {
 // UserSession represents active user sessions for session management
 type UserSession struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	UserID    uint      `gorm:"not null;index;index:idx_user_sessions_user_active,priority:1" json:"user_id"`
 	SessionID string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"session_id"`
 	IPAddress string    `gorm:"type:varchar(45)" json:"ip_address"`
 	UserAgent string    `gorm:"type:text" json:"user_agent"`
 	CreatedAt time.Time `json:"created_at"`
 	LastSeen  time.Time `json:"last_seen"`
 	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
-	IsActive  bool      `gorm:"default:true;index" json:"is_active"`
+	IsActive  bool      `gorm:"default:true;index;index:idx_user_sessions_user_active,priority:2" json:"is_active"`
 
 	// Foreign key relationship
-	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-" bson:"-"`
 }
 
 // TableName specifies the table name for UserSession model
 func (UserSession) TableName() string {
 	return "user_sessions"
 }
+
+// ErrUserPreferenceNotFound is returned when a user has no preferences stored for a cluster
+var ErrUserPreferenceNotFound = errors.New("user preference not found")
+
+// UserPreference holds a user's preferences for one cluster, such as the namespace selected
+// when a request names none. There is at most one row per user and cluster.
+type UserPreference struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	UserID           uint      `gorm:"not null;uniqueIndex:idx_user_preferences_user_cluster,priority:1" json:"user_id"`
+	ClusterID        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_user_preferences_user_cluster,priority:2" json:"cluster_id"`
+	DefaultNamespace string    `gorm:"type:varchar(63)" json:"default_namespace"`
+	DefaultCluster   bool      `json:"default_cluster"`           // Cluster used when a request names none, at most one per user
+	Settings         string    `gorm:"type:json" json:"settings"` // Free-form UI preferences as a JSON object
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	// Foreign key relationship
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-" bson:"-"`
+}
+
+// TableName specifies the table name for UserPreference model
+func (UserPreference) TableName() string {
+	return "user_preferences"
+}
+
+// UintList is a list of IDs stored as a JSON array
+type UintList []uint
+
+// Value - implements driver.Valuer interface, called by GORM when writing
+func (l UintList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan - implements sql.Scanner interface, called by GORM when reading
+func (l *UintList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	}
+	return errors.New("type assertion to []byte failed")
+}
+
+// Invite statuses
+const (
+	InviteStatusPending  = "pending"
+	InviteStatusAccepted = "accepted"
+	InviteStatusExpired  = "expired"
+)
+
+// ErrInviteNotFound is returned when a requested invite does not exist
+var ErrInviteNotFound = errors.New("invite not found")
+
+// Invite lets the owner of an email address create an account with roles chosen by an administrator.
+// It is pending until accepted once, or until it expires.
+type Invite struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Email      string     `gorm:"type:varchar(100);not null;index" json:"email"`
+	RoleIDs    UintList   `gorm:"type:json" json:"role_ids"`
+	Status     string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	InvitedBy  *uint      `json:"invited_by,omitempty"`
+	AcceptedBy *uint      `json:"accepted_by,omitempty"` // The user created by accepting the invite
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Invite model
+func (Invite) TableName() string {
+	return "invites"
+}
+
+// StatusAt returns the status of the invite at the given time, a pending invite past its expiry being expired
+func (i *Invite) StatusAt(now time.Time) string {
+	if i.Status == InviteStatusPending && !now.Before(i.ExpiresAt) {
+		return InviteStatusExpired
+	}
+	return i.Status
+}
+
+// ErrResourceTemplateNotFound is returned when a requested resource template does not exist
+var ErrResourceTemplateNotFound = errors.New("resource template not found")
+
+// TemplateParameter is a value a resource template is rendered with
+type TemplateParameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Default     string `json:"default,omitempty"` // Used when an optional parameter is not given
+}
+
+// TemplateParameters is a list of template parameters stored as a JSON array
+type TemplateParameters []TemplateParameter
+
+// Value - implements driver.Valuer interface, called by GORM when writing
+func (p TemplateParameters) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan - implements sql.Scanner interface, called by GORM when reading
+func (p *TemplateParameters) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	return errors.New("type assertion to []byte failed")
+}
+
+// ResourceTemplate is a named Go template rendering Kubernetes manifests from its parameters
+type ResourceTemplate struct {
+	ID          uint               `gorm:"primaryKey" json:"id"`
+	Name        string             `gorm:"type:varchar(63);uniqueIndex;not null" json:"name"`
+	Description string             `gorm:"type:text" json:"description"`
+	Parameters  TemplateParameters `gorm:"type:json" json:"parameters"`
+	Manifest    string             `gorm:"type:text;not null" json:"manifest"` // YAML documents with {{ .param }} placeholders
+	CreatedBy   *uint              `json:"created_by,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// TableName specifies the table name for ResourceTemplate model
+func (ResourceTemplate) TableName() string {
+	return "resource_templates"
+}
+
+// ErrFavoriteNotFound is returned when a user has no favorite with the requested ID
+var ErrFavoriteNotFound = errors.New("favorite not found")
+
+// Favorite is a Kubernetes resource a user bookmarked for quick access
+type Favorite struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_favorites_user_resource,priority:1" json:"user_id"`
+	ClusterID string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_favorites_user_resource,priority:2" json:"cluster_id"`
+	Namespace string    `gorm:"type:varchar(63);uniqueIndex:idx_favorites_user_resource,priority:3" json:"namespace"` // Empty for cluster-scoped resources
+	Kind      string    `gorm:"type:varchar(253);not null;uniqueIndex:idx_favorites_user_resource,priority:4" json:"kind"`
+	Name      string    `gorm:"type:varchar(253);not null;uniqueIndex:idx_favorites_user_resource,priority:5" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Foreign key relationship
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-" bson:"-"`
+}
+
+// TableName specifies the table name for Favorite model
+func (Favorite) TableName() string {
+	return "favorites"
+}
+
+// ErrAlertNotFound is returned when a requested alert does not exist
+var ErrAlertNotFound = errors.New("alert not found")
+
+// Alert represents a persisted monitoring alert.
+// Repeated alerts of the same type are folded into one row by bumping Count and LastSeen.
+type Alert struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Level          string     `gorm:"type:varchar(20);not null;index" json:"level"`
+	Type           string     `gorm:"type:varchar(100);not null;index" json:"type"`
+	Title          string     `gorm:"type:varchar(255)" json:"title"`
+	Description    string     `gorm:"type:text" json:"description"`
+	Source         string     `gorm:"type:varchar(100)" json:"source"`
+	Data           string     `gorm:"type:json" json:"data"`
+	Count          int        `gorm:"default:1" json:"count"`
+	FirstSeen      time.Time  `json:"first_seen"`
+	LastSeen       time.Time  `gorm:"index" json:"last_seen"`
+	Acknowledged   bool       `gorm:"default:false" json:"acknowledged"`
+	AcknowledgedBy *uint      `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	Resolved       bool       `gorm:"default:false;index" json:"resolved"`
+	ResolvedBy     *uint      `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Alert model
+func (Alert) TableName() string {
+	return "alerts"
+}
+
+// AlertFilter narrows down alert listings. Zero values do not filter.
+type AlertFilter struct {
+	Level    string
+	Type     string
+	Resolved *bool
+	Since    time.Time // Only alerts last seen at or after this time
+	Until    time.Time // Only alerts last seen before this time
+
+	// After continues a listing after this alert, it doesn't narrow the total count
+	After *AlertCursor
+}
+
+// AlertCursor is the position of an alert in listings, which are ordered by last seen time then ID, newest first
+type AlertCursor struct {
+	LastSeen time.Time `json:"last_seen"`
+	ID       uint      `json:"id"`
+}
+
+// CursorOf returns the position of an alert in listings
+func CursorOf(alert *Alert) AlertCursor {
+	return AlertCursor{LastSeen: alert.LastSeen, ID: alert.ID}
+}
+
+// Precedes reports whether alert is listed before the cursor position
+func (c AlertCursor) Precedes(alert *Alert) bool {
+	return alert.LastSeen.After(c.LastSeen) || (alert.LastSeen.Equal(c.LastSeen) && alert.ID >= c.ID)
+}
+
+// Matches reports whether an alert passes the filter
+func (f AlertFilter) Matches(alert *Alert) bool {
+	if f.Level != "" && alert.Level != f.Level {
+		return false
+	}
+	if f.Type != "" && alert.Type != f.Type {
+		return false
+	}
+	if f.Resolved != nil && alert.Resolved != *f.Resolved {
+		return false
+	}
+	if !f.Since.IsZero() && alert.LastSeen.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !alert.LastSeen.Before(f.Until) {
+		return false
+	}
+	return true
+}
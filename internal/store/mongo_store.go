@@ -0,0 +1,1085 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection names used by MongoStore
+const (
+	mongoClustersCollection      = "clusters"
+	mongoUsersCollection         = "users"
+	mongoRolesCollection         = "roles"
+	mongoUserRolesCollection     = "userRoles"
+	mongoOAuthCollection         = "oauth"
+	mongoAuditLogsCollection     = "auditLogs"
+	mongoSessionsCollection      = "sessions"
+	mongoLoginAttemptsCollection = "loginAttempts"
+	mongoAlertsCollection        = "alerts"
+	mongoPreferencesCollection   = "userPreferences"
+	mongoInvitesCollection       = "invites"
+	mongoTemplatesCollection     = "resourceTemplates"
+	mongoFavoritesCollection     = "favorites"
+	mongoCountersCollection      = "counters"
+)
+
+const (
+	// mongoDefaultDatabase is used when the configuration names no database
+	mongoDefaultDatabase = "cilikube"
+	// mongoOperationTimeout bounds a single MongoStore operation
+	mongoOperationTimeout = 10 * time.Second
+	// mongoTransactionTimeout bounds a whole transaction
+	mongoTransactionTimeout = 30 * time.Second
+)
+
+// MongoStore implements Store interface using MongoDB.
+// Documents are the store models encoded with the driver's default field names (the lowercased Go
+// field name, e.g. "userid"), and numeric IDs come from a counters collection so they behave like
+// the auto-increment IDs of DatabaseStore.
+type MongoStore struct {
+	client *mongo.Client
+	db     *mongo.Database
+	admin  adminBootstrap
+
+	// supportsTransactions is false on a standalone server, which cannot run multi-document transactions
+	supportsTransactions bool
+	// txCtx is set on the copy of the store handed to a Transaction callback
+	txCtx context.Context
+}
+
+// NewMongoStore connects to the MongoDB database configured for storage
+func NewMongoStore(config *configs.Config) (Store, error) {
+	dbConfig := config.GetStorageDatabase()
+	databaseName := dbConfig.Database
+	if databaseName == "" {
+		databaseName = mongoDefaultDatabase
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoOperationTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(dbConfig.MongoURI()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	s := &MongoStore{client: client, db: client.Database(databaseName), admin: newAdminBootstrap(config)}
+	s.supportsTransactions = s.detectTransactionSupport(ctx)
+	if !s.supportsTransactions {
+		log.Println("MongoDB is a standalone server, store transactions will not be atomic")
+	}
+	return s, nil
+}
+
+// detectTransactionSupport reports whether the server is a replica set member or a mongos router
+func (s *MongoStore) detectTransactionSupport(ctx context.Context) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := s.db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
+// Initialize implements Store interface for MongoDB
+func (s *MongoStore) Initialize() error {
+	if err := s.createIndexes(); err != nil {
+		return fmt.Errorf("failed to create MongoDB indexes: %w", err)
+	}
+
+	for _, role := range defaultRoles() {
+		if _, err := s.GetRoleByName(role.Name); err == nil {
+			continue // Role already exists
+		} else if !errors.Is(err, mongo.ErrNoDocuments) {
+			return fmt.Errorf("failed to look up role %s: %w", role.Name, err)
+		}
+		if err := s.CreateRole(role); err != nil {
+			return fmt.Errorf("failed to create role %s: %w", role.Name, err)
+		}
+	}
+
+	if err := s.createDefaultAdminUser(); err != nil {
+		return fmt.Errorf("failed to create default admin user: %w", err)
+	}
+	return nil
+}
+
+// createIndexes creates the unique and lookup indexes; creating an existing index is a no-op
+func (s *MongoStore) createIndexes() error {
+	unique := options.Index().SetUnique(true)
+	indexes := map[string][]mongo.IndexModel{
+		mongoClustersCollection: {
+			{Keys: bson.D{{Key: "id", Value: 1}}, Options: unique},
+			{Keys: bson.D{{Key: "name", Value: 1}}, Options: unique},
+		},
+		mongoUsersCollection: {
+			{Keys: bson.D{{Key: "id", Value: 1}}, Options: unique},
+			{Keys: bson.D{{Key: "username", Value: 1}}, Options: unique},
+			{Keys: bson.D{{Key: "email", Value: 1}}, Options: unique},
+		},
+		mongoRolesCollection: {
+			{Keys: bson.D{{Key: "id", Value: 1}}, Options: unique},
+			{Keys: bson.D{{Key: "name", Value: 1}}, Options: unique},
+		},
+		mongoUserRolesCollection: {
+			{Keys: bson.D{{Key: "userid", Value: 1}, {Key: "roleid", Value: 1}}, Options: unique},
+			{Keys: bson.D{{Key: "roleid", Value: 1}}},
+		},
+		mongoOAuthCollection: {
+			{Keys: bson.D{{Key: "userid", Value: 1}, {Key: "provider", Value: 1}}, Options: unique},
+			{Keys: bson.D{{Key: "provider", Value: 1}, {Key: "provideruserid", Value: 1}}},
+		},
+		mongoAuditLogsCollection: {
+			{Keys: bson.D{{Key: "userid", Value: 1}, {Key: "createdat", Value: -1}}},
+			{Keys: bson.D{{Key: "action", Value: 1}, {Key: "createdat", Value: -1}}},
+			{Keys: bson.D{{Key: "createdat", Value: -1}}},
+		},
+		mongoSessionsCollection: {
+			{Keys: bson.D{{Key: "sessionid", Value: 1}}, Options: unique},
+			{Keys: bson.D{{Key: "userid", Value: 1}, {Key: "isactive", Value: 1}}},
+			{Keys: bson.D{{Key: "expiresat", Value: 1}}},
+		},
+		mongoLoginAttemptsCollection: {
+			{Keys: bson.D{{Key: "ipaddress", Value: 1}, {Key: "createdat", Value: -1}}},
+			{Keys: bson.D{{Key: "username", Value: 1}, {Key: "createdat", Value: -1}}},
+			{Keys: bson.D{{Key: "userid", Value: 1}, {Key: "createdat", Value: -1}}},
+		},
+		mongoAlertsCollection: {
+			{Keys: bson.D{{Key: "id", Value: 1}}, Options: unique},
+			{Keys: bson.D{{Key: "type", Value: 1}, {Key: "resolved", Value: 1}, {Key: "lastseen", Value: -1}}},
+			{Keys: bson.D{{Key: "lastseen", Value: -1}}},
+		},
+		mongoPreferencesCollection: {
+			{Keys: bson.D{{Key: "userid", Value: 1}, {Key: "clusterid", Value: 1}}, Options: unique},
+		},
+		mongoInvitesCollection: {
+			{Keys: bson.D{{Key: "id", Value: 1}}, Options: unique},
+		},
+		mongoTemplatesCollection: {
+			{Keys: bson.D{{Key: "name", Value: 1}}, Options: unique},
+		},
+		mongoFavoritesCollection: {
+			{Keys: bson.D{{Key: "id", Value: 1}}, Options: unique},
+			{Keys: bson.D{{Key: "userid", Value: 1}, {Key: "clusterid", Value: 1}, {Key: "namespace", Value: 1}, {Key: "kind", Value: 1}, {Key: "name", Value: 1}}, Options: unique},
+		},
+	}
+
+	for collection, models := range indexes {
+		ctx, cancel := s.context()
+		_, err := s.db.Collection(collection).Indexes().CreateMany(ctx, models)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("collection %s: %w", collection, err)
+		}
+	}
+	return nil
+}
+
+// createDefaultAdminUser creates the bootstrap admin user with the admin role when no users exist
+func (s *MongoStore) createDefaultAdminUser() error {
+	ctx, cancel := s.context()
+	users, err := s.db.Collection(mongoUsersCollection).CountDocuments(ctx, bson.M{})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to count users: %w", err)
+	}
+	if users > 0 {
+		return nil // The admin was created before, or users were added another way
+	}
+
+	adminUser, err := s.admin.user()
+	if err != nil {
+		return err
+	}
+
+	return s.Transaction(func(tx Store) error {
+		if err := tx.CreateUser(adminUser); err != nil {
+			return fmt.Errorf("failed to create admin user: %w", err)
+		}
+		adminRole, err := tx.GetRoleByName("admin")
+		if err != nil {
+			return fmt.Errorf("failed to find admin role: %w", err)
+		}
+		if err := tx.AssignRole(adminUser.ID, adminRole.ID); err != nil {
+			return fmt.Errorf("failed to assign admin role: %w", err)
+		}
+		return nil
+	})
+}
+
+// Close implements Store interface for MongoDB
+func (s *MongoStore) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoOperationTimeout)
+	defer cancel()
+	return s.client.Disconnect(ctx)
+}
+
+// Transaction implements Store interface using a MongoDB transaction. On a standalone server,
+// which has no transactions, fn runs directly against the store.
+func (s *MongoStore) Transaction(fn func(Store) error) error {
+	if s.txCtx != nil {
+		return fn(s) // Already inside a transaction
+	}
+	if !s.supportsTransactions {
+		return fn(s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTransactionTimeout)
+	defer cancel()
+
+	session, err := s.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start MongoDB session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		tx := &MongoStore{client: s.client, db: s.db, supportsTransactions: true, txCtx: sessCtx}
+		return nil, fn(tx)
+	})
+	return err
+}
+
+// context returns the context for one operation, which is the session context inside a transaction
+func (s *MongoStore) context() (context.Context, context.CancelFunc) {
+	if s.txCtx != nil {
+		return s.txCtx, func() {}
+	}
+	return context.WithTimeout(context.Background(), mongoOperationTimeout)
+}
+
+// nextID returns the next value of the named counter
+func (s *MongoStore) nextID(ctx context.Context, name string) (uint, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := s.db.Collection(mongoCountersCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": name},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate %s ID: %w", name, err)
+	}
+	return uint(counter.Seq), nil
+}
+
+// mongoFindOne decodes the first document matching filter
+func mongoFindOne[T any](ctx context.Context, collection *mongo.Collection, filter interface{}, opts ...*options.FindOneOptions) (*T, error) {
+	var doc T
+	if err := collection.FindOne(ctx, filter, opts...).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// mongoFind decodes every document matching filter
+func mongoFind[T any](ctx context.Context, collection *mongo.Collection, filter interface{}, opts ...*options.FindOptions) ([]*T, error) {
+	cursor, err := collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*T, 0)
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// mongoPage lists one page of documents matching filter in the given order along with the total count
+func mongoPage[T any](ctx context.Context, collection *mongo.Collection, filter interface{}, sort bson.D, offset, limit int) ([]*T, int64, error) {
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts := options.Find().SetSkip(int64(offset))
+	if sort != nil {
+		opts.SetSort(sort)
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	docs, err := mongoFind[T](ctx, collection, filter, opts)
+	return docs, total, err
+}
+
+// newestFirst sorts documents by creation time, newest first
+var newestFirst = bson.D{{Key: "createdat", Value: -1}}
+
+// === MongoStore Cluster Methods ===
+
+func (s *MongoStore) CreateCluster(cluster *Cluster) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	if cluster.ID == "" {
+		cluster.ID = uuid.NewString()
+	}
+	now := time.Now()
+	cluster.CreatedAt, cluster.UpdatedAt = now, now
+	_, err := s.db.Collection(mongoClustersCollection).InsertOne(ctx, cluster)
+	return err
+}
+
+func (s *MongoStore) GetClusterByID(id string) (*Cluster, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFindOne[Cluster](ctx, s.db.Collection(mongoClustersCollection), bson.M{"id": id})
+}
+
+func (s *MongoStore) GetClusterByName(name string) (*Cluster, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFindOne[Cluster](ctx, s.db.Collection(mongoClustersCollection), bson.M{"name": name})
+}
+
+func (s *MongoStore) GetAllClusters() ([]Cluster, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	cursor, err := s.db.Collection(mongoClustersCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	clusters := make([]Cluster, 0)
+	err = cursor.All(ctx, &clusters)
+	return clusters, err
+}
+
+func (s *MongoStore) UpdateCluster(cluster *Cluster) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	cluster.UpdatedAt = time.Now()
+	_, err := s.db.Collection(mongoClustersCollection).ReplaceOne(ctx, bson.M{"id": cluster.ID}, cluster)
+	return err
+}
+
+func (s *MongoStore) DeleteClusterByName(name string) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.db.Collection(mongoClustersCollection).DeleteOne(ctx, bson.M{"name": name})
+	return err
+}
+
+func (s *MongoStore) DeleteClusterByID(id string) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.db.Collection(mongoClustersCollection).DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
+
+// === MongoStore User Methods ===
+
+func (s *MongoStore) CreateUser(user *User) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoUsersCollection)
+	if err != nil {
+		return err
+	}
+	user.ID = id
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	_, err = s.db.Collection(mongoUsersCollection).InsertOne(ctx, user)
+	return err
+}
+
+func (s *MongoStore) GetUserByID(id uint) (*User, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFindOne[User](ctx, s.db.Collection(mongoUsersCollection), bson.M{"id": id})
+}
+
+func (s *MongoStore) GetUserByUsername(username string) (*User, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFindOne[User](ctx, s.db.Collection(mongoUsersCollection), bson.M{"username": username})
+}
+
+func (s *MongoStore) GetUserByEmail(email string) (*User, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFindOne[User](ctx, s.db.Collection(mongoUsersCollection), bson.M{"email": email})
+}
+
+func (s *MongoStore) UpdateUser(user *User) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	user.UpdatedAt = time.Now()
+	_, err := s.db.Collection(mongoUsersCollection).ReplaceOne(ctx, bson.M{"id": user.ID}, user)
+	return err
+}
+
+func (s *MongoStore) DeleteUser(id uint) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	if _, err := s.db.Collection(mongoUsersCollection).DeleteOne(ctx, bson.M{"id": id}); err != nil {
+		return err
+	}
+	// Mirror the cascading foreign keys of the SQL schema
+	if _, err := s.db.Collection(mongoUserRolesCollection).DeleteMany(ctx, bson.M{"userid": id}); err != nil {
+		return err
+	}
+	if _, err := s.db.Collection(mongoOAuthCollection).DeleteMany(ctx, bson.M{"userid": id}); err != nil {
+		return err
+	}
+	if _, err := s.db.Collection(mongoPreferencesCollection).DeleteMany(ctx, bson.M{"userid": id}); err != nil {
+		return err
+	}
+	if _, err := s.db.Collection(mongoFavoritesCollection).DeleteMany(ctx, bson.M{"userid": id}); err != nil {
+		return err
+	}
+	_, err := s.db.Collection(mongoSessionsCollection).DeleteMany(ctx, bson.M{"userid": id})
+	return err
+}
+
+func (s *MongoStore) ListUsers(offset, limit int) ([]*User, int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoPage[User](ctx, s.db.Collection(mongoUsersCollection), bson.M{}, bson.D{{Key: "id", Value: 1}}, offset, limit)
+}
+
+// === MongoStore Role Methods ===
+
+func (s *MongoStore) CreateRole(role *Role) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoRolesCollection)
+	if err != nil {
+		return err
+	}
+	role.ID = id
+	now := time.Now()
+	role.CreatedAt, role.UpdatedAt = now, now
+	_, err = s.db.Collection(mongoRolesCollection).InsertOne(ctx, role)
+	return err
+}
+
+func (s *MongoStore) GetRoleByID(id uint) (*Role, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFindOne[Role](ctx, s.db.Collection(mongoRolesCollection), bson.M{"id": id})
+}
+
+func (s *MongoStore) GetRoleByName(name string) (*Role, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFindOne[Role](ctx, s.db.Collection(mongoRolesCollection), bson.M{"name": name})
+}
+
+func (s *MongoStore) UpdateRole(role *Role) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	role.UpdatedAt = time.Now()
+	_, err := s.db.Collection(mongoRolesCollection).ReplaceOne(ctx, bson.M{"id": role.ID}, role)
+	return err
+}
+
+func (s *MongoStore) DeleteRole(id uint) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	if _, err := s.db.Collection(mongoRolesCollection).DeleteOne(ctx, bson.M{"id": id}); err != nil {
+		return err
+	}
+	_, err := s.db.Collection(mongoUserRolesCollection).DeleteMany(ctx, bson.M{"roleid": id})
+	return err
+}
+
+func (s *MongoStore) ListRoles() ([]*Role, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFind[Role](ctx, s.db.Collection(mongoRolesCollection), bson.M{}, options.Find().SetSort(bson.D{{Key: "id", Value: 1}}))
+}
+
+// === MongoStore UserRole Methods ===
+
+func (s *MongoStore) AssignRole(userID, roleID uint) error {
+	return s.AssignRoleBy(userID, roleID, 0)
+}
+
+func (s *MongoStore) AssignRoleBy(userID, roleID, assignedBy uint) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	// Upsert so assigning a role twice is not an error and keeps the original assignment
+	userRole := UserRole{UserID: userID, RoleID: roleID, AssignedBy: assignedBy, AssignedAt: time.Now()}
+	_, err := s.db.Collection(mongoUserRolesCollection).UpdateOne(ctx,
+		bson.M{"userid": userID, "roleid": roleID},
+		bson.M{"$setOnInsert": userRole},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (s *MongoStore) RemoveRole(userID, roleID uint) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.db.Collection(mongoUserRolesCollection).DeleteOne(ctx, bson.M{"userid": userID, "roleid": roleID})
+	return err
+}
+
+func (s *MongoStore) GetUserRoles(userID uint) ([]*Role, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	roleIDs, err := s.db.Collection(mongoUserRolesCollection).Distinct(ctx, "roleid", bson.M{"userid": userID})
+	if err != nil {
+		return nil, err
+	}
+	return mongoFind[Role](ctx, s.db.Collection(mongoRolesCollection), bson.M{"id": bson.M{"$in": roleIDs}})
+}
+
+func (s *MongoStore) GetRoleUsers(roleID uint) ([]*User, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	userIDs, err := s.db.Collection(mongoUserRolesCollection).Distinct(ctx, "userid", bson.M{"roleid": roleID})
+	if err != nil {
+		return nil, err
+	}
+	return mongoFind[User](ctx, s.db.Collection(mongoUsersCollection), bson.M{"id": bson.M{"$in": userIDs}})
+}
+
+func (s *MongoStore) HasRole(userID, roleID uint) (bool, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	count, err := s.db.Collection(mongoUserRolesCollection).CountDocuments(ctx, bson.M{"userid": userID, "roleid": roleID})
+	return count > 0, err
+}
+
+func (s *MongoStore) GetRoleAssignments(roleID uint) ([]*UserRole, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFind[UserRole](ctx, s.db.Collection(mongoUserRolesCollection), bson.M{"roleid": roleID}, options.Find().SetSort(bson.D{{Key: "userid", Value: 1}}))
+}
+
+// === MongoStore OAuth Methods ===
+
+func (s *MongoStore) CreateOAuthProvider(provider *OAuthProvider) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoOAuthCollection)
+	if err != nil {
+		return err
+	}
+	provider.ID = id
+	now := time.Now()
+	provider.CreatedAt, provider.UpdatedAt = now, now
+	_, err = s.db.Collection(mongoOAuthCollection).InsertOne(ctx, provider)
+	return err
+}
+
+func (s *MongoStore) GetOAuthProvider(userID uint, provider string) (*OAuthProvider, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	oauthProvider, err := mongoFindOne[OAuthProvider](ctx, s.db.Collection(mongoOAuthCollection), bson.M{"userid": userID, "provider": provider})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrOAuthProviderNotFound
+	}
+	return oauthProvider, err
+}
+
+func (s *MongoStore) GetOAuthProviderByProviderUserID(provider, providerUserID string) (*OAuthProvider, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFindOne[OAuthProvider](ctx, s.db.Collection(mongoOAuthCollection), bson.M{"provider": provider, "provideruserid": providerUserID})
+}
+
+func (s *MongoStore) UpdateOAuthProvider(provider *OAuthProvider) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	provider.UpdatedAt = time.Now()
+	_, err := s.db.Collection(mongoOAuthCollection).ReplaceOne(ctx, bson.M{"id": provider.ID}, provider)
+	return err
+}
+
+func (s *MongoStore) DeleteOAuthProvider(userID uint, provider string) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.db.Collection(mongoOAuthCollection).DeleteOne(ctx, bson.M{"userid": userID, "provider": provider})
+	return err
+}
+
+func (s *MongoStore) ListUserOAuthProviders(userID uint) ([]*OAuthProvider, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFind[OAuthProvider](ctx, s.db.Collection(mongoOAuthCollection), bson.M{"userid": userID})
+}
+
+// === MongoStore AuditLog Methods ===
+
+func (s *MongoStore) CreateAuditLog(log *AuditLog) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoAuditLogsCollection)
+	if err != nil {
+		return err
+	}
+	log.ID = id
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+	_, err = s.db.Collection(mongoAuditLogsCollection).InsertOne(ctx, log)
+	return err
+}
+
+func (s *MongoStore) GetAuditLogsByUserID(userID uint, offset, limit int) ([]*AuditLog, int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoPage[AuditLog](ctx, s.db.Collection(mongoAuditLogsCollection), bson.M{"userid": userID}, newestFirst, offset, limit)
+}
+
+func (s *MongoStore) GetAuditLogsByAction(action string, offset, limit int) ([]*AuditLog, int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoPage[AuditLog](ctx, s.db.Collection(mongoAuditLogsCollection), bson.M{"action": action}, newestFirst, offset, limit)
+}
+
+func (s *MongoStore) ListAuditLogs(offset, limit int) ([]*AuditLog, int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoPage[AuditLog](ctx, s.db.Collection(mongoAuditLogsCollection), bson.M{}, newestFirst, offset, limit)
+}
+
+func (s *MongoStore) GroupAuditLogs(filter AuditLogFilter) ([]*AuditLogGroup, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	match := bson.M{}
+	if len(filter.Actions) > 0 {
+		match["action"] = bson.M{"$in": filter.Actions}
+	}
+	if filter.UserID != nil {
+		match["userid"] = *filter.UserID
+	}
+	createdAt := bson.M{}
+	if !filter.Since.IsZero() {
+		createdAt["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		createdAt["$lt"] = filter.Until
+	}
+	if len(createdAt) > 0 {
+		match["createdat"] = createdAt
+	}
+
+	cursor, err := s.db.Collection(mongoAuditLogsCollection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"userid": "$userid", "resourceid": "$resourceid", "namespace": "$namespace"},
+			"count":  bson.M{"$sum": 1},
+			"failed": bson.M{"$sum": bson.M{"$cond": bson.A{"$failed", 1, 0}}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Key struct {
+			UserID     *uint  `bson:"userid"`
+			ResourceID string `bson:"resourceid"`
+			Namespace  string `bson:"namespace"`
+		} `bson:"_id"`
+		Count  int64 `bson:"count"`
+		Failed int64 `bson:"failed"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	groups := make([]*AuditLogGroup, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, &AuditLogGroup{
+			UserID:     row.Key.UserID,
+			ResourceID: row.Key.ResourceID,
+			Namespace:  row.Key.Namespace,
+			Count:      row.Count,
+			Failed:     row.Failed,
+		})
+	}
+	return groups, nil
+}
+
+// === MongoStore LoginAttempt Methods ===
+
+func (s *MongoStore) CreateLoginAttempt(attempt *LoginAttempt) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoLoginAttemptsCollection)
+	if err != nil {
+		return err
+	}
+	attempt.ID = id
+	if attempt.CreatedAt.IsZero() {
+		attempt.CreatedAt = time.Now()
+	}
+	_, err = s.db.Collection(mongoLoginAttemptsCollection).InsertOne(ctx, attempt)
+	return err
+}
+
+// loginAttemptsSince lists the login attempts matching filter made after since, newest first
+func (s *MongoStore) loginAttemptsSince(filter bson.M, since time.Time) ([]*LoginAttempt, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	filter["createdat"] = bson.M{"$gt": since}
+	return mongoFind[LoginAttempt](ctx, s.db.Collection(mongoLoginAttemptsCollection), filter, options.Find().SetSort(newestFirst))
+}
+
+func (s *MongoStore) GetLoginAttemptsByUserID(userID uint, since time.Time) ([]*LoginAttempt, error) {
+	return s.loginAttemptsSince(bson.M{"userid": userID}, since)
+}
+
+func (s *MongoStore) GetLoginAttemptsByUsername(username string, since time.Time) ([]*LoginAttempt, error) {
+	return s.loginAttemptsSince(bson.M{"username": username}, since)
+}
+
+func (s *MongoStore) GetLoginAttemptsByIP(ipAddress string, since time.Time) ([]*LoginAttempt, error) {
+	return s.loginAttemptsSince(bson.M{"ipaddress": ipAddress}, since)
+}
+
+func (s *MongoStore) CleanupOldLoginAttempts(before time.Time) (int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	result, err := s.db.Collection(mongoLoginAttemptsCollection).DeleteMany(ctx, bson.M{"createdat": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// === MongoStore UserSession Methods ===
+
+func (s *MongoStore) CreateUserSession(session *UserSession) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoSessionsCollection)
+	if err != nil {
+		return err
+	}
+	session.ID = id
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+	_, err = s.db.Collection(mongoSessionsCollection).InsertOne(ctx, session)
+	return err
+}
+
+func (s *MongoStore) GetUserSession(sessionID string) (*UserSession, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFindOne[UserSession](ctx, s.db.Collection(mongoSessionsCollection), bson.M{"sessionid": sessionID})
+}
+
+func (s *MongoStore) UpdateUserSession(session *UserSession) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.db.Collection(mongoSessionsCollection).ReplaceOne(ctx, bson.M{"sessionid": session.SessionID}, session)
+	return err
+}
+
+func (s *MongoStore) DeleteUserSession(sessionID string) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.db.Collection(mongoSessionsCollection).DeleteOne(ctx, bson.M{"sessionid": sessionID})
+	return err
+}
+
+func (s *MongoStore) GetUserSessions(userID uint) ([]*UserSession, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFind[UserSession](ctx, s.db.Collection(mongoSessionsCollection), bson.M{"userid": userID, "isactive": true}, options.Find().SetSort(newestFirst))
+}
+
+func (s *MongoStore) DeleteUserSessions(userID uint) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.db.Collection(mongoSessionsCollection).DeleteMany(ctx, bson.M{"userid": userID})
+	return err
+}
+
+func (s *MongoStore) CleanupExpiredSessions(before time.Time) (int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	result, err := s.db.Collection(mongoSessionsCollection).DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"expiresat": bson.M{"$lt": before}},
+		bson.M{"isactive": false},
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// === MongoStore Alert Methods ===
+
+func (s *MongoStore) CreateAlert(alert *Alert) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoAlertsCollection)
+	if err != nil {
+		return err
+	}
+	alert.ID = id
+	alert.CreatedAt = time.Now()
+	alert.UpdatedAt = alert.CreatedAt
+	_, err = s.db.Collection(mongoAlertsCollection).InsertOne(ctx, alert)
+	return err
+}
+
+func (s *MongoStore) GetAlertByID(id uint) (*Alert, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	alert, err := mongoFindOne[Alert](ctx, s.db.Collection(mongoAlertsCollection), bson.M{"id": id})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrAlertNotFound
+	}
+	return alert, err
+}
+
+func (s *MongoStore) UpdateAlert(alert *Alert) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	alert.UpdatedAt = time.Now()
+	result, err := s.db.Collection(mongoAlertsCollection).ReplaceOne(ctx, bson.M{"id": alert.ID}, alert)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAlertNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) ListAlerts(filter AlertFilter, offset, limit int) ([]*Alert, int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	query := bson.M{}
+	if filter.Level != "" {
+		query["level"] = filter.Level
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+	if filter.Resolved != nil {
+		query["resolved"] = *filter.Resolved
+	}
+	lastSeen := bson.M{}
+	if !filter.Since.IsZero() {
+		lastSeen["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		lastSeen["$lt"] = filter.Until
+	}
+	if len(lastSeen) > 0 {
+		query["lastseen"] = lastSeen
+	}
+	newestSeenFirst := bson.D{{Key: "lastseen", Value: -1}, {Key: "id", Value: -1}}
+	if filter.After == nil {
+		return mongoPage[Alert](ctx, s.db.Collection(mongoAlertsCollection), query, newestSeenFirst, offset, limit)
+	}
+
+	// The total counts every matching alert, not only those after the cursor
+	collection := s.db.Collection(mongoAlertsCollection)
+	total, err := collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	after := bson.M{"$and": bson.A{query, bson.M{"$or": bson.A{
+		bson.M{"lastseen": bson.M{"$lt": filter.After.LastSeen}},
+		bson.M{"lastseen": filter.After.LastSeen, "id": bson.M{"$lt": filter.After.ID}},
+	}}}}
+	opts := options.Find().SetSkip(int64(offset)).SetSort(newestSeenFirst)
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	alerts, err := mongoFind[Alert](ctx, collection, after, opts)
+	return alerts, total, err
+}
+
+func (s *MongoStore) FindOpenAlert(alertType string, since time.Time) (*Alert, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	alert, err := mongoFindOne[Alert](ctx, s.db.Collection(mongoAlertsCollection),
+		bson.M{"type": alertType, "resolved": false, "lastseen": bson.M{"$gte": since}},
+		options.FindOne().SetSort(bson.D{{Key: "lastseen", Value: -1}}),
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrAlertNotFound
+	}
+	return alert, err
+}
+
+// === MongoStore UserPreference Methods ===
+
+func (s *MongoStore) GetUserPreference(userID uint, clusterID string) (*UserPreference, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	pref, err := mongoFindOne[UserPreference](ctx, s.db.Collection(mongoPreferencesCollection),
+		bson.M{"userid": userID, "clusterid": clusterID})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUserPreferenceNotFound
+	}
+	return pref, err
+}
+
+func (s *MongoStore) SaveUserPreference(pref *UserPreference) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	collection := s.db.Collection(mongoPreferencesCollection)
+	filter := bson.M{"userid": pref.UserID, "clusterid": pref.ClusterID}
+
+	pref.UpdatedAt = time.Now()
+	existing, err := mongoFindOne[UserPreference](ctx, collection, filter)
+	switch {
+	case err == nil:
+		pref.ID = existing.ID
+		pref.CreatedAt = existing.CreatedAt
+	case errors.Is(err, mongo.ErrNoDocuments):
+		id, err := s.nextID(ctx, mongoPreferencesCollection)
+		if err != nil {
+			return err
+		}
+		pref.ID = id
+		pref.CreatedAt = pref.UpdatedAt
+	default:
+		return err
+	}
+	_, err = collection.ReplaceOne(ctx, filter, pref, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (s *MongoStore) DeleteUserPreference(userID uint, clusterID string) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.db.Collection(mongoPreferencesCollection).DeleteOne(ctx, bson.M{"userid": userID, "clusterid": clusterID})
+	return err
+}
+
+func (s *MongoStore) ListUserPreferences(userID uint) ([]*UserPreference, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFind[UserPreference](ctx, s.db.Collection(mongoPreferencesCollection), bson.M{"userid": userID},
+		options.Find().SetSort(bson.D{{Key: "clusterid", Value: 1}}))
+}
+
+// === MongoStore Invite Methods ===
+
+func (s *MongoStore) CreateInvite(invite *Invite) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoInvitesCollection)
+	if err != nil {
+		return err
+	}
+	invite.ID = id
+	invite.CreatedAt = time.Now()
+	invite.UpdatedAt = invite.CreatedAt
+	_, err = s.db.Collection(mongoInvitesCollection).InsertOne(ctx, invite)
+	return err
+}
+
+func (s *MongoStore) GetInviteByID(id uint) (*Invite, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	invite, err := mongoFindOne[Invite](ctx, s.db.Collection(mongoInvitesCollection), bson.M{"id": id})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInviteNotFound
+	}
+	return invite, err
+}
+
+func (s *MongoStore) UpdateInvite(invite *Invite) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	invite.UpdatedAt = time.Now()
+	result, err := s.db.Collection(mongoInvitesCollection).ReplaceOne(ctx, bson.M{"id": invite.ID}, invite)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
+
+func (s *MongoStore) ListInvites(offset, limit int) ([]*Invite, int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoPage[Invite](ctx, s.db.Collection(mongoInvitesCollection), bson.M{}, bson.D{{Key: "id", Value: -1}}, offset, limit)
+}
+
+// === MongoStore ResourceTemplate Methods ===
+
+func (s *MongoStore) CreateResourceTemplate(template *ResourceTemplate) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoTemplatesCollection)
+	if err != nil {
+		return err
+	}
+	template.ID = id
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+	_, err = s.db.Collection(mongoTemplatesCollection).InsertOne(ctx, template)
+	return err
+}
+
+func (s *MongoStore) GetResourceTemplate(name string) (*ResourceTemplate, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	template, err := mongoFindOne[ResourceTemplate](ctx, s.db.Collection(mongoTemplatesCollection), bson.M{"name": name})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrResourceTemplateNotFound
+	}
+	return template, err
+}
+
+func (s *MongoStore) ListResourceTemplates() ([]*ResourceTemplate, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFind[ResourceTemplate](ctx, s.db.Collection(mongoTemplatesCollection), bson.M{},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+}
+
+// === MongoStore Favorite Methods ===
+
+func (s *MongoStore) CreateFavorite(favorite *Favorite) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	id, err := s.nextID(ctx, mongoFavoritesCollection)
+	if err != nil {
+		return err
+	}
+	favorite.ID = id
+	favorite.CreatedAt = time.Now()
+	_, err = s.db.Collection(mongoFavoritesCollection).InsertOne(ctx, favorite)
+	return err
+}
+
+func (s *MongoStore) ListFavorites(userID uint) ([]*Favorite, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return mongoFind[Favorite](ctx, s.db.Collection(mongoFavoritesCollection), bson.M{"userid": userID},
+		options.Find().SetSort(bson.D{{Key: "id", Value: 1}}))
+}
+
+func (s *MongoStore) DeleteFavorite(userID, id uint) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	result, err := s.db.Collection(mongoFavoritesCollection).DeleteOne(ctx, bson.M{"userid": userID, "id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrFavoriteNotFound
+	}
+	return nil
+}
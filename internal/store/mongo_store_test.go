@@ -0,0 +1,226 @@
+//go:build mongodb
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Run with: CILIKUBE_TEST_MONGO_URI=mongodb://localhost:27017 go test -tags mongodb ./internal/store
+const mongoTestURIEnv = "CILIKUBE_TEST_MONGO_URI"
+
+// newTestMongoStore connects to a fresh database on the server named by CILIKUBE_TEST_MONGO_URI
+func newTestMongoStore(t *testing.T) *MongoStore {
+	t.Helper()
+	uri := os.Getenv(mongoTestURIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", mongoTestURIEnv)
+	}
+
+	dbConfig := &configs.DatabaseConfig{
+		Enabled:  true,
+		Type:     "mongodb",
+		URI:      uri,
+		Database: fmt.Sprintf("cilikube_test_%d", time.Now().UnixNano()),
+	}
+	config := &configs.Config{Storage: configs.StorageConfig{Type: "mongodb", Database: dbConfig}}
+
+	s, err := NewMongoStore(config)
+	if err != nil {
+		t.Skipf("MongoDB is unavailable: %v", err)
+	}
+	mongoStore := s.(*MongoStore)
+	t.Cleanup(func() {
+		_ = mongoStore.db.Drop(context.Background())
+		_ = mongoStore.Close()
+	})
+
+	require.NoError(t, mongoStore.Initialize())
+	return mongoStore
+}
+
+func TestMongoStore_Initialize(t *testing.T) {
+	s := newTestMongoStore(t)
+
+	// Initializing again must not duplicate the default data
+	require.NoError(t, s.Initialize())
+
+	admin, err := s.GetUserByUsername("admin")
+	require.NoError(t, err)
+	assert.True(t, admin.CheckPassword("12345678"))
+	roles, err := s.GetUserRoles(admin.ID)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "admin", roles[0].Name)
+
+	_, total, err := s.ListUsers(0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	allRoles, err := s.ListRoles()
+	require.NoError(t, err)
+	assert.Len(t, allRoles, len(defaultRoles()))
+}
+
+func TestMongoStore_Indexes(t *testing.T) {
+	s := newTestMongoStore(t)
+
+	indexed := func(collection string, keys bson.D) bool {
+		cursor, err := s.db.Collection(collection).Indexes().List(context.Background())
+		require.NoError(t, err)
+		var indexes []struct {
+			Key bson.D `bson:"key"`
+		}
+		require.NoError(t, cursor.All(context.Background(), &indexes))
+		for _, index := range indexes {
+			if len(index.Key) != len(keys) {
+				continue
+			}
+			match := true
+			for i := range keys {
+				if index.Key[i].Key != keys[i].Key {
+					match = false
+				}
+			}
+			if match {
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.True(t, indexed(mongoUsersCollection, bson.D{{Key: "username"}}))
+	assert.True(t, indexed(mongoUserRolesCollection, bson.D{{Key: "userid"}, {Key: "roleid"}}))
+	assert.True(t, indexed(mongoAuditLogsCollection, bson.D{{Key: "userid"}, {Key: "createdat"}}))
+	assert.True(t, indexed(mongoAuditLogsCollection, bson.D{{Key: "action"}, {Key: "createdat"}}))
+	assert.True(t, indexed(mongoLoginAttemptsCollection, bson.D{{Key: "ipaddress"}, {Key: "createdat"}}))
+	assert.True(t, indexed(mongoSessionsCollection, bson.D{{Key: "sessionid"}}))
+	assert.True(t, indexed(mongoSessionsCollection, bson.D{{Key: "userid"}, {Key: "isactive"}}))
+	assert.True(t, indexed(mongoPreferencesCollection, bson.D{{Key: "userid"}, {Key: "clusterid"}}))
+
+	// The unique index on username rejects duplicates
+	err := s.CreateUser(&User{Username: "admin", Email: "other@example.com"})
+	assert.Error(t, err)
+}
+
+func TestMongoStore_CRUD(t *testing.T) {
+	s := newTestMongoStore(t)
+
+	t.Run("clusters", func(t *testing.T) {
+		cluster := &Cluster{Name: "dev", Provider: "kind", Labels: Labels{"env": "dev"}}
+		require.NoError(t, s.CreateCluster(cluster))
+		assert.NotEmpty(t, cluster.ID)
+
+		cluster.Description = "development"
+		require.NoError(t, s.UpdateCluster(cluster))
+		got, err := s.GetClusterByName("dev")
+		require.NoError(t, err)
+		assert.Equal(t, "development", got.Description)
+		assert.Equal(t, "dev", got.Labels["env"])
+
+		require.NoError(t, s.DeleteClusterByID(cluster.ID))
+		clusters, err := s.GetAllClusters()
+		require.NoError(t, err)
+		assert.Empty(t, clusters)
+	})
+
+	t.Run("users and roles", func(t *testing.T) {
+		require.NoError(t, createUserWithRole(s, "alice"))
+		alice, err := s.GetUserByEmail("alice@example.com")
+		require.NoError(t, err)
+
+		viewer, err := s.GetRoleByName("viewer")
+		require.NoError(t, err)
+		has, err := s.HasRole(alice.ID, viewer.ID)
+		require.NoError(t, err)
+		assert.True(t, has)
+		// Assigning a role twice is not an error
+		require.NoError(t, s.AssignRole(alice.ID, viewer.ID))
+
+		users, err := s.GetRoleUsers(viewer.ID)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, "alice", users[0].Username)
+
+		require.NoError(t, s.DeleteUser(alice.ID))
+		has, err = s.HasRole(alice.ID, viewer.ID)
+		require.NoError(t, err)
+		assert.False(t, has, "deleting a user removes its role assignments")
+	})
+
+	t.Run("audit logs", func(t *testing.T) {
+		userID := uint(42)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, s.CreateAuditLog(&AuditLog{UserID: &userID, Action: "login", Resource: "auth"}))
+		}
+		logs, total, err := s.GetAuditLogsByUserID(userID, 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.Len(t, logs, 2)
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		now := time.Now()
+		require.NoError(t, s.CreateUserSession(&UserSession{SessionID: "live", UserID: 7, ExpiresAt: now.Add(time.Hour), IsActive: true}))
+		require.NoError(t, s.CreateUserSession(&UserSession{SessionID: "expired", UserID: 7, ExpiresAt: now.Add(-time.Hour), IsActive: true}))
+
+		removed, err := s.CleanupExpiredSessions(now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+		sessions, err := s.GetUserSessions(7)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "live", sessions[0].SessionID)
+	})
+
+	t.Run("alerts", func(t *testing.T) {
+		_, err := s.GetAlertByID(999)
+		assert.ErrorIs(t, err, ErrAlertNotFound)
+
+		alert := &Alert{Level: "warning", Type: "node_not_ready", LastSeen: time.Now()}
+		require.NoError(t, s.CreateAlert(alert))
+		open, err := s.FindOpenAlert("node_not_ready", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, alert.ID, open.ID)
+	})
+}
+
+func TestMongoStore_UserPreferences(t *testing.T) {
+	testUserPreferences(t, newTestMongoStore(t))
+}
+
+func TestMongoStore_Invites(t *testing.T) {
+	testInvites(t, newTestMongoStore(t))
+}
+
+func TestMongoStore_ResourceTemplates(t *testing.T) {
+	testResourceTemplates(t, newTestMongoStore(t))
+}
+
+func TestMongoStore_Favorites(t *testing.T) {
+	testFavorites(t, newTestMongoStore(t))
+}
+
+func TestMongoStore_ListAlerts(t *testing.T) {
+	testListAlerts(t, newTestMongoStore(t))
+}
+
+func TestMongoStore_GroupAuditLogs(t *testing.T) {
+	testGroupAuditLogs(t, newTestMongoStore(t))
+}
+
+func TestMongoStore_Transaction(t *testing.T) {
+	s := newTestMongoStore(t)
+	if !s.supportsTransactions {
+		t.Skip("MongoDB transactions need a replica set")
+	}
+	testTransaction(t, s)
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testResourceTemplates(t *testing.T, s Store) {
+	_, err := s.GetResourceTemplate("redis")
+	assert.ErrorIs(t, err, ErrResourceTemplateNotFound)
+
+	redis := &ResourceTemplate{
+		Name:       "redis",
+		Parameters: TemplateParameters{{Name: "name", Required: true}, {Name: "image", Default: "redis:7"}},
+		Manifest:   "kind: Deployment\\nmetadata:\\n  name: {{ .name }}\\n",
+	}
+	require.NoError(t, s.CreateResourceTemplate(redis))
+	require.NoError(t, s.CreateResourceTemplate(&ResourceTemplate{Name: "app-config", Manifest: "kind: ConfigMap\\n"}))
+	assert.Error(t, s.CreateResourceTemplate(&ResourceTemplate{Name: "redis", Manifest: "kind: Service\\n"}), "names are unique")
+
+	template, err := s.GetResourceTemplate("redis")
+	require.NoError(t, err)
+	assert.Equal(t, redis.Manifest, template.Manifest)
+	assert.Equal(t, redis.Parameters, template.Parameters)
+
+	templates, err := s.ListResourceTemplates()
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "app-config", templates[0].Name)
+	assert.Equal(t, "redis", templates[1].Name)
+}
+
+func TestMemoryStore_ResourceTemplates(t *testing.T) {
+	testResourceTemplates(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_ResourceTemplates(t *testing.T) {
+	testResourceTemplates(t, newTestDatabaseStore(t))
+}
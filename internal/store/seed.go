@@ -0,0 +1,171 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+// SeedData lists the clusters, roles and users to load into a store, e.g. for tests and demos
+type SeedData struct {
+	Clusters []SeedCluster `json:"clusters"`
+	Roles    []SeedRole    `json:"roles"`
+	Users    []SeedUser    `json:"users"`
+}
+
+// SeedCluster is a cluster to register, identified by its name
+type SeedCluster struct {
+	Name        string            `json:"name"`
+	Kubeconfig  string            `json:"kubeconfig"` // Kubeconfig content, not a path
+	Description string            `json:"description,omitempty"`
+	Provider    string            `json:"provider,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Region      string            `json:"region,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// SeedRole is a role to create, identified by its name
+type SeedRole struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"` // Defaults to the name
+	Description string `json:"description,omitempty"`
+}
+
+// SeedUser is a user to create, identified by its username, with the names of the roles to assign
+type SeedUser struct {
+	Username    string   `json:"username"`
+	Email       string   `json:"email"`
+	Password    string   `json:"password,omitempty"` // Plain text, hashed before it is stored; empty for no password
+	DisplayName string   `json:"display_name,omitempty"`
+	Disabled    bool     `json:"disabled,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+}
+
+// LoadSeedFile reads seed data from a JSON file
+func LoadSeedFile(path string) (SeedData, error) {
+	var data SeedData
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return data, fmt.Errorf("failed to read seed file: %w", err)
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return data, fmt.Errorf("failed to parse seed file %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// Seed loads clusters, roles and users into s in one transaction. It is idempotent: clusters and roles
+// that exist by name and users that exist by username are left as they are, and only missing role
+// assignments are added, so seeding the same data again changes nothing.
+func Seed(s Store, data SeedData) error {
+	return s.Transaction(func(tx Store) error {
+		for _, seed := range data.Clusters {
+			if err := seedCluster(tx, seed); err != nil {
+				return err
+			}
+		}
+		for _, seed := range data.Roles {
+			if err := seedRole(tx, seed); err != nil {
+				return err
+			}
+		}
+		for _, seed := range data.Users {
+			if err := seedUser(tx, seed); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func seedCluster(tx Store, seed SeedCluster) error {
+	if seed.Name == "" || seed.Kubeconfig == "" {
+		return fmt.Errorf("seed cluster %q: name and kubeconfig are required", seed.Name)
+	}
+	if _, err := tx.GetClusterByName(seed.Name); err == nil {
+		return nil
+	}
+	cluster := &Cluster{
+		ID:             uuid.NewString(),
+		Name:           seed.Name,
+		KubeconfigData: []byte(seed.Kubeconfig),
+		Description:    seed.Description,
+		Provider:       seed.Provider,
+		Environment:    seed.Environment,
+		Region:         seed.Region,
+		Status:         "Active",
+		Labels:         seed.Labels,
+	}
+	if err := tx.CreateCluster(cluster); err != nil {
+		return fmt.Errorf("failed to seed cluster %s: %w", seed.Name, err)
+	}
+	return nil
+}
+
+func seedRole(tx Store, seed SeedRole) error {
+	if seed.Name == "" {
+		return fmt.Errorf("seed role: name is required")
+	}
+	if _, err := tx.GetRoleByName(seed.Name); err == nil {
+		return nil
+	}
+	role := &Role{Name: seed.Name, DisplayName: seed.DisplayName, Description: seed.Description}
+	if role.DisplayName == "" {
+		role.DisplayName = seed.Name
+	}
+	if err := tx.CreateRole(role); err != nil {
+		return fmt.Errorf("failed to seed role %s: %w", seed.Name, err)
+	}
+	return nil
+}
+
+func seedUser(tx Store, seed SeedUser) error {
+	if seed.Username == "" || seed.Email == "" {
+		return fmt.Errorf("seed user %q: username and email are required", seed.Username)
+	}
+	user, err := tx.GetUserByUsername(seed.Username)
+	if err != nil {
+		user = &User{
+			Username:      seed.Username,
+			Email:         seed.Email,
+			DisplayName:   seed.DisplayName,
+			IsActive:      true,
+			EmailVerified: true,
+		}
+		if seed.Password != "" {
+			if err := user.HashPassword(seed.Password); err != nil {
+				return fmt.Errorf("failed to hash password of seed user %s: %w", seed.Username, err)
+			}
+		}
+		if err := tx.CreateUser(user); err != nil {
+			return fmt.Errorf("failed to seed user %s: %w", seed.Username, err)
+		}
+		// Disabled after creation, the database applies its default to an inactive user on insert
+		if seed.Disabled {
+			user.IsActive = false
+			if err := tx.UpdateUser(user); err != nil {
+				return fmt.Errorf("failed to disable seed user %s: %w", seed.Username, err)
+			}
+		}
+	}
+
+	for _, roleName := range seed.Roles {
+		role, err := tx.GetRoleByName(roleName)
+		if err != nil {
+			return fmt.Errorf("seed user %s: role %s not found", seed.Username, roleName)
+		}
+		hasRole, err := tx.HasRole(user.ID, role.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check roles of seed user %s: %w", seed.Username, err)
+		}
+		if hasRole {
+			continue
+		}
+		if err := tx.AssignRole(user.ID, role.ID); err != nil {
+			return fmt.Errorf("failed to assign role %s to seed user %s: %w", roleName, seed.Username, err)
+		}
+	}
+	return nil
+}
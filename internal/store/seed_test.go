@@ -0,0 +1,116 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSeedData() SeedData {
+	return SeedData{
+		Clusters: []SeedCluster{{Name: "dev", Kubeconfig: "apiVersion: v1\nkind: Config\n", Environment: "development", Labels: map[string]string{"team": "core"}}},
+		Roles:    []SeedRole{{Name: "developer", Description: "Works on workloads"}},
+		Users: []SeedUser{
+			{Username: "alice", Email: "alice@example.com", Password: "password123", Roles: []string{"developer", "viewer"}},
+			{Username: "bob", Email: "bob@example.com", Disabled: true},
+		},
+	}
+}
+
+func testSeed(t *testing.T, s Store) {
+	_, usersBefore, err := s.ListUsers(0, 100)
+	require.NoError(t, err)
+	rolesBefore, err := s.ListRoles()
+	require.NoError(t, err)
+
+	// Seeding twice leaves the store as seeding once
+	for i := 0; i < 2; i++ {
+		require.NoError(t, Seed(s, testSeedData()))
+
+		_, users, err := s.ListUsers(0, 100)
+		require.NoError(t, err)
+		assert.Equal(t, usersBefore+2, users)
+		roles, err := s.ListRoles()
+		require.NoError(t, err)
+		assert.Len(t, roles, len(rolesBefore)+1)
+		clusters, err := s.GetAllClusters()
+		require.NoError(t, err)
+		assert.Len(t, clusters, 1)
+
+		alice, err := s.GetUserByUsername("alice")
+		require.NoError(t, err)
+		assert.True(t, alice.IsActive)
+		assert.True(t, alice.CheckPassword("password123"))
+		aliceRoles, err := s.GetUserRoles(alice.ID)
+		require.NoError(t, err)
+		assert.Len(t, aliceRoles, 2)
+	}
+
+	bob, err := s.GetUserByUsername("bob")
+	require.NoError(t, err)
+	assert.False(t, bob.IsActive)
+	developer, err := s.GetRoleByName("developer")
+	require.NoError(t, err)
+	assert.Equal(t, "developer", developer.DisplayName)
+	cluster, err := s.GetClusterByName("dev")
+	require.NoError(t, err)
+	assert.Equal(t, "development", cluster.Environment)
+	assert.Equal(t, Labels{"team": "core"}, cluster.Labels)
+
+	// Existing users only get the roles they are missing
+	data := SeedData{Users: []SeedUser{{Username: "bob", Email: "bob@example.com", Roles: []string{"developer"}}}}
+	require.NoError(t, Seed(s, data))
+	bob, err = s.GetUserByUsername("bob")
+	require.NoError(t, err)
+	assert.False(t, bob.IsActive, "existing users are not updated")
+	hasRole, err := s.HasRole(bob.ID, developer.ID)
+	require.NoError(t, err)
+	assert.True(t, hasRole)
+}
+
+func testSeedRollsBack(t *testing.T, s Store) {
+	data := SeedData{Users: []SeedUser{
+		{Username: "carol", Email: "carol@example.com"},
+		{Username: "dave", Email: "dave@example.com", Roles: []string{"missing"}},
+	}}
+	err := Seed(s, data)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "role missing not found")
+
+	_, err = s.GetUserByUsername("carol")
+	assert.Error(t, err, "a failed seed leaves nothing behind")
+}
+
+func TestMemoryStore_Seed(t *testing.T) {
+	testSeed(t, newTestMemoryStore(t))
+	testSeedRollsBack(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_Seed(t *testing.T) {
+	testSeed(t, newTestDatabaseStore(t))
+	testSeedRollsBack(t, newTestDatabaseStore(t))
+}
+
+func TestLoadSeedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seed.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"roles": [{"name": "developer"}],
+		"users": [{"username": "alice", "email": "alice@example.com", "password": "secret", "roles": ["developer"]}]
+	}`), 0o600))
+
+	data, err := LoadSeedFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []SeedRole{{Name: "developer"}}, data.Roles)
+	require.Len(t, data.Users, 1)
+	assert.Equal(t, []string{"developer"}, data.Users[0].Roles)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"users": [`), 0o600))
+	_, err = LoadSeedFile(path)
+	assert.ErrorContains(t, err, "failed to parse seed file")
+
+	_, err = LoadSeedFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
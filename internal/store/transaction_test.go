@@ -0,0 +1,103 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errForced = errors.New("forced failure")
+
+func newTestDatabaseStore(t *testing.T) Store {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	// A single connection keeps every query on the same in-memory database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	s := &DatabaseStore{db: db}
+	require.NoError(t, s.Initialize())
+	return s
+}
+
+func newTestMemoryStore(t *testing.T) Store {
+	t.Helper()
+	s := NewMemoryStore()
+	require.NoError(t, s.Initialize())
+	return s
+}
+
+// createUserWithRole creates a user and assigns it the viewer role inside tx
+func createUserWithRole(tx Store, username string) error {
+	user := &User{Username: username, Email: username + "@example.com", PasswordHash: "password123", IsActive: true}
+	if err := tx.CreateUser(user); err != nil {
+		return err
+	}
+	role, err := tx.GetRoleByName("viewer")
+	if err != nil {
+		return err
+	}
+	return tx.AssignRole(user.ID, role.ID)
+}
+
+func testTransaction(t *testing.T, s Store) {
+	t.Run("commit", func(t *testing.T) {
+		require.NoError(t, s.Transaction(func(tx Store) error {
+			return createUserWithRole(tx, "alice")
+		}))
+
+		user, err := s.GetUserByUsername("alice")
+		require.NoError(t, err)
+		roles, err := s.GetUserRoles(user.ID)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, "viewer", roles[0].Name)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		_, total, err := s.ListUsers(0, 100)
+		require.NoError(t, err)
+
+		err = s.Transaction(func(tx Store) error {
+			if err := createUserWithRole(tx, "bob"); err != nil {
+				return err
+			}
+			require.NoError(t, tx.CreateAuditLog(&AuditLog{Action: "user_register", Resource: "user"}))
+			return errForced
+		})
+		assert.ErrorIs(t, err, errForced)
+
+		_, err = s.GetUserByUsername("bob")
+		assert.Error(t, err, "the user created in the failed transaction is rolled back")
+		_, after, err := s.ListUsers(0, 100)
+		require.NoError(t, err)
+		assert.Equal(t, total, after)
+		logs, _, err := s.GetAuditLogsByAction("user_register", 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, logs)
+	})
+
+	t.Run("usable after rollback", func(t *testing.T) {
+		require.NoError(t, s.Transaction(func(tx Store) error {
+			return createUserWithRole(tx, "bob")
+		}))
+		_, err := s.GetUserByUsername("bob")
+		assert.NoError(t, err)
+	})
+}
+
+func TestMemoryStore_Transaction(t *testing.T) {
+	testTransaction(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_Transaction(t *testing.T) {
+	testTransaction(t, newTestDatabaseStore(t))
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testUserPreferences(t *testing.T, s Store) {
+	_, err := s.GetUserPreference(1, "prod")
+	assert.ErrorIs(t, err, ErrUserPreferenceNotFound)
+
+	require.NoError(t, s.SaveUserPreference(&UserPreference{UserID: 1, ClusterID: "prod", DefaultNamespace: "payments"}))
+	require.NoError(t, s.SaveUserPreference(&UserPreference{UserID: 1, ClusterID: "dev", DefaultNamespace: "sandbox"}))
+	require.NoError(t, s.SaveUserPreference(&UserPreference{UserID: 2, ClusterID: "prod", DefaultNamespace: "billing"}))
+
+	pref, err := s.GetUserPreference(1, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "payments", pref.DefaultNamespace)
+	firstID := pref.ID
+
+	// Saving again replaces the existing preferences instead of adding a row
+	require.NoError(t, s.SaveUserPreference(&UserPreference{UserID: 1, ClusterID: "prod", DefaultNamespace: "orders", Settings: `{"theme":"dark"}`}))
+	pref, err = s.GetUserPreference(1, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, firstID, pref.ID)
+	assert.Equal(t, "orders", pref.DefaultNamespace)
+	assert.JSONEq(t, `{"theme":"dark"}`, pref.Settings)
+
+	prefs, err := s.ListUserPreferences(1)
+	require.NoError(t, err)
+	require.Len(t, prefs, 2)
+	assert.Equal(t, "dev", prefs[0].ClusterID)
+	assert.Equal(t, "prod", prefs[1].ClusterID)
+
+	require.NoError(t, s.DeleteUserPreference(1, "prod"))
+	_, err = s.GetUserPreference(1, "prod")
+	assert.ErrorIs(t, err, ErrUserPreferenceNotFound)
+
+	other, err := s.GetUserPreference(2, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, "billing", other.DefaultNamespace, "other users keep their preferences")
+}
+
+func TestMemoryStore_UserPreferences(t *testing.T) {
+	testUserPreferences(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_UserPreferences(t *testing.T) {
+	testUserPreferences(t, newTestDatabaseStore(t))
+}
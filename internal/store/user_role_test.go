@@ -0,0 +1,56 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRoleAssignments(t *testing.T, s Store) {
+	viewer, err := s.GetRoleByName("viewer")
+	require.NoError(t, err)
+	alice := &User{Username: "alice", Email: "alice@example.com", PasswordHash: "password123", IsActive: true}
+	require.NoError(t, s.CreateUser(alice))
+	bob := &User{Username: "bob", Email: "bob@example.com", PasswordHash: "password123", IsActive: true}
+	require.NoError(t, s.CreateUser(bob))
+
+	before := time.Now()
+	require.NoError(t, s.AssignRoleBy(alice.ID, viewer.ID, 1))
+	after := time.Now()
+	require.NoError(t, s.AssignRole(bob.ID, viewer.ID))
+
+	assignments, err := s.GetRoleAssignments(viewer.ID)
+	require.NoError(t, err)
+	require.Len(t, assignments, 2)
+	assert.Equal(t, alice.ID, assignments[0].UserID)
+	assert.Equal(t, uint(1), assignments[0].AssignedBy)
+	assert.WithinRange(t, assignments[0].AssignedAt, before.Add(-time.Second), after.Add(time.Second))
+	assert.Equal(t, bob.ID, assignments[1].UserID)
+	assert.Zero(t, assignments[1].AssignedBy)
+
+	// Assigning again keeps the original assignment
+	assignedAt := assignments[0].AssignedAt
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, s.AssignRoleBy(alice.ID, viewer.ID, 2))
+	assignments, err = s.GetRoleAssignments(viewer.ID)
+	require.NoError(t, err)
+	require.Len(t, assignments, 2)
+	assert.Equal(t, uint(1), assignments[0].AssignedBy)
+	assert.True(t, assignedAt.Equal(assignments[0].AssignedAt))
+
+	require.NoError(t, s.RemoveRole(alice.ID, viewer.ID))
+	require.NoError(t, s.RemoveRole(bob.ID, viewer.ID))
+	assignments, err = s.GetRoleAssignments(viewer.ID)
+	require.NoError(t, err)
+	assert.Empty(t, assignments)
+}
+
+func TestMemoryStore_RoleAssignments(t *testing.T) {
+	testRoleAssignments(t, newTestMemoryStore(t))
+}
+
+func TestDatabaseStore_RoleAssignments(t *testing.T) {
+	testRoleAssignments(t, newTestDatabaseStore(t))
+}
@@ -0,0 +1,70 @@
+// Package swagger serves the OpenAPI (Swagger 2.0) spec that swag generates from the handler annotations
+// into docs/swagger, and the Swagger UI bundled with swaggo/files to browse it.
+package swagger
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	_ "github.com/ciliverse/cilikube/docs/swagger" // registers the generated spec with swag
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files"
+	ginswagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+)
+
+// DocHandler serves the spec as JSON. Behind a path prefix its basePath is set to the prefix, so
+// requests tried from Swagger UI reach the API.
+func DocHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		doc, err := swag.ReadDoc()
+		if err != nil {
+			utils.ApiError(c, http.StatusInternalServerError, "failed to read API spec", err.Error())
+			return
+		}
+		prefix := utils.ForwardedPrefix(c)
+		if prefix == "" {
+			c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(doc))
+			return
+		}
+
+		var spec map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(doc), &spec); err != nil {
+			c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(doc))
+			return
+		}
+		spec["basePath"], _ = json.Marshal(prefix)
+		c.JSON(http.StatusOK, spec)
+	}
+}
+
+// uiContentSecurityPolicy lets the page run the inline setup of Swagger UI and call the API. Its scripts
+// and styles are served from /swagger itself.
+const uiContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; " +
+	"style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+
+// UIHandler serves the Swagger UI page and its assets. A Content-Security-Policy set for the API
+// responses is replaced by one allowing the page's inline script and styles.
+func UIHandler() gin.HandlerFunc {
+	ui := ginswagger.WrapHandler(swaggerfiles.Handler, ginswagger.URL("doc.json"), ginswagger.PersistAuthorization(true))
+	return func(c *gin.Context) {
+		if c.Writer.Header().Get("Content-Security-Policy") != "" {
+			c.Header("Content-Security-Policy", uiContentSecurityPolicy)
+		}
+		ui(c)
+	}
+}
+
+// Handler serves everything under /swagger/*any: the spec at doc.json, the UI otherwise
+func Handler() gin.HandlerFunc {
+	doc, ui := DocHandler(), UIHandler()
+	return func(c *gin.Context) {
+		if strings.TrimPrefix(c.Param("any"), "/") == "doc.json" {
+			doc(c)
+			return
+		}
+		ui(c)
+	}
+}
@@ -0,0 +1,82 @@
+package swagger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocHandler_ServesSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/swagger/*any", Handler())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/doc.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	var spec struct {
+		Swagger     string                                `json:"swagger"`
+		Paths       map[string]map[string]json.RawMessage `json:"paths"`
+		Definitions map[string]json.RawMessage            `json:"definitions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "2.0", spec.Swagger)
+	require.Contains(t, spec.Paths, "/api/v1/auth/login")
+	assert.Contains(t, spec.Paths["/api/v1/auth/login"], "post")
+	assert.Contains(t, spec.Definitions, "models.LoginRequest")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `url: "doc.json"`)
+	assert.NotContains(t, w.Body.String(), "unpkg.com")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/swagger-ui-bundle.js", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "the UI assets are embedded")
+}
+
+func TestUIHandler_ContentSecurityPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Header("Content-Security-Policy", "default-src 'none'") })
+	router.GET("/swagger/*any", Handler())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+	assert.Equal(t, "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'",
+		w.Header().Get("Content-Security-Policy"), "Swagger UI is served locally")
+}
+
+func TestDocHandler_BasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/swagger/*any", Handler())
+
+	basePath := func(prefix string) string {
+		req := httptest.NewRequest(http.MethodGet, "/swagger/doc.json", nil)
+		if prefix != "" {
+			req.Header.Set("X-Forwarded-Prefix", prefix)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var spec struct {
+			BasePath string                     `json:"basePath"`
+			Paths    map[string]json.RawMessage `json:"paths"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+		require.Contains(t, spec.Paths, "/api/v1/auth/login")
+		return spec.BasePath
+	}
+
+	assert.Equal(t, "/", basePath(""))
+	assert.Equal(t, "/cilikube", basePath("/cilikube"), "requests from Swagger UI keep the path prefix")
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/casbin/casbin/v2"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -44,13 +45,15 @@ func (r *CasbinBuilder) CasbinMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 		// Get user ID from context (set by JWT middleware)
 		userIDVal, exist := c.Get("userID")
 		if !exist {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unable to get user information, please login first"})
+			utils.ApiError(c, http.StatusUnauthorized, "Unable to get user information, please login first")
+			c.Abort()
 			return
 		}
 
 		userID, ok := userIDVal.(uint)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User information format is incorrect"})
+			utils.ApiError(c, http.StatusUnauthorized, "User information format is incorrect")
+			c.Abort()
 			return
 		}
 
@@ -66,7 +69,8 @@ func (r *CasbinBuilder) CasbinMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 		allowed, err := e.Enforce(userSubject, obj, act)
 		if err != nil {
 			log.Printf("Casbin Enforce error: %v", err)
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal error occurred during permission check"})
+			utils.ApiError(c, http.StatusInternalServerError, "Internal error occurred during permission check")
+			c.Abort()
 			return
 		}
 
@@ -75,7 +79,8 @@ func (r *CasbinBuilder) CasbinMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 			c.Next()
 		} else {
 			log.Printf("Permission verification failed - UserID: %d has no access to %s %s", userID, act, obj)
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have permission to perform this operation"}) // Use 403 Forbidden
+			utils.ApiError(c, http.StatusForbidden, "You do not have permission to perform this operation")
+			c.Abort() // Use 403 Forbidden
 		}
 	}
 }
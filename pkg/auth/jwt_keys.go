@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// ErrUnknownKeyID is returned for RS256 tokens signed with a key that is not in the key set
+var ErrUnknownKeyID = errors.New("token signed with an unknown key")
+
+// KeySet holds the RSA key that signs new tokens and the public keys that verify them, looked up
+// by the kid header. Keeping retired public keys in the set lets tokens signed before a rotation
+// stay valid until they expire.
+type KeySet struct {
+	signingKeyID string
+	signingKey   *rsa.PrivateKey
+	publicKeys   map[string]*rsa.PublicKey
+}
+
+// NewKeySet creates a key set. The public half of the signing key is always part of the set.
+func NewKeySet(signingKeyID string, signingKey *rsa.PrivateKey, publicKeys map[string]*rsa.PublicKey) (*KeySet, error) {
+	if signingKey == nil {
+		return nil, errors.New("an RSA private key is required for RS256")
+	}
+	if signingKeyID == "" {
+		signingKeyID = keyIDFor(&signingKey.PublicKey)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(publicKeys)+1)
+	for kid, key := range publicKeys {
+		keys[kid] = key
+	}
+	keys[signingKeyID] = &signingKey.PublicKey
+	return &KeySet{signingKeyID: signingKeyID, signingKey: signingKey, publicKeys: keys}, nil
+}
+
+// LoadKeySet reads the RS256 signing key and verification keys named in the configuration
+func LoadKeySet(cfg configs.JWTConfig) (*KeySet, error) {
+	if cfg.PrivateKeyFile == "" {
+		return nil, errors.New("jwt.private_key_file is required for RS256")
+	}
+	signingKey, err := readPrivateKey(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys := make(map[string]*rsa.PublicKey, len(cfg.PublicKeys))
+	for _, entry := range cfg.PublicKeys {
+		key, err := readPublicKey(entry.File)
+		if err != nil {
+			return nil, err
+		}
+		kid := entry.KeyID
+		if kid == "" {
+			kid = keyIDFor(key)
+		}
+		publicKeys[kid] = key
+	}
+	return NewKeySet(cfg.KeyID, signingKey, publicKeys)
+}
+
+// Sign signs claims with the signing key, naming it in the kid header
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = k.signingKeyID
+	return token.SignedString(k.signingKey)
+}
+
+// Keyfunc returns the public key named by a token's kid header
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.publicKeys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return key, nil
+}
+
+// JWK is a public RSA key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the verification keys, sorted by key ID
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(k.publicKeys))}
+	for kid, key := range k.publicKeys {
+		jwks.Keys = append(jwks.Keys, JWK{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: AlgorithmRS256,
+			KeyID:     kid,
+			Modulus:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+	return jwks
+}
+
+var (
+	keySetMutex sync.Mutex
+	keySet      *KeySet
+)
+
+// InitJWTKeys loads the RS256 key set when the configuration asks for RS256, so a bad key file or algorithm
+// stops the server at startup instead of failing the first login
+func InitJWTKeys(cfg configs.JWTConfig) error {
+	keySetMutex.Lock()
+	defer keySetMutex.Unlock()
+
+	keySet = nil
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		return nil
+	case AlgorithmRS256:
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+	loaded, err := LoadKeySet(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+	keySet = loaded
+	return nil
+}
+
+// currentKeySet returns the RS256 key set, loading it from the global configuration on first use
+func currentKeySet() (*KeySet, error) {
+	keySetMutex.Lock()
+	defer keySetMutex.Unlock()
+
+	if keySet == nil {
+		loaded, err := LoadKeySet(configs.GlobalConfig.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+		}
+		keySet = loaded
+	}
+	return keySet, nil
+}
+
+// JWKSHandler serves the RS256 verification keys so other services can verify tokens
+func JWKSHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := currentKeySet()
+		if err != nil {
+			utils.ApiError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keys.JWKS())
+	}
+}
+
+// keyIDFor derives a stable key ID from a public key
+func keyIDFor(key *rsa.PublicKey) string {
+	der, _ := x509.MarshalPKIXPublicKey(key)
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// readPrivateKey reads a PKCS#1 or PKCS#8 RSA private key from a PEM file
+func readPrivateKey(path string) (*rsa.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+// readPublicKey reads an RSA public key from a PEM file holding a PKIX or PKCS#1 key or a certificate
+func readPublicKey(path string) (*rsa.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %s: %w", path, err)
+		}
+		parsed = cert.PublicKey
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	return block, nil
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func testClaims() *JWTClaims {
+	return &JWTClaims{
+		UserID:   1,
+		Username: "admin",
+		Role:     "admin",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+// useKeySet switches the package to RS256 with keys for the duration of the test
+func useKeySet(t *testing.T, keys *KeySet) {
+	t.Helper()
+	previousConfig, previousKeys := configs.GlobalConfig, keySet
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{Algorithm: AlgorithmRS256, ExpireDuration: time.Hour}}
+	keySet = keys
+	t.Cleanup(func() { configs.GlobalConfig, keySet = previousConfig, previousKeys })
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, newKey := newRSAKey(t), newRSAKey(t)
+
+	// A token signed before the rotation
+	before, err := NewKeySet("2025-01", oldKey, nil)
+	require.NoError(t, err)
+	oldToken, err := before.Sign(testClaims())
+	require.NoError(t, err)
+
+	// After the rotation the new key signs and the old public key still verifies
+	after, err := NewKeySet("2025-02", newKey, map[string]*rsa.PublicKey{"2025-01": &oldKey.PublicKey})
+	require.NoError(t, err)
+	useKeySet(t, after)
+
+	claims, err := ParseToken(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", claims.Username)
+
+	newToken, _, err := GenerateToken(&models.User{ID: 2, Username: "viewer", Role: "viewer"})
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &JWTClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-02", parsed.Header["kid"])
+	claims, err = ParseToken(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, "viewer", claims.Username)
+
+	// Once the old key is dropped its tokens are rejected
+	dropped, err := NewKeySet("2025-02", newKey, nil)
+	require.NoError(t, err)
+	keySet = dropped
+	_, err = ParseToken(oldToken)
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+}
+
+func TestParseToken_RejectsOtherAlgorithm(t *testing.T) {
+	keys, err := NewKeySet("current", newRSAKey(t), nil)
+	require.NoError(t, err)
+	useKeySet(t, keys)
+
+	// An HS256 token must not pass as RS256, whatever key it was signed with
+	hsToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = ParseToken(hsToken)
+	assert.Error(t, err)
+
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "secret"}}
+	rsToken, err := keys.Sign(testClaims())
+	require.NoError(t, err)
+	_, err = ParseToken(rsToken)
+	assert.Error(t, err)
+	_, err = ParseToken(hsToken)
+	assert.NoError(t, err, "HS256 stays the default")
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	signingKey, retiredKey := newRSAKey(t), newRSAKey(t)
+
+	privatePath := filepath.Join(dir, "signing.pem")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(signingKey)}), 0600))
+	der, err := x509.MarshalPKIXPublicKey(&retiredKey.PublicKey)
+	require.NoError(t, err)
+	publicPath := filepath.Join(dir, "retired.pem")
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	keys, err := LoadKeySet(configs.JWTConfig{
+		Algorithm:      AlgorithmRS256,
+		PrivateKeyFile: privatePath,
+		KeyID:          "current",
+		PublicKeys:     []configs.JWTPublicKey{{KeyID: "retired", File: publicPath}},
+	})
+	require.NoError(t, err)
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "current", jwks.Keys[0].KeyID)
+	assert.Equal(t, "retired", jwks.Keys[1].KeyID)
+	modulus, err := base64.RawURLEncoding.DecodeString(jwks.Keys[1].Modulus)
+	require.NoError(t, err)
+	assert.Equal(t, 0, new(big.Int).SetBytes(modulus).Cmp(retiredKey.N))
+
+	_, err = LoadKeySet(configs.JWTConfig{Algorithm: AlgorithmRS256, PrivateKeyFile: filepath.Join(dir, "missing.pem")})
+	assert.Error(t, err)
+}
+
+func TestJWKSHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys, err := NewKeySet("current", newRSAKey(t), nil)
+	require.NoError(t, err)
+	useKeySet(t, keys)
+
+	router := gin.New()
+	router.GET("/.well-known/jwks.json", JWKSHandler())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var jwks JWKS
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jwks))
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.Equal(t, AlgorithmRS256, jwks.Keys[0].Algorithm)
+	assert.Equal(t, "AQAB", jwks.Keys[0].Exponent)
+}
@@ -8,66 +8,138 @@ import (
 
 	"github.com/ciliverse/cilikube/configs"
 	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Authentication methods a caller can be authenticated with
+const (
+	AuthMethodPassword = "password"
+	AuthMethodOAuth    = "oauth"
+	AuthMethodWebhook  = "webhook"
+	AuthMethodAPIKey   = "apikey"
+)
+
+// Context keys holding the claims of the caller's token and how the caller authenticated
+const (
+	claimsContextKey     = "auth_claims"
+	authMethodContextKey = "auth_method"
+)
+
 type JWTClaims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// PasswordExpired limits the token to the routes needed to change the password
+	PasswordExpired bool `json:"password_expired,omitempty"`
+	// AuthMethod is how the user logged in to obtain the token, tokens issued before it was added have none
+	AuthMethod string `json:"auth_method,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// passwordExpiredRoutes are the routes a token issued for an expired password may still use
+var passwordExpiredRoutes = []string{"/auth/change-password", "/auth/password/expiry", "/auth/profile", "/auth/refresh", "/auth/whoami", "/auth/logout", "/auth/logout-all"}
+
+// rejectExpiredPassword answers 403 with the PASSWORD_EXPIRED error code when the token belongs to an
+// expired password and the route is not one that helps change it
+func rejectExpiredPassword(c *gin.Context, claims *JWTClaims) bool {
+	if !claims.PasswordExpired {
+		return false
+	}
+	for _, route := range passwordExpiredRoutes {
+		if strings.HasSuffix(c.FullPath(), route) {
+			return false
+		}
+	}
+	utils.ApiErrorFrom(c, utils.NewAPIError(http.StatusForbidden, utils.ErrCodePasswordExpired, "Password has expired and must be changed", ""))
+	c.Abort()
+	return true
+}
+
 // GenerateToken generates JWT token
 func GenerateToken(user *models.User) (string, time.Time, error) {
-	expirationTime := time.Now().Add(configs.GlobalConfig.JWT.ExpireDuration)
+	issuedAt := tokenIssueTime(user.ID)
+	expirationTime := issuedAt.Add(configs.GlobalConfig.JWT.ExpireDuration)
+	authMethod := user.AuthMethod
+	if authMethod == "" {
+		authMethod = AuthMethodPassword
+	}
 
 	claims := &JWTClaims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:          user.ID,
+		Username:        user.Username,
+		Role:            user.Role,
+		PasswordExpired: user.PasswordExpired,
+		AuthMethod:      authMethod,
 		RegisteredClaims: jwt.RegisteredClaims{
+			// The token ID names the login session, so audit entries can be tied to it
+			ID:        user.SessionID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
 			Issuer:    configs.GlobalConfig.JWT.Issuer,
 			Subject:   user.Username,
 		},
 	}
 
+	if configs.GlobalConfig.JWT.Algorithm == AlgorithmRS256 {
+		keys, err := currentKeySet()
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		tokenString, err := keys.Sign(claims)
+		return tokenString, expirationTime, err
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(configs.GlobalConfig.JWT.SecretKey))
 
 	return tokenString, expirationTime, err
 }
 
-// ParseToken parses JWT token
+// ParseToken parses JWT token. Only tokens signed with the configured algorithm and not revoked are accepted.
 func ParseToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(configs.GlobalConfig.JWT.SecretKey), nil
-	})
+	keyfunc, algorithm, err := verificationKey()
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keyfunc, jwt.WithValidMethods([]string{algorithm}))
 
 	if err != nil {
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		if tokenRevoked(claims) {
+			return nil, ErrTokenRevoked
+		}
 		return claims, nil
 	}
 
 	return nil, jwt.ErrInvalidKey
 }
 
+// verificationKey returns how tokens are verified under the configured algorithm
+func verificationKey() (jwt.Keyfunc, string, error) {
+	if configs.GlobalConfig.JWT.Algorithm == AlgorithmRS256 {
+		keys, err := currentKeySet()
+		if err != nil {
+			return nil, "", err
+		}
+		return keys.Keyfunc, AlgorithmRS256, nil
+	}
+	return func(*jwt.Token) (interface{}, error) {
+		return []byte(configs.GlobalConfig.JWT.SecretKey), nil
+	}, AlgorithmHS256, nil
+}
+
 // JWTAuthMiddleware JWT authentication middleware
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Authorization header is required",
-			})
+			utils.ApiError(c, http.StatusUnauthorized, "Authorization header is required")
 			c.Abort()
 			return
 		}
@@ -77,10 +149,7 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		if strings.HasPrefix(authHeader, "Bearer ") {
 			tokenString = authHeader[7:] // Remove "Bearer " prefix
 		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Invalid authorization header format",
-			})
+			utils.ApiError(c, http.StatusUnauthorized, "Invalid authorization header format")
 			c.Abort()
 			return
 		}
@@ -88,28 +157,24 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		// Parse token
 		claims, err := ParseToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Invalid token: " + err.Error(),
-			})
+			utils.ApiError(c, http.StatusUnauthorized, "Invalid token: "+err.Error())
 			c.Abort()
 			return
 		}
 
 		// Check if token is expired
 		if claims.ExpiresAt.Time.Before(time.Now()) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Token has expired",
-			})
+			utils.ApiError(c, http.StatusUnauthorized, "Token has expired")
 			c.Abort()
 			return
 		}
 
+		if rejectExpiredPassword(c, claims) {
+			return
+		}
+
 		// Store user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("user_role", claims.Role)
+		setCurrentUser(c, claims)
 
 		c.Next()
 	}
@@ -121,10 +186,7 @@ func JWTAuthWithSessionMiddleware(securityService interface{}) gin.HandlerFunc {
 		// Get token from header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Authorization header is required",
-			})
+			utils.ApiError(c, http.StatusUnauthorized, "Authorization header is required")
 			c.Abort()
 			return
 		}
@@ -134,10 +196,7 @@ func JWTAuthWithSessionMiddleware(securityService interface{}) gin.HandlerFunc {
 		if strings.HasPrefix(authHeader, "Bearer ") {
 			tokenString = authHeader[7:] // Remove "Bearer " prefix
 		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Invalid authorization header format",
-			})
+			utils.ApiError(c, http.StatusUnauthorized, "Invalid authorization header format")
 			c.Abort()
 			return
 		}
@@ -145,28 +204,24 @@ func JWTAuthWithSessionMiddleware(securityService interface{}) gin.HandlerFunc {
 		// Parse token
 		claims, err := ParseToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Invalid token: " + err.Error(),
-			})
+			utils.ApiError(c, http.StatusUnauthorized, "Invalid token: "+err.Error())
 			c.Abort()
 			return
 		}
 
 		// Check if token is expired
 		if claims.ExpiresAt.Time.Before(time.Now()) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "Token has expired",
-			})
+			utils.ApiError(c, http.StatusUnauthorized, "Token has expired")
 			c.Abort()
 			return
 		}
 
+		if rejectExpiredPassword(c, claims) {
+			return
+		}
+
 		// Store user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("user_role", claims.Role)
+		setCurrentUser(c, claims)
 
 		c.Next()
 	}
@@ -178,10 +233,7 @@ func AdminRequiredMiddleware() gin.HandlerFunc {
 		role, exists := c.Get("user_role")
 		if !exists {
 			fmt.Printf("DEBUG: User role not found in context\n")
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "User information not found",
-			})
+			utils.ApiError(c, http.StatusUnauthorized, "User information not found")
 			c.Abort()
 			return
 		}
@@ -190,10 +242,7 @@ func AdminRequiredMiddleware() gin.HandlerFunc {
 
 		if role != "admin" {
 			fmt.Printf("DEBUG: Access denied - role '%v' is not admin\n", role)
-			c.JSON(http.StatusForbidden, gin.H{
-				"code":    403,
-				"message": "Admin privileges required",
-			})
+			utils.ApiError(c, http.StatusForbidden, "Admin privileges required")
 			c.Abort()
 			return
 		}
@@ -206,38 +255,76 @@ func AdminRequiredMiddleware() gin.HandlerFunc {
 // OptionalAuthMiddleware optional authentication middleware (does not require mandatory login)
 func OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.Next()
-			return
+		if claims, ok := optionalClaims(c); ok {
+			// Set user information to context
+			setCurrentUser(c, claims)
 		}
 
-		tokenString := ""
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			tokenString = authHeader[7:]
-		} else {
-			c.Next()
-			return
-		}
+		c.Next()
+	}
+}
 
-		claims, err := ParseToken(tokenString)
-		if err != nil {
-			c.Next()
-			return
-		}
+// optionalClaims returns the claims of a valid bearer token, if the request carries one
+func optionalClaims(c *gin.Context) (*JWTClaims, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return nil, false
+	}
 
-		if claims.ExpiresAt.Time.Before(time.Now()) {
-			c.Next()
-			return
-		}
+	claims, err := ParseToken(authHeader[7:])
+	if err != nil {
+		return nil, false
+	}
 
-		// Set user information to context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("user_role", claims.Role)
+	if claims.ExpiresAt.Time.Before(time.Now()) {
+		return nil, false
+	}
 
-		c.Next()
+	// A token issued for an expired password does not count as logged in
+	if claims.PasswordExpired {
+		return nil, false
+	}
+	return claims, true
+}
+
+// CurrentUserID returns the ID of the authenticated caller. On routes without authentication middleware
+// it reads a valid bearer token, if the request carries one.
+func CurrentUserID(c *gin.Context) (uint, bool) {
+	if userID, _, _, ok := GetCurrentUser(c); ok {
+		return userID, true
+	}
+	if claims, ok := optionalClaims(c); ok {
+		return claims.UserID, true
 	}
+	return 0, false
+}
+
+// setCurrentUser stores the caller authenticated by claims in the context
+func setCurrentUser(c *gin.Context, claims *JWTClaims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("user_role", claims.Role)
+	c.Set(claimsContextKey, claims)
+	authMethod := claims.AuthMethod
+	if authMethod == "" {
+		authMethod = AuthMethodPassword
+	}
+	c.Set(authMethodContextKey, authMethod)
+}
+
+// CurrentClaims returns the token claims of the caller, false when the caller was not authenticated by a token
+func CurrentClaims(c *gin.Context) (*JWTClaims, bool) {
+	claims, ok := c.Get(claimsContextKey)
+	if !ok {
+		return nil, false
+	}
+	jwtClaims, ok := claims.(*JWTClaims)
+	return jwtClaims, ok
+}
+
+// CurrentAuthMethod returns how the caller authenticated, one of the AuthMethod constants, or "" when unauthenticated
+func CurrentAuthMethod(c *gin.Context) string {
+	return c.GetString(authMethodContextKey)
 }
 
 // GetCurrentUser gets current user information from context
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectExpiredPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	claims := &JWTClaims{PasswordExpired: true}
+	handler := func(c *gin.Context) {
+		if !rejectExpiredPassword(c, claims) {
+			c.Status(http.StatusOK)
+		}
+	}
+	for _, path := range []string{"/api/v1/auth/change-password", "/api/v1/auth/refresh", "/api/v1/auth/whoami", "/api/v1/pods"} {
+		router.POST(path, handler)
+	}
+
+	for path, status := range map[string]int{
+		"/api/v1/auth/change-password": http.StatusOK,
+		"/api/v1/auth/refresh":         http.StatusOK,
+		"/api/v1/auth/whoami":          http.StatusOK,
+		"/api/v1/pods":                 http.StatusForbidden,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, nil))
+		assert.Equal(t, status, w.Code, path)
+		if status == http.StatusForbidden {
+			assert.Contains(t, w.Body.String(), `"errorCode":"PASSWORD_EXPIRED"`)
+		}
+	}
+}
+
+func TestJWTAuthMiddleware_ErrorCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/pods", JWTAuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/pods", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"code":401,"errorCode":"UNAUTHORIZED","data":null,"message":"Authorization header is required","details":""}`, w.Body.String())
+}
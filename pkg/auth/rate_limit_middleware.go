@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -130,10 +131,7 @@ func RateLimitMiddleware(requestType string) gin.HandlerFunc {
 
 		ip := c.ClientIP()
 		if !globalRateLimiter.IsAllowed(ip, requestType) {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"code":    429,
-				"message": "Too many requests. Please try again later.",
-			})
+			utils.ApiError(c, http.StatusTooManyRequests, "Too many requests. Please try again later.")
 			c.Abort()
 			return
 		}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTokenRevoked is returned for tokens issued before their user logged out everywhere
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// revokedBefore holds per user the time before which issued tokens are rejected. Like sessions it is kept
+// in memory, so revocations don't survive a restart.
+var (
+	revocationMu  sync.RWMutex
+	revokedBefore = make(map[uint]time.Time)
+)
+
+// RevokeUserTokens rejects every token issued to the user so far. Tokens record their issue time in whole
+// seconds, so the cutoff is the start of the next second and tokens issued later are dated from it.
+func RevokeUserTokens(userID uint) {
+	revocationMu.Lock()
+	defer revocationMu.Unlock()
+	revokedBefore[userID] = time.Now().Truncate(time.Second).Add(time.Second)
+}
+
+// tokenIssueTime returns the issue time of a new token for the user, never before the user's revocation cutoff
+func tokenIssueTime(userID uint) time.Time {
+	now := time.Now()
+	revocationMu.RLock()
+	defer revocationMu.RUnlock()
+	if cutoff, ok := revokedBefore[userID]; ok && now.Before(cutoff) {
+		return cutoff
+	}
+	return now
+}
+
+// tokenRevoked reports whether claims were issued before their user's tokens were revoked
+func tokenRevoked(claims *JWTClaims) bool {
+	revocationMu.RLock()
+	cutoff, ok := revokedBefore[claims.UserID]
+	revocationMu.RUnlock()
+	return ok && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(cutoff))
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevokeUserTokens(t *testing.T) {
+	previousConfig := configs.GlobalConfig
+	configs.GlobalConfig = &configs.Config{JWT: configs.JWTConfig{SecretKey: "test-secret", ExpireDuration: time.Hour}}
+	t.Cleanup(func() {
+		configs.GlobalConfig = previousConfig
+		revocationMu.Lock()
+		delete(revokedBefore, 42)
+		revocationMu.Unlock()
+	})
+
+	user := &models.User{ID: 42, Username: "alice", Role: "viewer"}
+	other := &models.User{ID: 43, Username: "bob", Role: "viewer"}
+	oldToken, _, err := GenerateToken(user)
+	require.NoError(t, err)
+	otherToken, _, err := GenerateToken(other)
+	require.NoError(t, err)
+
+	RevokeUserTokens(user.ID)
+
+	_, err = ParseToken(oldToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = ParseToken(otherToken)
+	assert.NoError(t, err, "other users keep their tokens")
+
+	// A token issued right after the revocation, within the same second, is accepted
+	newToken, _, err := GenerateToken(user)
+	require.NoError(t, err)
+	claims, err := ParseToken(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+}
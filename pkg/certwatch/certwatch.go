@@ -0,0 +1,109 @@
+// Package certwatch serves a TLS certificate loaded from files and reloads it when the files change,
+// so renewed certificates are used without restarting the server.
+package certwatch
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Reloader holds the certificate of a key pair on disk for tls.Config.GetCertificate
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// New loads the key pair, failing when it can't be read or the certificate doesn't match the key
+func New(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the key pair again. The previous certificate stays in use when that fails.
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate %s: %w", r.certFile, err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate returns the current certificate, for tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch reloads the key pair whenever the directories holding the files change until Close is called.
+// Directories are watched rather than the files, so renewals that replace the files, like cert-manager
+// updating a mounted Secret through a symlink, are noticed too.
+func (r *Reloader) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch TLS certificate: %w", err)
+	}
+	for _, dir := range []string{filepath.Dir(r.certFile), filepath.Dir(r.keyFile)} {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch TLS certificate directory %s: %w", dir, err)
+		}
+	}
+
+	r.watcher = watcher
+	r.done = make(chan struct{})
+	go r.run()
+	return nil
+}
+
+func (r *Reloader) run() {
+	defer close(r.done)
+	for {
+		select {
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			// A renewal writing the certificate before the key fails once, the key's write loads the pair
+			if err := r.Reload(); err != nil {
+				log.Printf("Warning: keeping the current TLS certificate: %v", err)
+			}
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Warning: TLS certificate watch error: %v", err)
+		}
+	}
+}
+
+// Close stops watching the files
+func (r *Reloader) Close() error {
+	if r.watcher == nil {
+		return nil
+	}
+	err := r.watcher.Close()
+	<-r.done
+	r.watcher = nil
+	return err
+}
@@ -0,0 +1,83 @@
+package certwatch
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair writes a self-signed certificate with the given serial number and its key
+func writeKeyPair(t *testing.T, certFile, keyFile string, serial int64) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "cilikube.example.com"},
+		DNSNames:     []string{"cilikube.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+}
+
+func servedSerial(t *testing.T, r *Reloader) int64 {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	return cert.Leaf.SerialNumber.Int64()
+}
+
+func TestReloader_Watch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeKeyPair(t, certFile, keyFile, 1)
+
+	reloader, err := New(certFile, keyFile)
+	require.NoError(t, err)
+	require.NoError(t, reloader.Watch())
+	t.Cleanup(func() { reloader.Close() })
+	assert.Equal(t, int64(1), servedSerial(t, reloader))
+
+	writeKeyPair(t, certFile, keyFile, 2)
+	assert.Eventually(t, func() bool { return servedSerial(t, reloader) == 2 }, 5*time.Second, 10*time.Millisecond,
+		"the renewed certificate is served")
+
+	// A broken file keeps the renewed certificate in use
+	require.NoError(t, os.WriteFile(certFile, []byte("not a certificate"), 0o600))
+	assert.Error(t, reloader.Reload())
+	assert.Equal(t, int64(2), servedSerial(t, reloader))
+
+	require.NoError(t, reloader.Close())
+	assert.NoError(t, reloader.Close(), "closing twice is a no-op")
+}
+
+func TestNew_InvalidKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeKeyPair(t, certFile, keyFile, 1)
+	otherCert, otherKey := filepath.Join(dir, "other.crt"), filepath.Join(dir, "other.key")
+	writeKeyPair(t, otherCert, otherKey, 2)
+
+	_, err := New(certFile, otherKey)
+	assert.Error(t, err, "the certificate must match the key")
+	_, err = New(filepath.Join(dir, "missing.crt"), keyFile)
+	assert.Error(t, err)
+}
@@ -1,15 +1,20 @@
 package database
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ciliverse/cilikube/configs"
 	"github.com/ciliverse/cilikube/internal/models"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -17,6 +22,9 @@ import (
 
 var DB *gorm.DB
 
+// DefaultSQLiteBusyTimeout is how many milliseconds SQLite waits for a lock when no timeout is configured
+const DefaultSQLiteBusyTimeout = 5000
+
 // InitDatabase initializes database connection
 func InitDatabase() error {
 	if !configs.GlobalConfig.Database.Enabled {
@@ -40,10 +48,16 @@ func InitDatabase() error {
 		if err := ensureSQLiteDir(dsn); err != nil {
 			return fmt.Errorf("failed to create SQLite directory: %v", err)
 		}
-		DB, err = gorm.Open(sqlite.Open(dsn), gormConfig)
+		DB, err = openSQLite(dsn, configs.GlobalConfig.Database.BusyTimeout, gormConfig)
 	case "mysql", "":
 		// Default to MySQL for backward compatibility
+		if err := registerMySQLTLS(&configs.GlobalConfig.Database); err != nil {
+			return fmt.Errorf("failed to configure MySQL TLS: %v", err)
+		}
 		DB, err = gorm.Open(mysql.Open(dsn), gormConfig)
+	case "postgresql", "postgres":
+		// The DSN carries sslmode and the certificate files, see DatabaseConfig.PostgresDSN
+		DB, err = gorm.Open(postgres.Open(dsn), gormConfig)
 	default:
 		return fmt.Errorf("unsupported database type: %s", dbType)
 	}
@@ -52,7 +66,7 @@ func InitDatabase() error {
 		return fmt.Errorf("failed to connect to %s database: %v", dbType, err)
 	}
 
-	// Configure connection pool (skip for SQLite)
+	// Configure connection pool (SQLite is configured by openSQLite)
 	if dbType != "sqlite" {
 		sqlDB, err := DB.DB()
 		if err != nil {
@@ -74,6 +88,96 @@ func InitDatabase() error {
 	return nil
 }
 
+// openSQLite opens a SQLite database for concurrent use. WAL journaling lets readers in other
+// processes continue while a write is in progress, the busy timeout makes a connection wait for a
+// lock instead of failing with "database is locked", and a single connection serializes the
+// application's own writes, which SQLite cannot run in parallel anyway.
+func openSQLite(path string, busyTimeout int, gormConfig *gorm.Config) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(path, busyTimeout)), gormConfig)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	return db, nil
+}
+
+// sqliteDSN adds the WAL journal mode and busy timeout to a SQLite file path
+func sqliteDSN(path string, busyTimeout int) string {
+	if busyTimeout <= 0 {
+		busyTimeout = DefaultSQLiteBusyTimeout
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%s_journal_mode=WAL&_busy_timeout=%d", path, separator, busyTimeout)
+}
+
+// registerMySQLTLS registers the TLS settings built from the configured certificate files under
+// configs.MySQLTLSConfigName, which the MySQL DSN then refers to
+func registerMySQLTLS(dbConfig *configs.DatabaseConfig) error {
+	if !dbConfig.UsesMySQLTLSConfig() {
+		return nil
+	}
+	tlsConfig, err := mysqlTLSConfig(dbConfig)
+	if err != nil {
+		return err
+	}
+	return mysqldriver.RegisterTLSConfig(configs.MySQLTLSConfigName, tlsConfig)
+}
+
+// mysqlTLSConfig builds TLS settings from the certificate files, verifying the server as SSLMode asks:
+// "require" and the lenient modes only encrypt, "verify-ca" checks the certificate chain, and
+// everything else also checks the host name
+func mysqlTLSConfig(dbConfig *configs.DatabaseConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: dbConfig.Host, MinVersion: tls.VersionTLS12}
+
+	if dbConfig.SSLRootCert != "" {
+		pem, err := os.ReadFile(dbConfig.SSLRootCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read root certificate: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", dbConfig.SSLRootCert)
+		}
+	}
+	if dbConfig.SSLCert != "" {
+		cert, err := tls.LoadX509KeyPair(dbConfig.SSLCert, dbConfig.SSLKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch dbConfig.SSLMode {
+	case "allow", "prefer", "preferred", "require", "skip-verify":
+		tlsConfig.InsecureSkipVerify = true
+	case "verify-ca":
+		// Verify the chain ourselves, without matching the host name
+		roots := tlsConfig.RootCAs
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return fmt.Errorf("server sent no certificate")
+			}
+			opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+			for _, cert := range state.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := state.PeerCertificates[0].Verify(opts)
+			return err
+		}
+	}
+	return tlsConfig, nil
+}
+
 // ensureSQLiteDir ensures the directory exists for SQLite database file
 func ensureSQLiteDir(dbPath string) error {
 	dir := filepath.Dir(dbPath)
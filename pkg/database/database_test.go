@@ -0,0 +1,123 @@
+package database
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testRecord struct {
+	ID    uint `gorm:"primaryKey"`
+	Name  string
+	Count int
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	assert.Equal(t, "data/cilikube.db?_journal_mode=WAL&_busy_timeout=5000", sqliteDSN("data/cilikube.db", 0))
+	assert.Equal(t, "file:test.db?cache=shared&_journal_mode=WAL&_busy_timeout=250", sqliteDSN("file:test.db?cache=shared", 250))
+}
+
+func TestOpenSQLite_ConcurrentWrites(t *testing.T) {
+	db, err := openSQLite(filepath.Join(t.TempDir(), "cilikube.db"), 0, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	require.NoError(t, db.AutoMigrate(&testRecord{}))
+
+	var journalMode string
+	require.NoError(t, db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error)
+	assert.Equal(t, "wal", journalMode)
+
+	const writers, writesPerWriter = 16, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*writesPerWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < writesPerWriter; i++ {
+				record := &testRecord{Name: fmt.Sprintf("writer-%d-%d", w, i)}
+				if err := db.Create(record).Error; err != nil {
+					errs <- err
+					continue
+				}
+				err := db.Transaction(func(tx *gorm.DB) error {
+					return tx.Model(record).Update("count", gorm.Expr("count + 1")).Error
+				})
+				if err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent write failed: %v", err)
+	}
+
+	var total int64
+	require.NoError(t, db.Model(&testRecord{}).Where("count = 1").Count(&total).Error)
+	assert.Equal(t, int64(writers*writesPerWriter), total)
+}
+
+// writeTestCA writes a self-signed CA certificate and returns its path
+func writeTestCA(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "cilikube test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return path
+}
+
+func TestMySQLTLSConfig(t *testing.T) {
+	caPath := writeTestCA(t)
+
+	tlsConfig, err := mysqlTLSConfig(&configs.DatabaseConfig{Host: "db.example.com", SSLMode: "verify-full", SSLRootCert: caPath})
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Equal(t, "db.example.com", tlsConfig.ServerName)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+
+	tlsConfig, err = mysqlTLSConfig(&configs.DatabaseConfig{SSLMode: "verify-ca", SSLRootCert: caPath})
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify, "the host name is not checked")
+	assert.NotNil(t, tlsConfig.VerifyConnection, "the chain is still verified")
+
+	tlsConfig, err = mysqlTLSConfig(&configs.DatabaseConfig{SSLMode: "require", SSLRootCert: caPath})
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+
+	_, err = mysqlTLSConfig(&configs.DatabaseConfig{SSLMode: "verify-full", SSLRootCert: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.Error(t, err)
+}
@@ -2,8 +2,13 @@ package k8s
 
 import (
 	"fmt"
+	"net"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/ciliverse/cilikube/configs"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
@@ -35,19 +40,91 @@ type Client struct {
 	clusterInfo *ClusterInfo
 }
 
-func NewClient(kubeconfig string) (*Client, error) {
+// ClientOptions tunes the rate limits and timeouts of the clients built for a cluster
+type ClientOptions struct {
+	QPS   float32
+	Burst int
+	// Timeout bounds every request except watches, followed logs and exec, attach and port-forward sessions
+	Timeout time.Duration
+	// ExecAllowlist are the exec credential plugins kubeconfigs may run, see CheckExecPlugin
+	ExecAllowlist []string
+	// ExecEnvAllowlist are the environment variables their exec plugins may set
+	ExecEnvAllowlist []string
+}
+
+// DefaultClientOptions returns the options used when none are configured, the single source of the
+// kubernetes.qps, kubernetes.burst and kubernetes.request_timeout defaults
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		QPS:              50.0,
+		Burst:            100,
+		Timeout:          30 * time.Second,
+		ExecAllowlist:    DefaultExecAllowlist,
+		ExecEnvAllowlist: DefaultExecEnvAllowlist,
+	}
+}
+
+// ClientOptionsFromConfig returns the client options configured under kubernetes, falling back to the defaults
+func ClientOptionsFromConfig(config configs.KubernetesConfig) ClientOptions {
+	opts := DefaultClientOptions()
+	if config.QPS > 0 {
+		opts.QPS = config.QPS
+	}
+	if config.Burst > 0 {
+		opts.Burst = config.Burst
+	}
+	if config.RequestTimeout > 0 {
+		opts.Timeout = config.RequestTimeout
+	}
+	if config.ExecAllowlist != nil {
+		opts.ExecAllowlist = config.ExecAllowlist
+	}
+	if config.ExecEnvAllowlist != nil {
+		opts.ExecEnvAllowlist = config.ExecEnvAllowlist
+	}
+	return opts
+}
+
+// apply sets the rate limits on config, retries idempotent requests on transient errors and bounds
+// non-streaming requests by the timeout
+func (o ClientOptions) apply(config *rest.Config) {
+	if config.QPS == 0 {
+		config.QPS = o.QPS
+	}
+	if config.Burst == 0 {
+		config.Burst = o.Burst
+	}
+	config.Wrap(newRetryTransport)
+	config.Wrap(newTimeoutTransport(o.Timeout))
+}
+
+func NewClient(kubeconfig string, opts ClientOptions) (*Client, error) {
 	config, err := buildConfig(kubeconfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build Kubernetes config: %w", err)
 	}
 
-	return newClientFromConfig(config)
+	return newClientFromConfig(config, opts)
+}
+
+// InClusterConfigPath is the config_path value that selects the service account mounted into the cilikube pod
+const InClusterConfigPath = "in-cluster"
+
+// Locations of the service account credentials mounted into every pod, variables so tests can replace them
+var (
+	inClusterTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	inClusterCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// IsInClusterConfigPath reports whether a cluster's config_path selects in-cluster service account auth
+func IsInClusterConfigPath(configPath string) bool {
+	return strings.EqualFold(strings.TrimSpace(configPath), InClusterConfigPath)
 }
 
 func buildConfig(kubeconfig string) (*rest.Config, error) {
 
-	if kubeconfig == "in-cluster" {
-		return rest.InClusterConfig()
+	if IsInClusterConfigPath(kubeconfig) {
+		return buildInClusterConfig()
 	}
 
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
@@ -63,6 +140,27 @@ func buildConfig(kubeconfig string) (*rest.Config, error) {
 	return clientConfig.ClientConfig()
 }
 
+// buildInClusterConfig builds a config from the pod's service account. Unlike rest.InClusterConfig it only
+// references the token file, so client-go re-reads the token as the kubelet rotates it.
+func buildInClusterConfig() (*rest.Config, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("config_path is %q but cilikube is not running inside a Kubernetes pod: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set", InClusterConfigPath)
+	}
+	if _, err := os.Stat(inClusterTokenFile); err != nil {
+		return nil, fmt.Errorf("config_path is %q but no service account token is mounted (is automountServiceAccountToken disabled?): %w", InClusterConfigPath, err)
+	}
+	if _, err := os.Stat(inClusterCAFile); err != nil {
+		return nil, fmt.Errorf("config_path is %q but the service account CA certificate is missing: %w", InClusterConfigPath, err)
+	}
+
+	return &rest.Config{
+		Host:            "https://" + net.JoinHostPort(host, port),
+		BearerTokenFile: inClusterTokenFile,
+		TLSClientConfig: rest.TLSClientConfig{CAFile: inClusterCAFile},
+	}, nil
+}
+
 func resolveKubeconfigPath(kubeconfig string) string {
 
 	if kubeconfig != "" && kubeconfig != "default" {
@@ -78,16 +176,15 @@ func resolveKubeconfigPath(kubeconfig string) string {
 	return defaultKubeconfig
 }
 
-func newClientFromConfig(config *rest.Config) (*Client, error) {
+func newClientFromConfig(config *rest.Config, opts ClientOptions) (*Client, error) {
+	// client-go runs the exec credential plugin on the first request, so it is checked beforehand
+	if err := CheckExecPlugin(config, opts.ExecAllowlist, opts.ExecEnvAllowlist); err != nil {
+		return nil, err
+	}
+
 	// Create configuration copy to avoid modifying original configuration
 	clientConfig := *config
-
-	if clientConfig.QPS == 0 {
-		clientConfig.QPS = 50.0
-	}
-	if clientConfig.Burst == 0 {
-		clientConfig.Burst = 100
-	}
+	opts.apply(&clientConfig)
 
 	// Try to create client using original configuration
 	clientset, err := kubernetes.NewForConfig(&clientConfig)
@@ -105,10 +202,13 @@ func newClientFromConfig(config *rest.Config) (*Client, error) {
 				Insecure: true,
 			},
 			// Preserve authentication information
-			Username:    clientConfig.Username,
-			Password:    clientConfig.Password,
-			BearerToken: clientConfig.BearerToken,
-			Timeout:     clientConfig.Timeout,
+			Username:        clientConfig.Username,
+			Password:        clientConfig.Password,
+			BearerToken:     clientConfig.BearerToken,
+			BearerTokenFile: clientConfig.BearerTokenFile,
+			ExecProvider:    clientConfig.ExecProvider,
+			Timeout:         clientConfig.Timeout,
+			WrapTransport:   clientConfig.WrapTransport,
 		}
 
 		clientset, err = kubernetes.NewForConfig(insecureConfig)
@@ -142,7 +242,7 @@ func newClientFromConfig(config *rest.Config) (*Client, error) {
 	return client, nil
 }
 
-func NewClientFromContent(kubeconfigData []byte) (*Client, error) {
+func NewClientFromContent(kubeconfigData []byte, opts ClientOptions) (*Client, error) {
 	if len(kubeconfigData) == 0 {
 		return nil, fmt.Errorf("kubeconfig content cannot be empty")
 	}
@@ -157,7 +257,7 @@ func NewClientFromContent(kubeconfigData []byte) (*Client, error) {
 		return nil, fmt.Errorf("failed to get REST config from client config: %w", err)
 	}
 
-	return newClientFromConfig(restConfig)
+	return newClientFromConfig(restConfig, opts)
 }
 
 func (c *Client) initClusterInfo() error {
@@ -0,0 +1,172 @@
+package k8s
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const emptyNamespaceList = `{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[]}`
+
+// fakeTransport answers every request after delay, failing the first failures requests with status 503
+type fakeTransport struct {
+	delay    time.Duration
+	failures int32
+	calls    int32
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := atomic.AddInt32(&t.calls, 1)
+	select {
+	case <-time.After(t.delay):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	status, body := http.StatusOK, emptyNamespaceList
+	if call <= t.failures {
+		status, body = http.StatusServiceUnavailable, `{"kind":"Status","apiVersion":"v1","status":"Failure","code":503}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func newFakeClientset(t *testing.T, transport http.RoundTripper, opts ClientOptions) kubernetes.Interface {
+	t.Helper()
+	config := &rest.Config{Host: "https://cluster.invalid", Transport: transport}
+	opts.apply(config)
+	clientset, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err)
+	return clientset
+}
+
+func TestClientOptions_TimeoutFires(t *testing.T) {
+	transport := &fakeTransport{delay: 10 * time.Second}
+	opts := DefaultClientOptions()
+	opts.Timeout = 100 * time.Millisecond
+	clientset := newFakeClientset(t, transport, opts)
+
+	start := time.Now()
+	_, err := clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second, "a slow API server must not hold the request until it answers")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&transport.calls), "timeouts are not retried")
+}
+
+func TestClientOptions_RetriesIdempotentRequests(t *testing.T) {
+	transport := &fakeTransport{failures: 2}
+	clientset := newFakeClientset(t, transport, DefaultClientOptions())
+
+	list, err := clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&transport.calls))
+
+	transport = &fakeTransport{failures: 1}
+	clientset = newFakeClientset(t, transport, DefaultClientOptions())
+	_, err = clientset.CoreV1().Namespaces().Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "demo"}}, metav1.CreateOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&transport.calls), "non-idempotent requests are not retried")
+}
+
+func TestClientOptionsFromConfig(t *testing.T) {
+	assert.Equal(t, DefaultClientOptions(), ClientOptionsFromConfig(configs.KubernetesConfig{}))
+
+	opts := ClientOptionsFromConfig(configs.KubernetesConfig{QPS: 5, Burst: 10, RequestTimeout: time.Second})
+	assert.Equal(t, ClientOptions{QPS: 5, Burst: 10, Timeout: time.Second, ExecAllowlist: DefaultExecAllowlist, ExecEnvAllowlist: DefaultExecEnvAllowlist}, opts)
+	assert.Equal(t, []string{"/opt/bin/aws"}, ClientOptionsFromConfig(configs.KubernetesConfig{ExecAllowlist: []string{"/opt/bin/aws"}}).ExecAllowlist)
+	assert.Empty(t, ClientOptionsFromConfig(configs.KubernetesConfig{ExecEnvAllowlist: []string{}}).ExecEnvAllowlist, "an empty list allows no variables")
+
+	config := &rest.Config{QPS: 1}
+	opts.apply(config)
+	assert.Equal(t, float32(1), config.QPS, "values from the kubeconfig are kept")
+	assert.Equal(t, 10, config.Burst)
+	assert.Zero(t, config.Timeout, "a client-wide timeout would cut off streams")
+}
+
+func TestClientOptions_TimeoutSkipsStreams(t *testing.T) {
+	transport := &fakeTransport{delay: 300 * time.Millisecond}
+	opts := DefaultClientOptions()
+	opts.Timeout = 100 * time.Millisecond
+	clientset := newFakeClientset(t, transport, opts)
+
+	_, err := clientset.CoreV1().Namespaces().Watch(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err, "watches outlive the request timeout")
+	_, err = clientset.CoreV1().Pods("default").GetLogs("web", &corev1.PodLogOptions{Follow: true}).Stream(context.Background())
+	assert.NoError(t, err, "followed logs outlive the request timeout")
+	_, err = clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	assert.Error(t, err)
+}
+
+func useInClusterFiles(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	tokenFile, caFile := filepath.Join(dir, "token"), filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token"), 0o600))
+	require.NoError(t, os.WriteFile(caFile, []byte("ca"), 0o600))
+
+	oldToken, oldCA := inClusterTokenFile, inClusterCAFile
+	inClusterTokenFile, inClusterCAFile = tokenFile, caFile
+	t.Cleanup(func() { inClusterTokenFile, inClusterCAFile = oldToken, oldCA })
+	return tokenFile, caFile
+}
+
+func TestIsInClusterConfigPath(t *testing.T) {
+	assert.True(t, IsInClusterConfigPath("in-cluster"))
+	assert.True(t, IsInClusterConfigPath(" In-Cluster "))
+	assert.False(t, IsInClusterConfigPath(""))
+	assert.False(t, IsInClusterConfigPath("/root/.kube/in-cluster"))
+}
+
+func TestBuildConfig_InCluster(t *testing.T) {
+	tokenFile, caFile := useInClusterFiles(t)
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+
+	config, err := buildConfig("in-cluster")
+	require.NoError(t, err)
+	assert.Equal(t, "https://10.96.0.1:443", config.Host)
+	assert.Equal(t, tokenFile, config.BearerTokenFile)
+	assert.Empty(t, config.BearerToken, "the token is read from the file so rotation is picked up")
+	assert.Equal(t, caFile, config.TLSClientConfig.CAFile)
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "fd00::1")
+	config, err = buildConfig("in-cluster")
+	require.NoError(t, err)
+	assert.Equal(t, "https://[fd00::1]:443", config.Host)
+}
+
+func TestBuildConfig_InClusterEnvironmentMissing(t *testing.T) {
+	tokenFile, _ := useInClusterFiles(t)
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	_, err := buildConfig("in-cluster")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not running inside a Kubernetes pod")
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+	require.NoError(t, os.Remove(tokenFile))
+	_, err = buildConfig("in-cluster")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no service account token is mounted")
+}
@@ -1,22 +1,119 @@
 package k8s
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/ciliverse/cilikube/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
-// GetClientFromQuery gets clusterId from URL query parameters and returns the corresponding k8s client.
+// ClusterIDHeader selects the target cluster of a request whose path names none
+const ClusterIDHeader = "X-Cluster-ID"
+
+// PreferredClusterFunc returns the cluster the caller of a request chose as default, or "" when there is none
+type PreferredClusterFunc func(c *gin.Context) string
+
+type requestClusterKey struct{}
+
+// RequestCluster is the cluster a request targets. The cluster is resolved and its client is built on
+// first use, so requests that never touch a cluster don't pay for it.
+type RequestCluster struct {
+	manager *ClusterManager
+	resolve func() string
+
+	once   sync.Once
+	id     string
+	client *Client
+	err    error
+}
+
+func (rc *RequestCluster) load() {
+	rc.once.Do(func() {
+		rc.id = rc.resolve()
+		if rc.id == "" {
+			rc.err = ErrNoActiveCluster
+			return
+		}
+		rc.client, rc.err = rc.manager.GetClientByID(rc.id)
+	})
+}
+
+// ID returns the ID of the target cluster, empty when none is selected and none is active
+func (rc *RequestCluster) ID() string {
+	rc.load()
+	return rc.id
+}
+
+// Client returns the client of the target cluster
+func (rc *RequestCluster) Client() (*Client, error) {
+	rc.load()
+	return rc.client, rc.err
+}
+
+// ClusterContext attaches the target cluster of each request to the request context, so concurrent
+// requests can work on different clusters. The cluster is taken from, in order: the /clusters/:id path,
+// the X-Cluster-ID header, the clusterId query parameter, the caller's preferred cluster and finally
+// the globally active cluster.
+func ClusterContext(cm *ClusterManager, preferred PreferredClusterFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rc := &RequestCluster{manager: cm}
+		// Resolved on first use, after route middleware such as authentication has run
+		rc.resolve = func() string { return resolveClusterID(c, cm, preferred) }
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestClusterKey{}, rc))
+		c.Next()
+	}
+}
+
+// RequestClusterFrom returns the cluster attached by ClusterContext, nil when the middleware is not installed
+func RequestClusterFrom(ctx context.Context) *RequestCluster {
+	rc, _ := ctx.Value(requestClusterKey{}).(*RequestCluster)
+	return rc
+}
+
+// resolveClusterID picks the target cluster of a request, see ClusterContext
+func resolveClusterID(c *gin.Context, cm *ClusterManager, preferred PreferredClusterFunc) string {
+	if strings.Contains(c.FullPath(), "/clusters/:id") {
+		return c.Param("id")
+	}
+	if id := strings.TrimSpace(c.GetHeader(ClusterIDHeader)); id != "" {
+		return id
+	}
+	if id := c.Query("clusterId"); id != "" {
+		return id
+	}
+	// A preference for a cluster that has since been removed is ignored
+	if preferred != nil {
+		if id := preferred(c); id != "" && cm.HasCluster(id) {
+			return id
+		}
+	}
+	return cm.GetActiveClusterID()
+}
+
+// GetClientFromQuery returns the client of the cluster a request targets. Behind ClusterContext this is
+// the cluster it resolved; otherwise the clusterId query parameter, falling back to the active cluster.
 // This is the "gatekeeper" for all resource operation handler functions.
 func GetClientFromQuery(c *gin.Context, cm *ClusterManager) (*Client, bool) {
+	if rc := RequestClusterFrom(c.Request.Context()); rc != nil {
+		client, err := rc.Client()
+		if err != nil {
+			respondClientError(c, rc.ID(), err)
+			return nil, false
+		}
+		return client, true
+	}
+
 	clusterID := c.Query("clusterId")
 	if clusterID == "" {
 		// If no clusterId is provided, try to use the currently active cluster as fallback
 		activeID := cm.GetActiveClusterID()
 		if activeID == "" {
-			utils.ApiError(c, http.StatusBadRequest, "missing 'clusterId' query parameter and no active default cluster", "e.g., /api/v1/nodes?clusterId=cls-xxxxx")
+			respondNoActiveCluster(c)
 			return nil, false
 		}
 		clusterID = activeID
@@ -24,9 +121,66 @@ func GetClientFromQuery(c *gin.Context, cm *ClusterManager) (*Client, bool) {
 
 	client, err := cm.GetClientByID(clusterID)
 	if err != nil {
-		utils.ApiError(c, http.StatusNotFound, fmt.Sprintf("cluster ID '%s' not found or unavailable", clusterID), err.Error())
+		respondClientError(c, clusterID, err)
 		return nil, false
 	}
 
 	return client, true
 }
+
+// GetClientFromPath gets the cluster ID from the ':id' path parameter and returns the corresponding k8s client.
+// It is used by routes nested under /clusters/:id.
+func GetClientFromPath(c *gin.Context, cm *ClusterManager) (*Client, bool) {
+	return GetClientByID(c, cm, c.Param("id"))
+}
+
+// GetClientByID returns the client of a cluster, answering the request with an error when the cluster is
+// unknown or unreachable
+func GetClientByID(c *gin.Context, cm *ClusterManager, clusterID string) (*Client, bool) {
+	client, err := cm.GetClientByID(clusterID)
+	if err != nil {
+		respondClientError(c, clusterID, err)
+		return nil, false
+	}
+	return client, true
+}
+
+// ExplicitClusterID returns the cluster a request names with the X-Cluster-ID header or the clusterId query
+// parameter, empty when it names none. Unlike ClusterContext it never falls back to a preferred or active
+// cluster, for requests that only need a cluster when asked to.
+func ExplicitClusterID(c *gin.Context) string {
+	if id := strings.TrimSpace(c.GetHeader(ClusterIDHeader)); id != "" {
+		return id
+	}
+	return c.Query("clusterId")
+}
+
+// respondClientError answers NOT_FOUND for unknown clusters, CLUSTER_CREDENTIAL_EXPIRED for clusters whose
+// client certificate expired and CLUSTER_UNREACHABLE for clusters whose client could not be created
+func respondClientError(c *gin.Context, clusterID string, err error) {
+	message := fmt.Sprintf("cluster ID '%s' not found or unavailable", clusterID)
+	if errors.Is(err, ErrNoActiveCluster) {
+		respondNoActiveCluster(c)
+		return
+	}
+	if errors.Is(err, ErrClusterNotFound) {
+		utils.ApiErrorFrom(c, utils.NewAPIError(http.StatusNotFound, utils.ErrCodeNotFound, message, err.Error()))
+		return
+	}
+	if errors.Is(err, ErrClusterCredentialExpired) {
+		utils.ApiErrorFrom(c, utils.NewAPIError(http.StatusServiceUnavailable, utils.ErrCodeClusterCredentialExpired, message, err.Error()))
+		return
+	}
+	utils.ApiErrorFrom(c, utils.NewAPIError(http.StatusServiceUnavailable, utils.ErrCodeClusterUnreachable, message, err.Error()))
+}
+
+// NoActiveClusterError is the response of requests that need a cluster while none is registered or active
+func NoActiveClusterError() *utils.APIError {
+	return utils.NewAPIError(http.StatusConflict, utils.ErrCodeNoActiveCluster, ErrNoActiveCluster.Error(),
+		"register a cluster under /api/v1/clusters, or select one with the '"+ClusterIDHeader+"' header or the 'clusterId' query parameter")
+}
+
+// respondNoActiveCluster answers 409 NO_ACTIVE_CLUSTER
+func respondNoActiveCluster(c *gin.Context) {
+	utils.ApiErrorFrom(c, NoActiveClusterError())
+}
@@ -0,0 +1,102 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClusterContextRouter answers the host of the client each request resolves to
+func newClusterContextRouter(cm *ClusterManager, preferred PreferredClusterFunc, handlerHook func()) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ClusterContext(cm, preferred))
+	respond := func(c *gin.Context) {
+		if handlerHook != nil {
+			handlerHook()
+		}
+		client, ok := GetClientFromQuery(c, cm)
+		if !ok {
+			return
+		}
+		c.String(http.StatusOK, client.Config.Host)
+	}
+	router.GET("/nodes", respond)
+	router.GET("/clusters/:id/nodes", respond)
+	return router
+}
+
+func TestClusterContext_ConcurrentRequestsUseTheirHeader(t *testing.T) {
+	cm, _ := newTestClusterManager(t, 5, "a", "b")
+	require.NoError(t, cm.SetActiveClusterByID("a"))
+
+	// Both handlers wait for each other, so the requests are in flight at the same time
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	router := newClusterContextRouter(cm, nil, func() {
+		arrived.Done()
+		arrived.Wait()
+	})
+
+	hosts := make([]string, 2)
+	var done sync.WaitGroup
+	for i, id := range []string{"a", "b"} {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+			req.Header.Set(ClusterIDHeader, id)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			hosts[i] = w.Body.String()
+		}()
+	}
+	done.Wait()
+
+	assert.Equal(t, []string{"https://a", "https://b"}, hosts)
+	assert.Equal(t, "a", cm.GetActiveClusterID(), "selecting a cluster per request leaves the active cluster alone")
+}
+
+func TestClusterContext_ResolutionOrder(t *testing.T) {
+	cm, _ := newTestClusterManager(t, 5, "a", "b", "c", "d")
+	require.NoError(t, cm.SetActiveClusterByID("a"))
+	preferred := "c"
+	router := newClusterContextRouter(cm, func(*gin.Context) string { return preferred }, nil)
+
+	get := func(target string, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if header != "" {
+			req.Header.Set(ClusterIDHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, "https://b", get("/clusters/b/nodes?clusterId=d", "c").Body.String(), "the path wins")
+	assert.Equal(t, "https://b", get("/nodes?clusterId=d", "b").Body.String(), "the header wins over the query")
+	assert.Equal(t, "https://d", get("/nodes?clusterId=d", "").Body.String())
+	assert.Equal(t, "https://c", get("/nodes", "").Body.String(), "the caller's preference")
+
+	preferred = "removed"
+	assert.Equal(t, "https://a", get("/nodes", "").Body.String(), "a stale preference falls back to the active cluster")
+	preferred = ""
+	assert.Equal(t, "https://a", get("/nodes", "").Body.String())
+
+	assert.Equal(t, http.StatusNotFound, get("/nodes", "missing").Code, "an explicit unknown cluster is not found")
+}
+
+func TestClusterContext_NoActiveCluster(t *testing.T) {
+	cm, _ := newTestClusterManager(t, 5)
+	router := newClusterContextRouter(cm, nil, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/nodes", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"errorCode":"NO_ACTIVE_CLUSTER"`)
+}
@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ClusterCredentialExpiredStatus is the status of a cluster whose kubeconfig client certificate has expired
+const ClusterCredentialExpiredStatus = "CLUSTER_CREDENTIAL_EXPIRED"
+
+// credentialExpiryWarning is how long before its client certificate expires a cluster starts being warned about
+const credentialExpiryWarning = 14 * 24 * time.Hour
+
+// ErrClusterCredentialExpired is returned for clusters whose kubeconfig client certificate has expired
+var ErrClusterCredentialExpired = errors.New("cluster credential expired")
+
+// clientCertificateExpiry returns when the client certificate of config expires, false when it
+// authenticates without one or the certificate cannot be read
+func clientCertificateExpiry(config *rest.Config) (time.Time, bool) {
+	data := config.TLSClientConfig.CertData
+	if len(data) == 0 && config.TLSClientConfig.CertFile != "" {
+		var err error
+		if data, err = os.ReadFile(config.TLSClientConfig.CertFile); err != nil {
+			return time.Time{}, false
+		}
+	}
+
+	// The first certificate is the client's own, any following ones are its chain
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return cert.NotAfter, true
+	}
+	return time.Time{}, false
+}
+
+// credentialExpiry returns when the client certificate in the kubeconfig of a cluster expires, the zero time
+// when it has none
+func (s clusterSource) credentialExpiry() time.Time {
+	var config *rest.Config
+	var err error
+	switch s.source {
+	case "database":
+		config, err = clientcmd.RESTConfigFromKubeConfig(s.kubeconfigData)
+	case "file":
+		config, err = buildConfig(s.configPath)
+	default:
+		return time.Time{}
+	}
+	if err != nil {
+		return time.Time{}
+	}
+	expiry, _ := clientCertificateExpiry(config)
+	return expiry
+}
+
+// credentialExpiredError describes the expired client certificate of a cluster
+func credentialExpiredError(id string, source clusterSource) error {
+	return fmt.Errorf("%w: the client certificate of cluster '%s' (ID: %s) expired at %s, update its kubeconfig",
+		ErrClusterCredentialExpired, source.name, id, source.certExpiry.Format(time.RFC3339))
+}
+
+// credentialExpired reports whether the client certificate of a cluster has expired
+func (s clusterSource) credentialExpired() bool {
+	return !s.certExpiry.IsZero() && !time.Now().Before(s.certExpiry)
+}
+
+// applyCredentialExpiry records the client certificate expiry of a cluster in its status. A cluster whose
+// certificate expired loses its live client and is marked CLUSTER_CREDENTIAL_EXPIRED; one expiring soon is
+// warned about. The caller must hold cm.lock.
+func (cm *ClusterManager) applyCredentialExpiry(id string) {
+	source := cm.sources[id]
+	info := cm.statusCache[id]
+	info.CredentialExpiresAt, info.Warning = nil, ""
+	// A renewed certificate clears the expired status, the client is built again on next use
+	if info.Status == ClusterCredentialExpiredStatus {
+		info.Status = idleClusterStatus
+	}
+
+	if !source.certExpiry.IsZero() {
+		expiresAt := source.certExpiry
+		info.CredentialExpiresAt = &expiresAt
+		switch remaining := time.Until(expiresAt); {
+		case remaining <= 0:
+			cm.stopClient(id)
+			info.Status = ClusterCredentialExpiredStatus
+			info.Warning = fmt.Sprintf("client certificate expired at %s", expiresAt.Format(time.RFC3339))
+			log.Printf("Warning: The client certificate of cluster '%s' (ID: %s) expired at %s", source.name, id, expiresAt.Format(time.RFC3339))
+		case remaining < credentialExpiryWarning:
+			info.Warning = fmt.Sprintf("client certificate expires at %s, in %d days", expiresAt.Format(time.RFC3339), int(remaining.Hours()/24))
+			log.Printf("Warning: The client certificate of cluster '%s' (ID: %s) expires at %s", source.name, id, expiresAt.Format(time.RFC3339))
+		}
+	}
+	cm.statusCache[id] = info
+}
@@ -0,0 +1,119 @@
+package k8s
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+// testKubeconfig returns a kubeconfig authenticating with a client certificate that expires at notAfter
+func testKubeconfig(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "admin"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+    insecure-skip-tls-verify: true
+users:
+- name: admin
+  user:
+    client-certificate-data: %s
+    client-key-data: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: admin
+current-context: test
+`, base64.StdEncoding.EncodeToString(cert), base64.StdEncoding.EncodeToString(keyPEM)))
+}
+
+func TestClientCertificateExpiry(t *testing.T) {
+	notAfter := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+	source := clusterSource{source: "database", kubeconfigData: testKubeconfig(t, notAfter)}
+	assert.True(t, source.credentialExpiry().Equal(notAfter))
+
+	_, ok := clientCertificateExpiry(&rest.Config{BearerToken: "token"})
+	assert.False(t, ok, "token authentication has no certificate to expire")
+	assert.True(t, clusterSource{source: "database", kubeconfigData: []byte("not a kubeconfig")}.credentialExpiry().IsZero())
+}
+
+func TestClusterManager_CredentialExpiry(t *testing.T) {
+	clusterStore := store.NewMemoryStore()
+	require.NoError(t, clusterStore.Initialize())
+	expired := &store.Cluster{Name: "expired", KubeconfigData: testKubeconfig(t, time.Now().Add(-24*time.Hour))}
+	expiring := &store.Cluster{Name: "expiring", KubeconfigData: testKubeconfig(t, time.Now().Add(3*24*time.Hour))}
+	valid := &store.Cluster{Name: "valid", KubeconfigData: testKubeconfig(t, time.Now().Add(365*24*time.Hour))}
+	for _, cluster := range []*store.Cluster{expired, expiring, valid} {
+		require.NoError(t, clusterStore.CreateCluster(cluster))
+	}
+	cm, err := NewClusterManager(clusterStore, &configs.Config{})
+	require.NoError(t, err)
+	cm.buildClient = func(source clusterSource, opts ClientOptions) (*Client, error) {
+		return &Client{Clientset: fake.NewSimpleClientset(), Config: &rest.Config{Host: "https://" + source.name}}, nil
+	}
+
+	info, _ := cm.GetStatusFromCache(expired.ID)
+	assert.Equal(t, ClusterCredentialExpiredStatus, info.Status)
+	require.NotNil(t, info.CredentialExpiresAt)
+	assert.Contains(t, info.Warning, "expired at")
+	_, err = cm.GetClientByID(expired.ID)
+	assert.ErrorIs(t, err, ErrClusterCredentialExpired)
+	assert.Contains(t, err.Error(), info.CredentialExpiresAt.Format(time.RFC3339))
+
+	info, _ = cm.GetStatusFromCache(expiring.ID)
+	assert.Equal(t, "Idle", info.Status)
+	assert.Contains(t, info.Warning, "expires at")
+	_, err = cm.GetClientByID(expiring.ID)
+	assert.NoError(t, err, "clusters expiring soon are still served")
+
+	info, _ = cm.GetStatusFromCache(valid.ID)
+	require.NotNil(t, info.CredentialExpiresAt)
+	assert.Empty(t, info.Warning)
+
+	// The health check notices a certificate renewed in place
+	cm.lock.Lock()
+	source := cm.sources[expired.ID]
+	source.kubeconfigData = valid.KubeconfigData
+	cm.sources[expired.ID] = source
+	cm.lock.Unlock()
+	cm.RefreshAllClusterStatus()
+	info, _ = cm.GetStatusFromCache(expired.ID)
+	assert.Equal(t, "Idle", info.Status)
+	assert.Empty(t, info.Warning)
+	_, err = cm.GetClientByID(expired.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,104 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// DefaultExecAllowlist holds the credential plugins of the major cloud providers, allowed when
+// kubernetes.exec_allowlist is not configured
+var DefaultExecAllowlist = []string{
+	"aws",                    // EKS: aws eks get-token
+	"aws-iam-authenticator",  // EKS
+	"gke-gcloud-auth-plugin", // GKE
+	"gcloud",                 // GKE, older kubeconfigs
+	"kubelogin",              // AKS and OIDC
+	"oci",                    // OKE
+	"doctl",                  // DigitalOcean
+}
+
+// DefaultExecEnvAllowlist holds the environment variables exec plugins may set when kubernetes.exec_env_allowlist
+// is not configured: those aws eks update-kubeconfig writes. Others, such as PATH, LD_PRELOAD or AWS_CONFIG_FILE,
+// could change what the plugin runs or which credentials of the server it reads.
+var DefaultExecEnvAllowlist = []string{
+	"AWS_PROFILE",
+	"AWS_REGION",
+	"AWS_DEFAULT_REGION",
+	"AWS_STS_REGIONAL_ENDPOINTS",
+}
+
+// ErrExecPluginNotAllowed is returned for kubeconfigs whose exec credential plugin is not in the allowlist
+var ErrExecPluginNotAllowed = errors.New("exec credential plugin not allowed")
+
+// ErrExecPluginNotFound is returned for kubeconfigs whose exec credential plugin is not installed
+var ErrExecPluginNotFound = errors.New("exec credential plugin not found")
+
+// CheckExecPlugin verifies that the exec credential plugin config authenticates with, if any, is allowed and
+// installed, and only sets environment variables of envAllowlist, before client-go runs it. Entries of
+// allowlist are command names looked up on PATH, or absolute paths of a binary; "*" allows any command,
+// as it allows any variable in envAllowlist.
+func CheckExecPlugin(config *rest.Config, allowlist, envAllowlist []string) error {
+	if config.ExecProvider == nil {
+		return nil
+	}
+	command := config.ExecProvider.Command
+	path, lookErr := exec.LookPath(command)
+	if !execCommandAllowed(command, path, allowlist) {
+		return fmt.Errorf("%w: the kubeconfig authenticates with '%s', which is not in kubernetes.exec_allowlist", ErrExecPluginNotAllowed, command)
+	}
+	for _, env := range config.ExecProvider.Env {
+		if !execEnvAllowed(env.Name, envAllowlist) {
+			return fmt.Errorf("%w: the kubeconfig sets '%s' for '%s', which is not in kubernetes.exec_env_allowlist", ErrExecPluginNotAllowed, env.Name, command)
+		}
+	}
+	if lookErr != nil {
+		err := fmt.Errorf("%w: the kubeconfig authenticates with '%s', which is not installed or not on the PATH of the cilikube server", ErrExecPluginNotFound, command)
+		if hint := strings.TrimSpace(config.ExecProvider.InstallHint); hint != "" {
+			err = fmt.Errorf("%w. %s", err, hint)
+		}
+		return err
+	}
+	return nil
+}
+
+// CheckExecPlugin verifies a kubeconfig's exec credential plugin against kubernetes.exec_allowlist and
+// exec_env_allowlist before it is run, e.g. to test a cluster being added
+func (cm *ClusterManager) CheckExecPlugin(config *rest.Config) error {
+	return CheckExecPlugin(config, cm.clientOptions.ExecAllowlist, cm.clientOptions.ExecEnvAllowlist)
+}
+
+// execEnvAllowed reports whether an exec plugin may set the environment variable name
+func execEnvAllowed(name string, allowlist []string) bool {
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		if entry == "*" || (entry != "" && entry == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// execCommandAllowed reports whether an exec plugin command, resolved to path on PATH, matches the allowlist.
+// A name only allows the command given by that name, not a binary of the same name elsewhere.
+func execCommandAllowed(command, path string, allowlist []string) bool {
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "*":
+			return true
+		case filepath.IsAbs(entry):
+			entry = filepath.Clean(entry)
+			if filepath.Clean(command) == entry || (path != "" && filepath.Clean(path) == entry) {
+				return true
+			}
+		case entry != "" && entry == command:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const execKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: eks
+  cluster:
+    server: https://eks.example.com
+contexts:
+- name: eks
+  context:
+    cluster: eks
+    user: eks
+current-context: eks
+users:
+- name: eks
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: %s
+      args: ["eks", "get-token", "--cluster-name", "prod"]
+      installHint: Install the AWS CLI
+      interactiveMode: Never
+`
+
+// fakeExecPlugin installs an executable named name in a directory put on PATH
+func fakeExecPlugin(t *testing.T, name string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", dir)
+	return path
+}
+
+func TestCheckExecPlugin(t *testing.T) {
+	awsPath := fakeExecPlugin(t, "aws")
+
+	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(fmt.Sprintf(execKubeconfig, "aws")))
+	require.NoError(t, err)
+	require.NotNil(t, config.ExecProvider, "the exec credential plugin is parsed")
+	assert.Equal(t, "aws", config.ExecProvider.Command)
+	assert.Equal(t, []string{"eks", "get-token", "--cluster-name", "prod"}, config.ExecProvider.Args)
+
+	assert.NoError(t, CheckExecPlugin(config, DefaultExecAllowlist, nil))
+	assert.NoError(t, CheckExecPlugin(config, []string{awsPath}, nil), "absolute entries match the command found on PATH")
+	assert.NoError(t, CheckExecPlugin(config, []string{"*"}, nil))
+	assert.ErrorIs(t, CheckExecPlugin(config, []string{"gke-gcloud-auth-plugin"}, nil), ErrExecPluginNotAllowed)
+	assert.ErrorIs(t, CheckExecPlugin(config, nil, nil), ErrExecPluginNotAllowed)
+
+	// A disallowed command is rejected before it is run
+	config, err = clientcmd.RESTConfigFromKubeConfig([]byte(fmt.Sprintf(execKubeconfig, "/tmp/steal-credentials.sh")))
+	require.NoError(t, err)
+	err = CheckExecPlugin(config, DefaultExecAllowlist, nil)
+	assert.ErrorIs(t, err, ErrExecPluginNotAllowed)
+	assert.Contains(t, err.Error(), "/tmp/steal-credentials.sh")
+	_, err = newClientFromConfig(config, DefaultClientOptions())
+	assert.ErrorIs(t, err, ErrExecPluginNotAllowed)
+
+	// An allowed plugin missing from PATH is reported with its install hint
+	t.Setenv("PATH", t.TempDir())
+	config, err = clientcmd.RESTConfigFromKubeConfig([]byte(fmt.Sprintf(execKubeconfig, "aws")))
+	require.NoError(t, err)
+	err = CheckExecPlugin(config, DefaultExecAllowlist, nil)
+	assert.ErrorIs(t, err, ErrExecPluginNotFound)
+	assert.Contains(t, err.Error(), "'aws'")
+	assert.Contains(t, err.Error(), "Install the AWS CLI")
+}
+
+func TestCheckExecPlugin_Env(t *testing.T) {
+	fakeExecPlugin(t, "aws")
+	withEnv := func(name string) string {
+		return fmt.Sprintf(execKubeconfig, "aws") + "      env:\n      - name: " + name + "\n        value: x\n"
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(withEnv("AWS_PROFILE")))
+	require.NoError(t, err)
+	require.Len(t, config.ExecProvider.Env, 1)
+	assert.NoError(t, CheckExecPlugin(config, DefaultExecAllowlist, DefaultExecEnvAllowlist))
+
+	for _, name := range []string{"PATH", "LD_PRELOAD", "AWS_CONFIG_FILE"} {
+		config, err = clientcmd.RESTConfigFromKubeConfig([]byte(withEnv(name)))
+		require.NoError(t, err)
+		err = CheckExecPlugin(config, DefaultExecAllowlist, DefaultExecEnvAllowlist)
+		assert.ErrorIs(t, err, ErrExecPluginNotAllowed, name)
+		assert.Contains(t, err.Error(), name)
+		assert.NoError(t, CheckExecPlugin(config, DefaultExecAllowlist, []string{name}), "operators can allow %s", name)
+		assert.NoError(t, CheckExecPlugin(config, DefaultExecAllowlist, []string{"*"}))
+	}
+	_, err = newClientFromConfig(config, DefaultClientOptions())
+	assert.ErrorIs(t, err, ErrExecPluginNotAllowed, "clients are not built with a disallowed variable")
+}
@@ -0,0 +1,110 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+)
+
+// informerSyncTimeout bounds the initial informer cache sync for a cluster
+const informerSyncTimeout = 30 * time.Second
+
+// InformerRegistry runs one shared informer factory per cluster for the services answering from informer
+// caches, so an informer such as the pod one is started once per cluster however many services read it.
+// The cluster manager stops the informers of a cluster when its client is evicted or the cluster removed.
+type InformerRegistry struct {
+	mu          sync.Mutex
+	clusters    map[string]*clusterInformers
+	syncTimeout time.Duration
+}
+
+// clusterInformers holds the running informer factory of a cluster
+type clusterInformers struct {
+	factory informers.SharedInformerFactory
+	stopCh  chan struct{}
+}
+
+// NewInformerRegistry creates an empty InformerRegistry
+func NewInformerRegistry() *InformerRegistry {
+	return &InformerRegistry{
+		clusters:    make(map[string]*clusterInformers),
+		syncTimeout: informerSyncTimeout,
+	}
+}
+
+// Sync passes the informer factory of a cluster to register, which requests the listers the caller reads,
+// then starts the informers behind them and waits for their caches. The factory is created from clientset
+// on first use. The wait happens outside the registry lock, so a slow cluster does not hold up the others.
+func (r *InformerRegistry) Sync(clusterID string, clientset kubernetes.Interface, register func(informers.SharedInformerFactory)) error {
+	ci := r.informersFor(clusterID, clientset)
+	register(ci.factory)
+	ci.factory.Start(ci.stopCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.syncTimeout)
+	defer cancel()
+	// Stopping the cluster meanwhile ends the wait as well
+	go func() {
+		select {
+		case <-ci.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for informerType, synced := range ci.factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			r.stop(clusterID, ci)
+			return fmt.Errorf("failed to sync %v cache for cluster %s", informerType, clusterID)
+		}
+	}
+	return nil
+}
+
+// informersFor returns the informers of a cluster, creating its factory on first use
+func (r *InformerRegistry) informersFor(clusterID string, clientset kubernetes.Interface) *clusterInformers {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ci, ok := r.clusters[clusterID]; ok {
+		return ci
+	}
+	ci := &clusterInformers{
+		factory: informers.NewSharedInformerFactory(clientset, 0),
+		stopCh:  make(chan struct{}),
+	}
+	r.clusters[clusterID] = ci
+	return ci
+}
+
+// Stop stops and drops the informers of a cluster, the next Sync starts them again from its current client
+func (r *InformerRegistry) Stop(clusterID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ci, ok := r.clusters[clusterID]; ok {
+		close(ci.stopCh)
+		delete(r.clusters, clusterID)
+	}
+}
+
+// stop drops the informers of a cluster unless they were already replaced
+func (r *InformerRegistry) stop(clusterID string, ci *clusterInformers) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.clusters[clusterID] == ci {
+		close(ci.stopCh)
+		delete(r.clusters, clusterID)
+	}
+}
+
+// Running reports whether informers are running for a cluster
+func (r *InformerRegistry) Running(clusterID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.clusters[clusterID]
+	return ok
+}
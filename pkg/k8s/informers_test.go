@@ -0,0 +1,119 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestInformerRegistry_SharesFactoryPerCluster(t *testing.T) {
+	registry := NewInformerRegistry()
+	t.Cleanup(func() { registry.Stop("a") })
+	clientset := fake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}})
+
+	var factories []informers.SharedInformerFactory
+	var pods corelisters.PodLister
+	for i := 0; i < 2; i++ {
+		require.NoError(t, registry.Sync("a", clientset, func(factory informers.SharedInformerFactory) {
+			factories = append(factories, factory)
+			pods = factory.Core().V1().Pods().Lister()
+		}))
+	}
+	assert.Same(t, factories[0], factories[1], "services share the informers of a cluster")
+	list, err := pods.List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	registry.Stop("a")
+	assert.False(t, registry.Running("a"))
+	require.NoError(t, registry.Sync("a", clientset, func(factory informers.SharedInformerFactory) {
+		assert.NotSame(t, factories[0], factory, "stopped informers are started again")
+		factory.Core().V1().Pods().Lister()
+	}))
+}
+
+func TestInformerRegistry_SlowClusterDoesNotBlockOthers(t *testing.T) {
+	registry := NewInformerRegistry()
+	registry.syncTimeout = 500 * time.Millisecond
+	t.Cleanup(func() { registry.Stop("fast") })
+
+	slow := fake.NewSimpleClientset()
+	slow.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		time.Sleep(time.Second)
+		return false, nil, nil
+	})
+	slowDone := make(chan error)
+	go func() {
+		slowDone <- registry.Sync("slow", slow, func(factory informers.SharedInformerFactory) {
+			factory.Core().V1().Pods().Lister()
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	start := time.Now()
+	require.NoError(t, registry.Sync("fast", fake.NewSimpleClientset(), func(factory informers.SharedInformerFactory) {
+		factory.Core().V1().Pods().Lister()
+	}))
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+
+	assert.Error(t, <-slowDone, "the sync of the slow cluster times out")
+	assert.False(t, registry.Running("slow"), "informers failing to sync are stopped")
+}
+
+func TestClusterManager_StopsInformersOfEvictedClients(t *testing.T) {
+	cm, _ := newTestClusterManager(t, 1, "a", "b")
+	clientset := fake.NewSimpleClientset()
+	sync := func(id string) {
+		require.NoError(t, cm.Informers().Sync(id, clientset, func(factory informers.SharedInformerFactory) {
+			factory.Core().V1().Pods().Lister()
+		}))
+	}
+
+	_, err := cm.GetClientByID("a")
+	require.NoError(t, err)
+	sync("a")
+	assert.True(t, cm.Informers().Running("a"))
+
+	_, err = cm.GetClientByID("b")
+	require.NoError(t, err)
+	sync("b")
+	assert.False(t, cm.Informers().Running("a"), "evicting a client stops its informers")
+	assert.True(t, cm.Informers().Running("b"))
+
+	cm.lock.Lock()
+	cm.addClient("b", "b", nil, "file", "", "/kube/b-rotated")
+	cm.lock.Unlock()
+	assert.False(t, cm.Informers().Running("b"), "re-registering a cluster stops its informers")
+}
+
+func TestClusterManager_ReloadStopsInformers(t *testing.T) {
+	cm, _ := newTestClusterManager(t, 1, "a")
+	cm.buildClient = func(source clusterSource, opts ClientOptions) (*Client, error) {
+		discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}, FakedServerVersion: &version.Info{GitVersion: "v1.31.2"}}
+		return &Client{DiscoveryClient: discovery, Config: &rest.Config{Host: "https://" + source.name}}, nil
+	}
+
+	_, err := cm.GetClientByID("a")
+	require.NoError(t, err)
+	require.NoError(t, cm.Informers().Sync("a", fake.NewSimpleClientset(), func(factory informers.SharedInformerFactory) {
+		factory.Core().V1().Pods().Lister()
+	}))
+	require.True(t, cm.Informers().Running("a"))
+
+	_, err = cm.ReloadClient("a")
+	require.NoError(t, err)
+	assert.False(t, cm.Informers().Running("a"), "informers of the replaced client are stopped")
+}
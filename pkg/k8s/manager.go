@@ -1,17 +1,28 @@
 package k8s
 
 import (
+	"bytes"
+	"container/list"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ciliverse/cilikube/configs"
 	"github.com/ciliverse/cilikube/internal/models"
 	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/ciliverse/cilikube/pkg/metrics"
 )
 
+// ErrClusterNotFound is returned when no cluster is registered under an ID
+var ErrClusterNotFound = errors.New("cluster not found")
+
+// ErrNoActiveCluster is returned when the active cluster is needed but none is registered or active
+var ErrNoActiveCluster = errors.New("no active cluster configured")
+
 type ClusterInfoResponse struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
@@ -20,26 +31,92 @@ type ClusterInfoResponse struct {
 	Status      string `json:"status"`
 	Source      string `json:"source"`
 	Environment string `json:"environment"`
+	// CredentialExpiresAt is when the kubeconfig client certificate expires, for clusters authenticating with one
+	CredentialExpiresAt *time.Time `json:"credential_expires_at,omitempty"`
+	Warning             string     `json:"warning,omitempty"` // Set as the client certificate nears or passes its expiry
 }
 
+// defaultMaxCachedClients bounds the live clients kept when kubernetes.max_cached_clients is not configured
+const defaultMaxCachedClients = 20
+
+// idleClusterStatus is reported for registered clusters without a live client
+const idleClusterStatus = "Idle"
+
+// ClusterManager registers clusters and builds their clients lazily on first use.
+// Live clients are kept in an LRU cache, evicted clusters are rebuilt on their next access.
 type ClusterManager struct {
-	clients        map[string]*Client
+	clients        map[string]*list.Element // Values are *cachedClient
+	lru            *list.List               // Most recently used client at the front
+	maxClients     int
+	building       map[string]*clientBuild
+	sources        map[string]clusterSource
 	clientInfo     map[string]store.Cluster
 	nameToID       map[string]string
 	store          store.ClusterStore
 	statusCache    map[string]ClusterInfoResponse
 	lock           sync.RWMutex
 	activeClientID string
-	activeClient   *Client
+	clientOptions  ClientOptions
+	informers      *InformerRegistry
+
+	// buildClient creates the client of a cluster, replaced in tests
+	buildClient func(source clusterSource, opts ClientOptions) (*Client, error)
+}
+
+// clusterSource holds what is needed to build the client of a registered cluster
+type clusterSource struct {
+	name           string
+	source         string // "database" or "file"
+	environment    string
+	configPath     string
+	kubeconfigData []byte
+	qps            float32   // Overrides the manager's client QPS when positive
+	burst          int       // Overrides the manager's client burst when positive
+	certExpiry     time.Time // Expiry of the kubeconfig client certificate, zero without one
+}
+
+// clientOptions returns defaults with the rate limits configured for the cluster
+func (s clusterSource) clientOptions(defaults ClientOptions) ClientOptions {
+	opts := defaults
+	if s.qps > 0 {
+		opts.QPS = s.qps
+	}
+	if s.burst > 0 {
+		opts.Burst = s.burst
+	}
+	return opts
+}
+
+type cachedClient struct {
+	id     string
+	client *Client
+}
+
+// clientBuild lets concurrent callers wait for a client that is being built
+type clientBuild struct {
+	done   chan struct{}
+	client *Client
+	err    error
 }
 
 func NewClusterManager(clusterStore store.ClusterStore, config *configs.Config) (*ClusterManager, error) {
+	maxClients := config.Kubernetes.MaxCachedClients
+	if maxClients <= 0 {
+		maxClients = defaultMaxCachedClients
+	}
 	manager := &ClusterManager{
-		clients:     make(map[string]*Client),
-		clientInfo:  make(map[string]store.Cluster),
-		nameToID:    make(map[string]string),
-		store:       clusterStore,
-		statusCache: make(map[string]ClusterInfoResponse),
+		clients:       make(map[string]*list.Element),
+		lru:           list.New(),
+		maxClients:    maxClients,
+		building:      make(map[string]*clientBuild),
+		sources:       make(map[string]clusterSource),
+		clientInfo:    make(map[string]store.Cluster),
+		nameToID:      make(map[string]string),
+		store:         clusterStore,
+		statusCache:   make(map[string]ClusterInfoResponse),
+		clientOptions: ClientOptionsFromConfig(config.Kubernetes),
+		informers:     NewInformerRegistry(),
+		buildClient:   buildClusterClient,
 	}
 	log.Println("initializing cluster manager...")
 
@@ -50,6 +127,7 @@ func NewClusterManager(clusterStore store.ClusterStore, config *configs.Config)
 		} else {
 			for _, cluster := range dbClusters {
 				manager.addClient(cluster.ID, cluster.Name, cluster.KubeconfigData, "database", cluster.Environment, "")
+				manager.setRateLimits(cluster.ID, cluster.QPS, cluster.Burst)
 				manager.clientInfo[cluster.ID] = cluster
 				manager.nameToID[cluster.Name] = cluster.ID
 			}
@@ -67,7 +145,7 @@ func NewClusterManager(clusterStore store.ClusterStore, config *configs.Config)
 				continue
 			}
 
-			if _, exists := manager.clients[clusterID]; exists {
+			if _, exists := manager.sources[clusterID]; exists {
 				continue
 			}
 			if _, nameExists := manager.nameToID[clusterInfo.Name]; nameExists {
@@ -76,6 +154,7 @@ func NewClusterManager(clusterStore store.ClusterStore, config *configs.Config)
 			}
 
 			manager.addClient(clusterID, clusterInfo.Name, nil, "file", clusterInfo.Environment, clusterInfo.ConfigPath)
+			manager.setRateLimits(clusterID, clusterInfo.QPS, clusterInfo.Burst)
 			manager.clientInfo[clusterID] = store.Cluster{
 				ID:          clusterID,
 				Name:        clusterInfo.Name,
@@ -83,6 +162,7 @@ func NewClusterManager(clusterStore store.ClusterStore, config *configs.Config)
 				Description: clusterInfo.Description,
 				Environment: clusterInfo.Environment,
 				Region:      clusterInfo.Region,
+				Labels:      clusterInfo.Labels,
 			}
 			manager.nameToID[clusterInfo.Name] = clusterID
 		}
@@ -94,45 +174,106 @@ func NewClusterManager(clusterStore store.ClusterStore, config *configs.Config)
 		if err := manager.SetActiveClusterByID(config.Server.ActiveClusterID); err != nil {
 			log.Printf("Warning: Unable to set active cluster ID '%s' from config file as active: %v", config.Server.ActiveClusterID, err)
 		}
-	} else if len(manager.clients) > 0 {
-		for id := range manager.clients {
+	} else if len(manager.sources) > 0 {
+		for id := range manager.sources {
 			if err := manager.SetActiveClusterByID(id); err == nil {
 				break
 			}
 		}
 	}
 
-	log.Printf("Cluster manager initialization completed, loaded %d clients. Active cluster ID: '%s'", len(manager.clients), manager.GetActiveClusterID())
+	log.Printf("Cluster manager initialization completed, registered %d clusters. Active cluster ID: '%s'", len(manager.sources), manager.GetActiveClusterID())
 	return manager, nil
 }
 
+// addClient registers a cluster whose client is built on first use, replacing any previous registration.
+// The caller must hold cm.lock unless the manager is still being constructed.
 func (cm *ClusterManager) addClient(id, name string, kubeconfigData []byte, source, environment string, configPath string) {
-	cm.lock.Lock()
-	defer cm.lock.Unlock()
-	var client *Client
-	var err error
-	if source == "database" {
-		client, err = NewClientFromContent(kubeconfigData)
-	} else if source == "file" {
-		client, err = NewClient(configPath)
-	} else {
-		err = fmt.Errorf("invalid addClient call for ID %s", id)
-	}
-
-	if err != nil {
-		log.Printf("Warning: Failed to create client for cluster '%s' (ID: %s): %v", name, id, err)
-		cm.statusCache[id] = ClusterInfoResponse{ID: id, Name: name, Status: fmt.Sprintf("Initialization failed: %v", err), Source: source}
-		return
+	cm.stopClient(id)
+	delete(cm.building, id)
+	clusterSource := clusterSource{
+		name:           name,
+		source:         source,
+		environment:    environment,
+		configPath:     configPath,
+		kubeconfigData: kubeconfigData,
 	}
-	cm.clients[id] = client
+	clusterSource.certExpiry = clusterSource.credentialExpiry()
+	cm.sources[id] = clusterSource
 	cm.statusCache[id] = ClusterInfoResponse{
 		ID:          id,
 		Name:        name,
-		Server:      client.Config.Host,
-		Status:      "Checking...",
+		Status:      idleClusterStatus,
 		Source:      source,
 		Environment: environment,
 	}
+	cm.applyCredentialExpiry(id)
+}
+
+// setRateLimits sets the client QPS and burst overrides of a registered cluster whose client isn't built yet.
+// The caller must hold cm.lock unless the manager is still being constructed.
+func (cm *ClusterManager) setRateLimits(id string, qps float32, burst int) {
+	source := cm.sources[id]
+	source.qps, source.burst = qps, burst
+	cm.sources[id] = source
+}
+
+// buildClusterClient creates the client of a registered cluster from its kubeconfig
+func buildClusterClient(source clusterSource, opts ClientOptions) (*Client, error) {
+	switch source.source {
+	case "database":
+		return NewClientFromContent(source.kubeconfigData, opts)
+	case "file":
+		return NewClient(source.configPath, opts)
+	default:
+		return nil, fmt.Errorf("invalid cluster source '%s'", source.source)
+	}
+}
+
+// cacheClient stores a live client and evicts the least recently used ones above the cap.
+// The caller must hold cm.lock.
+func (cm *ClusterManager) cacheClient(id string, client *Client) {
+	cm.clients[id] = cm.lru.PushFront(&cachedClient{id: id, client: client})
+	for cm.lru.Len() > cm.maxClients {
+		oldest := cm.lru.Back().Value.(*cachedClient)
+		log.Printf("Evicting idle client of cluster '%s' (ID: %s)", cm.sources[oldest.id].name, oldest.id)
+		cm.stopClient(oldest.id)
+	}
+	metrics.CachedClusterClients.Set(float64(cm.lru.Len()))
+}
+
+// evictClient drops the live client of a cluster, if any. The caller must hold cm.lock.
+func (cm *ClusterManager) evictClient(id string) {
+	elem, ok := cm.clients[id]
+	if !ok {
+		return
+	}
+	cm.lru.Remove(elem)
+	delete(cm.clients, id)
+	if info, ok := cm.statusCache[id]; ok {
+		info.Status = idleClusterStatus
+		cm.statusCache[id] = info
+	}
+	metrics.CachedClusterClients.Set(float64(cm.lru.Len()))
+}
+
+// stopClient evicts the live client of a cluster and stops its informers, which would otherwise keep
+// watching with it. The caller must hold cm.lock.
+func (cm *ClusterManager) stopClient(id string) {
+	cm.evictClient(id)
+	cm.informers.Stop(id)
+}
+
+// Informers returns the registry of the informers shared by the services reading from informer caches
+func (cm *ClusterManager) Informers() *InformerRegistry {
+	return cm.informers
+}
+
+// CachedClientCount returns the number of live clients currently cached
+func (cm *ClusterManager) CachedClientCount() int {
+	cm.lock.RLock()
+	defer cm.lock.RUnlock()
+	return cm.lru.Len()
 }
 
 func (cm *ClusterManager) startStatusUpdater() {
@@ -147,11 +288,12 @@ func (cm *ClusterManager) startStatusUpdater() {
 	}
 }
 
+// RefreshAllClusterStatus checks the clusters with a live client, idle clusters are not connected to
 func (cm *ClusterManager) RefreshAllClusterStatus() {
 	cm.lock.RLock()
 	clientsToUpdate := make(map[string]*Client)
-	for id, client := range cm.clients {
-		clientsToUpdate[id] = client
+	for id, elem := range cm.clients {
+		clientsToUpdate[id] = elem.Value.(*cachedClient).client
 	}
 	cm.lock.RUnlock()
 
@@ -170,12 +312,14 @@ func (cm *ClusterManager) RefreshAllClusterStatus() {
 				version = serverVersion.GitVersion
 			}
 			cm.lock.Lock()
-			cachedInfo := cm.statusCache[id]
-			cachedInfo.Status = status
-			cachedInfo.Version = version
-			cm.statusCache[id] = cachedInfo
+			cachedInfo, ok := cm.statusCache[id]
+			if ok {
+				cachedInfo.Status = status
+				cachedInfo.Version = version
+				cm.statusCache[id] = cachedInfo
+			}
 			cm.lock.Unlock()
-			if cachedInfo.Source == "database" && cm.store != nil {
+			if ok && cachedInfo.Source == "database" && cm.store != nil {
 				dbCluster, err := cm.store.GetClusterByID(id)
 				if err == nil && dbCluster.Version != version {
 					dbCluster.Version = version
@@ -185,6 +329,37 @@ func (cm *ClusterManager) RefreshAllClusterStatus() {
 		}(id, client)
 	}
 	wg.Wait()
+
+	cm.refreshCredentialExpiry()
+}
+
+// refreshCredentialExpiry reads the client certificate of every cluster again, so certificates renewed in
+// place or expiring since the last check are reflected in the cluster status
+func (cm *ClusterManager) refreshCredentialExpiry() {
+	cm.lock.RLock()
+	sources := make(map[string]clusterSource, len(cm.sources))
+	for id, source := range cm.sources {
+		sources[id] = source
+	}
+	cm.lock.RUnlock()
+
+	expiries := make(map[string]time.Time, len(sources))
+	for id, source := range sources {
+		expiries[id] = source.credentialExpiry()
+	}
+
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	for id, expiry := range expiries {
+		source, exists := cm.sources[id]
+		// Skip clusters removed or reconfigured meanwhile
+		if !exists || source.configPath != sources[id].configPath || !bytes.Equal(source.kubeconfigData, sources[id].kubeconfigData) {
+			continue
+		}
+		source.certExpiry = expiry
+		cm.sources[id] = source
+		cm.applyCredentialExpiry(id)
+	}
 }
 
 func (cm *ClusterManager) ListClusterInfo() []ClusterInfoResponse {
@@ -197,6 +372,73 @@ func (cm *ClusterManager) ListClusterInfo() []ClusterInfoResponse {
 	return list
 }
 
+// ClusterFilter selects registered clusters by their metadata. Empty fields match every cluster,
+// all labels must be present with the given values.
+type ClusterFilter struct {
+	Labels      map[string]string
+	Provider    string
+	Environment string
+	Region      string
+}
+
+// Matches reports whether a cluster satisfies the filter. Provider, environment and region
+// are compared case-insensitively, label values exactly.
+func (f ClusterFilter) Matches(cluster store.Cluster) bool {
+	if f.Provider != "" && !strings.EqualFold(cluster.Provider, f.Provider) {
+		return false
+	}
+	if f.Environment != "" && !strings.EqualFold(cluster.Environment, f.Environment) {
+		return false
+	}
+	if f.Region != "" && !strings.EqualFold(cluster.Region, f.Region) {
+		return false
+	}
+	for key, value := range f.Labels {
+		if got, ok := cluster.Labels[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLabelSelectors parses "key=value" selectors into a label map. A selector may hold several
+// comma-separated pairs, as in "env=prod,team=core".
+func ParseLabelSelectors(selectors []string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, selector := range selectors {
+		for _, pair := range strings.Split(selector, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(pair, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid label selector '%s', expected key=value", pair)
+			}
+			value = strings.TrimSpace(value)
+			if existing, dup := labels[key]; dup && existing != value {
+				return nil, fmt.Errorf("label '%s' is selected with conflicting values '%s' and '%s'", key, existing, value)
+			}
+			labels[key] = value
+		}
+	}
+	return labels, nil
+}
+
+// ListClusterInfoFiltered returns the clusters whose metadata matches the filter
+func (cm *ClusterManager) ListClusterInfoFiltered(filter ClusterFilter) []ClusterInfoResponse {
+	cm.lock.RLock()
+	defer cm.lock.RUnlock()
+	var list []ClusterInfoResponse
+	for id, info := range cm.statusCache {
+		if filter.Matches(cm.clientInfo[id]) {
+			list = append(list, info)
+		}
+	}
+	return list
+}
+
 func (cm *ClusterManager) AddDBCluster(cluster *store.Cluster) error {
 	cm.lock.Lock()
 	defer cm.lock.Unlock()
@@ -211,9 +453,9 @@ func (cm *ClusterManager) AddDBCluster(cluster *store.Cluster) error {
 	}
 	// Use "database" as source even for memory store to distinguish from file-based clusters
 	cm.addClient(cluster.ID, cluster.Name, cluster.KubeconfigData, "database", cluster.Environment, "")
+	cm.setRateLimits(cluster.ID, cluster.QPS, cluster.Burst)
 	cm.clientInfo[cluster.ID] = *cluster
 	cm.nameToID[cluster.Name] = cluster.ID
-	go cm.RefreshAllClusterStatus()
 	return nil
 }
 
@@ -233,15 +475,16 @@ func (cm *ClusterManager) RemoveDBClusterByID(id string) error {
 	if err := cm.store.DeleteClusterByID(id); err != nil {
 		return fmt.Errorf("failed to delete cluster '%s' (ID: %s): %w", clientInfo.Name, id, err)
 	}
-	delete(cm.clients, id)
+	cm.stopClient(id)
+	delete(cm.building, id)
+	delete(cm.sources, id)
 	delete(cm.statusCache, id)
 	delete(cm.clientInfo, id)
 	delete(cm.nameToID, clientInfo.Name)
 	if cm.activeClientID == id {
-		cm.activeClient = nil
 		cm.activeClientID = ""
-		for newActiveID := range cm.clients {
-			_ = cm.SetActiveClusterByID(newActiveID)
+		for newActiveID := range cm.sources {
+			cm.setActiveCluster(newActiveID)
 			break
 		}
 	}
@@ -251,23 +494,25 @@ func (cm *ClusterManager) RemoveDBClusterByID(id string) error {
 func (cm *ClusterManager) SetActiveClusterByID(id string) error {
 	cm.lock.Lock()
 	defer cm.lock.Unlock()
-	client, exists := cm.clients[id]
-	if !exists {
+	if _, exists := cm.sources[id]; !exists {
 		return fmt.Errorf("cluster ID '%s' not found or not initialized", id)
 	}
-	cm.activeClient = client
+	cm.setActiveCluster(id)
+	return nil
+}
+
+// setActiveCluster marks a registered cluster as active. The caller must hold cm.lock.
+func (cm *ClusterManager) setActiveCluster(id string) {
 	cm.activeClientID = id
 	log.Printf("Active cluster set to ID: %s (name: %s)", id, cm.clientInfo[id].Name)
-	return nil
 }
 
 func (cm *ClusterManager) GetActiveClient() (*Client, error) {
-	cm.lock.RLock()
-	defer cm.lock.RUnlock()
-	if cm.activeClient == nil {
-		return nil, fmt.Errorf("no active cluster currently configured or available")
+	id := cm.GetActiveClusterID()
+	if id == "" {
+		return nil, ErrNoActiveCluster
 	}
-	return cm.activeClient, nil
+	return cm.GetClientByID(id)
 }
 
 func (cm *ClusterManager) GetActiveClusterID() string {
@@ -276,14 +521,125 @@ func (cm *ClusterManager) GetActiveClusterID() string {
 	return cm.activeClientID
 }
 
-func (cm *ClusterManager) GetClientByID(id string) (*Client, error) {
+// HasCluster reports whether a cluster with the given ID is registered
+func (cm *ClusterManager) HasCluster(id string) bool {
 	cm.lock.RLock()
 	defer cm.lock.RUnlock()
-	client, exists := cm.clients[id]
+	_, exists := cm.sources[id]
+	return exists
+}
+
+// GetClientByID returns the client of a cluster, building it on first use.
+// Concurrent callers for a cluster that is being built wait for the same client.
+func (cm *ClusterManager) GetClientByID(id string) (*Client, error) {
+	cm.lock.Lock()
+	// Requests with an expired client certificate would only fail with TLS errors
+	if source, exists := cm.sources[id]; exists && source.credentialExpired() {
+		cm.applyCredentialExpiry(id)
+		cm.lock.Unlock()
+		return nil, credentialExpiredError(id, source)
+	}
+	if elem, ok := cm.clients[id]; ok {
+		cm.lru.MoveToFront(elem)
+		client := elem.Value.(*cachedClient).client
+		cm.lock.Unlock()
+		return client, nil
+	}
+	source, exists := cm.sources[id]
 	if !exists {
-		return nil, fmt.Errorf("client with ID '%s' not found in memory", id)
+		cm.lock.Unlock()
+		return nil, fmt.Errorf("%w: client with ID '%s' not found in memory", ErrClusterNotFound, id)
 	}
-	return client, nil
+	if build, inProgress := cm.building[id]; inProgress {
+		cm.lock.Unlock()
+		<-build.done
+		return build.client, build.err
+	}
+	build := &clientBuild{done: make(chan struct{})}
+	cm.building[id] = build
+	cm.lock.Unlock()
+
+	build.client, build.err = cm.buildClient(source, source.clientOptions(cm.clientOptions))
+
+	cm.lock.Lock()
+	// The cluster may have been removed or reconfigured while its client was built
+	if cm.building[id] == build {
+		delete(cm.building, id)
+		info := cm.statusCache[id]
+		if build.err != nil {
+			log.Printf("Warning: Failed to create client for cluster '%s' (ID: %s): %v", source.name, id, build.err)
+			info.Status = fmt.Sprintf("Initialization failed: %v", build.err)
+		} else {
+			cm.cacheClient(id, build.client)
+			info.Server = build.client.Config.Host
+			info.Status = "Available"
+		}
+		cm.statusCache[id] = info
+	}
+	cm.lock.Unlock()
+	close(build.done)
+
+	return build.client, build.err
+}
+
+// ErrClusterValidation is returned when a reloaded client cannot reach its API server
+var ErrClusterValidation = errors.New("cluster validation failed")
+
+// ReloadClient rebuilds the client of a cluster from its current kubeconfig, read again from the database
+// or the file, for instance after its credentials were rotated. The new client replaces the cached one
+// only once a discovery call succeeds, and the informers of the previous client are stopped; on failure
+// the previous client is kept.
+func (cm *ClusterManager) ReloadClient(id string) (ClusterInfoResponse, error) {
+	cm.lock.RLock()
+	source, exists := cm.sources[id]
+	cm.lock.RUnlock()
+	if !exists {
+		return ClusterInfoResponse{}, fmt.Errorf("%w: client with ID '%s' not found in memory", ErrClusterNotFound, id)
+	}
+	if source.source == "database" && cm.store != nil {
+		cluster, err := cm.store.GetClusterByID(id)
+		if err != nil {
+			return ClusterInfoResponse{}, fmt.Errorf("failed to load cluster '%s' (ID: %s): %w", source.name, id, err)
+		}
+		source.kubeconfigData = cluster.KubeconfigData
+		source.qps, source.burst = cluster.QPS, cluster.Burst
+	}
+
+	source.certExpiry = source.credentialExpiry()
+	if source.credentialExpired() {
+		return ClusterInfoResponse{}, fmt.Errorf("%w: %w", ErrClusterValidation, credentialExpiredError(id, source))
+	}
+
+	client, err := cm.buildClient(source, source.clientOptions(cm.clientOptions))
+	if err != nil {
+		return ClusterInfoResponse{}, fmt.Errorf("%w: %v", ErrClusterValidation, err)
+	}
+	serverVersion, err := client.DiscoveryClient.ServerVersion()
+	if err != nil {
+		return ClusterInfoResponse{}, fmt.Errorf("%w: %v", ErrClusterValidation, err)
+	}
+
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	// The cluster may have been removed while its client was rebuilt
+	if _, exists := cm.sources[id]; !exists {
+		return ClusterInfoResponse{}, fmt.Errorf("%w: client with ID '%s' not found in memory", ErrClusterNotFound, id)
+	}
+	cm.sources[id] = source
+	cm.stopClient(id)
+	// A build started before the reload would use the old kubeconfig, so its client is not cached
+	delete(cm.building, id)
+	cm.cacheClient(id, client)
+
+	info := cm.statusCache[id]
+	info.Server = client.Config.Host
+	info.Version = serverVersion.GitVersion
+	info.Status = "Available"
+	cm.statusCache[id] = info
+	cm.applyCredentialExpiry(id)
+	info = cm.statusCache[id]
+	log.Printf("Reloaded client of cluster '%s' (ID: %s), server version %s", source.name, id, info.Version)
+	return info, nil
 }
 
 func (cm *ClusterManager) GetClusterDetailFromDB(id string) (*store.Cluster, error) {
@@ -304,10 +660,16 @@ func (cm *ClusterManager) UpdateDBCluster(id string, req models.UpdateClusterReq
 		return fmt.Errorf("cluster ID '%s' not found: %w", id, err)
 	}
 	oldName := cluster.Name
-	kubeconfigUpdated := false
+	kubeconfigUpdated, rateLimitsUpdated := false, false
 	if req.Name != "" {
 		cluster.Name = req.Name
 	}
+	if req.QPS != nil && *req.QPS != cluster.QPS {
+		cluster.QPS, rateLimitsUpdated = *req.QPS, true
+	}
+	if req.Burst != nil && *req.Burst != cluster.Burst {
+		cluster.Burst, rateLimitsUpdated = *req.Burst, true
+	}
 	// ... other field updates ...
 	if req.KubeconfigData != "" {
 		kubeconfigBytes, err := base64.StdEncoding.DecodeString(req.KubeconfigData)
@@ -325,11 +687,10 @@ func (cm *ClusterManager) UpdateDBCluster(id string, req models.UpdateClusterReq
 		delete(cm.nameToID, oldName)
 		cm.nameToID[cluster.Name] = id
 	}
-	if kubeconfigUpdated {
-		delete(cm.clients, id)
-		delete(cm.statusCache, id)
+	// The client is built again with the new kubeconfig or rate limits on its next use
+	if kubeconfigUpdated || rateLimitsUpdated {
 		cm.addClient(id, cluster.Name, cluster.KubeconfigData, "database", cluster.Environment, "")
-		go cm.RefreshAllClusterStatus()
+		cm.setRateLimits(id, cluster.QPS, cluster.Burst)
 	}
 	return nil
 }
@@ -0,0 +1,308 @@
+package k8s
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/ciliverse/cilikube/internal/models"
+	"github.com/ciliverse/cilikube/internal/store"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestClusterManager registers file clusters with the given IDs and counts the clients it builds
+func newTestClusterManager(t *testing.T, maxClients int, ids ...string) (*ClusterManager, *int32) {
+	t.Helper()
+	cm, err := NewClusterManager(nil, &configs.Config{Kubernetes: configs.KubernetesConfig{MaxCachedClients: maxClients}})
+	require.NoError(t, err)
+
+	var builds int32
+	cm.buildClient = func(source clusterSource, opts ClientOptions) (*Client, error) {
+		atomic.AddInt32(&builds, 1)
+		time.Sleep(10 * time.Millisecond)
+		if source.configPath == "broken" {
+			return nil, errors.New("connection refused")
+		}
+		return &Client{Config: &rest.Config{Host: "https://" + source.name}}, nil
+	}
+
+	cm.lock.Lock()
+	for _, id := range ids {
+		cm.addClient(id, id, nil, "file", "", "/kube/"+id)
+	}
+	cm.lock.Unlock()
+	return cm, &builds
+}
+
+func TestClusterManager_LazyClientCreation(t *testing.T) {
+	cm, builds := newTestClusterManager(t, 5, "a", "b")
+	require.NoError(t, cm.SetActiveClusterByID("a"))
+	assert.Equal(t, int32(0), atomic.LoadInt32(builds), "registering clusters does not build clients")
+	assert.Equal(t, 0, cm.CachedClientCount())
+	info, _ := cm.GetStatusFromCache("a")
+	assert.Equal(t, "Idle", info.Status)
+
+	client, err := cm.GetActiveClient()
+	require.NoError(t, err)
+	assert.Equal(t, "https://a", client.Config.Host)
+	again, err := cm.GetClientByID("a")
+	require.NoError(t, err)
+	assert.Same(t, client, again)
+	assert.Equal(t, int32(1), atomic.LoadInt32(builds))
+	assert.Equal(t, 1, cm.CachedClientCount())
+	info, _ = cm.GetStatusFromCache("a")
+	assert.Equal(t, "https://a", info.Server)
+
+	_, err = cm.GetClientByID("missing")
+	assert.Error(t, err)
+}
+
+func TestClusterManager_ConcurrentAccessBuildsOnce(t *testing.T) {
+	cm, builds := newTestClusterManager(t, 5, "a")
+
+	clients := make([]*Client, 10)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := cm.GetClientByID("a")
+			assert.NoError(t, err)
+			clients[i] = client
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(builds))
+	for _, client := range clients {
+		assert.Same(t, clients[0], client)
+	}
+}
+
+func TestClusterManager_EvictsLeastRecentlyUsed(t *testing.T) {
+	cm, builds := newTestClusterManager(t, 2, "a", "b", "c")
+
+	for _, id := range []string{"a", "b", "a", "c"} {
+		_, err := cm.GetClientByID(id)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(builds))
+	assert.Equal(t, 2, cm.CachedClientCount())
+	info, _ := cm.GetStatusFromCache("b")
+	assert.Equal(t, "Idle", info.Status, "b was the least recently used client")
+
+	// b is rebuilt, evicting a
+	_, err := cm.GetClientByID("b")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(builds))
+	_, err = cm.GetClientByID("c")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(builds), "c is still cached")
+	assert.Equal(t, 2, cm.CachedClientCount())
+}
+
+func TestClusterManager_BuildFailure(t *testing.T) {
+	cm, builds := newTestClusterManager(t, 2)
+	cm.lock.Lock()
+	cm.addClient("x", "x", nil, "file", "", "broken")
+	cm.lock.Unlock()
+
+	_, err := cm.GetClientByID("x")
+	require.Error(t, err)
+	info, _ := cm.GetStatusFromCache("x")
+	assert.Equal(t, "Initialization failed: connection refused", info.Status)
+	assert.Equal(t, 0, cm.CachedClientCount())
+
+	_, err = cm.GetClientByID("x")
+	require.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(builds), "failed clients are retried on the next access")
+}
+
+func TestClusterManager_ClusterRateLimits(t *testing.T) {
+	cm, err := NewClusterManager(nil, &configs.Config{
+		Kubernetes: configs.KubernetesConfig{QPS: 20, Burst: 40},
+		Clusters: []configs.ClusterInfo{
+			{ID: "busy", Name: "busy", ConfigPath: "/kube/busy", QPS: 200, Burst: 400},
+			{ID: "quiet", Name: "quiet", ConfigPath: "/kube/quiet"},
+		},
+	})
+	require.NoError(t, err)
+	cm.buildClient = func(source clusterSource, opts ClientOptions) (*Client, error) {
+		config := &rest.Config{Host: "https://" + source.name}
+		opts.apply(config)
+		return &Client{Config: config}, nil
+	}
+
+	busy, err := cm.GetClientByID("busy")
+	require.NoError(t, err)
+	assert.Equal(t, float32(200), busy.Config.QPS)
+	assert.Equal(t, 400, busy.Config.Burst)
+
+	quiet, err := cm.GetClientByID("quiet")
+	require.NoError(t, err)
+	assert.Equal(t, float32(20), quiet.Config.QPS, "clusters without limits use kubernetes.qps")
+	assert.Equal(t, 40, quiet.Config.Burst)
+}
+
+func TestClusterManager_DatabaseClusterRateLimits(t *testing.T) {
+	clusterStore := store.NewMemoryStore()
+	require.NoError(t, clusterStore.Initialize())
+	cluster := &store.Cluster{Name: "db", KubeconfigData: []byte("kubeconfig"), QPS: 200, Burst: 400}
+	require.NoError(t, clusterStore.CreateCluster(cluster))
+	cm, err := NewClusterManager(clusterStore, &configs.Config{Kubernetes: configs.KubernetesConfig{QPS: 20, Burst: 40}})
+	require.NoError(t, err)
+	cm.buildClient = func(source clusterSource, opts ClientOptions) (*Client, error) {
+		config := &rest.Config{Host: "https://" + source.name}
+		opts.apply(config)
+		return &Client{Config: config}, nil
+	}
+
+	client, err := cm.GetClientByID(cluster.ID)
+	require.NoError(t, err)
+	assert.Equal(t, float32(200), client.Config.QPS)
+	assert.Equal(t, 400, client.Config.Burst)
+
+	qps, burst := float32(0), 80
+	require.NoError(t, cm.UpdateDBCluster(cluster.ID, models.UpdateClusterRequest{QPS: &qps, Burst: &burst}))
+	client, err = cm.GetClientByID(cluster.ID)
+	require.NoError(t, err)
+	assert.Equal(t, float32(20), client.Config.QPS, "a QPS of 0 restores kubernetes.qps")
+	assert.Equal(t, 80, client.Config.Burst, "updated limits apply to the rebuilt client")
+	stored, err := clusterStore.GetClusterByID(cluster.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 80, stored.Burst)
+}
+
+func TestClusterManager_ReloadClient(t *testing.T) {
+	clusterStore := store.NewMemoryStore()
+	require.NoError(t, clusterStore.Initialize())
+	cluster := &store.Cluster{Name: "db", KubeconfigData: []byte("old-cert")}
+	require.NoError(t, clusterStore.CreateCluster(cluster))
+	cm, err := NewClusterManager(clusterStore, &configs.Config{})
+	require.NoError(t, err)
+
+	// The built client reports the kubeconfig it was built from as its host
+	var validationErr error
+	cm.buildClient = func(source clusterSource, opts ClientOptions) (*Client, error) {
+		discovery := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}, FakedServerVersion: &version.Info{GitVersion: "v1.31.2"}}
+		discovery.AddReactor("get", "version", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return validationErr != nil, nil, validationErr
+		})
+		return &Client{DiscoveryClient: discovery, Config: &rest.Config{Host: string(source.kubeconfigData)}}, nil
+	}
+
+	old, err := cm.GetClientByID(cluster.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "old-cert", old.Config.Host)
+
+	// The credentials are rotated
+	cluster.KubeconfigData = []byte("new-cert")
+	require.NoError(t, clusterStore.UpdateCluster(cluster))
+
+	validationErr = errors.New("x509: certificate has expired")
+	_, err = cm.ReloadClient(cluster.ID)
+	require.ErrorIs(t, err, ErrClusterValidation)
+	current, err := cm.GetClientByID(cluster.ID)
+	require.NoError(t, err)
+	assert.Same(t, old, current, "a client failing validation does not replace the cached one")
+
+	validationErr = nil
+	info, err := cm.ReloadClient(cluster.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.31.2", info.Version)
+	assert.Equal(t, "new-cert", info.Server)
+	assert.Equal(t, "Available", info.Status)
+	current, err = cm.GetClientByID(cluster.ID)
+	require.NoError(t, err)
+	assert.NotSame(t, old, current)
+	assert.Equal(t, "new-cert", current.Config.Host)
+
+	_, err = cm.ReloadClient("missing")
+	assert.ErrorIs(t, err, ErrClusterNotFound)
+}
+
+func TestClusterManager_ListClusterInfoFiltered(t *testing.T) {
+	cm, _ := newTestClusterManager(t, 5, "prod-aws", "staging-aws", "prod-gcp")
+	cm.lock.Lock()
+	cm.clientInfo["prod-aws"] = store.Cluster{ID: "prod-aws", Provider: "aws", Environment: "production", Region: "us-east-1",
+		Labels: store.Labels{"env": "prod", "team": "core"}}
+	cm.clientInfo["staging-aws"] = store.Cluster{ID: "staging-aws", Provider: "aws", Environment: "staging", Region: "us-east-1",
+		Labels: store.Labels{"env": "staging", "team": "core"}}
+	cm.clientInfo["prod-gcp"] = store.Cluster{ID: "prod-gcp", Provider: "gcp", Environment: "production", Region: "europe-west1",
+		Labels: store.Labels{"env": "prod"}}
+	cm.lock.Unlock()
+
+	ids := func(filter ClusterFilter) []string {
+		var result []string
+		for _, info := range cm.ListClusterInfoFiltered(filter) {
+			result = append(result, info.ID)
+		}
+		return result
+	}
+
+	assert.ElementsMatch(t, []string{"prod-aws", "staging-aws", "prod-gcp"}, ids(ClusterFilter{}))
+	assert.ElementsMatch(t, []string{"prod-aws", "prod-gcp"}, ids(ClusterFilter{Labels: map[string]string{"env": "prod"}}))
+	assert.ElementsMatch(t, []string{"prod-aws", "staging-aws"}, ids(ClusterFilter{Provider: "AWS"}))
+	assert.ElementsMatch(t, []string{"staging-aws"}, ids(ClusterFilter{Environment: "staging"}))
+	assert.ElementsMatch(t, []string{"prod-gcp"}, ids(ClusterFilter{Region: "europe-west1"}))
+
+	assert.ElementsMatch(t, []string{"prod-aws"}, ids(ClusterFilter{
+		Labels:   map[string]string{"env": "prod", "team": "core"},
+		Provider: "aws",
+	}))
+	assert.Empty(t, ids(ClusterFilter{Labels: map[string]string{"env": "prod"}, Environment: "staging"}))
+	assert.Empty(t, ids(ClusterFilter{Labels: map[string]string{"missing": ""}}))
+}
+
+func TestParseLabelSelectors(t *testing.T) {
+	labels, err := ParseLabelSelectors([]string{"env=prod", "team=core, tier = web"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "core", "tier": "web"}, labels)
+
+	labels, err = ParseLabelSelectors(nil)
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+
+	_, err = ParseLabelSelectors([]string{"env"})
+	assert.Error(t, err)
+	_, err = ParseLabelSelectors([]string{"=prod"})
+	assert.Error(t, err)
+	_, err = ParseLabelSelectors([]string{"env=prod", "env=staging"})
+	assert.Error(t, err)
+}
+
+func TestGetClientFromQuery_NoActiveCluster(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cm, _ := newTestClusterManager(t, 0)
+
+	_, err := cm.GetActiveClient()
+	assert.ErrorIs(t, err, ErrNoActiveCluster)
+
+	router := gin.New()
+	router.GET("/nodes", func(c *gin.Context) {
+		if _, ok := GetClientFromQuery(c, cm); ok {
+			c.Status(http.StatusOK)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/nodes", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"errorCode":"NO_ACTIVE_CLUSTER"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/nodes?clusterId=missing", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "an explicit unknown cluster is still not found")
+}
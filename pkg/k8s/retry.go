@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Retry settings for idempotent requests that fail with a transient error
+const (
+	defaultMaxRetries   = 2
+	defaultRetryBackoff = 200 * time.Millisecond
+)
+
+// retryTransport retries GET and HEAD requests that fail with a transient error.
+// Other methods are passed through unchanged since they may not be safe to repeat.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	backoff    time.Duration
+}
+
+func newRetryTransport(next http.RoundTripper) http.RoundTripper {
+	return &retryTransport{next: next, maxRetries: defaultMaxRetries, backoff: defaultRetryBackoff}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.next.RoundTrip(req)
+	}
+
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.maxRetries || req.Context().Err() != nil || !isTransient(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		backoff *= 2
+	}
+}
+
+// WrappedRoundTripper lets client-go inspect the wrapped transport
+func (t *retryTransport) WrappedRoundTripper() http.RoundTripper {
+	return t.next
+}
+
+// isTransient reports whether a request failed in a way that is worth retrying
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
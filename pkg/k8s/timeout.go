@@ -0,0 +1,76 @@
+package k8s
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// timeoutTransport bounds each non-streaming API request, including its retries, by a deadline.
+// rest.Config.Timeout would do the same for every request and cut off log follows, watches and
+// exec, attach and port-forward sessions, so it is left at zero.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func newTimeoutTransport(timeout time.Duration) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &timeoutTransport{next: next, timeout: timeout}
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 || isStreamingRequest(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The deadline also covers reading the body, it is released once the caller closes it
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// WrappedRoundTripper lets client-go inspect the wrapped transport
+func (t *timeoutTransport) WrappedRoundTripper() http.RoundTripper {
+	return t.next
+}
+
+// isStreamingRequest reports whether a request keeps its connection open for as long as the caller reads:
+// watches, followed logs, connection upgrades and the exec, attach, port-forward and proxy subresources
+func isStreamingRequest(req *http.Request) bool {
+	query := req.URL.Query()
+	if query.Get("watch") == "true" || query.Get("watch") == "1" || query.Get("follow") == "true" {
+		return true
+	}
+	if req.Header.Get("Upgrade") != "" {
+		return true
+	}
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	for _, subresource := range []string{"/exec", "/attach", "/portforward", "/proxy"} {
+		if strings.HasSuffix(path, subresource) || strings.Contains(path, subresource+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelOnClose cancels the request context when the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -25,10 +25,17 @@ var (
 		},
 		[]string{"method", "path"},
 	)
+
+	CachedClusterClients = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "cluster_clients_cached",
+			Help: "Number of live Kubernetes cluster clients held by the cluster manager",
+		},
+	)
 )
 
 func init() {
-	prometheus.MustRegister(RequestCounter, RequestDuration)
+	prometheus.MustRegister(RequestCounter, RequestDuration, CachedClusterClients)
 }
 
 func PromMiddleware() gin.HandlerFunc {
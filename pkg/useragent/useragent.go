@@ -0,0 +1,63 @@
+// Package useragent extracts the browser, operating system and device type from User-Agent
+// headers, so sessions can be shown as devices people recognize. Parsing is done by
+// github.com/mileusna/useragent.
+package useragent
+
+import (
+	"strings"
+
+	"github.com/mileusna/useragent"
+)
+
+// Device types
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceOther   = "other" // Command line tools and API clients
+	DeviceUnknown = "unknown"
+)
+
+// Unknown is reported for a browser or operating system that is not recognized
+const Unknown = "Unknown"
+
+// Info is what a User-Agent header says about the client
+type Info struct {
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+	OS             string `json:"os"`
+	OSVersion      string `json:"os_version,omitempty"`
+	DeviceType     string `json:"device_type"`
+}
+
+// Parse extracts the client information from a User-Agent header. Parts that can't be recognized
+// are reported as Unknown rather than failing.
+func Parse(userAgent string) Info {
+	info := Info{Browser: Unknown, OS: Unknown, DeviceType: DeviceUnknown}
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return info
+	}
+
+	ua := useragent.Parse(userAgent)
+	if ua.Name != "" {
+		info.Browser, info.BrowserVersion = ua.Name, ua.Version
+	}
+	if ua.OS != "" {
+		info.OS, info.OSVersion = ua.OS, ua.OSVersion
+	}
+	switch {
+	case ua.Bot:
+		info.DeviceType = DeviceBot
+	case ua.Tablet:
+		info.DeviceType = DeviceTablet
+	case ua.Mobile:
+		info.DeviceType = DeviceMobile
+	case ua.Desktop:
+		info.DeviceType = DeviceDesktop
+	case ua.Name != "":
+		info.DeviceType = DeviceOther
+	}
+	return info
+}
@@ -0,0 +1,71 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      Info
+	}{
+		{
+			name:      "chrome on windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want:      Info{Browser: "Chrome", BrowserVersion: "124.0.0.0", OS: "Windows", OSVersion: "10.0", DeviceType: DeviceDesktop},
+		},
+		{
+			name:      "edge on windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			want:      Info{Browser: "Edge", BrowserVersion: "124.0.2478.51", OS: "Windows", OSVersion: "10.0", DeviceType: DeviceDesktop},
+		},
+		{
+			name:      "safari on macos",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+			want:      Info{Browser: "Safari", BrowserVersion: "17.4", OS: "macOS", OSVersion: "10.15.7", DeviceType: DeviceDesktop},
+		},
+		{
+			name:      "firefox on linux",
+			userAgent: "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			want:      Info{Browser: "Firefox", BrowserVersion: "125.0", OS: "Linux", OSVersion: "x86_64", DeviceType: DeviceDesktop},
+		},
+		{
+			name:      "safari on iphone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			want:      Info{Browser: "Safari", BrowserVersion: "17.4", OS: "iOS", OSVersion: "17.4.1", DeviceType: DeviceMobile},
+		},
+		{
+			name:      "chrome on ipad",
+			userAgent: "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1",
+			want:      Info{Browser: "Chrome", BrowserVersion: "124.0.6367.88", OS: "iOS", OSVersion: "16.6", DeviceType: DeviceTablet},
+		},
+		{
+			name:      "chrome on android phone",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.6367.82 Mobile Safari/537.36",
+			want:      Info{Browser: "Chrome", BrowserVersion: "124.0.6367.82", OS: "Android", OSVersion: "14", DeviceType: DeviceMobile},
+		},
+		{
+			name:      "crawler",
+			userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want:      Info{Browser: "Googlebot", BrowserVersion: "2.1", OS: Unknown, DeviceType: DeviceBot},
+		},
+		{
+			name:      "curl",
+			userAgent: "curl/8.5.0",
+			want:      Info{Browser: "curl", BrowserVersion: "8.5.0", OS: Unknown, DeviceType: DeviceOther},
+		},
+		{
+			name: "empty",
+			want: Info{Browser: Unknown, OS: Unknown, DeviceType: DeviceUnknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse(tt.userAgent))
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// ErrorCode is a machine-readable error code returned with every error response, so clients can
+// branch on it instead of parsing messages
+type ErrorCode string
+
+// Error codes of API error responses
+const (
+	ErrCodeBadRequest               ErrorCode = "BAD_REQUEST"
+	ErrCodeValidationFailed         ErrorCode = "VALIDATION_FAILED"
+	ErrCodeUnauthorized             ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden                ErrorCode = "FORBIDDEN"
+	ErrCodePasswordExpired          ErrorCode = "PASSWORD_EXPIRED"
+	ErrCodeNotFound                 ErrorCode = "NOT_FOUND"
+	ErrCodeAlreadyExists            ErrorCode = "ALREADY_EXISTS"
+	ErrCodeConflict                 ErrorCode = "CONFLICT"
+	ErrCodeGone                     ErrorCode = "GONE"
+	ErrCodePreconditionRequired     ErrorCode = "PRECONDITION_REQUIRED"
+	ErrCodeRequestTooLarge          ErrorCode = "REQUEST_TOO_LARGE"
+	ErrCodeTooManyRequests          ErrorCode = "TOO_MANY_REQUESTS"
+	ErrCodeNotImplemented           ErrorCode = "NOT_IMPLEMENTED"
+	ErrCodeServiceUnavailable       ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrCodeClusterUnreachable       ErrorCode = "CLUSTER_UNREACHABLE"
+	ErrCodeClusterCredentialExpired ErrorCode = "CLUSTER_CREDENTIAL_EXPIRED"
+	ErrCodeNoActiveCluster          ErrorCode = "NO_ACTIVE_CLUSTER"
+	ErrCodeTimeout                  ErrorCode = "TIMEOUT"
+	ErrCodeInternal                 ErrorCode = "INTERNAL_ERROR"
+)
+
+// APIError is an error response: the HTTP status, a machine-readable code, a human message and
+// optional details
+type APIError struct {
+	Status  int               `json:"-"`
+	Code    ErrorCode         `json:"errorCode"`
+	Message string            `json:"message"`
+	Details string            `json:"details"`
+	Fields  map[string]string `json:"errors,omitempty"` // Message of each invalid request field, by its JSON name
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Details == "" {
+		return e.Message
+	}
+	return e.Message + ": " + e.Details
+}
+
+// NewAPIError creates an APIError
+func NewAPIError(status int, code ErrorCode, message, details string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message, Details: details}
+}
+
+// ErrorCodeForStatus returns the default error code of an HTTP status
+func ErrorCodeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrCodeBadRequest
+	case http.StatusUnauthorized:
+		return ErrCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrCodeForbidden
+	case http.StatusNotFound:
+		return ErrCodeNotFound
+	case http.StatusConflict:
+		return ErrCodeConflict
+	case http.StatusGone:
+		return ErrCodeGone
+	case http.StatusRequestEntityTooLarge:
+		return ErrCodeRequestTooLarge
+	case http.StatusPreconditionRequired:
+		return ErrCodePreconditionRequired
+	case http.StatusUnprocessableEntity:
+		return ErrCodeValidationFailed
+	case http.StatusTooManyRequests:
+		return ErrCodeTooManyRequests
+	case http.StatusNotImplemented:
+		return ErrCodeNotImplemented
+	case http.StatusServiceUnavailable:
+		return ErrCodeServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrCodeTimeout
+	}
+	if status >= http.StatusInternalServerError {
+		return ErrCodeInternal
+	}
+	return ErrCodeBadRequest
+}
+
+// KubernetesAPIError maps an error returned by the Kubernetes API or its client to the matching
+// HTTP status and code. Errors reaching the cluster at all are reported as CLUSTER_UNREACHABLE.
+func KubernetesAPIError(message string, err error) *APIError {
+	if err == nil {
+		return NewAPIError(http.StatusInternalServerError, ErrCodeInternal, message, "")
+	}
+	status, code := kubernetesErrorStatus(err)
+	return NewAPIError(status, code, message, err.Error())
+}
+
+// kubernetesErrorStatus returns the HTTP status and code matching a Kubernetes client error
+func kubernetesErrorStatus(err error) (int, ErrorCode) {
+	switch {
+	case meta.IsNoMatchError(err), k8serrors.IsNotFound(err):
+		return http.StatusNotFound, ErrCodeNotFound
+	case k8serrors.IsAlreadyExists(err):
+		return http.StatusConflict, ErrCodeAlreadyExists
+	case k8serrors.IsConflict(err):
+		return http.StatusConflict, ErrCodeConflict
+	case k8serrors.IsInvalid(err):
+		return http.StatusBadRequest, ErrCodeValidationFailed
+	case k8serrors.IsBadRequest(err):
+		return http.StatusBadRequest, ErrCodeBadRequest
+	case k8serrors.IsUnauthorized(err):
+		return http.StatusUnauthorized, ErrCodeUnauthorized
+	case k8serrors.IsForbidden(err):
+		return http.StatusForbidden, ErrCodeForbidden
+	case k8serrors.IsGone(err), k8serrors.IsResourceExpired(err):
+		return http.StatusGone, ErrCodeGone
+	case k8serrors.IsRequestEntityTooLargeError(err):
+		return http.StatusRequestEntityTooLarge, ErrCodeRequestTooLarge
+	case k8serrors.IsTooManyRequests(err):
+		return http.StatusTooManyRequests, ErrCodeTooManyRequests
+	case k8serrors.IsTimeout(err), k8serrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, ErrCodeTimeout
+	case k8serrors.IsServiceUnavailable(err):
+		return http.StatusServiceUnavailable, ErrCodeServiceUnavailable
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return http.StatusGatewayTimeout, ErrCodeTimeout
+		}
+		return http.StatusServiceUnavailable, ErrCodeClusterUnreachable
+	}
+	return http.StatusInternalServerError, ErrCodeInternal
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestKubernetesAPIError(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	refused := &url.Error{Op: "Get", URL: "https://10.0.0.1:6443/api", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   ErrorCode
+	}{
+		{"not found", k8serrors.NewNotFound(pods, "web"), http.StatusNotFound, ErrCodeNotFound},
+		{"conflict", k8serrors.NewConflict(deployments, "web", errors.New("the object has been modified")), http.StatusConflict, ErrCodeConflict},
+		{"already exists", k8serrors.NewAlreadyExists(pods, "web"), http.StatusConflict, ErrCodeAlreadyExists},
+		{"invalid", k8serrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, "web", field.ErrorList{field.Required(field.NewPath("spec"), "")}), http.StatusBadRequest, ErrCodeValidationFailed},
+		{"forbidden", k8serrors.NewForbidden(pods, "web", errors.New("rbac")), http.StatusForbidden, ErrCodeForbidden},
+		{"wrapped not found", fmt.Errorf("failed to get pod: %w", k8serrors.NewNotFound(pods, "web")), http.StatusNotFound, ErrCodeNotFound},
+		{"unreachable", refused, http.StatusServiceUnavailable, ErrCodeClusterUnreachable},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, ErrCodeTimeout},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, ErrCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := KubernetesAPIError("request failed", tt.err)
+			assert.Equal(t, tt.status, apiErr.Status)
+			assert.Equal(t, tt.code, apiErr.Code)
+			assert.Equal(t, tt.err.Error(), apiErr.Details)
+		})
+	}
+}
+
+func TestApiErrorFrom_Response(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/pods/web", func(c *gin.Context) {
+		ApiErrorFrom(c, KubernetesAPIError("failed to get resource", k8serrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web")))
+	})
+	router.GET("/legacy", func(c *gin.Context) {
+		ApiError(c, http.StatusConflict, "already running", "")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pods/web", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"code":404,"errorCode":"NOT_FOUND","data":null,"message":"failed to get resource","details":"pods \"web\" not found"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/legacy", nil))
+	assert.JSONEq(t, `{"code":409,"errorCode":"CONFLICT","data":null,"message":"already running","details":""}`, w.Body.String(), "ApiError derives the code from the status")
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForwardedPrefixHeader carries the path prefix a reverse proxy stripped before forwarding a request
+const ForwardedPrefixHeader = "X-Forwarded-Prefix"
+
+// forwardedHeaders describe the address clients reach the server at, so they are only kept from trusted proxies
+var forwardedHeaders = []string{ForwardedPrefixHeader, "X-Forwarded-Host", "X-Forwarded-Proto"}
+
+// TrustForwardedHeaders removes X-Forwarded-Prefix, -Host and -Proto from requests that don't come from
+// one of trustedProxies, IPs or CIDRs, so any client can't make the server build links to another site.
+// It wraps StripBasePath, which sets X-Forwarded-Prefix itself.
+func TrustForwardedHeaders(trustedProxies []string, next http.Handler) http.Handler {
+	var networks []*net.IPNet
+	for _, proxy := range trustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			networks = append(networks, network)
+		} else if ip := net.ParseIP(proxy); ip != nil {
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		}
+	}
+	trusted := func(remoteAddr string) bool {
+		host, _, err := net.SplitHostPort(remoteAddr)
+		if err != nil {
+			host = remoteAddr
+		}
+		ip := net.ParseIP(host)
+		for _, network := range networks {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !trusted(r.RemoteAddr) {
+			r = r.Clone(r.Context())
+			for _, name := range forwardedHeaders {
+				r.Header.Del(name)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StripBasePath serves next below basePath, e.g. "/cilikube": the prefix is removed before routing, so
+// routes and the permission checks on their paths stay the same, and requests outside it get 404.
+// basePath is appended to X-Forwarded-Prefix, so links built with ExternalPath, and gin's own redirects,
+// include it just like a prefix stripped by a reverse proxy.
+func StripBasePath(basePath string, next http.Handler) http.Handler {
+	if basePath == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, basePath)
+		if !ok || (rest != "" && rest[0] != '/') {
+			http.NotFound(w, r)
+			return
+		}
+		if rest == "" {
+			rest = "/"
+		}
+
+		stripped := r.Clone(r.Context())
+		stripped.URL.Path = rest
+		if r.URL.RawPath != "" {
+			stripped.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, basePath)
+		}
+		stripped.Header.Set(ForwardedPrefixHeader, forwardedPrefix(r.Header.Get(ForwardedPrefixHeader))+basePath)
+		next.ServeHTTP(w, stripped)
+	})
+}
+
+// ForwardedPrefix returns the path prefix clients reach this server under, from X-Forwarded-Prefix,
+// or "" when it is served at the root. See TrustForwardedHeaders for who may set the header.
+func ForwardedPrefix(c *gin.Context) string {
+	return forwardedPrefix(c.GetHeader(ForwardedPrefixHeader))
+}
+
+// ExternalPath returns the path a client uses for p, a path as routed by this server
+func ExternalPath(c *gin.Context, p string) string {
+	return ForwardedPrefix(c) + p
+}
+
+// ExternalURL returns the absolute URL a client uses for p, taking the scheme and host from
+// X-Forwarded-Proto and X-Forwarded-Host when a trusted reverse proxy set them
+func ExternalURL(c *gin.Context, p string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := firstHeaderValue(c.GetHeader("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	host := c.Request.Host
+	if forwardedHost := firstHeaderValue(c.GetHeader("X-Forwarded-Host")); forwardedHost != "" {
+		host = forwardedHost
+	}
+	return scheme + "://" + host + ExternalPath(c, p)
+}
+
+// forwardedPrefix cleans an X-Forwarded-Prefix value. Anything but an absolute path is ignored, so the
+// header can't point links at another site.
+func forwardedPrefix(value string) string {
+	prefix := firstHeaderValue(value)
+	if !strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, "\\?#") {
+		return ""
+	}
+	for _, r := range prefix {
+		if r < 0x20 || r == 0x7f {
+			return ""
+		}
+	}
+	if prefix = path.Clean(prefix); prefix == "/" {
+		return ""
+	}
+	return prefix
+}
+
+// firstHeaderValue returns the first entry of a comma-separated header, the one added by the outermost proxy
+func firstHeaderValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.TrimSpace(first)
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripBasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/version", func(c *gin.Context) {
+		c.String(http.StatusOK, c.Request.URL.Path+" "+ExternalPath(c, "/swagger/index.html"))
+	})
+	router.GET("/swagger/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	handler := StripBasePath("/cilikube", router)
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/cilikube/api/v1/version", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/api/v1/version /cilikube/swagger/index.html", w.Body.String(), "routes see the path without the base path")
+
+	assert.Equal(t, http.StatusNotFound, serve("/api/v1/version", nil).Code, "routes are only served below the base path")
+	assert.Equal(t, http.StatusNotFound, serve("/cilikubex/api/v1/version", nil).Code)
+
+	w = serve("/cilikube/api/v1/version", http.Header{ForwardedPrefixHeader: {"/tools"}})
+	assert.Equal(t, "/api/v1/version /tools/cilikube/swagger/index.html", w.Body.String(), "a prefix stripped by a proxy comes first")
+
+	// gin's trailing slash redirect keeps the prefix
+	w = serve("/cilikube/swagger", nil)
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/cilikube/swagger/", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	StripBasePath("", router).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+	assert.Equal(t, "/api/v1/version /swagger/index.html", w.Body.String(), "without a base path routes are served at the root")
+}
+
+func TestExternalURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	externalURL := func(header http.Header) string {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "http://cilikube:8080/api/v1/auth/oauth/github/auth", nil)
+		c.Request.Header = header
+		return ExternalURL(c, "/api/v1/auth/oauth/callback")
+	}
+
+	assert.Equal(t, "http://cilikube:8080/api/v1/auth/oauth/callback", externalURL(http.Header{}))
+	assert.Equal(t, "https://ops.example.com/cilikube/api/v1/auth/oauth/callback", externalURL(http.Header{
+		"X-Forwarded-Proto":   {"https"},
+		"X-Forwarded-Host":    {"ops.example.com, cilikube:8080"},
+		ForwardedPrefixHeader: {"/cilikube/"},
+	}))
+	assert.Equal(t, "http://cilikube:8080/api/v1/auth/oauth/callback", externalURL(http.Header{"X-Forwarded-Proto": {"javascript"}}))
+}
+
+func TestTrustForwardedHeaders(t *testing.T) {
+	handler := TrustForwardedHeaders([]string{"10.0.0.0/8", "192.168.1.5"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Forwarded-Host") + r.Header.Get(ForwardedPrefixHeader) + " " + r.Header.Get("X-Forwarded-For")))
+	}))
+	serve := func(remoteAddr string) string {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/github/auth", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-Host", "evil.com")
+		req.Header.Set(ForwardedPrefixHeader, "/x")
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "evil.com/x 1.2.3.4", serve("10.1.2.3:4000"), "trusted proxies forward the headers")
+	assert.Equal(t, "evil.com/x 1.2.3.4", serve("192.168.1.5:4000"))
+	assert.Equal(t, " 1.2.3.4", serve("192.168.1.6:4000"), "other peers' forwarded headers are dropped, gin resolves X-Forwarded-For itself")
+}
+
+func TestForwardedPrefix(t *testing.T) {
+	for value, want := range map[string]string{
+		"":                 "",
+		"/":                "",
+		"/cilikube":        "/cilikube",
+		"/a//b/../c/":      "/a/c",
+		"/one, /two":       "/one",
+		"cilikube":         "",
+		"https://evil.com": "",
+		"/\\evil.com":      "",
+		"/a?b":             "",
+		"/a\r\nSet-Cookie": "",
+	} {
+		assert.Equal(t, want, forwardedPrefix(value), value)
+	}
+}
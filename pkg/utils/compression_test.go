@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compressionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Compression(gzip.BestSpeed, 1024))
+	router.GET("/large", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("pod-", 1000))
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		c.Writer.WriteString("data: hello\n\n")
+		c.Writer.Flush()
+		c.Writer.WriteString("data: " + strings.Repeat("x", 2048) + "\n\n")
+		c.Writer.Flush()
+	})
+	return router
+}
+
+func requestWithEncoding(router *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompression_LargeResponseIsGzipped(t *testing.T) {
+	w := requestWithEncoding(compressionRouter(), "/large", "gzip, deflate, br")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+	assert.Less(t, w.Body.Len(), 4000)
+
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("pod-", 1000), string(body))
+}
+
+func TestCompression_Deflate(t *testing.T) {
+	w := requestWithEncoding(compressionRouter(), "/large", "gzip;q=0, deflate")
+
+	assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+	reader, err := zlib.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("pod-", 1000), string(body))
+}
+
+func TestCompression_SkipsSmallAndUnsupported(t *testing.T) {
+	router := compressionRouter()
+
+	small := requestWithEncoding(router, "/small", "gzip")
+	assert.Empty(t, small.Header().Get("Content-Encoding"))
+	assert.Equal(t, "ok", small.Body.String())
+
+	identity := requestWithEncoding(router, "/large", "identity")
+	assert.Empty(t, identity.Header().Get("Content-Encoding"))
+	assert.Equal(t, 4000, identity.Body.Len())
+}
+
+func TestCompression_SkipsStreams(t *testing.T) {
+	w := requestWithEncoding(compressionRouter(), "/stream", "gzip")
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "data: hello\n\n"))
+}
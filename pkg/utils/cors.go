@@ -3,93 +3,103 @@ package utils
 import (
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/ciliverse/cilikube/configs"
 	"github.com/gin-gonic/gin"
 )
 
-// Cors handles cross-origin requests, supports preflight requests
-// Suggestion: pass allowedOrigins as parameter or load from configuration
-func Cors(allowedOrigins []string) gin.HandlerFunc {
-	// If allowedOrigins is empty, provide a default value or print warning
-	if len(allowedOrigins) == 0 {
-		log.Println("CORS Warning: No allowed origins configured!")
-		// Can choose to completely disable CORS or allow all (if AllowCredentials is false)
-		// Here we choose to disable CORS and just continue processing
-		return func(c *gin.Context) {
-			c.Next()
+// Defaults used when the CORS configuration leaves a field empty
+var (
+	DefaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	DefaultCORSHeaders = []string{"Authorization", "Content-Type", "Accept", "Origin", "Cache-Control", "X-Requested-With", "X-CSRF-Token", "X-Cluster-ID"}
+)
+
+// DefaultCORSMaxAge is how long browsers may cache a preflight response when no max age is configured
+const DefaultCORSMaxAge = 86400
+
+// Cors handles cross-origin requests according to cfg. Allowed origins get their own origin reflected
+// back, preflight requests are answered with 204 without reaching the handlers, and preflight requests
+// from other origins are rejected with 403. A "*" entry allows any origin only while credentials are
+// disabled; with credentials it is ignored so the API is never opened to every site with cookies.
+func Cors(cfg configs.CORSConfig) gin.HandlerFunc {
+	allowAny := false
+	allowed := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "*" {
+			allowAny = true
+			continue
 		}
+		if origin != "" {
+			allowed[strings.ToLower(origin)] = true
+		}
+	}
+	if allowAny && cfg.AllowCredentials {
+		log.Println("CORS Warning: \"*\" in allowed_origins is ignored because allow_credentials is enabled")
+		allowAny = false
+	}
+	if !allowAny && len(allowed) == 0 {
+		log.Println("CORS Warning: No allowed origins configured, cross-origin requests will be rejected")
 	}
 
-	return func(c *gin.Context) {
-		// Get the request's Origin
-		origin := c.Request.Header.Get("Origin")
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+	maxAge := cfg.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultCORSMaxAge
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
 
-		// If there's no Origin header (e.g., non-browser requests or same-origin requests), no need to handle CORS
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		// Requests without an Origin header are not cross-origin browser requests
 		if origin == "" {
 			c.Next()
 			return
 		}
 
-		// Check if the request's Origin is in the allowed list
-		allowedOrigin := ""
-		for _, o := range allowedOrigins {
-			if o == origin {
-				allowedOrigin = origin
-				break
-			}
-			// Optional: handle more complex matching logic like wildcard subdomains
+		header := c.Writer.Header()
+		if !allowAny {
+			header.Add("Vary", "Origin")
 		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
 
-		// If Origin matches successfully
-		if allowedOrigin != "" {
-			c.Header("Access-Control-Allow-Origin", allowedOrigin)
-			// Important: Since Allow-Origin is not "*", we need the Vary header
-			c.Header("Vary", "Origin")
-
-			// Set other CORS headers
-			c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE, PATCH")            // More comprehensive method list
-			c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-CSRF-Token, Accept") // Add common headers
-			c.Header("Access-Control-Allow-Credentials", "true")                                          // Support credentials
-			c.Header("Access-Control-Max-Age", "86400")                                                   // Preflight request cache time
-
-			// Properly handle OPTIONS preflight requests: if Origin is allowed and method is OPTIONS, abort and return 204
-			if c.Request.Method == "OPTIONS" {
-				log.Printf("CORS: Preflight request for %s from %s allowed.", c.Request.URL.Path, origin)
-				c.AbortWithStatus(http.StatusNoContent)
+		if !allowAny && !allowed[strings.ToLower(origin)] {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
 				return
 			}
-
-			// For non-OPTIONS requests, continue processing
-			log.Printf("CORS: Allowed non-preflight request for %s from %s.", c.Request.URL.Path, origin)
+			// Without CORS headers the browser keeps the response from the page; same-origin
+			// requests that carry an Origin header still work.
 			c.Next()
+			return
+		}
 
+		if allowAny {
+			header.Set("Access-Control-Allow-Origin", "*")
 		} else {
-			// If Origin doesn't match
-			log.Printf("CORS: Origin '%s' not allowed for %s.", origin, c.Request.URL.Path)
-
-			// For OPTIONS preflight requests, if Origin is not allowed, should also abort but may not set CORS headers
-			// Browser will reject the request due to missing necessary Allow-Origin header
-			if c.Request.Method == "OPTIONS" {
-				// Can choose to return 403 Forbidden or simply Abort
-				// c.AbortWithStatus(http.StatusForbidden) // More explicit rejection
-				c.Abort() // Or just abort the processing chain
-				return
-			}
-
-			// For non-OPTIONS requests, Origin not allowed
-			// Browser will send the request but will block frontend JS from reading the response.
-			// Here we can choose:
-			// 1. Call c.Next(): let request continue, but browser will error (current code logic)
-			// 2. Call c.AbortWithStatus(http.StatusForbidden): directly reject request (stricter)
-			// Choosing c.Next() means backend might execute operations, but frontend can't receive results.
-			// Choosing Abort is safer, preventing unauthorized origins from triggering operations.
-			// Here we choose the safer Abort:
-			log.Printf("CORS: Aborting non-preflight request from disallowed origin '%s' for %s.", origin, c.Request.URL.Path)
-			c.AbortWithStatus(http.StatusForbidden) // Direct rejection
-			// Or, if you want to keep the original behavior (allow backend processing but browser blocks):
-			// c.Next()
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
 
-			return // Ensure return after abort
+		if preflight {
+			header.Set("Access-Control-Allow-Methods", allowMethods)
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+			header.Set("Access-Control-Max-Age", strconv.Itoa(maxAge))
+			c.AbortWithStatus(http.StatusNoContent)
+			return
 		}
+		c.Next()
 	}
 }
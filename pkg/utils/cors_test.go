@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func corsRouter(cfg configs.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Cors(cfg))
+	router.GET("/pods", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/pods", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCors_AllowedOrigin(t *testing.T) {
+	router := corsRouter(configs.CORSConfig{
+		AllowedOrigins:   []string{"https://console.example.com/"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	})
+
+	w := corsRequest(router, http.MethodGet, "https://console.example.com", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://console.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	w = corsRequest(router, http.MethodOptions, "https://console.example.com", true)
+	assert.Equal(t, http.StatusNoContent, w.Code, "preflight requests are answered by the middleware")
+	assert.Equal(t, "https://console.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestCors_DisallowedOrigin(t *testing.T) {
+	router := corsRouter(configs.CORSConfig{AllowedOrigins: []string{"https://console.example.com"}, AllowCredentials: true})
+
+	w := corsRequest(router, http.MethodOptions, "https://evil.example.com", true)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = corsRequest(router, http.MethodGet, "https://evil.example.com", false)
+	assert.Equal(t, http.StatusOK, w.Code, "the browser, not the server, blocks the response")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = corsRequest(router, http.MethodGet, "", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCors_Wildcard(t *testing.T) {
+	router := corsRouter(configs.CORSConfig{AllowedOrigins: []string{"*"}})
+	w := corsRequest(router, http.MethodGet, "https://any.example.com", false)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	router = corsRouter(configs.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	w = corsRequest(router, http.MethodGet, "https://any.example.com", false)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), "\"*\" is never combined with credentials")
+
+	w = corsRequest(router, http.MethodOptions, "https://any.example.com", true)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodyBytes is the largest request body accepted when no limit is configured
+const DefaultMaxBodyBytes int64 = 10 << 20
+
+// MaxBodySize rejects requests whose body is larger than limit bytes with 413. Bodies with a known
+// length are rejected before the handler runs; chunked bodies are cut off once the limit is read,
+// which handlers can detect with IsBodyTooLarge. A limit of zero uses DefaultMaxBodyBytes, a negative
+// limit disables the check.
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	if limit == 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	return func(c *gin.Context) {
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			ApiError(c, http.StatusRequestEntityTooLarge, "request body too large", fmt.Sprintf("the limit is %d bytes", limit))
+			c.Abort()
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// IsBodyTooLarge reports whether err comes from reading a body cut off by MaxBodySize
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// RouteTimeout replaces the server wide read and write timeouts for the routes it is attached to.
+// A zero duration removes that deadline, which streaming endpoints need to outlive WriteTimeout.
+// The deadlines also apply to connections hijacked for WebSockets.
+func RouteTimeout(read, write time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setDeadlines(c, read, write)
+		c.Next()
+	}
+}
+
+// NoTimeout lifts the server read and write deadlines for long lived streaming routes
+func NoTimeout() gin.HandlerFunc {
+	return RouteTimeout(0, 0)
+}
+
+// StreamingNoTimeout lifts the deadlines only for requests that stream: WebSocket upgrades,
+// event streams, and watch or follow requests. Other requests keep the server timeouts, which suits
+// routes such as the API proxy that serve both kinds.
+func StreamingNoTimeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsStreamingRequest(c.Request) {
+			setDeadlines(c, 0, 0)
+		}
+		c.Next()
+	}
+}
+
+// IsStreamingRequest reports whether r asks for a response that stays open
+func IsStreamingRequest(r *http.Request) bool {
+	if r.Header.Get("Upgrade") != "" || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return true
+	}
+	query := r.URL.Query()
+	return query.Get("watch") == "true" || query.Get("watch") == "1" || query.Get("follow") == "true"
+}
+
+func setDeadlines(c *gin.Context, read, write time.Duration) {
+	rc := http.NewResponseController(c.Writer)
+	// Writers that cannot change deadlines, such as test recorders, have no server timeouts to lift
+	_ = rc.SetReadDeadline(deadline(read))
+	_ = rc.SetWriteDeadline(deadline(write))
+}
+
+// deadline turns a timeout into an absolute deadline, the zero time meaning none
+func deadline(timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
+}
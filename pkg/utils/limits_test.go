@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxBodySize_RejectsOversizedBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MaxBodySize(16))
+	router.POST("/apply", func(c *gin.Context) {
+		data, err := io.ReadAll(c.Request.Body)
+		if IsBodyTooLarge(err) {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		require.NoError(t, err)
+		c.String(http.StatusOK, string(data))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apply", strings.NewReader("small")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "small", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apply", strings.NewReader(strings.Repeat("x", 17))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "a body with a known length is rejected up front")
+	assert.Contains(t, w.Body.String(), "request body too large")
+
+	// Without a Content-Length the body is cut off while the handler reads it
+	req := httptest.NewRequest(http.MethodPost, "/apply", io.NopCloser(strings.NewReader(strings.Repeat("x", 64))))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestMaxBodySize_BoundBodiesAreRejectedWith413(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MaxBodySize(0))
+	router.POST("/apply", func(c *gin.Context) {
+		var body map[string]interface{}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			ApiBindError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	body := `{"data":"` + strings.Repeat("x", int(DefaultMaxBodyBytes)) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/apply", io.NopCloser(strings.NewReader(body)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "an unset limit uses DefaultMaxBodyBytes")
+	assert.Contains(t, w.Body.String(), string(ErrCodeRequestTooLarge))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apply", strings.NewReader(`{"data":"x"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRouteTimeout_StreamingRouteOutlivesWriteTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	stream := func(c *gin.Context) {
+		for i := 0; i < 4; i++ {
+			c.Writer.WriteString("tick\n")
+			c.Writer.Flush()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	router.GET("/stream", NoTimeout(), stream)
+	router.GET("/bounded", stream)
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 150 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/stream")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("tick\n", 4), string(body))
+
+	resp, err = http.Get(server.URL + "/bounded")
+	if err == nil {
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	assert.True(t, err != nil || len(body) < len(strings.Repeat("tick\n", 4)), "routes without an override keep the server write timeout")
+}
+
+func TestIsStreamingRequest(t *testing.T) {
+	assert.True(t, IsStreamingRequest(httptest.NewRequest(http.MethodGet, "/api/v1/pods?watch=true", nil)))
+	assert.True(t, IsStreamingRequest(httptest.NewRequest(http.MethodGet, "/api/v1/namespaces/a/pods/b/log?follow=true", nil)))
+
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Upgrade", "websocket")
+	assert.True(t, IsStreamingRequest(req))
+
+	assert.False(t, IsStreamingRequest(httptest.NewRequest(http.MethodGet, "/api/v1/pods", nil)))
+}
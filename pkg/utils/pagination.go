@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/gin-gonic/gin"
+)
+
+// Defaults used when the pagination configuration leaves a field empty
+const (
+	DefaultPageSize    = 20
+	DefaultMaxPageSize = 100
+)
+
+// paginationContextKey holds the configured page sizes of a request
+const paginationContextKey = "pagination_limits"
+
+// Page is the page of a list request
+type Page struct {
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+// Offset returns the index of the first item of the page
+func (p Page) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// Bounds returns the slice bounds of the page within total items
+func (p Page) Bounds(total int) (int, int) {
+	start := min(p.Offset(), total)
+	return start, min(start+p.PageSize, total)
+}
+
+// PaginationLimits makes the configured default and maximum page sizes available to ParsePage
+func PaginationLimits(cfg configs.PaginationConfig) gin.HandlerFunc {
+	limits := paginationLimits(cfg)
+	return func(c *gin.Context) {
+		c.Set(paginationContextKey, limits)
+		c.Next()
+	}
+}
+
+// paginationLimits fills the empty fields of cfg with the defaults
+func paginationLimits(cfg configs.PaginationConfig) configs.PaginationConfig {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultMaxPageSize
+	}
+	if cfg.DefaultSize <= 0 {
+		cfg.DefaultSize = DefaultPageSize
+	}
+	cfg.DefaultSize = min(cfg.DefaultSize, cfg.MaxSize)
+	return cfg
+}
+
+// ParsePage reads the page and page_size query parameters. A missing page_size uses the configured
+// default, an invalid one or one above the configured maximum is answered with 400 and false.
+func ParsePage(c *gin.Context) (Page, bool) {
+	limits := paginationLimits(configs.PaginationConfig{})
+	if value, ok := c.Get(paginationContextKey); ok {
+		limits = value.(configs.PaginationConfig)
+	}
+
+	page := Page{Page: 1, PageSize: limits.DefaultSize}
+	if value := c.Query("page"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			ApiError(c, http.StatusBadRequest, "invalid page", "page must be a positive integer")
+			return Page{}, false
+		}
+		page.Page = n
+	}
+	if value := c.Query("page_size"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			ApiError(c, http.StatusBadRequest, "invalid page_size", "page_size must be a positive integer")
+			return Page{}, false
+		}
+		if n > limits.MaxSize {
+			ApiError(c, http.StatusBadRequest, "invalid page_size", fmt.Sprintf("page_size must not exceed %d", limits.MaxSize))
+			return Page{}, false
+		}
+		page.PageSize = n
+	}
+	return page, true
+}
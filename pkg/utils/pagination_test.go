@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(PaginationLimits(configs.PaginationConfig{DefaultSize: 25, MaxSize: 50}))
+	router.GET("/items", func(c *gin.Context) {
+		page, ok := ParsePage(c)
+		if !ok {
+			return
+		}
+		start, end := page.Bounds(60)
+		c.JSON(http.StatusOK, gin.H{"page": page.Page, "page_size": page.PageSize, "offset": page.Offset(), "start": start, "end": end})
+	})
+
+	tests := []struct {
+		query  string
+		status int
+		body   string
+	}{
+		{"", http.StatusOK, `{"page":1,"page_size":25,"offset":0,"start":0,"end":25}`},
+		{"?page=3&page_size=25", http.StatusOK, `{"page":3,"page_size":25,"offset":50,"start":50,"end":60}`},
+		{"?page=9&page_size=50", http.StatusOK, `{"page":9,"page_size":50,"offset":400,"start":60,"end":60}`},
+		{"?page_size=51", http.StatusBadRequest, ""},
+		{"?page_size=1000000", http.StatusBadRequest, ""},
+		{"?page_size=0", http.StatusBadRequest, ""},
+		{"?page=-1", http.StatusBadRequest, ""},
+		{"?page=abc", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items"+tt.query, nil))
+			assert.Equal(t, tt.status, w.Code)
+			if tt.body != "" {
+				assert.JSONEq(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestParsePage_DefaultLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/items", func(c *gin.Context) {
+		if page, ok := ParsePage(c); ok {
+			c.JSON(http.StatusOK, page)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	assert.JSONEq(t, `{"page":1,"page_size":20}`, w.Body.String(), "routes without the middleware use the defaults")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?page_size=101", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "page_size must not exceed 100")
+}
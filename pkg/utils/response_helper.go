@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,16 +24,82 @@ func ApiSuccess(c *gin.Context, data interface{}, message string) {
 	})
 }
 
+// ApiSuccessWithETag writes the same response as ApiSuccess with an ETag header and answers
+// 304 Not Modified when the client's If-None-Match already matches. The ETag is derived from
+// version, normally a Kubernetes resourceVersion, or from a hash of the body when version is empty.
+func ApiSuccessWithETag(c *gin.Context, data interface{}, message, version string) {
+	if message == "" {
+		message = "success"
+	}
+	response := gin.H{
+		"code":    http.StatusOK,
+		"data":    data,
+		"message": message,
+	}
+
+	var body []byte
+	var etag string
+	if version != "" {
+		etag = strconv.Quote(version)
+	} else {
+		var err error
+		if body, err = json.Marshal(response); err != nil {
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		sum := sha256.Sum256(body)
+		etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	if body != nil {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using weak comparison
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ApiError writes an error response with the code matching statusCode
 func ApiError(c *gin.Context, statusCode int, message string, details ...string) {
 	detailStr := ""
 	if len(details) > 0 {
 		detailStr = details[0]
 	}
-	log.Printf("API Error: Status %d, Message: %s, Details: %s, Path: %s", statusCode, message, detailStr, c.Request.URL.Path)
-	c.JSON(statusCode, gin.H{
-		"code":    statusCode,
-		"data":    nil,
-		"message": message,
-		"details": detailStr,
-	})
+	ApiErrorFrom(c, NewAPIError(statusCode, ErrorCodeForStatus(statusCode), message, detailStr))
+}
+
+// ApiErrorFrom writes the error response of apiErr. "code" keeps carrying the HTTP status, while
+// "errorCode" is the machine-readable code. Invalid request fields are listed under "errors".
+func ApiErrorFrom(c *gin.Context, apiErr *APIError) {
+	log.Printf("API Error: Status %d, Code: %s, Message: %s, Details: %s, Path: %s", apiErr.Status, apiErr.Code, apiErr.Message, apiErr.Details, c.Request.URL.Path)
+	response := gin.H{
+		"code":      apiErr.Status,
+		"errorCode": apiErr.Code,
+		"data":      nil,
+		"message":   apiErr.Message,
+		"details":   apiErr.Details,
+	}
+	if len(apiErr.Fields) > 0 {
+		response["errors"] = apiErr.Fields
+	}
+	c.JSON(apiErr.Status, response)
 }
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func etagRouter(version *string, data *string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/items", func(c *gin.Context) {
+		ApiSuccessWithETag(c, gin.H{"name": *data}, "", *version)
+	})
+	return router
+}
+
+func getWithETag(router *gin.Engine, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestApiSuccessWithETag_ResourceVersion(t *testing.T) {
+	version, data := "1001", "nginx"
+	router := etagRouter(&version, &data)
+
+	first := getWithETag(router, "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.Equal(t, `"1001"`, etag)
+	assert.Contains(t, first.Body.String(), "nginx")
+
+	second := getWithETag(router, etag)
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	assert.Empty(t, second.Body.String())
+	assert.Equal(t, http.StatusNotModified, getWithETag(router, `"999", W/"1001"`).Code, "weak and listed tags match")
+
+	version = "1002"
+	third := getWithETag(router, etag)
+	assert.Equal(t, http.StatusOK, third.Code, "a new resourceVersion invalidates the ETag")
+	assert.Equal(t, `"1002"`, third.Header().Get("ETag"))
+}
+
+func TestApiSuccessWithETag_BodyHash(t *testing.T) {
+	version, data := "", "nginx"
+	router := etagRouter(&version, &data)
+
+	first := getWithETag(router, "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.JSONEq(t, `{"code":200,"data":{"name":"nginx"},"message":"success"}`, first.Body.String())
+
+	assert.Equal(t, http.StatusNotModified, getWithETag(router, etag).Code)
+
+	data = "redis"
+	changed := getWithETag(router, etag)
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+}
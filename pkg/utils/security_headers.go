@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/gin-gonic/gin"
+)
+
+// Defaults used for security headers the configuration leaves unset
+const (
+	DefaultContentTypeOptions    = "nosniff"
+	DefaultFrameOptions          = "DENY"
+	DefaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+	DefaultReferrerPolicy        = "no-referrer"
+	DefaultHSTSMaxAge            = 365 * 24 * 60 * 60
+)
+
+// SecurityHeaders adds the configured security headers to every response before the handlers run, so
+// handlers serving pages, like Swagger UI, can replace the policy with their own. Strict-Transport-Security
+// is only sent over HTTPS, directly or through a proxy setting X-Forwarded-Proto. WebSocket handshakes and
+// event streams are not documents and only get Strict-Transport-Security and X-Content-Type-Options.
+func SecurityHeaders(cfg configs.SecurityHeadersConfig) gin.HandlerFunc {
+	if cfg.Enabled != nil && !*cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	contentTypeOptions := headerOrDefault(cfg.ContentTypeOptions, DefaultContentTypeOptions)
+	documentHeaders := map[string]string{
+		"X-Frame-Options":         headerOrDefault(cfg.FrameOptions, DefaultFrameOptions),
+		"Content-Security-Policy": headerOrDefault(cfg.ContentSecurityPolicy, DefaultContentSecurityPolicy),
+		"Referrer-Policy":         headerOrDefault(cfg.ReferrerPolicy, DefaultReferrerPolicy),
+	}
+
+	hsts := ""
+	maxAge := cfg.HSTSMaxAge
+	if maxAge == 0 {
+		maxAge = DefaultHSTSMaxAge
+	}
+	if maxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(maxAge)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		if contentTypeOptions != "" {
+			header.Set("X-Content-Type-Options", contentTypeOptions)
+		}
+		if hsts != "" && (c.Request.TLS != nil || firstHeaderValue(c.GetHeader("X-Forwarded-Proto")) == "https") {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+
+		streaming := c.GetHeader("Upgrade") != "" || strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+		if !streaming {
+			for name, value := range documentHeaders {
+				if value != "" {
+					header.Set(name, value)
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// headerOrDefault returns the configured header value, or def when it is unset
+func headerOrDefault(value *string, def string) string {
+	if value == nil {
+		return def
+	}
+	return strings.TrimSpace(*value)
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ciliverse/cilikube/configs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func securityHeadersRequest(cfg configs.SecurityHeadersConfig, prepare func(*http.Request)) http.Header {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SecurityHeaders(cfg))
+	router.GET("/pods", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": []string{}})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/pods", nil)
+	if prepare != nil {
+		prepare(req)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Header()
+}
+
+func TestSecurityHeaders_Defaults(t *testing.T) {
+	header := securityHeadersRequest(configs.SecurityHeadersConfig{}, nil)
+	assert.Equal(t, "nosniff", header.Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", header.Get("X-Frame-Options"))
+	assert.Equal(t, "default-src 'none'; frame-ancestors 'none'", header.Get("Content-Security-Policy"))
+	assert.Equal(t, "no-referrer", header.Get("Referrer-Policy"))
+	assert.Empty(t, header.Get("Strict-Transport-Security"), "HSTS is not sent over plain HTTP")
+
+	header = securityHeadersRequest(configs.SecurityHeadersConfig{}, func(req *http.Request) { req.TLS = &tls.ConnectionState{} })
+	assert.Equal(t, "max-age=31536000", header.Get("Strict-Transport-Security"))
+
+	header = securityHeadersRequest(configs.SecurityHeadersConfig{}, func(req *http.Request) { req.Header.Set("X-Forwarded-Proto", "https") })
+	assert.Equal(t, "max-age=31536000", header.Get("Strict-Transport-Security"), "TLS terminated at a proxy")
+}
+
+func TestSecurityHeaders_Configured(t *testing.T) {
+	sameOrigin, empty := "SAMEORIGIN", ""
+	cfg := configs.SecurityHeadersConfig{
+		FrameOptions:          &sameOrigin,
+		ContentSecurityPolicy: &empty,
+		HSTSMaxAge:            600,
+		HSTSIncludeSubdomains: true,
+	}
+	header := securityHeadersRequest(cfg, func(req *http.Request) { req.TLS = &tls.ConnectionState{} })
+	assert.Equal(t, "SAMEORIGIN", header.Get("X-Frame-Options"))
+	assert.NotContains(t, header, "Content-Security-Policy", "an empty value leaves the header out")
+	assert.Equal(t, "no-referrer", header.Get("Referrer-Policy"))
+	assert.Equal(t, "max-age=600; includeSubDomains", header.Get("Strict-Transport-Security"))
+
+	header = securityHeadersRequest(configs.SecurityHeadersConfig{HSTSMaxAge: -1}, func(req *http.Request) { req.TLS = &tls.ConnectionState{} })
+	assert.Empty(t, header.Get("Strict-Transport-Security"))
+
+	disabled := false
+	header = securityHeadersRequest(configs.SecurityHeadersConfig{Enabled: &disabled}, nil)
+	assert.Empty(t, header.Get("X-Content-Type-Options"))
+	assert.Empty(t, header.Get("X-Frame-Options"))
+}
+
+func TestSecurityHeaders_Streaming(t *testing.T) {
+	for name, prepare := range map[string]func(*http.Request){
+		"websocket":    func(req *http.Request) { req.Header.Set("Upgrade", "websocket") },
+		"event stream": func(req *http.Request) { req.Header.Set("Accept", "text/event-stream") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			header := securityHeadersRequest(configs.SecurityHeadersConfig{}, prepare)
+			assert.Equal(t, "nosniff", header.Get("X-Content-Type-Options"))
+			assert.Empty(t, header.Get("X-Frame-Options"))
+			assert.Empty(t, header.Get("Content-Security-Policy"))
+			assert.Empty(t, header.Get("Referrer-Policy"))
+		})
+	}
+}
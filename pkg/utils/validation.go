@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// usernameRegex allows letters, digits, '.', '_' and '-', starting with a letter or digit
+var usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// Report fields by their JSON names, the ones clients send
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+		_ = v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+			return usernameRegex.MatchString(fl.Field().String())
+		})
+	}
+}
+
+// ValidationErrors returns a message for every field that failed the binding tags of a request, keyed
+// by its JSON name, or nil when err is not a validation error
+func ValidationErrors(err error) map[string]string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+	fields := make(map[string]string, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		field := fieldError.Field()
+		if _, exists := fields[field]; !exists {
+			fields[field] = validationMessage(fieldError)
+		}
+	}
+	return fields
+}
+
+// validationMessage describes the rule a field broke
+func validationMessage(fieldError validator.FieldError) string {
+	isString := fieldError.Kind() == reflect.String
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "username":
+		return "may only contain letters, digits, '.', '_' and '-', starting with a letter or digit"
+	case "min":
+		if isString {
+			return "min " + fieldError.Param() + " chars"
+		}
+		return "must be at least " + fieldError.Param()
+	case "max":
+		if isString {
+			return "max " + fieldError.Param() + " chars"
+		}
+		return "must be at most " + fieldError.Param()
+	case "oneof":
+		return "must be one of " + fieldError.Param()
+	}
+	return "failed the " + fieldError.Tag() + " check"
+}
+
+// ApiBindError writes the response of a request body that failed to bind: 400 with VALIDATION_FAILED
+// and the message of every invalid field under "errors", BAD_REQUEST when the body can't be parsed,
+// or 413 when it was cut off by MaxBodySize
+func ApiBindError(c *gin.Context, err error) {
+	if IsBodyTooLarge(err) {
+		ApiError(c, http.StatusRequestEntityTooLarge, "request body too large", err.Error())
+		return
+	}
+	if fields := ValidationErrors(err); fields != nil {
+		apiErr := NewAPIError(http.StatusBadRequest, ErrCodeValidationFailed, "validation failed", "")
+		apiErr.Fields = fields
+		ApiErrorFrom(c, apiErr)
+		return
+	}
+	ApiError(c, http.StatusBadRequest, "invalid request body", err.Error())
+}
@@ -0,0 +1,35 @@
+// Package version holds build information injected at compile time, e.g.
+//
+//	go build -ldflags "-X github.com/ciliverse/cilikube/pkg/version.Version=v0.5.0 \
+//	  -X github.com/ciliverse/cilikube/pkg/version.GitCommit=$(git rev-parse --short HEAD) \
+//	  -X github.com/ciliverse/cilikube/pkg/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "runtime"
+
+// These variables are set at build time via -ldflags
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+}
@@ -0,0 +1,21 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	oldVersion, oldCommit, oldDate := Version, GitCommit, BuildDate
+	defer func() { Version, GitCommit, BuildDate = oldVersion, oldCommit, oldDate }()
+
+	Version, GitCommit, BuildDate = "v0.5.0", "abc1234", "2026-10-16T00:00:00Z"
+	info := Get()
+	assert.Equal(t, "v0.5.0", info.Version)
+	assert.Equal(t, "abc1234", info.GitCommit)
+	assert.Equal(t, "2026-10-16T00:00:00Z", info.BuildDate)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, info.Platform)
+}